@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+
+	"github.com/concourse/baggageclaim"
+)
+
+// chunks are held in memory, to checksum them before they are sent or
+// extracted
+const maxChunkSizeInMB = 64
+
+const chunkedContentType = "multipart/mixed"
+
+var ErrInvalidChunkSize = errors.New("chunk size must be a number of megabytes between 1 and 64")
+var ErrChunkOutOfOrder = errors.New("stream chunk is missing or out of order")
+var ErrChunkTooLarge = errors.New("stream chunk is larger than 64 megabytes")
+var ErrChunkChecksumMismatch = errors.New("stream chunk does not match its checksum")
+
+func parseChunkSize(value string) (int, error) {
+	megabytes, err := strconv.Atoi(value)
+	if err != nil || megabytes < 1 || megabytes > maxChunkSizeInMB {
+		return 0, ErrInvalidChunkSize
+	}
+
+	return megabytes * 1024 * 1024, nil
+}
+
+// chunkWriter splits what is written to it into parts of a multipart
+// response, each of the chunk size but the last.
+type chunkWriter struct {
+	parts *multipart.Writer
+	size  int
+
+	chunk bytes.Buffer
+	index int
+}
+
+func newChunkWriter(w io.Writer, size int) *chunkWriter {
+	return &chunkWriter{
+		parts: multipart.NewWriter(w),
+		size:  size,
+	}
+}
+
+func (w *chunkWriter) ContentType() string {
+	return mime.FormatMediaType(chunkedContentType, map[string]string{"boundary": w.parts.Boundary()})
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		room := w.size - w.chunk.Len()
+		if room > len(p) {
+			room = len(p)
+		}
+
+		w.chunk.Write(p[:room])
+		written += room
+		p = p[room:]
+
+		if w.chunk.Len() == w.size {
+			err := w.flush()
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// Close sends the last chunk and ends the response, which is how consumers
+// tell a complete stream from one that was cut short.
+func (w *chunkWriter) Close() error {
+	if w.chunk.Len() > 0 {
+		err := w.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.parts.Close()
+}
+
+func (w *chunkWriter) flush() error {
+	checksum := sha256.Sum256(w.chunk.Bytes())
+
+	part, err := w.parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":                   {"application/octet-stream"},
+		baggageclaim.ChunkIndexHeader:    {strconv.Itoa(w.index)},
+		baggageclaim.ChunkChecksumHeader: {hex.EncodeToString(checksum[:])},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = part.Write(w.chunk.Bytes())
+	if err != nil {
+		return err
+	}
+
+	w.chunk.Reset()
+	w.index++
+
+	return nil
+}
+
+// chunkReader reassembles a stream sent as chunks, only handing out a chunk
+// once it has been checked to be the next one and to match its checksum.
+type chunkReader struct {
+	body  io.Reader
+	parts *multipart.Reader
+
+	chunk *bytes.Reader
+	index int
+
+	// the first chunk that failed the checks, as opposed to the stream
+	// being cut short
+	err error
+}
+
+// chunkedRequestBody returns a reader reassembling the body of the request if
+// it was sent as chunks, or nil if it was not.
+func chunkedRequestBody(req *http.Request) *chunkReader {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != chunkedContentType || params["boundary"] == "" {
+		return nil
+	}
+
+	return &chunkReader{
+		body:  req.Body,
+		parts: multipart.NewReader(req.Body, params["boundary"]),
+		chunk: bytes.NewReader(nil),
+	}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for r.chunk.Len() == 0 {
+		err := r.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return r.chunk.Read(p)
+}
+
+func (r *chunkReader) next() error {
+	part, err := r.parts.NextPart()
+	if err == io.EOF {
+		// whatever follows the last part is read too, so that the request's
+		// trailer is
+		_, err = io.Copy(ioutil.Discard, r.body)
+		if err != nil {
+			return err
+		}
+
+		return io.EOF
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer part.Close()
+
+	index, err := strconv.Atoi(part.Header.Get(baggageclaim.ChunkIndexHeader))
+	if err != nil || index != r.index {
+		r.err = ErrChunkOutOfOrder
+		return r.err
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(part, maxChunkSizeInMB*1024*1024+1))
+	if err != nil {
+		return err
+	}
+
+	if len(contents) > maxChunkSizeInMB*1024*1024 {
+		r.err = ErrChunkTooLarge
+		return r.err
+	}
+
+	checksum := sha256.Sum256(contents)
+	if part.Header.Get(baggageclaim.ChunkChecksumHeader) != hex.EncodeToString(checksum[:]) {
+		r.err = ErrChunkChecksumMismatch
+		return r.err
+	}
+
+	r.chunk.Reset(contents)
+	r.index++
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+const (
+	ControlDrain   = "drain"
+	ControlStatus  = "status"
+	ControlReapNow = "reap-now"
+)
+
+type ControlResponse struct {
+	Error string `json:"error,omitempty"`
+
+	Draining bool `json:"draining"`
+	Volumes  int  `json:"volumes"`
+}
+
+// ControlServer serves a line-based protocol on a unix socket for local
+// tooling: each line is a command, answered by a single line of JSON. There
+// is no authentication; access is gated by the socket's file permissions,
+// which only allow the owner.
+type ControlServer struct {
+	logger     lager.Logger
+	socketPath string
+
+	volumeRepo volume.Repository
+	drainState *DrainState
+	reap       func(lager.Logger) error
+}
+
+func NewControlServer(
+	logger lager.Logger,
+	socketPath string,
+	volumeRepo volume.Repository,
+	drainState *DrainState,
+	reap func(lager.Logger) error,
+) *ControlServer {
+	return &ControlServer{
+		logger:     logger,
+		socketPath: socketPath,
+		volumeRepo: volumeRepo,
+		drainState: drainState,
+		reap:       reap,
+	}
+}
+
+func (cs *ControlServer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	// a socket left behind by a previous run would fail the listen
+	err := os.Remove(cs.socketPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	listener, err := net.Listen("unix", cs.socketPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(cs.socketPath, 0600)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	close(ready)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go cs.serve(conn)
+		}
+	}()
+
+	<-signals
+
+	return listener.Close()
+}
+
+func (cs *ControlServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	encoder := json.NewEncoder(conn)
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" {
+			continue
+		}
+
+		err := encoder.Encode(cs.handle(command))
+		if err != nil {
+			cs.logger.Error("failed-to-write-response", err)
+			return
+		}
+	}
+}
+
+func (cs *ControlServer) handle(command string) ControlResponse {
+	logger := cs.logger.Session("control", lager.Data{
+		"command": command,
+	})
+
+	switch command {
+	case ControlDrain:
+		logger.Info("draining")
+		cs.drainState.Drain()
+
+	case ControlStatus:
+
+	case ControlReapNow:
+		err := cs.reap(logger)
+		if err != nil {
+			logger.Error("failed-to-reap", err)
+			return ControlResponse{Error: err.Error()}
+		}
+
+	default:
+		logger.Info("unknown-command")
+		return ControlResponse{Error: "unknown command: " + command}
+	}
+
+	return cs.status(logger)
+}
+
+func (cs *ControlServer) status(logger lager.Logger) ControlResponse {
+	volumes, _, err := cs.volumeRepo.ListVolumes(volume.Properties{})
+	if err != nil {
+		logger.Error("failed-to-list-volumes", err)
+		return ControlResponse{Error: err.Error()}
+	}
+
+	return ControlResponse{
+		Draining: cs.drainState.IsDraining(),
+		Volumes:  len(volumes),
+	}
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+)
+
+var _ = Describe("Control Server", func() {
+	var (
+		tempDir        string
+		socketPath     string
+		fakeRepository *volumefakes.FakeRepository
+		drainState     *api.DrainState
+		reapCalls      int
+		reapErr        error
+
+		process ifrit.Process
+		conn    net.Conn
+		reader  *bufio.Reader
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "control-server")
+		Expect(err).NotTo(HaveOccurred())
+
+		socketPath = filepath.Join(tempDir, "control.sock")
+
+		fakeRepository = new(volumefakes.FakeRepository)
+		fakeRepository.ListVolumesReturns(volume.Volumes{{Handle: "a"}, {Handle: "b"}}, nil, nil)
+
+		drainState = &api.DrainState{}
+		reapCalls = 0
+		reapErr = nil
+	})
+
+	JustBeforeEach(func() {
+		server := api.NewControlServer(
+			lagertest.NewTestLogger("control-server"),
+			socketPath,
+			fakeRepository,
+			drainState,
+			func(lager.Logger) error {
+				reapCalls++
+				return reapErr
+			},
+		)
+
+		process = ifrit.Invoke(server)
+
+		var err error
+		conn, err = net.Dial("unix", socketPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		reader = bufio.NewReader(conn)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	send := func(command string) api.ControlResponse {
+		_, err := conn.Write([]byte(command + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		line, err := reader.ReadBytes('\n')
+		Expect(err).NotTo(HaveOccurred())
+
+		var response api.ControlResponse
+		Expect(json.Unmarshal(line, &response)).To(Succeed())
+		return response
+	}
+
+	It("only lets the owner connect", func() {
+		info, err := os.Stat(socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("reports the status", func() {
+		Expect(send("status")).To(Equal(api.ControlResponse{Draining: false, Volumes: 2}))
+	})
+
+	It("drains", func() {
+		Expect(send("drain")).To(Equal(api.ControlResponse{Draining: true, Volumes: 2}))
+		Expect(drainState.IsDraining()).To(BeTrue())
+	})
+
+	It("reaps on demand", func() {
+		Expect(send("reap-now")).To(Equal(api.ControlResponse{Volumes: 2}))
+		Expect(reapCalls).To(Equal(1))
+	})
+
+	Context("when reaping fails", func() {
+		BeforeEach(func() {
+			reapErr = errors.New("nope")
+		})
+
+		It("returns the error", func() {
+			Expect(send("reap-now").Error).To(Equal("nope"))
+		})
+	})
+
+	It("rejects unknown commands", func() {
+		Expect(send("self-destruct").Error).To(Equal("unknown command: self-destruct"))
+	})
+
+	Context("when a socket was left behind", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(socketPath, nil, 0600)).To(Succeed())
+		})
+
+		It("replaces it", func() {
+			Expect(send("status").Volumes).To(Equal(2))
+		})
+	})
+})
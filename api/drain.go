@@ -0,0 +1,18 @@
+package api
+
+import "sync/atomic"
+
+// DrainState is shared between the public API and the control server. Once
+// draining, no new volumes are created; existing volumes keep working so
+// that in-flight builds can finish.
+type DrainState struct {
+	draining int32
+}
+
+func (state *DrainState) Drain() {
+	atomic.StoreInt32(&state.draining, 1)
+}
+
+func (state *DrainState) IsDraining() bool {
+	return atomic.LoadInt32(&state.draining) == 1
+}
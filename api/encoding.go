@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// encodeReadResponse writes the response of a read endpoint, as gob if the
+// request accepts it and as JSON otherwise. The gob body is encoded in terms
+// of the client's response types, as gob matches fields by name.
+func encodeReadResponse(w http.ResponseWriter, req *http.Request, jsonBody interface{}, gobBody interface{}) error {
+	if acceptsGob(req) {
+		w.Header().Set("Content-Type", baggageclaim.GobContentType)
+		return gob.NewEncoder(w).Encode(gobBody)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(jsonBody)
+}
+
+func acceptsGob(req *http.Request) bool {
+	for _, accept := range req.Header["Accept"] {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			if mediaType == baggageclaim.GobContentType && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding picks the codec to stream out with from the request's
+// Accept-Encoding, or nil for the plain stream. The coding with the highest
+// weight wins, the codecs registered first breaking ties. A wildcard only
+// accepts the plain stream, as clients that predate compressed streams may
+// send one. It reports false if nothing the request accepts is supported.
+func negotiateEncoding(req *http.Request) (volume.Codec, bool) {
+	weights := map[string]float64{}
+	for _, accept := range req.Header["Accept-Encoding"] {
+		for _, coding := range strings.Split(accept, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			weight := 1.0
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				parsed, err := strconv.ParseFloat(q, 64)
+				if err == nil {
+					weight = parsed
+				}
+			}
+
+			weights[name] = weight
+		}
+	}
+
+	if len(weights) == 0 {
+		return nil, true
+	}
+
+	var (
+		best       volume.Codec
+		bestWeight float64
+	)
+
+	for _, codec := range volume.Codecs() {
+		if weights[codec.Name()] > bestWeight {
+			best = codec
+			bestWeight = weights[codec.Name()]
+		}
+	}
+
+	identity, listed := weights["identity"]
+	if !listed {
+		identity = weights["*"]
+	}
+
+	if best != nil && bestWeight >= identity {
+		return best, true
+	}
+
+	return nil, identity > 0
+}
+
+// advertiseEncodings lists the codecs streams can be sent and received with,
+// so that clients can pick one they support as well.
+func advertiseEncodings(w http.ResponseWriter) {
+	var names []string
+	for _, codec := range volume.Codecs() {
+		names = append(names, codec.Name())
+	}
+
+	w.Header().Set("Accept-Encoding", strings.Join(names, ", "))
+}
+
+func volumeResponse(vol volume.Volume) baggageclaim.VolumeResponse {
+	return baggageclaim.VolumeResponse{
+		Handle:         vol.Handle,
+		Path:           vol.Path,
+		Properties:     baggageclaim.VolumeProperties(vol.Properties),
+		TTLInSeconds:   uint(vol.TTL),
+		ExpiresAt:      vol.ExpiresAt,
+		ParentHandle:   vol.ParentHandle,
+		PendingDestroy: vol.PendingDestroy,
+		Committed:      vol.Committed,
+		CommittedAt:    vol.CommittedAt,
+		Frozen:         vol.Frozen,
+		ReadOnly:       vol.ReadOnly,
+		MountOptions:   vol.MountOptions,
+		Encrypted:      vol.Encrypted,
+		LastAccessedAt: vol.LastAccessedAt,
+		CreatedAt:      vol.CreatedAt,
+		ModifiedAt:     vol.ModifiedAt,
+		Strategy:       vol.Strategy,
+		Digest:         vol.Digest,
+		Driver:         vol.Driver,
+		FilesystemType: vol.FilesystemType,
+
+		RenewTTLOnAccess: vol.RenewTTLOnAccess,
+
+		Deleted:   vol.Deleted,
+		DeletedAt: vol.DeletedAt,
+	}
+}
@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/concourse/baggageclaim"
+)
+
+// BenchmarkDecodeVolumes decodes a listing of 5000 volumes the way the
+// client does, in each of the encodings the read endpoints support.
+func BenchmarkDecodeVolumes(b *testing.B) {
+	volumes := make([]baggageclaim.VolumeResponse, 5000)
+	for i := range volumes {
+		volumes[i] = baggageclaim.VolumeResponse{
+			Handle:       fmt.Sprintf("handle-%d", i),
+			Path:         fmt.Sprintf("/volumes/live/handle-%d/volume", i),
+			Properties:   baggageclaim.VolumeProperties{"resource-type": "git", "version": fmt.Sprintf("%d", i)},
+			TTLInSeconds: 300,
+			ExpiresAt:    time.Now(),
+			Strategy:     "cow",
+		}
+	}
+
+	jsonBody := new(bytes.Buffer)
+	err := json.NewEncoder(jsonBody).Encode(volumes)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	gobBody := new(bytes.Buffer)
+	err = gob.NewEncoder(gobBody).Encode(volumes)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("json", func(b *testing.B) {
+		b.SetBytes(int64(jsonBody.Len()))
+
+		for i := 0; i < b.N; i++ {
+			var decoded []baggageclaim.VolumeResponse
+			err := json.NewDecoder(bytes.NewReader(jsonBody.Bytes())).Decode(&decoded)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("gob", func(b *testing.B) {
+		b.SetBytes(int64(gobBody.Len()))
+
+		for i := 0; i < b.N; i++ {
+			var decoded []baggageclaim.VolumeResponse
+			err := gob.NewDecoder(bytes.NewReader(gobBody.Bytes())).Decode(&decoded)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// how many events a stream may fall behind by before it is ended, so that
+// the client reconnects and knows to resync
+const eventsBuffer = 1024
+
+// how often a comment is sent on an idle event stream, so that proxies
+// don't time it out
+const eventsKeepAliveInterval = 15 * time.Second
+
+var ErrStreamingUnsupported = errors.New("streaming is not supported")
+
+type EventsServer struct {
+	logger lager.Logger
+
+	events            *volume.EventHub
+	keepAliveInterval time.Duration
+}
+
+func NewEventsServer(
+	logger lager.Logger,
+	events *volume.EventHub,
+	keepAliveInterval time.Duration,
+) *EventsServer {
+	return &EventsServer{
+		logger: logger,
+
+		events:            events,
+		keepAliveInterval: keepAliveInterval,
+	}
+}
+
+// StreamEvents streams the volumes' lifecycle events as server-sent events,
+// starting with a resync event, until the client goes away or falls too far
+// behind.
+func (server *EventsServer) StreamEvents(w http.ResponseWriter, req *http.Request) {
+	hLog := requestSession(req.Context(), server.logger, "stream-events")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		hLog.Info("streaming-unsupported")
+		RespondWithError(w, ErrStreamingUnsupported, http.StatusInternalServerError)
+		return
+	}
+
+	// subscribed before the resync is sent, so that nothing goes missing
+	// in between
+	events, unsubscribe := server.events.Subscribe(eventsBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	err := writeEvent(w, baggageclaim.EventResync, struct{}{})
+	if err != nil {
+		hLog.Info("failed-to-write-event", lager.Data{"error": err.Error()})
+		return
+	}
+
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(server.keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				hLog.Info("fell-behind")
+				return
+			}
+
+			err = writeEvent(w, string(event.Type), baggageclaim.VolumeEventResponse{
+				Handle:         event.Handle,
+				PreviousHandle: event.PreviousHandle,
+				Properties:     baggageclaim.VolumeProperties(event.Properties),
+				Reason:         string(event.Reason),
+				At:             event.At,
+			})
+
+		case <-keepAlive.C:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		}
+
+		if err != nil {
+			hLog.Info("failed-to-write-event", lager.Data{"error": err.Error()})
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim/reaper"
+)
+
+type DestroyFailureSource interface {
+	DestroyFailures() []reaper.DestroyFailure
+}
+
+type GCServer struct {
+	logger   lager.Logger
+	failures DestroyFailureSource
+}
+
+func NewGCServer(
+	logger lager.Logger,
+	failures DestroyFailureSource,
+) *GCServer {
+	return &GCServer{
+		logger:   logger,
+		failures: failures,
+	}
+}
+
+func (gs *GCServer) GetFailures(w http.ResponseWriter, req *http.Request) {
+	hLog := requestSession(req.Context(), gs.logger, "get-failures")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(gs.failures.DestroyFailures()); err != nil {
+		hLog.Error("failed-to-encode-failures", err)
+	}
+}
@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("GC Server", func() {
+	It("lists the volumes the reaper failed to destroy", func() {
+		gcServer := api.NewGCServer(
+			lagertest.NewTestLogger("gc-server"),
+			fakeDestroyFailures{
+				{
+					Handle:        "stuck",
+					Failures:      3,
+					FirstFailedAt: time.Unix(100, 0).UTC(),
+					LastFailedAt:  time.Unix(200, 0).UTC(),
+					LastError:     "device or resource busy",
+					NextAttemptAt: time.Unix(300, 0).UTC(),
+				},
+			},
+		)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/gc/failures", nil)
+		gcServer.GetFailures(recorder, request)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body).To(MatchJSON(`[{
+			"handle": "stuck",
+			"failures": 3,
+			"first_failed_at": "1970-01-01T00:01:40Z",
+			"last_failed_at": "1970-01-01T00:03:20Z",
+			"last_error": "device or resource busy",
+			"next_attempt_at": "1970-01-01T00:05:00Z",
+			"quarantined": false
+		}]`))
+	})
+
+	It("returns an empty list when nothing failed", func() {
+		gcServer := api.NewGCServer(lagertest.NewTestLogger("gc-server"), fakeDestroyFailures{})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/gc/failures", nil)
+		gcServer.GetFailures(recorder, request)
+
+		Expect(recorder.Body).To(MatchJSON(`[]`))
+	})
+})
@@ -0,0 +1,822 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/rpc"
+	"github.com/concourse/baggageclaim/volume"
+)
+
+var ErrNoStreamInHeader = errors.New("stream-in has no first message")
+
+// streamOutChunkSize is the most a single message of a stream-out carries.
+const streamOutChunkSize = 64 * 1024
+
+// GRPCServer serves the volume API over gRPC, on a listener of its own. Its
+// calls go through the same VolumeServer as those of the HTTP API do, so
+// that they share its volumes, drain state, and stream caps.
+//
+// Once signalled it takes no new calls, and waits up to the shutdown timeout
+// for those in flight before canceling them; canceled stream-ins are rolled
+// back before it exits.
+type GRPCServer struct {
+	logger          lager.Logger
+	listenAddr      string
+	service         *grpcVolumeService
+	shutdownTimeout time.Duration
+	tlsConfig       *tls.Config
+
+	// authToken, unless empty, is the bearer token every call must carry in
+	// its authorization metadata
+	authToken   string
+	tokenDigest [sha256.Size]byte
+}
+
+func NewGRPCServer(
+	logger lager.Logger,
+	listenAddr string,
+	volumeServer *VolumeServer,
+	shutdownTimeout time.Duration,
+	tlsConfig *tls.Config,
+	authToken string,
+) *GRPCServer {
+	return &GRPCServer{
+		logger:          logger,
+		listenAddr:      listenAddr,
+		service:         &grpcVolumeService{vs: volumeServer, logger: logger},
+		shutdownTimeout: shutdownTimeout,
+		tlsConfig:       tlsConfig,
+		authToken:       authToken,
+		tokenDigest:     sha256.Sum256([]byte(authToken)),
+	}
+}
+
+func (s *GRPCServer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return err
+	}
+
+	// canceled handlers are still waited for once the server is stopped, as
+	// a stream-in removes what it had extracted on its way out
+	opts := []grpc.ServerOption{grpc.WaitForHandlers(true)}
+
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(tagUnaryRequestID),
+		grpc.ChainStreamInterceptor(tagStreamRequestID),
+	)
+
+	if s.authToken != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(s.authorizeUnary),
+			grpc.ChainStreamInterceptor(s.authorizeStream),
+		)
+	}
+
+	server := grpc.NewServer(opts...)
+	rpc.RegisterBaggageclaimServer(server, s.service)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	close(ready)
+
+	select {
+	case err := <-serveErr:
+		return err
+
+	case <-signals:
+	}
+
+	logger := s.logger.Session("shutdown", lager.Data{"timeout": s.shutdownTimeout.String()})
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	logger.Info("waiting-for-calls")
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+
+	case <-timer.C:
+		logger.Info("timed-out")
+
+		server.Stop()
+
+		<-stopped
+
+		logger.Info("canceled-calls")
+
+		return nil
+	}
+
+	logger.Info("done")
+
+	return nil
+}
+
+func (s *GRPCServer) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !s.authorized(ctx, info.FullMethod) {
+		return nil, status.Error(codes.Unauthenticated, ErrUnauthorized.Error())
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *GRPCServer) authorizeStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !s.authorized(stream.Context(), info.FullMethod) {
+		return status.Error(codes.Unauthenticated, ErrUnauthorized.Error())
+	}
+
+	return handler(srv, stream)
+}
+
+// tagUnaryRequestID gives the call an ID, as NewRequestIDHandler gives HTTP
+// requests one, taken from or sent back in its x-request-id metadata.
+func tagUnaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID := grpcRequestID(ctx)
+
+	grpc.SetHeader(ctx, metadata.Pairs(baggageclaim.RequestIDHeader, requestID))
+
+	return handler(withRequestID(ctx, requestID), req)
+}
+
+func tagStreamRequestID(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	requestID := grpcRequestID(stream.Context())
+
+	stream.SetHeader(metadata.Pairs(baggageclaim.RequestIDHeader, requestID))
+
+	return handler(srv, &requestIDServerStream{
+		ServerStream: stream,
+		ctx:          withRequestID(stream.Context(), requestID),
+	})
+}
+
+// grpcRequestID returns the ID the call was sent with, or makes one up if it
+// wasn't sent with one fit to be logged.
+func grpcRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(baggageclaim.RequestIDHeader)
+	if len(values) == 1 && validRequestID(values[0]) {
+		return values[0]
+	}
+
+	return newRequestID()
+}
+
+// requestIDServerStream is a stream whose context carries its call's ID.
+type requestIDServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (stream *requestIDServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authorized tells whether the call carries the token as a bearer token in
+// its authorization metadata, as HTTP requests carry it in their
+// Authorization header.
+func (s *GRPCServer) authorized(ctx context.Context, method string) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("authorization")
+	if len(values) == 1 && bearerAuthorized(values[0], s.tokenDigest) {
+		return true
+	}
+
+	s.logger.Info("unauthorized", lager.Data{"method": method}, requestLogData(ctx))
+
+	return false
+}
+
+// grpcVolumeService implements the gRPC service with the VolumeServer, doing
+// what the HTTP route of the same name does.
+type grpcVolumeService struct {
+	rpc.UnimplementedBaggageclaimServer
+
+	vs     *VolumeServer
+	logger lager.Logger
+}
+
+func (s *grpcVolumeService) CreateVolume(ctx context.Context, req *rpc.CreateVolumeRequest) (*rpc.Volume, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "create-volume")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	if vs.drainState.IsDraining() {
+		hLog.Info("draining")
+		return nil, status.Error(codes.Unavailable, ErrDraining.Error())
+	}
+
+	request := baggageclaim.VolumeRequest{
+		Handle:              req.GetHandle(),
+		Properties:          baggageclaim.VolumeProperties(req.GetProperties()),
+		TTLInSeconds:        uint(req.GetTtlInSeconds()),
+		Privileged:          req.GetPrivileged(),
+		ExpectedSizeInBytes: req.GetExpectedSizeInBytes(),
+		MutationHeavy:       req.GetMutationHeavy(),
+		SizeInBytes:         req.GetSizeInBytes(),
+		ReadOnly:            req.GetReadOnly(),
+		MountOptions:        req.GetMountOptions(),
+		RenewTTLOnAccess:    req.GetRenewTtlOnAccess(),
+		IdempotencyKey:      req.GetIdempotencyKey(),
+		Encrypted:           req.GetEncrypted(),
+	}
+
+	// the strategerizer takes the strategy as the HTTP API is given it
+	if req.GetStrategy() != nil {
+		strategy, err := json.Marshal(req.GetStrategy())
+		if err != nil {
+			return nil, rpcError(ctx, hLog, "failed-to-encode-strategy", err, ErrCreateVolumeFailed)
+		}
+
+		request.Strategy = (*json.RawMessage)(&strategy)
+	}
+
+	hLog = hLog.WithData(lager.Data{
+		"ttl":        request.TTLInSeconds,
+		"privileged": request.Privileged,
+		"strategy":   req.GetStrategy(),
+		"size":       request.SizeInBytes,
+		"read-only":  request.ReadOnly,
+	})
+
+	volumeStrategy, err := vs.strategerizer.StrategyFor(request)
+	if err != nil {
+		hLog.Info("could-not-produce-strategy", lager.Data{"error": err.Error()})
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	createdVolume, err := vs.createVolume(hLog, volumeStrategy, request)
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-create", err, ErrCreateVolumeFailed)
+	}
+
+	hLog.Debug("created", lager.Data{"volume": createdVolume.Handle})
+
+	return rpcVolume(createdVolume), nil
+}
+
+func (s *grpcVolumeService) DestroyVolume(ctx context.Context, req *rpc.DestroyVolumeRequest) (*rpc.DestroyVolumeResponse, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "destroy", lager.Data{
+		"volume": req.GetHandle(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	opts := volume.DestroyOptions{
+		Reason:     volume.DestroyReasonManual,
+		Annotation: req.GetReason(),
+		LeaseToken: req.GetLeaseToken(),
+	}
+
+	var destroyed []string
+	var err error
+	if req.GetDryRun() {
+		destroyed, err = vs.volumeRepo.PlanDestroy(req.GetHandle(), opts, req.GetForce())
+	} else if req.GetForce() {
+		destroyed, err = vs.volumeRepo.DestroyVolumeAndDescendants(req.GetHandle(), opts)
+	} else {
+		err = vs.volumeRepo.DestroyVolume(req.GetHandle(), opts)
+	}
+
+	if err == volume.ErrVolumeDoesNotExist && req.GetMissingOk() && !req.GetDryRun() {
+		hLog.Info("volume-already-destroyed")
+		return &rpc.DestroyVolumeResponse{}, nil
+	}
+
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-destroy", err, ErrDestroyVolumeFailed)
+	}
+
+	hLog.Info("destroyed", lager.Data{"destroyed": destroyed, "dry-run": req.GetDryRun()})
+
+	return &rpc.DestroyVolumeResponse{Destroyed: destroyed}, nil
+}
+
+func (s *grpcVolumeService) ListVolumes(ctx context.Context, req *rpc.ListVolumesRequest) (*rpc.ListVolumesResponse, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "list-volumes")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	switch req.GetSort() {
+	case "", baggageclaim.SortByHandle, baggageclaim.SortByCreatedAt:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "volumes can only be sorted by "+baggageclaim.SortByHandle+" or "+baggageclaim.SortByCreatedAt+", not "+req.GetSort())
+	}
+
+	volumes, _, err := vs.volumeRepo.ListVolumes(volume.Properties(req.GetProperties()))
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-list-volumes", err, ErrListVolumesFailed)
+	}
+
+	count := len(volumes)
+
+	if req.GetLimit() != 0 || req.GetOffset() != 0 || req.GetSort() != "" {
+		volumes = pageVolumes(volumes, baggageclaim.ListVolumesOptions{
+			Limit:  int(req.GetLimit()),
+			Offset: int(req.GetOffset()),
+			Sort:   req.GetSort(),
+		})
+	}
+
+	response := &rpc.ListVolumesResponse{
+		Volumes: make([]*rpc.Volume, len(volumes)),
+		Count:   uint32(count),
+	}
+
+	for i, vol := range volumes {
+		response.Volumes[i] = rpcVolume(vol)
+	}
+
+	return response, nil
+}
+
+func (s *grpcVolumeService) GetVolume(ctx context.Context, req *rpc.GetVolumeRequest) (*rpc.Volume, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "get-volume", lager.Data{
+		"volume": req.GetHandle(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	vol, found, err := vs.volumeRepo.GetVolume(req.GetHandle())
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-get-volume", err, ErrGetVolumeFailed)
+	}
+
+	if !found {
+		hLog.Info("volume-not-found")
+		return nil, status.Error(codes.NotFound, volume.ErrVolumeDoesNotExist.Error())
+	}
+
+	return rpcVolume(vol), nil
+}
+
+func (s *grpcVolumeService) SetProperty(ctx context.Context, req *rpc.SetPropertyRequest) (*rpc.SetPropertyResponse, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "set-property", lager.Data{
+		"volume":   req.GetHandle(),
+		"property": req.GetName(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var expected *volume.PropertyExpectation
+	if req.GetExpectAbsent() {
+		expected = &volume.PropertyExpectation{Absent: true}
+	} else if req.Expected != nil {
+		expected = &volume.PropertyExpectation{Value: req.GetExpected()}
+	}
+
+	err := vs.volumeRepo.SetProperty(req.GetHandle(), req.GetName(), req.GetValue(), expected, req.GetLeaseToken())
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-set-property", err, ErrSetPropertyFailed)
+	}
+
+	return &rpc.SetPropertyResponse{}, nil
+}
+
+func (s *grpcVolumeService) SetTTL(ctx context.Context, req *rpc.SetTTLRequest) (*rpc.SetTTLResponse, error) {
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "set-ttl", lager.Data{
+		"volume": req.GetHandle(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	if req.ExpiresAt != nil && req.GetTtlInSeconds() != 0 {
+		return nil, status.Error(codes.InvalidArgument, ErrTTLAndExpiresAt.Error())
+	}
+
+	var err error
+	if req.ExpiresAt != nil {
+		err = vs.volumeRepo.SetExpiresAt(req.GetHandle(), req.GetExpiresAt().AsTime())
+	} else {
+		err = vs.volumeRepo.SetTTL(req.GetHandle(), uint(req.GetTtlInSeconds()))
+	}
+
+	if err != nil {
+		return nil, rpcError(ctx, hLog, "failed-to-set-ttl", err, ErrSetTTLFailed)
+	}
+
+	return &rpc.SetTTLResponse{}, nil
+}
+
+func (s *grpcVolumeService) StreamIn(stream rpc.Baggageclaim_StreamInServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, ErrNoStreamInHeader.Error())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "stream-in", lager.Data{
+		"volume": first.GetHandle(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	bytesPerSecond, err := s.bytesPerSecond(first.GetBytesPerSecond())
+	if err != nil {
+		hLog.Info("invalid-bytes-per-second", lager.Data{"bytes-per-second": first.GetBytesPerSecond()})
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	body := &streamInReader{stream: stream, chunk: first.GetChunk(), checksum: first.GetChecksum()}
+
+	opts := volume.StreamInOptions{
+		IdempotencyKey:  first.GetIdempotencyKey(),
+		SELinuxLabel:    first.GetSelinuxLabel(),
+		ContentEncoding: first.GetContentEncoding(),
+		Xattrs:          first.GetXattrs(),
+		Delta:           first.GetDelta(),
+		Layer:           first.GetLayer(),
+		BytesPerSecond:  bytesPerSecond,
+		LeaseToken:      first.GetLeaseToken(),
+		Checksum:        func() string { return body.checksum },
+	}
+
+	badStream, err := vs.volumeRepo.StreamIn(ctx, first.GetHandle(), first.GetPath(), body, opts)
+	if err == volume.ErrStreamInAlreadyApplied {
+		hLog.Info("already-applied")
+		return stream.SendAndClose(&rpc.StreamInResponse{AlreadyApplied: true})
+	}
+
+	if err != nil {
+		if body.err != nil && ctx.Err() == nil {
+			hLog.Info("failed-to-receive", lager.Data{"error": body.err.Error()})
+			return body.err
+		}
+
+		if badStream && ctx.Err() == nil && !isStreamInRequestError(err) {
+			hLog.Info("bad-stream-payload", lager.Data{"error": err.Error()})
+			return status.Error(codes.InvalidArgument, ErrStreamInFailed.Error())
+		}
+
+		return rpcError(ctx, hLog, "failed-to-stream-into-volume", err, ErrStreamInFailed)
+	}
+
+	response := &rpc.StreamInResponse{}
+
+	vol, found, err := vs.volumeRepo.GetVolume(first.GetHandle())
+	if err != nil {
+		hLog.Error("failed-to-get-digest", err)
+	} else if found {
+		response.Digest = vol.Digest
+	}
+
+	return stream.SendAndClose(response)
+}
+
+func (s *grpcVolumeService) StreamOut(req *rpc.StreamOutRequest, stream rpc.Baggageclaim_StreamOutServer) error {
+	ctx := stream.Context()
+
+	vs := s.vs.forRequest(ctx)
+
+	hLog := requestSession(ctx, s.logger, "stream-out", lager.Data{
+		"volume": req.GetHandle(),
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	bytesPerSecond, err := s.bytesPerSecond(req.GetBytesPerSecond())
+	if err != nil {
+		hLog.Info("invalid-bytes-per-second", lager.Data{"bytes-per-second": req.GetBytesPerSecond()})
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	opts := volume.StreamOutOptions{
+		Consistent:     req.GetConsistent(),
+		Xattrs:         req.GetXattrs(),
+		Sparse:         req.GetSparse(),
+		Canonical:      req.GetCanonical(),
+		BytesPerSecond: bytesPerSecond,
+	}
+
+	if req.ModifiedSince != nil {
+		opts.ModifiedSince = req.GetModifiedSince().AsTime()
+	}
+
+	switch format := volume.StreamOutFormat(req.GetFormat()); format {
+	case "", volume.StreamOutTar, volume.StreamOutFile:
+		opts.Format = format
+	default:
+		hLog.Info("invalid-format", lager.Data{"format": format})
+		return status.Error(codes.InvalidArgument, ErrInvalidStreamOutFormat.Error())
+	}
+
+	var streamedAs volume.StreamOutFormat
+	opts.StreamedAs = &streamedAs
+
+	dest := &streamOutWriter{stream: stream, format: &streamedAs}
+
+	err = vs.volumeRepo.StreamOut(ctx, req.GetHandle(), req.GetPath(), dest, opts)
+	if err == nil && !dest.sent {
+		// nothing is written for an empty file, but the format is still told
+		err = dest.send(nil)
+	}
+
+	if err != nil {
+		return rpcError(ctx, hLog, "failed-to-stream-out", err, ErrStreamOutFailed)
+	}
+
+	return nil
+}
+
+// bytesPerSecond is the cap on a call's stream: the server's, or the one
+// asked for if that is lower.
+func (s *grpcVolumeService) bytesPerSecond(requested int64) (int64, error) {
+	if requested == 0 {
+		return s.vs.streamBytesPerSecond, nil
+	}
+
+	if requested < 0 {
+		return 0, errors.New("bytes_per_second must not be negative")
+	}
+
+	return s.vs.capBytesPerSecond(requested), nil
+}
+
+// streamInReader reads the chunks of a stream-in as they are received, so
+// that the client is held back by flow control while the volume is slower to
+// take them than they are sent.
+type streamInReader struct {
+	stream rpc.Baggageclaim_StreamInServer
+	chunk  []byte
+
+	// checksum is the last one received
+	checksum string
+
+	// err is what failed receiving, other than the stream having ended
+	err error
+}
+
+func (r *streamInReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+
+			return 0, err
+		}
+
+		r.chunk = req.GetChunk()
+
+		if req.GetChecksum() != "" {
+			r.checksum = req.GetChecksum()
+		}
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
+// streamOutWriter sends what is written to it as the chunks of a stream-out,
+// each send waiting for the client to have room for it.
+type streamOutWriter struct {
+	stream rpc.Baggageclaim_StreamOutServer
+	format *volume.StreamOutFormat
+
+	sent bool
+}
+
+func (w *streamOutWriter) Write(p []byte) (int, error) {
+	total := len(p)
+
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > streamOutChunkSize {
+			chunk = chunk[:streamOutChunkSize]
+		}
+
+		err := w.send(chunk)
+		if err != nil {
+			return total - len(p), err
+		}
+
+		p = p[len(chunk):]
+	}
+
+	return total, nil
+}
+
+func (w *streamOutWriter) send(chunk []byte) error {
+	response := &rpc.StreamOutResponse{Chunk: chunk}
+
+	if !w.sent {
+		w.sent = true
+		response.Format = string(*w.format)
+	}
+
+	return w.stream.Send(response)
+}
+
+// isStreamInRequestError tells whether a stream-in failed for what was asked
+// of it rather than for what was streamed.
+func isStreamInRequestError(err error) bool {
+	switch err {
+	case volume.ErrVolumeDoesNotExist,
+		volume.ErrVolumeIsFrozen,
+		volume.ErrVolumeIsLeased,
+		volume.ErrInvalidSELinuxLabel,
+		volume.ErrUnsafeSubPath,
+		volume.ErrUnsupportedContentEncoding,
+		volume.ErrInvalidChecksum,
+		volume.ErrStreamChecksumMismatch:
+		return true
+	}
+
+	return false
+}
+
+// rpcError is the status a call fails with for the error, with the code
+// closest to the HTTP status the route of the same name responds with. Calls
+// whose context is done fail with its error instead, as whatever failed was
+// most likely only failing because of it. Unexpected errors are logged, and
+// only given as failed.
+func rpcError(ctx context.Context, hLog lager.Logger, action string, err error, failed error) error {
+	if ctx.Err() != nil || isCanceled(err) {
+		hLog.Info("canceled")
+
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+
+		return status.FromContextError(err).Err()
+	}
+
+	var code codes.Code
+
+	switch err {
+	case volume.ErrVolumeDoesNotExist:
+		code = codes.NotFound
+
+	case volume.ErrVolumeAlreadyExists,
+		volume.ErrIdempotencyKeyConflict:
+		code = codes.AlreadyExists
+
+	case volume.ErrPropertyConflict:
+		code = codes.Aborted
+
+	case volume.ErrVolumeHasChildren,
+		volume.ErrVolumeIsFrozen,
+		volume.ErrVolumeIsLeased:
+		code = codes.FailedPrecondition
+
+	case volume.ErrParentVolumeNotFound,
+		volume.ErrNoParentVolumeProvided,
+		volume.ErrParentVolumeIsView,
+		volume.ErrInvalidPropertyValue,
+		volume.ErrInvalidPropertyName,
+		volume.ErrPropertyValueTooLarge,
+		volume.ErrQuotasNotSupported,
+		volume.ErrReadOnlyNotSupported,
+		volume.ErrTmpfsNotSupported,
+		volume.ErrCopyOnWriteOfTmpfs,
+		volume.ErrUnknownStreamFormat,
+		volume.ErrInvalidSELinuxLabel,
+		volume.ErrUnsafeSubPath,
+		volume.ErrInvalidWhiteout,
+		volume.ErrInvalidLayerWhiteout,
+		volume.ErrUnsupportedContentEncoding,
+		volume.ErrNotARegularFile,
+		volume.ErrStreamOutOptionsNeedTar,
+		volume.ErrChecksumMismatch,
+		volume.ErrInvalidChecksum,
+		volume.ErrStreamChecksumMismatch,
+		volume.ErrInvalidFetchedArchive,
+		volume.ErrEncryptionNotEnabled,
+		volume.ErrEncryptionNotSupported,
+		volume.ErrStrategyNotEncryptable:
+		code = codes.InvalidArgument
+
+	case volume.ErrInsufficientInodes:
+		code = codes.ResourceExhausted
+
+	case volume.ErrTooManyStreams:
+		code = codes.Unavailable
+
+	default:
+		switch err.(type) {
+		case volume.MountOptionsError:
+			code = codes.InvalidArgument
+		case volume.FetchError:
+			code = codes.InvalidArgument
+			if err.(volume.FetchError).Temporary {
+				code = codes.Unavailable
+			}
+		case volume.NoSpaceError, volume.StreamTooLargeError:
+			code = codes.ResourceExhausted
+		default:
+			if os.IsNotExist(err) {
+				hLog.Info("source-path-not-found")
+				return status.Error(codes.NotFound, ErrStreamOutNotFound.Error())
+			}
+
+			hLog.Error(action, err)
+			return status.Error(codes.Internal, failed.Error())
+		}
+	}
+
+	hLog.Info(action, lager.Data{"error": err.Error()})
+
+	return status.Error(code, err.Error())
+}
+
+func rpcVolume(vol volume.Volume) *rpc.Volume {
+	response := volumeResponse(vol)
+
+	return &rpc.Volume{
+		Handle:           response.Handle,
+		Path:             response.Path,
+		Properties:       response.Properties,
+		TtlInSeconds:     uint32(response.TTLInSeconds),
+		ExpiresAt:        rpcTimestamp(response.ExpiresAt),
+		ParentHandle:     response.ParentHandle,
+		PendingDestroy:   response.PendingDestroy,
+		Committed:        response.Committed,
+		CommittedAt:      rpcTimestamp(response.CommittedAt),
+		Frozen:           response.Frozen,
+		ReadOnly:         response.ReadOnly,
+		MountOptions:     response.MountOptions,
+		LastAccessedAt:   rpcTimestamp(response.LastAccessedAt),
+		CreatedAt:        rpcTimestamp(response.CreatedAt),
+		ModifiedAt:       rpcTimestamp(response.ModifiedAt),
+		Strategy:         response.Strategy,
+		Digest:           response.Digest,
+		Driver:           response.Driver,
+		FilesystemType:   response.FilesystemType,
+		RenewTtlOnAccess: response.RenewTTLOnAccess,
+		Encrypted:        response.Encrypted,
+	}
+}
+
+// rpcTimestamp leaves times that are not set unset, rather than giving them
+// as the zero time.
+func rpcTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -0,0 +1,302 @@
+package api_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/rpc"
+	"github.com/concourse/baggageclaim/uidgid"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("GRPCServer", func() {
+	var (
+		volumeDir string
+		authToken string
+
+		process ifrit.Process
+		conn    *grpc.ClientConn
+		client  rpc.BaggageclaimClient
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		volumeDir, err = ioutil.TempDir("", fmt.Sprintf("baggageclaim_grpc_dir_%d", GinkgoParallelNode()))
+		Expect(err).NotTo(HaveOccurred())
+
+		authToken = ""
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		logger := lagertest.NewTestLogger("grpc-server")
+
+		fs, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumeDir, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		repo := volume.NewRepository(
+			logger,
+			fakeclock.NewFakeClock(time.Now()),
+			fs,
+			volume.NewLockManager(),
+			volume.NewPathLockManager(),
+			uidgid.NoopNamespacer{},
+			uidgid.NoopNamespacer{},
+			nil,
+			time.Minute,
+			volume.NoopDestroyAuditLog{},
+			0,
+			1,
+			nil,
+			volume.NewEventHub(),
+			volume.PropertyLimits{},
+			0,
+			volume.StreamLimits{},
+			0,
+		)
+
+		volumeServer := api.NewVolumeServer(logger, volume.NewStrategerizer(0, 0, 0), repo, 0, &api.DrainState{}, 0, api.UUIDHandleGenerator{})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		listenAddr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		process = ifrit.Invoke(api.NewGRPCServer(logger, listenAddr, volumeServer, time.Minute, nil, authToken))
+
+		conn, err = grpc.NewClient(listenAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+
+		client = rpc.NewBaggageclaimClient(conn)
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+
+		Expect(os.RemoveAll(volumeDir)).To(Succeed())
+	})
+
+	createVolume := func(handle string) *rpc.Volume {
+		vol, err := client.CreateVolume(ctx, &rpc.CreateVolumeRequest{
+			Handle:     handle,
+			Strategy:   map[string]string{"type": "empty"},
+			Properties: map[string]string{"some": "property"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return vol
+	}
+
+	It("creates, finds, changes, and destroys volumes", func() {
+		created := createVolume("some-handle")
+		Expect(created.GetHandle()).To(Equal("some-handle"))
+		Expect(created.GetProperties()).To(Equal(map[string]string{"some": "property"}))
+		Expect(created.GetCreatedAt()).NotTo(BeNil())
+
+		_, err := client.SetProperty(ctx, &rpc.SetPropertyRequest{Handle: "some-handle", Name: "other", Value: "value"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.SetTTL(ctx, &rpc.SetTTLRequest{Handle: "some-handle", TtlInSeconds: 60})
+		Expect(err).NotTo(HaveOccurred())
+
+		found, err := client.GetVolume(ctx, &rpc.GetVolumeRequest{Handle: "some-handle"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.GetProperties()).To(Equal(map[string]string{"some": "property", "other": "value"}))
+		Expect(found.GetTtlInSeconds()).To(Equal(uint32(60)))
+
+		createVolume("other-handle")
+
+		listed, err := client.ListVolumes(ctx, &rpc.ListVolumesRequest{
+			Properties: map[string]string{"some": "property"},
+			Limit:      1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed.GetCount()).To(Equal(uint32(2)))
+		Expect(listed.GetVolumes()).To(HaveLen(1))
+		Expect(listed.GetVolumes()[0].GetHandle()).To(Equal("other-handle"))
+
+		planned, err := client.DestroyVolume(ctx, &rpc.DestroyVolumeRequest{Handle: "some-handle", Force: true, DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(planned.GetDestroyed()).To(Equal([]string{"some-handle"}))
+
+		_, err = client.DestroyVolume(ctx, &rpc.DestroyVolumeRequest{Handle: "some-handle"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.GetVolume(ctx, &rpc.GetVolumeRequest{Handle: "some-handle"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+		_, err = client.DestroyVolume(ctx, &rpc.DestroyVolumeRequest{Handle: "some-handle", MissingOk: true})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails with the codes closest to the HTTP statuses", func() {
+		createVolume("some-handle")
+
+		_, err := client.CreateVolume(ctx, &rpc.CreateVolumeRequest{
+			Handle:   "some-handle",
+			Strategy: map[string]string{"type": "empty"},
+		})
+		Expect(status.Code(err)).To(Equal(codes.AlreadyExists))
+
+		_, err = client.CreateVolume(ctx, &rpc.CreateVolumeRequest{
+			Strategy: map[string]string{"type": "bogus"},
+		})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+		_, err = client.SetProperty(ctx, &rpc.SetPropertyRequest{Handle: "some-handle", Name: "some", Value: "new", ExpectAbsent: true})
+		Expect(status.Code(err)).To(Equal(codes.Aborted))
+
+		_, err = client.DestroyVolume(ctx, &rpc.DestroyVolumeRequest{Handle: "missing-handle"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("streams tars in and back out", func() {
+		createVolume("some-handle")
+
+		archive := &bytes.Buffer{}
+		tarWriter := tar.NewWriter(archive)
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tarWriter.Write([]byte("some-content"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tarWriter.Close()).To(Succeed())
+
+		streamIn, err := client.StreamIn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// only the first message says where to stream to
+		tarBytes := archive.Bytes()
+		Expect(streamIn.Send(&rpc.StreamInRequest{Handle: "some-handle", Path: "some-dir", Chunk: tarBytes[:100]})).To(Succeed())
+		Expect(streamIn.Send(&rpc.StreamInRequest{Chunk: tarBytes[100:]})).To(Succeed())
+
+		streamedIn, err := streamIn.CloseAndRecv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(streamedIn.GetAlreadyApplied()).To(BeFalse())
+
+		streamOut, err := client.StreamOut(ctx, &rpc.StreamOutRequest{Handle: "some-handle", Path: "some-dir/some-file"})
+		Expect(err).NotTo(HaveOccurred())
+
+		first, err := streamOut.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.GetFormat()).To(Equal("file"))
+
+		contents := bytes.NewBuffer(first.GetChunk())
+		for {
+			chunk, err := streamOut.Recv()
+			if err == io.EOF {
+				break
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			contents.Write(chunk.GetChunk())
+		}
+
+		Expect(contents.String()).To(Equal("some-content"))
+
+		streamOut, err = client.StreamOut(ctx, &rpc.StreamOutRequest{Handle: "some-handle", Path: "missing-file"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = streamOut.Recv()
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("checks the checksum sent with any message of a stream once it has been read", func() {
+		createVolume("some-handle")
+
+		archive := &bytes.Buffer{}
+		tarWriter := tar.NewWriter(archive)
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tarWriter.Write([]byte("some-content"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tarWriter.Close()).To(Succeed())
+
+		tarBytes := archive.Bytes()
+		sum := sha256.Sum256(tarBytes)
+		checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+		streamIn, err := client.StreamIn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(streamIn.Send(&rpc.StreamInRequest{Handle: "some-handle", Path: "some-dir", Chunk: tarBytes[:100], Checksum: checksum})).To(Succeed())
+		Expect(streamIn.Send(&rpc.StreamInRequest{Chunk: tarBytes[100:], Checksum: "sha256:" + strings.Repeat("0", 64)})).To(Succeed())
+
+		_, err = streamIn.CloseAndRecv()
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		Expect(status.Convert(err).Message()).To(Equal(volume.ErrStreamChecksumMismatch.Error()))
+
+		streamIn, err = client.StreamIn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(streamIn.Send(&rpc.StreamInRequest{Handle: "some-handle", Path: "some-dir", Chunk: tarBytes[:100]})).To(Succeed())
+		Expect(streamIn.Send(&rpc.StreamInRequest{Chunk: tarBytes[100:], Checksum: checksum})).To(Succeed())
+
+		_, err = streamIn.CloseAndRecv()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("responds with the request ID each call was sent with, or one made up for it", func() {
+		var header metadata.MD
+		_, err := client.ListVolumes(metadata.AppendToOutgoingContext(ctx, "x-request-id", "some-request-id"), &rpc.ListVolumesRequest{}, grpc.Header(&header))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header.Get("x-request-id")).To(Equal([]string{"some-request-id"}))
+
+		_, err = client.ListVolumes(ctx, &rpc.ListVolumesRequest{}, grpc.Header(&header))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header.Get("x-request-id")).To(HaveLen(1))
+		Expect(header.Get("x-request-id")[0]).NotTo(BeEmpty())
+
+		createVolume("some-handle")
+
+		streamOut, err := client.StreamOut(metadata.AppendToOutgoingContext(ctx, "x-request-id", "other-request-id"), &rpc.StreamOutRequest{Handle: "some-handle", Path: "."})
+		Expect(err).NotTo(HaveOccurred())
+
+		header, err = streamOut.Header()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header.Get("x-request-id")).To(Equal([]string{"other-request-id"}))
+	})
+
+	Context("when an auth token is set", func() {
+		BeforeEach(func() {
+			authToken = "some-token"
+		})
+
+		It("only serves calls that carry it", func() {
+			_, err := client.ListVolumes(ctx, &rpc.ListVolumesRequest{})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+			_, err = client.ListVolumes(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong-token"), &rpc.ListVolumesRequest{})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+			_, err = client.ListVolumes(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer some-token"), &rpc.ListVolumesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/clock"
+	uuid "github.com/nu7hatch/gouuid"
+)
+
+// HandleGenerator generates the handles of volumes that are created or
+// cloned without one of their own.
+type HandleGenerator interface {
+	Generate() (string, error)
+
+	// Taken is told of each generated handle that turned out to belong to an
+	// existing volume, before another is generated in its place.
+	Taken(handle string)
+}
+
+// UUIDHandleGenerator generates a random UUID for each handle.
+type UUIDHandleGenerator struct{}
+
+func (UUIDHandleGenerator) Generate() (string, error) {
+	handle, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	return handle.String(), nil
+}
+
+// Taken does nothing, as the next UUID is no more likely to be taken for it.
+func (UUIDHandleGenerator) Taken(string) {}
+
+// MonotonicHandleGenerator generates handles made of a prefix and a number
+// that goes up with each one. The numbers start from the time in nanoseconds,
+// so that they keep going up across restarts, and handles sort in the order
+// their volumes were made.
+type MonotonicHandleGenerator struct {
+	prefix string
+	clock  clock.Clock
+
+	lock sync.Mutex
+	last int64
+}
+
+func NewMonotonicHandleGenerator(prefix string, clock clock.Clock) *MonotonicHandleGenerator {
+	return &MonotonicHandleGenerator{
+		prefix: prefix,
+		clock:  clock,
+	}
+}
+
+func (generator *MonotonicHandleGenerator) Generate() (string, error) {
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+
+	next := generator.clock.Now().UnixNano()
+	if next <= generator.last {
+		next = generator.last + 1
+	}
+
+	generator.last = next
+
+	return generator.prefix + strconv.FormatInt(next, 10), nil
+}
+
+// Taken moves the numbers past that of the handle, which can happen once the
+// clock has been set back, so that they don't collide again with each of the
+// volumes made since.
+func (generator *MonotonicHandleGenerator) Taken(handle string) {
+	number, err := strconv.ParseInt(strings.TrimPrefix(handle, generator.prefix), 10, 64)
+	if err != nil {
+		return
+	}
+
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+
+	if number > generator.last {
+		generator.last = number
+	}
+}
@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("HandleGenerator", func() {
+	Describe("UUIDHandleGenerator", func() {
+		It("generates a different handle each time", func() {
+			generator := api.UUIDHandleGenerator{}
+
+			first, err := generator.Generate()
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := generator.Generate()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first).To(HaveLen(36))
+			Expect(second).NotTo(Equal(first))
+		})
+	})
+
+	Describe("MonotonicHandleGenerator", func() {
+		var (
+			fakeClock *fakeclock.FakeClock
+			generator *api.MonotonicHandleGenerator
+		)
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+			generator = api.NewMonotonicHandleGenerator("some-prefix-", fakeClock)
+		})
+
+		It("generates the prefixed time", func() {
+			Expect(generator.Generate()).To(Equal("some-prefix-123000000456"))
+
+			fakeClock.Increment(time.Second)
+			Expect(generator.Generate()).To(Equal("some-prefix-124000000456"))
+		})
+
+		It("keeps going up while the clock stands still or goes back", func() {
+			Expect(generator.Generate()).To(Equal("some-prefix-123000000456"))
+			Expect(generator.Generate()).To(Equal("some-prefix-123000000457"))
+
+			fakeClock.Increment(-time.Second)
+			Expect(generator.Generate()).To(Equal("some-prefix-123000000458"))
+		})
+
+		It("moves past a handle it is told is taken", func() {
+			generator.Taken("some-prefix-200000000000")
+			Expect(generator.Generate()).To(Equal("some-prefix-200000000001"))
+		})
+
+		It("ignores taken handles with numbers behind its own, or none at all", func() {
+			generator.Taken("some-prefix-100")
+			generator.Taken("some-prefix-bogus")
+			generator.Taken("other-prefix-200000000000")
+
+			Expect(generator.Generate()).To(Equal("some-prefix-123000000456"))
+		})
+	})
+})
@@ -3,11 +3,14 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"github.com/tedsuo/rata"
 
 	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/metrics"
 	"github.com/concourse/baggageclaim/volume"
 )
 
@@ -15,31 +18,118 @@ func NewHandler(
 	logger lager.Logger,
 	strategerizer volume.Strategerizer,
 	volumeRepo volume.Repository,
+	clock clock.Clock,
+	driverName string,
+	bodyReadTimeout time.Duration,
+	drainState *DrainState,
+	destroyFailures DestroyFailureSource,
+	registry *metrics.Registry,
+	filesystem volume.Filesystem,
+	minFreeBytes uint64,
+	events *volume.EventHub,
+	streamBytesPerSecond int64,
+	handleGenerator HandleGenerator,
 ) (http.Handler, error) {
+	infoServer := NewInfoServer(
+		logger.Session("info-server"),
+		driverName,
+	)
+
+	metricsServer := NewMetricsServer(
+		logger.Session("metrics-server"),
+		clock,
+		volumeRepo,
+		destroyFailures,
+		registry,
+		metricsCacheDuration,
+	)
+
+	healthServer := NewHealthServer(
+		logger.Session("health-server"),
+		filesystem,
+		minFreeBytes,
+		healthProbeTimeout,
+	)
+
+	eventsServer := NewEventsServer(
+		logger.Session("events-server"),
+		events,
+		eventsKeepAliveInterval,
+	)
+
+	gcServer := NewGCServer(
+		logger.Session("gc-server"),
+		destroyFailures,
+	)
+
 	volumeServer := NewVolumeServer(
 		logger.Session("volume-server"),
 		strategerizer,
 		volumeRepo,
+		bodyReadTimeout,
+		drainState,
+		streamBytesPerSecond,
+		handleGenerator,
 	)
 
 	handlers := rata.Handlers{
-		baggageclaim.CreateVolume:   http.HandlerFunc(volumeServer.CreateVolume),
-		baggageclaim.ListVolumes:    http.HandlerFunc(volumeServer.ListVolumes),
-		baggageclaim.GetVolume:      http.HandlerFunc(volumeServer.GetVolume),
-		baggageclaim.GetVolumeStats: http.HandlerFunc(volumeServer.GetVolumeStats),
-		baggageclaim.SetProperty:    http.HandlerFunc(volumeServer.SetProperty),
-		baggageclaim.SetTTL:         http.HandlerFunc(volumeServer.SetTTL),
-		baggageclaim.SetPrivileged:  http.HandlerFunc(volumeServer.SetPrivileged),
-		baggageclaim.StreamIn:       http.HandlerFunc(volumeServer.StreamIn),
-		baggageclaim.StreamOut:      http.HandlerFunc(volumeServer.StreamOut),
-		baggageclaim.DestroyVolume:  http.HandlerFunc(volumeServer.DestroyVolume),
+		baggageclaim.GetInfo:    http.HandlerFunc(infoServer.GetInfo),
+		baggageclaim.GetMetrics: http.HandlerFunc(metricsServer.GetMetrics),
+		baggageclaim.GetHealth:  http.HandlerFunc(healthServer.GetHealth),
+
+		baggageclaim.GetGCFailures: http.HandlerFunc(gcServer.GetFailures),
+
+		baggageclaim.CreateVolume:    http.HandlerFunc(volumeServer.CreateVolume),
+		baggageclaim.CloneVolume:     http.HandlerFunc(volumeServer.CloneVolume),
+		baggageclaim.RenameVolume:    http.HandlerFunc(volumeServer.RenameVolume),
+		baggageclaim.PromoteVolume:   http.HandlerFunc(volumeServer.PromoteVolume),
+		baggageclaim.RestoreVolume:   http.HandlerFunc(volumeServer.RestoreVolume),
+		baggageclaim.ListVolumes:     http.HandlerFunc(volumeServer.ListVolumes),
+		baggageclaim.GetVolume:       http.HandlerFunc(volumeServer.GetVolume),
+		baggageclaim.VolumeExists:    http.HandlerFunc(volumeServer.VolumeExists),
+		baggageclaim.GetVolumeStats:  http.HandlerFunc(volumeServer.GetVolumeStats),
+		baggageclaim.GetUsage:        http.HandlerFunc(volumeServer.GetUsage),
+		baggageclaim.StreamEvents:    http.HandlerFunc(eventsServer.StreamEvents),
+		baggageclaim.GetDigest:       http.HandlerFunc(volumeServer.GetDigest),
+		baggageclaim.GetManifest:     http.HandlerFunc(volumeServer.GetManifest),
+		baggageclaim.ListFiles:       http.HandlerFunc(volumeServer.ListFiles),
+		baggageclaim.GetChildren:     http.HandlerFunc(volumeServer.GetChildren),
+		baggageclaim.GetProperty:     http.HandlerFunc(volumeServer.GetProperty),
+		baggageclaim.SetProperty:     http.HandlerFunc(volumeServer.SetProperty),
+		baggageclaim.SetProperties:   http.HandlerFunc(volumeServer.SetProperties),
+		baggageclaim.DeleteProperty:  http.HandlerFunc(volumeServer.DeleteProperty),
+		baggageclaim.SetTTL:          http.HandlerFunc(volumeServer.SetTTL),
+		baggageclaim.SetPrivileged:   http.HandlerFunc(volumeServer.SetPrivileged),
+		baggageclaim.SetSELinuxLabel: http.HandlerFunc(volumeServer.SetSELinuxLabel),
+		baggageclaim.StreamIn:        http.HandlerFunc(volumeServer.StreamIn),
+		baggageclaim.StreamOut:       http.HandlerFunc(volumeServer.StreamOut),
+		baggageclaim.CopyIn:          http.HandlerFunc(volumeServer.CopyIn),
+		baggageclaim.CommitVolume:    http.HandlerFunc(volumeServer.CommitVolume),
+		baggageclaim.DiffVolumes:     http.HandlerFunc(volumeServer.DiffVolumes),
+		baggageclaim.TouchAccess:     http.HandlerFunc(volumeServer.TouchAccess),
+		baggageclaim.Materialize:     http.HandlerFunc(volumeServer.Materialize),
+		baggageclaim.AcquireLease:    http.HandlerFunc(volumeServer.AcquireLease),
+		baggageclaim.ReleaseLease:    http.HandlerFunc(volumeServer.ReleaseLease),
+		baggageclaim.DestroyVolume:   http.HandlerFunc(volumeServer.DestroyVolume),
+		baggageclaim.DestroyVolumes:  http.HandlerFunc(volumeServer.DestroyVolumes),
+
+		baggageclaim.DestroyVolumesWithProperties: http.HandlerFunc(volumeServer.DestroyVolumesWithProperties),
+	}
+
+	router, err := rata.NewRouter(baggageclaim.Routes, handlers)
+	if err != nil {
+		return nil, err
 	}
 
-	return rata.NewRouter(baggageclaim.Routes, handlers)
+	return NewRequestIDHandler(router), nil
 }
 
 type ErrorResponse struct {
 	Message string `json:"error"`
+
+	// Fields lists what is wrong with each field of the request, when that
+	// is what the error comes down to.
+	Fields []baggageclaim.FieldError `json:"fields,omitempty"`
 }
 
 func RespondWithError(w http.ResponseWriter, err error, statusCode ...int) {
@@ -55,3 +145,11 @@ func RespondWithError(w http.ResponseWriter, err error, statusCode ...int) {
 	errResponse := ErrorResponse{Message: err.Error()}
 	json.NewEncoder(w).Encode(errResponse)
 }
+
+// RespondWithFieldErrors responds with the error along with what is wrong
+// with each of the request's fields.
+func RespondWithFieldErrors(w http.ResponseWriter, err error, fields []baggageclaim.FieldError, statusCode int) {
+	w.WriteHeader(statusCode)
+	errResponse := ErrorResponse{Message: err.Error(), Fields: fields}
+	json.NewEncoder(w).Encode(errResponse)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// how long a health check waits for the write probe before calling the
+// filesystem unwritable
+const healthProbeTimeout = 5 * time.Second
+
+// The checks made by GetHealth, in the order they are made.
+const (
+	healthCheckMounted   = "mounted"
+	healthCheckFreeSpace = "free-space"
+	healthCheckWritable  = "writable"
+)
+
+var ErrWriteProbeTimedOut = errors.New("timed out writing to the volumes directory")
+var ErrWriteProbeSkipped = errors.New("not probed, as the volumes filesystem is out of space")
+
+type HealthServer struct {
+	logger lager.Logger
+
+	filesystem   volume.Filesystem
+	minFreeBytes uint64
+	probeTimeout time.Duration
+
+	probeL sync.Mutex
+	probe  *writeProbe
+}
+
+// writeProbe is a write to the volumes directory that may still be going.
+// Health checks made while one is wait on it rather than start another, so
+// that a filesystem that hangs writes doesn't pile them up.
+type writeProbe struct {
+	done chan struct{}
+	err  error
+}
+
+func NewHealthServer(
+	logger lager.Logger,
+	filesystem volume.Filesystem,
+	minFreeBytes uint64,
+	probeTimeout time.Duration,
+) *HealthServer {
+	return &HealthServer{
+		logger: logger,
+
+		filesystem:   filesystem,
+		minFreeBytes: minFreeBytes,
+		probeTimeout: probeTimeout,
+	}
+}
+
+// GetHealth checks that the volumes directory is mounted, that its filesystem
+// has space left, and that it can be written to. The write is not probed
+// once the filesystem is out of space, as it is bound to fail, and may hang
+// rather than fail on some filesystems.
+func (hs *HealthServer) GetHealth(w http.ResponseWriter, req *http.Request) {
+	hLog := requestSession(req.Context(), hs.logger, "get-health")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	response := baggageclaim.HealthResponse{
+		Status: baggageclaim.HealthOK,
+	}
+
+	check := func(name string, err error) {
+		result := baggageclaim.HealthCheck{Name: name}
+
+		if err != nil {
+			hLog.Info("check-failed", lager.Data{"check": name, "error": err.Error()})
+
+			result.Error = err.Error()
+			response.Status = baggageclaim.HealthDegraded
+		}
+
+		response.Checks = append(response.Checks, result)
+	}
+
+	check(healthCheckMounted, hs.filesystem.CheckMount())
+
+	freeSpaceErr := hs.checkFreeSpace()
+	check(healthCheckFreeSpace, freeSpaceErr)
+
+	if freeSpaceErr != nil {
+		check(healthCheckWritable, ErrWriteProbeSkipped)
+	} else {
+		check(healthCheckWritable, hs.probeWrite())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if response.Status != baggageclaim.HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (hs *HealthServer) checkFreeSpace() error {
+	free, err := hs.filesystem.FreeBytes()
+	if err != nil {
+		return err
+	}
+
+	if free == 0 {
+		return errors.New("volumes filesystem is full")
+	}
+
+	if free < hs.minFreeBytes {
+		return fmt.Errorf("%d bytes free, fewer than the %d required", free, hs.minFreeBytes)
+	}
+
+	return nil
+}
+
+func (hs *HealthServer) probeWrite() error {
+	hs.probeL.Lock()
+
+	probe := hs.probe
+	if probe == nil {
+		probe = &writeProbe{done: make(chan struct{})}
+		hs.probe = probe
+
+		go func() {
+			probe.err = hs.filesystem.ProbeWrite()
+			close(probe.done)
+
+			hs.probeL.Lock()
+			hs.probe = nil
+			hs.probeL.Unlock()
+		}()
+	}
+
+	hs.probeL.Unlock()
+
+	timer := time.NewTimer(hs.probeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-probe.done:
+		return probe.err
+
+	case <-timer.C:
+		return ErrWriteProbeTimedOut
+	}
+}
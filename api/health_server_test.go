@@ -0,0 +1,197 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+)
+
+var _ = Describe("Health Server", func() {
+	var (
+		fakeFilesystem *volumefakes.FakeFilesystem
+		healthServer   *api.HealthServer
+	)
+
+	BeforeEach(func() {
+		fakeFilesystem = new(volumefakes.FakeFilesystem)
+		fakeFilesystem.FreeBytesReturns(10*1024*1024, nil)
+
+		healthServer = api.NewHealthServer(
+			lagertest.NewTestLogger("health-server"),
+			fakeFilesystem,
+			1024*1024,
+			100*time.Millisecond,
+		)
+	})
+
+	getHealth := func() (int, baggageclaim.HealthResponse) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/health", nil)
+		healthServer.GetHealth(recorder, request)
+
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var health baggageclaim.HealthResponse
+		err := json.NewDecoder(recorder.Body).Decode(&health)
+		Expect(err).NotTo(HaveOccurred())
+
+		return recorder.Code, health
+	}
+
+	It("returns 200 when every check passes", func() {
+		code, health := getHealth()
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(health).To(Equal(baggageclaim.HealthResponse{
+			Status: baggageclaim.HealthOK,
+			Checks: []baggageclaim.HealthCheck{
+				{Name: "mounted"},
+				{Name: "free-space"},
+				{Name: "writable"},
+			},
+		}))
+
+		Expect(fakeFilesystem.ProbeWriteCallCount()).To(Equal(1))
+	})
+
+	Context("when the volumes directory is not mounted", func() {
+		BeforeEach(func() {
+			fakeFilesystem.CheckMountReturns(errors.New("not on btrfs"))
+		})
+
+		It("returns 503 naming the check", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Status).To(Equal(baggageclaim.HealthDegraded))
+			Expect(health.Checks[0]).To(Equal(baggageclaim.HealthCheck{Name: "mounted", Error: "not on btrfs"}))
+		})
+	})
+
+	Context("when there is less space free than required", func() {
+		BeforeEach(func() {
+			fakeFilesystem.FreeBytesReturns(1024, nil)
+		})
+
+		It("returns 503 without probing the write", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Status).To(Equal(baggageclaim.HealthDegraded))
+			Expect(health.Checks[1].Name).To(Equal("free-space"))
+			Expect(health.Checks[1].Error).NotTo(BeEmpty())
+			Expect(health.Checks[2]).To(Equal(baggageclaim.HealthCheck{Name: "writable", Error: api.ErrWriteProbeSkipped.Error()}))
+
+			Expect(fakeFilesystem.ProbeWriteCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the filesystem is full", func() {
+		BeforeEach(func() {
+			healthServer = api.NewHealthServer(lagertest.NewTestLogger("health-server"), fakeFilesystem, 0, time.Second)
+			fakeFilesystem.FreeBytesReturns(0, nil)
+		})
+
+		It("returns 503 even without a required amount of free space", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Checks[1]).To(Equal(baggageclaim.HealthCheck{Name: "free-space", Error: "volumes filesystem is full"}))
+		})
+	})
+
+	Context("when the write fails", func() {
+		BeforeEach(func() {
+			fakeFilesystem.ProbeWriteReturns(errors.New("read-only file system"))
+		})
+
+		It("returns 503 naming the check", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Checks[2]).To(Equal(baggageclaim.HealthCheck{Name: "writable", Error: "read-only file system"}))
+		})
+	})
+
+	Context("when the write hangs", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+
+			fakeFilesystem.ProbeWriteStub = func() error {
+				<-release
+				return nil
+			}
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("returns 503 once the probe times out, and waits on the same probe next time", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Checks[2]).To(Equal(baggageclaim.HealthCheck{Name: "writable", Error: api.ErrWriteProbeTimedOut.Error()}))
+
+			code, _ = getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+
+			Expect(fakeFilesystem.ProbeWriteCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("with a real filesystem", func() {
+		var volumesDir string
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "health-server")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			healthServer = api.NewHealthServer(lagertest.NewTestLogger("health-server"), filesystem, 0, time.Second)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("is healthy and leaves nothing behind", func() {
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(health.Status).To(Equal(baggageclaim.HealthOK))
+
+			entries, err := ioutil.ReadDir(volumesDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			names := []string{}
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+
+			Expect(names).To(ConsistOf("init", "live", "dead", "snapshots"))
+		})
+
+		It("is degraded once the live volumes directory is gone", func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+
+			code, health := getHealth()
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(health.Checks[0].Name).To(Equal("mounted"))
+			Expect(health.Checks[0].Error).NotTo(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/baggageclaim"
+)
+
+type InfoServer struct {
+	driverName string
+
+	logger lager.Logger
+}
+
+func NewInfoServer(
+	logger lager.Logger,
+	driverName string,
+) *InfoServer {
+	return &InfoServer{
+		driverName: driverName,
+		logger:     logger,
+	}
+}
+
+func (is *InfoServer) GetInfo(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	hLog := requestSession(req.Context(), is.logger, "get-info")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	info := baggageclaim.InfoResponse{
+		Driver: is.driverName,
+	}
+
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
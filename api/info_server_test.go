@@ -0,0 +1,62 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/metrics"
+	"github.com/concourse/baggageclaim/reaper"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+)
+
+var _ = Describe("Info Server", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		var err error
+
+		logger := lagertest.NewTestLogger("info-server")
+
+		handler, err = api.NewHandler(
+			logger,
+			volume.NewStrategerizer(0, 0, 0),
+			new(volumefakes.FakeRepository),
+			clock.NewClock(),
+			"some-driver",
+			0,
+			&api.DrainState{},
+			reaper.NewReaper(clock.NewClock(), new(volumefakes.FakeRepository), 0, reaper.RetryPolicy{}, 0, metrics.NewRegistry(), reaper.EvictionPolicy{}),
+			metrics.NewRegistry(),
+			new(volumefakes.FakeFilesystem),
+			0,
+			volume.NewEventHub(),
+			0,
+			api.UUIDHandleGenerator{},
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("getting info", func() {
+		It("returns the resolved driver", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/info", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var info baggageclaim.InfoResponse
+			err := json.NewDecoder(recorder.Body).Decode(&info)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Driver).To(Equal("some-driver"))
+		})
+	})
+})
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim/metrics"
+	"github.com/concourse/baggageclaim/volume"
+)
+
+const metricsCacheDuration = 15 * time.Second
+
+var (
+	volumeSizeBuckets = []float64{
+		1 << 20,   // 1MiB
+		10 << 20,  // 10MiB
+		100 << 20, // 100MiB
+		1 << 30,   // 1GiB
+		10 << 30,  // 10GiB
+		100 << 30, // 100GiB
+	}
+
+	volumeAgeBuckets = []float64{
+		60,     // 1m
+		600,    // 10m
+		3600,   // 1h
+		21600,  // 6h
+		86400,  // 1d
+		604800, // 1w
+	}
+
+	volumeTTLRemainingBuckets = []float64{
+		0,
+		60,    // 1m
+		600,   // 10m
+		3600,  // 1h
+		21600, // 6h
+		86400, // 1d
+	}
+)
+
+// MetricsServer exposes aggregates across all volumes in the Prometheus text
+// format, followed by the metrics in the registry. Nothing is labelled by
+// handle, so the number of series does not grow with the number of volumes.
+// Computing the aggregates walks every volume, so they are cached for a short
+// while; the registry is always written as it is.
+type MetricsServer struct {
+	logger        lager.Logger
+	clock         clock.Clock
+	volumeRepo    volume.Repository
+	failures      DestroyFailureSource
+	registry      *metrics.Registry
+	cacheDuration time.Duration
+
+	cacheL   sync.Mutex
+	cached   []byte
+	cachedAt time.Time
+}
+
+func NewMetricsServer(
+	logger lager.Logger,
+	clock clock.Clock,
+	volumeRepo volume.Repository,
+	failures DestroyFailureSource,
+	registry *metrics.Registry,
+	cacheDuration time.Duration,
+) *MetricsServer {
+	return &MetricsServer{
+		logger:        logger,
+		clock:         clock,
+		volumeRepo:    volumeRepo,
+		failures:      failures,
+		registry:      registry,
+		cacheDuration: cacheDuration,
+	}
+}
+
+func (ms *MetricsServer) GetMetrics(w http.ResponseWriter, req *http.Request) {
+	hLog := requestSession(req.Context(), ms.logger, "get-metrics")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	ms.cacheL.Lock()
+	defer ms.cacheL.Unlock()
+
+	now := ms.clock.Now()
+
+	if ms.cached == nil || now.Sub(ms.cachedAt) >= ms.cacheDuration {
+		metrics, err := ms.collect(now)
+		if err != nil {
+			hLog.Error("failed-to-collect-metrics", err)
+			RespondWithError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		ms.cached = metrics
+		ms.cachedAt = now
+	}
+
+	buf := bytes.NewBuffer(append([]byte(nil), ms.cached...))
+	ms.registry.Write(buf)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	if _, err := buf.WriteTo(w); err != nil {
+		hLog.Error("failed-to-write-metrics", err)
+	}
+}
+
+func (ms *MetricsServer) collect(now time.Time) ([]byte, error) {
+	volumes, _, err := ms.volumeRepo.ListVolumes(volume.Properties{})
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := metrics.NewHistogram("baggageclaim_volume_size_bytes", "Size of the volumes on disk.", volumeSizeBuckets)
+	ages := metrics.NewHistogram("baggageclaim_volume_age_seconds", "Time since the volumes were created.", volumeAgeBuckets)
+	ttlsRemaining := metrics.NewHistogram("baggageclaim_volume_ttl_remaining_seconds", "Time until the volumes with a TTL expire.", volumeTTLRemainingBuckets)
+
+	strategies := map[string]int{
+		volume.StrategyEmpty:       0,
+		volume.StrategyCopyOnWrite: 0,
+		volume.StrategyImport:      0,
+		volume.StrategyCopy:        0,
+		volume.StrategyView:        0,
+	}
+
+	for _, vol := range volumes {
+		// volumes may go away while collecting; the repository logs any
+		// other failure, and the volume is left out of the size histogram
+		stats, found, err := ms.volumeRepo.GetVolumeStats(vol.Handle)
+		if err == nil && found {
+			sizes.Observe(float64(stats.SizeInBytes))
+		}
+
+		if !vol.CreatedAt.IsZero() {
+			ages.Observe(now.Sub(vol.CreatedAt).Seconds())
+		}
+
+		if !vol.TTL.IsUnlimited() {
+			remaining := vol.ExpiresAt.Sub(now)
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			ttlsRemaining.Observe(remaining.Seconds())
+		}
+
+		strategy := vol.Strategy
+		if strategy == "" {
+			strategy = "unknown"
+		}
+
+		strategies[strategy]++
+	}
+
+	buf := new(bytes.Buffer)
+
+	sizes.Write(buf)
+	ages.Write(buf)
+	ttlsRemaining.Write(buf)
+
+	fmt.Fprintln(buf, "# HELP baggageclaim_volume_count Number of volumes.")
+	fmt.Fprintln(buf, "# TYPE baggageclaim_volume_count gauge")
+	fmt.Fprintf(buf, "baggageclaim_volume_count %d\n", len(volumes))
+
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(buf, "# HELP baggageclaim_volumes Number of volumes by the strategy they were created with.")
+	fmt.Fprintln(buf, "# TYPE baggageclaim_volumes gauge")
+
+	for _, name := range names {
+		fmt.Fprintf(buf, "baggageclaim_volumes{strategy=%q} %d\n", name, strategies[name])
+	}
+
+	var failing, quarantined int
+	for _, failure := range ms.failures.DestroyFailures() {
+		if failure.Quarantined {
+			quarantined++
+		} else {
+			failing++
+		}
+	}
+
+	fmt.Fprintln(buf, "# HELP baggageclaim_reaper_destroy_failures Volumes the reaper failed to destroy that still exist.")
+	fmt.Fprintln(buf, "# TYPE baggageclaim_reaper_destroy_failures gauge")
+	fmt.Fprintf(buf, "baggageclaim_reaper_destroy_failures{quarantined=\"false\"} %d\n", failing)
+	fmt.Fprintf(buf, "baggageclaim_reaper_destroy_failures{quarantined=\"true\"} %d\n", quarantined)
+
+	return buf.Bytes(), nil
+}
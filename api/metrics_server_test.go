@@ -0,0 +1,182 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/metrics"
+	"github.com/concourse/baggageclaim/reaper"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+)
+
+var _ = Describe("Metrics Server", func() {
+	var (
+		fakeRepository  *volumefakes.FakeRepository
+		fakeClock       *fakeclock.FakeClock
+		destroyFailures fakeDestroyFailures
+		registry        *metrics.Registry
+
+		metricsServer *api.MetricsServer
+	)
+
+	BeforeEach(func() {
+		fakeRepository = new(volumefakes.FakeRepository)
+		fakeClock = fakeclock.NewFakeClock(time.Unix(10000, 0))
+		destroyFailures = fakeDestroyFailures{
+			{Handle: "stuck"},
+			{Handle: "really-stuck", Quarantined: true},
+			{Handle: "also-really-stuck", Quarantined: true},
+		}
+
+		registry = metrics.NewRegistry()
+
+		fakeRepository.ListVolumesReturns(volume.Volumes{
+			{
+				Handle:    "small-cow",
+				TTL:       volume.TTL(60),
+				ExpiresAt: time.Unix(10030, 0),
+				CreatedAt: time.Unix(9970, 0),
+				Strategy:  volume.StrategyCopyOnWrite,
+			},
+			{
+				Handle:    "big-empty",
+				TTL:       volume.TTL(0),
+				CreatedAt: time.Unix(10000-7200, 0),
+				Strategy:  volume.StrategyEmpty,
+			},
+			{
+				Handle:    "expired-legacy",
+				TTL:       volume.TTL(60),
+				ExpiresAt: time.Unix(9000, 0),
+			},
+		}, nil, nil)
+
+		fakeRepository.GetVolumeStatsStub = func(handle string) (volume.VolumeStats, bool, error) {
+			switch handle {
+			case "small-cow":
+				return volume.VolumeStats{SizeInBytes: 512 << 10}, true, nil
+			case "big-empty":
+				return volume.VolumeStats{SizeInBytes: 2 << 30}, true, nil
+			default:
+				return volume.VolumeStats{}, false, nil
+			}
+		}
+
+		metricsServer = api.NewMetricsServer(
+			lagertest.NewTestLogger("metrics-server"),
+			fakeClock,
+			fakeRepository,
+			destroyFailures,
+			registry,
+			time.Minute,
+		)
+	})
+
+	scrape := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/metrics", nil)
+		metricsServer.GetMetrics(recorder, request)
+		return recorder
+	}
+
+	It("exposes histograms of volume sizes", func() {
+		recorder := scrape()
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain"))
+
+		body := recorder.Body.String()
+		Expect(body).To(ContainSubstring("# TYPE baggageclaim_volume_size_bytes histogram\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_size_bytes_bucket{le=\"1.048576e+06\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_size_bytes_bucket{le=\"1.073741824e+09\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_size_bytes_bucket{le=\"1.073741824e+10\"} 2\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_size_bytes_bucket{le=\"+Inf\"} 2\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_size_bytes_count 2\n"))
+	})
+
+	It("exposes histograms of volume ages, skipping volumes without a recorded creation", func() {
+		body := scrape().Body.String()
+		Expect(body).To(ContainSubstring("baggageclaim_volume_age_seconds_bucket{le=\"60\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_age_seconds_bucket{le=\"3600\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_age_seconds_bucket{le=\"21600\"} 2\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_age_seconds_sum 7230\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_age_seconds_count 2\n"))
+	})
+
+	It("exposes histograms of TTL remaining, counting expired volumes as zero", func() {
+		body := scrape().Body.String()
+		Expect(body).To(ContainSubstring("baggageclaim_volume_ttl_remaining_seconds_bucket{le=\"0\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_ttl_remaining_seconds_bucket{le=\"60\"} 2\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_ttl_remaining_seconds_count 2\n"))
+	})
+
+	It("exposes the number of volumes", func() {
+		body := scrape().Body.String()
+		Expect(body).To(ContainSubstring("# TYPE baggageclaim_volume_count gauge\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volume_count 3\n"))
+	})
+
+	It("exposes volume counts by strategy", func() {
+		body := scrape().Body.String()
+		Expect(body).To(ContainSubstring("baggageclaim_volumes{strategy=\"cow\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volumes{strategy=\"empty\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volumes{strategy=\"import\"} 0\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_volumes{strategy=\"unknown\"} 1\n"))
+	})
+
+	It("exposes the number of volumes the reaper failed to destroy", func() {
+		body := scrape().Body.String()
+		Expect(body).To(ContainSubstring("baggageclaim_reaper_destroy_failures{quarantined=\"false\"} 1\n"))
+		Expect(body).To(ContainSubstring("baggageclaim_reaper_destroy_failures{quarantined=\"true\"} 2\n"))
+	})
+
+	It("does not label anything by handle", func() {
+		Expect(scrape().Body.String()).NotTo(ContainSubstring("small-cow"))
+	})
+
+	It("caches the aggregates for the cache duration", func() {
+		scrape()
+		scrape()
+		Expect(fakeRepository.ListVolumesCallCount()).To(Equal(1))
+
+		fakeClock.Increment(time.Minute)
+
+		scrape()
+		Expect(fakeRepository.ListVolumesCallCount()).To(Equal(2))
+	})
+
+	It("exposes the metrics in the registry without caching them", func() {
+		created := registry.NewCounter("some_total", "Some things.")
+
+		created.Inc()
+		Expect(scrape().Body.String()).To(ContainSubstring("some_total 1\n"))
+
+		created.Inc()
+		Expect(scrape().Body.String()).To(ContainSubstring("some_total 2\n"))
+	})
+
+	Context("when listing the volumes fails", func() {
+		BeforeEach(func() {
+			fakeRepository.ListVolumesReturns(nil, nil, errors.New("nope"))
+		})
+
+		It("returns 500", func() {
+			Expect(scrape().Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
+
+type fakeDestroyFailures []reaper.DestroyFailure
+
+func (failures fakeDestroyFailures) DestroyFailures() []reaper.DestroyFailure {
+	return failures
+}
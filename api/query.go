@@ -3,11 +3,25 @@ package api
 import (
 	"errors"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/concourse/baggageclaim"
 	"github.com/concourse/baggageclaim/volume"
 )
 
+// the query parameters of a volume list that page through and order it
+// rather than filter it by property
+const (
+	limitParam  = "limit"
+	offsetParam = "offset"
+	sortParam   = "sort"
+)
+
+// includeDeletedParam lists deleted volumes along with the rest
+const includeDeletedParam = "includeDeleted"
+
 func ConvertQueryToProperties(values url.Values) (volume.Properties, error) {
 	properties := volume.Properties{}
 
@@ -22,3 +36,115 @@ func ConvertQueryToProperties(values url.Values) (volume.Properties, error) {
 
 	return properties, nil
 }
+
+// ConvertQueryToIncludeDeleted takes the includeDeleted parameter out of
+// values, returning whether it asks for deleted volumes to be listed too.
+func ConvertQueryToIncludeDeleted(values url.Values) (bool, error) {
+	value := values.Get(includeDeletedParam)
+	values.Del(includeDeletedParam)
+
+	if value == "" {
+		return false, nil
+	}
+
+	includeDeleted, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New(includeDeletedParam + " must be true or false: " + value)
+	}
+
+	return includeDeleted, nil
+}
+
+// ConvertQueryToListOptions takes the paging and sorting parameters out of
+// values, leaving only the properties to filter by, and returns whether any
+// were given.
+func ConvertQueryToListOptions(values url.Values) (baggageclaim.ListVolumesOptions, bool, error) {
+	var opts baggageclaim.ListVolumesOptions
+
+	given := false
+	for _, name := range []string{limitParam, offsetParam, sortParam} {
+		if _, found := values[name]; found {
+			given = true
+		}
+	}
+
+	if !given {
+		return opts, false, nil
+	}
+
+	limit, err := intParam(values, limitParam)
+	if err != nil {
+		return opts, false, err
+	}
+
+	if limit < 0 {
+		return opts, false, errors.New("limit must not be negative: " + values.Get(limitParam))
+	}
+
+	offset, err := intParam(values, offsetParam)
+	if err != nil {
+		return opts, false, err
+	}
+
+	if offset < 0 {
+		return opts, false, errors.New("offset must not be negative: " + values.Get(offsetParam))
+	}
+
+	order := values.Get(sortParam)
+	switch order {
+	case "", baggageclaim.SortByHandle, baggageclaim.SortByCreatedAt:
+	default:
+		return opts, false, errors.New("volumes can only be sorted by " + baggageclaim.SortByHandle + " or " + baggageclaim.SortByCreatedAt + ", not " + order)
+	}
+
+	values.Del(limitParam)
+	values.Del(offsetParam)
+	values.Del(sortParam)
+
+	return baggageclaim.ListVolumesOptions{
+		Limit:  limit,
+		Offset: offset,
+		Sort:   order,
+	}, true, nil
+}
+
+func intParam(values url.Values, name string) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New(name + " must be a number: " + value)
+	}
+
+	return n, nil
+}
+
+// pageVolumes orders the volumes as opts asks, by handle if it doesn't say,
+// and returns the page of them it asks for.
+func pageVolumes(volumes volume.Volumes, opts baggageclaim.ListVolumesOptions) volume.Volumes {
+	sorted := make(volume.Volumes, len(volumes))
+	copy(sorted, volumes)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if opts.Sort == baggageclaim.SortByCreatedAt && !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+
+		return sorted[i].Handle < sorted[j].Handle
+	})
+
+	if opts.Offset >= len(sorted) {
+		return volume.Volumes{}
+	}
+
+	sorted = sorted[opts.Offset:]
+
+	if opts.Limit > 0 && opts.Limit < len(sorted) {
+		sorted = sorted[:opts.Limit]
+	}
+
+	return sorted
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrRangeNotSatisfiable = errors.New("range is not satisfiable")
+
+// errRangeWritten stops a stream once all of the range has been written.
+var errRangeWritten = errors.New("range has been written")
+
+// errStreamShortened is for a stream that ended before the range did, the
+// volume having changed since it was sized.
+var errStreamShortened = errors.New("stream ended before the range")
+
+// byteRange is the one range of bytes asked for in a Range header: from
+// first to last, or the last suffix bytes.
+type byteRange struct {
+	first  int64
+	last   int64
+	suffix int64
+
+	open     bool
+	isSuffix bool
+}
+
+// parseByteRange parses a Range header asking for one range of bytes. It
+// returns false for headers that are to be ignored, in another unit or
+// asking for many ranges, and ErrRangeNotSatisfiable for malformed ones.
+func parseByteRange(header string) (byteRange, bool, error) {
+	const unit = "bytes="
+
+	if !strings.HasPrefix(header, unit) {
+		return byteRange{}, false, nil
+	}
+
+	spec := strings.TrimSpace(strings.TrimPrefix(header, unit))
+	if strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+
+	dash := strings.Index(spec, "-")
+	if dash < 0 {
+		return byteRange{}, false, ErrRangeNotSatisfiable
+	}
+
+	first, last := spec[:dash], spec[dash+1:]
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return byteRange{}, false, ErrRangeNotSatisfiable
+		}
+
+		return byteRange{suffix: suffix, isSuffix: true}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false, ErrRangeNotSatisfiable
+	}
+
+	if last == "" {
+		return byteRange{first: start, open: true}, true, nil
+	}
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return byteRange{}, false, ErrRangeNotSatisfiable
+	}
+
+	return byteRange{first: start, last: end}, true, nil
+}
+
+// resolve returns where the range starts in a stream of the size and how
+// many bytes of it there are, which is always at least one.
+func (r byteRange) resolve(size int64) (int64, int64, error) {
+	if r.isSuffix {
+		if r.suffix == 0 || size == 0 {
+			return 0, 0, ErrRangeNotSatisfiable
+		}
+
+		if r.suffix > size {
+			return 0, size, nil
+		}
+
+		return size - r.suffix, r.suffix, nil
+	}
+
+	if r.first >= size {
+		return 0, 0, ErrRangeNotSatisfiable
+	}
+
+	last := r.last
+	if r.open || last >= size {
+		last = size - 1
+	}
+
+	return r.first, last - r.first + 1, nil
+}
+
+// rangeWriter writes only the range of what is written to it to the
+// response, answering 206 once it gets to it. Once the range has been
+// written, it fails further writes to stop the stream.
+type rangeWriter struct {
+	http.ResponseWriter
+
+	start  int64
+	length int64
+	size   int64
+
+	skipped   int64
+	written   int64
+	wroteBody bool
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	total := len(p)
+
+	if skip := w.start - w.skipped; skip > 0 {
+		if int64(len(p)) <= skip {
+			w.skipped += int64(len(p))
+			return total, nil
+		}
+
+		w.skipped += skip
+		p = p[skip:]
+	}
+
+	left := w.length - w.written
+	if left == 0 {
+		return 0, errRangeWritten
+	}
+
+	if int64(len(p)) > left {
+		p = p[:left]
+	}
+
+	if !w.wroteBody {
+		w.wroteBody = true
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", w.start, w.start+w.length-1, w.size))
+
+		// trailers can only follow a chunked body
+		if w.Header().Get("Trailer") == "" {
+			w.Header().Set("Content-Length", strconv.FormatInt(w.length, 10))
+		}
+
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	if err != nil {
+		return n, err
+	}
+
+	return total, nil
+}
+
+// done tells whether all of the range has been written.
+func (w *rangeWriter) done() bool {
+	return w.written == w.length
+}
+
+// sizeWriter counts what is written to it, to size a stream.
+type sizeWriter struct {
+	size int64
+}
+
+func (w *sizeWriter) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	uuid "github.com/nu7hatch/gouuid"
+
+	"github.com/concourse/baggageclaim"
+)
+
+// maxRequestIDLength is the longest request ID taken from a client, so that
+// one can't bloat every line logged for its request.
+const maxRequestIDLength = 200
+
+type requestIDKey struct{}
+
+type requestIDHandler struct {
+	handler http.Handler
+}
+
+// NewRequestIDHandler wraps the handler so that every request has an ID,
+// either the one it was sent with or one made up for it, which is sent back
+// in the response and can be had from its context with RequestID.
+func NewRequestIDHandler(handler http.Handler) http.Handler {
+	return &requestIDHandler{
+		handler: handler,
+	}
+}
+
+func (h *requestIDHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	requestID := req.Header.Get(baggageclaim.RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = newRequestID()
+	}
+
+	w.Header().Set(baggageclaim.RequestIDHeader, requestID)
+
+	h.handler.ServeHTTP(w, req.WithContext(withRequestID(req.Context(), requestID)))
+}
+
+// RequestID returns the ID of the request the context is for, or "" if it
+// doesn't have one.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// requestSession starts a session of the logger for the request the context
+// is for, tagged with its ID.
+func requestSession(ctx context.Context, logger lager.Logger, task string, data ...lager.Data) lager.Logger {
+	return logger.Session(task, append(data, requestLogData(ctx))...)
+}
+
+// requestLogData is the data that tags what is logged for the request the
+// context is for.
+func requestLogData(ctx context.Context) lager.Data {
+	requestID := RequestID(ctx)
+	if requestID == "" {
+		return lager.Data{}
+	}
+
+	return lager.Data{"request-id": requestID}
+}
+
+// validRequestID tells whether the ID a client sent is fit to be logged.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	requestID, err := uuid.NewV4()
+	if err != nil {
+		// there's no request ID without randomness, and nothing that
+		// depends on there being one
+		return ""
+	}
+
+	return requestID.String()
+}
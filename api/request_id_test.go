@@ -0,0 +1,69 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim"
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("Request ID Handler", func() {
+	var (
+		handledID string
+
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		handledID = ""
+
+		handler = api.NewRequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handledID = api.RequestID(req.Context())
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/volumes", nil)
+		if requestID != "" {
+			request.Header.Set(baggageclaim.RequestIDHeader, requestID)
+		}
+
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("serves the request with the ID it was sent with, and responds with it", func() {
+		recorder := serve("some-request-id")
+		Expect(handledID).To(Equal("some-request-id"))
+		Expect(recorder.Header().Get(baggageclaim.RequestIDHeader)).To(Equal("some-request-id"))
+	})
+
+	It("makes one up for a request without one", func() {
+		recorder := serve("")
+		Expect(handledID).NotTo(BeEmpty())
+		Expect(recorder.Header().Get(baggageclaim.RequestIDHeader)).To(Equal(handledID))
+
+		serve("")
+		Expect(handledID).NotTo(Equal(recorder.Header().Get(baggageclaim.RequestIDHeader)))
+	})
+
+	for _, unfit := range []string{"some request id", "some-request-id\x1b[31m", strings.Repeat("a", 201)} {
+		unfit := unfit
+
+		It(fmt.Sprintf("makes one up in place of %q", unfit), func() {
+			recorder := serve(unfit)
+			Expect(handledID).NotTo(BeEmpty())
+			Expect(handledID).NotTo(Equal(unfit))
+			Expect(recorder.Header().Get(baggageclaim.RequestIDHeader)).To(Equal(handledID))
+		})
+	}
+})
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim"
+)
+
+var ErrShuttingDown = errors.New("shutting down; not starting streams or creating or destroying volumes")
+
+// Server serves the API over HTTP. When signalled it refuses new streams,
+// creates, and destroys with 503, and waits up to the shutdown timeout for
+// in-flight stream-ins, stream-outs, and copy-ins to finish. Streams still going after
+// that are canceled, so that stream-ins roll back what they had written, and
+// their connections closed once they have. Event streams, which would never
+// finish on their own, are ended as soon as it is signalled.
+//
+// With a TLS config the API is served over HTTPS, and otherwise over plain
+// HTTP.
+type Server struct {
+	logger          lager.Logger
+	listenAddr      string
+	handler         http.Handler
+	shutdownTimeout time.Duration
+	tlsConfig       *tls.Config
+
+	streamsL     sync.Mutex
+	streams      int
+	shuttingDown bool
+	streamsDone  chan struct{}
+
+	stopEventStreams chan struct{}
+}
+
+func NewServer(
+	logger lager.Logger,
+	listenAddr string,
+	handler http.Handler,
+	shutdownTimeout time.Duration,
+	tlsConfig *tls.Config,
+) *Server {
+	return &Server{
+		logger:          logger,
+		listenAddr:      listenAddr,
+		handler:         handler,
+		shutdownTimeout: shutdownTimeout,
+		tlsConfig:       tlsConfig,
+
+		stopEventStreams: make(chan struct{}),
+	}
+}
+
+func (s *Server) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return err
+	}
+
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+
+	// every request's context is derived from this, so that the streams
+	// outlasting the shutdown timeout can be canceled together
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	server := &http.Server{
+		Handler:           http.HandlerFunc(s.serveHTTP),
+		ReadHeaderTimeout: 2 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return requestsCtx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	close(ready)
+
+	select {
+	case err := <-serveErr:
+		return err
+
+	case <-signals:
+	}
+
+	logger := s.logger.Session("shutdown", lager.Data{"timeout": s.shutdownTimeout.String()})
+
+	deadline := time.Now().Add(s.shutdownTimeout)
+
+	streamsDone := s.shutDown()
+
+	logger.Info("waiting-for-streams", lager.Data{"streams": s.inFlightStreams()})
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-streamsDone:
+
+	case <-timer.C:
+		logger.Info("timed-out", lager.Data{"streams": s.inFlightStreams()})
+
+		cancelRequests()
+
+		// closing the connections unblocks streams stuck reading from or
+		// writing to them; their handlers are still waited for, as a
+		// stream-in removes what it had extracted on its way out
+		err := server.Close()
+
+		<-streamsDone
+
+		logger.Info("canceled-streams")
+
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	err = server.Shutdown(ctx)
+	if err == context.DeadlineExceeded {
+		logger.Info("timed-out-waiting-for-requests")
+		return server.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger.Info("done")
+
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	route := routeOf(req)
+
+	if route == baggageclaim.StreamEvents {
+		if s.isShuttingDown() {
+			s.refuse(w, req)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		go func() {
+			select {
+			case <-s.stopEventStreams:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		req = req.WithContext(ctx)
+	} else if baggageclaim.StreamRoutes[route] {
+		if !s.startStream() {
+			s.refuse(w, req)
+			return
+		}
+
+		defer s.finishStream()
+	} else if baggageclaim.CreateOrDestroyRoutes[route] && s.isShuttingDown() {
+		s.refuse(w, req)
+		return
+	}
+
+	s.handler.ServeHTTP(w, req)
+}
+
+func (s *Server) refuse(w http.ResponseWriter, req *http.Request) {
+	s.logger.Info("refused-while-shutting-down", lager.Data{"method": req.Method, "path": req.URL.Path})
+	RespondWithError(w, ErrShuttingDown, http.StatusServiceUnavailable)
+}
+
+func (s *Server) startStream() bool {
+	s.streamsL.Lock()
+	defer s.streamsL.Unlock()
+
+	if s.shuttingDown {
+		return false
+	}
+
+	s.streams++
+
+	return true
+}
+
+func (s *Server) finishStream() {
+	s.streamsL.Lock()
+	defer s.streamsL.Unlock()
+
+	s.streams--
+
+	if s.streams == 0 && s.streamsDone != nil {
+		close(s.streamsDone)
+		s.streamsDone = nil
+	}
+}
+
+// shutDown refuses further streams, returning a channel that is closed once
+// the in-flight ones have finished.
+func (s *Server) shutDown() <-chan struct{} {
+	s.streamsL.Lock()
+	defer s.streamsL.Unlock()
+
+	s.shuttingDown = true
+
+	close(s.stopEventStreams)
+
+	done := make(chan struct{})
+	if s.streams == 0 {
+		close(done)
+	} else {
+		s.streamsDone = done
+	}
+
+	return done
+}
+
+func (s *Server) isShuttingDown() bool {
+	s.streamsL.Lock()
+	defer s.streamsL.Unlock()
+
+	return s.shuttingDown
+}
+
+func (s *Server) inFlightStreams() int {
+	s.streamsL.Lock()
+	defer s.streamsL.Unlock()
+
+	return s.streams
+}
+
+// routeOf returns the name of the route the request is for, found as the
+// router finds it: the first route with the request's method whose path
+// matches, with GET routes also taking HEAD requests. It returns "" if there
+// is none.
+func routeOf(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+
+	for _, route := range baggageclaim.Routes {
+		if route.Method != req.Method && !(route.Method == "GET" && req.Method == "HEAD") {
+			continue
+		}
+
+		if pathMatches(strings.Split(route.Path, "/"), segments) {
+			return route.Name
+		}
+	}
+
+	return ""
+}
+
+// pathMatches returns whether the segments of a path match those of a route's
+// path, in which a :param segment matches any one segment.
+func pathMatches(routeSegments []string, segments []string) bool {
+	if len(routeSegments) != len(segments) {
+		return false
+	}
+
+	for i, routeSegment := range routeSegments {
+		if strings.HasPrefix(routeSegment, ":") {
+			continue
+		}
+
+		if routeSegment != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,376 @@
+package api_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		logger          *lagertest.TestLogger
+		listenAddr      string
+		shutdownTimeout time.Duration
+		streamPath      string
+
+		streaming chan struct{}
+		release   chan struct{}
+		canceled  chan struct{}
+
+		process  ifrit.Process
+		response chan error
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("server")
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		listenAddr = listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		shutdownTimeout = time.Minute
+		streamPath = "/volumes/some-handle/stream-out"
+
+		streaming = make(chan struct{})
+		release = make(chan struct{})
+		canceled = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		// the server may outlive the test, so it must not see the next one's
+		streaming, release, canceled, streamPath := streaming, release, canceled, streamPath
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != streamPath {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			close(streaming)
+
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				close(canceled)
+			}
+		})
+
+		process = ifrit.Invoke(api.NewServer(logger, listenAddr, handler, shutdownTimeout, nil))
+
+		responses := make(chan error, 1)
+		response = responses
+
+		req, err := http.NewRequest("PUT", fmt.Sprintf("http://%s%s", listenAddr, streamPath), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		go func() {
+			resp, err := http.DefaultClient.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			responses <- err
+		}()
+
+		Eventually(streaming).Should(BeClosed())
+
+		process.Signal(os.Interrupt)
+	})
+
+	It("waits for in-flight streams before exiting", func() {
+		Consistently(process.Wait()).ShouldNot(Receive())
+
+		close(release)
+
+		Eventually(response).Should(Receive(BeNil()))
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	Context("when a copy-in is in flight", func() {
+		BeforeEach(func() {
+			streamPath = "/volumes/some-handle/copy-in"
+		})
+
+		It("waits for it as for any other stream", func() {
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			close(release)
+
+			Eventually(response).Should(Receive(BeNil()))
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+		})
+	})
+
+	It("refuses new streams, creates, and destroys meanwhile", func() {
+		request := func(method string, path string) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", listenAddr, path), nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+
+			return resp.StatusCode
+		}
+
+		Eventually(func() int {
+			return request("PUT", "/volumes/other-handle/stream-in")
+		}).Should(Equal(http.StatusServiceUnavailable))
+
+		Expect(request("PUT", "/volumes/other-handle/stream-out")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("PUT", "/volumes/other-handle/copy-in")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("POST", "/volumes")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("POST", "/volumes/other-handle/clone")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("DELETE", "/volumes/other-handle")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("POST", "/volumes/destroy")).To(Equal(http.StatusServiceUnavailable))
+		Expect(request("DELETE", "/volumes?build-id=42")).To(Equal(http.StatusServiceUnavailable))
+
+		Expect(request("GET", "/volumes/other-handle")).To(Equal(http.StatusOK))
+		Expect(request("DELETE", "/volumes/other-handle/properties/some-property")).To(Equal(http.StatusOK))
+
+		close(release)
+	})
+
+	It("stops accepting connections once the streams are done", func() {
+		close(release)
+
+		Eventually(response).Should(Receive(BeNil()))
+
+		Eventually(func() error {
+			conn, err := net.Dial("tcp", listenAddr)
+			if err == nil {
+				conn.Close()
+			}
+
+			return err
+		}).Should(HaveOccurred())
+	})
+
+	Context("when the streams outlast the shutdown timeout", func() {
+		BeforeEach(func() {
+			shutdownTimeout = 100 * time.Millisecond
+		})
+
+		It("cancels them, closes their connections, and logs how many were left", func() {
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(canceled).To(BeClosed())
+
+			Eventually(response).Should(Receive())
+
+			Expect(logger.LogMessages()).To(ContainElement("server.shutdown.timed-out"))
+
+			for _, log := range logger.Logs() {
+				if log.Message == "server.shutdown.timed-out" {
+					Expect(log.Data["streams"]).To(BeEquivalentTo(1))
+				}
+			}
+
+			Expect(logger.LogMessages()).To(ContainElement("server.shutdown.canceled-streams"))
+		})
+	})
+})
+
+var _ = Describe("Server with event streams", func() {
+	It("ends them as soon as it is signalled", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		listenAddr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		ended := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+
+			<-req.Context().Done()
+			close(ended)
+		})
+
+		process := ifrit.Invoke(api.NewServer(lagertest.NewTestLogger("server"), listenAddr, handler, time.Minute, nil))
+
+		resp, err := http.Get(fmt.Sprintf("http://%s/volumes/events", listenAddr))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		process.Signal(os.Interrupt)
+
+		Eventually(ended).Should(BeClosed())
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+})
+
+var _ = Describe("Server over TLS", func() {
+	var (
+		listenAddr string
+		tlsConfig  *tls.Config
+
+		ca         *testCA
+		serverCert tls.Certificate
+
+		reached chan struct{}
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		listenAddr = listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		ca = newTestCA()
+		serverCert = ca.issue(x509.ExtKeyUsageServerAuth)
+
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			MinVersion:   tls.VersionTLS12,
+		}
+
+		reached = make(chan struct{}, 1)
+	})
+
+	JustBeforeEach(func() {
+		reached := reached
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			reached <- struct{}{}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		process = ifrit.Invoke(api.NewServer(lagertest.NewTestLogger("server"), listenAddr, handler, time.Second, tlsConfig))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	get := func(clientCerts ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      ca.pool(),
+					Certificates: clientCerts,
+				},
+			},
+		}
+
+		return client.Get(fmt.Sprintf("https://%s/volumes", listenAddr))
+	}
+
+	It("serves the API over HTTPS", func() {
+		resp, err := get()
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.TLS).NotTo(BeNil())
+	})
+
+	It("does not serve plain HTTP", func() {
+		resp, err := http.Get(fmt.Sprintf("http://%s/volumes", listenAddr))
+		if err == nil {
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		}
+
+		Expect(reached).NotTo(Receive())
+	})
+
+	Context("when client certificates are required", func() {
+		BeforeEach(func() {
+			tlsConfig.ClientCAs = ca.pool()
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		})
+
+		It("serves clients presenting a certificate signed by the CA", func() {
+			resp, err := get(ca.issue(x509.ExtKeyUsageClientAuth))
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("refuses clients without a certificate before reaching the handler", func() {
+			_, err := get()
+			Expect(err).To(HaveOccurred())
+			Expect(reached).NotTo(Receive())
+		})
+
+		It("refuses clients with a certificate signed by another CA", func() {
+			_, err := get(newTestCA().issue(x509.ExtKeyUsageClientAuth))
+			Expect(err).To(HaveOccurred())
+			Expect(reached).NotTo(Receive())
+		})
+	})
+})
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA() *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "baggageclaim-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue signs a certificate for 127.0.0.1 with the given usage.
+func (ca *testCA) issue(usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
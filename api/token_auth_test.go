@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("Token Auth Handler", func() {
+	var (
+		exemptHealth bool
+		handled      int
+
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		exemptHealth = false
+		handled = 0
+	})
+
+	JustBeforeEach(func() {
+		wrapped := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handled++
+			w.WriteHeader(http.StatusOK)
+		})
+
+		handler = api.NewTokenAuthHandler(lagertest.NewTestLogger("token-auth"), wrapped, "some-token", exemptHealth)
+	})
+
+	serve := func(method string, path string, authorization string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("serves requests presenting the token", func() {
+		recorder := serve("DELETE", "/volumes/some-handle", "Bearer some-token")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(handled).To(Equal(1))
+	})
+
+	It("accepts the scheme in any case", func() {
+		recorder := serve("GET", "/volumes", "bearer some-token")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	expectUnauthorized := func(recorder *httptest.ResponseRecorder) {
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+
+		var errResponse api.ErrorResponse
+		Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+		Expect(errResponse.Message).To(Equal(api.ErrUnauthorized.Error()))
+
+		Expect(handled).To(BeZero())
+	}
+
+	It("refuses requests without an Authorization header", func() {
+		expectUnauthorized(serve("DELETE", "/volumes/some-handle", ""))
+	})
+
+	It("refuses requests presenting another token", func() {
+		expectUnauthorized(serve("DELETE", "/volumes/some-handle", "Bearer some-other-token"))
+		expectUnauthorized(serve("DELETE", "/volumes/some-handle", "Bearer some-"))
+	})
+
+	It("refuses requests presenting the token in another scheme", func() {
+		expectUnauthorized(serve("DELETE", "/volumes/some-handle", "Basic some-token"))
+		expectUnauthorized(serve("DELETE", "/volumes/some-handle", "some-token"))
+	})
+
+	It("requires the token for /health", func() {
+		recorder := serve("GET", "/health", "")
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	Context("when /health is exempt", func() {
+		BeforeEach(func() {
+			exemptHealth = true
+		})
+
+		It("serves /health without the token", func() {
+			recorder := serve("GET", "/health", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(handled).To(Equal(1))
+		})
+
+		It("still requires the token elsewhere", func() {
+			recorder := serve("GET", "/volumes", "")
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
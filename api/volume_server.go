@@ -1,36 +1,82 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/concourse/baggageclaim"
 	"github.com/concourse/baggageclaim/volume"
-	uuid "github.com/nu7hatch/gouuid"
 	"github.com/tedsuo/rata"
 )
 
 const httpUnprocessableEntity = 422
 
+// nginx's status for a request the client went away from before the
+// response; nobody reads it, but it keeps the logs honest
+const httpClientClosedRequest = 499
+
 var ErrListVolumesFailed = errors.New("failed to list volumes")
 var ErrGetVolumeFailed = errors.New("failed to get volume")
 var ErrGetVolumeStatsFailed = errors.New("failed to get volume stats")
+var ErrGetUsageFailed = errors.New("failed to get usage")
+var ErrGetDigestFailed = errors.New("failed to digest volume")
+var ErrGetManifestFailed = errors.New("failed to list the contents of volume")
+var ErrGetChildrenFailed = errors.New("failed to list the children of volume")
+var ErrListFilesFailed = errors.New("failed to list the files of volume")
 var ErrCreateVolumeFailed = errors.New("failed to create volume")
+var ErrCloneVolumeFailed = errors.New("failed to clone volume")
+var ErrRenameVolumeFailed = errors.New("failed to rename volume")
+var ErrPromoteVolumeFailed = errors.New("failed to promote volume")
+var ErrRestoreVolumeFailed = errors.New("failed to restore volume")
 var ErrDestroyVolumeFailed = errors.New("failed to destroy volume")
+var ErrGetPropertyFailed = errors.New("failed to get property of volume")
 var ErrSetPropertyFailed = errors.New("failed to set property on volume")
+var ErrDeletePropertyFailed = errors.New("failed to delete property from volume")
 var ErrSetTTLFailed = errors.New("failed to set ttl on volume")
+var ErrTTLAndExpiresAt = errors.New("only one of value and expires_at may be given")
 var ErrSetPrivilegedFailed = errors.New("failed to change privileged status of volume")
+var ErrSetSELinuxLabelFailed = errors.New("failed to relabel volume")
+var ErrCommitVolumeFailed = errors.New("failed to commit volume")
+var ErrTouchAccessFailed = errors.New("failed to record access to volume")
+var ErrMaterializeFailed = errors.New("failed to materialize volume")
+var ErrAcquireLeaseFailed = errors.New("failed to acquire lease on volume")
+var ErrReleaseLeaseFailed = errors.New("failed to release lease on volume")
 var ErrStreamInFailed = errors.New("failed to stream in to volume")
 var ErrStreamOutFailed = errors.New("failed to stream out from volume")
 var ErrStreamOutNotFound = errors.New("no such file or directory")
+var ErrCopyInFailed = errors.New("failed to copy in to volume")
+var ErrStreamOutNotAcceptable = errors.New("none of the accepted encodings are supported")
+var ErrInvalidStreamOutFormat = errors.New("format must be tar or file")
+var ErrInvalidStreamBytesPerSecond = errors.New(baggageclaim.StreamBytesPerSecondHeader + " must be a positive number")
+var ErrRequestBodyTimeout = errors.New("timed out reading request body")
+var ErrDiffVolumesFailed = errors.New("failed to diff volumes")
+var ErrDraining = errors.New("draining; not creating new volumes")
 
 type VolumeServer struct {
 	strategerizer volume.Strategerizer
 	volumeRepo    volume.Repository
 
+	bodyReadTimeout time.Duration
+
+	drainState *DrainState
+
+	// streamBytesPerSecond caps every stream, unless it is zero
+	streamBytesPerSecond int64
+
+	handleGenerator HandleGenerator
+
 	logger lager.Logger
 }
 
@@ -38,75 +84,175 @@ func NewVolumeServer(
 	logger lager.Logger,
 	strategerizer volume.Strategerizer,
 	volumeRepo volume.Repository,
+	bodyReadTimeout time.Duration,
+	drainState *DrainState,
+	streamBytesPerSecond int64,
+	handleGenerator HandleGenerator,
 ) *VolumeServer {
 	return &VolumeServer{
-		strategerizer: strategerizer,
-		volumeRepo:    volumeRepo,
-		logger:        logger,
+		strategerizer:        strategerizer,
+		volumeRepo:           volumeRepo,
+		bodyReadTimeout:      bodyReadTimeout,
+		drainState:           drainState,
+		streamBytesPerSecond: streamBytesPerSecond,
+		handleGenerator:      handleGenerator,
+		logger:               logger,
+	}
+}
+
+// forRequest returns the server as it is to serve the request the context is
+// for, its repository tagging everything it logs with the request's ID.
+func (vs *VolumeServer) forRequest(ctx context.Context) *VolumeServer {
+	if RequestID(ctx) == "" {
+		return vs
 	}
+
+	scoped := *vs
+	scoped.volumeRepo = vs.volumeRepo.WithLogData(requestLogData(ctx))
+	return &scoped
 }
 
 func (vs *VolumeServer) CreateVolume(w http.ResponseWriter, req *http.Request) {
-	hLog := vs.logger.Session("create-volume")
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "create-volume")
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
+	if vs.drainState.IsDraining() {
+		hLog.Info("draining")
+		RespondWithError(w, ErrDraining, http.StatusServiceUnavailable)
+		return
+	}
+
 	var request baggageclaim.VolumeRequest
-	err := json.NewDecoder(req.Body).Decode(&request)
+	err := vs.decodeBody(w, req, &request)
 	if err != nil {
 		hLog.Error("failed-to-decode-request", err)
-		RespondWithError(w, ErrCreateVolumeFailed, http.StatusBadRequest)
+		RespondWithError(w, ErrCreateVolumeFailed, decodeErrorStatus(err))
 		return
 	}
 
-	handle := request.Handle
-	if handle == "" {
-		handle, err = vs.generateHandle()
-		if err != nil {
-			hLog.Error("failed-to-generate-handle", err)
-			RespondWithError(w, ErrCreateVolumeFailed, http.StatusBadRequest)
-			return
-		}
-	}
-
 	hLog = hLog.WithData(lager.Data{
-		"handle":     handle,
 		"ttl":        request.TTLInSeconds,
 		"privileged": request.Privileged,
 		"strategy":   request.Strategy,
+		"size":       request.SizeInBytes,
+		"read-only":  request.ReadOnly,
+
+		"mount-options":       request.MountOptions,
+		"renew-ttl-on-access": request.RenewTTLOnAccess,
+		"idempotency-key":     request.IdempotencyKey,
+		"encrypted":           request.Encrypted,
 	})
 
 	strategy, err := vs.strategerizer.StrategyFor(request)
 	if err != nil {
 		hLog.Error("could-not-produce-strategy", err)
+
+		if invalid, ok := err.(volume.StrategyError); ok {
+			RespondWithFieldErrors(w, ErrCreateVolumeFailed, invalid.Fields, httpUnprocessableEntity)
+			return
+		}
+
 		RespondWithError(w, ErrCreateVolumeFailed, httpUnprocessableEntity)
 		return
 	}
 
 	hLog.Debug("creating")
 
-	createdVolume, err := vs.volumeRepo.CreateVolume(
-		handle,
-		strategy,
-		volume.Properties(request.Properties),
-		request.TTLInSeconds,
-		request.Privileged,
-	)
+	createdVolume, err := vs.createVolume(hLog, strategy, request)
+	if err == errHandleGenerationFailed {
+		RespondWithError(w, ErrCreateVolumeFailed, http.StatusInternalServerError)
+		return
+	}
+
+	if err == volume.ErrVolumeAlreadyExists {
+		hLog.Info("volume-already-exists", lager.Data{"handle": request.Handle})
+		RespondWithError(w, volume.ErrVolumeAlreadyExists, http.StatusConflict)
+		return
+	}
+
+	if err == volume.ErrIdempotencyKeyConflict {
+		hLog.Info("idempotency-key-conflict")
+		RespondWithError(w, err, http.StatusConflict)
+		return
+	}
 
 	if err != nil {
 		hLog.Error("failed-to-create", err)
 
+		if invalid, ok := err.(volume.MountOptionsError); ok {
+			RespondWithFieldErrors(w, ErrCreateVolumeFailed, invalid.Fields, httpUnprocessableEntity)
+			return
+		}
+
+		// a fetch that may work if retried is the remote server's fault, not
+		// the request's
+		if fetchErr, ok := err.(volume.FetchError); ok {
+			code := httpUnprocessableEntity
+			if fetchErr.Temporary {
+				code = http.StatusBadGateway
+			}
+
+			RespondWithFieldErrors(w, ErrCreateVolumeFailed, []baggageclaim.FieldError{
+				{Field: "strategy.url", Code: baggageclaim.FieldErrorFetchFailed, Message: err.Error()},
+			}, code)
+			return
+		}
+
+		// the parent is only found to be wrong once the strategy is
+		// materialized
+		var fields []baggageclaim.FieldError
+
 		var code int
 		switch err {
 		case volume.ErrParentVolumeNotFound:
 			code = httpUnprocessableEntity
+			fields = parentFieldErrors(baggageclaim.FieldErrorNotFound, err)
 		case volume.ErrNoParentVolumeProvided:
 			code = httpUnprocessableEntity
+		case volume.ErrParentVolumeIsView:
+			code = httpUnprocessableEntity
+			fields = parentFieldErrors(baggageclaim.FieldErrorIsView, err)
+		case volume.ErrInvalidPropertyValue:
+			code = httpUnprocessableEntity
+		case volume.ErrInvalidPropertyName:
+			code = httpUnprocessableEntity
+			fields = propertiesFieldErrors(baggageclaim.FieldErrorNotAllowed, err)
+		case volume.ErrPropertyValueTooLarge:
+			code = httpUnprocessableEntity
+			fields = propertiesFieldErrors(baggageclaim.FieldErrorTooLarge, err)
+		case volume.ErrQuotasNotSupported:
+			code = httpUnprocessableEntity
+		case volume.ErrReadOnlyNotSupported:
+			code = httpUnprocessableEntity
+		case volume.ErrTmpfsNotSupported:
+			code = httpUnprocessableEntity
+		case volume.ErrCopyOnWriteOfTmpfs:
+			code = httpUnprocessableEntity
+		case volume.ErrChecksumMismatch:
+			code = httpUnprocessableEntity
+			fields = []baggageclaim.FieldError{
+				{Field: "strategy.checksum", Code: baggageclaim.FieldErrorChecksumMismatch, Message: err.Error()},
+			}
+		case volume.ErrInvalidFetchedArchive, volume.ErrUnknownStreamFormat:
+			code = httpUnprocessableEntity
+			fields = []baggageclaim.FieldError{
+				{Field: "strategy.url", Code: baggageclaim.FieldErrorInvalidArchive, Message: err.Error()},
+			}
+		case volume.ErrEncryptionNotEnabled, volume.ErrEncryptionNotSupported, volume.ErrStrategyNotEncryptable:
+			code = httpUnprocessableEntity
+			fields = []baggageclaim.FieldError{
+				{Field: "encrypted", Code: baggageclaim.FieldErrorNotAllowed, Message: err.Error()},
+			}
+		case volume.ErrInsufficientInodes:
+			code = http.StatusInsufficientStorage
 		default:
 			code = http.StatusInternalServerError
 		}
-		RespondWithError(w, ErrCreateVolumeFailed, code)
+		RespondWithFieldErrors(w, ErrCreateVolumeFailed, fields, code)
 		return
 	}
 
@@ -126,273 +272,1682 @@ func (vs *VolumeServer) CreateVolume(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (vs *VolumeServer) DestroyVolume(w http.ResponseWriter, req *http.Request) {
-	handle := rata.Param(req, "handle")
+// createVolume creates the volume with the handle of the request, or with a
+// generated one if it has none.
+func (vs *VolumeServer) createVolume(hLog lager.Logger, strategy volume.Strategy, request baggageclaim.VolumeRequest) (volume.Volume, error) {
+	var (
+		createdVolume volume.Volume
+		err           error
+	)
 
-	hLog := vs.logger.Session("destroy", lager.Data{
-		"volume": handle,
-	})
+	for attempt := 1; ; attempt++ {
+		handle := request.Handle
+		if handle == "" {
+			handle, err = vs.handleGenerator.Generate()
+			if err != nil {
+				hLog.Error("failed-to-generate-handle", err)
+				return volume.Volume{}, errHandleGenerationFailed
+			}
+		}
 
-	hLog.Debug("start")
-	defer hLog.Debug("done")
+		createdVolume, err = vs.volumeRepo.CreateVolume(
+			handle,
+			strategy,
+			volume.Properties(request.Properties),
+			request.TTLInSeconds,
+			request.Privileged,
+			volume.CreateOptions{
+				SizeInBytes:      request.SizeInBytes,
+				ReadOnly:         request.ReadOnly,
+				MountOptions:     request.MountOptions,
+				RenewTTLOnAccess: request.RenewTTLOnAccess,
+				IdempotencyKey:   request.IdempotencyKey,
+				Encrypted:        request.Encrypted,
+			},
+		)
+
+		// a generated handle is only taken if the generator collided, so
+		// another is generated rather than failing the create
+		if err != volume.ErrVolumeAlreadyExists || request.Handle != "" {
+			break
+		}
 
-	err := vs.volumeRepo.DestroyVolume(handle)
-	if err != nil {
-		if err == volume.ErrVolumeDoesNotExist {
-			hLog.Info("volume-does-not-exist")
-			RespondWithError(w, ErrDestroyVolumeFailed, http.StatusNotFound)
-		} else {
-			hLog.Error("failed-to-destroy", err)
-			RespondWithError(w, ErrDestroyVolumeFailed, http.StatusInternalServerError)
+		hLog.Info("generated-handle-taken", lager.Data{"handle": handle, "attempt": attempt})
+
+		vs.handleGenerator.Taken(handle)
+
+		if attempt == maxHandleGenerations {
+			break
 		}
+	}
 
-		return
+	// the key's volume is given back whatever the handle, as it may have
+	// been generated, but one asked for by name must be the one
+	if err == nil && request.Handle != "" && createdVolume.Handle != request.Handle {
+		hLog.Info("idempotency-key-used-for-another-handle", lager.Data{"volume": createdVolume.Handle})
+		return volume.Volume{}, volume.ErrIdempotencyKeyConflict
 	}
 
-	hLog.Info("destroyed")
+	return createdVolume, err
+}
 
-	w.WriteHeader(http.StatusNoContent)
+// parentFieldErrors are the field errors for a strategy whose parent turned
+// out to be wrong.
+func parentFieldErrors(code string, err error) []baggageclaim.FieldError {
+	return []baggageclaim.FieldError{
+		{Field: "strategy.volume", Code: code, Message: err.Error()},
+	}
 }
 
-func (vs *VolumeServer) ListVolumes(w http.ResponseWriter, req *http.Request) {
-	hLog := vs.logger.Session("list-volumes")
+func propertiesFieldErrors(code string, err error) []baggageclaim.FieldError {
+	return []baggageclaim.FieldError{
+		{Field: "properties", Code: code, Message: err.Error()},
+	}
+}
+
+func (vs *VolumeServer) CloneVolume(w http.ResponseWriter, req *http.Request) {
+	srcHandle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "clone-volume", lager.Data{
+		"source": srcHandle,
+	})
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	w.Header().Set("Content-Type", "application/json")
+	if vs.drainState.IsDraining() {
+		hLog.Info("draining")
+		RespondWithError(w, ErrDraining, http.StatusServiceUnavailable)
+		return
+	}
 
-	properties, err := ConvertQueryToProperties(req.URL.Query())
+	var request baggageclaim.CloneVolumeRequest
+	err := vs.decodeBody(w, req, &request)
 	if err != nil {
-		RespondWithError(w, err, httpUnprocessableEntity)
+		hLog.Error("failed-to-decode-request", err)
+		RespondWithError(w, ErrCloneVolumeFailed, decodeErrorStatus(err))
 		return
 	}
 
-	volumes, _, err := vs.volumeRepo.ListVolumes(properties)
+	var handle string
+	var clonedVolume volume.Volume
+	for attempt := 1; ; attempt++ {
+		handle = request.Handle
+		if handle == "" {
+			handle, err = vs.handleGenerator.Generate()
+			if err != nil {
+				hLog.Error("failed-to-generate-handle", err)
+				RespondWithError(w, ErrCloneVolumeFailed, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		clonedVolume, err = vs.volumeRepo.CloneVolume(srcHandle, handle)
+		if err != volume.ErrVolumeAlreadyExists || request.Handle != "" {
+			break
+		}
+
+		hLog.Info("generated-handle-taken", lager.Data{"handle": handle, "attempt": attempt})
+
+		vs.handleGenerator.Taken(handle)
+
+		if attempt == maxHandleGenerations {
+			break
+		}
+	}
+
+	hLog = hLog.WithData(lager.Data{
+		"handle": handle,
+	})
+
 	if err != nil {
-		hLog.Error("failed-to-list-volumes", err)
-		RespondWithError(w, ErrListVolumesFailed, http.StatusInternalServerError)
+		var code int
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			hLog.Info("source-not-found")
+			code = http.StatusNotFound
+		case volume.ErrVolumeAlreadyExists:
+			hLog.Info("volume-already-exists")
+			code = http.StatusConflict
+		case volume.ErrInsufficientInodes:
+			hLog.Info("inodes-exhausted")
+			code = http.StatusInsufficientStorage
+		default:
+			hLog.Error("failed-to-clone", err)
+			code = http.StatusInternalServerError
+		}
+
+		RespondWithError(w, ErrCloneVolumeFailed, code)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(volumes); err != nil {
-		hLog.Error("failed-to-encode", err)
-	}
-}
+	hLog.Debug("cloned")
 
-func (vs *VolumeServer) GetVolume(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(clonedVolume); err != nil {
+		hLog.Error("failed-to-encode", err, lager.Data{
+			"volume-path": clonedVolume.Path,
+		})
+	}
+}
 
+func (vs *VolumeServer) RenameVolume(w http.ResponseWriter, req *http.Request) {
 	handle := rata.Param(req, "handle")
 
-	hLog := vs.logger.Session("get-volume", lager.Data{
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "rename-volume", lager.Data{
 		"volume": handle,
 	})
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	vol, found, err := vs.volumeRepo.GetVolume(handle)
+	var request baggageclaim.RenameVolumeRequest
+	err := vs.decodeBody(w, req, &request)
 	if err != nil {
-		hLog.Error("failed-to-get-volume", err)
-		RespondWithError(w, ErrGetVolumeFailed, http.StatusInternalServerError)
+		hLog.Error("failed-to-decode-request", err)
+		RespondWithError(w, ErrRenameVolumeFailed, decodeErrorStatus(err))
 		return
 	}
 
-	if !found {
-		hLog.Info("volume-not-found")
-		RespondWithError(w, ErrGetVolumeFailed, http.StatusNotFound)
+	hLog = hLog.WithData(lager.Data{
+		"new-handle": request.Handle,
+	})
+
+	renamedVolume, err := vs.volumeRepo.RenameVolume(handle, request.Handle)
+	if err != nil {
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrRenameVolumeFailed, http.StatusNotFound)
+		case volume.ErrInvalidHandle:
+			hLog.Info("invalid-handle")
+			RespondWithError(w, err, http.StatusBadRequest)
+		case volume.ErrVolumeAlreadyExists, volume.ErrVolumeIsStreaming:
+			hLog.Info("conflict", lager.Data{"error": err.Error()})
+			RespondWithError(w, err, http.StatusConflict)
+		default:
+			hLog.Error("failed-to-rename", err)
+			RespondWithError(w, ErrRenameVolumeFailed, http.StatusInternalServerError)
+		}
+
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(vol); err != nil {
-		hLog.Error("failed-to-encode", err)
-	}
-}
+	hLog.Debug("renamed")
 
-func (vs *VolumeServer) GetVolumeStats(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(renamedVolume); err != nil {
+		hLog.Error("failed-to-encode", err, lager.Data{
+			"volume-path": renamedVolume.Path,
+		})
+	}
+}
 
+func (vs *VolumeServer) PromoteVolume(w http.ResponseWriter, req *http.Request) {
 	handle := rata.Param(req, "handle")
 
-	hLog := vs.logger.Session("get-volume-stats", lager.Data{
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "promote-volume", lager.Data{
 		"volume": handle,
 	})
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	vol, found, err := vs.volumeRepo.GetVolumeStats(handle)
+	promotedVolume, err := vs.volumeRepo.Promote(handle)
 	if err != nil {
-		hLog.Error("failed-to-get-volume-stats", err)
-		RespondWithError(w, ErrGetVolumeStatsFailed, http.StatusInternalServerError)
-		return
-	}
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrPromoteVolumeFailed, http.StatusNotFound)
+		case volume.ErrPromoteWithChildren, volume.ErrVolumeIsStreaming:
+			hLog.Info("conflict", lager.Data{"error": err.Error()})
+			RespondWithError(w, err, http.StatusConflict)
+		default:
+			hLog.Error("failed-to-promote", err)
+			RespondWithError(w, ErrPromoteVolumeFailed, http.StatusInternalServerError)
+		}
 
-	if !found {
-		RespondWithError(w, ErrGetVolumeStatsFailed, http.StatusNotFound)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(vol); err != nil {
-		hLog.Error("failed-to-encode", err)
+	hLog.Debug("promoted")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(promotedVolume); err != nil {
+		hLog.Error("failed-to-encode", err, lager.Data{
+			"volume-path": promotedVolume.Path,
+		})
 	}
 }
 
-func (vs *VolumeServer) SetProperty(w http.ResponseWriter, req *http.Request) {
+func (vs *VolumeServer) RestoreVolume(w http.ResponseWriter, req *http.Request) {
 	handle := rata.Param(req, "handle")
-	propertyName := rata.Param(req, "property")
 
-	hLog := vs.logger.Session("set-property", lager.Data{
-		"volume":   handle,
-		"property": propertyName,
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "restore-volume", lager.Data{
+		"volume": handle,
 	})
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	var request baggageclaim.PropertyRequest
-	err := json.NewDecoder(req.Body).Decode(&request)
+	restoredVolume, err := vs.volumeRepo.RestoreVolume(handle)
 	if err != nil {
-		RespondWithError(w, ErrSetPropertyFailed, http.StatusBadRequest)
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrRestoreVolumeFailed, http.StatusNotFound)
+		case volume.ErrVolumeGone:
+			hLog.Info("volume-gone")
+			RespondWithError(w, err, http.StatusGone)
+		case volume.ErrParentVolumeDeleted:
+			hLog.Info("conflict", lager.Data{"error": err.Error()})
+			RespondWithError(w, err, http.StatusConflict)
+		default:
+			hLog.Error("failed-to-restore", err)
+			RespondWithError(w, ErrRestoreVolumeFailed, http.StatusInternalServerError)
+		}
+
 		return
 	}
 
-	propertyValue := request.Value
-
-	hLog.Debug("setting-property")
-
-	err = vs.volumeRepo.SetProperty(handle, propertyName, propertyValue)
-	if err != nil {
-		hLog.Error("failed-to-set-property", err)
+	hLog.Debug("restored")
 
-		if err == volume.ErrVolumeDoesNotExist {
-			RespondWithError(w, ErrSetPropertyFailed, http.StatusNotFound)
-		} else {
-			RespondWithError(w, ErrSetPropertyFailed, http.StatusInternalServerError)
-		}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 
-		return
+	if err := json.NewEncoder(w).Encode(restoredVolume); err != nil {
+		hLog.Error("failed-to-encode", err, lager.Data{
+			"volume-path": restoredVolume.Path,
+		})
 	}
-
-	w.WriteHeader(http.StatusNoContent)
 }
 
-func (vs *VolumeServer) SetTTL(w http.ResponseWriter, req *http.Request) {
+func (vs *VolumeServer) DestroyVolume(w http.ResponseWriter, req *http.Request) {
 	handle := rata.Param(req, "handle")
 
-	hLog := vs.logger.Session("set-ttl", lager.Data{
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "destroy", lager.Data{
 		"volume": handle,
 	})
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	var request baggageclaim.TTLRequest
-	err := json.NewDecoder(req.Body).Decode(&request)
-	if err != nil {
-		RespondWithError(w, ErrSetTTLFailed, http.StatusBadRequest)
-		return
+	opts := volume.DestroyOptions{
+		Reason:     volume.DestroyReasonManual,
+		Annotation: req.URL.Query().Get("reason"),
+		LeaseToken: req.Header.Get(baggageclaim.LeaseTokenHeader),
 	}
 
-	ttl := request.Value
-
-	hLog.Debug("setting-ttl", lager.Data{"ttl": ttl})
+	// the volume's descendants go with it
+	force := req.URL.Query().Get("force") == "true"
+
+	// a dry run reports what would be destroyed as a forced destroy reports
+	// what was, whether or not it is forced
+	dryRun := req.URL.Query().Get("dryRun") == "true"
+
+	var destroyed []string
+	var err error
+	if dryRun {
+		destroyed, err = vs.volumeRepo.PlanDestroy(handle, opts, force)
+	} else if force {
+		destroyed, err = vs.volumeRepo.DestroyVolumeAndDescendants(handle, opts)
+	} else {
+		err = vs.volumeRepo.DestroyVolume(handle, opts)
+	}
 
-	err = vs.volumeRepo.SetTTL(handle, ttl)
 	if err != nil {
-		hLog.Error("failed-to-set-ttl", err)
-
 		if err == volume.ErrVolumeDoesNotExist {
-			RespondWithError(w, ErrSetTTLFailed, http.StatusNotFound)
+			// there is nothing to report on for a dry run
+			if req.URL.Query().Get("missing-ok") == "true" && !dryRun {
+				hLog.Info("volume-already-destroyed")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			hLog.Info("volume-does-not-exist")
+			RespondWithError(w, ErrDestroyVolumeFailed, http.StatusNotFound)
+		} else if err == volume.ErrVolumeHasChildren {
+			hLog.Info("volume-has-children")
+			RespondWithError(w, err, http.StatusConflict)
+		} else if err == volume.ErrVolumeIsLeased {
+			hLog.Info("volume-is-leased")
+			RespondWithError(w, err, http.StatusLocked)
 		} else {
-			RespondWithError(w, ErrSetTTLFailed, http.StatusInternalServerError)
+			hLog.Error("failed-to-destroy", err)
+			RespondWithError(w, ErrDestroyVolumeFailed, http.StatusInternalServerError)
 		}
 
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if !dryRun && !force {
+		hLog.Info("destroyed")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if dryRun {
+		hLog.Info("planned", lager.Data{"destroyed": destroyed})
+	} else {
+		hLog.Info("destroyed", lager.Data{"destroyed": destroyed})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(baggageclaim.DestroyVolumeResponse{Destroyed: destroyed})
+	if err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
 }
 
-func (vs *VolumeServer) SetPrivileged(w http.ResponseWriter, req *http.Request) {
-	handle := rata.Param(req, "handle")
+// DestroyVolumes destroys each volume in the JSON array of handles. It
+// responds with how each one went, in the same order, so that only the
+// failed ones need retrying.
+func (vs *VolumeServer) DestroyVolumes(w http.ResponseWriter, req *http.Request) {
+	vs = vs.forRequest(req.Context())
 
-	hLog := vs.logger.Session("set-privileged", lager.Data{
-		"volume": handle,
-	})
+	hLog := requestSession(req.Context(), vs.logger, "destroy-volumes")
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	var request baggageclaim.PrivilegedRequest
-	err := json.NewDecoder(req.Body).Decode(&request)
+	var handles []string
+	err := vs.decodeBody(w, req, &handles)
 	if err != nil {
-		RespondWithError(w, ErrSetPrivilegedFailed, http.StatusBadRequest)
+		hLog.Error("failed-to-decode-request", err)
+		RespondWithError(w, ErrDestroyVolumeFailed, decodeErrorStatus(err))
 		return
 	}
 
-	privileged := request.Value
-
-	hLog.Debug("setting-privileged", lager.Data{"privileged": privileged})
+	errs := vs.volumeRepo.DestroyVolumes(handles, volume.DestroyOptions{
+		Reason:     volume.DestroyReasonManual,
+		Annotation: req.URL.Query().Get("reason"),
+	})
 
-	err = vs.volumeRepo.SetPrivileged(handle, privileged)
-	if err != nil {
-		hLog.Error("failed-to-change-privileged-status", err)
+	results := make([]baggageclaim.DestroyVolumesResult, len(handles))
+	for i, handle := range handles {
+		results[i].Handle = handle
 
-		if err == volume.ErrVolumeDoesNotExist {
-			RespondWithError(w, ErrSetPrivilegedFailed, http.StatusNotFound)
-		} else {
-			RespondWithError(w, ErrSetPrivilegedFailed, http.StatusInternalServerError)
+		if errs[handle] != nil {
+			hLog.Error("failed-to-destroy", errs[handle], lager.Data{"volume": handle})
+			results[i].Error = ErrDestroyVolumeFailed.Error()
 		}
-
-		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	hLog.Info("destroyed", lager.Data{"volumes": len(handles)})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
 }
 
-func (vs *VolumeServer) StreamIn(w http.ResponseWriter, req *http.Request) {
-	handle := rata.Param(req, "handle")
+// DestroyVolumesWithProperties destroys the volumes that have all of the
+// properties in the query, which may also give a reason like the other
+// destroys. It responds with how many were destroyed and which failed.
+func (vs *VolumeServer) DestroyVolumesWithProperties(w http.ResponseWriter, req *http.Request) {
+	vs = vs.forRequest(req.Context())
 
-	hLog := vs.logger.Session("stream-in", lager.Data{
-		"volume": handle,
-	})
+	hLog := requestSession(req.Context(), vs.logger, "destroy-volumes-with-properties")
 
 	hLog.Debug("start")
 	defer hLog.Debug("done")
 
-	var subPath string
-	if queryPath, ok := req.URL.Query()["path"]; ok {
-		subPath = queryPath[0]
+	query := req.URL.Query()
+
+	reason := query.Get("reason")
+	query.Del("reason")
+
+	properties, err := ConvertQueryToProperties(query)
+	if err != nil {
+		RespondWithError(w, err, httpUnprocessableEntity)
+		return
 	}
 
-	badStream, err := vs.volumeRepo.StreamIn(handle, subPath, req.Body)
+	errs, err := vs.volumeRepo.DestroyVolumesWithProperties(properties, volume.DestroyOptions{
+		Reason:     volume.DestroyReasonManual,
+		Annotation: reason,
+	})
 	if err != nil {
-		if err == volume.ErrVolumeDoesNotExist {
-			hLog.Info("volume-not-found")
+		if err == volume.ErrNoPropertiesToSelectBy {
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		hLog.Error("failed-to-destroy-volumes", err)
+		RespondWithError(w, ErrDestroyVolumeFailed, http.StatusInternalServerError)
+		return
+	}
+
+	handles := make([]string, 0, len(errs))
+	for handle := range errs {
+		handles = append(handles, handle)
+	}
+
+	sort.Strings(handles)
+
+	response := baggageclaim.DestroyVolumesWithPropertiesResponse{
+		Failed: []baggageclaim.DestroyVolumesResult{},
+	}
+
+	for _, handle := range handles {
+		if errs[handle] != nil {
+			hLog.Error("failed-to-destroy", errs[handle], lager.Data{"volume": handle})
+
+			response.Failed = append(response.Failed, baggageclaim.DestroyVolumesResult{
+				Handle: handle,
+				Error:  ErrDestroyVolumeFailed.Error(),
+			})
+		} else {
+			response.Destroyed++
+		}
+	}
+
+	hLog.Info("destroyed", lager.Data{"volumes": response.Destroyed, "failed": len(response.Failed)})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) ListVolumes(w http.ResponseWriter, req *http.Request) {
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "list-volumes")
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	query := req.URL.Query()
+
+	listOpts, paged, err := ConvertQueryToListOptions(query)
+	if err != nil {
+		RespondWithError(w, err, httpUnprocessableEntity)
+		return
+	}
+
+	includeDeleted, err := ConvertQueryToIncludeDeleted(query)
+	if err != nil {
+		RespondWithError(w, err, httpUnprocessableEntity)
+		return
+	}
+
+	properties, err := ConvertQueryToProperties(query)
+	if err != nil {
+		RespondWithError(w, err, httpUnprocessableEntity)
+		return
+	}
+
+	volumes, _, err := vs.volumeRepo.ListVolumes(properties)
+	if err != nil {
+		hLog.Error("failed-to-list-volumes", err)
+		RespondWithError(w, ErrListVolumesFailed, http.StatusInternalServerError)
+		return
+	}
+
+	if includeDeleted {
+		deletedVolumes, _, err := vs.volumeRepo.ListDeletedVolumes(properties)
+		if err != nil {
+			hLog.Error("failed-to-list-deleted-volumes", err)
+			RespondWithError(w, ErrListVolumesFailed, http.StatusInternalServerError)
+			return
+		}
+
+		volumes = append(volumes, deletedVolumes...)
+	}
+
+	if paged {
+		w.Header().Set(baggageclaim.VolumeCountHeader, strconv.Itoa(len(volumes)))
+		volumes = pageVolumes(volumes, listOpts)
+	}
+
+	responses := make([]baggageclaim.VolumeResponse, len(volumes))
+	for i, vol := range volumes {
+		responses[i] = volumeResponse(vol)
+	}
+
+	if err := encodeReadResponse(w, req, volumes, responses); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) GetVolume(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-volume", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	vol, found, err := vs.volumeRepo.GetVolume(handle)
+	if err != nil {
+		hLog.Error("failed-to-get-volume", err)
+		RespondWithError(w, ErrGetVolumeFailed, http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		hLog.Info("volume-not-found")
+		RespondWithError(w, ErrGetVolumeFailed, http.StatusNotFound)
+		return
+	}
+
+	if err := encodeReadResponse(w, req, vol, volumeResponse(vol)); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+// VolumeExists responds with 200 if there is a volume with the handle and
+// 404 if there is not, with no body either way, for clients that only need
+// to know that much to do without the volume's properties being encoded.
+func (vs *VolumeServer) VolumeExists(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "volume-exists", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	found, err := vs.volumeRepo.VolumeExists(handle)
+	if err != nil {
+		hLog.Error("failed-to-check-volume", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		hLog.Debug("volume-not-found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (vs *VolumeServer) GetVolumeStats(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-volume-stats", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	vol, found, err := vs.volumeRepo.GetVolumeStats(handle)
+	if err != nil {
+		hLog.Error("failed-to-get-volume-stats", err)
+		RespondWithError(w, ErrGetVolumeStatsFailed, http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		RespondWithError(w, ErrGetVolumeStatsFailed, http.StatusNotFound)
+		return
+	}
+
+	stats := baggageclaim.VolumeStatsResponse{
+		SizeInBytes:  vol.SizeInBytes,
+		FileCount:    vol.FileCount,
+		SizeExact:    vol.SizeExact,
+		FreeBytes:    vol.FreeBytes,
+		QuotaInBytes: vol.QuotaInBytes,
+
+		Driver:         vol.Driver,
+		FilesystemType: vol.FilesystemType,
+	}
+
+	if err := encodeReadResponse(w, req, vol, stats); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+// GetUsage sums up the sizes of the volumes, grouped by the values they have
+// for the group-by property if one is given.
+func (vs *VolumeServer) GetUsage(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupBy := req.URL.Query().Get("group-by")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-usage", lager.Data{
+		"group-by": groupBy,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	usage, err := vs.volumeRepo.TotalUsage(groupBy)
+	if err != nil {
+		hLog.Error("failed-to-get-usage", err)
+		RespondWithError(w, ErrGetUsageFailed, http.StatusInternalServerError)
+		return
+	}
+
+	response := baggageclaim.UsageResponse{
+		UsedBytes: usage.UsedBytes,
+		FreeBytes: usage.FreeBytes,
+		Volumes:   usage.Volumes,
+
+		GroupedBy: usage.GroupedBy,
+
+		Failed: usage.Failed,
+	}
+
+	if usage.Groups != nil {
+		response.Groups = map[string]baggageclaim.UsageGroupResponse{}
+		for value, group := range usage.Groups {
+			response.Groups[value] = baggageclaim.UsageGroupResponse(group)
+		}
+	}
+
+	if usage.Ungrouped != nil {
+		ungrouped := baggageclaim.UsageGroupResponse(*usage.Ungrouped)
+		response.Ungrouped = &ungrouped
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) GetDigest(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-digest", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	digest, err := vs.volumeRepo.VolumeDigest(handle)
+	if err != nil {
+		hLog.Error("failed-to-digest-volume", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrGetDigestFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrGetDigestFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(baggageclaim.VolumeDigestResponse{Digest: digest}); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+// GetChildren responds with the JSON array of the handles of the volume's
+// copy-on-write children.
+func (vs *VolumeServer) GetChildren(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-children", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	children, err := vs.volumeRepo.VolumeChildren(handle)
+	if err != nil {
+		if err == volume.ErrVolumeDoesNotExist {
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrGetChildrenFailed, http.StatusNotFound)
+		} else {
+			hLog.Error("failed-to-list-children", err)
+			RespondWithError(w, ErrGetChildrenFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(children); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) GetManifest(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	subPath := req.URL.Query().Get("path")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-manifest", lager.Data{
+		"volume":   handle,
+		"sub-path": subPath,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	dest := &lazyContentTypeWriter{ResponseWriter: w, contentType: "application/x-ndjson"}
+
+	encoder := json.NewEncoder(dest)
+
+	err := vs.volumeRepo.Manifest(handle, subPath, func(entry volume.ManifestEntry) error {
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		if dest.wroteBody {
+			// too late to report the error with a status code; cut the
+			// response short instead
+			hLog.Error("failed-while-streaming-manifest", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err == volume.ErrVolumeDoesNotExist {
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrGetManifestFailed, http.StatusNotFound)
+			return
+		}
+
+		if os.IsNotExist(err) {
+			hLog.Info("path-not-found")
+			RespondWithError(w, ErrStreamOutNotFound, http.StatusNotFound)
+			return
+		}
+
+		if err == volume.ErrUnsafeSubPath {
+			hLog.Info("unsafe-sub-path")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		hLog.Error("failed-to-list-manifest", err)
+		RespondWithError(w, ErrGetManifestFailed, http.StatusInternalServerError)
+		return
+	}
+}
+
+func (vs *VolumeServer) ListFiles(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handle := rata.Param(req, "handle")
+	subPath := req.URL.Query().Get("path")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "list-files", lager.Data{
+		"volume":   handle,
+		"sub-path": subPath,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	files, err := vs.volumeRepo.ListFiles(handle, subPath)
+	if err != nil {
+		if err == volume.ErrVolumeDoesNotExist {
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrListFilesFailed, http.StatusNotFound)
+			return
+		}
+
+		if os.IsNotExist(err) {
+			hLog.Info("path-not-found")
+			RespondWithError(w, ErrStreamOutNotFound, http.StatusNotFound)
+			return
+		}
+
+		if err == volume.ErrUnsafeSubPath {
+			hLog.Info("unsafe-sub-path")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		hLog.Error("failed-to-list-files", err)
+		RespondWithError(w, ErrListFilesFailed, http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(files); err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) GetProperty(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	propertyName := rata.Param(req, "property")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "get-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	propertyValue, err := vs.volumeRepo.GetProperty(handle, propertyName)
+	if err != nil {
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrGetPropertyFailed, http.StatusNotFound)
+		case volume.ErrPropertyDoesNotExist:
+			// told apart from a missing volume by its message
+			hLog.Debug("property-not-found")
+			RespondWithError(w, err, http.StatusNotFound)
+		default:
+			hLog.Error("failed-to-get-property", err)
+			RespondWithError(w, ErrGetPropertyFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(baggageclaim.PropertyResponse{Value: propertyValue})
+	if err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) SetProperty(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	propertyName := rata.Param(req, "property")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "set-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.PropertyRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrSetPropertyFailed, decodeErrorStatus(err))
+		return
+	}
+
+	propertyValue := request.Value
+
+	var expected *volume.PropertyExpectation
+	if request.ExpectAbsent {
+		expected = &volume.PropertyExpectation{Absent: true}
+	} else if request.Expected != nil {
+		expected = &volume.PropertyExpectation{Value: *request.Expected}
+	}
+
+	hLog.Debug("setting-property")
+
+	err = vs.volumeRepo.SetProperty(handle, propertyName, propertyValue, expected, req.Header.Get(baggageclaim.LeaseTokenHeader))
+	if err == volume.ErrPropertyConflict {
+		hLog.Info("property-conflict")
+		RespondWithError(w, err, http.StatusConflict)
+		return
+	}
+
+	if err == volume.ErrVolumeIsLeased {
+		hLog.Info("volume-is-leased")
+		RespondWithError(w, err, http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		hLog.Error("failed-to-set-property", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrSetPropertyFailed, http.StatusNotFound)
+		} else if err == volume.ErrInvalidPropertyValue {
+			RespondWithError(w, ErrSetPropertyFailed, httpUnprocessableEntity)
+		} else if err == volume.ErrInvalidPropertyName || err == volume.ErrPropertyValueTooLarge {
+			RespondWithError(w, err, httpUnprocessableEntity)
+		} else {
+			RespondWithError(w, ErrSetPropertyFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) SetProperties(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "set-properties", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.VolumeProperties
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrSetPropertyFailed, decodeErrorStatus(err))
+		return
+	}
+
+	hLog.Debug("setting-properties", lager.Data{"properties": request})
+
+	err = vs.volumeRepo.SetProperties(handle, volume.Properties(request), req.Header.Get(baggageclaim.LeaseTokenHeader))
+	if err == volume.ErrVolumeIsLeased {
+		hLog.Info("volume-is-leased")
+		RespondWithError(w, err, http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		hLog.Error("failed-to-set-properties", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrSetPropertyFailed, http.StatusNotFound)
+		} else if err == volume.ErrInvalidPropertyValue {
+			RespondWithError(w, ErrSetPropertyFailed, httpUnprocessableEntity)
+		} else if err == volume.ErrInvalidPropertyName || err == volume.ErrPropertyValueTooLarge {
+			RespondWithError(w, err, httpUnprocessableEntity)
+		} else {
+			RespondWithError(w, ErrSetPropertyFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) DeleteProperty(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	propertyName := rata.Param(req, "property")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "delete-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	err := vs.volumeRepo.DeleteProperty(handle, propertyName, req.Header.Get(baggageclaim.LeaseTokenHeader))
+	if err == volume.ErrVolumeIsLeased {
+		hLog.Info("volume-is-leased")
+		RespondWithError(w, err, http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		hLog.Error("failed-to-delete-property", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrDeletePropertyFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrDeletePropertyFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) SetTTL(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "set-ttl", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.TTLRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrSetTTLFailed, decodeErrorStatus(err))
+		return
+	}
+
+	if request.ExpiresAt != nil && request.Value != 0 {
+		RespondWithError(w, ErrTTLAndExpiresAt, httpUnprocessableEntity)
+		return
+	}
+
+	if request.ExpiresAt != nil {
+		hLog.Debug("setting-expires-at", lager.Data{"expires-at": *request.ExpiresAt})
+
+		err = vs.volumeRepo.SetExpiresAt(handle, *request.ExpiresAt)
+	} else {
+		hLog.Debug("setting-ttl", lager.Data{"ttl": request.Value})
+
+		err = vs.volumeRepo.SetTTL(handle, request.Value)
+	}
+
+	if err != nil {
+		hLog.Error("failed-to-set-ttl", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrSetTTLFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrSetTTLFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) SetPrivileged(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "set-privileged", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.PrivilegedRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrSetPrivilegedFailed, decodeErrorStatus(err))
+		return
+	}
+
+	privileged := request.Value
+
+	hLog.Debug("setting-privileged", lager.Data{"privileged": privileged})
+
+	err = vs.volumeRepo.SetPrivileged(handle, privileged)
+	if err != nil {
+		hLog.Error("failed-to-change-privileged-status", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrSetPrivilegedFailed, http.StatusNotFound)
+		} else if err == volume.ErrVolumeIsFrozen {
+			RespondWithError(w, ErrSetPrivilegedFailed, http.StatusConflict)
+		} else {
+			RespondWithError(w, ErrSetPrivilegedFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) SetSELinuxLabel(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "set-selinux-label", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.SELinuxLabelRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrSetSELinuxLabelFailed, decodeErrorStatus(err))
+		return
+	}
+
+	hLog.Debug("setting-selinux-label", lager.Data{"label": request.Label})
+
+	err = vs.volumeRepo.SetSELinuxLabel(handle, request.Label)
+	if err != nil {
+		hLog.Error("failed-to-relabel", err)
+
+		switch err {
+		case volume.ErrInvalidSELinuxLabel:
+			RespondWithError(w, err, http.StatusBadRequest)
+		case volume.ErrVolumeDoesNotExist:
+			RespondWithError(w, ErrSetSELinuxLabelFailed, http.StatusNotFound)
+		case volume.ErrVolumeIsFrozen:
+			RespondWithError(w, ErrSetSELinuxLabelFailed, http.StatusConflict)
+		default:
+			RespondWithError(w, ErrSetSELinuxLabelFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) CommitVolume(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "commit-volume", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.CommitRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil && err != io.EOF {
+		RespondWithError(w, ErrCommitVolumeFailed, decodeErrorStatus(err))
+		return
+	}
+
+	hLog.Debug("committing", lager.Data{"freeze": request.Freeze})
+
+	err = vs.volumeRepo.CommitVolume(handle, request.Freeze)
+	if err != nil {
+		hLog.Error("failed-to-commit", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrCommitVolumeFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrCommitVolumeFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) TouchAccess(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "touch-access", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	err := vs.volumeRepo.TouchAccess(handle)
+	if err != nil {
+		hLog.Error("failed-to-touch-access", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrTouchAccessFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrTouchAccessFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) Materialize(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "materialize", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	mounted, err := vs.volumeRepo.Materialize(handle)
+	if err != nil {
+		hLog.Error("failed-to-materialize", err)
+
+		if err == volume.ErrVolumeDoesNotExist {
+			RespondWithError(w, ErrMaterializeFailed, http.StatusNotFound)
+		} else {
+			RespondWithError(w, ErrMaterializeFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(baggageclaim.MaterializeResponse{
+		Mounted: mounted,
+	})
+	if err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) AcquireLease(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "acquire-lease", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var request baggageclaim.LeaseRequest
+	err := vs.decodeBody(w, req, &request)
+	if err != nil {
+		RespondWithError(w, ErrAcquireLeaseFailed, decodeErrorStatus(err))
+		return
+	}
+
+	lease, err := vs.volumeRepo.AcquireLease(handle, request.Token, time.Duration(request.TTL)*time.Second)
+	if err != nil {
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			RespondWithError(w, ErrAcquireLeaseFailed, http.StatusNotFound)
+		case volume.ErrInvalidLeaseDuration:
+			RespondWithError(w, err, httpUnprocessableEntity)
+		case volume.ErrVolumeIsLeased:
+			RespondWithError(w, err, http.StatusLocked)
+		case volume.ErrLeaseNotHeld:
+			RespondWithError(w, err, http.StatusConflict)
+		default:
+			hLog.Error("failed-to-acquire-lease", err)
+			RespondWithError(w, ErrAcquireLeaseFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(baggageclaim.LeaseResponse{
+		Token:     lease.Token,
+		ExpiresAt: lease.ExpiresAt,
+	})
+	if err != nil {
+		hLog.Error("failed-to-encode", err)
+	}
+}
+
+func (vs *VolumeServer) ReleaseLease(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "release-lease", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	err := vs.volumeRepo.ReleaseLease(handle, req.Header.Get(baggageclaim.LeaseTokenHeader))
+	if err != nil {
+		switch err {
+		case volume.ErrVolumeDoesNotExist:
+			RespondWithError(w, ErrReleaseLeaseFailed, http.StatusNotFound)
+		case volume.ErrVolumeIsLeased:
+			RespondWithError(w, err, http.StatusLocked)
+		default:
+			hLog.Error("failed-to-release-lease", err)
+			RespondWithError(w, ErrReleaseLeaseFailed, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) StreamIn(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "stream-in", lager.Data{
+		"volume": handle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	var subPath string
+	if queryPath, ok := req.URL.Query()["path"]; ok {
+		subPath = queryPath[0]
+	}
+
+	advertiseEncodings(w)
+
+	bytesPerSecond, err := vs.bytesPerSecond(req)
+	if err != nil {
+		hLog.Info("invalid-bytes-per-second", lager.Data{"bytes-per-second": req.Header.Get(baggageclaim.StreamBytesPerSecondHeader)})
+		RespondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	opts := volume.StreamInOptions{
+		IdempotencyKey:  req.Header.Get("Idempotency-Key"),
+		SELinuxLabel:    req.URL.Query().Get("selinux-label"),
+		ContentEncoding: req.Header.Get("Content-Encoding"),
+		Xattrs:          req.URL.Query().Get("xattrs") == "true",
+		Delta:           req.URL.Query().Get("delta") == "true",
+		Layer:           req.URL.Query().Get("layer") == "true",
+		BytesPerSecond:  bytesPerSecond,
+		LeaseToken:      req.Header.Get(baggageclaim.LeaseTokenHeader),
+		Checksum:        streamChecksum(req),
+	}
+
+	var body io.Reader = req.Body
+
+	chunks := chunkedRequestBody(req)
+	if chunks != nil {
+		body = chunks
+	}
+
+	badStream, err := vs.volumeRepo.StreamIn(req.Context(), handle, subPath, body, opts)
+	if err != nil {
+		if chunks != nil && chunks.err != nil {
+			hLog.Info("invalid-chunk", lager.Data{"error": chunks.err.Error()})
+			RespondWithError(w, chunks.err, http.StatusBadRequest)
+			return
+		}
+
+		if isCanceled(err) {
+			hLog.Info("canceled")
+			RespondWithError(w, ErrStreamInFailed, httpClientClosedRequest)
+			return
+		}
+
+		if err == volume.ErrStreamInAlreadyApplied {
+			hLog.Info("already-applied")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if err == volume.ErrVolumeDoesNotExist {
+			hLog.Info("volume-not-found")
 			RespondWithError(w, ErrStreamInFailed, http.StatusNotFound)
 			return
 		}
 
-		if badStream {
-			hLog.Info("bad-stream-payload", lager.Data{"error": err.Error()})
-			RespondWithError(w, ErrStreamInFailed, http.StatusBadRequest)
+		if err == volume.ErrVolumeIsFrozen {
+			hLog.Info("volume-is-frozen")
+			RespondWithError(w, ErrStreamInFailed, http.StatusConflict)
+			return
+		}
+
+		if err == volume.ErrInsufficientInodes {
+			hLog.Info("inodes-exhausted")
+			RespondWithError(w, ErrStreamInFailed, http.StatusInsufficientStorage)
+			return
+		}
+
+		if err == volume.ErrTooManyStreams {
+			hLog.Info("too-many-streams")
+			RespondWithError(w, err, http.StatusServiceUnavailable)
+			return
+		}
+
+		if noSpace, ok := err.(volume.NoSpaceError); ok {
+			hLog.Info("out-of-space", lager.Data{"bytes-written": noSpace.BytesWritten})
+			RespondWithError(w, err, http.StatusInsufficientStorage)
+			return
+		}
+
+		if tooLarge, ok := err.(volume.StreamTooLargeError); ok {
+			hLog.Info("stream-too-large", lager.Data{"size": tooLarge.Size, "room": tooLarge.Room})
+			RespondWithError(w, err, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if err == volume.ErrUnknownStreamFormat {
+			hLog.Info("unknown-stream-format")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrInvalidSELinuxLabel {
+			hLog.Info("invalid-selinux-label")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrUnsafeSubPath {
+			hLog.Info("unsafe-sub-path")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrInvalidWhiteout {
+			hLog.Info("invalid-whiteout")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrInvalidLayerWhiteout {
+			hLog.Info("invalid-layer-whiteout")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrVolumeIsLeased {
+			hLog.Info("volume-is-leased")
+			RespondWithError(w, err, http.StatusLocked)
+			return
+		}
+
+		if err == volume.ErrUnsupportedContentEncoding {
+			hLog.Info("unsupported-content-encoding")
+			RespondWithError(w, err, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err == volume.ErrInvalidChecksum {
+			hLog.Info("invalid-checksum")
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrStreamChecksumMismatch {
+			hLog.Info("checksum-mismatch")
+			RespondWithError(w, err, httpUnprocessableEntity)
+			return
+		}
+
+		if badStream {
+			hLog.Info("bad-stream-payload", lager.Data{"error": err.Error()})
+			RespondWithError(w, ErrStreamInFailed, http.StatusBadRequest)
+			return
+		}
+
+		hLog.Error("failed-to-stream-into-volume", err)
+		RespondWithError(w, ErrStreamInFailed, http.StatusInternalServerError)
+		return
+	}
+
+	// the digest recorded once the stream landed; a later stream into the
+	// volume may have replaced it already, but it covers this one too
+	vol, found, err := vs.volumeRepo.GetVolume(handle)
+	if err != nil {
+		hLog.Error("failed-to-get-digest", err)
+	} else if found && vol.Digest != "" {
+		w.Header().Set(baggageclaim.VolumeDigestHeader, vol.Digest)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (vs *VolumeServer) CopyIn(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	sourceHandle := req.URL.Query().Get("source")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "copy-in", lager.Data{
+		"volume": handle,
+		"source": sourceHandle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	if sourceHandle == "" {
+		RespondWithError(w, ErrCopyInFailed, http.StatusBadRequest)
+		return
+	}
+
+	opts := volume.CopyInOptions{
+		SourcePath: req.URL.Query().Get("source-path"),
+		LeaseToken: req.Header.Get(baggageclaim.LeaseTokenHeader),
+	}
+
+	err := vs.volumeRepo.CopyIn(req.Context(), handle, req.URL.Query().Get("path"), sourceHandle, opts)
+	if err != nil {
+		if isCanceled(err) {
+			hLog.Info("canceled")
+			RespondWithError(w, ErrCopyInFailed, httpClientClosedRequest)
+			return
+		}
+
+		// which of the volumes is missing is told apart by the message
+		if err == volume.ErrVolumeDoesNotExist {
+			hLog.Info("volume-not-found")
+			RespondWithError(w, ErrCopyInFailed, http.StatusNotFound)
+			return
+		}
+
+		if err == volume.ErrSourceVolumeDoesNotExist {
+			hLog.Info("source-not-found")
+			RespondWithError(w, err, http.StatusNotFound)
 			return
 		}
 
-		hLog.Error("failed-to-stream-into-volume", err)
-		RespondWithError(w, ErrStreamInFailed, http.StatusInternalServerError)
+		if err == volume.ErrSourcePathDoesNotExist {
+			hLog.Info("source-path-not-found")
+			RespondWithError(w, ErrStreamOutNotFound, http.StatusNotFound)
+			return
+		}
+
+		if err == volume.ErrVolumeIsFrozen {
+			hLog.Info("volume-is-frozen")
+			RespondWithError(w, ErrCopyInFailed, http.StatusConflict)
+			return
+		}
+
+		if err == volume.ErrVolumeIsLeased {
+			hLog.Info("volume-is-leased")
+			RespondWithError(w, err, http.StatusLocked)
+			return
+		}
+
+		if err == volume.ErrUnsafeSubPath || err == volume.ErrCopyOverlapsSource {
+			hLog.Info("invalid-path", lager.Data{"error": err.Error()})
+			RespondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err == volume.ErrInsufficientInodes {
+			hLog.Info("inodes-exhausted")
+			RespondWithError(w, ErrCopyInFailed, http.StatusInsufficientStorage)
+			return
+		}
+
+		if err == volume.ErrTooManyStreams {
+			hLog.Info("too-many-streams")
+			RespondWithError(w, err, http.StatusServiceUnavailable)
+			return
+		}
+
+		hLog.Error("failed-to-copy-into-volume", err)
+		RespondWithError(w, ErrCopyInFailed, http.StatusInternalServerError)
 		return
 	}
 
+	vol, found, err := vs.volumeRepo.GetVolume(handle)
+	if err != nil {
+		hLog.Error("failed-to-get-digest", err)
+	} else if found && vol.Digest != "" {
+		w.Header().Set(baggageclaim.VolumeDigestHeader, vol.Digest)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// streamChecksum returns the checksum the request's stream is expected to
+// have, from its header or, once its body has been read, its trailer.
+func streamChecksum(req *http.Request) func() string {
+	if checksum := req.Header.Get(baggageclaim.StreamChecksumHeader); checksum != "" {
+		return func() string { return checksum }
+	}
+
+	if _, declared := req.Trailer[http.CanonicalHeaderKey(baggageclaim.StreamChecksumHeader)]; !declared {
+		return nil
+	}
+
+	return func() string {
+		return req.Trailer.Get(baggageclaim.StreamChecksumHeader)
+	}
+}
+
 func (vs *VolumeServer) StreamOut(w http.ResponseWriter, req *http.Request) {
 	handle := rata.Param(req, "handle")
 
-	hLog := vs.logger.Session("stream-out", lager.Data{
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "stream-out", lager.Data{
 		"volume": handle,
 	})
 
@@ -404,31 +1959,556 @@ func (vs *VolumeServer) StreamOut(w http.ResponseWriter, req *http.Request) {
 		subPath = queryPath[0]
 	}
 
-	err := vs.volumeRepo.StreamOut(handle, subPath, w)
+	var opts volume.StreamOutOptions
+	if modifiedSince := req.URL.Query().Get("modified-since"); modifiedSince != "" {
+		unix, err := strconv.ParseInt(modifiedSince, 10, 64)
+		if err != nil {
+			hLog.Info("invalid-modified-since", lager.Data{"modified-since": modifiedSince})
+			RespondWithError(w, ErrStreamOutFailed, http.StatusBadRequest)
+			return
+		}
+
+		opts.ModifiedSince = time.Unix(unix, 0)
+	}
+
+	opts.Consistent = req.URL.Query().Get("consistent") == "true"
+	opts.Xattrs = req.URL.Query().Get("xattrs") == "true"
+
+	// only asked for by clients that can extract sparse entries, so that
+	// others keep getting every file in full
+	opts.Sparse = req.URL.Query().Get("sparse") == "true"
+	opts.Canonical = req.URL.Query().Get("canonical") == "true"
+
+	bytesPerSecond, err := vs.bytesPerSecond(req)
+	if err != nil {
+		hLog.Info("invalid-bytes-per-second", lager.Data{"bytes-per-second": req.Header.Get(baggageclaim.StreamBytesPerSecondHeader)})
+		RespondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	opts.BytesPerSecond = bytesPerSecond
+
+	switch format := volume.StreamOutFormat(req.URL.Query().Get("format")); format {
+	case "", volume.StreamOutTar, volume.StreamOutFile:
+		opts.Format = format
+	default:
+		hLog.Info("invalid-format", lager.Data{"format": format})
+		RespondWithError(w, ErrInvalidStreamOutFormat, http.StatusBadRequest)
+		return
+	}
+
+	var streamedAs volume.StreamOutFormat
+	opts.StreamedAs = &streamedAs
+
+	if req.URL.Query().Get("downgrade") == "true" {
+		opts.Downgrade = &volume.DowngradeReport{}
+
+		// what was changed is only known once the stream is done
+		w.Header().Set("Trailer", baggageclaim.DowngradedTrailer)
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	advertiseEncodings(w)
+
+	chunkSize := req.URL.Query().Get("chunk-size")
+
+	// a range is of the stream as it is, so ranged responses are never
+	// encoded; chunked ones are checksummed chunk by chunk instead, and are
+	// always sent in full
+	byteRange, ranged, err := parseByteRange(req.Header.Get("Range"))
+	if err != nil && chunkSize == "" {
+		hLog.Info("invalid-range", lager.Data{"range": req.Header.Get("Range")})
+		RespondWithError(w, err, http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	ranged = ranged && chunkSize == ""
+
+	var (
+		body       http.ResponseWriter = w
+		compressed *encodingResponseWriter
+	)
+
+	if !ranged {
+		codec, acceptable := negotiateEncoding(req)
+		if !acceptable {
+			hLog.Info("no-acceptable-encoding", lager.Data{"accept-encoding": req.Header.Values("Accept-Encoding")})
+			RespondWithError(w, ErrStreamOutNotAcceptable, http.StatusNotAcceptable)
+			return
+		}
+
+		if codec != nil {
+			compressed = &encodingResponseWriter{ResponseWriter: w, codec: codec}
+			body = compressed
+		}
+	}
+
+	var streamed bool
+	if chunkSize != "" {
+		streamed = vs.streamOutChunks(req.Context(), hLog, w, body, handle, subPath, opts, chunkSize)
+	} else if ranged {
+		streamed = vs.streamOutRange(req.Context(), hLog, w, handle, subPath, opts, byteRange, req.Header.Get("If-Range"))
+	} else {
+		dest := &formatHeaderWriter{Writer: body, header: w.Header(), format: &streamedAs, contentType: true, path: subPath}
+
+		err := vs.volumeRepo.StreamOut(req.Context(), handle, subPath, dest, opts)
+		if err == nil {
+			// nothing is written for an empty file
+			dest.setHeaders()
+		}
+
+		if err != nil {
+			if compressed != nil && compressed.wroteBody {
+				// leave the encoded stream unfinished, so that it can't be
+				// mistaken for a complete one
+				logStreamOutAbandoned(hLog, "failed-while-streaming-compressed", err)
+			} else {
+				vs.respondToStreamOutError(hLog, w, err)
+			}
+		}
+
+		streamed = err == nil
+	}
+
+	if !streamed && compressed != nil {
+		// the encoder may write to the response on its own, which must have
+		// stopped by the time the handler returns
+		err := compressed.Abort()
+		if err != nil {
+			hLog.Error("failed-to-abort-compressed-stream", err)
+		}
+	}
+
+	if streamed && compressed != nil {
+		err := compressed.Close()
+		if err != nil {
+			hLog.Error("failed-to-finish-compressed-stream", err)
+			return
+		}
+	}
+
+	if streamed && opts.Downgrade != nil {
+		w.Header().Set(baggageclaim.DowngradedTrailer, opts.Downgrade.String())
+	}
+}
+
+// bytesPerSecond is the cap on the request's stream: the server's, or the
+// one asked for in the header if that is lower.
+func (vs *VolumeServer) bytesPerSecond(req *http.Request) (int64, error) {
+	header := req.Header.Get(baggageclaim.StreamBytesPerSecondHeader)
+	if header == "" {
+		return vs.streamBytesPerSecond, nil
+	}
+
+	requested, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || requested < 1 {
+		return 0, ErrInvalidStreamBytesPerSecond
+	}
+
+	return vs.capBytesPerSecond(requested), nil
+}
+
+// capBytesPerSecond lowers the cap asked for to the server's, if that is
+// lower.
+func (vs *VolumeServer) capBytesPerSecond(requested int64) int64 {
+	if vs.streamBytesPerSecond > 0 && vs.streamBytesPerSecond < requested {
+		return vs.streamBytesPerSecond
+	}
+
+	return requested
+}
+
+// streamOutRange answers with only the range of the stream asked for. The
+// stream is streamed out once beforehand to find its size, which is how big
+// it is the next time too, as a volume is streamed out as the same bytes each
+// time while it is unchanged. Every ranged request so costs the whole of the
+// stream twice, however little of it is asked for.
+//
+// What was streamed out beforehand is digested into a strong ETag, which a
+// client resuming a stream sends back as If-Range: if the volume has changed
+// since, it is answered with the whole of the stream it now is, rather than
+// a range of it that would not add up with what it already has.
+func (vs *VolumeServer) streamOutRange(ctx context.Context, hLog lager.Logger, w http.ResponseWriter, handle string, subPath string, opts volume.StreamOutOptions, byteRange byteRange, ifRange string) bool {
+	sizing := opts
+	sizing.StreamedAs = nil
+	sizing.BytesPerSecond = 0
+
+	if opts.Downgrade != nil {
+		sizing.Downgrade = &volume.DowngradeReport{}
+	}
+
+	size := &sizeWriter{}
+	digest := sha256.New()
+
+	err := vs.volumeRepo.StreamOut(ctx, handle, subPath, io.MultiWriter(size, digest), sizing)
+	if err != nil {
+		vs.respondToStreamOutError(hLog, w, err)
+		return false
+	}
+
+	etag := `"` + hex.EncodeToString(digest.Sum(nil)) + `"`
+	w.Header().Set("ETag", etag)
+
+	if ifRange != "" && ifRange != etag {
+		hLog.Info("range-of-changed-stream", lager.Data{"if-range": ifRange, "etag": etag})
+		return vs.streamOutWhole(ctx, hLog, w, handle, subPath, opts)
+	}
+
+	start, length, err := byteRange.resolve(size.size)
+	if err != nil {
+		hLog.Info("range-not-satisfiable", lager.Data{"size": size.size})
+		w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(size.size, 10))
+		RespondWithError(w, err, http.StatusRequestedRangeNotSatisfiable)
+		return false
+	}
+
+	dest := &rangeWriter{ResponseWriter: w, start: start, length: length, size: size.size}
+	format := &formatHeaderWriter{Writer: dest, header: w.Header(), format: opts.StreamedAs, contentType: true, path: subPath}
+
+	err = vs.volumeRepo.StreamOut(ctx, handle, subPath, format, opts)
+	if dest.done() {
+		// the stream is stopped once the range has been written, failing it
+		return true
+	}
+
+	if err == nil {
+		err = errStreamShortened
+	}
+
+	if dest.wroteBody {
+		logStreamOutAbandoned(hLog, "failed-while-streaming-range", err)
+	} else {
+		vs.respondToStreamOutError(hLog, w, err)
+	}
+
+	return false
+}
+
+// streamOutWhole answers a ranged request with the whole of the stream,
+// left unencoded as a range of it would be.
+func (vs *VolumeServer) streamOutWhole(ctx context.Context, hLog lager.Logger, w http.ResponseWriter, handle string, subPath string, opts volume.StreamOutOptions) bool {
+	format := &formatHeaderWriter{Writer: w, header: w.Header(), format: opts.StreamedAs, contentType: true, path: subPath}
+
+	err := vs.volumeRepo.StreamOut(ctx, handle, subPath, format, opts)
+	if err == nil {
+		format.setHeaders()
+		return true
+	}
+
+	if format.headersSet {
+		logStreamOutAbandoned(hLog, "failed-while-streaming-whole", err)
+	} else {
+		vs.respondToStreamOutError(hLog, w, err)
+	}
+
+	return false
+}
+
+// streamOutChunks streams the volume out as chunks written to body, which is
+// w itself unless the response is compressed. Errors are written to w.
+func (vs *VolumeServer) streamOutChunks(ctx context.Context, hLog lager.Logger, w http.ResponseWriter, body http.ResponseWriter, handle string, subPath string, opts volume.StreamOutOptions, chunkSize string) bool {
+	size, err := parseChunkSize(chunkSize)
+	if err != nil {
+		hLog.Info("invalid-chunk-size", lager.Data{"chunk-size": chunkSize})
+		RespondWithError(w, err, http.StatusBadRequest)
+		return false
+	}
+
+	dest := &lazyContentTypeWriter{ResponseWriter: body}
+
+	chunks := newChunkWriter(dest, size)
+	dest.contentType = chunks.ContentType()
+
+	format := &formatHeaderWriter{Writer: chunks, header: w.Header(), format: opts.StreamedAs}
+
+	err = vs.volumeRepo.StreamOut(ctx, handle, subPath, format, opts)
+	if err == nil {
+		format.setHeaders()
+		err = chunks.Close()
+	}
+
+	if err != nil {
+		if dest.wroteBody {
+			// leave the response without its closing boundary, so that it
+			// can't be mistaken for a complete stream
+			logStreamOutAbandoned(hLog, "failed-while-streaming-chunks", err)
+			return false
+		}
+
+		vs.respondToStreamOutError(hLog, w, err)
+		return false
+	}
+
+	return true
+}
+
+func (vs *VolumeServer) respondToStreamOutError(hLog lager.Logger, w http.ResponseWriter, err error) {
+	if isCanceled(err) {
+		hLog.Info("canceled")
+		RespondWithError(w, ErrStreamOutFailed, httpClientClosedRequest)
+		return
+	}
+
+	if err == volume.ErrVolumeDoesNotExist {
+		hLog.Info("volume-not-found")
+		RespondWithError(w, ErrStreamOutFailed, http.StatusNotFound)
+		return
+	}
+
+	if os.IsNotExist(err) {
+		hLog.Info("source-path-not-found")
+		RespondWithError(w, ErrStreamOutNotFound, http.StatusNotFound)
+		return
+	}
+
+	if err == volume.ErrTooManyStreams {
+		hLog.Info("too-many-streams")
+		RespondWithError(w, err, http.StatusServiceUnavailable)
+		return
+	}
+
+	if err == volume.ErrNotARegularFile || err == volume.ErrStreamOutOptionsNeedTar || err == volume.ErrUnsafeSubPath {
+		hLog.Info("cannot-stream-out-as-requested", lager.Data{"error": err.Error()})
+		RespondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	hLog.Error("failed-to-stream-out", err)
+	RespondWithError(w, ErrStreamOutFailed, http.StatusInternalServerError)
+}
+
+func (vs *VolumeServer) DiffVolumes(w http.ResponseWriter, req *http.Request) {
+	handle := rata.Param(req, "handle")
+	baseHandle := req.URL.Query().Get("base")
+
+	vs = vs.forRequest(req.Context())
+
+	hLog := requestSession(req.Context(), vs.logger, "diff-volumes", lager.Data{
+		"volume": handle,
+		"base":   baseHandle,
+	})
+
+	hLog.Debug("start")
+	defer hLog.Debug("done")
+
+	if baseHandle == "" {
+		RespondWithError(w, ErrDiffVolumesFailed, http.StatusBadRequest)
+		return
+	}
+
+	var (
+		dest *lazyContentTypeWriter
+		err  error
+	)
+
+	if req.URL.Query().Get("patch") == "true" {
+		dest = &lazyContentTypeWriter{ResponseWriter: w, contentType: "application/x-tar"}
+
+		err = vs.volumeRepo.StreamOutDiff(handle, baseHandle, dest)
+	} else {
+		dest = &lazyContentTypeWriter{ResponseWriter: w, contentType: "application/x-ndjson"}
+
+		encoder := json.NewEncoder(dest)
+
+		err = vs.volumeRepo.DiffVolumes(handle, baseHandle, func(entry volume.DiffEntry) error {
+			return encoder.Encode(entry)
+		})
+	}
+
 	if err != nil {
+		if dest.wroteBody {
+			// too late to report the error with a status code; cut the
+			// response short instead
+			hLog.Error("failed-while-streaming-diff", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
 		if err == volume.ErrVolumeDoesNotExist {
 			hLog.Info("volume-not-found")
-			RespondWithError(w, ErrStreamOutFailed, http.StatusNotFound)
+			RespondWithError(w, ErrDiffVolumesFailed, http.StatusNotFound)
 			return
 		}
 
-		if os.IsNotExist(err) {
-			hLog.Info("source-path-not-found")
-			RespondWithError(w, ErrStreamOutNotFound, http.StatusNotFound)
+		if err == volume.ErrTooManyStreams {
+			hLog.Info("too-many-streams")
+			RespondWithError(w, err, http.StatusServiceUnavailable)
 			return
 		}
 
-		hLog.Error("failed-to-stream-out", err)
-		RespondWithError(w, ErrStreamOutFailed, http.StatusInternalServerError)
+		hLog.Error("failed-to-diff-volumes", err)
+		RespondWithError(w, ErrDiffVolumesFailed, http.StatusInternalServerError)
 		return
 	}
 }
 
-func (vs *VolumeServer) generateHandle() (string, error) {
-	handle, err := uuid.NewV4()
-	if err != nil {
-		return "", err
+// lazyContentTypeWriter only sets the Content-Type of a streamed response once
+// the first bytes are written, so that errors detected before any output is
+// produced can still be reported as JSON.
+type lazyContentTypeWriter struct {
+	http.ResponseWriter
+
+	contentType string
+	wroteBody   bool
+}
+
+func (w *lazyContentTypeWriter) Write(p []byte) (int, error) {
+	if !w.wroteBody {
+		w.Header().Set("Content-Type", w.contentType)
+		w.wroteBody = true
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// formatHeaderWriter sets the header saying what format a stream-out is in
+// before the first of it is written, once the repository has decided. A
+// file streamed on its own is also given a Content-Type going by its name,
+// unless the stream is chunked.
+type formatHeaderWriter struct {
+	io.Writer
+
+	header      http.Header
+	format      *volume.StreamOutFormat
+	contentType bool
+	path        string
+
+	headersSet bool
+}
+
+func (w *formatHeaderWriter) Write(p []byte) (int, error) {
+	w.setHeaders()
+	return w.Writer.Write(p)
+}
+
+func (w *formatHeaderWriter) setHeaders() {
+	if w.headersSet {
+		return
+	}
+
+	w.headersSet = true
+
+	w.header.Set(baggageclaim.StreamOutFormatHeader, string(*w.format))
+
+	if w.contentType && *w.format == volume.StreamOutFile {
+		contentType := mime.TypeByExtension(filepath.Ext(w.path))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		w.header.Set("Content-Type", contentType)
+	}
+}
+
+// encodingResponseWriter encodes the response body with the codec. Like
+// lazyContentTypeWriter, it only sets its header once the body is written,
+// so that an error can still be responded with as is.
+type encodingResponseWriter struct {
+	http.ResponseWriter
+
+	codec     volume.Codec
+	encoder   io.WriteCloser
+	wroteBody bool
+}
+
+func (w *encodingResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteBody {
+		// the encoder may write to the response as soon as it is made
+		w.Header().Set("Content-Encoding", w.codec.Name())
+		w.Header().Del("Content-Length")
+
+		encoder, err := w.codec.NewWriter(w.ResponseWriter)
+		if err != nil {
+			w.Header().Del("Content-Encoding")
+			return 0, err
+		}
+
+		w.encoder = encoder
+		w.wroteBody = true
+	}
+
+	return w.encoder.Write(p)
+}
+
+// Close finishes the encoded stream, if anything was written at all.
+func (w *encodingResponseWriter) Close() error {
+	if !w.wroteBody {
+		return nil
+	}
+
+	return w.encoder.Close()
+}
+
+// Abort gives up on the encoded stream, leaving it unfinished, so that it
+// can't be mistaken for a complete one.
+func (w *encodingResponseWriter) Abort() error {
+	if !w.wroteBody {
+		return nil
+	}
+
+	if aborter, ok := w.encoder.(volume.Aborter); ok {
+		return aborter.Abort()
+	}
+
+	return nil
+}
+
+// decodeBody decodes the JSON request body, bounding the time spent reading
+// it so that a client trickling its body can't hold the connection forever.
+// Stream-in bodies are not read through here.
+func (vs *VolumeServer) decodeBody(w http.ResponseWriter, req *http.Request, v interface{}) error {
+	if vs.bodyReadTimeout == 0 {
+		return json.NewDecoder(req.Body).Decode(v)
+	}
+
+	controller := http.NewResponseController(w)
+
+	deadlineErr := controller.SetReadDeadline(time.Now().Add(vs.bodyReadTimeout))
+
+	err := json.NewDecoder(req.Body).Decode(v)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		// leave the deadline in place so the server gives up on the rest of
+		// the body instead of waiting for it before sending the response
+		return ErrRequestBodyTimeout
+	}
+
+	if deadlineErr == nil {
+		controller.SetReadDeadline(time.Time{})
+	}
+
+	return err
+}
+
+// logStreamOutAbandoned logs a stream-out that failed after some of it was
+// sent, which is only an error if the client was still listening.
+func logStreamOutAbandoned(hLog lager.Logger, action string, err error) {
+	if isCanceled(err) {
+		hLog.Info("canceled")
+		return
 	}
 
-	return handle.String(), nil
+	hLog.Error(action, err)
 }
+
+// isCanceled tells whether a stream was given up on because the request's
+// context is done, which is the client's doing rather than ours.
+func isCanceled(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
+
+func decodeErrorStatus(err error) int {
+	if err == ErrRequestBodyTimeout {
+		return http.StatusRequestTimeout
+	}
+
+	return http.StatusBadRequest
+}
+
+// maxHandleGenerations bounds how many handles are generated for a create or
+// clone whose generated handles keep turning out to be taken.
+const maxHandleGenerations = 3
+
+// errHandleGenerationFailed is for a create whose handle could not be
+// generated, which has already been logged.
+var errHandleGenerationFailed = errors.New("failed to generate handle")
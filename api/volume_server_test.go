@@ -2,37 +2,70 @@ package api_test
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/lagertest"
 
 	"github.com/concourse/baggageclaim"
 	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/metrics"
+	"github.com/concourse/baggageclaim/reaper"
 	"github.com/concourse/baggageclaim/uidgid"
 	"github.com/concourse/baggageclaim/volume"
 	"github.com/concourse/baggageclaim/volume/driver"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
 )
 
 var _ = Describe("Volume Server", func() {
 	var (
 		handler http.Handler
+		logger  *lagertest.TestLogger
 
 		volumeDir string
 		tempDir   string
+
+		bodyReadTimeout  time.Duration
+		fakeClock        *fakeclock.FakeClock
+		labelSchemas     volume.LabelSchemas
+		propertyLimits   volume.PropertyLimits
+		minFreeInodes    uint64
+		deletedRetention time.Duration
+		drainState       *api.DrainState
+		events           *volume.EventHub
+		unprivilegedIDs  uidgid.IDRange
+		extractionUmask  os.FileMode
 	)
 
 	BeforeEach(func() {
@@ -47,23 +80,33 @@ var _ = Describe("Volume Server", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		volumeDir = tempDir
+		bodyReadTimeout = 0
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		labelSchemas = nil
+		propertyLimits = volume.PropertyLimits{}
+		minFreeInodes = 0
+		deletedRetention = 0
+		drainState = &api.DrainState{}
+		events = volume.NewEventHub()
+		unprivilegedIDs = uidgid.IDRange{}
+		extractionUmask = 0
 	})
 
 	JustBeforeEach(func() {
-		logger := lagertest.NewTestLogger("volume-server")
+		logger = lagertest.NewTestLogger("volume-server")
 
-		fs, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumeDir)
+		fs, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumeDir, nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		var privilegedNamespacer, unprivilegedNamespacer uidgid.Namespacer
 		if runtime.GOOS == "linux" {
 			privilegedNamespacer = &uidgid.UidNamespacer{
-				Translator: uidgid.NewTranslator(uidgid.NewPrivilegedMapper()),
+				Translator: uidgid.NewTranslator(uidgid.NewPrivilegedMapper(unprivilegedIDs)),
 				Logger:     logger.Session("uid-namespacer"),
 			}
 
 			unprivilegedNamespacer = &uidgid.UidNamespacer{
-				Translator: uidgid.NewTranslator(uidgid.NewUnprivilegedMapper()),
+				Translator: uidgid.NewTranslator(uidgid.NewUnprivilegedMapper(unprivilegedIDs)),
 				Logger:     logger.Session("uid-namespacer"),
 			}
 		} else {
@@ -73,15 +116,28 @@ var _ = Describe("Volume Server", func() {
 
 		repo := volume.NewRepository(
 			logger,
+			fakeClock,
 			fs,
 			volume.NewLockManager(),
+			volume.NewPathLockManager(),
 			privilegedNamespacer,
 			unprivilegedNamespacer,
+			labelSchemas,
+			time.Minute,
+			volume.NoopDestroyAuditLog{},
+			minFreeInodes,
+			1,
+			nil,
+			events,
+			propertyLimits,
+			deletedRetention,
+			volume.StreamLimits{},
+			extractionUmask,
 		)
 
-		strategerizer := volume.NewStrategerizer()
+		strategerizer := volume.NewStrategerizer(0, 0, 0)
 
-		handler, err = api.NewHandler(logger, strategerizer, repo)
+		handler, err = api.NewHandler(logger, strategerizer, repo, fakeClock, "naive", bodyReadTimeout, drainState, reaper.NewReaper(fakeClock, repo, 0, reaper.RetryPolicy{}, 0, metrics.NewRegistry(), reaper.EvictionPolicy{}), metrics.NewRegistry(), fs, 0, events, 0, api.UUIDHandleGenerator{})
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -90,21 +146,286 @@ var _ = Describe("Volume Server", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	Describe("request IDs", func() {
+		It("tags everything logged for the request with its ID, by the handler and the repository alike", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes/bogus-handle", nil)
+			request.Header.Set(baggageclaim.RequestIDHeader, "some-request-id")
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Header().Get(baggageclaim.RequestIDHeader)).To(Equal("some-request-id"))
+
+			messages := []string{}
+			for _, log := range logger.Logs() {
+				Expect(log.Data).To(HaveKeyWithValue("request-id", "some-request-id"))
+				messages = append(messages, log.Message)
+			}
+
+			Expect(messages).To(ContainElement("volume-server.volume-server.get-volume.start"))
+			Expect(messages).To(ContainElement("volume-server.get-volume.volume-not-found"))
+		})
+	})
+
+	Describe("reading request bodies", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			bodyReadTimeout = 100 * time.Millisecond
+		})
+
+		JustBeforeEach(func() {
+			server = httptest.NewServer(handler)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("returns 408 when the body is not sent in time", func() {
+			conn, err := net.Dial("tcp", server.Listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			_, err = fmt.Fprintf(conn, "POST /volumes HTTP/1.1\r\nHost: baggageclaim\r\nContent-Length: 100\r\n\r\n{\"handle\":")
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusRequestTimeout))
+		})
+	})
+
 	Describe("listing the volumes", func() {
-		var recorder *httptest.ResponseRecorder
+		var (
+			existing []baggageclaim.VolumeRequest
+			query    string
+
+			recorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			existing = nil
+			query = ""
+		})
 
 		JustBeforeEach(func() {
+			for i, volumeRequest := range existing {
+				body := &bytes.Buffer{}
+				err := json.NewEncoder(body).Encode(volumeRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(201))
+
+				// creation is recorded to the second; spread them out
+				// rather than wait
+				created := fmt.Sprintf(`{"created_at":%d,"strategy":"empty"}`, 1000+i)
+				err = ioutil.WriteFile(filepath.Join(volumeDir, "live", volumeRequest.Handle, "created.json"), []byte(created), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
 			recorder = httptest.NewRecorder()
-			request, _ := http.NewRequest("GET", "/volumes", nil)
+			request, _ := http.NewRequest("GET", "/volumes"+query, nil)
 
 			handler.ServeHTTP(recorder, request)
 		})
 
+		handles := func() []string {
+			var volumes []baggageclaim.VolumeResponse
+			err := json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+
+			handles := []string{}
+			for _, vol := range volumes {
+				handles = append(handles, vol.Handle)
+			}
+
+			return handles
+		}
+
 		Context("when there are no volumes", func() {
 			It("returns an empty array", func() {
 				Expect(recorder.Body).To(MatchJSON(`[]`))
 			})
 		})
+
+		Context("when there are volumes", func() {
+			BeforeEach(func() {
+				for _, handle := range []string{"c", "a", "d", "b"} {
+					properties := baggageclaim.VolumeProperties{"kind": "even"}
+					if handle == "a" || handle == "c" {
+						properties = baggageclaim.VolumeProperties{"kind": "odd"}
+					}
+
+					existing = append(existing, baggageclaim.VolumeRequest{
+						Handle:     handle,
+						Strategy:   encStrategy(map[string]string{"type": "empty"}),
+						Properties: properties,
+					})
+				}
+			})
+
+			Context("without paging or sorting", func() {
+				It("lists all of them without a count", func() {
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.VolumeCountHeader)).To(BeEmpty())
+					Expect(handles()).To(ConsistOf("a", "b", "c", "d"))
+				})
+			})
+
+			Context("with a limit and an offset", func() {
+				BeforeEach(func() {
+					query = "?limit=2&offset=1"
+				})
+
+				It("lists that page of them by handle, with how many there are", func() {
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.VolumeCountHeader)).To(Equal("4"))
+					Expect(handles()).To(Equal([]string{"b", "c"}))
+				})
+			})
+
+			Context("with an offset past the last of them", func() {
+				BeforeEach(func() {
+					query = "?offset=4"
+				})
+
+				It("lists none of them", func() {
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.VolumeCountHeader)).To(Equal("4"))
+					Expect(recorder.Body).To(MatchJSON(`[]`))
+				})
+			})
+
+			Context("sorted by when they were created", func() {
+				BeforeEach(func() {
+					query = "?sort=created-at&limit=3"
+				})
+
+				It("lists them oldest first", func() {
+					Expect(recorder.Code).To(Equal(200))
+					Expect(handles()).To(Equal([]string{"c", "a", "d"}))
+				})
+			})
+
+			Context("filtered by property", func() {
+				BeforeEach(func() {
+					query = "?kind=even&sort=created-at&limit=1&offset=1"
+				})
+
+				It("pages through the volumes that match", func() {
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.VolumeCountHeader)).To(Equal("2"))
+					Expect(handles()).To(Equal([]string{"b"}))
+				})
+			})
+
+			for _, invalid := range []string{"?limit=-1", "?offset=some", "?sort=size"} {
+				invalid := invalid
+
+				Context("with "+invalid, func() {
+					BeforeEach(func() {
+						query = invalid
+					})
+
+					It("returns 422", func() {
+						Expect(recorder.Code).To(Equal(422))
+					})
+				})
+			}
+		})
+	})
+
+	Describe("reading volumes as gob", func() {
+		get := func(path string, accept string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", path, nil)
+			request.Header.Set("Accept", accept)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			return recorder
+		}
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties:   baggageclaim.VolumeProperties{"some": "property"},
+				TTLInSeconds: 60,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+		})
+
+		It("lists the volumes as client responses", func() {
+			recorder := get("/volumes", baggageclaim.GobContentType+", application/json")
+			Expect(recorder.Header().Get("Content-Type")).To(Equal(baggageclaim.GobContentType))
+
+			var volumes []baggageclaim.VolumeResponse
+			err := gob.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Handle).To(Equal("some-handle"))
+			Expect(volumes[0].Properties).To(Equal(baggageclaim.VolumeProperties{"some": "property"}))
+			Expect(volumes[0].TTLInSeconds).To(Equal(uint(60)))
+			Expect(volumes[0].Strategy).To(Equal(volume.StrategyEmpty))
+		})
+
+		It("gets a volume and its stats", func() {
+			recorder := get("/volumes/some-handle", baggageclaim.GobContentType)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal(baggageclaim.GobContentType))
+
+			var vol baggageclaim.VolumeResponse
+			err := gob.NewDecoder(recorder.Body).Decode(&vol)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Handle).To(Equal("some-handle"))
+			Expect(vol.TTLInSeconds).To(Equal(uint(60)))
+			Expect(vol.Driver).To(Equal("naive"))
+			Expect(vol.FilesystemType).NotTo(BeEmpty())
+
+			recorder = get("/volumes/some-handle/stats", baggageclaim.GobContentType)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal(baggageclaim.GobContentType))
+
+			var stats baggageclaim.VolumeStatsResponse
+			err = gob.NewDecoder(recorder.Body).Decode(&stats)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.Driver).To(Equal("naive"))
+			Expect(stats.FilesystemType).To(Equal(vol.FilesystemType))
+			Expect(stats.SizeExact).To(BeFalse())
+			Expect(stats.FreeBytes).NotTo(BeZero())
+
+			recorder = get("/volumes/some-handle/stats", "application/json")
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var jsonStats map[string]interface{}
+			err = json.NewDecoder(recorder.Body).Decode(&jsonStats)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jsonStats).To(HaveKeyWithValue("size_exact", false))
+			Expect(jsonStats).To(HaveKey("free_bytes"))
+		})
+
+		It("keeps sending JSON to requests that do not ask for gob", func() {
+			recorder := get("/volumes", "application/json")
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			recorder = get("/volumes", baggageclaim.GobContentType+";q=0, application/json")
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var volumes []volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+		})
 	})
 
 	Describe("querying for volumes with properties", func() {
@@ -164,14 +485,12 @@ var _ = Describe("Volume Server", func() {
 		})
 	})
 
-	Describe("streaming tar files into volumes", func() {
-		var (
-			myVolume     volume.Volume
-			tarBuffer    *(bytes.Buffer)
-			isPrivileged bool
-		)
+	Describe("when draining", func() {
+		BeforeEach(func() {
+			drainState.Drain()
+		})
 
-		JustBeforeEach(func() {
+		It("refuses to create volumes with 503", func() {
 			body := &bytes.Buffer{}
 
 			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
@@ -179,327 +498,377 @@ var _ = Describe("Volume Server", func() {
 				Strategy: encStrategy(map[string]string{
 					"type": "empty",
 				}),
-				Privileged: isPrivileged,
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			request, err := http.NewRequest("POST", "/volumes", body)
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Describe("when the volumes filesystem is low on inodes", func() {
+		BeforeEach(func() {
+			minFreeInodes = math.MaxUint64
+		})
+
+		It("refuses to create volumes with 507", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
 			Expect(err).NotTo(HaveOccurred())
 
 			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
 			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(201))
+			Expect(recorder.Code).To(Equal(http.StatusInsufficientStorage))
+		})
+	})
 
-			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+	Describe("creating a mutation-heavy COW volume", func() {
+		It("copies the parent and reports the copy strategy", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "parent-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
 			Expect(err).NotTo(HaveOccurred())
-		})
 
-		Context("when tar file is valid", func() {
-			BeforeEach(func() {
-				tarBuffer = new(bytes.Buffer)
-				tarWriter := tar.NewWriter(tarBuffer)
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
 
-				err := tarWriter.WriteHeader(&tar.Header{
-					Name: "some-file",
-					Mode: 0600,
-					Size: int64(len("file-content")),
-				})
-				Expect(err).NotTo(HaveOccurred())
-				_, err = tarWriter.Write([]byte("file-content"))
-				Expect(err).NotTo(HaveOccurred())
+			var parent volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&parent)
+			Expect(err).NotTo(HaveOccurred())
 
-				err = tarWriter.Close()
-				Expect(err).NotTo(HaveOccurred())
+			err = ioutil.WriteFile(filepath.Join(parent.Path, "some-file"), []byte("some-content"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			body.Reset()
+			err = json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "child-handle",
+				Strategy: encStrategy(map[string]string{
+					"type":   "cow",
+					"volume": "parent-handle",
+				}),
+				MutationHeavy: true,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
-			It("extracts the tar stream into the volume's DataPath", func() {
-				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
-				recorder := httptest.NewRecorder()
-				handler.ServeHTTP(recorder, request)
-				Expect(recorder.Code).To(Equal(204))
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
 
-				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
-				Expect(tarContentsPath).To(BeAnExistingFile())
+			var child volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&child)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(child.Strategy).To(Equal(volume.StrategyCopy))
 
-				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
-			})
+			contents, err := ioutil.ReadFile(filepath.Join(child.Path, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-content"))
+		})
+	})
 
-			Context("when volume is not privileged", func() {
-				BeforeEach(func() {
-					isPrivileged = false
-				})
+	Describe("creating a volume from a URL", func() {
+		var (
+			remote       *httptest.Server
+			remoteStatus int
+			archive      []byte
+		)
 
-				It("namespaces volume path", func() {
-					if runtime.GOOS != "linux" {
-						Skip("only runs somewhere we can run privileged")
-						return
-					}
+		BeforeEach(func() {
+			buffer := &bytes.Buffer{}
+			tarWriter := tar.NewWriter(buffer)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tarWriter.Write([]byte("some-content"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
 
-					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
-					recorder := httptest.NewRecorder()
-					handler.ServeHTTP(recorder, request)
-					Expect(recorder.Code).To(Equal(204))
+			archive = buffer.Bytes()
+			remoteStatus = http.StatusOK
 
-					tarInfoPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
-					Expect(tarInfoPath).To(BeAnExistingFile())
+			remote = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(remoteStatus)
+				w.Write(archive)
+			}))
+		})
 
-					stat, err := os.Stat(tarInfoPath)
-					Expect(err).ToNot(HaveOccurred())
+		AfterEach(func() {
+			remote.Close()
+		})
 
-					maxUID := uidgid.MustGetMaxValidUID()
-					maxGID := uidgid.MustGetMaxValidGID()
+		create := func(strategy baggageclaim.URLStrategy) *httptest.ResponseRecorder {
+			body := &bytes.Buffer{}
 
-					sysStat := stat.Sys().(*syscall.Stat_t)
-					Expect(sysStat.Uid).To(Equal(uint32(maxUID)))
-					Expect(sysStat.Gid).To(Equal(uint32(maxGID)))
-				})
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:   "some-handle",
+				Strategy: strategy.Encode(),
 			})
+			Expect(err).NotTo(HaveOccurred())
 
-			Context("when volume privileged", func() {
-				BeforeEach(func() {
-					isPrivileged = true
-				})
-
-				It("namespaces volume path", func() {
-					if runtime.GOOS != "linux" {
-						Skip("only runs somewhere we can run privileged")
-						return
-					}
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
 
-					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
-					recorder := httptest.NewRecorder()
-					handler.ServeHTTP(recorder, request)
-					Expect(recorder.Code).To(Equal(204))
+		fieldErrors := func(recorder *httptest.ResponseRecorder) []baggageclaim.FieldError {
+			var response api.ErrorResponse
+			Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+			return response.Fields
+		}
 
-					tarInfoPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
-					Expect(tarInfoPath).To(BeAnExistingFile())
+		volumeCount := func() int {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes", nil)
+			handler.ServeHTTP(recorder, request)
 
-					stat, err := os.Stat(tarInfoPath)
-					Expect(err).ToNot(HaveOccurred())
+			var volumes []volume.Volume
+			Expect(json.NewDecoder(recorder.Body).Decode(&volumes)).To(Succeed())
+			return len(volumes)
+		}
 
-					sysStat := stat.Sys().(*syscall.Stat_t)
-					Expect(sysStat.Uid).To(Equal(uint32(0)))
-					Expect(sysStat.Gid).To(Equal(uint32(0)))
-				})
+		It("creates the volume with the fetched contents", func() {
+			sum := sha256.Sum256(archive)
+
+			recorder := create(baggageclaim.URLStrategy{
+				URL:      remote.URL + "/some-archive.tar",
+				Checksum: "sha256:" + hex.EncodeToString(sum[:]),
 			})
+			Expect(recorder.Code).To(Equal(201))
+
+			var created volume.Volume
+			Expect(json.NewDecoder(recorder.Body).Decode(&created)).To(Succeed())
+			Expect(created.Strategy).To(Equal(volume.StrategyURL))
+
+			contents, err := ioutil.ReadFile(filepath.Join(created.Path, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-content"))
 		})
 
-		Context("when the tar stream is invalid", func() {
-			BeforeEach(func() {
-				tarBuffer = new(bytes.Buffer)
-				tarBuffer.Write([]byte("This is an invalid tar file!"))
+		It("refuses with 422 when the fetched archive does not match the checksum", func() {
+			recorder := create(baggageclaim.URLStrategy{
+				URL:      remote.URL + "/some-archive.tar",
+				Checksum: "sha256:" + strings.Repeat("0", 64),
 			})
+			Expect(recorder.Code).To(Equal(422))
+			Expect(fieldErrors(recorder)).To(Equal([]baggageclaim.FieldError{
+				{Field: "strategy.checksum", Code: baggageclaim.FieldErrorChecksumMismatch, Message: volume.ErrChecksumMismatch.Error()},
+			}))
 
-			It("returns 400 when err is exitError", func() {
-				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", myVolume.Handle), tarBuffer)
-				recorder := httptest.NewRecorder()
-				handler.ServeHTTP(recorder, request)
-				Expect(recorder.Code).To(Equal(400))
-			})
+			Expect(volumeCount()).To(BeZero())
 		})
 
-		It("returns 404 when volume is not found", func() {
-			tarBuffer = new(bytes.Buffer)
-			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", "invalid-handle"), tarBuffer)
-			recorder := httptest.NewRecorder()
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(404))
+		It("refuses with 422 when the remote server refuses the request", func() {
+			remoteStatus = http.StatusNotFound
+
+			recorder := create(baggageclaim.URLStrategy{URL: remote.URL + "/some-archive.tar"})
+			Expect(recorder.Code).To(Equal(422))
+
+			fields := fieldErrors(recorder)
+			Expect(fields).To(HaveLen(1))
+			Expect(fields[0].Field).To(Equal("strategy.url"))
+			Expect(fields[0].Code).To(Equal(baggageclaim.FieldErrorFetchFailed))
 		})
-	})
 
-	Describe("streaming tar out of a volume", func() {
-		var (
-			myVolume  volume.Volume
-			tarBuffer *(bytes.Buffer)
-		)
+		It("fails with 502 when the remote server fails", func() {
+			remoteStatus = http.StatusInternalServerError
 
-		BeforeEach(func() {
-			tarBuffer = new(bytes.Buffer)
+			recorder := create(baggageclaim.URLStrategy{URL: remote.URL + "/some-archive.tar"})
+			Expect(recorder.Code).To(Equal(http.StatusBadGateway))
+
+			fields := fieldErrors(recorder)
+			Expect(fields).To(HaveLen(1))
+			Expect(fields[0].Code).To(Equal(baggageclaim.FieldErrorFetchFailed))
+
+			Expect(volumeCount()).To(BeZero())
 		})
+	})
 
-		JustBeforeEach(func() {
+	Describe("cloning a volume", func() {
+		var source volume.Volume
+
+		clone := func(srcHandle string, handle string) *httptest.ResponseRecorder {
 			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.CloneVolumeRequest{Handle: handle})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/clone", srcHandle), body)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
 
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
 			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
-				Handle: "some-handle",
+				Handle: "source-handle",
 				Strategy: encStrategy(map[string]string{
 					"type": "empty",
 				}),
+				Properties: baggageclaim.VolumeProperties{"some": "property"},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			request, err := http.NewRequest("POST", "/volumes", body)
-			Expect(err).NotTo(HaveOccurred())
-
 			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
 			handler.ServeHTTP(recorder, request)
 			Expect(recorder.Code).To(Equal(201))
 
-			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			err = json.NewDecoder(recorder.Body).Decode(&source)
 			Expect(err).NotTo(HaveOccurred())
-		})
-
-		It("returns 404 when source path is invalid", func() {
-			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "bogus-path"), nil)
-			recorder := httptest.NewRecorder()
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(404))
 
-			var responseError *api.ErrorResponse
-			err := json.NewDecoder(recorder.Body).Decode(&responseError)
+			err = ioutil.WriteFile(filepath.Join(source.Path, "some-file"), []byte("some-content"), 0644)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(responseError.Message).To(Equal("no such file or directory"))
 		})
 
-		Context("when streaming a file", func() {
-			BeforeEach(func() {
-				tarWriter := tar.NewWriter(tarBuffer)
-
-				err := tarWriter.WriteHeader(&tar.Header{
-					Name: "some-file",
-					Mode: 0600,
-					Size: int64(len("file-content")),
-				})
-				Expect(err).NotTo(HaveOccurred())
-				_, err = tarWriter.Write([]byte("file-content"))
-				Expect(err).NotTo(HaveOccurred())
-
-				err = tarWriter.Close()
-				Expect(err).NotTo(HaveOccurred())
-			})
+		It("creates an independent copy of the volume", func() {
+			recorder := clone("source-handle", "clone-handle")
+			Expect(recorder.Code).To(Equal(201))
 
-			JustBeforeEach(func() {
-				streamInRequest, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
-				streamInRecorder := httptest.NewRecorder()
-				handler.ServeHTTP(streamInRecorder, streamInRequest)
-				Expect(streamInRecorder.Code).To(Equal(204))
+			var cloned volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&cloned)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloned.Handle).To(Equal("clone-handle"))
+			Expect(cloned.Strategy).To(Equal(volume.StrategyClone))
+			Expect(cloned.Properties).To(Equal(volume.Properties{"some": "property"}))
 
-				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
-				Expect(tarContentsPath).To(BeAnExistingFile())
+			Expect(ioutil.ReadFile(filepath.Join(cloned.Path, "some-file"))).To(Equal([]byte("some-content")))
 
-				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
-			})
+			err = ioutil.WriteFile(filepath.Join(cloned.Path, "some-file"), []byte("changed"), 0644)
+			Expect(err).NotTo(HaveOccurred())
 
-			It("creates a tar", func() {
-				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
-				recorder := httptest.NewRecorder()
-				handler.ServeHTTP(recorder, request)
-				Expect(recorder.Code).To(Equal(200))
+			Expect(ioutil.ReadFile(filepath.Join(source.Path, "some-file"))).To(Equal([]byte("some-content")))
+		})
 
-				unpackedDir := filepath.Join(tempDir, "unpacked-dir")
-				err := os.MkdirAll(unpackedDir, os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
-				defer os.RemoveAll(unpackedDir)
+		It("generates a handle if none is given", func() {
+			recorder := clone("source-handle", "")
+			Expect(recorder.Code).To(Equal(201))
 
-				cmd := exec.Command("tar", "-x", "-C", unpackedDir)
-				cmd.Stdin = recorder.Body
-				err = cmd.Run()
-				Expect(err).NotTo(HaveOccurred())
+			var cloned volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&cloned)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloned.Handle).NotTo(BeEmpty())
+			Expect(cloned.Handle).NotTo(Equal("source-handle"))
+		})
 
-				fileInfo, err := os.Stat(filepath.Join(unpackedDir, "some-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(fileInfo.IsDir()).To(BeFalse())
-				Expect(fileInfo.Size()).To(Equal(int64(len("file-content"))))
+		It("responds with 404 when the source does not exist", func() {
+			Expect(clone("bogus-handle", "clone-handle").Code).To(Equal(404))
+		})
 
-				contents, err := ioutil.ReadFile(filepath.Join(unpackedDir, "./some-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(string(contents)).To(Equal("file-content"))
-			})
+		It("responds with 409 when the handle is taken", func() {
+			Expect(clone("source-handle", "clone-handle").Code).To(Equal(201))
+			Expect(clone("source-handle", "clone-handle").Code).To(Equal(409))
 		})
+	})
 
-		Context("when streaming a directory", func() {
-			var tarDir string
+	Describe("renaming a volume", func() {
+		var original volume.Volume
 
-			BeforeEach(func() {
-				tarDir = filepath.Join(tempDir, "tar-dir")
+		rename := func(handle string, newHandle string) *httptest.ResponseRecorder {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.RenameVolumeRequest{Handle: newHandle})
+			Expect(err).NotTo(HaveOccurred())
 
-				err := os.MkdirAll(filepath.Join(tarDir, "sub"), os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/rename", handle), body)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
 
-				err = ioutil.WriteFile(filepath.Join(tarDir, "sub", "some-file"), []byte("some-file-content"), os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
+		create := func(handle string, strategy map[string]string) volume.Volume {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:   handle,
+				Strategy: encStrategy(strategy),
+			})
+			Expect(err).NotTo(HaveOccurred())
 
-				err = ioutil.WriteFile(filepath.Join(tarDir, "other-file"), []byte("other-file-content"), os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
 
-				cmd := exec.Command("tar", "-c", ".")
-				cmd.Dir = tarDir
-				tarBytes, err := cmd.Output()
-				Expect(err).NotTo(HaveOccurred())
+			var created volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&created)
+			Expect(err).NotTo(HaveOccurred())
 
-				tarBuffer = bytes.NewBuffer(tarBytes)
-			})
+			return created
+		}
 
-			JustBeforeEach(func() {
-				streamInRequest, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
-				streamInRecorder := httptest.NewRecorder()
-				handler.ServeHTTP(streamInRecorder, streamInRequest)
-				Expect(streamInRecorder.Code).To(Equal(204))
+		JustBeforeEach(func() {
+			original = create("some-handle", map[string]string{"type": "empty"})
 
-				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
-				Expect(tarContentsPath).To(BeADirectory())
-			})
+			err := ioutil.WriteFile(filepath.Join(original.Path, "some-file"), []byte("some-content"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
 
-			It("creates a tar", func() {
-				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
-				recorder := httptest.NewRecorder()
-				handler.ServeHTTP(recorder, request)
-				Expect(recorder.Code).To(Equal(200))
+		It("responds with the volume under its new handle", func() {
+			recorder := rename("some-handle", "new-handle")
+			Expect(recorder.Code).To(Equal(200))
 
-				unpackedDir := filepath.Join(tempDir, "unpacked-dir")
-				err := os.MkdirAll(unpackedDir, os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
-				defer os.RemoveAll(unpackedDir)
+			var renamed volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&renamed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed.Handle).To(Equal("new-handle"))
+			Expect(ioutil.ReadFile(filepath.Join(renamed.Path, "some-file"))).To(Equal([]byte("some-content")))
 
-				cmd := exec.Command("tar", "-x", "-C", unpackedDir)
-				cmd.Stdin = recorder.Body
-				err = cmd.Run()
-				Expect(err).NotTo(HaveOccurred())
+			getRecorder := httptest.NewRecorder()
+			getReq, _ := http.NewRequest("GET", "/volumes/some-handle", nil)
+			handler.ServeHTTP(getRecorder, getReq)
+			Expect(getRecorder.Code).To(Equal(404))
+		})
 
-				fileInfo, err := os.Stat(filepath.Join(unpackedDir, "other-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(fileInfo.IsDir()).To(BeFalse())
-				Expect(fileInfo.Size()).To(Equal(int64(len("other-file-content"))))
+		It("keeps copy-on-write children streaming their parent's data", func() {
+			create("child-handle", map[string]string{"type": "cow", "volume": "some-handle"})
 
-				contents, err := ioutil.ReadFile(filepath.Join(unpackedDir, "other-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(string(contents)).To(Equal("other-file-content"))
+			Expect(rename("some-handle", "new-handle").Code).To(Equal(200))
 
-				dirInfo, err := os.Stat(filepath.Join(unpackedDir, "sub"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(dirInfo.IsDir()).To(BeTrue())
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/volumes/child-handle/stream-out?path=some-file", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal("some-content"))
+		})
 
-				fileInfo, err = os.Stat(filepath.Join(unpackedDir, "sub/some-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(fileInfo.IsDir()).To(BeFalse())
-				Expect(fileInfo.Size()).To(Equal(int64(len("some-file-content"))))
+		It("responds with 404 when the volume does not exist", func() {
+			Expect(rename("bogus-handle", "new-handle").Code).To(Equal(404))
+		})
 
-				contents, err = ioutil.ReadFile(filepath.Join(unpackedDir, "sub/some-file"))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(string(contents)).To(Equal("some-file-content"))
-			})
+		It("responds with 409 when the handle is taken", func() {
+			create("other-handle", map[string]string{"type": "empty"})
+
+			recorder := rename("some-handle", "other-handle")
+			Expect(recorder.Code).To(Equal(409))
+			Expect(recorder.Body).To(ContainSubstring(volume.ErrVolumeAlreadyExists.Error()))
 		})
 
-		It("returns 404 when volume is not found", func() {
-			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out", "invalid-handle"), nil)
-			recorder := httptest.NewRecorder()
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(404))
+		It("responds with 400 when the handle is not a name", func() {
+			recorder := rename("some-handle", "../escaped")
+			Expect(recorder.Code).To(Equal(400))
+			Expect(recorder.Body).To(ContainSubstring(volume.ErrInvalidHandle.Error()))
 		})
 	})
 
-	Describe("updating a volume", func() {
-		It("can have it's properties updated", func() {
+	Describe("the copy-on-write hierarchy", func() {
+		create := func(handle string, strategy map[string]string) {
 			body := &bytes.Buffer{}
-
 			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
-				Handle: "some-handle",
-				Strategy: encStrategy(map[string]string{
-					"type": "empty",
-				}),
-				Properties: baggageclaim.VolumeProperties{
-					"property-name": "property-val",
-				},
+				Handle:   handle,
+				Strategy: encStrategy(strategy),
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -507,129 +876,4064 @@ var _ = Describe("Volume Server", func() {
 			request, _ := http.NewRequest("POST", "/volumes", body)
 			handler.ServeHTTP(recorder, request)
 			Expect(recorder.Code).To(Equal(201))
+		}
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("GET", "/volumes?property-name=property-val", nil)
+		get := func(path string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", path, nil)
 			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		JustBeforeEach(func() {
+			create("some-handle", map[string]string{"type": "empty"})
+			create("child-handle", map[string]string{"type": "cow", "volume": "some-handle"})
+		})
+
+		It("includes the parent handle of a copy-on-write volume, and an empty one for others", func() {
+			for handle, parentHandle := range map[string]string{"some-handle": "", "child-handle": "some-handle"} {
+				recorder := get("/volumes/" + handle)
+				Expect(recorder.Code).To(Equal(200))
+
+				var fields map[string]interface{}
+				err := json.NewDecoder(recorder.Body).Decode(&fields)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fields).To(HaveKeyWithValue("parent_handle", parentHandle), handle)
+			}
+		})
+
+		It("lists the handles of the volume's copy-on-write children", func() {
+			recorder := get("/volumes/some-handle/children")
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body).To(MatchJSON(`["child-handle"]`))
+
+			recorder = get("/volumes/child-handle/children")
 			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body).To(MatchJSON(`[]`))
+		})
+
+		It("responds with 404 for the children of a volume that does not exist", func() {
+			Expect(get("/volumes/bogus-handle/children").Code).To(Equal(404))
+		})
+	})
+
+	Describe("promoting a volume", func() {
+		promote := func(handle string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/promote", handle), nil)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		create := func(handle string, strategy map[string]string) volume.Volume {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:   handle,
+				Strategy: encStrategy(strategy),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			var created volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&created)
+			Expect(err).NotTo(HaveOccurred())
+
+			return created
+		}
+
+		JustBeforeEach(func() {
+			parent := create("some-handle", map[string]string{"type": "empty"})
+
+			err := ioutil.WriteFile(filepath.Join(parent.Path, "some-file"), []byte("some-content"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			create("child-handle", map[string]string{"type": "cow", "volume": "some-handle"})
+		})
+
+		It("responds with the volume, which no longer needs its parent", func() {
+			recorder := promote("child-handle")
+			Expect(recorder.Code).To(Equal(200))
+
+			var promoted volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&promoted)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(promoted.Handle).To(Equal("child-handle"))
+
+			destroyRecorder := httptest.NewRecorder()
+			destroyRequest, _ := http.NewRequest("DELETE", "/volumes/some-handle", nil)
+			handler.ServeHTTP(destroyRecorder, destroyRequest)
+			Expect(destroyRecorder.Code).To(Equal(204))
+
+			streamRecorder := httptest.NewRecorder()
+			streamRequest, _ := http.NewRequest("PUT", "/volumes/child-handle/stream-out?path=some-file", nil)
+			handler.ServeHTTP(streamRecorder, streamRequest)
+			Expect(streamRecorder.Code).To(Equal(200))
+			Expect(streamRecorder.Body.String()).To(Equal("some-content"))
+		})
+
+		It("responds with 200 for a volume that is not a copy-on-write child", func() {
+			Expect(promote("some-handle").Code).To(Equal(200))
+		})
+
+		It("responds with 404 when the volume does not exist", func() {
+			Expect(promote("bogus-handle").Code).To(Equal(404))
+		})
+	})
+
+	Describe("creating a view of a volume", func() {
+		var base, view volume.Volume
+
+		create := func(request baggageclaim.VolumeRequest) volume.Volume {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, httpRequest)
+			Expect(recorder.Code).To(Equal(201))
+
+			var created volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&created)
+			Expect(err).NotTo(HaveOccurred())
+
+			return created
+		}
+
+		destroy := func(handle string) {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("DELETE", fmt.Sprintf("/volumes/%s", handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(204))
+		}
+
+		JustBeforeEach(func() {
+			base = create(baggageclaim.VolumeRequest{
+				Handle: "base-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+
+			err := ioutil.WriteFile(filepath.Join(base.Path, "some-file"), []byte("some-content"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			view = create(baggageclaim.VolumeRequest{
+				Handle: "view-handle",
+				Strategy: encStrategy(map[string]string{
+					"type":   "view",
+					"volume": "base-handle",
+				}),
+			})
+		})
+
+		It("shares the base's data and is frozen", func() {
+			Expect(view.Strategy).To(Equal(volume.StrategyView))
+			Expect(view.Frozen).To(BeTrue())
+
+			contents, err := ioutil.ReadFile(filepath.Join(view.Path, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-content"))
+		})
+
+		It("can be streamed out of", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", view.Handle, "some-file"), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal("file"))
+			Expect(recorder.Body.String()).To(Equal("some-content"))
+		})
+
+		It("refuses to be streamed into", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", view.Handle, "dest-path"), &bytes.Buffer{})
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(409))
+		})
+
+		It("refuses to be the base of another view", func() {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "nested-handle",
+				Strategy: encStrategy(map[string]string{
+					"type":   "view",
+					"volume": "view-handle",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(422))
+		})
+
+		It("keeps the base until its last view is destroyed", func() {
+			other := create(baggageclaim.VolumeRequest{
+				Handle: "other-view-handle",
+				Strategy: encStrategy(map[string]string{
+					"type":   "view",
+					"volume": "base-handle",
+				}),
+			})
+
+			destroy(base.Handle)
+			Expect(filepath.Join(view.Path, "some-file")).To(BeAnExistingFile())
+
+			destroy(view.Handle)
+			Expect(filepath.Join(other.Path, "some-file")).To(BeAnExistingFile())
+
+			destroy(other.Handle)
+			Expect(filepath.Join(volumeDir, "live", base.Handle)).NotTo(BeADirectory())
+		})
+	})
+
+	Describe("streaming tar files into volumes", func() {
+		var (
+			myVolume     volume.Volume
+			tarBuffer    *(bytes.Buffer)
+			isPrivileged bool
+		)
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Privileged: isPrivileged,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err := http.NewRequest("POST", "/volumes", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when tar file is valid", func() {
+			BeforeEach(func() {
+				tarBuffer = new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarBuffer)
+
+				err := tarWriter.WriteHeader(&tar.Header{
+					Name: "some-file",
+					Mode: 0600,
+					Size: int64(len("file-content")),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = tarWriter.Write([]byte("file-content"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("extracts the tar stream into the volume's DataPath", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(tarContentsPath).To(BeAnExistingFile())
+
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("returns the digest of the volume's contents, which can be checked again later", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(204))
+
+				digest := recorder.Header().Get(baggageclaim.VolumeDigestHeader)
+				Expect(digest).To(HavePrefix("sha256:"))
+
+				request, _ = http.NewRequest("GET", fmt.Sprintf("/volumes/%s", myVolume.Handle), nil)
+				recorder = httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				var fetchedVolume volume.Volume
+				Expect(json.NewDecoder(recorder.Body).Decode(&fetchedVolume)).To(Succeed())
+				Expect(fetchedVolume.Digest).To(Equal(digest))
+
+				request, _ = http.NewRequest("GET", fmt.Sprintf("/volumes/%s/digest", myVolume.Handle), nil)
+				recorder = httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				var digestResponse baggageclaim.VolumeDigestResponse
+				Expect(json.NewDecoder(recorder.Body).Decode(&digestResponse)).To(Succeed())
+				Expect(digestResponse.Digest).To(Equal(digest))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(ioutil.WriteFile(tarContentsPath, []byte("changed"), 0600)).To(Succeed())
+
+				request, _ = http.NewRequest("GET", fmt.Sprintf("/volumes/%s/digest", myVolume.Handle), nil)
+				recorder = httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				Expect(json.NewDecoder(recorder.Body).Decode(&digestResponse)).To(Succeed())
+				Expect(digestResponse.Digest).NotTo(Equal(digest))
+			})
+
+			It("responds with 499 and leaves nothing behind when the client goes away", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request.WithContext(ctx))
+				Expect(recorder.Code).To(Equal(499))
+
+				destPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+				Expect(destPath).NotTo(BeADirectory())
+			})
+
+			It("returns 400 when the path would be outside of the volume", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "../escape"), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(400))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrUnsafeSubPath.Error()))
+
+				Expect(filepath.Join(volumeDir, "live", myVolume.Handle, "escape")).NotTo(BeADirectory())
+			})
+
+			It("extracts it when it matches the checksum it was sent with", func() {
+				sum := sha256.Sum256(tarBuffer.Bytes())
+
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				request.Header.Set(baggageclaim.StreamChecksumHeader, "sha256:"+hex.EncodeToString(sum[:]))
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("returns 422 and leaves nothing behind when it does not match the checksum", func() {
+				sum := sha256.Sum256([]byte("something else"))
+
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				request.Header.Set(baggageclaim.StreamChecksumHeader, "sha256:"+hex.EncodeToString(sum[:]))
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrStreamChecksumMismatch.Error()))
+
+				destPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+				Expect(destPath).NotTo(BeADirectory())
+			})
+
+			It("checks the checksum sent in the trailer once the stream has been read", func() {
+				server := httptest.NewServer(handler)
+				defer server.Close()
+
+				payload := tarBuffer.Bytes()
+
+				streamIn := func(contents []byte) int {
+					sum := sha256.Sum256(contents)
+
+					request, err := http.NewRequest("PUT", fmt.Sprintf("%s/volumes/%s/stream-in?path=%s", server.URL, myVolume.Handle, "dest-path"), bytes.NewReader(payload))
+					Expect(err).NotTo(HaveOccurred())
+
+					// trailers are only sent with chunked bodies
+					request.ContentLength = -1
+					request.Trailer = http.Header{baggageclaim.StreamChecksumHeader: {"sha256:" + hex.EncodeToString(sum[:])}}
+
+					response, err := http.DefaultClient.Do(request)
+					Expect(err).NotTo(HaveOccurred())
+					response.Body.Close()
+
+					return response.StatusCode
+				}
+
+				Expect(streamIn([]byte("something else"))).To(Equal(422))
+				Expect(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")).NotTo(BeADirectory())
+
+				Expect(streamIn(payload)).To(Equal(204))
+				Expect(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")).To(BeAnExistingFile())
+			})
+
+			It("returns 400 when the checksum is malformed", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				request.Header.Set(baggageclaim.StreamChecksumHeader, "bogus")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(400))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrInvalidChecksum.Error()))
+			})
+
+			It("does not apply a retry with the same Idempotency-Key again", func() {
+				payload := tarBuffer.Bytes()
+
+				streamIn := func(key string) int {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), bytes.NewReader(payload))
+					request.Header.Set("Idempotency-Key", key)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					return recorder.Code
+				}
+
+				Expect(streamIn("some-key")).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(os.Remove(tarContentsPath)).To(Succeed())
+
+				Expect(streamIn("some-key")).To(Equal(200))
+				Expect(tarContentsPath).NotTo(BeAnExistingFile())
+
+				Expect(streamIn("other-key")).To(Equal(204))
+				Expect(tarContentsPath).To(BeAnExistingFile())
+			})
+
+			It("extracts concurrent streams into distinct sub-paths", func() {
+				tarBytes := tarBuffer.Bytes()
+				subPaths := []string{"a", "b", "c"}
+
+				codes := make(chan int, len(subPaths))
+
+				wg := new(sync.WaitGroup)
+				for _, subPath := range subPaths {
+					wg.Add(1)
+
+					go func(subPath string) {
+						defer GinkgoRecover()
+						defer wg.Done()
+
+						request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, subPath), bytes.NewBuffer(tarBytes))
+						recorder := httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+
+						codes <- recorder.Code
+					}(subPath)
+				}
+
+				wg.Wait()
+				close(codes)
+
+				for code := range codes {
+					Expect(code).To(Equal(204))
+				}
+
+				for _, subPath := range subPaths {
+					tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", subPath, "some-file")
+					Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+				}
+			})
+
+			Context("when volume is not privileged", func() {
+				BeforeEach(func() {
+					isPrivileged = false
+				})
+
+				It("namespaces volume path", func() {
+					if runtime.GOOS != "linux" {
+						Skip("only runs somewhere we can run privileged")
+						return
+					}
+
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(204))
+
+					tarInfoPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+					Expect(tarInfoPath).To(BeAnExistingFile())
+
+					stat, err := os.Stat(tarInfoPath)
+					Expect(err).ToNot(HaveOccurred())
+
+					maxUID := uidgid.MustGetMaxValidUID()
+					maxGID := uidgid.MustGetMaxValidGID()
+
+					sysStat := stat.Sys().(*syscall.Stat_t)
+					Expect(sysStat.Uid).To(Equal(uint32(maxUID)))
+					Expect(sysStat.Gid).To(Equal(uint32(maxGID)))
+				})
+
+				Context("when their IDs are shifted into a range of their own", func() {
+					BeforeEach(func() {
+						if runtime.GOOS != "linux" {
+							Skip("only runs somewhere we can run privileged")
+						}
+
+						unprivilegedIDs = uidgid.IDRange{UIDBase: 100000, GIDBase: 200000, Size: 65536}
+						if unprivilegedIDs.Validate(uidgid.MustGetMaxValidUID(), uidgid.MustGetMaxValidGID()) != nil {
+							Skip("the range is not valid in this user namespace")
+						}
+
+						tarBuffer = new(bytes.Buffer)
+						tarWriter := tar.NewWriter(tarBuffer)
+
+						err := tarWriter.WriteHeader(&tar.Header{
+							Name: "some-file",
+							Mode: 0600,
+							Size: int64(len("file-content")),
+							Uid:  1000,
+							Gid:  1000,
+						})
+						Expect(err).NotTo(HaveOccurred())
+						_, err = tarWriter.Write([]byte("file-content"))
+						Expect(err).NotTo(HaveOccurred())
+
+						err = tarWriter.Close()
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					owner := func(path string) (uint32, uint32) {
+						stat, err := os.Stat(path)
+						Expect(err).ToNot(HaveOccurred())
+
+						sysStat := stat.Sys().(*syscall.Stat_t)
+						return sysStat.Uid, sysStat.Gid
+					}
+
+					It("extracts what is owned by an ID at the base plus that ID", func() {
+						request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+						recorder := httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+						Expect(recorder.Code).To(Equal(204))
+
+						uid, gid := owner(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file"))
+						Expect(uid).To(Equal(uint32(101000)))
+						Expect(gid).To(Equal(uint32(201000)))
+
+						uid, gid = owner(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path"))
+						Expect(uid).To(Equal(uint32(100000)))
+						Expect(gid).To(Equal(uint32(200000)))
+					})
+
+					It("shifts the IDs of a privileged parent's data for a copy-on-write volume, and of an unprivileged one's only once", func() {
+						createChild := func(handle string, parent string, privileged bool) string {
+							body := &bytes.Buffer{}
+							err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+								Handle:     handle,
+								Strategy:   encStrategy(map[string]string{"type": "cow", "volume": parent}),
+								Privileged: privileged,
+							})
+							Expect(err).NotTo(HaveOccurred())
+
+							request, _ := http.NewRequest("POST", "/volumes", body)
+							recorder := httptest.NewRecorder()
+							handler.ServeHTTP(recorder, request)
+							Expect(recorder.Code).To(Equal(201))
+
+							var child volume.Volume
+							Expect(json.NewDecoder(recorder.Body).Decode(&child)).To(Succeed())
+
+							return child.Path
+						}
+
+						privilegedParent := createChild("privileged-parent-handle", myVolume.Handle, true)
+						Expect(ioutil.WriteFile(filepath.Join(privilegedParent, "some-file"), []byte("file-content"), 0644)).To(Succeed())
+						Expect(os.Chown(filepath.Join(privilegedParent, "some-file"), 1000, 1000)).To(Succeed())
+
+						unprivilegedChild := createChild("unprivileged-child-handle", "privileged-parent-handle", false)
+
+						uid, gid := owner(filepath.Join(unprivilegedChild, "some-file"))
+						Expect(uid).To(Equal(uint32(101000)))
+						Expect(gid).To(Equal(uint32(201000)))
+
+						unprivilegedGrandchild := createChild("unprivileged-grandchild-handle", "unprivileged-child-handle", false)
+
+						uid, gid = owner(filepath.Join(unprivilegedGrandchild, "some-file"))
+						Expect(uid).To(Equal(uint32(101000)))
+						Expect(gid).To(Equal(uint32(201000)))
+					})
+				})
+
+				Context("when an extraction umask is set", func() {
+					BeforeEach(func() {
+						if runtime.GOOS != "linux" {
+							Skip("only runs somewhere we can run privileged")
+						}
+
+						extractionUmask = 0027
+
+						tarBuffer = new(bytes.Buffer)
+						tarWriter := tar.NewWriter(tarBuffer)
+
+						err := tarWriter.WriteHeader(&tar.Header{
+							Name: "some-file",
+							Mode: 0666,
+							Size: int64(len("file-content")),
+						})
+						Expect(err).NotTo(HaveOccurred())
+						_, err = tarWriter.Write([]byte("file-content"))
+						Expect(err).NotTo(HaveOccurred())
+
+						err = tarWriter.Close()
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("masks the modes of what is extracted with it", func() {
+						request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+						recorder := httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+						Expect(recorder.Code).To(Equal(204))
+
+						info, err := os.Stat(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file"))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+					})
+				})
+			})
+
+			Context("when volume privileged", func() {
+				BeforeEach(func() {
+					isPrivileged = true
+				})
+
+				It("namespaces volume path", func() {
+					if runtime.GOOS != "linux" {
+						Skip("only runs somewhere we can run privileged")
+						return
+					}
+
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(204))
+
+					tarInfoPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+					Expect(tarInfoPath).To(BeAnExistingFile())
+
+					stat, err := os.Stat(tarInfoPath)
+					Expect(err).ToNot(HaveOccurred())
+
+					sysStat := stat.Sys().(*syscall.Stat_t)
+					Expect(sysStat.Uid).To(Equal(uint32(0)))
+					Expect(sysStat.Gid).To(Equal(uint32(0)))
+				})
+
+				Context("when a file has capabilities", func() {
+					var copyPath string
+
+					BeforeEach(func() {
+						if runtime.GOOS != "linux" {
+							Skip("only runs somewhere we can run privileged")
+						}
+
+						if _, err := exec.LookPath("setcap"); err != nil {
+							Skip("setcap is not installed")
+						}
+					})
+
+					JustBeforeEach(func() {
+						request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+						recorder := httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+						Expect(recorder.Code).To(Equal(204))
+
+						filePath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+
+						// chown clears capabilities, so it goes first
+						Expect(os.Chown(filePath, 1234, 5678)).To(Succeed())
+						Expect(exec.Command("setcap", "cap_net_raw+ep", filePath).Run()).To(Succeed())
+
+						copyPath = filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "copy", "some-file")
+					})
+
+					roundTrip := func(query string) {
+						request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s%s", myVolume.Handle, "dest-path", query), nil)
+						recorder := httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+						Expect(recorder.Code).To(Equal(200))
+
+						request, _ = http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s%s", myVolume.Handle, "copy", query), recorder.Body)
+						recorder = httptest.NewRecorder()
+						handler.ServeHTTP(recorder, request)
+						Expect(recorder.Code).To(Equal(204))
+					}
+
+					It("reproduces them and the owner when the xattrs are carried", func() {
+						roundTrip("&xattrs=true")
+
+						capabilities, err := exec.Command("getcap", copyPath).Output()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(capabilities)).To(ContainSubstring("cap_net_raw=ep"))
+
+						stat, err := os.Stat(copyPath)
+						Expect(err).ToNot(HaveOccurred())
+
+						sysStat := stat.Sys().(*syscall.Stat_t)
+						Expect(sysStat.Uid).To(Equal(uint32(1234)))
+						Expect(sysStat.Gid).To(Equal(uint32(5678)))
+					})
+
+					It("leaves them out by default", func() {
+						roundTrip("")
+
+						capabilities, err := exec.Command("getcap", copyPath).Output()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(capabilities)).NotTo(ContainSubstring("cap_net_raw"))
+					})
+				})
+			})
+		})
+
+		Context("when the tar stream advertises more than the volume has room for", func() {
+			BeforeEach(func() {
+				tarBuffer = new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarBuffer)
+
+				err := tarWriter.WriteHeader(&tar.Header{
+					Typeflag:   tar.TypeXGlobalHeader,
+					PAXRecords: map[string]string{baggageclaim.StreamSizeRecord: fmt.Sprintf("%d", uint64(math.MaxUint64-1))},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.WriteHeader(&tar.Header{
+					Name: "some-file",
+					Mode: 0600,
+					Size: int64(len("file-content")),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = tarWriter.Write([]byte("file-content"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 413 and leaves nothing behind", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+				Expect(recorder.Body).To(ContainSubstring("the volume only has room for"))
+
+				destPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+				Expect(destPath).NotTo(BeADirectory())
+			})
+		})
+
+		Context("when the tar stream is invalid", func() {
+			BeforeEach(func() {
+				tarBuffer = new(bytes.Buffer)
+				tarBuffer.Write([]byte("This is an invalid tar file!"))
+			})
+
+			It("returns 400 when err is exitError", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", myVolume.Handle), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(400))
+			})
+
+			It("explains that the format is not recognized", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", myVolume.Handle), tarBuffer)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrUnknownStreamFormat.Error()))
+			})
+		})
+
+		Context("when the tar stream is compressed", func() {
+			var compressedBuffer *bytes.Buffer
+
+			BeforeEach(func() {
+				tarBuffer = new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarBuffer)
+
+				err := tarWriter.WriteHeader(&tar.Header{
+					Name: "some-file",
+					Mode: 0600,
+					Size: int64(len("file-content")),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = tarWriter.Write([]byte("file-content"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+
+				compressedBuffer = new(bytes.Buffer)
+			})
+
+			compressWith := func(name string, args ...string) {
+				cmd := exec.Command(name, args...)
+				cmd.Stdin = tarBuffer
+				cmd.Stdout = compressedBuffer
+				Expect(cmd.Run()).To(Succeed())
+			}
+
+			itExtractsIt := func() {
+				It("extracts it", func() {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), compressedBuffer)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(204))
+
+					tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+					Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+				})
+			}
+
+			Context("with gzip", func() {
+				BeforeEach(func() {
+					gzipWriter := gzip.NewWriter(compressedBuffer)
+					_, err := io.Copy(gzipWriter, tarBuffer)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(gzipWriter.Close()).To(Succeed())
+				})
+
+				itExtractsIt()
+			})
+
+			Context("with bzip2", func() {
+				BeforeEach(func() {
+					compressWith("bzip2", "--stdout")
+				})
+
+				itExtractsIt()
+			})
+
+			Context("with xz", func() {
+				BeforeEach(func() {
+					compressWith("xz", "--stdout")
+				})
+
+				itExtractsIt()
+			})
+
+			Context("with zstd", func() {
+				BeforeEach(func() {
+					compressWith("zstd", "--stdout", "--quiet")
+				})
+
+				itExtractsIt()
+			})
+
+			Context("when the compressed stream is truncated", func() {
+				BeforeEach(func() {
+					compressWith("xz", "--stdout")
+					compressedBuffer.Truncate(compressedBuffer.Len() / 2)
+				})
+
+				It("returns 400", func() {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", myVolume.Handle), compressedBuffer)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(400))
+				})
+			})
+		})
+
+		Context("when the stream is sent with a content encoding", func() {
+			var (
+				tarBytes      []byte
+				encodedBuffer *bytes.Buffer
+			)
+
+			BeforeEach(func() {
+				tarBuffer = new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarBuffer)
+
+				err := tarWriter.WriteHeader(&tar.Header{
+					Name: "some-file",
+					Mode: 0600,
+					Size: int64(len("file-content")),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = tarWriter.Write([]byte("file-content"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+
+				tarBytes = tarBuffer.Bytes()
+
+				encodedBuffer = new(bytes.Buffer)
+				gzipWriter := gzip.NewWriter(encodedBuffer)
+				_, err = io.Copy(gzipWriter, tarBuffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gzipWriter.Close()).To(Succeed())
+			})
+
+			streamIn := func(encoding string) *httptest.ResponseRecorder {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), encodedBuffer)
+				request.Header.Set("Content-Encoding", encoding)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			encodeWithZstd := func() {
+				cmd := exec.Command("zstd", "--stdout", "--quiet")
+				cmd.Stdin = bytes.NewReader(tarBytes)
+				encodedBuffer = new(bytes.Buffer)
+				cmd.Stdout = encodedBuffer
+				Expect(cmd.Run()).To(Succeed())
+			}
+
+			It("decodes it before extracting", func() {
+				Expect(streamIn("gzip").Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("still extracts a payload that is compressed itself", func() {
+				compressedBuffer := encodedBuffer
+				encodedBuffer = new(bytes.Buffer)
+
+				gzipWriter := gzip.NewWriter(encodedBuffer)
+				_, err := io.Copy(gzipWriter, compressedBuffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gzipWriter.Close()).To(Succeed())
+
+				Expect(streamIn("gzip").Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("returns 400 when the encoded stream is truncated", func() {
+				encodedBuffer.Truncate(encodedBuffer.Len() - 8)
+
+				Expect(streamIn("gzip").Code).To(Equal(400))
+			})
+
+			It("returns 400 when the encoded stream is not gzip at all", func() {
+				encodedBuffer = bytes.NewBufferString("This is not gzip!")
+
+				Expect(streamIn("gzip").Code).To(Equal(400))
+			})
+
+			It("returns 415 when the encoding is not supported", func() {
+				recorder := streamIn("br")
+				Expect(recorder.Code).To(Equal(415))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrUnsupportedContentEncoding.Error()))
+			})
+
+			It("advertises the supported encodings", func() {
+				Expect(streamIn("br").Header().Get("Accept-Encoding")).To(Equal("zstd, gzip"))
+			})
+
+			It("decodes a zstd encoded stream", func() {
+				encodeWithZstd()
+
+				Expect(streamIn("zstd").Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("returns 400 when the zstd encoded stream is truncated", func() {
+				encodeWithZstd()
+
+				encodedBuffer.Truncate(encodedBuffer.Len() / 2)
+
+				Expect(streamIn("zstd").Code).To(Equal(400))
+			})
+		})
+
+		It("returns 404 when volume is not found", func() {
+			tarBuffer = new(bytes.Buffer)
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in", "invalid-handle"), tarBuffer)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+		})
+	})
+
+	Describe("streaming tar out of a volume", func() {
+		var (
+			myVolume  volume.Volume
+			tarBuffer *(bytes.Buffer)
+		)
+
+		BeforeEach(func() {
+			tarBuffer = new(bytes.Buffer)
+		})
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err := http.NewRequest("POST", "/volumes", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns 404 when source path is invalid", func() {
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "bogus-path"), nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+
+			var responseError *api.ErrorResponse
+			err := json.NewDecoder(recorder.Body).Decode(&responseError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseError.Message).To(Equal("no such file or directory"))
+		})
+
+		It("responds with an uncompressed error when gzip is accepted", func() {
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "bogus-path"), nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+
+			var responseError *api.ErrorResponse
+			err := json.NewDecoder(recorder.Body).Decode(&responseError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseError.Message).To(Equal("no such file or directory"))
+		})
+
+		Context("when streaming a file", func() {
+			BeforeEach(func() {
+				tarWriter := tar.NewWriter(tarBuffer)
+
+				err := tarWriter.WriteHeader(&tar.Header{
+					Name: "some-file",
+					Mode: 0600,
+					Size: int64(len("file-content")),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = tarWriter.Write([]byte("file-content"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			JustBeforeEach(func() {
+				streamInRequest, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				streamInRecorder := httptest.NewRecorder()
+				handler.ServeHTTP(streamInRecorder, streamInRequest)
+				Expect(streamInRecorder.Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "some-file")
+				Expect(tarContentsPath).To(BeAnExistingFile())
+
+				Expect(ioutil.ReadFile(tarContentsPath)).To(Equal([]byte("file-content")))
+			})
+
+			It("creates a tar", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal("tar"))
+
+				unpackedDir := filepath.Join(tempDir, "unpacked-dir")
+				err := os.MkdirAll(unpackedDir, os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(unpackedDir)
+
+				cmd := exec.Command("tar", "-x", "-C", unpackedDir)
+				cmd.Stdin = recorder.Body
+				err = cmd.Run()
+				Expect(err).NotTo(HaveOccurred())
+
+				fileInfo, err := os.Stat(filepath.Join(unpackedDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fileInfo.IsDir()).To(BeFalse())
+				Expect(fileInfo.Size()).To(Equal(int64(len("file-content"))))
+
+				contents, err := ioutil.ReadFile(filepath.Join(unpackedDir, "./some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("file-content"))
+			})
+
+			It("does not compress the tar unless asked to", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				request.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+
+				_, err := tar.NewReader(recorder.Body).Next()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("compresses the tar when gzip is accepted", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				request.Header.Set("Accept-Encoding", "deflate, gzip")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+				gzipReader, err := gzip.NewReader(recorder.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				contents := map[string]string{}
+
+				tarReader := tar.NewReader(gzipReader)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					content, err := ioutil.ReadAll(tarReader)
+					Expect(err).NotTo(HaveOccurred())
+
+					contents[filepath.Clean(header.Name)] = string(content)
+				}
+
+				Expect(contents).To(HaveKeyWithValue("some-file", "file-content"))
+
+				_, err = io.Copy(ioutil.Discard, gzipReader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("prefers zstd when both it and gzip are accepted", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				request.Header.Set("Accept-Encoding", "gzip, zstd")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Header().Get("Content-Encoding")).To(Equal("zstd"))
+
+				unpackedDir := filepath.Join(tempDir, "unpacked-dir")
+				err := os.MkdirAll(unpackedDir, os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(unpackedDir)
+
+				cmd := exec.Command("tar", "-x", "--zstd", "-C", unpackedDir)
+				cmd.Stdin = recorder.Body
+				Expect(cmd.Run()).To(Succeed())
+
+				Expect(ioutil.ReadFile(filepath.Join(unpackedDir, "some-file"))).To(Equal([]byte("file-content")))
+			})
+
+			It("honors the weights of the accepted encodings", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				request.Header.Set("Accept-Encoding", "zstd;q=0.5, gzip")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			})
+
+			It("carries the holes of sparse files only when asked to", func() {
+				sparseFile, err := os.Create(filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "sparse-file"))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = sparseFile.WriteAt([]byte("file-content"), 16*1024*1024)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sparseFile.Close()).To(Succeed())
+
+				for query, typeflag := range map[string]byte{"&format=tar": tar.TypeReg, "&format=tar&sparse=true": tar.TypeGNUSparse} {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s%s", myVolume.Handle, "dest-path/sparse-file", query), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(200))
+
+					header, err := tar.NewReader(recorder.Body).Next()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(header.Typeflag).To(Equal(typeflag), "query: %q", query)
+					Expect(header.Size).To(Equal(int64(16*1024*1024 + len("file-content"))))
+				}
+			})
+
+			It("returns 406 when none of the accepted encodings are supported", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				request.Header.Set("Accept-Encoding", "br")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(406))
+				Expect(recorder.Header().Get("Accept-Encoding")).To(Equal("zstd, gzip"))
+				Expect(recorder.Body).To(ContainSubstring(api.ErrStreamOutNotAcceptable.Error()))
+			})
+
+			Context("when the path is the file itself", func() {
+				streamOut := func(query string, header http.Header) *httptest.ResponseRecorder {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s%s", myVolume.Handle, "dest-path/some-file", query), nil)
+					for name, values := range header {
+						request.Header[name] = values
+					}
+
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					return recorder
+				}
+
+				It("streams the file's bytes rather than a tar", func() {
+					recorder := streamOut("", nil)
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal("file"))
+					Expect(recorder.Header().Get("Content-Type")).To(Equal("application/octet-stream"))
+					Expect(recorder.Body.String()).To(Equal("file-content"))
+				})
+
+				It("streams the file's bytes when asked for a file", func() {
+					recorder := streamOut("&format=file", nil)
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Body.String()).To(Equal("file-content"))
+				})
+
+				It("compresses the file's bytes when gzip is accepted", func() {
+					recorder := streamOut("", http.Header{"Accept-Encoding": {"gzip"}})
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+					gzipReader, err := gzip.NewReader(recorder.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ioutil.ReadAll(gzipReader)).To(Equal([]byte("file-content")))
+				})
+
+				It("streams a tar of the file when asked for a tar", func() {
+					recorder := streamOut("&format=tar", nil)
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal("tar"))
+
+					tarReader := tar.NewReader(recorder.Body)
+					_, err := tarReader.Next()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ioutil.ReadAll(tarReader)).To(Equal([]byte("file-content")))
+				})
+
+				It("streams a tar of the file when given options that only apply to tars", func() {
+					recorder := streamOut("&xattrs=true", nil)
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal("tar"))
+				})
+
+				It("returns 400 when asked for a file with options that only apply to tars", func() {
+					recorder := streamOut("&format=file&xattrs=true", nil)
+					Expect(recorder.Code).To(Equal(400))
+					Expect(recorder.Body).To(ContainSubstring(volume.ErrStreamOutOptionsNeedTar.Error()))
+				})
+			})
+
+			It("returns 400 when asked for a file at a directory", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&format=file", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(400))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrNotARegularFile.Error()))
+			})
+
+			It("returns 404 when asked for a file that does not exist", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&format=file", myVolume.Handle, "dest-path/bogus-file"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(404))
+				Expect(recorder.Body).To(ContainSubstring(api.ErrStreamOutNotFound.Error()))
+			})
+
+			It("returns 400 when asked for an unknown format", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&format=zip", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(400))
+				Expect(recorder.Body).To(ContainSubstring(api.ErrInvalidStreamOutFormat.Error()))
+			})
+		})
+
+		Context("when streaming a directory", func() {
+			var tarDir string
+
+			BeforeEach(func() {
+				tarDir = filepath.Join(tempDir, "tar-dir")
+
+				err := os.MkdirAll(filepath.Join(tarDir, "sub"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(tarDir, "sub", "some-file"), []byte("some-file-content"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(tarDir, "other-file"), []byte("other-file-content"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				cmd := exec.Command("tar", "-c", ".")
+				cmd.Dir = tarDir
+				tarBytes, err := cmd.Output()
+				Expect(err).NotTo(HaveOccurred())
+
+				tarBuffer = bytes.NewBuffer(tarBytes)
+			})
+
+			JustBeforeEach(func() {
+				streamInRequest, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), tarBuffer)
+				streamInRecorder := httptest.NewRecorder()
+				handler.ServeHTTP(streamInRecorder, streamInRequest)
+				Expect(streamInRecorder.Code).To(Equal(204))
+
+				tarContentsPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+				Expect(tarContentsPath).To(BeADirectory())
+			})
+
+			It("creates a tar", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				unpackedDir := filepath.Join(tempDir, "unpacked-dir")
+				err := os.MkdirAll(unpackedDir, os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(unpackedDir)
+
+				cmd := exec.Command("tar", "-x", "-C", unpackedDir)
+				cmd.Stdin = recorder.Body
+				err = cmd.Run()
+				Expect(err).NotTo(HaveOccurred())
+
+				fileInfo, err := os.Stat(filepath.Join(unpackedDir, "other-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fileInfo.IsDir()).To(BeFalse())
+				Expect(fileInfo.Size()).To(Equal(int64(len("other-file-content"))))
+
+				contents, err := ioutil.ReadFile(filepath.Join(unpackedDir, "other-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("other-file-content"))
+
+				dirInfo, err := os.Stat(filepath.Join(unpackedDir, "sub"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dirInfo.IsDir()).To(BeTrue())
+
+				fileInfo, err = os.Stat(filepath.Join(unpackedDir, "sub/some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fileInfo.IsDir()).To(BeFalse())
+				Expect(fileInfo.Size()).To(Equal(int64(len("some-file-content"))))
+
+				contents, err = ioutil.ReadFile(filepath.Join(unpackedDir, "sub/some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-file-content"))
+			})
+
+			It("creates a tar of a consistent view when asked", func() {
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&consistent=true", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				var names []string
+				tarReader := tar.NewReader(recorder.Body)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					names = append(names, filepath.Clean(header.Name))
+				}
+
+				Expect(names).To(ConsistOf(".", "other-file", "sub", "sub/some-file"))
+			})
+
+			It("reports what was changed in a trailer when downgrading", func() {
+				otherFile := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "other-file")
+				Expect(os.Chmod(otherFile, 0755|os.ModeSetuid)).To(Succeed())
+
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&downgrade=true", myVolume.Handle, "dest-path"), nil)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				tarReader := tar.NewReader(recorder.Body)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(header.FileInfo().Mode() & os.ModeSetuid).To(BeZero())
+				}
+
+				Expect(recorder.Result().Trailer.Get(baggageclaim.DowngradedTrailer)).To(Equal("setuid=1, setgid=0, devices=0"))
+			})
+
+			Context("when a canonical tar is asked for", func() {
+				streamOut := func() []byte {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&canonical=true", myVolume.Handle, "dest-path"), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get(baggageclaim.StreamOutFormatHeader)).To(Equal(string(volume.StreamOutTar)))
+
+					return recorder.Body.Bytes()
+				}
+
+				It("zeroes the mtimes and leaves out owner names", func() {
+					tarReader := tar.NewReader(bytes.NewReader(streamOut()))
+					for {
+						header, err := tarReader.Next()
+						if err == io.EOF {
+							break
+						}
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(header.ModTime.Unix()).To(BeZero())
+						Expect(header.Uname).To(BeEmpty())
+						Expect(header.Gname).To(BeEmpty())
+					}
+				})
+
+				It("streams the same bytes for the same contents written at another time", func() {
+					before := streamOut()
+
+					destPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+					later := time.Now().Add(time.Hour)
+					err := filepath.Walk(destPath, func(path string, info os.FileInfo, err error) error {
+						if err != nil {
+							return err
+						}
+
+						return os.Chtimes(path, later, later)
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(streamOut()).To(Equal(before))
+				})
+			})
+
+			Context("when a range is asked for", func() {
+				var full []byte
+
+				streamOutIfRange := func(byteRange string, ifRange string) *httptest.ResponseRecorder {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s", myVolume.Handle, "dest-path"), nil)
+					if byteRange != "" {
+						request.Header.Set("Range", byteRange)
+					}
+
+					if ifRange != "" {
+						request.Header.Set("If-Range", ifRange)
+					}
+
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					return recorder
+				}
+
+				streamOut := func(byteRange string) *httptest.ResponseRecorder {
+					return streamOutIfRange(byteRange, "")
+				}
+
+				JustBeforeEach(func() {
+					recorder := streamOut("")
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Header().Get("Content-Range")).To(BeEmpty())
+
+					full = recorder.Body.Bytes()
+				})
+
+				It("streams the volume out as the same bytes each time, sorted by name", func() {
+					Expect(streamOut("").Body.Bytes()).To(Equal(full))
+
+					var names []string
+					tarReader := tar.NewReader(bytes.NewReader(full))
+					for {
+						header, err := tarReader.Next()
+						if err == io.EOF {
+							break
+						}
+						Expect(err).NotTo(HaveOccurred())
+
+						names = append(names, filepath.Clean(header.Name))
+					}
+
+					Expect(names).To(Equal([]string{".", "other-file", "sub", "sub/some-file"}))
+				})
+
+				It("resumes the stream from where the range starts", func() {
+					offset := len(full) / 2
+
+					recorder := streamOut(fmt.Sprintf("bytes=%d-", offset))
+					Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+					Expect(recorder.Header().Get("Content-Range")).To(Equal(fmt.Sprintf("bytes %d-%d/%d", offset, len(full)-1, len(full))))
+					Expect(recorder.Header().Get("Content-Length")).To(Equal(strconv.Itoa(len(full) - offset)))
+					Expect(recorder.Body.Bytes()).To(Equal(full[offset:]))
+				})
+
+				It("streams only the bytes in a range that ends early", func() {
+					recorder := streamOut("bytes=10-19")
+					Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+					Expect(recorder.Header().Get("Content-Range")).To(Equal(fmt.Sprintf("bytes 10-19/%d", len(full))))
+					Expect(recorder.Body.Bytes()).To(Equal(full[10:20]))
+				})
+
+				It("streams the last bytes of the stream for a suffix", func() {
+					recorder := streamOut("bytes=-1024")
+					Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+					Expect(recorder.Body.Bytes()).To(Equal(full[len(full)-1024:]))
+				})
+
+				It("answers 416 to a range past the end of the stream", func() {
+					recorder := streamOut(fmt.Sprintf("bytes=%d-", len(full)))
+					Expect(recorder.Code).To(Equal(http.StatusRequestedRangeNotSatisfiable))
+					Expect(recorder.Header().Get("Content-Range")).To(Equal(fmt.Sprintf("bytes */%d", len(full))))
+					Expect(recorder.Body.String()).To(ContainSubstring(api.ErrRangeNotSatisfiable.Error()))
+				})
+
+				It("answers 416 to a malformed range", func() {
+					recorder := streamOut("bytes=20-10")
+					Expect(recorder.Code).To(Equal(http.StatusRequestedRangeNotSatisfiable))
+				})
+
+				It("streams the volume out in full for many ranges", func() {
+					recorder := streamOut("bytes=0-9,20-29")
+					Expect(recorder.Code).To(Equal(200))
+					Expect(recorder.Body.Bytes()).To(Equal(full))
+				})
+
+				It("tags ranges with the digest of the stream they are of", func() {
+					checksum := sha256.Sum256(full)
+
+					recorder := streamOut("bytes=10-19")
+					Expect(recorder.Header().Get("ETag")).To(Equal(`"` + hex.EncodeToString(checksum[:]) + `"`))
+				})
+
+				Context("when resuming with the tag of the stream", func() {
+					It("streams the range of it", func() {
+						etag := streamOut("bytes=0-9").Header().Get("ETag")
+
+						recorder := streamOutIfRange("bytes=10-19", etag)
+						Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+						Expect(recorder.Body.Bytes()).To(Equal(full[10:20]))
+					})
+
+					Context("when the volume has changed since", func() {
+						It("streams out the whole of what it is now, with its tag", func() {
+							etag := streamOut("bytes=0-9").Header().Get("ETag")
+
+							changedFile := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "other-file")
+							Expect(ioutil.WriteFile(changedFile, []byte("changed"), 0644)).To(Succeed())
+
+							recorder := streamOutIfRange("bytes=10-", etag)
+							Expect(recorder.Code).To(Equal(200))
+							Expect(recorder.Header().Get("Content-Range")).To(BeEmpty())
+
+							changed := streamOut("").Body.Bytes()
+							Expect(recorder.Body.Bytes()).To(Equal(changed))
+							Expect(changed).NotTo(Equal(full))
+
+							checksum := sha256.Sum256(changed)
+							Expect(recorder.Header().Get("ETag")).To(Equal(`"` + hex.EncodeToString(checksum[:]) + `"`))
+						})
+					})
+				})
+			})
+
+			Context("when a chunk size is given", func() {
+				type chunk struct {
+					header   textproto.MIMEHeader
+					contents []byte
+				}
+
+				var chunks []chunk
+
+				JustBeforeEach(func() {
+					bigFile := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path", "big-file")
+					err := ioutil.WriteFile(bigFile, bytes.Repeat([]byte("x"), 1536*1024), 0644)
+					Expect(err).NotTo(HaveOccurred())
+
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&chunk-size=1", myVolume.Handle, "dest-path"), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(200))
+
+					mediaType, params, err := mime.ParseMediaType(recorder.Header().Get("Content-Type"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mediaType).To(Equal("multipart/mixed"))
+
+					chunks = nil
+
+					parts := multipart.NewReader(recorder.Body, params["boundary"])
+					for {
+						part, err := parts.NextPart()
+						if err == io.EOF {
+							break
+						}
+						Expect(err).NotTo(HaveOccurred())
+
+						contents, err := ioutil.ReadAll(part)
+						Expect(err).NotTo(HaveOccurred())
+
+						chunks = append(chunks, chunk{header: part.Header, contents: contents})
+					}
+				})
+
+				streamInChunks := func(chunks []chunk) *httptest.ResponseRecorder {
+					body := new(bytes.Buffer)
+					parts := multipart.NewWriter(body)
+
+					for _, chunk := range chunks {
+						part, err := parts.CreatePart(chunk.header)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, err = part.Write(chunk.contents)
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					Expect(parts.Close()).To(Succeed())
+
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "reassembled"), body)
+					request.Header.Set("Content-Type", "multipart/mixed; boundary="+parts.Boundary())
+
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+
+					return recorder
+				}
+
+				It("splits the tar into checksummed chunks of the size, in order", func() {
+					Expect(len(chunks)).To(BeNumerically(">", 1))
+
+					for i, chunk := range chunks {
+						Expect(chunk.header.Get(baggageclaim.ChunkIndexHeader)).To(Equal(fmt.Sprint(i)))
+
+						checksum := sha256.Sum256(chunk.contents)
+						Expect(chunk.header.Get(baggageclaim.ChunkChecksumHeader)).To(Equal(hex.EncodeToString(checksum[:])))
+
+						if i < len(chunks)-1 {
+							Expect(chunk.contents).To(HaveLen(1024 * 1024))
+						}
+					}
+				})
+
+				It("can be streamed back in as chunks", func() {
+					Expect(streamInChunks(chunks).Code).To(Equal(204))
+
+					reassembled := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "reassembled")
+					Expect(ioutil.ReadFile(filepath.Join(reassembled, "other-file"))).To(Equal([]byte("other-file-content")))
+					Expect(filepath.Join(reassembled, "big-file")).To(BeARegularFile())
+				})
+
+				It("refuses chunks that don't match their checksum", func() {
+					chunks[0].contents[0] ^= 0xff
+
+					recorder := streamInChunks(chunks)
+					Expect(recorder.Code).To(Equal(400))
+					Expect(recorder.Body.String()).To(ContainSubstring(api.ErrChunkChecksumMismatch.Error()))
+				})
+
+				It("leaves nothing of the earlier chunks extracted when a later one doesn't match its checksum", func() {
+					chunks[len(chunks)-1].contents[0] ^= 0xff
+
+					recorder := streamInChunks(chunks)
+					Expect(recorder.Code).To(Equal(400))
+					Expect(recorder.Body.String()).To(ContainSubstring(api.ErrChunkChecksumMismatch.Error()))
+
+					reassembled := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "reassembled")
+					Expect(reassembled).NotTo(BeADirectory())
+				})
+
+				It("refuses chunks out of order", func() {
+					chunks[0], chunks[1] = chunks[1], chunks[0]
+
+					recorder := streamInChunks(chunks)
+					Expect(recorder.Code).To(Equal(400))
+					Expect(recorder.Body.String()).To(ContainSubstring(api.ErrChunkOutOfOrder.Error()))
+				})
+
+				It("refuses chunk sizes that are out of range", func() {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&chunk-size=65", myVolume.Handle, "dest-path"), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(400))
+				})
+			})
+
+			Context("when modified-since is given", func() {
+				JustBeforeEach(func() {
+					destPath := filepath.Join(volumeDir, "live", myVolume.Handle, "volume", "dest-path")
+
+					err := os.Chtimes(filepath.Join(destPath, "other-file"), time.Unix(1000, 0), time.Unix(1000, 0))
+					Expect(err).NotTo(HaveOccurred())
+
+					err = os.Chtimes(filepath.Join(destPath, "sub"), time.Unix(1000, 0), time.Unix(1000, 0))
+					Expect(err).NotTo(HaveOccurred())
+
+					err = os.Chtimes(filepath.Join(destPath, "sub", "some-file"), time.Unix(3000, 0), time.Unix(3000, 0))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("only includes directories and files modified after the time", func() {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&modified-since=%d", myVolume.Handle, "dest-path", 2000), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(200))
+
+					var names []string
+					tarReader := tar.NewReader(recorder.Body)
+					for {
+						header, err := tarReader.Next()
+						if err == io.EOF {
+							break
+						}
+						Expect(err).NotTo(HaveOccurred())
+
+						names = append(names, filepath.Clean(header.Name))
+					}
+
+					Expect(names).To(ConsistOf(".", "sub", "sub/some-file"))
+				})
+
+				It("returns 400 when the time is invalid", func() {
+					request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out?path=%s&modified-since=%s", myVolume.Handle, "dest-path", "yesterday"), nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				})
+			})
+		})
+
+		It("returns 404 when volume is not found", func() {
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-out", "invalid-handle"), nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+		})
+	})
+
+	Describe("copying into a volume from another", func() {
+		createVolume := func(handle string) {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:     handle,
+				Strategy:   encStrategy(map[string]string{"type": "empty"}),
+				Privileged: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+		}
+
+		dataPath := func(handle string, path string) string {
+			return filepath.Join(volumeDir, "live", handle, "volume", path)
+		}
+
+		copyIn := func(path string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", path, nil)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		errorMessage := func(recorder *httptest.ResponseRecorder) string {
+			var errResponse api.ErrorResponse
+			err := json.NewDecoder(recorder.Body).Decode(&errResponse)
+			Expect(err).NotTo(HaveOccurred())
+
+			return errResponse.Message
+		}
+
+		JustBeforeEach(func() {
+			createVolume("some-handle")
+			createVolume("source-handle")
+
+			Expect(os.MkdirAll(dataPath("source-handle", "some-dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(dataPath("source-handle", "some-dir/some-file"), []byte("some-contents"), 0644)).To(Succeed())
+		})
+
+		It("copies the source path into the path, and responds with the volume's digest", func() {
+			recorder := copyIn("/volumes/some-handle/copy-in?path=into&source=source-handle&source-path=some-dir")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Header().Get(baggageclaim.VolumeDigestHeader)).NotTo(BeEmpty())
+
+			Expect(ioutil.ReadFile(dataPath("some-handle", "into/some-file"))).To(Equal([]byte("some-contents")))
+		})
+
+		It("responds with 400 without a source", func() {
+			Expect(copyIn("/volumes/some-handle/copy-in?path=into").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("responds with 400 when the paths overlap in the same volume", func() {
+			recorder := copyIn("/volumes/source-handle/copy-in?path=some-dir/copy&source=source-handle&source-path=some-dir")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorMessage(recorder)).To(Equal(volume.ErrCopyOverlapsSource.Error()))
+		})
+
+		It("responds with 404s that tell which of the volumes or the source path is missing", func() {
+			recorder := copyIn("/volumes/bogus-handle/copy-in?source=source-handle")
+			Expect(recorder.Code).To(Equal(404))
+			Expect(errorMessage(recorder)).To(Equal(api.ErrCopyInFailed.Error()))
+
+			recorder = copyIn("/volumes/some-handle/copy-in?source=bogus-handle")
+			Expect(recorder.Code).To(Equal(404))
+			Expect(errorMessage(recorder)).To(Equal(volume.ErrSourceVolumeDoesNotExist.Error()))
+
+			recorder = copyIn("/volumes/some-handle/copy-in?source=source-handle&source-path=bogus-dir")
+			Expect(recorder.Code).To(Equal(404))
+			Expect(errorMessage(recorder)).To(Equal(api.ErrStreamOutNotFound.Error()))
+		})
+	})
+
+	Describe("diffing volumes", func() {
+		var (
+			myVolume   volume.Volume
+			baseVolume volume.Volume
+		)
+
+		createVolume := func(handle string) volume.Volume {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: handle,
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			var createdVolume volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&createdVolume)
+			Expect(err).NotTo(HaveOccurred())
+
+			return createdVolume
+		}
+
+		writeFile := func(vol volume.Volume, path string, contents string) {
+			fullPath := filepath.Join(volumeDir, "live", vol.Handle, "volume", path)
+			err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+			Expect(err).NotTo(HaveOccurred())
+			err = ioutil.WriteFile(fullPath, []byte(contents), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		JustBeforeEach(func() {
+			myVolume = createVolume("some-handle")
+			baseVolume = createVolume("base-handle")
+
+			writeFile(baseVolume, "same", "same-content")
+			writeFile(baseVolume, "changed", "old-content")
+			writeFile(baseVolume, "removed", "removed-content")
+
+			writeFile(myVolume, "same", "same-content")
+			writeFile(myVolume, "changed", "new-content")
+			writeFile(myVolume, "added/file", "added-content")
+		})
+
+		It("streams a report of added, removed and modified paths", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/diff?base=%s", myVolume.Handle, baseVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+			var entries []volume.DiffEntry
+			decoder := json.NewDecoder(recorder.Body)
+			for decoder.More() {
+				var entry volume.DiffEntry
+				err := decoder.Decode(&entry)
+				Expect(err).NotTo(HaveOccurred())
+				entries = append(entries, entry)
+			}
+
+			Expect(entries).To(Equal([]volume.DiffEntry{
+				{Path: "added", Change: volume.DiffAdded},
+				{Path: "added/file", Change: volume.DiffAdded},
+				{Path: "changed", Change: volume.DiffModified},
+				{Path: "removed", Change: volume.DiffRemoved},
+			}))
+		})
+
+		It("streams a patch tar of added and modified paths", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/diff?base=%s&patch=true", myVolume.Handle, baseVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-tar"))
+
+			contents := map[string]string{}
+			tarReader := tar.NewReader(recorder.Body)
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+
+				data, err := ioutil.ReadAll(tarReader)
+				Expect(err).NotTo(HaveOccurred())
+				contents[filepath.Clean(header.Name)] = string(data)
+			}
+
+			Expect(contents).To(Equal(map[string]string{
+				"added":      "",
+				"added/file": "added-content",
+				"changed":    "new-content",
+			}))
+		})
+
+		It("returns 400 when no base is given", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/diff", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when the base volume is not found", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/diff?base=bogus-handle", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var errResponse api.ErrorResponse
+			err := json.NewDecoder(recorder.Body).Decode(&errResponse)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errResponse.Message).To(Equal(api.ErrDiffVolumesFailed.Error()))
+		})
+
+		It("reports a missing base volume as JSON when a patch is requested", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/diff?base=bogus-handle&patch=true", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+	})
+
+	Describe("streaming deltas into a volume", func() {
+		var myVolume volume.Volume
+
+		dataPath := func(path string) string {
+			return filepath.Join(volumeDir, "live", myVolume.Handle, "volume", path)
+		}
+
+		tarOf := func(headers ...*tar.Header) *bytes.Buffer {
+			buffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(buffer)
+
+			for _, header := range headers {
+				contents := header.Name
+				if header.Typeflag == tar.TypeDir || strings.HasPrefix(path.Base(header.Name), baggageclaim.WhiteoutPrefix) {
+					contents = ""
+				}
+
+				header.Size = int64(len(contents))
+				Expect(tarWriter.WriteHeader(header)).To(Succeed())
+
+				_, err := tarWriter.Write([]byte(contents))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(tarWriter.Close()).To(Succeed())
+
+			return buffer
+		}
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			Expect(json.NewDecoder(recorder.Body).Decode(&myVolume)).To(Succeed())
+
+			Expect(os.MkdirAll(dataPath("dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(dataPath("dir/file"), []byte("contents"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(dataPath("removed"), []byte("removed"), 0644)).To(Succeed())
+		})
+
+		getManifest := func(query string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/manifest%s", myVolume.Handle, query), nil)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		It("lists the volume's contents", func() {
+			recorder := getManifest("")
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+			entries := []baggageclaim.ManifestEntry{}
+			decoder := json.NewDecoder(recorder.Body)
+			for decoder.More() {
+				var entry baggageclaim.ManifestEntry
+				Expect(decoder.Decode(&entry)).To(Succeed())
+				entries = append(entries, entry)
+			}
+
+			Expect(entries).To(HaveLen(3))
+			Expect(entries[0].Path).To(Equal("dir"))
+			Expect(entries[0].Type).To(Equal("directory"))
+			Expect(entries[1].Path).To(Equal("dir/file"))
+			Expect(entries[1].Size).To(Equal(int64(len("contents"))))
+			Expect(entries[1].SHA256).NotTo(BeEmpty())
+			Expect(entries[2].Path).To(Equal("removed"))
+		})
+
+		It("lists the contents of a path in the volume", func() {
+			recorder := getManifest("?path=dir")
+			Expect(recorder.Code).To(Equal(200))
+
+			var entry baggageclaim.ManifestEntry
+			Expect(json.NewDecoder(recorder.Body).Decode(&entry)).To(Succeed())
+			Expect(entry.Path).To(Equal("file"))
+		})
+
+		It("returns 404 for a path that does not exist", func() {
+			recorder := getManifest("?path=bogus")
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Body.String()).To(ContainSubstring(api.ErrStreamOutNotFound.Error()))
+		})
+
+		It("returns 404 for a volume that does not exist", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes/bogus-handle/manifest", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+		})
+
+		It("applies a delta stream, removing what it whites out", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=.&delta=true", myVolume.Handle), tarOf(
+				&tar.Header{Name: ".wh.removed", Mode: 0644},
+				&tar.Header{Name: "added", Mode: 0644},
+			))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(204))
+
+			Expect(dataPath("removed")).NotTo(BeAnExistingFile())
+			Expect(dataPath(".wh.removed")).NotTo(BeAnExistingFile())
+			Expect(ioutil.ReadFile(dataPath("added"))).To(Equal([]byte("added")))
+			Expect(ioutil.ReadFile(dataPath("dir/file"))).To(Equal([]byte("contents")))
+		})
+
+		It("returns 400 for a delta stream with a whiteout after other entries", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=.&delta=true", myVolume.Handle), tarOf(
+				&tar.Header{Name: "added", Mode: 0644},
+				&tar.Header{Name: ".wh.removed", Mode: 0644},
+			))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(400))
+			Expect(recorder.Body.String()).To(ContainSubstring(volume.ErrInvalidWhiteout.Error()))
+
+			Expect(dataPath("removed")).To(BeAnExistingFile())
+		})
+
+		It("applies a gzipped layer, with its whiteouts anywhere in it", func() {
+			layer := new(bytes.Buffer)
+			gzipWriter := gzip.NewWriter(layer)
+			_, err := io.Copy(gzipWriter, tarOf(
+				&tar.Header{Name: "added", Mode: 0644},
+				&tar.Header{Name: ".wh.removed", Mode: 0644},
+				&tar.Header{Name: "dir/.wh..wh..opq", Mode: 0644},
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gzipWriter.Close()).To(Succeed())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=.&layer=true", myVolume.Handle), layer)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(204))
+
+			Expect(dataPath("removed")).NotTo(BeAnExistingFile())
+			Expect(dataPath(".wh.removed")).NotTo(BeAnExistingFile())
+			Expect(dataPath("dir")).To(BeADirectory())
+			Expect(dataPath("dir/file")).NotTo(BeAnExistingFile())
+			Expect(ioutil.ReadFile(dataPath("added"))).To(Equal([]byte("added")))
+		})
+
+		It("returns 400 for a layer with a whiteout of the directory it is in", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=.&layer=true", myVolume.Handle), tarOf(
+				&tar.Header{Name: "dir/.wh..", Mode: 0644},
+			))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(400))
+			Expect(recorder.Body.String()).To(ContainSubstring(volume.ErrInvalidLayerWhiteout.Error()))
+
+			Expect(dataPath("dir/file")).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("listing the files in a volume", func() {
+		var myVolume volume.Volume
+
+		dataPath := func(path string) string {
+			return filepath.Join(volumeDir, "live", myVolume.Handle, "volume", path)
+		}
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			Expect(json.NewDecoder(recorder.Body).Decode(&myVolume)).To(Succeed())
+
+			Expect(os.MkdirAll(dataPath("dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(dataPath("dir/file"), []byte("contents"), 0644)).To(Succeed())
+			Expect(os.Symlink("dir/file", dataPath("link"))).To(Succeed())
+		})
+
+		listFiles := func(query string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s/files%s", myVolume.Handle, query), nil)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		It("lists the paths in the volume with their sizes, modes, and mtimes", func() {
+			recorder := listFiles("")
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var files []baggageclaim.FileEntry
+			Expect(json.NewDecoder(recorder.Body).Decode(&files)).To(Succeed())
+
+			Expect(files).To(HaveLen(3))
+			Expect(files[0].Path).To(Equal("dir"))
+			Expect(files[0].Type).To(Equal("directory"))
+			Expect(files[0].Mode).To(Equal(os.FileMode(0755)))
+			Expect(files[1].Path).To(Equal("dir/file"))
+			Expect(files[1].Type).To(Equal("file"))
+			Expect(files[1].Size).To(Equal(int64(len("contents"))))
+			Expect(files[1].ModTime).NotTo(BeZero())
+			Expect(files[2].Path).To(Equal("link"))
+			Expect(files[2].Type).To(Equal("symlink"))
+			Expect(files[2].Target).To(Equal("dir/file"))
+		})
+
+		It("lists the paths under a path in the volume", func() {
+			recorder := listFiles("?path=dir")
+			Expect(recorder.Code).To(Equal(200))
+
+			var files []baggageclaim.FileEntry
+			Expect(json.NewDecoder(recorder.Body).Decode(&files)).To(Succeed())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Path).To(Equal("file"))
+		})
+
+		It("returns 400 for a path that leads out of the volume", func() {
+			Expect(listFiles("?path=../..").Code).To(Equal(400))
+		})
+
+		It("returns 404 for a path that does not exist", func() {
+			recorder := listFiles("?path=bogus")
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Body.String()).To(ContainSubstring(api.ErrStreamOutNotFound.Error()))
+		})
+
+		It("returns 404 for a volume that does not exist", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes/bogus-handle/files", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+		})
+	})
+
+	Describe("updating a volume", func() {
+		It("can have it's properties updated", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties: baggageclaim.VolumeProperties{
+					"property-name": "property-val",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes?property-name=property-val", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+
+			err = json.NewEncoder(body).Encode(baggageclaim.PropertyRequest{
+				Value: "other-val",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/properties/property-name", volumes[0].Handle), body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Body.String()).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes?property-name=other-val", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(volumes).To(HaveLen(1))
+		})
+
+		It("only has a property updated if it has the expected value", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties: baggageclaim.VolumeProperties{
+					"property-name": "property-val",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			setProperty := func(name string, propertyRequest baggageclaim.PropertyRequest) *httptest.ResponseRecorder {
+				body := &bytes.Buffer{}
+				Expect(json.NewEncoder(body).Encode(propertyRequest)).To(Succeed())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties/"+name, body)
+				handler.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			stale := "stale-val"
+			recorder = setProperty("property-name", baggageclaim.PropertyRequest{Value: "other-val", Expected: &stale})
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(recorder.Body.String()).To(ContainSubstring(volume.ErrPropertyConflict.Error()))
+
+			recorder = setProperty("property-name", baggageclaim.PropertyRequest{Value: "other-val", ExpectAbsent: true})
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+
+			current := "property-val"
+			recorder = setProperty("property-name", baggageclaim.PropertyRequest{Value: "other-val", Expected: &current})
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			recorder = setProperty("new-property", baggageclaim.PropertyRequest{Value: "new-val", ExpectAbsent: true})
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes?property-name=other-val&new-property=new-val", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			Expect(json.NewDecoder(recorder.Body).Decode(&volumes)).To(Succeed())
+			Expect(volumes).To(HaveLen(1))
+		})
+
+		It("can have several properties set at once", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties: baggageclaim.VolumeProperties{
+					"property-name": "property-val",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewEncoder(body).Encode(baggageclaim.VolumeProperties{
+				"property-name":  "other-val",
+				"other-property": "some-val",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("PUT", "/volumes/some-handle/properties", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Body.String()).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes?property-name=other-val&other-property=some-val", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+		})
+
+		It("returns 404 when setting properties on a volume that does not exist", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/volumes/bogus-handle/properties", bytes.NewBufferString(`{"some":"property"}`))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("can have its properties deleted", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties: baggageclaim.VolumeProperties{
+					"property-name":  "property-val",
+					"other-property": "other-val",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			for i := 0; i < 2; i++ {
+				recorder = httptest.NewRecorder()
+				request, _ = http.NewRequest("DELETE", "/volumes/some-handle/properties/property-name", nil)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusNoContent))
+				Expect(recorder.Body.String()).To(BeEmpty())
+			}
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes?property-name=property-val", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes/some-handle", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var fetchedVolume volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchedVolume.Properties).To(Equal(volume.Properties{"other-property": "other-val"}))
+		})
+
+		It("can have one of its properties fetched", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				Properties: baggageclaim.VolumeProperties{
+					"property-name": "property-val",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes/some-handle/properties/property-name", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body).To(MatchJSON(`{"value":"property-val"}`))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes/some-handle/properties/other-property", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+			var responseError *api.ErrorResponse
+			err = json.NewDecoder(recorder.Body).Decode(&responseError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseError.Message).To(Equal(volume.ErrPropertyDoesNotExist.Error()))
+		})
+
+		It("returns 404 when getting a property of a volume that does not exist", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes/bogus-handle/properties/property-name", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+			var responseError *api.ErrorResponse
+			err := json.NewDecoder(recorder.Body).Decode(&responseError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseError.Message).To(Equal(api.ErrGetPropertyFailed.Error()))
+		})
+
+		It("returns 404 when deleting a property from a volume that does not exist", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("DELETE", "/volumes/bogus-handle/properties/property-name", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("can have its ttl updated", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				TTLInSeconds: 1,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			var firstVolume volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&firstVolume)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(firstVolume.TTL).To(Equal(volume.TTL(1)))
+
+			err = json.NewEncoder(body).Encode(baggageclaim.TTLRequest{
+				Value: 2,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/ttl", firstVolume.Handle), body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Body.String()).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].TTL).To(Equal(volume.TTL(2)))
+			Expect(volumes[0].ExpiresAt).NotTo(Equal(firstVolume.ExpiresAt))
+		})
+
+		Context("when setting when the volume expires", func() {
+			var myVolume volume.Volume
+
+			JustBeforeEach(func() {
+				body := &bytes.Buffer{}
+
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(201))
+
+				err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			setTTL := func(body string) int {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/ttl", myVolume.Handle), bytes.NewBufferString(body))
+				handler.ServeHTTP(recorder, request)
+				return recorder.Code
+			}
+
+			getVolume := func() volume.Volume {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s", myVolume.Handle), nil)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				var fetchedVolume volume.Volume
+				err := json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+				Expect(err).NotTo(HaveOccurred())
+
+				return fetchedVolume
+			}
+
+			It("expires the volume at that time by the server's clock", func() {
+				expiresAt := fakeClock.Now().Add(time.Hour)
+
+				Expect(setTTL(fmt.Sprintf(`{"expires_at":%q}`, expiresAt.Format(time.RFC3339)))).To(Equal(http.StatusNoContent))
+
+				fetchedVolume := getVolume()
+				Expect(fetchedVolume.TTL).To(Equal(volume.TTL(3600)))
+				Expect(fetchedVolume.ExpiresAt.Unix()).To(Equal(expiresAt.Unix()))
+				Expect(fetchedVolume.PendingDestroy).To(BeFalse())
+			})
+
+			It("makes the volume pending destroy at once if the time has passed", func() {
+				expiresAt := fakeClock.Now().Add(-time.Hour)
+
+				Expect(setTTL(fmt.Sprintf(`{"expires_at":%q}`, expiresAt.Format(time.RFC3339)))).To(Equal(http.StatusNoContent))
+
+				fetchedVolume := getVolume()
+				Expect(fetchedVolume.ExpiresAt.Unix()).To(Equal(expiresAt.Unix()))
+				Expect(fetchedVolume.PendingDestroy).To(BeTrue())
+			})
+
+			It("returns 422 if a TTL is given as well", func() {
+				expiresAt := fakeClock.Now().Add(time.Hour)
+
+				Expect(setTTL(fmt.Sprintf(`{"value":60,"expires_at":%q}`, expiresAt.Format(time.RFC3339)))).To(Equal(422))
+
+				Expect(getVolume().TTL.IsUnlimited()).To(BeTrue())
+			})
+		})
+
+		Context("when a label schema is registered for the property", func() {
+			BeforeEach(func() {
+				labelSchemas = volume.LabelSchemas{
+					"size-class": volume.LabelSchema{Values: []string{"small", "medium", "large"}},
+				}
+			})
+
+			createVolume := func(properties baggageclaim.VolumeProperties) int {
+				body := &bytes.Buffer{}
+
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					Properties: properties,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+
+				return recorder.Code
+			}
+
+			setProperty := func(value string) int {
+				body := &bytes.Buffer{}
+
+				err := json.NewEncoder(body).Encode(baggageclaim.PropertyRequest{
+					Value: value,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties/size-class", body)
+				handler.ServeHTTP(recorder, request)
+
+				return recorder.Code
+			}
+
+			It("accepts allowed values", func() {
+				Expect(createVolume(baggageclaim.VolumeProperties{"size-class": "small"})).To(Equal(201))
+				Expect(setProperty("large")).To(Equal(http.StatusNoContent))
+			})
+
+			It("rejects other values with 422", func() {
+				Expect(createVolume(baggageclaim.VolumeProperties{"size-class": "smal"})).To(Equal(422))
+
+				Expect(createVolume(baggageclaim.VolumeProperties{"other": "free-form"})).To(Equal(201))
+				Expect(setProperty("huge")).To(Equal(422))
+			})
+
+			It("rejects setting several properties if any value is not allowed, setting none of them", func() {
+				Expect(createVolume(baggageclaim.VolumeProperties{"size-class": "small"})).To(Equal(201))
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties", bytes.NewBufferString(`{"other":"free-form","size-class":"huge"}`))
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(422))
+
+				recorder = httptest.NewRecorder()
+				request, _ = http.NewRequest("GET", "/volumes/some-handle", nil)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+
+				var fetchedVolume volume.Volume
+				err := json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fetchedVolume.Properties).To(Equal(volume.Properties{"size-class": "small"}))
+			})
+		})
+
+		Context("when properties are limited", func() {
+			BeforeEach(func() {
+				propertyLimits = volume.PropertyLimits{MaxNameLength: 16, MaxValueSize: 8}
+			})
+
+			createVolume := func(properties baggageclaim.VolumeProperties) *httptest.ResponseRecorder {
+				body := &bytes.Buffer{}
+
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					Properties: properties,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+
+				return recorder
+			}
+
+			setProperty := func(name string, value string) *httptest.ResponseRecorder {
+				body := &bytes.Buffer{}
+
+				err := json.NewEncoder(body).Encode(baggageclaim.PropertyRequest{
+					Value: value,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties/"+name, body)
+				handler.ServeHTTP(recorder, request)
+
+				return recorder
+			}
+
+			errorResponse := func(recorder *httptest.ResponseRecorder) api.ErrorResponse {
+				var response api.ErrorResponse
+				Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+				return response
+			}
+
+			It("accepts properties within the limits", func() {
+				Expect(createVolume(baggageclaim.VolumeProperties{"some-name": "value"}).Code).To(Equal(201))
+				Expect(setProperty("other-name", "12345678").Code).To(Equal(http.StatusNoContent))
+			})
+
+			It("rejects creating a volume with a bad name or too large a value with 422, saying which", func() {
+				recorder := createVolume(baggageclaim.VolumeProperties{"some\nname": "value"})
+				Expect(recorder.Code).To(Equal(422))
+				Expect(errorResponse(recorder).Fields).To(Equal([]baggageclaim.FieldError{
+					{Field: "properties", Code: baggageclaim.FieldErrorNotAllowed, Message: volume.ErrInvalidPropertyName.Error()},
+				}))
+
+				recorder = createVolume(baggageclaim.VolumeProperties{"some-name": "123456789"})
+				Expect(recorder.Code).To(Equal(422))
+				Expect(errorResponse(recorder).Fields).To(Equal([]baggageclaim.FieldError{
+					{Field: "properties", Code: baggageclaim.FieldErrorTooLarge, Message: volume.ErrPropertyValueTooLarge.Error()},
+				}))
+			})
+
+			It("rejects setting a bad name or too large a value with 422, storing neither", func() {
+				Expect(createVolume(baggageclaim.VolumeProperties{}).Code).To(Equal(201))
+
+				recorder := setProperty("some%0Aname", "value")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(errorResponse(recorder).Message).To(Equal(volume.ErrInvalidPropertyName.Error()))
+
+				recorder = setProperty("a-very-long-property-name", "value")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(errorResponse(recorder).Message).To(Equal(volume.ErrInvalidPropertyName.Error()))
+
+				recorder = setProperty("some-name", "123456789")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(errorResponse(recorder).Message).To(Equal(volume.ErrPropertyValueTooLarge.Error()))
+
+				recorder = httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties", bytes.NewBufferString(`{"other":"ok","some-name":"123456789"}`))
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(422))
+
+				recorder = httptest.NewRecorder()
+				request, _ = http.NewRequest("GET", "/volumes/some-handle", nil)
+				handler.ServeHTTP(recorder, request)
+
+				var fetchedVolume volume.Volume
+				Expect(json.NewDecoder(recorder.Body).Decode(&fetchedVolume)).To(Succeed())
+				Expect(fetchedVolume.Properties).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("committing a volume", func() {
+		var myVolume volume.Volume
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(myVolume.Committed).To(BeFalse())
+		})
+
+		getVolume := func() volume.Volume {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var fetchedVolume volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+			Expect(err).NotTo(HaveOccurred())
+
+			return fetchedVolume
+		}
+
+		It("marks the volume as committed", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/commit", myVolume.Handle), &bytes.Buffer{})
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			fetchedVolume := getVolume()
+			Expect(fetchedVolume.Committed).To(BeTrue())
+			Expect(fetchedVolume.CommittedAt).NotTo(BeZero())
+			Expect(fetchedVolume.Frozen).To(BeFalse())
+		})
+
+		Context("when freeze is requested", func() {
+			JustBeforeEach(func() {
+				body := &bytes.Buffer{}
+				err := json.NewEncoder(body).Encode(baggageclaim.CommitRequest{
+					Freeze: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/commit", myVolume.Handle), body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			})
+
+			It("marks the volume as frozen", func() {
+				Expect(getVolume().Frozen).To(BeTrue())
+			})
+
+			It("stays frozen when committed again", func() {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/commit", myVolume.Handle), &bytes.Buffer{})
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+				Expect(getVolume().Frozen).To(BeTrue())
+			})
+
+			It("rejects streaming into the volume with 409", func() {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s", myVolume.Handle, "dest-path"), &bytes.Buffer{})
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusConflict))
+			})
+		})
+
+		It("returns 404 when volume is not found", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/bogus-handle/commit", &bytes.Buffer{})
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("relabeling a volume", func() {
+		var myVolume volume.Volume
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		relabel := func(handle string, label string) *httptest.ResponseRecorder {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.SELinuxLabelRequest{
+				Label: label,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/selinux-label", handle), body)
+			handler.ServeHTTP(recorder, request)
+
+			return recorder
+		}
+
+		It("succeeds, doing nothing without SELinux", func() {
+			recorder := relabel(myVolume.Handle, "system_u:object_r:container_file_t:s0")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("returns 400 with the reason for a malformed label", func() {
+			recorder := relabel(myVolume.Handle, "container_file_t")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring(volume.ErrInvalidSELinuxLabel.Error()))
+		})
+
+		It("returns 409 for a frozen volume", func() {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.CommitRequest{
+				Freeze: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/commit", myVolume.Handle), body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			recorder = relabel(myVolume.Handle, "system_u:object_r:container_file_t:s0")
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("returns 404 when volume is not found", func() {
+			recorder := relabel("bogus-handle", "system_u:object_r:container_file_t:s0")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects a stream-in with a malformed label with 400", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/stream-in?path=%s&selinux-label=%s", myVolume.Handle, "dest-path", "bogus"), &bytes.Buffer{})
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("materializing a volume", func() {
+		var myVolume volume.Volume
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports that a volume not backed by a mount needed no mounting", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/materialize", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var response baggageclaim.MaterializeResponse
+			Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+			Expect(response.Mounted).To(BeFalse())
+		})
+
+		It("returns 404 for an unknown volume", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/bogus-handle/materialize", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+		})
+	})
+
+	Describe("getting a volume", func() {
+		var myVolume volume.Volume
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				TTLInSeconds: 60,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		getVolume := func() map[string]interface{} {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/volumes/%s", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var fetchedVolume map[string]interface{}
+			err := json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+			Expect(err).NotTo(HaveOccurred())
+
+			return fetchedVolume
+		}
+
+		It("reports pending_destroy once the TTL has expired", func() {
+			Expect(getVolume()).To(HaveKeyWithValue("pending_destroy", false))
+
+			fakeClock.Increment(2 * time.Minute)
+
+			Expect(getVolume()).To(HaveKeyWithValue("pending_destroy", true))
+		})
+
+		It("reports when the volume was created and last modified", func() {
+			fetchedVolume := getVolume()
+			Expect(fetchedVolume).To(HaveKey("created_at"))
+			Expect(fetchedVolume).To(HaveKeyWithValue("modified_at", fetchedVolume["created_at"]))
+		})
+	})
+
+	Describe("checking whether a volume exists", func() {
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+		})
+
+		It("responds with 200 and no body for a volume that exists", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("HEAD", "/volumes/some-handle", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+
+		It("responds with 404 and no body for a volume that does not", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("HEAD", "/volumes/bogus-handle", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+	})
+
+	Describe("recording access to a volume", func() {
+		var myVolume volume.Volume
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				TTLInSeconds: 60,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			err = json.NewDecoder(recorder.Body).Decode(&myVolume)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(myVolume.LastAccessedAt).To(BeZero())
+		})
+
+		It("updates the last access time without changing the TTL", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/volumes/%s/touch-access", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", fmt.Sprintf("/volumes/%s", myVolume.Handle), nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var fetchedVolume volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&fetchedVolume)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchedVolume.LastAccessedAt).NotTo(BeZero())
+			Expect(fetchedVolume.TTL).To(Equal(myVolume.TTL))
+			Expect(fetchedVolume.ExpiresAt).To(Equal(myVolume.ExpiresAt))
+		})
+
+		It("returns 404 when volume is not found", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/bogus-handle/touch-access", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("leasing a volume", func() {
+		var lease baggageclaim.LeaseResponse
+
+		JustBeforeEach(func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			body = &bytes.Buffer{}
+			Expect(json.NewEncoder(body).Encode(baggageclaim.LeaseRequest{TTL: 60})).To(Succeed())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("POST", "/volumes/some-handle/lease", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			Expect(json.NewDecoder(recorder.Body).Decode(&lease)).To(Succeed())
+			Expect(lease.Token).NotTo(BeEmpty())
+		})
+
+		setProperty := func(token string) *httptest.ResponseRecorder {
+			body := &bytes.Buffer{}
+			Expect(json.NewEncoder(body).Encode(baggageclaim.PropertyRequest{Value: "property-val"})).To(Succeed())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/volumes/some-handle/properties/property-name", body)
+			if token != "" {
+				request.Header.Set(baggageclaim.LeaseTokenHeader, token)
+			}
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		It("answers 423 to changes made without the lease's token", func() {
+			recorder := setProperty("")
+			Expect(recorder.Code).To(Equal(http.StatusLocked))
+			Expect(recorder.Body.String()).To(ContainSubstring(volume.ErrVolumeIsLeased.Error()))
+
+			recorder = httptest.NewRecorder()
+			request, _ := http.NewRequest("DELETE", "/volumes/some-handle", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusLocked))
+
+			recorder = setProperty(lease.Token)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("answers 423 to a lease of another holder", func() {
+			body := &bytes.Buffer{}
+			Expect(json.NewEncoder(body).Encode(baggageclaim.LeaseRequest{TTL: 60})).To(Succeed())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/some-handle/lease", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusLocked))
+		})
+
+		It("lets the volume be changed once the lease is released", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("DELETE", "/volumes/some-handle/lease", nil)
+			request.Header.Set(baggageclaim.LeaseTokenHeader, lease.Token)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			recorder = setProperty("")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+			body := &bytes.Buffer{}
+			Expect(json.NewEncoder(body).Encode(baggageclaim.LeaseRequest{TTL: 60, Token: lease.Token})).To(Succeed())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("POST", "/volumes/some-handle/lease", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("answers 422 to a lease without a TTL", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/some-handle/lease", bytes.NewBufferString(`{"ttl":0}`))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("returns 404 when volume is not found", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/bogus-handle/lease", bytes.NewBufferString(`{"ttl":60}`))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("destroying a volume", func() {
+		It("can be destroyed", func() {
+			body := &bytes.Buffer{}
+
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle: "some-handle",
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+				TTLInSeconds: 0,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			var createdVolume volume.Volume
+			err = json.NewDecoder(recorder.Body).Decode(&createdVolume)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = json.NewEncoder(body).Encode(baggageclaim.TTLRequest{
+				Value: 2,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder = httptest.NewRecorder()
+			request, err = http.NewRequest("DELETE", fmt.Sprintf("/volumes/%s", createdVolume.Handle), nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Body.String()).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes volume.Volumes
+			err = json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(0))
+		})
+
+		Context("when the volume does not exist", func() {
+			It("returns 404", func() {
+				recorder := httptest.NewRecorder()
+				request, err := http.NewRequest("DELETE", "/volumes/bogus-handle", nil)
+				Expect(err).NotTo(HaveOccurred())
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			})
+
+			Context("when missing-ok is requested", func() {
+				It("returns 204", func() {
+					recorder := httptest.NewRecorder()
+					request, err := http.NewRequest("DELETE", "/volumes/bogus-handle?missing-ok=true", nil)
+					Expect(err).NotTo(HaveOccurred())
+					handler.ServeHTTP(recorder, request)
+					Expect(recorder.Code).To(Equal(http.StatusNoContent))
+					Expect(recorder.Body.String()).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when the volume has copy-on-write children", func() {
+			create := func(handle string, strategy map[string]string) {
+				body := &bytes.Buffer{}
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle:   handle,
+					Strategy: encStrategy(strategy),
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(201))
+			}
+
+			destroy := func(path string) *httptest.ResponseRecorder {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("DELETE", path, nil)
+				handler.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			exists := func(handle string) bool {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("GET", "/volumes/"+handle, nil)
+				handler.ServeHTTP(recorder, request)
+				return recorder.Code == http.StatusOK
+			}
+
+			JustBeforeEach(func() {
+				create("some-handle", map[string]string{"type": "empty"})
+				create("child-handle", map[string]string{"type": "cow", "volume": "some-handle"})
+			})
+
+			It("returns 409, leaving the volume as it is", func() {
+				recorder := destroy("/volumes/some-handle")
+				Expect(recorder.Code).To(Equal(http.StatusConflict))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrVolumeHasChildren.Error()))
+
+				Expect(exists("some-handle")).To(BeTrue())
+			})
+
+			destroyed := func(recorder *httptest.ResponseRecorder) []string {
+				var response baggageclaim.DestroyVolumeResponse
+				Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+				return response.Destroyed
+			}
+
+			It("destroys the volume along with its children when forced, reporting them", func() {
+				recorder := destroy("/volumes/some-handle?force=true")
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(destroyed(recorder)).To(Equal([]string{"child-handle", "some-handle"}))
+
+				Expect(exists("some-handle")).To(BeFalse())
+				Expect(exists("child-handle")).To(BeFalse())
+			})
+
+			It("reports what a forced destroy would destroy on a dry run, leaving them be", func() {
+				recorder := destroy("/volumes/some-handle?force=true&dryRun=true")
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(destroyed(recorder)).To(Equal([]string{"child-handle", "some-handle"}))
+
+				Expect(exists("some-handle")).To(BeTrue())
+				Expect(exists("child-handle")).To(BeTrue())
+			})
+
+			It("fails a dry run as the destroy would", func() {
+				recorder := destroy("/volumes/some-handle?dryRun=true")
+				Expect(recorder.Code).To(Equal(http.StatusConflict))
+
+				recorder = destroy("/volumes/child-handle?dryRun=true")
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(destroyed(recorder)).To(Equal([]string{"child-handle"}))
+
+				Expect(destroy("/volumes/bogus-handle?dryRun=true&missing-ok=true").Code).To(Equal(http.StatusNotFound))
+
+				Expect(exists("child-handle")).To(BeTrue())
+			})
+
+			It("destroys the volume once its children are gone", func() {
+				Expect(destroy("/volumes/child-handle").Code).To(Equal(http.StatusNoContent))
+				Expect(destroy("/volumes/some-handle").Code).To(Equal(http.StatusNoContent))
+
+				Expect(exists("some-handle")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("restoring a deleted volume", func() {
+		serve := func(method string, path string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(method, path, nil)
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		create := func(handle string, strategy map[string]string) {
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:     handle,
+				Strategy:   encStrategy(strategy),
+				Properties: baggageclaim.VolumeProperties{"some": "property"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+		}
+
+		list := func(query string) []baggageclaim.VolumeResponse {
+			recorder := serve("GET", "/volumes"+query)
+			Expect(recorder.Code).To(Equal(200))
+
+			var volumes []baggageclaim.VolumeResponse
+			err := json.NewDecoder(recorder.Body).Decode(&volumes)
+			Expect(err).NotTo(HaveOccurred())
+
+			return volumes
+		}
+
+		BeforeEach(func() {
+			deletedRetention = time.Hour
+		})
+
+		JustBeforeEach(func() {
+			create("some-handle", map[string]string{"type": "empty"})
+			Expect(serve("DELETE", "/volumes/some-handle").Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("responds with the volume, which is listed again", func() {
+			Expect(serve("GET", "/volumes/some-handle").Code).To(Equal(404))
+			Expect(list("")).To(BeEmpty())
+
+			recorder := serve("POST", "/volumes/some-handle/restore")
+			Expect(recorder.Code).To(Equal(200))
+
+			var restored volume.Volume
+			err := json.NewDecoder(recorder.Body).Decode(&restored)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restored.Handle).To(Equal("some-handle"))
+			Expect(restored.Deleted).To(BeFalse())
+
+			Expect(serve("GET", "/volumes/some-handle").Code).To(Equal(200))
+			Expect(list("")).To(HaveLen(1))
+		})
+
+		It("is not found to exist until it is restored", func() {
+			Expect(serve("HEAD", "/volumes/some-handle").Code).To(Equal(404))
+
+			Expect(serve("POST", "/volumes/some-handle/restore").Code).To(Equal(200))
+
+			Expect(serve("HEAD", "/volumes/some-handle").Code).To(Equal(200))
+		})
+
+		It("lists deleted volumes alongside the others when asked to", func() {
+			create("other-handle", map[string]string{"type": "empty"})
+
+			volumes := list("?includeDeleted=true&some=property")
+			Expect(volumes).To(HaveLen(2))
+
+			deleted := map[string]bool{}
+			for _, v := range volumes {
+				deleted[v.Handle] = v.Deleted
+			}
+
+			Expect(deleted).To(Equal(map[string]bool{"some-handle": true, "other-handle": false}))
+		})
+
+		It("responds with 422 when includeDeleted is not a boolean", func() {
+			Expect(serve("GET", "/volumes?includeDeleted=maybe").Code).To(Equal(422))
+		})
+
+		It("responds with 404 when the volume never existed", func() {
+			Expect(serve("POST", "/volumes/bogus-handle/restore").Code).To(Equal(404))
+		})
+
+		It("responds with 410 once the retention window has passed", func() {
+			fakeClock.Increment(time.Hour)
+
+			Expect(serve("POST", "/volumes/some-handle/restore").Code).To(Equal(http.StatusGone))
+		})
+
+		Context("when the volume is a copy-on-write child of a deleted volume", func() {
+			JustBeforeEach(func() {
+				Expect(serve("POST", "/volumes/some-handle/restore").Code).To(Equal(200))
+
+				create("child-handle", map[string]string{"type": "cow", "volume": "some-handle"})
+				Expect(serve("DELETE", "/volumes/child-handle").Code).To(Equal(http.StatusNoContent))
+				Expect(serve("DELETE", "/volumes/some-handle").Code).To(Equal(http.StatusNoContent))
+			})
+
+			It("responds with 409 until the parent is restored", func() {
+				Expect(serve("POST", "/volumes/child-handle/restore").Code).To(Equal(http.StatusConflict))
+
+				Expect(serve("POST", "/volumes/some-handle/restore").Code).To(Equal(200))
+				Expect(serve("POST", "/volumes/child-handle/restore").Code).To(Equal(200))
+			})
+		})
+	})
+
+	Describe("destroying volumes in bulk", func() {
+		JustBeforeEach(func() {
+			for _, handle := range []string{"handle-a", "handle-b"} {
+				body := &bytes.Buffer{}
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: handle,
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(201))
+			}
+		})
+
+		It("destroys the volumes and reports how each one went", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/destroy", bytes.NewBufferString(`["handle-a", "bogus-handle", "handle-b"]`))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var results []baggageclaim.DestroyVolumesResult
+			err := json.NewDecoder(recorder.Body).Decode(&results)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]baggageclaim.DestroyVolumesResult{
+				{Handle: "handle-a"},
+				{Handle: "bogus-handle"},
+				{Handle: "handle-b"},
+			}))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/volumes", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Body).To(MatchJSON("[]"))
+		})
+
+		It("returns 400 when the handles are not a JSON array", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/destroy", bytes.NewBufferString(`{"handle": "handle-a"}`))
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		Describe("by properties", func() {
+			JustBeforeEach(func() {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("PUT", "/volumes/handle-a/properties/build-id", bytes.NewBufferString(`{"value": "42"}`))
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			})
+
+			destroy := func(query string) *httptest.ResponseRecorder {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("DELETE", "/volumes"+query, nil)
+				handler.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			It("destroys the volumes that have them and counts them", func() {
+				recorder := destroy("?build-id=42&reason=pipeline-destroyed")
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Body).To(MatchJSON(`{"destroyed": 1, "failed": []}`))
+
+				recorder = httptest.NewRecorder()
+				request, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(recorder, request)
+
+				var volumes []baggageclaim.VolumeResponse
+				err := json.NewDecoder(recorder.Body).Decode(&volumes)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].Handle).To(Equal("handle-b"))
+			})
+
+			It("succeeds with a count of zero when no volume has them", func() {
+				recorder := destroy("?build-id=43")
+				Expect(recorder.Code).To(Equal(200))
+				Expect(recorder.Body).To(MatchJSON(`{"destroyed": 0, "failed": []}`))
+			})
+
+			It("returns 400 without any properties", func() {
+				recorder := destroy("?reason=everything")
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("streaming the volume events", func() {
+		var (
+			server *httptest.Server
+			cancel context.CancelFunc
+			lines  *bufio.Reader
+		)
+
+		JustBeforeEach(func() {
+			server = httptest.NewServer(handler)
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+
+			request, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/volumes/events", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(response.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+			lines = bufio.NewReader(response.Body)
+		})
+
+		AfterEach(func() {
+			cancel()
+			server.Close()
+		})
+
+		readEvent := func() (string, string) {
+			var eventType, data string
+			for {
+				line, err := lines.ReadString('\n')
+				Expect(err).NotTo(HaveOccurred())
+
+				switch {
+				case line == "\n":
+					return eventType, data
+				case len(line) > len("event: ") && line[:len("event: ")] == "event: ":
+					eventType = line[len("event: ") : len(line)-1]
+				case len(line) > len("data: ") && line[:len("data: ")] == "data: ":
+					data = line[len("data: ") : len(line)-1]
+				}
+			}
+		}
+
+		It("starts with a resync, then streams the lifecycle of the volumes", func() {
+			eventType, _ := readEvent()
+			Expect(eventType).To(Equal(baggageclaim.EventResync))
+
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Handle:     "some-handle",
+				Strategy:   encStrategy(map[string]string{"type": "empty"}),
+				Properties: baggageclaim.VolumeProperties{"team": "main"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(201))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("DELETE", "/volumes/some-handle", nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(204))
+
+			var event baggageclaim.VolumeEventResponse
+
+			eventType, data := readEvent()
+			Expect(eventType).To(Equal("created"))
+			Expect(json.Unmarshal([]byte(data), &event)).To(Succeed())
+			Expect(event.Handle).To(Equal("some-handle"))
+			Expect(event.Properties).To(Equal(baggageclaim.VolumeProperties{"team": "main"}))
+
+			eventType, data = readEvent()
+			Expect(eventType).To(Equal("destroyed"))
+			Expect(json.Unmarshal([]byte(data), &event)).To(Succeed())
+			Expect(event.Handle).To(Equal("some-handle"))
+			Expect(event.Reason).To(Equal("manual"))
+		})
+	})
+
+	Describe("getting the usage of the volumes", func() {
+		JustBeforeEach(func() {
+			for handle, team := range map[string]string{"handle-a": "main", "handle-b": "other"} {
+				body := &bytes.Buffer{}
+				err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle:     handle,
+					Strategy:   encStrategy(map[string]string{"type": "empty"}),
+					Properties: baggageclaim.VolumeProperties{"team": team},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", body)
+				handler.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(201))
+			}
+		})
+
+		getUsage := func(query string) baggageclaim.UsageResponse {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes/usage"+query, nil)
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(200))
+
+			var usage baggageclaim.UsageResponse
+			err := json.NewDecoder(recorder.Body).Decode(&usage)
+			Expect(err).NotTo(HaveOccurred())
+
+			return usage
+		}
+
+		It("returns the totals without grouping", func() {
+			usage := getUsage("")
+			Expect(usage.Volumes).To(Equal(2))
+			Expect(usage.FreeBytes).NotTo(BeZero())
+			Expect(usage.GroupedBy).To(BeEmpty())
+			Expect(usage.Groups).To(BeNil())
+		})
+
+		It("groups the volumes by the property asked for", func() {
+			usage := getUsage("?group-by=team")
+			Expect(usage.Volumes).To(Equal(2))
+			Expect(usage.GroupedBy).To(Equal("team"))
+			Expect(usage.Groups).To(HaveLen(2))
+			Expect(usage.Groups["main"].Volumes).To(Equal(1))
+			Expect(usage.Groups["other"].Volumes).To(Equal(1))
+			Expect(usage.Ungrouped).To(Equal(&baggageclaim.UsageGroupResponse{}))
+		})
+	})
+
+	Describe("creating a volume with an idempotency key", func() {
+		create := func(request baggageclaim.VolumeRequest) *httptest.ResponseRecorder {
+			request.Strategy = encStrategy(map[string]string{"type": "empty"})
+			request.IdempotencyKey = "some-key"
+
+			body := &bytes.Buffer{}
+			err := json.NewEncoder(body).Encode(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest("POST", "/volumes", body)
+			handler.ServeHTTP(recorder, httpRequest)
+
+			return recorder
+		}
+
+		var created volume.Volume
+
+		JustBeforeEach(func() {
+			recorder := create(baggageclaim.VolumeRequest{TTLInSeconds: 60})
+			Expect(recorder.Code).To(Equal(201))
+			Expect(json.NewDecoder(recorder.Body).Decode(&created)).To(Succeed())
+		})
+
+		It("responds with the volume already created when it is retried", func() {
+			recorder := create(baggageclaim.VolumeRequest{TTLInSeconds: 60})
+			Expect(recorder.Code).To(Equal(201))
+
+			var retried volume.Volume
+			Expect(json.NewDecoder(recorder.Body).Decode(&retried)).To(Succeed())
+			Expect(retried.Handle).To(Equal(created.Handle))
+
+			recorder = httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/volumes", nil)
+			handler.ServeHTTP(recorder, request)
+
+			var volumes []volume.Volume
+			Expect(json.NewDecoder(recorder.Body).Decode(&volumes)).To(Succeed())
+			Expect(volumes).To(HaveLen(1))
+		})
+
+		It("responds with 409 when the key was used with other parameters", func() {
+			recorder := create(baggageclaim.VolumeRequest{TTLInSeconds: 120})
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("responds with 409 when the retry asks for another handle", func() {
+			recorder := create(baggageclaim.VolumeRequest{Handle: "other-handle", TTLInSeconds: 60})
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("creating a volume whose generated handles are taken", func() {
+		var (
+			fakeRepository  *volumefakes.FakeRepository
+			handleGenerator *fakeHandleGenerator
+			recorder        *httptest.ResponseRecorder
+			body            io.ReadWriter
+		)
+
+		BeforeEach(func() {
+			fakeRepository = new(volumefakes.FakeRepository)
+			handleGenerator = &fakeHandleGenerator{}
+
+			body = &bytes.Buffer{}
+			json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+				Strategy: encStrategy(map[string]string{
+					"type": "empty",
+				}),
+			})
+		})
+
+		JustBeforeEach(func() {
+			server := api.NewVolumeServer(lagertest.NewTestLogger("volume-server"), volume.NewStrategerizer(0, 0, 0), fakeRepository, 0, &api.DrainState{}, 0, handleGenerator)
+
+			recorder = httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes", body)
+			server.CreateVolume(recorder, request)
+		})
+
+		Context("when one is free before long", func() {
+			BeforeEach(func() {
+				fakeRepository.CreateVolumeStub = func(handle string, _ volume.Strategy, _ volume.Properties, _ uint, _ bool, _ volume.CreateOptions) (volume.Volume, error) {
+					if fakeRepository.CreateVolumeCallCount() == 1 {
+						return volume.Volume{}, volume.ErrVolumeAlreadyExists
+					}
+
+					return volume.Volume{Handle: handle}, nil
+				}
+			})
+
+			It("creates the volume with another generated handle", func() {
+				Expect(recorder.Code).To(Equal(201))
+				Expect(fakeRepository.CreateVolumeCallCount()).To(Equal(2))
+
+				first, _, _, _, _, _ := fakeRepository.CreateVolumeArgsForCall(0)
+				second, _, _, _, _, _ := fakeRepository.CreateVolumeArgsForCall(1)
+				Expect(first).To(Equal("generated-handle-1"))
+				Expect(second).To(Equal("generated-handle-2"))
+
+				var response volume.Volume
+				Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+				Expect(response.Handle).To(Equal(second))
+			})
+
+			It("tells the generator which handle was taken", func() {
+				Expect(handleGenerator.taken).To(Equal([]string{"generated-handle-1"}))
+			})
+		})
+
+		Context("when they keep being taken", func() {
+			BeforeEach(func() {
+				fakeRepository.CreateVolumeReturns(volume.Volume{}, volume.ErrVolumeAlreadyExists)
+			})
+
+			It("gives up with 409 after a bounded number of attempts", func() {
+				Expect(recorder.Code).To(Equal(409))
+				Expect(fakeRepository.CreateVolumeCallCount()).To(Equal(3))
+			})
+		})
+
+		Context("when the request has a handle of its own", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+				})
+
+				fakeRepository.CreateVolumeReturns(volume.Volume{}, volume.ErrVolumeAlreadyExists)
+			})
+
+			It("uses it, and responds with 409 rather than generating another", func() {
+				Expect(recorder.Code).To(Equal(409))
+				Expect(fakeRepository.CreateVolumeCallCount()).To(Equal(1))
+
+				handle, _, _, _, _, _ := fakeRepository.CreateVolumeArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(handleGenerator.generated).To(BeZero())
+			})
+		})
+	})
+
+	Describe("cloning a volume whose generated handles are taken", func() {
+		var (
+			fakeRepository  *volumefakes.FakeRepository
+			handleGenerator *fakeHandleGenerator
+			recorder        *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			fakeRepository = new(volumefakes.FakeRepository)
+			handleGenerator = &fakeHandleGenerator{}
+
+			fakeRepository.CloneVolumeStub = func(_ string, handle string) (volume.Volume, error) {
+				if fakeRepository.CloneVolumeCallCount() == 1 {
+					return volume.Volume{}, volume.ErrVolumeAlreadyExists
+				}
+
+				return volume.Volume{Handle: handle}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			server := api.NewVolumeServer(lagertest.NewTestLogger("volume-server"), volume.NewStrategerizer(0, 0, 0), fakeRepository, 0, &api.DrainState{}, 0, handleGenerator)
+
+			recorder = httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/volumes/some-handle/clone", bytes.NewBufferString("{}"))
+			server.CloneVolume(recorder, request)
+		})
+
+		It("clones the volume with another generated handle", func() {
+			Expect(recorder.Code).To(Equal(201))
+			Expect(fakeRepository.CloneVolumeCallCount()).To(Equal(2))
+
+			_, second := fakeRepository.CloneVolumeArgsForCall(1)
+			Expect(second).To(Equal("generated-handle-2"))
+			Expect(handleGenerator.taken).To(Equal([]string{"generated-handle-1"}))
+		})
+	})
+
+	Describe("capping the bandwidth of streams", func() {
+		var (
+			fakeRepository *volumefakes.FakeRepository
+			serverCap      int64
+			header         string
+		)
+
+		BeforeEach(func() {
+			fakeRepository = new(volumefakes.FakeRepository)
+			serverCap = 0
+			header = ""
+		})
+
+		streamIn := func() (int, volume.StreamInOptions) {
+			server := api.NewVolumeServer(lagertest.NewTestLogger("volume-server"), volume.NewStrategerizer(0, 0, 0), fakeRepository, 0, &api.DrainState{}, serverCap, api.UUIDHandleGenerator{})
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/volumes/some-handle/stream-in", bytes.NewBufferString("some-tar"))
+			if header != "" {
+				request.Header.Set(baggageclaim.StreamBytesPerSecondHeader, header)
+			}
+
+			server.StreamIn(recorder, request)
+
+			if fakeRepository.StreamInCallCount() == 0 {
+				return recorder.Code, volume.StreamInOptions{}
+			}
+
+			_, _, _, _, opts := fakeRepository.StreamInArgsForCall(0)
+			return recorder.Code, opts
+		}
+
+		streamOut := func() (int, volume.StreamOutOptions) {
+			server := api.NewVolumeServer(lagertest.NewTestLogger("volume-server"), volume.NewStrategerizer(0, 0, 0), fakeRepository, 0, &api.DrainState{}, serverCap, api.UUIDHandleGenerator{})
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/volumes/some-handle/stream-out", nil)
+			if header != "" {
+				request.Header.Set(baggageclaim.StreamBytesPerSecondHeader, header)
+			}
+
+			server.StreamOut(recorder, request)
+
+			if fakeRepository.StreamOutCallCount() == 0 {
+				return recorder.Code, volume.StreamOutOptions{}
+			}
+
+			_, _, _, _, opts := fakeRepository.StreamOutArgsForCall(0)
+			return recorder.Code, opts
+		}
+
+		It("does not throttle streams by default", func() {
+			code, inOpts := streamIn()
+			Expect(code).To(Equal(http.StatusNoContent))
+			Expect(inOpts.BytesPerSecond).To(BeZero())
+
+			code, outOpts := streamOut()
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(outOpts.BytesPerSecond).To(BeZero())
+		})
+
+		Context("when the server is started with a cap", func() {
+			BeforeEach(func() {
+				serverCap = 1024
+			})
 
-			var volumes volume.Volumes
-			err = json.NewDecoder(recorder.Body).Decode(&volumes)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(volumes).To(HaveLen(1))
+			It("caps every stream", func() {
+				_, inOpts := streamIn()
+				Expect(inOpts.BytesPerSecond).To(Equal(int64(1024)))
 
-			err = json.NewEncoder(body).Encode(baggageclaim.PropertyRequest{
-				Value: "other-val",
+				_, outOpts := streamOut()
+				Expect(outOpts.BytesPerSecond).To(Equal(int64(1024)))
 			})
-			Expect(err).NotTo(HaveOccurred())
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/properties/property-name", volumes[0].Handle), body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(http.StatusNoContent))
-			Expect(recorder.Body.String()).To(BeEmpty())
+			Context("when a request asks for a lower one", func() {
+				BeforeEach(func() {
+					header = "512"
+				})
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("GET", "/volumes?property-name=other-val", nil)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(200))
+				It("uses the lower cap", func() {
+					_, inOpts := streamIn()
+					Expect(inOpts.BytesPerSecond).To(Equal(int64(512)))
 
-			err = json.NewDecoder(recorder.Body).Decode(&volumes)
-			Expect(err).NotTo(HaveOccurred())
+					_, outOpts := streamOut()
+					Expect(outOpts.BytesPerSecond).To(Equal(int64(512)))
+				})
+			})
 
-			Expect(volumes).To(HaveLen(1))
+			Context("when a request asks for a higher one", func() {
+				BeforeEach(func() {
+					header = "4096"
+				})
+
+				It("keeps the server's cap", func() {
+					_, inOpts := streamIn()
+					Expect(inOpts.BytesPerSecond).To(Equal(int64(1024)))
+
+					_, outOpts := streamOut()
+					Expect(outOpts.BytesPerSecond).To(Equal(int64(1024)))
+				})
+			})
 		})
 
-		It("can have its ttl updated", func() {
-			body := &bytes.Buffer{}
+		Context("when a request asks for a cap without the server having one", func() {
+			BeforeEach(func() {
+				header = "4096"
+			})
 
-			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
-				Handle: "some-handle",
-				Strategy: encStrategy(map[string]string{
-					"type": "empty",
-				}),
-				TTLInSeconds: 1,
+			It("uses the request's cap", func() {
+				_, inOpts := streamIn()
+				Expect(inOpts.BytesPerSecond).To(Equal(int64(4096)))
 			})
-			Expect(err).NotTo(HaveOccurred())
+		})
 
-			recorder := httptest.NewRecorder()
-			request, _ := http.NewRequest("POST", "/volumes", body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(201))
+		Context("when the requested cap is not a positive number", func() {
+			BeforeEach(func() {
+				header = "0"
+			})
 
-			var firstVolume volume.Volume
-			err = json.NewDecoder(recorder.Body).Decode(&firstVolume)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(firstVolume.TTL).To(Equal(volume.TTL(1)))
+			It("returns 400 without streaming", func() {
+				code, _ := streamIn()
+				Expect(code).To(Equal(http.StatusBadRequest))
+				Expect(fakeRepository.StreamInCallCount()).To(BeZero())
 
-			err = json.NewEncoder(body).Encode(baggageclaim.TTLRequest{
-				Value: 2,
+				code, _ = streamOut()
+				Expect(code).To(Equal(http.StatusBadRequest))
+				Expect(fakeRepository.StreamOutCallCount()).To(BeZero())
 			})
-			Expect(err).NotTo(HaveOccurred())
+		})
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("PUT", fmt.Sprintf("/volumes/%s/ttl", firstVolume.Handle), body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(http.StatusNoContent))
-			Expect(recorder.Body.String()).To(BeEmpty())
+		Context("when too many streams are in progress", func() {
+			BeforeEach(func() {
+				fakeRepository.StreamInReturns(false, volume.ErrTooManyStreams)
+				fakeRepository.StreamOutReturns(volume.ErrTooManyStreams)
+			})
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("GET", "/volumes", body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(200))
+			It("returns 503", func() {
+				code, _ := streamIn()
+				Expect(code).To(Equal(http.StatusServiceUnavailable))
 
-			var volumes volume.Volumes
-			err = json.NewDecoder(recorder.Body).Decode(&volumes)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(volumes).To(HaveLen(1))
-			Expect(volumes[0].TTL).To(Equal(volume.TTL(2)))
-			Expect(volumes[0].ExpiresAt).NotTo(Equal(firstVolume.ExpiresAt))
+				code, _ = streamOut()
+				Expect(code).To(Equal(http.StatusServiceUnavailable))
+			})
 		})
 	})
 
-	Describe("destroying a volume", func() {
-		It("can be destroyed", func() {
-			body := &bytes.Buffer{}
+	Describe("a compressed stream-out failing partway", func() {
+		var (
+			fakeRepository *volumefakes.FakeRepository
+			recorder       *httptest.ResponseRecorder
+		)
 
-			err := json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
-				Handle: "some-handle",
-				Strategy: encStrategy(map[string]string{
-					"type": "empty",
-				}),
-				TTLInSeconds: 0,
-			})
-			Expect(err).NotTo(HaveOccurred())
+		BeforeEach(func() {
+			if _, found := volume.LookupCodec("zstd"); !found {
+				Skip("zstd is not installed")
+			}
 
-			recorder := httptest.NewRecorder()
-			request, _ := http.NewRequest("POST", "/volumes", body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(201))
+			fakeRepository = new(volumefakes.FakeRepository)
+			fakeRepository.StreamOutStub = func(ctx context.Context, handle string, path string, dest io.Writer, opts volume.StreamOutOptions) error {
+				_, err := dest.Write(bytes.Repeat([]byte("some-contents"), 1024))
+				Expect(err).NotTo(HaveOccurred())
 
-			var createdVolume volume.Volume
-			err = json.NewDecoder(recorder.Body).Decode(&createdVolume)
-			Expect(err).NotTo(HaveOccurred())
+				return errors.New("disk on fire")
+			}
+		})
 
-			err = json.NewEncoder(body).Encode(baggageclaim.TTLRequest{
-				Value: 2,
-			})
-			Expect(err).NotTo(HaveOccurred())
+		JustBeforeEach(func() {
+			server := api.NewVolumeServer(lagertest.NewTestLogger("volume-server"), volume.NewStrategerizer(0, 0, 0), fakeRepository, 0, &api.DrainState{}, 0, api.UUIDHandleGenerator{})
 
 			recorder = httptest.NewRecorder()
-			request, err = http.NewRequest("DELETE", fmt.Sprintf("/volumes/%s", createdVolume.Handle), nil)
-			Expect(err).NotTo(HaveOccurred())
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(http.StatusNoContent))
-			Expect(recorder.Body.String()).To(BeEmpty())
+			request, _ := http.NewRequest("PUT", "/volumes/some-handle/stream-out", nil)
+			request.Header.Set("Accept-Encoding", "zstd")
 
-			recorder = httptest.NewRecorder()
-			request, _ = http.NewRequest("GET", "/volumes", body)
-			handler.ServeHTTP(recorder, request)
-			Expect(recorder.Code).To(Equal(200))
+			server.StreamOut(recorder, request)
+		})
 
-			var volumes volume.Volumes
-			err = json.NewDecoder(recorder.Body).Decode(&volumes)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(volumes).To(HaveLen(0))
+		It("stops the encoder before returning, leaving the stream unfinished", func() {
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("zstd"))
+			Expect(childProcesses("zstd")).To(BeEmpty())
+
+			check := exec.Command("zstd", "--test", "--quiet")
+			check.Stdin = bytes.NewReader(recorder.Body.Bytes())
+			Expect(check.Run()).NotTo(Succeed())
 		})
 	})
 
@@ -689,6 +4993,208 @@ var _ = Describe("Volume Server", func() {
 			})
 		})
 
+		Context("when a volume with the handle already exists", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					Properties: baggageclaim.VolumeProperties{"created": "again"},
+				})
+			})
+
+			JustBeforeEach(func() {
+				Expect(recorder.Code).To(Equal(201))
+
+				createBody := &bytes.Buffer{}
+				json.NewEncoder(createBody).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+				})
+
+				recorder = httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/volumes", createBody)
+				handler.ServeHTTP(recorder, request)
+			})
+
+			It("returns 409", func() {
+				Expect(recorder.Code).To(Equal(409))
+				Expect(recorder.Body).To(ContainSubstring(volume.ErrVolumeAlreadyExists.Error()))
+			})
+
+			It("leaves the existing volume as it was", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes/some-handle", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Code).To(Equal(200))
+				Expect(getRecorder.Body).To(ContainSubstring(`"created":"again"`))
+			})
+		})
+
+		Context("when a size is given", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					SizeInBytes: 1024 * 1024,
+				})
+			})
+
+			It("returns 422 when the driver cannot limit volumes", func() {
+				Expect(recorder.Code).To(Equal(422))
+			})
+
+			It("does not create a volume", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Body).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when a tmpfs volume is requested", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "tmpfs",
+					}),
+					SizeInBytes: 1024 * 1024,
+				})
+			})
+
+			It("returns 422 when tmpfs volumes are not enabled", func() {
+				Expect(recorder.Code).To(Equal(422))
+			})
+
+			It("does not create a volume", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Body).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when mount options are requested of a driver without them", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					MountOptions: []string{"noatime"},
+				})
+			})
+
+			It("returns 422, listing the mount options in the fields of the response", func() {
+				Expect(recorder.Code).To(Equal(422))
+
+				var errResponse api.ErrorResponse
+				Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+				Expect(errResponse.Fields).To(Equal([]baggageclaim.FieldError{
+					{Field: "mount_options", Code: baggageclaim.FieldErrorNotAllowed, Message: "the naive driver does not support mount options"},
+				}))
+			})
+
+			It("does not create a volume", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Body).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when the volume is to be encrypted without a master key", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					Encrypted: true,
+				})
+			})
+
+			It("returns 422, saying encryption is not enabled in the fields of the response", func() {
+				Expect(recorder.Code).To(Equal(422))
+
+				var errResponse api.ErrorResponse
+				Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+				Expect(errResponse.Fields).To(Equal([]baggageclaim.FieldError{
+					{Field: "encrypted", Code: baggageclaim.FieldErrorNotAllowed, Message: volume.ErrEncryptionNotEnabled.Error()},
+				}))
+			})
+
+			It("does not create a volume", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Body).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when the volume is to be read-only", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					ReadOnly: true,
+				})
+			})
+
+			It("returns 422 when the driver cannot make volumes read-only", func() {
+				Expect(recorder.Code).To(Equal(422))
+			})
+
+			It("does not create a volume", func() {
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Body).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when the volume is to renew its TTL on access", func() {
+			BeforeEach(func() {
+				body = &bytes.Buffer{}
+				json.NewEncoder(body).Encode(baggageclaim.VolumeRequest{
+					Handle: "some-handle",
+					Strategy: encStrategy(map[string]string{
+						"type": "empty",
+					}),
+					TTLInSeconds:     60,
+					RenewTTLOnAccess: true,
+				})
+			})
+
+			It("creates it, and says that it does", func() {
+				Expect(recorder.Code).To(Equal(201))
+				Expect(recorder.Body).To(ContainSubstring(`"renew_ttl_on_access":true`))
+
+				getRecorder := httptest.NewRecorder()
+				getReq, _ := http.NewRequest("GET", "/volumes/some-handle", nil)
+				handler.ServeHTTP(getRecorder, getReq)
+				Expect(getRecorder.Code).To(Equal(200))
+
+				var response baggageclaim.VolumeResponse
+				Expect(json.NewDecoder(getRecorder.Body).Decode(&response)).To(Succeed())
+				Expect(response.RenewTTLOnAccess).To(BeTrue())
+			})
+		})
+
 		Context("when there are no properties given", func() {
 			BeforeEach(func() {
 				body = &bytes.Buffer{}
@@ -783,6 +5289,14 @@ var _ = Describe("Volume Server", func() {
 					Expect(recorder.Body).To(ContainSubstring(`"error":`))
 				})
 
+				It("lists the unknown type in the fields of the response", func() {
+					var errResponse api.ErrorResponse
+					Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+					Expect(errResponse.Fields).To(Equal([]baggageclaim.FieldError{
+						{Field: "strategy.type", Code: baggageclaim.FieldErrorUnknownStrategy, Message: volume.ErrUnknownStrategy.Error()},
+					}))
+				})
+
 				It("does not create a volume", func() {
 					getRecorder := httptest.NewRecorder()
 					getReq, _ := http.NewRequest("GET", "/volumes", nil)
@@ -810,6 +5324,14 @@ var _ = Describe("Volume Server", func() {
 					Expect(recorder.Body).To(ContainSubstring(`"error":`))
 				})
 
+				It("lists the missing parent in the fields of the response", func() {
+					var errResponse api.ErrorResponse
+					Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+					Expect(errResponse.Fields).To(Equal([]baggageclaim.FieldError{
+						{Field: "strategy.volume", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoParentVolumeProvided.Error()},
+					}))
+				})
+
 				It("does not create a volume", func() {
 					getRecorder := httptest.NewRecorder()
 					getReq, _ := http.NewRequest("GET", "/volumes", nil)
@@ -837,6 +5359,14 @@ var _ = Describe("Volume Server", func() {
 					Expect(recorder.Body).To(ContainSubstring(`"error":`))
 				})
 
+				It("lists the parent as not found in the fields of the response", func() {
+					var errResponse api.ErrorResponse
+					Expect(json.NewDecoder(recorder.Body).Decode(&errResponse)).To(Succeed())
+					Expect(errResponse.Fields).To(Equal([]baggageclaim.FieldError{
+						{Field: "strategy.volume", Code: baggageclaim.FieldErrorNotFound, Message: volume.ErrParentVolumeNotFound.Error()},
+					}))
+				})
+
 				It("does not create a volume", func() {
 					getRecorder := httptest.NewRecorder()
 					getReq, _ := http.NewRequest("GET", "/volumes", nil)
@@ -856,3 +5386,43 @@ func encStrategy(strategy map[string]string) *json.RawMessage {
 
 	return &msg
 }
+
+type fakeHandleGenerator struct {
+	generated int
+	taken     []string
+}
+
+func (generator *fakeHandleGenerator) Generate() (string, error) {
+	generator.generated++
+	return fmt.Sprintf("generated-handle-%d", generator.generated), nil
+}
+
+func (generator *fakeHandleGenerator) Taken(handle string) {
+	generator.taken = append(generator.taken, handle)
+}
+
+// childProcesses are the pids of this process's children running the
+// command, which have not been waited for if they have exited.
+func childProcesses(command string) []int {
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	Expect(err).NotTo(HaveOccurred())
+
+	children := []int{}
+	for _, stat := range stats {
+		contents, err := ioutil.ReadFile(stat)
+		if err != nil {
+			continue
+		}
+
+		// pid (comm) state ppid ...
+		fields := strings.Fields(string(contents))
+		if len(fields) < 4 || fields[1] != "("+command+")" || fields[3] != strconv.Itoa(os.Getpid()) {
+			continue
+		}
+
+		pid, _ := strconv.Atoi(fields[0])
+		children = append(children, pid)
+	}
+
+	return children
+}
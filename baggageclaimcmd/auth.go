@@ -0,0 +1,27 @@
+package baggageclaimcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// authToken returns the token that API requests must present, or "" if they
+// need not present one.
+func (cmd *BaggageclaimCommand) authToken() (string, error) {
+	if cmd.AuthTokenFile == "" {
+		return "", nil
+	}
+
+	contents, err := ioutil.ReadFile(cmd.AuthTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read auth token file: %s", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("auth token file is empty: %s", cmd.AuthTokenFile)
+	}
+
+	return token, nil
+}
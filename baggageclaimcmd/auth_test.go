@@ -0,0 +1,48 @@
+package baggageclaimcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("authToken", func() {
+	var (
+		cmd    *BaggageclaimCommand
+		tmpDir string
+	)
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "auth-token")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("requires no token without a token file", func() {
+		Expect(cmd.authToken()).To(BeEmpty())
+	})
+
+	It("reads the token, ignoring surrounding whitespace", func() {
+		cmd.AuthTokenFile = filepath.Join(tmpDir, "token")
+		Expect(ioutil.WriteFile(cmd.AuthTokenFile, []byte("some-token\n"), 0600)).To(Succeed())
+
+		Expect(cmd.authToken()).To(Equal("some-token"))
+	})
+
+	It("fails when the token file is empty", func() {
+		cmd.AuthTokenFile = filepath.Join(tmpDir, "token")
+		Expect(ioutil.WriteFile(cmd.AuthTokenFile, []byte("\n"), 0600)).To(Succeed())
+
+		_, err := cmd.authToken()
+		Expect(err).To(HaveOccurred())
+	})
+})
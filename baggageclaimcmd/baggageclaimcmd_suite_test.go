@@ -0,0 +1,13 @@
+package baggageclaimcmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBaggageclaimcmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Baggageclaimcmd Suite")
+}
@@ -1,6 +1,7 @@
 package baggageclaimcmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -8,12 +9,12 @@ import (
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"github.com/concourse/baggageclaim/api"
+	"github.com/concourse/baggageclaim/metrics"
 	"github.com/concourse/baggageclaim/reaper"
 	"github.com/concourse/baggageclaim/uidgid"
 	"github.com/concourse/baggageclaim/volume"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/grouper"
-	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
 	"github.com/xoebus/zest"
 )
@@ -24,6 +25,21 @@ type BaggageclaimCommand struct {
 	BindIP   IPFlag `long:"bind-ip"   default:"127.0.0.1" description:"IP address on which to listen for API traffic."`
 	BindPort uint16 `long:"bind-port" default:"7788"      description:"Port on which to listen for API traffic."`
 
+	GRPCBindPort uint16 `long:"grpc-bind-port" default:"0" description:"Port on which to also serve the API over gRPC, on --bind-ip with the same TLS and auth token. 0 does not serve it."`
+
+	TLSCert     string `long:"tls-cert"      description:"Path to a PEM certificate chain to serve the API over HTTPS with. Requires --tls-key. The API is served over plain HTTP without it."`
+	TLSKey      string `long:"tls-key"       description:"Path to the PEM private key of the --tls-cert certificate."`
+	TLSClientCA string `long:"tls-client-ca" description:"Path to PEM CA certificates that clients must present a certificate signed by. Requires --tls-cert."`
+
+	AuthTokenFile    string `long:"auth-token-file"    description:"Path to a file holding a token that every API request must present as an Authorization: Bearer header, or get 401. The API is unauthenticated without it."`
+	AuthExemptHealth bool   `long:"auth-exempt-health" description:"Serve GET /health without a token, for health checkers that can't be given one."`
+
+	BodyReadTimeout time.Duration `long:"body-read-timeout" default:"1m" description:"Maximum time to spend reading the JSON body of a request. Does not apply to stream-in."`
+
+	ShutdownTimeout time.Duration `long:"shutdown-timeout" default:"1m" description:"How long to wait on shutdown for in-flight streams before canceling them. New streams, creates, and destroys are refused with 503 meanwhile, and canceled stream-ins are rolled back."`
+
+	ControlSocket string `long:"control-socket" description:"Path at which to listen on a unix socket for local drain, status, and reap-now commands. Only the owner may connect."`
+
 	VolumesDir DirFlag `long:"volumes" required:"true" description:"Directory in which to place volume data."`
 
 	Driver string `long:"driver" default:"detect" choice:"detect" choice:"naive" choice:"btrfs" choice:"overlay" description:"Driver to use for managing volumes."`
@@ -31,9 +47,69 @@ type BaggageclaimCommand struct {
 	BtrfsBin string `long:"btrfs-bin" default:"btrfs" description:"Path to btrfs binary"`
 	MkfsBin  string `long:"mkfs-bin" default:"mkfs.btrfs" description:"Path to mkfs.btrfs binary"`
 
+	BtrfsImage bool `long:"btrfs-image" description:"When using the btrfs driver on a non-btrfs filesystem, create and mount a loopback btrfs image at the volumes directory instead of failing."`
+
 	OverlaysDir string `long:"overlays-dir" description:"Path to directory in which to store overlay data"`
 
-	ReapInterval time.Duration `long:"reap-interval" default:"10s" description:"Interval on which to reap expired volumes."`
+	NaiveCopyBufferSize  int `long:"naive-copy-buffer-size" default:"131072" description:"Size in bytes of the buffer each file is copied through when the naive driver copies a volume for a COW volume or a clone. Not used on Windows, where robocopy picks its own."`
+	NaiveCopyParallelism int `long:"naive-copy-parallelism" default:"1"      description:"Number of files and directories the naive driver copies at once when copying a volume. 1 copies them one at a time. Higher values only help with cores and disk bandwidth to spare."`
+
+	StatsCacheTTL time.Duration `long:"stats-cache-ttl" default:"10s" description:"How long the driver reuses what walking a volume found for its stats, e.g. its file count, before walking it again. Streaming or copying into the volume has it walked again sooner. 0 walks it for every request."`
+
+	StreamInIdempotencyWindow time.Duration `long:"stream-in-idempotency-window" default:"10m" description:"How long a stream-in's Idempotency-Key is remembered, so that retries with the same key are not applied again."`
+
+	StreamInConcurrency int `long:"stream-in-concurrency" default:"1" description:"Number of files written at once when streaming into a volume. 1 extracts with tar. On Linux only privileged volumes are extracted concurrently; unprivileged ones always go through tar in their user namespace."`
+
+	UnprivilegedUIDBase int `long:"unprivileged-uid-base" default:"0" description:"First host UID that the UIDs of unprivileged volumes are shifted into. See --unprivileged-id-range."`
+	UnprivilegedGIDBase int `long:"unprivileged-gid-base" default:"0" description:"First host GID that the GIDs of unprivileged volumes are shifted into. See --unprivileged-id-range."`
+	UnprivilegedIDRange int `long:"unprivileged-id-range" default:"0" description:"Number of IDs of unprivileged volumes shifted into the host IDs from --unprivileged-uid-base and --unprivileged-gid-base, e.g. a rootless worker's subordinate ID range: a file owned by UID n in a stream-in, or in the parent of a copy-on-write volume copied by the naive driver, is owned by the UID base plus n on the host. The bases must be at least the range. 0 only shifts root, to the highest valid ID. Privileged volumes are never shifted."`
+
+	UnprivilegedExtractionUmask os.FileMode `long:"unprivileged-extraction-umask" default:"0" base:"8" description:"Umask, in octal, that the modes of what is streamed into unprivileged volumes are masked with, e.g. 022 to keep them from being writable by anyone but their owner. 0 keeps the modes in the stream. Streams whose xattrs are restored keep their modes all the same. Linux only; privileged volumes are never masked."`
+
+	StreamBytesPerSecond int64 `long:"stream-bytes-per-second" default:"0" description:"Maximum rate in bytes per second at which each stream-in or stream-out is streamed. Requests can ask for a lower rate with the X-Stream-Bytes-Per-Second header. 0 streams as fast as possible."`
+
+	MaxStreamsIn       int           `long:"max-streams-in"       default:"0"  description:"Most stream-ins that run at once. Those beyond it wait for one to finish. 0 leaves them unbounded."`
+	MaxStreamsOut      int           `long:"max-streams-out"      default:"0"  description:"Most stream-outs and diffs that run at once, capped apart from stream-ins so that neither can hold up the other. Those beyond it wait for one to finish. 0 leaves them unbounded."`
+	StreamQueueTimeout time.Duration `long:"stream-queue-timeout" default:"1m" description:"How long a stream beyond --max-streams-in or --max-streams-out waits for one to finish before it is refused with 503. 0 refuses it straight away."`
+
+	COWCopyThreshold int64 `long:"cow-copy-threshold" default:"0" description:"Expected size in bytes at or above which a COW volume is created as a full copy of its parent. 0 disables the threshold; requests flagged as mutation-heavy are always copied."`
+
+	MaxTmpfsVolumeSize int64 `long:"max-tmpfs-volume-size" default:"0" description:"Largest size in bytes a volume created with the tmpfs strategy may be given. Its data is held in memory, up to its size. 0 disables the tmpfs strategy."`
+
+	URLFetchTimeout time.Duration `long:"url-fetch-timeout" default:"10m" description:"Maximum time to spend fetching the archive for a volume created with the url strategy. 0 leaves fetches unbounded."`
+
+	EncryptionMasterKeyFile string `long:"encryption-master-key-file" description:"Path to a file of at least 32 random bytes from which the keys of volumes created encrypted are derived. Encrypted volumes need the naive driver on a filesystem with encryption enabled, e.g. ext4 with the encrypt feature. Volumes can't be encrypted without it, and encrypted ones can't be unlocked after the host restarts."`
+
+	HandleGenerator string `long:"handle-generator" default:"uuid" choice:"uuid" choice:"monotonic" description:"How to generate the handles of volumes created without one. uuid generates random UUIDs; monotonic generates numbers that go up with each volume, after --handle-prefix."`
+	HandlePrefix    string `long:"handle-prefix"                                                   description:"Prefix of the handles generated by the monotonic handle generator."`
+
+	DestroyAuditLog string `long:"destroy-audit-log" description:"Path to a file to which a JSON line is appended for each destroyed volume, recording why it was destroyed."`
+
+	DeletedVolumeRetention time.Duration `long:"deleted-volume-retention" default:"0s" description:"How long destroyed volumes are kept, hidden but restorable with POST /volumes/:handle/restore, before the reaper purges them. Volumes destroyed under disk pressure are destroyed straight away. 0 destroys every volume straight away."`
+
+	LabelSchemas []LabelSchemaFlag `long:"label-schema" description:"Restrict the values of a volume property, as NAME=VALUE1,VALUE2 or NAME=/REGEXP/. Can be specified multiple times."`
+
+	MaxPropertyNameLength int `long:"max-property-name-length" default:"256"   description:"Longest name in bytes a volume property may be given. Names may only have letters, digits, '.', '_', '-', and ':'. 0 leaves their length unbounded."`
+	MaxPropertyValueSize  int `long:"max-property-value-size"  default:"65536" description:"Largest value in bytes a volume property may be given. 0 leaves it unbounded."`
+
+	IndexedProperties []string `long:"indexed-property" description:"Volume property to keep an index of, so that listing volumes by it does not load every volume. Can be specified multiple times."`
+
+	MinFreeInodes uint64 `long:"min-free-inodes" default:"0" description:"Refuse to create or stream into volumes while fewer inodes than this are free, and reap expired volumes without their grace period. 0 disables the check."`
+
+	HealthMinFreeBytes uint64 `long:"health-min-free-bytes" default:"0" description:"Report the server as degraded on GET /health while fewer bytes than this are free on the volumes filesystem. It is reported degraded once the filesystem is full regardless."`
+
+	ReapInterval    time.Duration `long:"reap-interval"     default:"10s" description:"Interval on which to reap expired volumes."`
+	ReapGracePeriod time.Duration `long:"reap-grace-period" default:"0s"  description:"How long an expired volume is kept pending destruction, during which setting a TTL rescues it."`
+	ReapBatchSize   int           `long:"reap-batch-size"   default:"0"   description:"Maximum number of volumes to destroy on each reap, leaving the rest for the next one. 0 destroys all of them. Volumes being streamed into or out of are left until a reap after their streams finish."`
+
+	ReapRetryInitialBackoff time.Duration `long:"reap-retry-initial-backoff" default:"10s" description:"How long to wait before retrying a failed destroy, doubling on each failure."`
+	ReapRetryMaxBackoff     time.Duration `long:"reap-retry-max-backoff"     default:"10m" description:"Maximum time to wait between retries of a failed destroy."`
+	ReapEscalateAfter       int           `long:"reap-escalate-after"        default:"10"  description:"Number of failed destroys of a volume after which it is logged as an error. 0 never escalates."`
+	ReapQuarantine          bool          `long:"reap-quarantine"                          description:"Stop retrying destroys of a volume once they have been escalated."`
+
+	EvictLowWatermark  uint64 `long:"evict-low-watermark"  default:"0" description:"Free bytes on the volumes filesystem below which the reaper evicts volumes, reaping expired ones without their grace period until --evict-high-watermark bytes are free. Volumes being streamed or leased are left alone. 0 disables eviction."`
+	EvictHighWatermark uint64 `long:"evict-high-watermark" default:"0" description:"Free bytes on the volumes filesystem at which the reaper stops evicting. Taken to be --evict-low-watermark when lower."`
+	EvictUnexpired     bool   `long:"evict-unexpired"                  description:"While evicting, also evict volumes that have yet to expire, least recently used first, once expired ones are gone. Volumes that never expire are left alone."`
 
 	Metrics struct {
 		YellerAPIKey      string `long:"yeller-api-key"     description:"Yeller API key. If specified, all errors logged will be emitted."`
@@ -55,18 +131,52 @@ func (cmd *BaggageclaimCommand) Runner(args []string) (ifrit.Runner, error) {
 
 	listenAddr := fmt.Sprintf("%s:%d", cmd.BindIP.IP(), cmd.BindPort)
 
+	tlsConfig, err := cmd.tlsConfig()
+	if err != nil {
+		logger.Error("failed-to-load-tls-config", err)
+		return nil, err
+	}
+
+	authToken, err := cmd.authToken()
+	if err != nil {
+		logger.Error("failed-to-load-auth-token", err)
+		return nil, err
+	}
+
+	if cmd.UnprivilegedExtractionUmask&^os.ModePerm != 0 {
+		err = fmt.Errorf("unprivileged extraction umask %o has more than permission bits", cmd.UnprivilegedExtractionUmask)
+		logger.Error("invalid-unprivileged-extraction-umask", err)
+		return nil, err
+	}
+
 	var privilegedNamespacer, unprivilegedNamespacer uidgid.Namespacer
 
+	unprivilegedIDs := uidgid.IDRange{
+		UIDBase: cmd.UnprivilegedUIDBase,
+		GIDBase: cmd.UnprivilegedGIDBase,
+		Size:    cmd.UnprivilegedIDRange,
+	}
+
 	if uidgid.Supported() {
+		err = unprivilegedIDs.Validate(uidgid.MustGetMaxValidUID(), uidgid.MustGetMaxValidGID())
+		if err != nil {
+			logger.Error("invalid-unprivileged-id-range", err)
+			return nil, err
+		}
+
 		privilegedNamespacer = &uidgid.UidNamespacer{
-			Translator: uidgid.NewTranslator(uidgid.NewPrivilegedMapper()),
+			Translator: uidgid.NewTranslator(uidgid.NewPrivilegedMapper(unprivilegedIDs)),
 			Logger:     logger.Session("uid-namespacer"),
 		}
 
 		unprivilegedNamespacer = &uidgid.UidNamespacer{
-			Translator: uidgid.NewTranslator(uidgid.NewUnprivilegedMapper()),
+			Translator: uidgid.NewTranslator(uidgid.NewUnprivilegedMapper(unprivilegedIDs)),
 			Logger:     logger.Session("uid-namespacer"),
 		}
+	} else if unprivilegedIDs.Size > 0 {
+		err = errors.New("unprivileged ID range requires user namespaces, which are not supported here")
+		logger.Error("invalid-unprivileged-id-range", err)
+		return nil, err
 	} else {
 		privilegedNamespacer = uidgid.NoopNamespacer{}
 		unprivilegedNamespacer = uidgid.NoopNamespacer{}
@@ -80,42 +190,156 @@ func (cmd *BaggageclaimCommand) Runner(args []string) (ifrit.Runner, error) {
 		return nil, err
 	}
 
-	filesystem, err := volume.NewFilesystem(driver, cmd.VolumesDir.Path())
+	tmpfsDriver, err := cmd.tmpfsDriver()
+	if err != nil {
+		logger.Error("failed-to-set-up-tmpfs-driver", err)
+		return nil, err
+	}
+
+	masterKey, err := cmd.masterKey()
+	if err != nil {
+		logger.Error("failed-to-load-master-key", err)
+		return nil, err
+	}
+
+	filesystem, err := volume.NewFilesystem(driver, cmd.VolumesDir.Path(), tmpfsDriver, masterKey)
 	if err != nil {
 		logger.Error("failed-to-initialize-filesystem", err)
 		return nil, err
 	}
 
+	clock := clock.NewClock()
+
+	handleGenerator, err := cmd.handleGenerator(clock)
+	if err != nil {
+		logger.Error("failed-to-set-up-handle-generator", err)
+		return nil, err
+	}
+
+	var destroyAuditLog volume.DestroyAuditLog = volume.NoopDestroyAuditLog{}
+	if cmd.DestroyAuditLog != "" {
+		destroyAuditLog, err = volume.NewFileDestroyAuditLog(cmd.DestroyAuditLog)
+		if err != nil {
+			logger.Error("failed-to-open-destroy-audit-log", err)
+			return nil, err
+		}
+	}
+
+	registry := metrics.NewRegistry()
+
+	events := volume.NewEventHub()
+
 	volumeRepo := volume.NewRepository(
 		logger.Session("repository"),
+		clock,
 		filesystem,
 		locker,
+		volume.NewPathLockManager(),
 		privilegedNamespacer,
 		unprivilegedNamespacer,
+		cmd.labelSchemas(),
+		cmd.StreamInIdempotencyWindow,
+		destroyAuditLog,
+		cmd.MinFreeInodes,
+		cmd.StreamInConcurrency,
+		cmd.IndexedProperties,
+		events,
+		cmd.propertyLimits(),
+		cmd.DeletedVolumeRetention,
+		cmd.streamLimits(),
+		cmd.UnprivilegedExtractionUmask,
 	)
 
+	volumeRepo = volume.NewInstrumentedRepository(volumeRepo, clock, registry)
+
+	morbidReality := reaper.NewReaper(clock, volumeRepo, cmd.ReapGracePeriod, reaper.RetryPolicy{
+		InitialBackoff: cmd.ReapRetryInitialBackoff,
+		MaxBackoff:     cmd.ReapRetryMaxBackoff,
+		EscalateAfter:  cmd.ReapEscalateAfter,
+		Quarantine:     cmd.ReapQuarantine,
+	}, cmd.ReapBatchSize, registry, reaper.EvictionPolicy{
+		LowWatermark:   cmd.EvictLowWatermark,
+		HighWatermark:  cmd.EvictHighWatermark,
+		EvictUnexpired: cmd.EvictUnexpired,
+	})
+
+	drainState := &api.DrainState{}
+
+	strategerizer := volume.NewStrategerizer(cmd.COWCopyThreshold, cmd.MaxTmpfsVolumeSize, cmd.URLFetchTimeout)
+
 	apiHandler, err := api.NewHandler(
 		logger.Session("api"),
-		volume.NewStrategerizer(),
+		strategerizer,
 		volumeRepo,
+		clock,
+		cmd.Driver,
+		cmd.BodyReadTimeout,
+		drainState,
+		morbidReality,
+		registry,
+		filesystem,
+		cmd.HealthMinFreeBytes,
+		events,
+		cmd.StreamBytesPerSecond,
+		handleGenerator,
 	)
 	if err != nil {
 		logger.Fatal("failed-to-create-handler", err)
 	}
 
-	clock := clock.NewClock()
-
-	morbidReality := reaper.NewReaper(clock, volumeRepo)
+	if authToken != "" {
+		apiHandler = api.NewTokenAuthHandler(logger.Session("token-auth"), apiHandler, authToken, cmd.AuthExemptHealth)
+	}
 
 	members := []grouper.Member{
-		{Name: "api", Runner: http_server.New(listenAddr, apiHandler)},
+		{Name: "api", Runner: api.NewServer(logger.Session("api-server"), listenAddr, apiHandler, cmd.ShutdownTimeout, tlsConfig)},
 		{Name: "reaper", Runner: reaper.NewRunner(logger, clock, cmd.ReapInterval, morbidReality.Reap)},
 	}
 
+	var grpcListenAddr string
+	if cmd.GRPCBindPort != 0 {
+		grpcListenAddr = fmt.Sprintf("%s:%d", cmd.BindIP.IP(), cmd.GRPCBindPort)
+
+		grpcVolumeServer := api.NewVolumeServer(
+			logger.Session("grpc-volume-server"),
+			strategerizer,
+			volumeRepo,
+			cmd.BodyReadTimeout,
+			drainState,
+			cmd.StreamBytesPerSecond,
+			handleGenerator,
+		)
+
+		members = append(members, grouper.Member{
+			Name:   "grpc",
+			Runner: api.NewGRPCServer(logger.Session("grpc-server"), grpcListenAddr, grpcVolumeServer, cmd.ShutdownTimeout, tlsConfig, authToken),
+		})
+	}
+
+	if cmd.ControlSocket != "" {
+		members = append(members, grouper.Member{
+			Name: "control",
+			Runner: api.NewControlServer(
+				logger.Session("control-server"),
+				cmd.ControlSocket,
+				volumeRepo,
+				drainState,
+				morbidReality.Reap,
+			),
+		})
+	}
+
 	return onReady(grouper.NewParallel(os.Interrupt, members), func() {
 		logger.Info("listening", lager.Data{
 			"addr": listenAddr,
+			"tls":  tlsConfig != nil,
+			"mtls": tlsConfig != nil && tlsConfig.ClientCAs != nil,
+			"auth": authToken != "",
 		})
+
+		if grpcListenAddr != "" {
+			logger.Info("listening-for-grpc", lager.Data{"addr": grpcListenAddr})
+		}
 	}), nil
 }
 
@@ -130,6 +354,30 @@ func (cmd *BaggageclaimCommand) constructLogger() (lager.Logger, *lager.Reconfig
 	return logger, reconfigurableSink
 }
 
+func (cmd *BaggageclaimCommand) labelSchemas() volume.LabelSchemas {
+	schemas := volume.LabelSchemas{}
+	for _, flag := range cmd.LabelSchemas {
+		schemas[flag.Name] = flag.Schema
+	}
+
+	return schemas
+}
+
+func (cmd *BaggageclaimCommand) propertyLimits() volume.PropertyLimits {
+	return volume.PropertyLimits{
+		MaxNameLength: cmd.MaxPropertyNameLength,
+		MaxValueSize:  cmd.MaxPropertyValueSize,
+	}
+}
+
+func (cmd *BaggageclaimCommand) streamLimits() volume.StreamLimits {
+	return volume.StreamLimits{
+		MaxStreamsIn:  cmd.MaxStreamsIn,
+		MaxStreamsOut: cmd.MaxStreamsOut,
+		QueueTimeout:  cmd.StreamQueueTimeout,
+	}
+}
+
 func onReady(runner ifrit.Runner, cb func()) ifrit.Runner {
 	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
 		process := ifrit.Background(runner)
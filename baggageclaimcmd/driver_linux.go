@@ -15,6 +15,10 @@ import (
 const btrfsFSType = 0x9123683e
 
 func (cmd *BaggageclaimCommand) driver(logger lager.Logger) (volume.Driver, error) {
+	if cmd.StatsCacheTTL < 0 {
+		return nil, fmt.Errorf("stats cache TTL may not be negative: %s", cmd.StatsCacheTTL)
+	}
+
 	var fsStat syscall.Statfs_t
 	err := syscall.Statfs(cmd.VolumesDir.Path(), &fsStat)
 	if err != nil {
@@ -26,14 +30,9 @@ func (cmd *BaggageclaimCommand) driver(logger lager.Logger) (volume.Driver, erro
 		return nil, fmt.Errorf("failed to check kernel version: %s", err)
 	}
 
-	if cmd.Driver == "detect" {
-		if fsStat.Type == btrfsFSType {
-			cmd.Driver = "btrfs"
-		} else if kernelSupportsOverlay {
-			cmd.Driver = "overlay"
-		} else {
-			cmd.Driver = "naive"
-		}
+	cmd.Driver, err = resolveDriver(cmd.Driver, fsStat.Type == btrfsFSType, kernelSupportsOverlay, cmd.BtrfsImage)
+	if err != nil {
+		return nil, err
 	}
 
 	volumesDir := cmd.VolumesDir.Path()
@@ -54,25 +53,62 @@ func (cmd *BaggageclaimCommand) driver(logger lager.Logger) (volume.Driver, erro
 		}
 	}
 
-	if cmd.Driver == "overlay" && !kernelSupportsOverlay {
-		return nil, errors.New("overlay driver requires kernel version >= 4.0.0")
-	}
-
 	logger.Info("using-driver", lager.Data{"driver": cmd.Driver})
 
 	var d volume.Driver
 	switch cmd.Driver {
 	case "overlay":
 		d = &driver.OverlayDriver{
-			OverlaysDir: cmd.OverlaysDir,
+			OverlaysDir:   cmd.OverlaysDir,
+			StatsCacheTTL: cmd.StatsCacheTTL,
 		}
 	case "btrfs":
-		d = driver.NewBtrFSDriver(logger.Session("driver"), cmd.BtrfsBin)
+		d = driver.NewBtrFSDriver(logger.Session("driver"), cmd.BtrfsBin, cmd.StatsCacheTTL)
 	case "naive":
-		d = &driver.NaiveDriver{}
+		d, err = cmd.naiveDriver()
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("unknown driver: %s", cmd.Driver)
 	}
 
 	return d, nil
 }
+
+// tmpfsDriver returns the driver for tmpfs volumes, or nil if they are
+// disabled.
+func (cmd *BaggageclaimCommand) tmpfsDriver() (volume.Driver, error) {
+	if cmd.MaxTmpfsVolumeSize <= 0 {
+		return nil, nil
+	}
+
+	return &driver.TmpfsDriver{StatsCacheTTL: cmd.StatsCacheTTL}, nil
+}
+
+// resolveDriver picks the driver to use when detecting, and otherwise checks
+// that the requested driver can work with the volumes filesystem and kernel.
+// The btrfs driver is only allowed on other filesystems when asked to create
+// a loopback image for it.
+func resolveDriver(requested string, onBtrfs bool, kernelSupportsOverlay bool, allowImage bool) (string, error) {
+	switch requested {
+	case "detect":
+		if onBtrfs {
+			return "btrfs", nil
+		} else if kernelSupportsOverlay {
+			return "overlay", nil
+		}
+
+		return "naive", nil
+	case "btrfs":
+		if !onBtrfs && !allowImage {
+			return "", errors.New("btrfs driver requires the volumes directory to be on a btrfs filesystem, unless --btrfs-image is set to create a loopback image there")
+		}
+	case "overlay":
+		if !kernelSupportsOverlay {
+			return "", errors.New("overlay driver requires kernel version >= 4.0.0")
+		}
+	}
+
+	return requested, nil
+}
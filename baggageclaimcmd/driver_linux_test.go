@@ -0,0 +1,54 @@
+package baggageclaimcmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("resolveDriver", func() {
+	Context("when detecting", func() {
+		It("uses btrfs on a btrfs filesystem", func() {
+			Expect(resolveDriver("detect", true, true, true)).To(Equal("btrfs"))
+		})
+
+		It("uses overlay on other filesystems when the kernel supports it", func() {
+			Expect(resolveDriver("detect", false, true, true)).To(Equal("overlay"))
+		})
+
+		It("falls back to naive", func() {
+			Expect(resolveDriver("detect", false, false, true)).To(Equal("naive"))
+		})
+	})
+
+	Context("when the naive driver is requested", func() {
+		It("uses it even on a btrfs filesystem", func() {
+			Expect(resolveDriver("naive", true, true, true)).To(Equal("naive"))
+		})
+	})
+
+	Context("when the btrfs driver is requested", func() {
+		It("uses it on a btrfs filesystem", func() {
+			Expect(resolveDriver("btrfs", true, false, false)).To(Equal("btrfs"))
+		})
+
+		It("uses it on other filesystems when asked to create a loopback image", func() {
+			Expect(resolveDriver("btrfs", false, false, true)).To(Equal("btrfs"))
+		})
+
+		It("fails on other filesystems by default", func() {
+			_, err := resolveDriver("btrfs", false, true, false)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the overlay driver is requested", func() {
+		It("uses it when the kernel supports it", func() {
+			Expect(resolveDriver("overlay", false, true, false)).To(Equal("overlay"))
+		})
+
+		It("fails when the kernel does not support it", func() {
+			_, err := resolveDriver("overlay", true, false, true)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
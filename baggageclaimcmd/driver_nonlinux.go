@@ -3,11 +3,29 @@
 package baggageclaimcmd
 
 import (
+	"errors"
+	"fmt"
+
 	"code.cloudfoundry.org/lager"
 	"github.com/concourse/baggageclaim/volume"
-	"github.com/concourse/baggageclaim/volume/driver"
 )
 
 func (cmd *BaggageclaimCommand) driver(logger lager.Logger) (volume.Driver, error) {
-	return &driver.NaiveDriver{}, nil
+	if cmd.Driver != "detect" && cmd.Driver != "naive" {
+		return nil, fmt.Errorf("%s driver is not supported on this platform", cmd.Driver)
+	}
+
+	cmd.Driver = "naive"
+
+	logger.Info("using-driver", lager.Data{"driver": cmd.Driver})
+
+	return cmd.naiveDriver()
+}
+
+func (cmd *BaggageclaimCommand) tmpfsDriver() (volume.Driver, error) {
+	if cmd.MaxTmpfsVolumeSize > 0 {
+		return nil, errors.New("tmpfs volumes are not supported on this platform")
+	}
+
+	return nil, nil
 }
@@ -0,0 +1,37 @@
+// +build !linux
+
+package baggageclaimcmd
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("driver", func() {
+	var cmd *BaggageclaimCommand
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{}
+	})
+
+	It("uses the naive driver when detecting", func() {
+		cmd.Driver = "detect"
+
+		d, err := cmd.driver(lagertest.NewTestLogger("test"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(BeAssignableToTypeOf(&driver.NaiveDriver{}))
+		Expect(cmd.Driver).To(Equal("naive"))
+	})
+
+	It("rejects drivers other than naive", func() {
+		for _, name := range []string{"btrfs", "overlay"} {
+			cmd.Driver = name
+
+			_, err := cmd.driver(lagertest.NewTestLogger("test"))
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
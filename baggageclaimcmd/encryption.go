@@ -0,0 +1,22 @@
+package baggageclaimcmd
+
+import (
+	"fmt"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// masterKey returns the key encrypted volumes' keys are derived from, or nil
+// if volumes may not be encrypted.
+func (cmd *BaggageclaimCommand) masterKey() (volume.MasterKey, error) {
+	if cmd.EncryptionMasterKeyFile == "" {
+		return nil, nil
+	}
+
+	key, err := volume.LoadMasterKey(cmd.EncryptionMasterKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption master key file: %s", err)
+	}
+
+	return key, nil
+}
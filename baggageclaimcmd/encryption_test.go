@@ -0,0 +1,60 @@
+package baggageclaimcmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+var _ = Describe("masterKey", func() {
+	var (
+		cmd    *BaggageclaimCommand
+		tmpDir string
+	)
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "master-key")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("has no key without a key file, so that volumes can't be encrypted", func() {
+		Expect(cmd.masterKey()).To(BeNil())
+	})
+
+	It("reads the key as it is", func() {
+		key := bytes.Repeat([]byte{0xab}, volume.MinMasterKeySize)
+
+		cmd.EncryptionMasterKeyFile = filepath.Join(tmpDir, "key")
+		Expect(ioutil.WriteFile(cmd.EncryptionMasterKeyFile, key, 0600)).To(Succeed())
+
+		Expect(cmd.masterKey()).To(Equal(volume.MasterKey(key)))
+	})
+
+	It("fails when the key is too short", func() {
+		cmd.EncryptionMasterKeyFile = filepath.Join(tmpDir, "key")
+		Expect(ioutil.WriteFile(cmd.EncryptionMasterKeyFile, []byte("short"), 0600)).To(Succeed())
+
+		_, err := cmd.masterKey()
+		Expect(err).To(MatchError(ContainSubstring(volume.ErrMasterKeyTooShort.Error())))
+	})
+
+	It("fails when the key file is missing", func() {
+		cmd.EncryptionMasterKeyFile = filepath.Join(tmpDir, "missing")
+
+		_, err := cmd.masterKey()
+		Expect(err).To(HaveOccurred())
+	})
+})
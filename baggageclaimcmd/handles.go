@@ -0,0 +1,32 @@
+package baggageclaimcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/clock"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+// handleGenerator returns what generates the handles of volumes created
+// without one.
+func (cmd *BaggageclaimCommand) handleGenerator(clock clock.Clock) (api.HandleGenerator, error) {
+	if strings.Contains(cmd.HandlePrefix, "/") {
+		return nil, fmt.Errorf("handle prefix may not contain slashes: %s", cmd.HandlePrefix)
+	}
+
+	switch cmd.HandleGenerator {
+	case "monotonic":
+		return api.NewMonotonicHandleGenerator(cmd.HandlePrefix, clock), nil
+
+	case "uuid", "":
+		if cmd.HandlePrefix != "" {
+			return nil, fmt.Errorf("handle prefix is only used by the monotonic handle generator")
+		}
+
+		return api.UUIDHandleGenerator{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown handle generator: %s", cmd.HandleGenerator)
+}
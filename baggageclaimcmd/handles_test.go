@@ -0,0 +1,53 @@
+package baggageclaimcmd
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/api"
+)
+
+var _ = Describe("handleGenerator", func() {
+	var (
+		cmd       *BaggageclaimCommand
+		fakeClock *fakeclock.FakeClock
+	)
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{HandleGenerator: "uuid"}
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+	})
+
+	It("generates UUIDs by default", func() {
+		generator, err := cmd.handleGenerator(fakeClock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generator).To(Equal(api.UUIDHandleGenerator{}))
+	})
+
+	It("generates prefixed numbers when asked for monotonic handles", func() {
+		cmd.HandleGenerator = "monotonic"
+		cmd.HandlePrefix = "some-prefix-"
+
+		generator, err := cmd.handleGenerator(fakeClock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generator.Generate()).To(Equal("some-prefix-123000000456"))
+	})
+
+	It("fails when given a prefix for UUIDs", func() {
+		cmd.HandlePrefix = "some-prefix-"
+
+		_, err := cmd.handleGenerator(fakeClock)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails when the prefix has a slash", func() {
+		cmd.HandleGenerator = "monotonic"
+		cmd.HandlePrefix = "some/prefix-"
+
+		_, err := cmd.handleGenerator(fakeClock)
+		Expect(err).To(HaveOccurred())
+	})
+})
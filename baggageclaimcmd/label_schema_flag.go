@@ -0,0 +1,42 @@
+package baggageclaimcmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// LabelSchemaFlag registers a schema for a property, either as a list of
+// allowed values (NAME=VALUE1,VALUE2) or as a pattern (NAME=/REGEXP/). The
+// pattern is anchored, so it must match all of a value, not just part of it.
+type LabelSchemaFlag struct {
+	Name   string
+	Schema volume.LabelSchema
+}
+
+func (f *LabelSchemaFlag) UnmarshalFlag(value string) error {
+	segs := strings.SplitN(value, "=", 2)
+	if len(segs) != 2 || segs[0] == "" || segs[1] == "" {
+		return fmt.Errorf("invalid label schema '%s': must be NAME=VALUE1,VALUE2 or NAME=/REGEXP/", value)
+	}
+
+	f.Name = segs[0]
+
+	definition := segs[1]
+	if len(definition) > 1 && strings.HasPrefix(definition, "/") && strings.HasSuffix(definition, "/") {
+		pattern, err := regexp.Compile(`^(?:` + definition[1:len(definition)-1] + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid label schema pattern for '%s': %s", f.Name, err)
+		}
+
+		f.Schema = volume.LabelSchema{Pattern: pattern}
+
+		return nil
+	}
+
+	f.Schema = volume.LabelSchema{Values: strings.Split(definition, ",")}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package baggageclaimcmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/baggageclaimcmd"
+)
+
+var _ = Describe("LabelSchemaFlag", func() {
+	var flag baggageclaimcmd.LabelSchemaFlag
+
+	BeforeEach(func() {
+		flag = baggageclaimcmd.LabelSchemaFlag{}
+	})
+
+	It("parses a list of allowed values", func() {
+		Expect(flag.UnmarshalFlag("size-class=small,medium,large")).To(Succeed())
+		Expect(flag.Name).To(Equal("size-class"))
+		Expect(flag.Schema.Values).To(Equal([]string{"small", "medium", "large"}))
+		Expect(flag.Schema.Pattern).To(BeNil())
+	})
+
+	It("parses a pattern", func() {
+		Expect(flag.UnmarshalFlag("team=/^[a-z-]+$/")).To(Succeed())
+		Expect(flag.Name).To(Equal("team"))
+		Expect(flag.Schema.Values).To(BeEmpty())
+		Expect(flag.Schema.Allows("some-team")).To(BeTrue())
+		Expect(flag.Schema.Allows("Some Team")).To(BeFalse())
+	})
+
+	It("only allows values the pattern matches all of", func() {
+		Expect(flag.UnmarshalFlag("team=/[a-z]+|ops/")).To(Succeed())
+		Expect(flag.Schema.Allows("some")).To(BeTrue())
+		Expect(flag.Schema.Allows("ops")).To(BeTrue())
+		Expect(flag.Schema.Allows("Some Team")).To(BeFalse())
+		Expect(flag.Schema.Allows("devops!")).To(BeFalse())
+	})
+
+	It("rejects a schema without values", func() {
+		Expect(flag.UnmarshalFlag("size-class=")).NotTo(Succeed())
+		Expect(flag.UnmarshalFlag("size-class")).NotTo(Succeed())
+	})
+
+	It("rejects an invalid pattern", func() {
+		Expect(flag.UnmarshalFlag("team=/[/")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,30 @@
+package baggageclaimcmd
+
+import (
+	"fmt"
+
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+// naiveDriver returns the naive driver, copying volumes and caching their
+// stats as tuned by the flags.
+func (cmd *BaggageclaimCommand) naiveDriver() (volume.Driver, error) {
+	if cmd.NaiveCopyBufferSize < 0 {
+		return nil, fmt.Errorf("naive copy buffer size may not be negative: %d", cmd.NaiveCopyBufferSize)
+	}
+
+	if cmd.NaiveCopyParallelism < 0 {
+		return nil, fmt.Errorf("naive copy parallelism may not be negative: %d", cmd.NaiveCopyParallelism)
+	}
+
+	if cmd.StatsCacheTTL < 0 {
+		return nil, fmt.Errorf("stats cache TTL may not be negative: %s", cmd.StatsCacheTTL)
+	}
+
+	return &driver.NaiveDriver{
+		CopyBufferSize:  cmd.NaiveCopyBufferSize,
+		CopyParallelism: cmd.NaiveCopyParallelism,
+		StatsCacheTTL:   cmd.StatsCacheTTL,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package baggageclaimcmd
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("naiveDriver", func() {
+	var cmd *BaggageclaimCommand
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{}
+	})
+
+	It("copies and caches stats as tuned by the flags", func() {
+		cmd.NaiveCopyBufferSize = 1024
+		cmd.NaiveCopyParallelism = 4
+		cmd.StatsCacheTTL = time.Minute
+
+		d, err := cmd.naiveDriver()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(&driver.NaiveDriver{CopyBufferSize: 1024, CopyParallelism: 4, StatsCacheTTL: time.Minute}))
+	})
+
+	It("fails when the buffer size is negative", func() {
+		cmd.NaiveCopyBufferSize = -1
+
+		_, err := cmd.naiveDriver()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails when the parallelism is negative", func() {
+		cmd.NaiveCopyParallelism = -1
+
+		_, err := cmd.naiveDriver()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails when the stats cache TTL is negative", func() {
+		cmd.StatsCacheTTL = -time.Second
+
+		_, err := cmd.naiveDriver()
+		Expect(err).To(HaveOccurred())
+	})
+})
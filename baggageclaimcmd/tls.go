@@ -0,0 +1,54 @@
+package baggageclaimcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// tlsConfig returns the config to serve the API over HTTPS with, or nil to
+// serve it over plain HTTP. With a client CA, connections from clients
+// without a certificate it signed fail their handshake, so their requests
+// never reach the handlers.
+func (cmd *BaggageclaimCommand) tlsConfig() (*tls.Config, error) {
+	if cmd.TLSCert == "" && cmd.TLSKey == "" {
+		if cmd.TLSClientCA != "" {
+			return nil, errors.New("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+
+		return nil, nil
+	}
+
+	if cmd.TLSCert == "" || cmd.TLSKey == "" {
+		return nil, errors.New("--tls-cert and --tls-key must be given together")
+	}
+
+	cert, err := tls.LoadX509KeyPair(cmd.TLSCert, cmd.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tls key pair: %s", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cmd.TLSClientCA != "" {
+		caPEM, err := ioutil.ReadFile(cmd.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls client ca: %s", err)
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in tls client ca: %s", cmd.TLSClientCA)
+		}
+
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
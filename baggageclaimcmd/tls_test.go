@@ -0,0 +1,57 @@
+package baggageclaimcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tlsConfig", func() {
+	var (
+		cmd    *BaggageclaimCommand
+		tmpDir string
+	)
+
+	BeforeEach(func() {
+		cmd = &BaggageclaimCommand{}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "tls-config")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("serves plain HTTP without a certificate", func() {
+		config, err := cmd.tlsConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(BeNil())
+	})
+
+	It("requires the certificate and key together", func() {
+		cmd.TLSCert = "some-cert"
+
+		_, err := cmd.tlsConfig()
+		Expect(err).To(MatchError("--tls-cert and --tls-key must be given together"))
+	})
+
+	It("requires a certificate to verify clients against the CA", func() {
+		cmd.TLSClientCA = "some-ca"
+
+		_, err := cmd.tlsConfig()
+		Expect(err).To(MatchError("--tls-client-ca requires --tls-cert and --tls-key"))
+	})
+
+	It("fails when the key pair can't be loaded", func() {
+		cmd.TLSCert = filepath.Join(tmpDir, "missing.crt")
+		cmd.TLSKey = filepath.Join(tmpDir, "missing.key")
+
+		_, err := cmd.tlsConfig()
+		Expect(err).To(HaveOccurred())
+	})
+})
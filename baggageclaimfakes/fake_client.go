@@ -24,6 +24,64 @@ type FakeClient struct {
 		result1 baggageclaim.Volume
 		result2 error
 	}
+	CloneVolumeStub        func(lager.Logger, string, string) (baggageclaim.Volume, error)
+	cloneVolumeMutex       sync.RWMutex
+	cloneVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	cloneVolumeReturns struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	cloneVolumeReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	RenameVolumeStub        func(lager.Logger, string, string) (baggageclaim.Volume, error)
+	renameVolumeMutex       sync.RWMutex
+	renameVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	renameVolumeReturns struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	renameVolumeReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	PromoteVolumeStub        func(lager.Logger, string) (baggageclaim.Volume, error)
+	promoteVolumeMutex       sync.RWMutex
+	promoteVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	promoteVolumeReturns struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	promoteVolumeReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	RestoreVolumeStub        func(lager.Logger, string) (baggageclaim.Volume, error)
+	restoreVolumeMutex       sync.RWMutex
+	restoreVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	restoreVolumeReturns struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
+	restoreVolumeReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}
 	ListVolumesStub        func(lager.Logger, baggageclaim.VolumeProperties) (baggageclaim.Volumes, error)
 	listVolumesMutex       sync.RWMutex
 	listVolumesArgsForCall []struct {
@@ -38,6 +96,37 @@ type FakeClient struct {
 		result1 baggageclaim.Volumes
 		result2 error
 	}
+	ListVolumesPageStub        func(lager.Logger, baggageclaim.VolumeProperties, baggageclaim.ListVolumesOptions) (baggageclaim.Volumes, int, error)
+	listVolumesPageMutex       sync.RWMutex
+	listVolumesPageArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+		arg3 baggageclaim.ListVolumesOptions
+	}
+	listVolumesPageReturns struct {
+		result1 baggageclaim.Volumes
+		result2 int
+		result3 error
+	}
+	listVolumesPageReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volumes
+		result2 int
+		result3 error
+	}
+	ListDeletedVolumesStub        func(lager.Logger, baggageclaim.VolumeProperties) ([]baggageclaim.VolumeResponse, error)
+	listDeletedVolumesMutex       sync.RWMutex
+	listDeletedVolumesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+	}
+	listDeletedVolumesReturns struct {
+		result1 []baggageclaim.VolumeResponse
+		result2 error
+	}
+	listDeletedVolumesReturnsOnCall map[int]struct {
+		result1 []baggageclaim.VolumeResponse
+		result2 error
+	}
 	LookupVolumeStub        func(lager.Logger, string) (baggageclaim.Volume, bool, error)
 	lookupVolumeMutex       sync.RWMutex
 	lookupVolumeArgsForCall []struct {
@@ -54,6 +143,64 @@ type FakeClient struct {
 		result2 bool
 		result3 error
 	}
+	VolumeExistsStub        func(lager.Logger, string) (bool, error)
+	volumeExistsMutex       sync.RWMutex
+	volumeExistsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	volumeExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+	volumeExistsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	TotalUsageStub        func(lager.Logger, string) (baggageclaim.UsageResponse, error)
+	totalUsageMutex       sync.RWMutex
+	totalUsageArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	totalUsageReturns struct {
+		result1 baggageclaim.UsageResponse
+		result2 error
+	}
+	totalUsageReturnsOnCall map[int]struct {
+		result1 baggageclaim.UsageResponse
+		result2 error
+	}
+	DestroyVolumesStub        func(lager.Logger, []string) (map[string]error, error)
+	destroyVolumesMutex       sync.RWMutex
+	destroyVolumesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []string
+	}
+	destroyVolumesReturns struct {
+		result1 map[string]error
+		result2 error
+	}
+	destroyVolumesReturnsOnCall map[int]struct {
+		result1 map[string]error
+		result2 error
+	}
+	DestroyVolumesWithPropertiesStub        func(lager.Logger, baggageclaim.VolumeProperties) (int, map[string]error, error)
+	destroyVolumesWithPropertiesMutex       sync.RWMutex
+	destroyVolumesWithPropertiesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+	}
+	destroyVolumesWithPropertiesReturns struct {
+		result1 int
+		result2 map[string]error
+		result3 error
+	}
+	destroyVolumesWithPropertiesReturnsOnCall map[int]struct {
+		result1 int
+		result2 map[string]error
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -111,6 +258,216 @@ func (fake *FakeClient) CreateVolumeReturnsOnCall(i int, result1 baggageclaim.Vo
 	}{result1, result2}
 }
 
+func (fake *FakeClient) CloneVolume(arg1 lager.Logger, arg2 string, arg3 string) (baggageclaim.Volume, error) {
+	fake.cloneVolumeMutex.Lock()
+	ret, specificReturn := fake.cloneVolumeReturnsOnCall[len(fake.cloneVolumeArgsForCall)]
+	fake.cloneVolumeArgsForCall = append(fake.cloneVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("CloneVolume", []interface{}{arg1, arg2, arg3})
+	fake.cloneVolumeMutex.Unlock()
+	if fake.CloneVolumeStub != nil {
+		return fake.CloneVolumeStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.cloneVolumeReturns.result1, fake.cloneVolumeReturns.result2
+}
+
+func (fake *FakeClient) CloneVolumeCallCount() int {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return len(fake.cloneVolumeArgsForCall)
+}
+
+func (fake *FakeClient) CloneVolumeArgsForCall(i int) (lager.Logger, string, string) {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return fake.cloneVolumeArgsForCall[i].arg1, fake.cloneVolumeArgsForCall[i].arg2, fake.cloneVolumeArgsForCall[i].arg3
+}
+
+func (fake *FakeClient) CloneVolumeReturns(result1 baggageclaim.Volume, result2 error) {
+	fake.CloneVolumeStub = nil
+	fake.cloneVolumeReturns = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CloneVolumeReturnsOnCall(i int, result1 baggageclaim.Volume, result2 error) {
+	fake.CloneVolumeStub = nil
+	if fake.cloneVolumeReturnsOnCall == nil {
+		fake.cloneVolumeReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volume
+			result2 error
+		})
+	}
+	fake.cloneVolumeReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RenameVolume(arg1 lager.Logger, arg2 string, arg3 string) (baggageclaim.Volume, error) {
+	fake.renameVolumeMutex.Lock()
+	ret, specificReturn := fake.renameVolumeReturnsOnCall[len(fake.renameVolumeArgsForCall)]
+	fake.renameVolumeArgsForCall = append(fake.renameVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("RenameVolume", []interface{}{arg1, arg2, arg3})
+	fake.renameVolumeMutex.Unlock()
+	if fake.RenameVolumeStub != nil {
+		return fake.RenameVolumeStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.renameVolumeReturns.result1, fake.renameVolumeReturns.result2
+}
+
+func (fake *FakeClient) RenameVolumeCallCount() int {
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	return len(fake.renameVolumeArgsForCall)
+}
+
+func (fake *FakeClient) RenameVolumeArgsForCall(i int) (lager.Logger, string, string) {
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	return fake.renameVolumeArgsForCall[i].arg1, fake.renameVolumeArgsForCall[i].arg2, fake.renameVolumeArgsForCall[i].arg3
+}
+
+func (fake *FakeClient) RenameVolumeReturns(result1 baggageclaim.Volume, result2 error) {
+	fake.RenameVolumeStub = nil
+	fake.renameVolumeReturns = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RenameVolumeReturnsOnCall(i int, result1 baggageclaim.Volume, result2 error) {
+	fake.RenameVolumeStub = nil
+	if fake.renameVolumeReturnsOnCall == nil {
+		fake.renameVolumeReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volume
+			result2 error
+		})
+	}
+	fake.renameVolumeReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) PromoteVolume(arg1 lager.Logger, arg2 string) (baggageclaim.Volume, error) {
+	fake.promoteVolumeMutex.Lock()
+	ret, specificReturn := fake.promoteVolumeReturnsOnCall[len(fake.promoteVolumeArgsForCall)]
+	fake.promoteVolumeArgsForCall = append(fake.promoteVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("PromoteVolume", []interface{}{arg1, arg2})
+	fake.promoteVolumeMutex.Unlock()
+	if fake.PromoteVolumeStub != nil {
+		return fake.PromoteVolumeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.promoteVolumeReturns.result1, fake.promoteVolumeReturns.result2
+}
+
+func (fake *FakeClient) PromoteVolumeCallCount() int {
+	fake.promoteVolumeMutex.RLock()
+	defer fake.promoteVolumeMutex.RUnlock()
+	return len(fake.promoteVolumeArgsForCall)
+}
+
+func (fake *FakeClient) PromoteVolumeArgsForCall(i int) (lager.Logger, string) {
+	fake.promoteVolumeMutex.RLock()
+	defer fake.promoteVolumeMutex.RUnlock()
+	return fake.promoteVolumeArgsForCall[i].arg1, fake.promoteVolumeArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) PromoteVolumeReturns(result1 baggageclaim.Volume, result2 error) {
+	fake.PromoteVolumeStub = nil
+	fake.promoteVolumeReturns = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) PromoteVolumeReturnsOnCall(i int, result1 baggageclaim.Volume, result2 error) {
+	fake.PromoteVolumeStub = nil
+	if fake.promoteVolumeReturnsOnCall == nil {
+		fake.promoteVolumeReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volume
+			result2 error
+		})
+	}
+	fake.promoteVolumeReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RestoreVolume(arg1 lager.Logger, arg2 string) (baggageclaim.Volume, error) {
+	fake.restoreVolumeMutex.Lock()
+	ret, specificReturn := fake.restoreVolumeReturnsOnCall[len(fake.restoreVolumeArgsForCall)]
+	fake.restoreVolumeArgsForCall = append(fake.restoreVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("RestoreVolume", []interface{}{arg1, arg2})
+	fake.restoreVolumeMutex.Unlock()
+	if fake.RestoreVolumeStub != nil {
+		return fake.RestoreVolumeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.restoreVolumeReturns.result1, fake.restoreVolumeReturns.result2
+}
+
+func (fake *FakeClient) RestoreVolumeCallCount() int {
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
+	return len(fake.restoreVolumeArgsForCall)
+}
+
+func (fake *FakeClient) RestoreVolumeArgsForCall(i int) (lager.Logger, string) {
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
+	return fake.restoreVolumeArgsForCall[i].arg1, fake.restoreVolumeArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) RestoreVolumeReturns(result1 baggageclaim.Volume, result2 error) {
+	fake.RestoreVolumeStub = nil
+	fake.restoreVolumeReturns = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RestoreVolumeReturnsOnCall(i int, result1 baggageclaim.Volume, result2 error) {
+	fake.RestoreVolumeStub = nil
+	if fake.restoreVolumeReturnsOnCall == nil {
+		fake.restoreVolumeReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volume
+			result2 error
+		})
+	}
+	fake.restoreVolumeReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) ListVolumes(arg1 lager.Logger, arg2 baggageclaim.VolumeProperties) (baggageclaim.Volumes, error) {
 	fake.listVolumesMutex.Lock()
 	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
@@ -163,6 +520,114 @@ func (fake *FakeClient) ListVolumesReturnsOnCall(i int, result1 baggageclaim.Vol
 	}{result1, result2}
 }
 
+func (fake *FakeClient) ListVolumesPage(arg1 lager.Logger, arg2 baggageclaim.VolumeProperties, arg3 baggageclaim.ListVolumesOptions) (baggageclaim.Volumes, int, error) {
+	fake.listVolumesPageMutex.Lock()
+	ret, specificReturn := fake.listVolumesPageReturnsOnCall[len(fake.listVolumesPageArgsForCall)]
+	fake.listVolumesPageArgsForCall = append(fake.listVolumesPageArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+		arg3 baggageclaim.ListVolumesOptions
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ListVolumesPage", []interface{}{arg1, arg2, arg3})
+	fake.listVolumesPageMutex.Unlock()
+	if fake.ListVolumesPageStub != nil {
+		return fake.ListVolumesPageStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.listVolumesPageReturns.result1, fake.listVolumesPageReturns.result2, fake.listVolumesPageReturns.result3
+}
+
+func (fake *FakeClient) ListVolumesPageCallCount() int {
+	fake.listVolumesPageMutex.RLock()
+	defer fake.listVolumesPageMutex.RUnlock()
+	return len(fake.listVolumesPageArgsForCall)
+}
+
+func (fake *FakeClient) ListVolumesPageArgsForCall(i int) (lager.Logger, baggageclaim.VolumeProperties, baggageclaim.ListVolumesOptions) {
+	fake.listVolumesPageMutex.RLock()
+	defer fake.listVolumesPageMutex.RUnlock()
+	return fake.listVolumesPageArgsForCall[i].arg1, fake.listVolumesPageArgsForCall[i].arg2, fake.listVolumesPageArgsForCall[i].arg3
+}
+
+func (fake *FakeClient) ListVolumesPageReturns(result1 baggageclaim.Volumes, result2 int, result3 error) {
+	fake.ListVolumesPageStub = nil
+	fake.listVolumesPageReturns = struct {
+		result1 baggageclaim.Volumes
+		result2 int
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) ListVolumesPageReturnsOnCall(i int, result1 baggageclaim.Volumes, result2 int, result3 error) {
+	fake.ListVolumesPageStub = nil
+	if fake.listVolumesPageReturnsOnCall == nil {
+		fake.listVolumesPageReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volumes
+			result2 int
+			result3 error
+		})
+	}
+	fake.listVolumesPageReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volumes
+		result2 int
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) ListDeletedVolumes(arg1 lager.Logger, arg2 baggageclaim.VolumeProperties) ([]baggageclaim.VolumeResponse, error) {
+	fake.listDeletedVolumesMutex.Lock()
+	ret, specificReturn := fake.listDeletedVolumesReturnsOnCall[len(fake.listDeletedVolumesArgsForCall)]
+	fake.listDeletedVolumesArgsForCall = append(fake.listDeletedVolumesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+	}{arg1, arg2})
+	fake.recordInvocation("ListDeletedVolumes", []interface{}{arg1, arg2})
+	fake.listDeletedVolumesMutex.Unlock()
+	if fake.ListDeletedVolumesStub != nil {
+		return fake.ListDeletedVolumesStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listDeletedVolumesReturns.result1, fake.listDeletedVolumesReturns.result2
+}
+
+func (fake *FakeClient) ListDeletedVolumesCallCount() int {
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	return len(fake.listDeletedVolumesArgsForCall)
+}
+
+func (fake *FakeClient) ListDeletedVolumesArgsForCall(i int) (lager.Logger, baggageclaim.VolumeProperties) {
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	return fake.listDeletedVolumesArgsForCall[i].arg1, fake.listDeletedVolumesArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) ListDeletedVolumesReturns(result1 []baggageclaim.VolumeResponse, result2 error) {
+	fake.ListDeletedVolumesStub = nil
+	fake.listDeletedVolumesReturns = struct {
+		result1 []baggageclaim.VolumeResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListDeletedVolumesReturnsOnCall(i int, result1 []baggageclaim.VolumeResponse, result2 error) {
+	fake.ListDeletedVolumesStub = nil
+	if fake.listDeletedVolumesReturnsOnCall == nil {
+		fake.listDeletedVolumesReturnsOnCall = make(map[int]struct {
+			result1 []baggageclaim.VolumeResponse
+			result2 error
+		})
+	}
+	fake.listDeletedVolumesReturnsOnCall[i] = struct {
+		result1 []baggageclaim.VolumeResponse
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) LookupVolume(arg1 lager.Logger, arg2 string) (baggageclaim.Volume, bool, error) {
 	fake.lookupVolumeMutex.Lock()
 	ret, specificReturn := fake.lookupVolumeReturnsOnCall[len(fake.lookupVolumeArgsForCall)]
@@ -218,15 +683,251 @@ func (fake *FakeClient) LookupVolumeReturnsOnCall(i int, result1 baggageclaim.Vo
 	}{result1, result2, result3}
 }
 
+func (fake *FakeClient) VolumeExists(arg1 lager.Logger, arg2 string) (bool, error) {
+	fake.volumeExistsMutex.Lock()
+	ret, specificReturn := fake.volumeExistsReturnsOnCall[len(fake.volumeExistsArgsForCall)]
+	fake.volumeExistsArgsForCall = append(fake.volumeExistsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("VolumeExists", []interface{}{arg1, arg2})
+	fake.volumeExistsMutex.Unlock()
+	if fake.VolumeExistsStub != nil {
+		return fake.VolumeExistsStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.volumeExistsReturns.result1, fake.volumeExistsReturns.result2
+}
+
+func (fake *FakeClient) VolumeExistsCallCount() int {
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
+	return len(fake.volumeExistsArgsForCall)
+}
+
+func (fake *FakeClient) VolumeExistsArgsForCall(i int) (lager.Logger, string) {
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
+	return fake.volumeExistsArgsForCall[i].arg1, fake.volumeExistsArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) VolumeExistsReturns(result1 bool, result2 error) {
+	fake.VolumeExistsStub = nil
+	fake.volumeExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) VolumeExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.VolumeExistsStub = nil
+	if fake.volumeExistsReturnsOnCall == nil {
+		fake.volumeExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.volumeExistsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TotalUsage(arg1 lager.Logger, arg2 string) (baggageclaim.UsageResponse, error) {
+	fake.totalUsageMutex.Lock()
+	ret, specificReturn := fake.totalUsageReturnsOnCall[len(fake.totalUsageArgsForCall)]
+	fake.totalUsageArgsForCall = append(fake.totalUsageArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("TotalUsage", []interface{}{arg1, arg2})
+	fake.totalUsageMutex.Unlock()
+	if fake.TotalUsageStub != nil {
+		return fake.TotalUsageStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.totalUsageReturns.result1, fake.totalUsageReturns.result2
+}
+
+func (fake *FakeClient) TotalUsageCallCount() int {
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
+	return len(fake.totalUsageArgsForCall)
+}
+
+func (fake *FakeClient) TotalUsageArgsForCall(i int) (lager.Logger, string) {
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
+	return fake.totalUsageArgsForCall[i].arg1, fake.totalUsageArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) TotalUsageReturns(result1 baggageclaim.UsageResponse, result2 error) {
+	fake.TotalUsageStub = nil
+	fake.totalUsageReturns = struct {
+		result1 baggageclaim.UsageResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TotalUsageReturnsOnCall(i int, result1 baggageclaim.UsageResponse, result2 error) {
+	fake.TotalUsageStub = nil
+	if fake.totalUsageReturnsOnCall == nil {
+		fake.totalUsageReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.UsageResponse
+			result2 error
+		})
+	}
+	fake.totalUsageReturnsOnCall[i] = struct {
+		result1 baggageclaim.UsageResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) DestroyVolumes(arg1 lager.Logger, arg2 []string) (map[string]error, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.destroyVolumesMutex.Lock()
+	ret, specificReturn := fake.destroyVolumesReturnsOnCall[len(fake.destroyVolumesArgsForCall)]
+	fake.destroyVolumesArgsForCall = append(fake.destroyVolumesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []string
+	}{arg1, arg2Copy})
+	fake.recordInvocation("DestroyVolumes", []interface{}{arg1, arg2Copy})
+	fake.destroyVolumesMutex.Unlock()
+	if fake.DestroyVolumesStub != nil {
+		return fake.DestroyVolumesStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.destroyVolumesReturns.result1, fake.destroyVolumesReturns.result2
+}
+
+func (fake *FakeClient) DestroyVolumesCallCount() int {
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	return len(fake.destroyVolumesArgsForCall)
+}
+
+func (fake *FakeClient) DestroyVolumesArgsForCall(i int) (lager.Logger, []string) {
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	return fake.destroyVolumesArgsForCall[i].arg1, fake.destroyVolumesArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) DestroyVolumesReturns(result1 map[string]error, result2 error) {
+	fake.DestroyVolumesStub = nil
+	fake.destroyVolumesReturns = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) DestroyVolumesReturnsOnCall(i int, result1 map[string]error, result2 error) {
+	fake.DestroyVolumesStub = nil
+	if fake.destroyVolumesReturnsOnCall == nil {
+		fake.destroyVolumesReturnsOnCall = make(map[int]struct {
+			result1 map[string]error
+			result2 error
+		})
+	}
+	fake.destroyVolumesReturnsOnCall[i] = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) DestroyVolumesWithProperties(arg1 lager.Logger, arg2 baggageclaim.VolumeProperties) (int, map[string]error, error) {
+	fake.destroyVolumesWithPropertiesMutex.Lock()
+	ret, specificReturn := fake.destroyVolumesWithPropertiesReturnsOnCall[len(fake.destroyVolumesWithPropertiesArgsForCall)]
+	fake.destroyVolumesWithPropertiesArgsForCall = append(fake.destroyVolumesWithPropertiesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 baggageclaim.VolumeProperties
+	}{arg1, arg2})
+	fake.recordInvocation("DestroyVolumesWithProperties", []interface{}{arg1, arg2})
+	fake.destroyVolumesWithPropertiesMutex.Unlock()
+	if fake.DestroyVolumesWithPropertiesStub != nil {
+		return fake.DestroyVolumesWithPropertiesStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.destroyVolumesWithPropertiesReturns.result1, fake.destroyVolumesWithPropertiesReturns.result2, fake.destroyVolumesWithPropertiesReturns.result3
+}
+
+func (fake *FakeClient) DestroyVolumesWithPropertiesCallCount() int {
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
+	return len(fake.destroyVolumesWithPropertiesArgsForCall)
+}
+
+func (fake *FakeClient) DestroyVolumesWithPropertiesArgsForCall(i int) (lager.Logger, baggageclaim.VolumeProperties) {
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
+	return fake.destroyVolumesWithPropertiesArgsForCall[i].arg1, fake.destroyVolumesWithPropertiesArgsForCall[i].arg2
+}
+
+func (fake *FakeClient) DestroyVolumesWithPropertiesReturns(result1 int, result2 map[string]error, result3 error) {
+	fake.DestroyVolumesWithPropertiesStub = nil
+	fake.destroyVolumesWithPropertiesReturns = struct {
+		result1 int
+		result2 map[string]error
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) DestroyVolumesWithPropertiesReturnsOnCall(i int, result1 int, result2 map[string]error, result3 error) {
+	fake.DestroyVolumesWithPropertiesStub = nil
+	if fake.destroyVolumesWithPropertiesReturnsOnCall == nil {
+		fake.destroyVolumesWithPropertiesReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 map[string]error
+			result3 error
+		})
+	}
+	fake.destroyVolumesWithPropertiesReturnsOnCall[i] = struct {
+		result1 int
+		result2 map[string]error
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.createVolumeMutex.RLock()
 	defer fake.createVolumeMutex.RUnlock()
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	fake.promoteVolumeMutex.RLock()
+	defer fake.promoteVolumeMutex.RUnlock()
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
+	fake.listVolumesPageMutex.RLock()
+	defer fake.listVolumesPageMutex.RUnlock()
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
 	fake.lookupVolumeMutex.RLock()
 	defer fake.lookupVolumeMutex.RUnlock()
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -39,6 +39,17 @@ type FakeVolume struct {
 	setTTLReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetExpiresAtStub        func(time.Time) error
+	setExpiresAtMutex       sync.RWMutex
+	setExpiresAtArgsForCall []struct {
+		arg1 time.Time
+	}
+	setExpiresAtReturns struct {
+		result1 error
+	}
+	setExpiresAtReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetPropertyStub        func(key string, value string) error
 	setPropertyMutex       sync.RWMutex
 	setPropertyArgsForCall []struct {
@@ -51,6 +62,41 @@ type FakeVolume struct {
 	setPropertyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CompareAndSetPropertyStub        func(key string, expected *string, value string) error
+	compareAndSetPropertyMutex       sync.RWMutex
+	compareAndSetPropertyArgsForCall []struct {
+		key      string
+		expected *string
+		value    string
+	}
+	compareAndSetPropertyReturns struct {
+		result1 error
+	}
+	compareAndSetPropertyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetPropertiesStub        func(baggageclaim.VolumeProperties) error
+	setPropertiesMutex       sync.RWMutex
+	setPropertiesArgsForCall []struct {
+		arg1 baggageclaim.VolumeProperties
+	}
+	setPropertiesReturns struct {
+		result1 error
+	}
+	setPropertiesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeletePropertyStub        func(key string) error
+	deletePropertyMutex       sync.RWMutex
+	deletePropertyArgsForCall []struct {
+		key string
+	}
+	deletePropertyReturns struct {
+		result1 error
+	}
+	deletePropertyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetPrivilegedStub        func(bool) error
 	setPrivilegedMutex       sync.RWMutex
 	setPrivilegedArgsForCall []struct {
@@ -62,6 +108,17 @@ type FakeVolume struct {
 	setPrivilegedReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetSELinuxLabelStub        func(label string) error
+	setSELinuxLabelMutex       sync.RWMutex
+	setSELinuxLabelArgsForCall []struct {
+		label string
+	}
+	setSELinuxLabelReturns struct {
+		result1 error
+	}
+	setSELinuxLabelReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StreamInStub        func(path string, tarStream io.Reader) error
 	streamInMutex       sync.RWMutex
 	streamInArgsForCall []struct {
@@ -74,6 +131,19 @@ type FakeVolume struct {
 	streamInReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StreamInWithProgressStub        func(path string, tarStream io.Reader, progress baggageclaim.ProgressFunc) error
+	streamInWithProgressMutex       sync.RWMutex
+	streamInWithProgressArgsForCall []struct {
+		path      string
+		tarStream io.Reader
+		progress  baggageclaim.ProgressFunc
+	}
+	streamInWithProgressReturns struct {
+		result1 error
+	}
+	streamInWithProgressReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StreamOutStub        func(path string) (io.ReadCloser, error)
 	streamOutMutex       sync.RWMutex
 	streamOutArgsForCall []struct {
@@ -87,6 +157,167 @@ type FakeVolume struct {
 		result1 io.ReadCloser
 		result2 error
 	}
+	StreamOutSparseStub        func(string) (io.ReadCloser, error)
+	streamOutSparseMutex       sync.RWMutex
+	streamOutSparseArgsForCall []struct {
+		arg1 string
+	}
+	streamOutSparseReturns struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	streamOutSparseReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	StreamOutCanonicalStub        func(string) (io.ReadCloser, error)
+	streamOutCanonicalMutex       sync.RWMutex
+	streamOutCanonicalArgsForCall []struct {
+		arg1 string
+	}
+	streamOutCanonicalReturns struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	streamOutCanonicalReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	StreamOutFromStub        func(path string, offset int64) (io.ReadCloser, error)
+	streamOutFromMutex       sync.RWMutex
+	streamOutFromArgsForCall []struct {
+		path   string
+		offset int64
+	}
+	streamOutFromReturns struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	streamOutFromReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	ManifestStub        func(path string) ([]baggageclaim.ManifestEntry, error)
+	manifestMutex       sync.RWMutex
+	manifestArgsForCall []struct {
+		path string
+	}
+	manifestReturns struct {
+		result1 []baggageclaim.ManifestEntry
+		result2 error
+	}
+	manifestReturnsOnCall map[int]struct {
+		result1 []baggageclaim.ManifestEntry
+		result2 error
+	}
+	ListFilesStub        func(path string) ([]baggageclaim.FileEntry, error)
+	listFilesMutex       sync.RWMutex
+	listFilesArgsForCall []struct {
+		path string
+	}
+	listFilesReturns struct {
+		result1 []baggageclaim.FileEntry
+		result2 error
+	}
+	listFilesReturnsOnCall map[int]struct {
+		result1 []baggageclaim.FileEntry
+		result2 error
+	}
+	StreamInDeltaStub        func(path string, tarStream io.Reader) error
+	streamInDeltaMutex       sync.RWMutex
+	streamInDeltaArgsForCall []struct {
+		path      string
+		tarStream io.Reader
+	}
+	streamInDeltaReturns struct {
+		result1 error
+	}
+	streamInDeltaReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StreamInLayerStub        func(path string, layer io.Reader) error
+	streamInLayerMutex       sync.RWMutex
+	streamInLayerArgsForCall []struct {
+		path  string
+		layer io.Reader
+	}
+	streamInLayerReturns struct {
+		result1 error
+	}
+	streamInLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StreamInWithChecksumStub        func(string, io.Reader, string) error
+	streamInWithChecksumMutex       sync.RWMutex
+	streamInWithChecksumArgsForCall []struct {
+		arg1 string
+		arg2 io.Reader
+		arg3 string
+	}
+	streamInWithChecksumReturns struct {
+		result1 error
+	}
+	streamInWithChecksumReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CopyFromStub        func(string, string, string) error
+	copyFromMutex       sync.RWMutex
+	copyFromArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	copyFromReturns struct {
+		result1 error
+	}
+	copyFromReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StreamOutWithProgressStub        func(path string, progress baggageclaim.ProgressFunc) (io.ReadCloser, error)
+	streamOutWithProgressMutex       sync.RWMutex
+	streamOutWithProgressArgsForCall []struct {
+		path     string
+		progress baggageclaim.ProgressFunc
+	}
+	streamOutWithProgressReturns struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	streamOutWithProgressReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	TouchAccessStub        func() error
+	touchAccessMutex       sync.RWMutex
+	touchAccessArgsForCall []struct{}
+	touchAccessReturns     struct {
+		result1 error
+	}
+	touchAccessReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MaterializeStub        func() (bool, error)
+	materializeMutex       sync.RWMutex
+	materializeArgsForCall []struct{}
+	materializeReturns     struct {
+		result1 bool
+		result2 error
+	}
+	materializeReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	CommitStub        func(freeze bool) error
+	commitMutex       sync.RWMutex
+	commitArgsForCall []struct {
+		freeze bool
+	}
+	commitReturns struct {
+		result1 error
+	}
+	commitReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ExpirationStub        func() (time.Duration, time.Time, error)
 	expirationMutex       sync.RWMutex
 	expirationArgsForCall []struct{}
@@ -111,6 +342,21 @@ type FakeVolume struct {
 		result1 baggageclaim.VolumeProperties
 		result2 error
 	}
+	PropertyStub        func(key string) (string, bool, error)
+	propertyMutex       sync.RWMutex
+	propertyArgsForCall []struct {
+		key string
+	}
+	propertyReturns struct {
+		result1 string
+		result2 bool
+		result3 error
+	}
+	propertyReturnsOnCall map[int]struct {
+		result1 string
+		result2 bool
+		result3 error
+	}
 	ReleaseStub        func(*time.Duration)
 	releaseMutex       sync.RWMutex
 	releaseArgsForCall []struct {
@@ -127,6 +373,156 @@ type FakeVolume struct {
 		result1 int64
 		result2 error
 	}
+	QuotaInBytesStub        func() (int64, error)
+	quotaInBytesMutex       sync.RWMutex
+	quotaInBytesArgsForCall []struct{}
+	quotaInBytesReturns     struct {
+		result1 int64
+		result2 error
+	}
+	quotaInBytesReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	FileCountStub        func() (int64, error)
+	fileCountMutex       sync.RWMutex
+	fileCountArgsForCall []struct{}
+	fileCountReturns     struct {
+		result1 int64
+		result2 error
+	}
+	fileCountReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	StatsStub        func() (baggageclaim.VolumeStatsResponse, error)
+	statsMutex       sync.RWMutex
+	statsArgsForCall []struct{}
+	statsReturns     struct {
+		result1 baggageclaim.VolumeStatsResponse
+		result2 error
+	}
+	statsReturnsOnCall map[int]struct {
+		result1 baggageclaim.VolumeStatsResponse
+		result2 error
+	}
+	RecordedDigestStub        func() (string, error)
+	recordedDigestMutex       sync.RWMutex
+	recordedDigestArgsForCall []struct{}
+	recordedDigestReturns     struct {
+		result1 string
+		result2 error
+	}
+	recordedDigestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	DigestStub        func() (string, error)
+	digestMutex       sync.RWMutex
+	digestArgsForCall []struct{}
+	digestReturns     struct {
+		result1 string
+		result2 error
+	}
+	digestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ParentHandleStub        func() (string, error)
+	parentHandleMutex       sync.RWMutex
+	parentHandleArgsForCall []struct{}
+	parentHandleReturns     struct {
+		result1 string
+		result2 error
+	}
+	parentHandleReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ChildrenStub        func() ([]string, error)
+	childrenMutex       sync.RWMutex
+	childrenArgsForCall []struct{}
+	childrenReturns     struct {
+		result1 []string
+		result2 error
+	}
+	childrenReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	MountOptionsStub        func() ([]string, error)
+	mountOptionsMutex       sync.RWMutex
+	mountOptionsArgsForCall []struct{}
+	mountOptionsReturns     struct {
+		result1 []string
+		result2 error
+	}
+	mountOptionsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	EncryptedStub        func() (bool, error)
+	encryptedMutex       sync.RWMutex
+	encryptedArgsForCall []struct{}
+	encryptedReturns     struct {
+		result1 bool
+		result2 error
+	}
+	encryptedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	AcquireLeaseStub        func(ttl time.Duration) (string, time.Time, error)
+	acquireLeaseMutex       sync.RWMutex
+	acquireLeaseArgsForCall []struct {
+		ttl time.Duration
+	}
+	acquireLeaseReturns struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	acquireLeaseReturnsOnCall map[int]struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	RenewLeaseStub        func(token string, ttl time.Duration) (time.Time, error)
+	renewLeaseMutex       sync.RWMutex
+	renewLeaseArgsForCall []struct {
+		token string
+		ttl   time.Duration
+	}
+	renewLeaseReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	renewLeaseReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	ReleaseLeaseStub        func(token string) error
+	releaseLeaseMutex       sync.RWMutex
+	releaseLeaseArgsForCall []struct {
+		token string
+	}
+	releaseLeaseReturns struct {
+		result1 error
+	}
+	releaseLeaseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WithLeaseStub        func(token string) baggageclaim.Volume
+	withLeaseMutex       sync.RWMutex
+	withLeaseArgsForCall []struct {
+		token string
+	}
+	withLeaseReturns struct {
+		result1 baggageclaim.Volume
+	}
+	withLeaseReturnsOnCall map[int]struct {
+		result1 baggageclaim.Volume
+	}
 	DestroyStub        func() error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct{}
@@ -268,6 +664,54 @@ func (fake *FakeVolume) SetTTLReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeVolume) SetExpiresAt(arg1 time.Time) error {
+	fake.setExpiresAtMutex.Lock()
+	ret, specificReturn := fake.setExpiresAtReturnsOnCall[len(fake.setExpiresAtArgsForCall)]
+	fake.setExpiresAtArgsForCall = append(fake.setExpiresAtArgsForCall, struct {
+		arg1 time.Time
+	}{arg1})
+	fake.recordInvocation("SetExpiresAt", []interface{}{arg1})
+	fake.setExpiresAtMutex.Unlock()
+	if fake.SetExpiresAtStub != nil {
+		return fake.SetExpiresAtStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setExpiresAtReturns.result1
+}
+
+func (fake *FakeVolume) SetExpiresAtCallCount() int {
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
+	return len(fake.setExpiresAtArgsForCall)
+}
+
+func (fake *FakeVolume) SetExpiresAtArgsForCall(i int) time.Time {
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
+	return fake.setExpiresAtArgsForCall[i].arg1
+}
+
+func (fake *FakeVolume) SetExpiresAtReturns(result1 error) {
+	fake.SetExpiresAtStub = nil
+	fake.setExpiresAtReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) SetExpiresAtReturnsOnCall(i int, result1 error) {
+	fake.SetExpiresAtStub = nil
+	if fake.setExpiresAtReturnsOnCall == nil {
+		fake.setExpiresAtReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setExpiresAtReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeVolume) SetProperty(key string, value string) error {
 	fake.setPropertyMutex.Lock()
 	ret, specificReturn := fake.setPropertyReturnsOnCall[len(fake.setPropertyArgsForCall)]
@@ -317,92 +761,286 @@ func (fake *FakeVolume) SetPropertyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeVolume) SetPrivileged(arg1 bool) error {
-	fake.setPrivilegedMutex.Lock()
-	ret, specificReturn := fake.setPrivilegedReturnsOnCall[len(fake.setPrivilegedArgsForCall)]
-	fake.setPrivilegedArgsForCall = append(fake.setPrivilegedArgsForCall, struct {
-		arg1 bool
-	}{arg1})
-	fake.recordInvocation("SetPrivileged", []interface{}{arg1})
-	fake.setPrivilegedMutex.Unlock()
-	if fake.SetPrivilegedStub != nil {
-		return fake.SetPrivilegedStub(arg1)
+func (fake *FakeVolume) CompareAndSetProperty(key string, expected *string, value string) error {
+	fake.compareAndSetPropertyMutex.Lock()
+	ret, specificReturn := fake.compareAndSetPropertyReturnsOnCall[len(fake.compareAndSetPropertyArgsForCall)]
+	fake.compareAndSetPropertyArgsForCall = append(fake.compareAndSetPropertyArgsForCall, struct {
+		key      string
+		expected *string
+		value    string
+	}{key, expected, value})
+	fake.recordInvocation("CompareAndSetProperty", []interface{}{key, expected, value})
+	fake.compareAndSetPropertyMutex.Unlock()
+	if fake.CompareAndSetPropertyStub != nil {
+		return fake.CompareAndSetPropertyStub(key, expected, value)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.setPrivilegedReturns.result1
+	return fake.compareAndSetPropertyReturns.result1
 }
 
-func (fake *FakeVolume) SetPrivilegedCallCount() int {
-	fake.setPrivilegedMutex.RLock()
-	defer fake.setPrivilegedMutex.RUnlock()
-	return len(fake.setPrivilegedArgsForCall)
+func (fake *FakeVolume) CompareAndSetPropertyCallCount() int {
+	fake.compareAndSetPropertyMutex.RLock()
+	defer fake.compareAndSetPropertyMutex.RUnlock()
+	return len(fake.compareAndSetPropertyArgsForCall)
 }
 
-func (fake *FakeVolume) SetPrivilegedArgsForCall(i int) bool {
-	fake.setPrivilegedMutex.RLock()
-	defer fake.setPrivilegedMutex.RUnlock()
-	return fake.setPrivilegedArgsForCall[i].arg1
+func (fake *FakeVolume) CompareAndSetPropertyArgsForCall(i int) (string, *string, string) {
+	fake.compareAndSetPropertyMutex.RLock()
+	defer fake.compareAndSetPropertyMutex.RUnlock()
+	return fake.compareAndSetPropertyArgsForCall[i].key, fake.compareAndSetPropertyArgsForCall[i].expected, fake.compareAndSetPropertyArgsForCall[i].value
 }
 
-func (fake *FakeVolume) SetPrivilegedReturns(result1 error) {
-	fake.SetPrivilegedStub = nil
-	fake.setPrivilegedReturns = struct {
+func (fake *FakeVolume) CompareAndSetPropertyReturns(result1 error) {
+	fake.CompareAndSetPropertyStub = nil
+	fake.compareAndSetPropertyReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeVolume) SetPrivilegedReturnsOnCall(i int, result1 error) {
-	fake.SetPrivilegedStub = nil
-	if fake.setPrivilegedReturnsOnCall == nil {
-		fake.setPrivilegedReturnsOnCall = make(map[int]struct {
+func (fake *FakeVolume) CompareAndSetPropertyReturnsOnCall(i int, result1 error) {
+	fake.CompareAndSetPropertyStub = nil
+	if fake.compareAndSetPropertyReturnsOnCall == nil {
+		fake.compareAndSetPropertyReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.setPrivilegedReturnsOnCall[i] = struct {
+	fake.compareAndSetPropertyReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeVolume) StreamIn(path string, tarStream io.Reader) error {
-	fake.streamInMutex.Lock()
-	ret, specificReturn := fake.streamInReturnsOnCall[len(fake.streamInArgsForCall)]
-	fake.streamInArgsForCall = append(fake.streamInArgsForCall, struct {
-		path      string
-		tarStream io.Reader
-	}{path, tarStream})
-	fake.recordInvocation("StreamIn", []interface{}{path, tarStream})
-	fake.streamInMutex.Unlock()
-	if fake.StreamInStub != nil {
-		return fake.StreamInStub(path, tarStream)
+func (fake *FakeVolume) SetProperties(arg1 baggageclaim.VolumeProperties) error {
+	fake.setPropertiesMutex.Lock()
+	ret, specificReturn := fake.setPropertiesReturnsOnCall[len(fake.setPropertiesArgsForCall)]
+	fake.setPropertiesArgsForCall = append(fake.setPropertiesArgsForCall, struct {
+		arg1 baggageclaim.VolumeProperties
+	}{arg1})
+	fake.recordInvocation("SetProperties", []interface{}{arg1})
+	fake.setPropertiesMutex.Unlock()
+	if fake.SetPropertiesStub != nil {
+		return fake.SetPropertiesStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.streamInReturns.result1
+	return fake.setPropertiesReturns.result1
 }
 
-func (fake *FakeVolume) StreamInCallCount() int {
-	fake.streamInMutex.RLock()
-	defer fake.streamInMutex.RUnlock()
-	return len(fake.streamInArgsForCall)
+func (fake *FakeVolume) SetPropertiesCallCount() int {
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	return len(fake.setPropertiesArgsForCall)
 }
 
-func (fake *FakeVolume) StreamInArgsForCall(i int) (string, io.Reader) {
-	fake.streamInMutex.RLock()
-	defer fake.streamInMutex.RUnlock()
-	return fake.streamInArgsForCall[i].path, fake.streamInArgsForCall[i].tarStream
+func (fake *FakeVolume) SetPropertiesArgsForCall(i int) baggageclaim.VolumeProperties {
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	return fake.setPropertiesArgsForCall[i].arg1
 }
 
-func (fake *FakeVolume) StreamInReturns(result1 error) {
-	fake.StreamInStub = nil
-	fake.streamInReturns = struct {
+func (fake *FakeVolume) SetPropertiesReturns(result1 error) {
+	fake.SetPropertiesStub = nil
+	fake.setPropertiesReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeVolume) StreamInReturnsOnCall(i int, result1 error) {
+func (fake *FakeVolume) SetPropertiesReturnsOnCall(i int, result1 error) {
+	fake.SetPropertiesStub = nil
+	if fake.setPropertiesReturnsOnCall == nil {
+		fake.setPropertiesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPropertiesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) DeleteProperty(key string) error {
+	fake.deletePropertyMutex.Lock()
+	ret, specificReturn := fake.deletePropertyReturnsOnCall[len(fake.deletePropertyArgsForCall)]
+	fake.deletePropertyArgsForCall = append(fake.deletePropertyArgsForCall, struct {
+		key string
+	}{key})
+	fake.recordInvocation("DeleteProperty", []interface{}{key})
+	fake.deletePropertyMutex.Unlock()
+	if fake.DeletePropertyStub != nil {
+		return fake.DeletePropertyStub(key)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deletePropertyReturns.result1
+}
+
+func (fake *FakeVolume) DeletePropertyCallCount() int {
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
+	return len(fake.deletePropertyArgsForCall)
+}
+
+func (fake *FakeVolume) DeletePropertyArgsForCall(i int) string {
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
+	return fake.deletePropertyArgsForCall[i].key
+}
+
+func (fake *FakeVolume) DeletePropertyReturns(result1 error) {
+	fake.DeletePropertyStub = nil
+	fake.deletePropertyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) DeletePropertyReturnsOnCall(i int, result1 error) {
+	fake.DeletePropertyStub = nil
+	if fake.deletePropertyReturnsOnCall == nil {
+		fake.deletePropertyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deletePropertyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) SetPrivileged(arg1 bool) error {
+	fake.setPrivilegedMutex.Lock()
+	ret, specificReturn := fake.setPrivilegedReturnsOnCall[len(fake.setPrivilegedArgsForCall)]
+	fake.setPrivilegedArgsForCall = append(fake.setPrivilegedArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	fake.recordInvocation("SetPrivileged", []interface{}{arg1})
+	fake.setPrivilegedMutex.Unlock()
+	if fake.SetPrivilegedStub != nil {
+		return fake.SetPrivilegedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setPrivilegedReturns.result1
+}
+
+func (fake *FakeVolume) SetPrivilegedCallCount() int {
+	fake.setPrivilegedMutex.RLock()
+	defer fake.setPrivilegedMutex.RUnlock()
+	return len(fake.setPrivilegedArgsForCall)
+}
+
+func (fake *FakeVolume) SetPrivilegedArgsForCall(i int) bool {
+	fake.setPrivilegedMutex.RLock()
+	defer fake.setPrivilegedMutex.RUnlock()
+	return fake.setPrivilegedArgsForCall[i].arg1
+}
+
+func (fake *FakeVolume) SetPrivilegedReturns(result1 error) {
+	fake.SetPrivilegedStub = nil
+	fake.setPrivilegedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) SetPrivilegedReturnsOnCall(i int, result1 error) {
+	fake.SetPrivilegedStub = nil
+	if fake.setPrivilegedReturnsOnCall == nil {
+		fake.setPrivilegedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPrivilegedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) SetSELinuxLabel(label string) error {
+	fake.setSELinuxLabelMutex.Lock()
+	ret, specificReturn := fake.setSELinuxLabelReturnsOnCall[len(fake.setSELinuxLabelArgsForCall)]
+	fake.setSELinuxLabelArgsForCall = append(fake.setSELinuxLabelArgsForCall, struct {
+		label string
+	}{label})
+	fake.recordInvocation("SetSELinuxLabel", []interface{}{label})
+	fake.setSELinuxLabelMutex.Unlock()
+	if fake.SetSELinuxLabelStub != nil {
+		return fake.SetSELinuxLabelStub(label)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setSELinuxLabelReturns.result1
+}
+
+func (fake *FakeVolume) SetSELinuxLabelCallCount() int {
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
+	return len(fake.setSELinuxLabelArgsForCall)
+}
+
+func (fake *FakeVolume) SetSELinuxLabelArgsForCall(i int) string {
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
+	return fake.setSELinuxLabelArgsForCall[i].label
+}
+
+func (fake *FakeVolume) SetSELinuxLabelReturns(result1 error) {
+	fake.SetSELinuxLabelStub = nil
+	fake.setSELinuxLabelReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) SetSELinuxLabelReturnsOnCall(i int, result1 error) {
+	fake.SetSELinuxLabelStub = nil
+	if fake.setSELinuxLabelReturnsOnCall == nil {
+		fake.setSELinuxLabelReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setSELinuxLabelReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamIn(path string, tarStream io.Reader) error {
+	fake.streamInMutex.Lock()
+	ret, specificReturn := fake.streamInReturnsOnCall[len(fake.streamInArgsForCall)]
+	fake.streamInArgsForCall = append(fake.streamInArgsForCall, struct {
+		path      string
+		tarStream io.Reader
+	}{path, tarStream})
+	fake.recordInvocation("StreamIn", []interface{}{path, tarStream})
+	fake.streamInMutex.Unlock()
+	if fake.StreamInStub != nil {
+		return fake.StreamInStub(path, tarStream)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamInReturns.result1
+}
+
+func (fake *FakeVolume) StreamInCallCount() int {
+	fake.streamInMutex.RLock()
+	defer fake.streamInMutex.RUnlock()
+	return len(fake.streamInArgsForCall)
+}
+
+func (fake *FakeVolume) StreamInArgsForCall(i int) (string, io.Reader) {
+	fake.streamInMutex.RLock()
+	defer fake.streamInMutex.RUnlock()
+	return fake.streamInArgsForCall[i].path, fake.streamInArgsForCall[i].tarStream
+}
+
+func (fake *FakeVolume) StreamInReturns(result1 error) {
+	fake.StreamInStub = nil
+	fake.streamInReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInReturnsOnCall(i int, result1 error) {
 	fake.StreamInStub = nil
 	if fake.streamInReturnsOnCall == nil {
 		fake.streamInReturnsOnCall = make(map[int]struct {
@@ -414,6 +1052,56 @@ func (fake *FakeVolume) StreamInReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeVolume) StreamInWithProgress(path string, tarStream io.Reader, progress baggageclaim.ProgressFunc) error {
+	fake.streamInWithProgressMutex.Lock()
+	ret, specificReturn := fake.streamInWithProgressReturnsOnCall[len(fake.streamInWithProgressArgsForCall)]
+	fake.streamInWithProgressArgsForCall = append(fake.streamInWithProgressArgsForCall, struct {
+		path      string
+		tarStream io.Reader
+		progress  baggageclaim.ProgressFunc
+	}{path, tarStream, progress})
+	fake.recordInvocation("StreamInWithProgress", []interface{}{path, tarStream, progress})
+	fake.streamInWithProgressMutex.Unlock()
+	if fake.StreamInWithProgressStub != nil {
+		return fake.StreamInWithProgressStub(path, tarStream, progress)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamInWithProgressReturns.result1
+}
+
+func (fake *FakeVolume) StreamInWithProgressCallCount() int {
+	fake.streamInWithProgressMutex.RLock()
+	defer fake.streamInWithProgressMutex.RUnlock()
+	return len(fake.streamInWithProgressArgsForCall)
+}
+
+func (fake *FakeVolume) StreamInWithProgressArgsForCall(i int) (string, io.Reader, baggageclaim.ProgressFunc) {
+	fake.streamInWithProgressMutex.RLock()
+	defer fake.streamInWithProgressMutex.RUnlock()
+	return fake.streamInWithProgressArgsForCall[i].path, fake.streamInWithProgressArgsForCall[i].tarStream, fake.streamInWithProgressArgsForCall[i].progress
+}
+
+func (fake *FakeVolume) StreamInWithProgressReturns(result1 error) {
+	fake.StreamInWithProgressStub = nil
+	fake.streamInWithProgressReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInWithProgressReturnsOnCall(i int, result1 error) {
+	fake.StreamInWithProgressStub = nil
+	if fake.streamInWithProgressReturnsOnCall == nil {
+		fake.streamInWithProgressReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.streamInWithProgressReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeVolume) StreamOut(path string) (io.ReadCloser, error) {
 	fake.streamOutMutex.Lock()
 	ret, specificReturn := fake.streamOutReturnsOnCall[len(fake.streamOutArgsForCall)]
@@ -465,122 +1153,813 @@ func (fake *FakeVolume) StreamOutReturnsOnCall(i int, result1 io.ReadCloser, res
 	}{result1, result2}
 }
 
-func (fake *FakeVolume) Expiration() (time.Duration, time.Time, error) {
-	fake.expirationMutex.Lock()
-	ret, specificReturn := fake.expirationReturnsOnCall[len(fake.expirationArgsForCall)]
-	fake.expirationArgsForCall = append(fake.expirationArgsForCall, struct{}{})
-	fake.recordInvocation("Expiration", []interface{}{})
-	fake.expirationMutex.Unlock()
-	if fake.ExpirationStub != nil {
-		return fake.ExpirationStub()
+func (fake *FakeVolume) StreamOutSparse(arg1 string) (io.ReadCloser, error) {
+	fake.streamOutSparseMutex.Lock()
+	ret, specificReturn := fake.streamOutSparseReturnsOnCall[len(fake.streamOutSparseArgsForCall)]
+	fake.streamOutSparseArgsForCall = append(fake.streamOutSparseArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StreamOutSparse", []interface{}{arg1})
+	fake.streamOutSparseMutex.Unlock()
+	if fake.StreamOutSparseStub != nil {
+		return fake.StreamOutSparseStub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2, ret.result3
+		return ret.result1, ret.result2
 	}
-	return fake.expirationReturns.result1, fake.expirationReturns.result2, fake.expirationReturns.result3
+	return fake.streamOutSparseReturns.result1, fake.streamOutSparseReturns.result2
 }
 
-func (fake *FakeVolume) ExpirationCallCount() int {
-	fake.expirationMutex.RLock()
-	defer fake.expirationMutex.RUnlock()
-	return len(fake.expirationArgsForCall)
+func (fake *FakeVolume) StreamOutSparseCallCount() int {
+	fake.streamOutSparseMutex.RLock()
+	defer fake.streamOutSparseMutex.RUnlock()
+	return len(fake.streamOutSparseArgsForCall)
 }
 
-func (fake *FakeVolume) ExpirationReturns(result1 time.Duration, result2 time.Time, result3 error) {
-	fake.ExpirationStub = nil
-	fake.expirationReturns = struct {
-		result1 time.Duration
-		result2 time.Time
-		result3 error
-	}{result1, result2, result3}
+func (fake *FakeVolume) StreamOutSparseArgsForCall(i int) string {
+	fake.streamOutSparseMutex.RLock()
+	defer fake.streamOutSparseMutex.RUnlock()
+	return fake.streamOutSparseArgsForCall[i].arg1
 }
 
-func (fake *FakeVolume) ExpirationReturnsOnCall(i int, result1 time.Duration, result2 time.Time, result3 error) {
-	fake.ExpirationStub = nil
-	if fake.expirationReturnsOnCall == nil {
-		fake.expirationReturnsOnCall = make(map[int]struct {
-			result1 time.Duration
-			result2 time.Time
-			result3 error
+func (fake *FakeVolume) StreamOutSparseReturns(result1 io.ReadCloser, result2 error) {
+	fake.StreamOutSparseStub = nil
+	fake.streamOutSparseReturns = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) StreamOutSparseReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+	fake.StreamOutSparseStub = nil
+	if fake.streamOutSparseReturnsOnCall == nil {
+		fake.streamOutSparseReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+			result2 error
 		})
 	}
-	fake.expirationReturnsOnCall[i] = struct {
-		result1 time.Duration
-		result2 time.Time
-		result3 error
-	}{result1, result2, result3}
+	fake.streamOutSparseReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeVolume) Properties() (baggageclaim.VolumeProperties, error) {
-	fake.propertiesMutex.Lock()
-	ret, specificReturn := fake.propertiesReturnsOnCall[len(fake.propertiesArgsForCall)]
-	fake.propertiesArgsForCall = append(fake.propertiesArgsForCall, struct{}{})
-	fake.recordInvocation("Properties", []interface{}{})
-	fake.propertiesMutex.Unlock()
-	if fake.PropertiesStub != nil {
-		return fake.PropertiesStub()
+func (fake *FakeVolume) StreamOutCanonical(arg1 string) (io.ReadCloser, error) {
+	fake.streamOutCanonicalMutex.Lock()
+	ret, specificReturn := fake.streamOutCanonicalReturnsOnCall[len(fake.streamOutCanonicalArgsForCall)]
+	fake.streamOutCanonicalArgsForCall = append(fake.streamOutCanonicalArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StreamOutCanonical", []interface{}{arg1})
+	fake.streamOutCanonicalMutex.Unlock()
+	if fake.StreamOutCanonicalStub != nil {
+		return fake.StreamOutCanonicalStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.propertiesReturns.result1, fake.propertiesReturns.result2
+	return fake.streamOutCanonicalReturns.result1, fake.streamOutCanonicalReturns.result2
 }
 
-func (fake *FakeVolume) PropertiesCallCount() int {
-	fake.propertiesMutex.RLock()
-	defer fake.propertiesMutex.RUnlock()
-	return len(fake.propertiesArgsForCall)
+func (fake *FakeVolume) StreamOutCanonicalCallCount() int {
+	fake.streamOutCanonicalMutex.RLock()
+	defer fake.streamOutCanonicalMutex.RUnlock()
+	return len(fake.streamOutCanonicalArgsForCall)
 }
 
-func (fake *FakeVolume) PropertiesReturns(result1 baggageclaim.VolumeProperties, result2 error) {
-	fake.PropertiesStub = nil
-	fake.propertiesReturns = struct {
-		result1 baggageclaim.VolumeProperties
+func (fake *FakeVolume) StreamOutCanonicalArgsForCall(i int) string {
+	fake.streamOutCanonicalMutex.RLock()
+	defer fake.streamOutCanonicalMutex.RUnlock()
+	return fake.streamOutCanonicalArgsForCall[i].arg1
+}
+
+func (fake *FakeVolume) StreamOutCanonicalReturns(result1 io.ReadCloser, result2 error) {
+	fake.StreamOutCanonicalStub = nil
+	fake.streamOutCanonicalReturns = struct {
+		result1 io.ReadCloser
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeVolume) PropertiesReturnsOnCall(i int, result1 baggageclaim.VolumeProperties, result2 error) {
-	fake.PropertiesStub = nil
-	if fake.propertiesReturnsOnCall == nil {
-		fake.propertiesReturnsOnCall = make(map[int]struct {
-			result1 baggageclaim.VolumeProperties
+func (fake *FakeVolume) StreamOutCanonicalReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+	fake.StreamOutCanonicalStub = nil
+	if fake.streamOutCanonicalReturnsOnCall == nil {
+		fake.streamOutCanonicalReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
 			result2 error
 		})
 	}
-	fake.propertiesReturnsOnCall[i] = struct {
-		result1 baggageclaim.VolumeProperties
+	fake.streamOutCanonicalReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeVolume) Release(arg1 *time.Duration) {
-	fake.releaseMutex.Lock()
-	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
-		arg1 *time.Duration
-	}{arg1})
-	fake.recordInvocation("Release", []interface{}{arg1})
-	fake.releaseMutex.Unlock()
-	if fake.ReleaseStub != nil {
-		fake.ReleaseStub(arg1)
+func (fake *FakeVolume) StreamOutFrom(path string, offset int64) (io.ReadCloser, error) {
+	fake.streamOutFromMutex.Lock()
+	ret, specificReturn := fake.streamOutFromReturnsOnCall[len(fake.streamOutFromArgsForCall)]
+	fake.streamOutFromArgsForCall = append(fake.streamOutFromArgsForCall, struct {
+		path   string
+		offset int64
+	}{path, offset})
+	fake.recordInvocation("StreamOutFrom", []interface{}{path, offset})
+	fake.streamOutFromMutex.Unlock()
+	if fake.StreamOutFromStub != nil {
+		return fake.StreamOutFromStub(path, offset)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
 	}
+	return fake.streamOutFromReturns.result1, fake.streamOutFromReturns.result2
 }
 
-func (fake *FakeVolume) ReleaseCallCount() int {
-	fake.releaseMutex.RLock()
-	defer fake.releaseMutex.RUnlock()
-	return len(fake.releaseArgsForCall)
+func (fake *FakeVolume) StreamOutFromCallCount() int {
+	fake.streamOutFromMutex.RLock()
+	defer fake.streamOutFromMutex.RUnlock()
+	return len(fake.streamOutFromArgsForCall)
 }
 
-func (fake *FakeVolume) ReleaseArgsForCall(i int) *time.Duration {
-	fake.releaseMutex.RLock()
-	defer fake.releaseMutex.RUnlock()
-	return fake.releaseArgsForCall[i].arg1
+func (fake *FakeVolume) StreamOutFromArgsForCall(i int) (string, int64) {
+	fake.streamOutFromMutex.RLock()
+	defer fake.streamOutFromMutex.RUnlock()
+	return fake.streamOutFromArgsForCall[i].path, fake.streamOutFromArgsForCall[i].offset
 }
 
-func (fake *FakeVolume) SizeInBytes() (int64, error) {
-	fake.sizeInBytesMutex.Lock()
-	ret, specificReturn := fake.sizeInBytesReturnsOnCall[len(fake.sizeInBytesArgsForCall)]
+func (fake *FakeVolume) StreamOutFromReturns(result1 io.ReadCloser, result2 error) {
+	fake.StreamOutFromStub = nil
+	fake.streamOutFromReturns = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) StreamOutFromReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+	fake.StreamOutFromStub = nil
+	if fake.streamOutFromReturnsOnCall == nil {
+		fake.streamOutFromReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+			result2 error
+		})
+	}
+	fake.streamOutFromReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Manifest(path string) ([]baggageclaim.ManifestEntry, error) {
+	fake.manifestMutex.Lock()
+	ret, specificReturn := fake.manifestReturnsOnCall[len(fake.manifestArgsForCall)]
+	fake.manifestArgsForCall = append(fake.manifestArgsForCall, struct {
+		path string
+	}{path})
+	fake.recordInvocation("Manifest", []interface{}{path})
+	fake.manifestMutex.Unlock()
+	if fake.ManifestStub != nil {
+		return fake.ManifestStub(path)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.manifestReturns.result1, fake.manifestReturns.result2
+}
+
+func (fake *FakeVolume) ManifestCallCount() int {
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	return len(fake.manifestArgsForCall)
+}
+
+func (fake *FakeVolume) ManifestArgsForCall(i int) string {
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	return fake.manifestArgsForCall[i].path
+}
+
+func (fake *FakeVolume) ManifestReturns(result1 []baggageclaim.ManifestEntry, result2 error) {
+	fake.ManifestStub = nil
+	fake.manifestReturns = struct {
+		result1 []baggageclaim.ManifestEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ManifestReturnsOnCall(i int, result1 []baggageclaim.ManifestEntry, result2 error) {
+	fake.ManifestStub = nil
+	if fake.manifestReturnsOnCall == nil {
+		fake.manifestReturnsOnCall = make(map[int]struct {
+			result1 []baggageclaim.ManifestEntry
+			result2 error
+		})
+	}
+	fake.manifestReturnsOnCall[i] = struct {
+		result1 []baggageclaim.ManifestEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ListFiles(path string) ([]baggageclaim.FileEntry, error) {
+	fake.listFilesMutex.Lock()
+	ret, specificReturn := fake.listFilesReturnsOnCall[len(fake.listFilesArgsForCall)]
+	fake.listFilesArgsForCall = append(fake.listFilesArgsForCall, struct {
+		path string
+	}{path})
+	fake.recordInvocation("ListFiles", []interface{}{path})
+	fake.listFilesMutex.Unlock()
+	if fake.ListFilesStub != nil {
+		return fake.ListFilesStub(path)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listFilesReturns.result1, fake.listFilesReturns.result2
+}
+
+func (fake *FakeVolume) ListFilesCallCount() int {
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	return len(fake.listFilesArgsForCall)
+}
+
+func (fake *FakeVolume) ListFilesArgsForCall(i int) string {
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	return fake.listFilesArgsForCall[i].path
+}
+
+func (fake *FakeVolume) ListFilesReturns(result1 []baggageclaim.FileEntry, result2 error) {
+	fake.ListFilesStub = nil
+	fake.listFilesReturns = struct {
+		result1 []baggageclaim.FileEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ListFilesReturnsOnCall(i int, result1 []baggageclaim.FileEntry, result2 error) {
+	fake.ListFilesStub = nil
+	if fake.listFilesReturnsOnCall == nil {
+		fake.listFilesReturnsOnCall = make(map[int]struct {
+			result1 []baggageclaim.FileEntry
+			result2 error
+		})
+	}
+	fake.listFilesReturnsOnCall[i] = struct {
+		result1 []baggageclaim.FileEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) StreamInDelta(path string, tarStream io.Reader) error {
+	fake.streamInDeltaMutex.Lock()
+	ret, specificReturn := fake.streamInDeltaReturnsOnCall[len(fake.streamInDeltaArgsForCall)]
+	fake.streamInDeltaArgsForCall = append(fake.streamInDeltaArgsForCall, struct {
+		path      string
+		tarStream io.Reader
+	}{path, tarStream})
+	fake.recordInvocation("StreamInDelta", []interface{}{path, tarStream})
+	fake.streamInDeltaMutex.Unlock()
+	if fake.StreamInDeltaStub != nil {
+		return fake.StreamInDeltaStub(path, tarStream)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamInDeltaReturns.result1
+}
+
+func (fake *FakeVolume) StreamInDeltaCallCount() int {
+	fake.streamInDeltaMutex.RLock()
+	defer fake.streamInDeltaMutex.RUnlock()
+	return len(fake.streamInDeltaArgsForCall)
+}
+
+func (fake *FakeVolume) StreamInDeltaArgsForCall(i int) (string, io.Reader) {
+	fake.streamInDeltaMutex.RLock()
+	defer fake.streamInDeltaMutex.RUnlock()
+	return fake.streamInDeltaArgsForCall[i].path, fake.streamInDeltaArgsForCall[i].tarStream
+}
+
+func (fake *FakeVolume) StreamInDeltaReturns(result1 error) {
+	fake.StreamInDeltaStub = nil
+	fake.streamInDeltaReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInDeltaReturnsOnCall(i int, result1 error) {
+	fake.StreamInDeltaStub = nil
+	if fake.streamInDeltaReturnsOnCall == nil {
+		fake.streamInDeltaReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.streamInDeltaReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInLayer(path string, layer io.Reader) error {
+	fake.streamInLayerMutex.Lock()
+	ret, specificReturn := fake.streamInLayerReturnsOnCall[len(fake.streamInLayerArgsForCall)]
+	fake.streamInLayerArgsForCall = append(fake.streamInLayerArgsForCall, struct {
+		path  string
+		layer io.Reader
+	}{path, layer})
+	fake.recordInvocation("StreamInLayer", []interface{}{path, layer})
+	fake.streamInLayerMutex.Unlock()
+	if fake.StreamInLayerStub != nil {
+		return fake.StreamInLayerStub(path, layer)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamInLayerReturns.result1
+}
+
+func (fake *FakeVolume) StreamInLayerCallCount() int {
+	fake.streamInLayerMutex.RLock()
+	defer fake.streamInLayerMutex.RUnlock()
+	return len(fake.streamInLayerArgsForCall)
+}
+
+func (fake *FakeVolume) StreamInLayerArgsForCall(i int) (string, io.Reader) {
+	fake.streamInLayerMutex.RLock()
+	defer fake.streamInLayerMutex.RUnlock()
+	return fake.streamInLayerArgsForCall[i].path, fake.streamInLayerArgsForCall[i].layer
+}
+
+func (fake *FakeVolume) StreamInLayerReturns(result1 error) {
+	fake.StreamInLayerStub = nil
+	fake.streamInLayerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInLayerReturnsOnCall(i int, result1 error) {
+	fake.StreamInLayerStub = nil
+	if fake.streamInLayerReturnsOnCall == nil {
+		fake.streamInLayerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.streamInLayerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInWithChecksum(arg1 string, arg2 io.Reader, arg3 string) error {
+	fake.streamInWithChecksumMutex.Lock()
+	ret, specificReturn := fake.streamInWithChecksumReturnsOnCall[len(fake.streamInWithChecksumArgsForCall)]
+	fake.streamInWithChecksumArgsForCall = append(fake.streamInWithChecksumArgsForCall, struct {
+		arg1 string
+		arg2 io.Reader
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("StreamInWithChecksum", []interface{}{arg1, arg2, arg3})
+	fake.streamInWithChecksumMutex.Unlock()
+	if fake.StreamInWithChecksumStub != nil {
+		return fake.StreamInWithChecksumStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamInWithChecksumReturns.result1
+}
+
+func (fake *FakeVolume) StreamInWithChecksumCallCount() int {
+	fake.streamInWithChecksumMutex.RLock()
+	defer fake.streamInWithChecksumMutex.RUnlock()
+	return len(fake.streamInWithChecksumArgsForCall)
+}
+
+func (fake *FakeVolume) StreamInWithChecksumArgsForCall(i int) (string, io.Reader, string) {
+	fake.streamInWithChecksumMutex.RLock()
+	defer fake.streamInWithChecksumMutex.RUnlock()
+	return fake.streamInWithChecksumArgsForCall[i].arg1, fake.streamInWithChecksumArgsForCall[i].arg2, fake.streamInWithChecksumArgsForCall[i].arg3
+}
+
+func (fake *FakeVolume) StreamInWithChecksumReturns(result1 error) {
+	fake.StreamInWithChecksumStub = nil
+	fake.streamInWithChecksumReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamInWithChecksumReturnsOnCall(i int, result1 error) {
+	fake.StreamInWithChecksumStub = nil
+	if fake.streamInWithChecksumReturnsOnCall == nil {
+		fake.streamInWithChecksumReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.streamInWithChecksumReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) CopyFrom(arg1 string, arg2 string, arg3 string) error {
+	fake.copyFromMutex.Lock()
+	ret, specificReturn := fake.copyFromReturnsOnCall[len(fake.copyFromArgsForCall)]
+	fake.copyFromArgsForCall = append(fake.copyFromArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("CopyFrom", []interface{}{arg1, arg2, arg3})
+	fake.copyFromMutex.Unlock()
+	if fake.CopyFromStub != nil {
+		return fake.CopyFromStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.copyFromReturns.result1
+}
+
+func (fake *FakeVolume) CopyFromCallCount() int {
+	fake.copyFromMutex.RLock()
+	defer fake.copyFromMutex.RUnlock()
+	return len(fake.copyFromArgsForCall)
+}
+
+func (fake *FakeVolume) CopyFromArgsForCall(i int) (string, string, string) {
+	fake.copyFromMutex.RLock()
+	defer fake.copyFromMutex.RUnlock()
+	return fake.copyFromArgsForCall[i].arg1, fake.copyFromArgsForCall[i].arg2, fake.copyFromArgsForCall[i].arg3
+}
+
+func (fake *FakeVolume) CopyFromReturns(result1 error) {
+	fake.CopyFromStub = nil
+	fake.copyFromReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) CopyFromReturnsOnCall(i int, result1 error) {
+	fake.CopyFromStub = nil
+	if fake.copyFromReturnsOnCall == nil {
+		fake.copyFromReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyFromReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) StreamOutWithProgress(path string, progress baggageclaim.ProgressFunc) (io.ReadCloser, error) {
+	fake.streamOutWithProgressMutex.Lock()
+	ret, specificReturn := fake.streamOutWithProgressReturnsOnCall[len(fake.streamOutWithProgressArgsForCall)]
+	fake.streamOutWithProgressArgsForCall = append(fake.streamOutWithProgressArgsForCall, struct {
+		path     string
+		progress baggageclaim.ProgressFunc
+	}{path, progress})
+	fake.recordInvocation("StreamOutWithProgress", []interface{}{path, progress})
+	fake.streamOutWithProgressMutex.Unlock()
+	if fake.StreamOutWithProgressStub != nil {
+		return fake.StreamOutWithProgressStub(path, progress)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.streamOutWithProgressReturns.result1, fake.streamOutWithProgressReturns.result2
+}
+
+func (fake *FakeVolume) StreamOutWithProgressCallCount() int {
+	fake.streamOutWithProgressMutex.RLock()
+	defer fake.streamOutWithProgressMutex.RUnlock()
+	return len(fake.streamOutWithProgressArgsForCall)
+}
+
+func (fake *FakeVolume) StreamOutWithProgressArgsForCall(i int) (string, baggageclaim.ProgressFunc) {
+	fake.streamOutWithProgressMutex.RLock()
+	defer fake.streamOutWithProgressMutex.RUnlock()
+	return fake.streamOutWithProgressArgsForCall[i].path, fake.streamOutWithProgressArgsForCall[i].progress
+}
+
+func (fake *FakeVolume) StreamOutWithProgressReturns(result1 io.ReadCloser, result2 error) {
+	fake.StreamOutWithProgressStub = nil
+	fake.streamOutWithProgressReturns = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) StreamOutWithProgressReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+	fake.StreamOutWithProgressStub = nil
+	if fake.streamOutWithProgressReturnsOnCall == nil {
+		fake.streamOutWithProgressReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+			result2 error
+		})
+	}
+	fake.streamOutWithProgressReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) TouchAccess() error {
+	fake.touchAccessMutex.Lock()
+	ret, specificReturn := fake.touchAccessReturnsOnCall[len(fake.touchAccessArgsForCall)]
+	fake.touchAccessArgsForCall = append(fake.touchAccessArgsForCall, struct{}{})
+	fake.recordInvocation("TouchAccess", []interface{}{})
+	fake.touchAccessMutex.Unlock()
+	if fake.TouchAccessStub != nil {
+		return fake.TouchAccessStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.touchAccessReturns.result1
+}
+
+func (fake *FakeVolume) TouchAccessCallCount() int {
+	fake.touchAccessMutex.RLock()
+	defer fake.touchAccessMutex.RUnlock()
+	return len(fake.touchAccessArgsForCall)
+}
+
+func (fake *FakeVolume) TouchAccessReturns(result1 error) {
+	fake.TouchAccessStub = nil
+	fake.touchAccessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) TouchAccessReturnsOnCall(i int, result1 error) {
+	fake.TouchAccessStub = nil
+	if fake.touchAccessReturnsOnCall == nil {
+		fake.touchAccessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.touchAccessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) Materialize() (bool, error) {
+	fake.materializeMutex.Lock()
+	ret, specificReturn := fake.materializeReturnsOnCall[len(fake.materializeArgsForCall)]
+	fake.materializeArgsForCall = append(fake.materializeArgsForCall, struct{}{})
+	fake.recordInvocation("Materialize", []interface{}{})
+	fake.materializeMutex.Unlock()
+	if fake.MaterializeStub != nil {
+		return fake.MaterializeStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.materializeReturns.result1, fake.materializeReturns.result2
+}
+
+func (fake *FakeVolume) MaterializeCallCount() int {
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	return len(fake.materializeArgsForCall)
+}
+
+func (fake *FakeVolume) MaterializeReturns(result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	fake.materializeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) MaterializeReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	if fake.materializeReturnsOnCall == nil {
+		fake.materializeReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.materializeReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Commit(freeze bool) error {
+	fake.commitMutex.Lock()
+	ret, specificReturn := fake.commitReturnsOnCall[len(fake.commitArgsForCall)]
+	fake.commitArgsForCall = append(fake.commitArgsForCall, struct {
+		freeze bool
+	}{freeze})
+	fake.recordInvocation("Commit", []interface{}{freeze})
+	fake.commitMutex.Unlock()
+	if fake.CommitStub != nil {
+		return fake.CommitStub(freeze)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.commitReturns.result1
+}
+
+func (fake *FakeVolume) CommitCallCount() int {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return len(fake.commitArgsForCall)
+}
+
+func (fake *FakeVolume) CommitArgsForCall(i int) bool {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return fake.commitArgsForCall[i].freeze
+}
+
+func (fake *FakeVolume) CommitReturns(result1 error) {
+	fake.CommitStub = nil
+	fake.commitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) CommitReturnsOnCall(i int, result1 error) {
+	fake.CommitStub = nil
+	if fake.commitReturnsOnCall == nil {
+		fake.commitReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) Expiration() (time.Duration, time.Time, error) {
+	fake.expirationMutex.Lock()
+	ret, specificReturn := fake.expirationReturnsOnCall[len(fake.expirationArgsForCall)]
+	fake.expirationArgsForCall = append(fake.expirationArgsForCall, struct{}{})
+	fake.recordInvocation("Expiration", []interface{}{})
+	fake.expirationMutex.Unlock()
+	if fake.ExpirationStub != nil {
+		return fake.ExpirationStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.expirationReturns.result1, fake.expirationReturns.result2, fake.expirationReturns.result3
+}
+
+func (fake *FakeVolume) ExpirationCallCount() int {
+	fake.expirationMutex.RLock()
+	defer fake.expirationMutex.RUnlock()
+	return len(fake.expirationArgsForCall)
+}
+
+func (fake *FakeVolume) ExpirationReturns(result1 time.Duration, result2 time.Time, result3 error) {
+	fake.ExpirationStub = nil
+	fake.expirationReturns = struct {
+		result1 time.Duration
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) ExpirationReturnsOnCall(i int, result1 time.Duration, result2 time.Time, result3 error) {
+	fake.ExpirationStub = nil
+	if fake.expirationReturnsOnCall == nil {
+		fake.expirationReturnsOnCall = make(map[int]struct {
+			result1 time.Duration
+			result2 time.Time
+			result3 error
+		})
+	}
+	fake.expirationReturnsOnCall[i] = struct {
+		result1 time.Duration
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) Properties() (baggageclaim.VolumeProperties, error) {
+	fake.propertiesMutex.Lock()
+	ret, specificReturn := fake.propertiesReturnsOnCall[len(fake.propertiesArgsForCall)]
+	fake.propertiesArgsForCall = append(fake.propertiesArgsForCall, struct{}{})
+	fake.recordInvocation("Properties", []interface{}{})
+	fake.propertiesMutex.Unlock()
+	if fake.PropertiesStub != nil {
+		return fake.PropertiesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.propertiesReturns.result1, fake.propertiesReturns.result2
+}
+
+func (fake *FakeVolume) PropertiesCallCount() int {
+	fake.propertiesMutex.RLock()
+	defer fake.propertiesMutex.RUnlock()
+	return len(fake.propertiesArgsForCall)
+}
+
+func (fake *FakeVolume) PropertiesReturns(result1 baggageclaim.VolumeProperties, result2 error) {
+	fake.PropertiesStub = nil
+	fake.propertiesReturns = struct {
+		result1 baggageclaim.VolumeProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) PropertiesReturnsOnCall(i int, result1 baggageclaim.VolumeProperties, result2 error) {
+	fake.PropertiesStub = nil
+	if fake.propertiesReturnsOnCall == nil {
+		fake.propertiesReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.VolumeProperties
+			result2 error
+		})
+	}
+	fake.propertiesReturnsOnCall[i] = struct {
+		result1 baggageclaim.VolumeProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Property(key string) (string, bool, error) {
+	fake.propertyMutex.Lock()
+	ret, specificReturn := fake.propertyReturnsOnCall[len(fake.propertyArgsForCall)]
+	fake.propertyArgsForCall = append(fake.propertyArgsForCall, struct {
+		key string
+	}{key})
+	fake.recordInvocation("Property", []interface{}{key})
+	fake.propertyMutex.Unlock()
+	if fake.PropertyStub != nil {
+		return fake.PropertyStub(key)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.propertyReturns.result1, fake.propertyReturns.result2, fake.propertyReturns.result3
+}
+
+func (fake *FakeVolume) PropertyCallCount() int {
+	fake.propertyMutex.RLock()
+	defer fake.propertyMutex.RUnlock()
+	return len(fake.propertyArgsForCall)
+}
+
+func (fake *FakeVolume) PropertyArgsForCall(i int) string {
+	fake.propertyMutex.RLock()
+	defer fake.propertyMutex.RUnlock()
+	return fake.propertyArgsForCall[i].key
+}
+
+func (fake *FakeVolume) PropertyReturns(result1 string, result2 bool, result3 error) {
+	fake.PropertyStub = nil
+	fake.propertyReturns = struct {
+		result1 string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) PropertyReturnsOnCall(i int, result1 string, result2 bool, result3 error) {
+	fake.PropertyStub = nil
+	if fake.propertyReturnsOnCall == nil {
+		fake.propertyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 bool
+			result3 error
+		})
+	}
+	fake.propertyReturnsOnCall[i] = struct {
+		result1 string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) Release(arg1 *time.Duration) {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		arg1 *time.Duration
+	}{arg1})
+	fake.recordInvocation("Release", []interface{}{arg1})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		fake.ReleaseStub(arg1)
+	}
+}
+
+func (fake *FakeVolume) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *FakeVolume) ReleaseArgsForCall(i int) *time.Duration {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].arg1
+}
+
+func (fake *FakeVolume) SizeInBytes() (int64, error) {
+	fake.sizeInBytesMutex.Lock()
+	ret, specificReturn := fake.sizeInBytesReturnsOnCall[len(fake.sizeInBytesArgsForCall)]
 	fake.sizeInBytesArgsForCall = append(fake.sizeInBytesArgsForCall, struct{}{})
 	fake.recordInvocation("SizeInBytes", []interface{}{})
 	fake.sizeInBytesMutex.Unlock()
@@ -590,37 +1969,626 @@ func (fake *FakeVolume) SizeInBytes() (int64, error) {
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.sizeInBytesReturns.result1, fake.sizeInBytesReturns.result2
+	return fake.sizeInBytesReturns.result1, fake.sizeInBytesReturns.result2
+}
+
+func (fake *FakeVolume) SizeInBytesCallCount() int {
+	fake.sizeInBytesMutex.RLock()
+	defer fake.sizeInBytesMutex.RUnlock()
+	return len(fake.sizeInBytesArgsForCall)
+}
+
+func (fake *FakeVolume) SizeInBytesReturns(result1 int64, result2 error) {
+	fake.SizeInBytesStub = nil
+	fake.sizeInBytesReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) SizeInBytesReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.SizeInBytesStub = nil
+	if fake.sizeInBytesReturnsOnCall == nil {
+		fake.sizeInBytesReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.sizeInBytesReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) QuotaInBytes() (int64, error) {
+	fake.quotaInBytesMutex.Lock()
+	ret, specificReturn := fake.quotaInBytesReturnsOnCall[len(fake.quotaInBytesArgsForCall)]
+	fake.quotaInBytesArgsForCall = append(fake.quotaInBytesArgsForCall, struct{}{})
+	fake.recordInvocation("QuotaInBytes", []interface{}{})
+	fake.quotaInBytesMutex.Unlock()
+	if fake.QuotaInBytesStub != nil {
+		return fake.QuotaInBytesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.quotaInBytesReturns.result1, fake.quotaInBytesReturns.result2
+}
+
+func (fake *FakeVolume) QuotaInBytesCallCount() int {
+	fake.quotaInBytesMutex.RLock()
+	defer fake.quotaInBytesMutex.RUnlock()
+	return len(fake.quotaInBytesArgsForCall)
+}
+
+func (fake *FakeVolume) QuotaInBytesReturns(result1 int64, result2 error) {
+	fake.QuotaInBytesStub = nil
+	fake.quotaInBytesReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) QuotaInBytesReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.QuotaInBytesStub = nil
+	if fake.quotaInBytesReturnsOnCall == nil {
+		fake.quotaInBytesReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.quotaInBytesReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) FileCount() (int64, error) {
+	fake.fileCountMutex.Lock()
+	ret, specificReturn := fake.fileCountReturnsOnCall[len(fake.fileCountArgsForCall)]
+	fake.fileCountArgsForCall = append(fake.fileCountArgsForCall, struct{}{})
+	fake.recordInvocation("FileCount", []interface{}{})
+	fake.fileCountMutex.Unlock()
+	if fake.FileCountStub != nil {
+		return fake.FileCountStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.fileCountReturns.result1, fake.fileCountReturns.result2
 }
 
-func (fake *FakeVolume) SizeInBytesCallCount() int {
-	fake.sizeInBytesMutex.RLock()
-	defer fake.sizeInBytesMutex.RUnlock()
-	return len(fake.sizeInBytesArgsForCall)
+func (fake *FakeVolume) FileCountCallCount() int {
+	fake.fileCountMutex.RLock()
+	defer fake.fileCountMutex.RUnlock()
+	return len(fake.fileCountArgsForCall)
 }
 
-func (fake *FakeVolume) SizeInBytesReturns(result1 int64, result2 error) {
-	fake.SizeInBytesStub = nil
-	fake.sizeInBytesReturns = struct {
+func (fake *FakeVolume) FileCountReturns(result1 int64, result2 error) {
+	fake.FileCountStub = nil
+	fake.fileCountReturns = struct {
 		result1 int64
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeVolume) SizeInBytesReturnsOnCall(i int, result1 int64, result2 error) {
-	fake.SizeInBytesStub = nil
-	if fake.sizeInBytesReturnsOnCall == nil {
-		fake.sizeInBytesReturnsOnCall = make(map[int]struct {
+func (fake *FakeVolume) FileCountReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.FileCountStub = nil
+	if fake.fileCountReturnsOnCall == nil {
+		fake.fileCountReturnsOnCall = make(map[int]struct {
 			result1 int64
 			result2 error
 		})
 	}
-	fake.sizeInBytesReturnsOnCall[i] = struct {
+	fake.fileCountReturnsOnCall[i] = struct {
 		result1 int64
 		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeVolume) Stats() (baggageclaim.VolumeStatsResponse, error) {
+	fake.statsMutex.Lock()
+	ret, specificReturn := fake.statsReturnsOnCall[len(fake.statsArgsForCall)]
+	fake.statsArgsForCall = append(fake.statsArgsForCall, struct{}{})
+	fake.recordInvocation("Stats", []interface{}{})
+	fake.statsMutex.Unlock()
+	if fake.StatsStub != nil {
+		return fake.StatsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.statsReturns.result1, fake.statsReturns.result2
+}
+
+func (fake *FakeVolume) StatsCallCount() int {
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	return len(fake.statsArgsForCall)
+}
+
+func (fake *FakeVolume) StatsReturns(result1 baggageclaim.VolumeStatsResponse, result2 error) {
+	fake.StatsStub = nil
+	fake.statsReturns = struct {
+		result1 baggageclaim.VolumeStatsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) StatsReturnsOnCall(i int, result1 baggageclaim.VolumeStatsResponse, result2 error) {
+	fake.StatsStub = nil
+	if fake.statsReturnsOnCall == nil {
+		fake.statsReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.VolumeStatsResponse
+			result2 error
+		})
+	}
+	fake.statsReturnsOnCall[i] = struct {
+		result1 baggageclaim.VolumeStatsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) RecordedDigest() (string, error) {
+	fake.recordedDigestMutex.Lock()
+	ret, specificReturn := fake.recordedDigestReturnsOnCall[len(fake.recordedDigestArgsForCall)]
+	fake.recordedDigestArgsForCall = append(fake.recordedDigestArgsForCall, struct{}{})
+	fake.recordInvocation("RecordedDigest", []interface{}{})
+	fake.recordedDigestMutex.Unlock()
+	if fake.RecordedDigestStub != nil {
+		return fake.RecordedDigestStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.recordedDigestReturns.result1, fake.recordedDigestReturns.result2
+}
+
+func (fake *FakeVolume) RecordedDigestCallCount() int {
+	fake.recordedDigestMutex.RLock()
+	defer fake.recordedDigestMutex.RUnlock()
+	return len(fake.recordedDigestArgsForCall)
+}
+
+func (fake *FakeVolume) RecordedDigestReturns(result1 string, result2 error) {
+	fake.RecordedDigestStub = nil
+	fake.recordedDigestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) RecordedDigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.RecordedDigestStub = nil
+	if fake.recordedDigestReturnsOnCall == nil {
+		fake.recordedDigestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.recordedDigestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Digest() (string, error) {
+	fake.digestMutex.Lock()
+	ret, specificReturn := fake.digestReturnsOnCall[len(fake.digestArgsForCall)]
+	fake.digestArgsForCall = append(fake.digestArgsForCall, struct{}{})
+	fake.recordInvocation("Digest", []interface{}{})
+	fake.digestMutex.Unlock()
+	if fake.DigestStub != nil {
+		return fake.DigestStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.digestReturns.result1, fake.digestReturns.result2
+}
+
+func (fake *FakeVolume) DigestCallCount() int {
+	fake.digestMutex.RLock()
+	defer fake.digestMutex.RUnlock()
+	return len(fake.digestArgsForCall)
+}
+
+func (fake *FakeVolume) DigestReturns(result1 string, result2 error) {
+	fake.DigestStub = nil
+	fake.digestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) DigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.DigestStub = nil
+	if fake.digestReturnsOnCall == nil {
+		fake.digestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.digestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ParentHandle() (string, error) {
+	fake.parentHandleMutex.Lock()
+	ret, specificReturn := fake.parentHandleReturnsOnCall[len(fake.parentHandleArgsForCall)]
+	fake.parentHandleArgsForCall = append(fake.parentHandleArgsForCall, struct{}{})
+	fake.recordInvocation("ParentHandle", []interface{}{})
+	fake.parentHandleMutex.Unlock()
+	if fake.ParentHandleStub != nil {
+		return fake.ParentHandleStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.parentHandleReturns.result1, fake.parentHandleReturns.result2
+}
+
+func (fake *FakeVolume) ParentHandleCallCount() int {
+	fake.parentHandleMutex.RLock()
+	defer fake.parentHandleMutex.RUnlock()
+	return len(fake.parentHandleArgsForCall)
+}
+
+func (fake *FakeVolume) ParentHandleReturns(result1 string, result2 error) {
+	fake.ParentHandleStub = nil
+	fake.parentHandleReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ParentHandleReturnsOnCall(i int, result1 string, result2 error) {
+	fake.ParentHandleStub = nil
+	if fake.parentHandleReturnsOnCall == nil {
+		fake.parentHandleReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.parentHandleReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Children() ([]string, error) {
+	fake.childrenMutex.Lock()
+	ret, specificReturn := fake.childrenReturnsOnCall[len(fake.childrenArgsForCall)]
+	fake.childrenArgsForCall = append(fake.childrenArgsForCall, struct{}{})
+	fake.recordInvocation("Children", []interface{}{})
+	fake.childrenMutex.Unlock()
+	if fake.ChildrenStub != nil {
+		return fake.ChildrenStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.childrenReturns.result1, fake.childrenReturns.result2
+}
+
+func (fake *FakeVolume) ChildrenCallCount() int {
+	fake.childrenMutex.RLock()
+	defer fake.childrenMutex.RUnlock()
+	return len(fake.childrenArgsForCall)
+}
+
+func (fake *FakeVolume) ChildrenReturns(result1 []string, result2 error) {
+	fake.ChildrenStub = nil
+	fake.childrenReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ChildrenReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.ChildrenStub = nil
+	if fake.childrenReturnsOnCall == nil {
+		fake.childrenReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.childrenReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) MountOptions() ([]string, error) {
+	fake.mountOptionsMutex.Lock()
+	ret, specificReturn := fake.mountOptionsReturnsOnCall[len(fake.mountOptionsArgsForCall)]
+	fake.mountOptionsArgsForCall = append(fake.mountOptionsArgsForCall, struct{}{})
+	fake.recordInvocation("MountOptions", []interface{}{})
+	fake.mountOptionsMutex.Unlock()
+	if fake.MountOptionsStub != nil {
+		return fake.MountOptionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.mountOptionsReturns.result1, fake.mountOptionsReturns.result2
+}
+
+func (fake *FakeVolume) MountOptionsCallCount() int {
+	fake.mountOptionsMutex.RLock()
+	defer fake.mountOptionsMutex.RUnlock()
+	return len(fake.mountOptionsArgsForCall)
+}
+
+func (fake *FakeVolume) MountOptionsReturns(result1 []string, result2 error) {
+	fake.MountOptionsStub = nil
+	fake.mountOptionsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) MountOptionsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.MountOptionsStub = nil
+	if fake.mountOptionsReturnsOnCall == nil {
+		fake.mountOptionsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.mountOptionsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) Encrypted() (bool, error) {
+	fake.encryptedMutex.Lock()
+	ret, specificReturn := fake.encryptedReturnsOnCall[len(fake.encryptedArgsForCall)]
+	fake.encryptedArgsForCall = append(fake.encryptedArgsForCall, struct{}{})
+	fake.recordInvocation("Encrypted", []interface{}{})
+	fake.encryptedMutex.Unlock()
+	if fake.EncryptedStub != nil {
+		return fake.EncryptedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.encryptedReturns.result1, fake.encryptedReturns.result2
+}
+
+func (fake *FakeVolume) EncryptedCallCount() int {
+	fake.encryptedMutex.RLock()
+	defer fake.encryptedMutex.RUnlock()
+	return len(fake.encryptedArgsForCall)
+}
+
+func (fake *FakeVolume) EncryptedReturns(result1 bool, result2 error) {
+	fake.EncryptedStub = nil
+	fake.encryptedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) EncryptedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.EncryptedStub = nil
+	if fake.encryptedReturnsOnCall == nil {
+		fake.encryptedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.encryptedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) AcquireLease(ttl time.Duration) (string, time.Time, error) {
+	fake.acquireLeaseMutex.Lock()
+	ret, specificReturn := fake.acquireLeaseReturnsOnCall[len(fake.acquireLeaseArgsForCall)]
+	fake.acquireLeaseArgsForCall = append(fake.acquireLeaseArgsForCall, struct {
+		ttl time.Duration
+	}{ttl})
+	fake.recordInvocation("AcquireLease", []interface{}{ttl})
+	fake.acquireLeaseMutex.Unlock()
+	if fake.AcquireLeaseStub != nil {
+		return fake.AcquireLeaseStub(ttl)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.acquireLeaseReturns.result1, fake.acquireLeaseReturns.result2, fake.acquireLeaseReturns.result3
+}
+
+func (fake *FakeVolume) AcquireLeaseCallCount() int {
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	return len(fake.acquireLeaseArgsForCall)
+}
+
+func (fake *FakeVolume) AcquireLeaseArgsForCall(i int) time.Duration {
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	return fake.acquireLeaseArgsForCall[i].ttl
+}
+
+func (fake *FakeVolume) AcquireLeaseReturns(result1 string, result2 time.Time, result3 error) {
+	fake.AcquireLeaseStub = nil
+	fake.acquireLeaseReturns = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) AcquireLeaseReturnsOnCall(i int, result1 string, result2 time.Time, result3 error) {
+	fake.AcquireLeaseStub = nil
+	if fake.acquireLeaseReturnsOnCall == nil {
+		fake.acquireLeaseReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 time.Time
+			result3 error
+		})
+	}
+	fake.acquireLeaseReturnsOnCall[i] = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVolume) RenewLease(token string, ttl time.Duration) (time.Time, error) {
+	fake.renewLeaseMutex.Lock()
+	ret, specificReturn := fake.renewLeaseReturnsOnCall[len(fake.renewLeaseArgsForCall)]
+	fake.renewLeaseArgsForCall = append(fake.renewLeaseArgsForCall, struct {
+		token string
+		ttl   time.Duration
+	}{token, ttl})
+	fake.recordInvocation("RenewLease", []interface{}{token, ttl})
+	fake.renewLeaseMutex.Unlock()
+	if fake.RenewLeaseStub != nil {
+		return fake.RenewLeaseStub(token, ttl)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.renewLeaseReturns.result1, fake.renewLeaseReturns.result2
+}
+
+func (fake *FakeVolume) RenewLeaseCallCount() int {
+	fake.renewLeaseMutex.RLock()
+	defer fake.renewLeaseMutex.RUnlock()
+	return len(fake.renewLeaseArgsForCall)
+}
+
+func (fake *FakeVolume) RenewLeaseArgsForCall(i int) (string, time.Duration) {
+	fake.renewLeaseMutex.RLock()
+	defer fake.renewLeaseMutex.RUnlock()
+	return fake.renewLeaseArgsForCall[i].token, fake.renewLeaseArgsForCall[i].ttl
+}
+
+func (fake *FakeVolume) RenewLeaseReturns(result1 time.Time, result2 error) {
+	fake.RenewLeaseStub = nil
+	fake.renewLeaseReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) RenewLeaseReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.RenewLeaseStub = nil
+	if fake.renewLeaseReturnsOnCall == nil {
+		fake.renewLeaseReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.renewLeaseReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolume) ReleaseLease(token string) error {
+	fake.releaseLeaseMutex.Lock()
+	ret, specificReturn := fake.releaseLeaseReturnsOnCall[len(fake.releaseLeaseArgsForCall)]
+	fake.releaseLeaseArgsForCall = append(fake.releaseLeaseArgsForCall, struct {
+		token string
+	}{token})
+	fake.recordInvocation("ReleaseLease", []interface{}{token})
+	fake.releaseLeaseMutex.Unlock()
+	if fake.ReleaseLeaseStub != nil {
+		return fake.ReleaseLeaseStub(token)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.releaseLeaseReturns.result1
+}
+
+func (fake *FakeVolume) ReleaseLeaseCallCount() int {
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
+	return len(fake.releaseLeaseArgsForCall)
+}
+
+func (fake *FakeVolume) ReleaseLeaseArgsForCall(i int) string {
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
+	return fake.releaseLeaseArgsForCall[i].token
+}
+
+func (fake *FakeVolume) ReleaseLeaseReturns(result1 error) {
+	fake.ReleaseLeaseStub = nil
+	fake.releaseLeaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) ReleaseLeaseReturnsOnCall(i int, result1 error) {
+	fake.ReleaseLeaseStub = nil
+	if fake.releaseLeaseReturnsOnCall == nil {
+		fake.releaseLeaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseLeaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolume) WithLease(token string) baggageclaim.Volume {
+	fake.withLeaseMutex.Lock()
+	ret, specificReturn := fake.withLeaseReturnsOnCall[len(fake.withLeaseArgsForCall)]
+	fake.withLeaseArgsForCall = append(fake.withLeaseArgsForCall, struct {
+		token string
+	}{token})
+	fake.recordInvocation("WithLease", []interface{}{token})
+	fake.withLeaseMutex.Unlock()
+	if fake.WithLeaseStub != nil {
+		return fake.WithLeaseStub(token)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.withLeaseReturns.result1
+}
+
+func (fake *FakeVolume) WithLeaseCallCount() int {
+	fake.withLeaseMutex.RLock()
+	defer fake.withLeaseMutex.RUnlock()
+	return len(fake.withLeaseArgsForCall)
+}
+
+func (fake *FakeVolume) WithLeaseArgsForCall(i int) string {
+	fake.withLeaseMutex.RLock()
+	defer fake.withLeaseMutex.RUnlock()
+	return fake.withLeaseArgsForCall[i].token
+}
+
+func (fake *FakeVolume) WithLeaseReturns(result1 baggageclaim.Volume) {
+	fake.WithLeaseStub = nil
+	fake.withLeaseReturns = struct {
+		result1 baggageclaim.Volume
+	}{result1}
+}
+
+func (fake *FakeVolume) WithLeaseReturnsOnCall(i int, result1 baggageclaim.Volume) {
+	fake.WithLeaseStub = nil
+	if fake.withLeaseReturnsOnCall == nil {
+		fake.withLeaseReturnsOnCall = make(map[int]struct {
+			result1 baggageclaim.Volume
+		})
+	}
+	fake.withLeaseReturnsOnCall[i] = struct {
+		result1 baggageclaim.Volume
+	}{result1}
+}
+
 func (fake *FakeVolume) Destroy() error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
@@ -670,22 +2638,88 @@ func (fake *FakeVolume) Invocations() map[string][][]interface{} {
 	defer fake.pathMutex.RUnlock()
 	fake.setTTLMutex.RLock()
 	defer fake.setTTLMutex.RUnlock()
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
 	fake.setPropertyMutex.RLock()
 	defer fake.setPropertyMutex.RUnlock()
+	fake.compareAndSetPropertyMutex.RLock()
+	defer fake.compareAndSetPropertyMutex.RUnlock()
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
 	fake.setPrivilegedMutex.RLock()
 	defer fake.setPrivilegedMutex.RUnlock()
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
 	fake.streamInMutex.RLock()
 	defer fake.streamInMutex.RUnlock()
+	fake.streamInWithProgressMutex.RLock()
+	defer fake.streamInWithProgressMutex.RUnlock()
 	fake.streamOutMutex.RLock()
 	defer fake.streamOutMutex.RUnlock()
+	fake.streamOutSparseMutex.RLock()
+	defer fake.streamOutSparseMutex.RUnlock()
+	fake.streamOutCanonicalMutex.RLock()
+	defer fake.streamOutCanonicalMutex.RUnlock()
+	fake.streamOutFromMutex.RLock()
+	defer fake.streamOutFromMutex.RUnlock()
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	fake.streamInDeltaMutex.RLock()
+	defer fake.streamInDeltaMutex.RUnlock()
+	fake.streamInLayerMutex.RLock()
+	defer fake.streamInLayerMutex.RUnlock()
+	fake.streamInWithChecksumMutex.RLock()
+	defer fake.streamInWithChecksumMutex.RUnlock()
+	fake.copyFromMutex.RLock()
+	defer fake.copyFromMutex.RUnlock()
+	fake.streamOutWithProgressMutex.RLock()
+	defer fake.streamOutWithProgressMutex.RUnlock()
+	fake.touchAccessMutex.RLock()
+	defer fake.touchAccessMutex.RUnlock()
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
 	fake.expirationMutex.RLock()
 	defer fake.expirationMutex.RUnlock()
 	fake.propertiesMutex.RLock()
 	defer fake.propertiesMutex.RUnlock()
+	fake.propertyMutex.RLock()
+	defer fake.propertyMutex.RUnlock()
 	fake.releaseMutex.RLock()
 	defer fake.releaseMutex.RUnlock()
 	fake.sizeInBytesMutex.RLock()
 	defer fake.sizeInBytesMutex.RUnlock()
+	fake.quotaInBytesMutex.RLock()
+	defer fake.quotaInBytesMutex.RUnlock()
+	fake.fileCountMutex.RLock()
+	defer fake.fileCountMutex.RUnlock()
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	fake.recordedDigestMutex.RLock()
+	defer fake.recordedDigestMutex.RUnlock()
+	fake.digestMutex.RLock()
+	defer fake.digestMutex.RUnlock()
+	fake.parentHandleMutex.RLock()
+	defer fake.parentHandleMutex.RUnlock()
+	fake.childrenMutex.RLock()
+	defer fake.childrenMutex.RUnlock()
+	fake.mountOptionsMutex.RLock()
+	defer fake.mountOptionsMutex.RUnlock()
+	fake.encryptedMutex.RLock()
+	defer fake.encryptedMutex.RUnlock()
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	fake.renewLeaseMutex.RLock()
+	defer fake.renewLeaseMutex.RUnlock()
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
+	fake.withLeaseMutex.RLock()
+	defer fake.withLeaseMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -23,6 +23,49 @@ type Client interface {
 	// could not be created.
 	CreateVolume(lager.Logger, string, VolumeSpec) (Volume, error)
 
+	// CloneVolume creates a volume with the second handle whose contents are
+	// a copy of the volume with the first. Writes to one are not seen by the
+	// other. A handle is generated if the second one is empty.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// CloneVolume returns the volume that was created or an error as to why it
+	// could not be created.
+	CloneVolume(lager.Logger, string, string) (Volume, error)
+
+	// RenameVolume gives the volume with the first handle the second one,
+	// without touching its contents.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// RenameVolume returns the renamed volume or an error as to why it could
+	// not be renamed.
+	RenameVolume(lager.Logger, string, string) (Volume, error)
+
+	// PromoteVolume gives the copy-on-write volume with the handle a copy of
+	// its parent's contents of its own, after which the parent can be
+	// destroyed. Other volumes are left as they are.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// PromoteVolume returns the promoted volume or an error as to why it could
+	// not be promoted.
+	PromoteVolume(lager.Logger, string) (Volume, error)
+
+	// RestoreVolume brings back the volume with the handle that was destroyed
+	// on a server that keeps destroyed volumes for a while. Restoring a
+	// volume that was not destroyed returns it as it is.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// RestoreVolume returns the restored volume or an error as to why it could
+	// not be restored, which is ErrVolumeGone once it is kept no longer.
+	RestoreVolume(lager.Logger, string) (Volume, error)
+
 	// ListVolumes lists the volumes that are present on the server. A
 	// VolumeProperties object can be passed in to filter the volumes that are in
 	// the response.
@@ -34,6 +77,26 @@ type Client interface {
 	// could not be listed.
 	ListVolumes(lager.Logger, VolumeProperties) (Volumes, error)
 
+	// ListVolumesPage lists a page of the volumes that are present on the
+	// server and match the VolumeProperties, ordered and paged as the
+	// ListVolumesOptions say.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// ListVolumesPage returns the page of volumes and how many volumes match
+	// across all pages, or an error as to why they could not be listed.
+	ListVolumesPage(lager.Logger, VolumeProperties, ListVolumesOptions) (Volumes, int, error)
+
+	// ListDeletedVolumes lists the volumes that match the VolumeProperties
+	// and were destroyed on a server that keeps destroyed volumes for a
+	// while, until they are restored or purged. They are only described, as
+	// nothing else can be done with them.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	ListDeletedVolumes(lager.Logger, VolumeProperties) ([]VolumeResponse, error)
+
 	// LookupVolume finds a volume that is present on the server. It takes a
 	// string that corresponds to the Handle of the Volume.
 	//
@@ -43,10 +106,52 @@ type Client interface {
 	// LookupVolume returns a bool if the volume is found with the matching volume
 	// or an error as to why the volume could not be found.
 	LookupVolume(lager.Logger, string) (Volume, bool, error)
+
+	// VolumeExists returns whether LookupVolume would find the volume,
+	// without fetching it or heartbeating it. It takes a string that
+	// corresponds to the Handle of the Volume.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	VolumeExists(lager.Logger, string) (bool, error)
+
+	// TotalUsage sums up how much of the server's volumes filesystem the
+	// volumes use. If a property is given, the volumes are also grouped by
+	// their values for it.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	TotalUsage(lager.Logger, string) (UsageResponse, error)
+
+	// DestroyVolumes destroys the volumes with the given handles in one
+	// request. Volumes that do not exist count as destroyed.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// DestroyVolumes returns the handles of the volumes that could not be
+	// destroyed, with why, or an error if the request failed as a whole.
+	DestroyVolumes(lager.Logger, []string) (map[string]error, error)
+
+	// DestroyVolumesWithProperties destroys the volumes that have all of the
+	// given properties in one request. At least one property must be given.
+	//
+	// You are required to pass in a logger to the call to retain context across
+	// the library boundary.
+	//
+	// DestroyVolumesWithProperties returns how many volumes were destroyed and
+	// the handles of the ones that could not be, with why, or an error if the
+	// request failed as a whole.
+	DestroyVolumesWithProperties(lager.Logger, VolumeProperties) (int, map[string]error, error)
 }
 
 //go:generate counterfeiter . Volume
 
+// ProgressFunc is told how many bytes of a stream have been transferred, and
+// how many there are in total, or -1 if that isn't known. It is called from
+// the goroutine reading the stream, so it should return quickly.
+type ProgressFunc func(transferred int64, total int64)
+
 // Volume represents a volume in the BaggageClaim system.
 type Volume interface {
 	// Handle returns a per-server unique identifier for the volume. The URL of
@@ -61,26 +166,129 @@ type Volume interface {
 	// the TTL could not be set.
 	SetTTL(time.Duration) error
 
+	// SetExpiresAt makes the volume expire at the given time, as told by the
+	// server's clock rather than counted from when the server receives it. A
+	// time that has passed makes the volume expire straight away.
+	SetExpiresAt(time.Time) error
+
 	// SetProperty sets a property on the Volume. Properties can be used to
 	// filter the results in the ListVolumes call above.
 	SetProperty(key string, value string) error
 
+	// CompareAndSetProperty sets a property on the Volume only if it has the
+	// expected value, or, if expected is nil, only if the Volume does not
+	// have it yet. It returns ErrPropertyConflict otherwise. Unlike
+	// SetProperty, it is never retried.
+	CompareAndSetProperty(key string, expected *string, value string) error
+
+	// SetProperties sets all of the properties on the Volume in one go.
+	// Either all of them are set or, if an error is returned, none are.
+	SetProperties(VolumeProperties) error
+
+	// DeleteProperty removes a property from the Volume, if it has it.
+	DeleteProperty(key string) error
+
 	// SetPrivileged namespaces or un-namespaces the UID/GID ownership of the
 	// volume's contents.
 	SetPrivileged(bool) error
 
+	// SetSELinuxLabel applies the SELinux label to the volume's contents. It
+	// has no effect on workers without SELinux.
+	SetSELinuxLabel(label string) error
+
 	// StreamIn calls BaggageClaim API endpoint in order to initialize tarStream
 	// to stream the contents of the Reader into this volume at the specified path.
 	StreamIn(path string, tarStream io.Reader) error
 
+	// StreamInWithChecksum is StreamIn, having the server check that the tar
+	// has the checksum, sha256:<hex> of it uncompressed, once it has been
+	// streamed. It returns ErrStreamChecksumMismatch if it doesn't, having
+	// removed what was streamed in.
+	StreamInWithChecksum(path string, tarStream io.Reader, checksum string) error
+
+	// CopyFrom copies what is at sourcePath in the source volume into this
+	// volume at path, on the server, as StreamIn would the source's
+	// StreamOut of it, without it going through the client. Both volumes
+	// must be on the same server. It returns ErrSourceVolumeNotFound if the
+	// source doesn't exist, ErrFileNotFound if there is nothing at
+	// sourcePath, and ErrVolumeNotFound if this volume doesn't exist.
+	CopyFrom(path string, sourceHandle string, sourcePath string) error
+
 	StreamOut(path string) (io.ReadCloser, error)
 
+	// StreamOutSparse is StreamOut, asking for the holes in sparse files to
+	// be carried as GNU sparse entries rather than as runs of zeros. Servers
+	// that can't stream them so send every file in full.
+	StreamOutSparse(path string) (io.ReadCloser, error)
+
+	// StreamOutCanonical is StreamOut, asking for a tar of only what is in
+	// the tree, with every mtime zeroed and owners by number alone, so that
+	// volumes with the same contents are streamed out as the same bytes,
+	// e.g. for caching by a digest of them. Servers that can't stream one
+	// so stream it as StreamOut does.
+	StreamOutCanonical(path string) (io.ReadCloser, error)
+
+	// StreamOutFrom is StreamOut, starting offset bytes into the stream, for
+	// resuming one that was cut off. A volume is streamed out as the same
+	// bytes each time while it is unchanged, so the two streams only add up
+	// to the volume's contents if it has not changed since. It returns
+	// ErrRangeNotSatisfiable if the stream is no longer than offset.
+	StreamOutFrom(path string, offset int64) (io.ReadCloser, error)
+
+	// Manifest lists what is under the path in the volume, for working out
+	// what a StreamInDelta needs to carry.
+	Manifest(path string) ([]ManifestEntry, error)
+
+	// ListFiles lists what is under the path in the volume, without
+	// streaming any of it out.
+	ListFiles(path string) ([]FileEntry, error)
+
+	// StreamInDelta streams the entries of tarStream in on top of what is
+	// already at the path, first removing the paths named by the whiteouts
+	// it starts with (see WhiteoutPrefix).
+	StreamInDelta(path string, tarStream io.Reader) error
+
+	// StreamInLayer streams an OCI or Docker image layer in on top of what is
+	// already at the path, as a container runtime would stack it, applying
+	// its whiteouts (see OpaqueWhiteout). The layer may be compressed.
+	// Streaming each layer of an image into a copy-on-write child of the
+	// volume holding the layers below it gives the image's root filesystem.
+	StreamInLayer(path string, layer io.Reader) error
+
+	// StreamInWithProgress is StreamIn, calling progress every so often with
+	// the bytes sent so far, and once more when all of them have been. The
+	// total is known if the Reader is a *bytes.Buffer, *bytes.Reader, or
+	// *strings.Reader. progress may be nil.
+	StreamInWithProgress(path string, tarStream io.Reader, progress ProgressFunc) error
+
+	// StreamOutWithProgress is StreamOut, calling progress every so often with
+	// the bytes read so far, and once more when the stream ends. The total is
+	// known if the server gives a Content-Length. progress may be nil.
+	StreamOutWithProgress(path string, progress ProgressFunc) (io.ReadCloser, error)
+
+	// TouchAccess records an access to the volume that did not go through
+	// StreamOut, without changing its TTL.
+	TouchAccess() error
+
+	// Materialize gets the volume ready for use ahead of time, so that the
+	// first operations on it don't pay for it. It returns whether the
+	// volume's mount was gone and had to be mounted again.
+	Materialize() (bool, error)
+
+	// Commit flushes the volume's contents to disk and marks it as ready. If
+	// freeze is true, further StreamIn and SetPrivileged calls are rejected.
+	Commit(freeze bool) error
+
 	Expiration() (time.Duration, time.Time, error)
 
 	// Properties returns the currently set properties for a Volume. An error is
 	// returned if these could not be retrieved.
 	Properties() (VolumeProperties, error)
 
+	// Property returns the value of one of the Volume's properties, and
+	// whether it has it at all, without fetching the rest of the Volume.
+	Property(key string) (string, bool, error)
+
 	// Release stops the Volume being kept alive by the server. A final TTL can
 	// be specified.
 	Release(*time.Duration)
@@ -88,6 +296,60 @@ type Volume interface {
 	// Size returns the exclusive size of the volume on disk in bytes
 	SizeInBytes() (int64, error)
 
+	// QuotaInBytes returns the volume's size limit, or 0 if it has none
+	QuotaInBytes() (int64, error)
+
+	// FileCount returns the number of files and directories in the volume
+	FileCount() (int64, error)
+
+	// Stats returns the volume's size, file count, and quota in one request,
+	// along with what is free on its backing store and whether its size is
+	// exact or estimated.
+	Stats() (VolumeStatsResponse, error)
+
+	// RecordedDigest returns the digest of the volume's contents recorded by
+	// the last StreamIn, or "" if there has not been one.
+	RecordedDigest() (string, error)
+
+	// Digest has the server digest the volume's contents as they are now,
+	// which matches RecordedDigest unless they have changed since.
+	Digest() (string, error)
+
+	// ParentHandle returns the handle of the volume a copy-on-write volume
+	// was created from, or "" for other volumes.
+	ParentHandle() (string, error)
+
+	// Children returns the handles of the volume's copy-on-write children.
+	Children() ([]string, error)
+
+	// MountOptions returns the mount options the volume was given of its
+	// own, as they were applied.
+	MountOptions() ([]string, error)
+
+	// Encrypted returns whether the volume's data is encrypted where it is
+	// stored.
+	Encrypted() (bool, error)
+
+	// AcquireLease takes out a lease on the volume for the TTL, returning its
+	// token and when it expires. While the lease is held, stream-ins,
+	// property changes, and destroys of the volume fail with
+	// ErrVolumeIsLeased unless made through WithLease. Leases are advisory,
+	// keeping other clients from changing the volume rather than securing it,
+	// and do not outlive a restart of the server.
+	AcquireLease(ttl time.Duration) (string, time.Time, error)
+
+	// RenewLease extends the lease held with the token to the TTL from now.
+	// It returns ErrLeaseNotHeld if the lease has expired or been released.
+	RenewLease(token string, ttl time.Duration) (time.Time, error)
+
+	// ReleaseLease gives up the lease held with the token, if it is still
+	// held.
+	ReleaseLease(token string) error
+
+	// WithLease returns the Volume, making its stream-ins, property changes,
+	// and destroys with the lease held with the token.
+	WithLease(token string) Volume
+
 	// Destroy removes the volume and its contents. Note that it does not
 	// safeguard against child volumes being present. To safely remove a volume
 	// that may have children, set a TTL instead.
@@ -117,6 +379,49 @@ type VolumeSpec struct {
 	// translation of the files in the volume so that they can be read by a
 	// non-privileged user.
 	Privileged bool
+
+	// ExpectedSizeInBytes is a hint of how large the volume is expected to
+	// grow. Along with MutationHeavy, the server may use it to create a COW
+	// volume as a full copy of its parent instead.
+	ExpectedSizeInBytes int64
+
+	// MutationHeavy hints that most of the volume's contents will be
+	// modified.
+	MutationHeavy bool
+
+	// SizeInBytes limits how much the volume may hold. Writes past it fail
+	// with ENOSPC. The volume is unlimited if it is 0.
+	SizeInBytes int64
+
+	// ReadOnly makes the volume read-only once it has been created from the
+	// strategy. COW volumes created from it are writable unless they ask to
+	// be read-only too.
+	ReadOnly bool
+
+	// MountOptions are mount options to give the volume of its own, e.g.
+	// noatime. The server refuses to create the volume if its driver does
+	// not allow all of them.
+	MountOptions []string
+
+	// RenewTTLOnAccess starts the volume's TTL over each time it is streamed
+	// out, looked up, or touched, so that volumes in use are kept around
+	// without heartbeating them. Volumes without it expire at their TTL
+	// unless it is set again.
+	RenewTTLOnAccess bool
+
+	// IdempotencyKey, if set, makes the create safe to retry: the server
+	// gives back the volume it already created with the key rather than
+	// create another, and the create is retried as the client's retry
+	// policy allows. Creates asking for a different volume with the same key
+	// fail with ErrIdempotencyKeyConflict.
+	IdempotencyKey string
+
+	// Encrypted has the server keep the volume's data encrypted where it is
+	// stored, with a key of the volume's own that is shredded when the
+	// volume is destroyed. It is read and written as it is otherwise. The
+	// server refuses to create the volume if it has no master key, or its
+	// driver or the strategy can't encrypt it.
+	Encrypted bool
 }
 
 type Strategy interface {
@@ -161,6 +466,27 @@ func (strategy COWStrategy) Encode() *json.RawMessage {
 	return &msg
 }
 
+// ViewStrategy creates a read-only view of another Volume that shares its
+// data. Destroying the base is deferred until its last view is destroyed.
+// The base cannot itself be a view.
+type ViewStrategy struct {
+	// The volume whose data the view shares.
+	Parent Volume
+}
+
+func (strategy ViewStrategy) Encode() *json.RawMessage {
+	payload, _ := json.Marshal(struct {
+		Type   string `json:"type"`
+		Volume string `json:"volume"`
+	}{
+		Type:   "view",
+		Volume: strategy.Parent.Handle(),
+	})
+
+	msg := json.RawMessage(payload)
+	return &msg
+}
+
 // EmptyStrategy created a new empty volume.
 type EmptyStrategy struct{}
 
@@ -169,6 +495,47 @@ func (EmptyStrategy) Encode() *json.RawMessage {
 	return &msg
 }
 
+// TmpfsStrategy creates a new empty volume whose data is held in memory, up
+// to the size of the volume, which must be given.
+type TmpfsStrategy struct{}
+
+func (TmpfsStrategy) Encode() *json.RawMessage {
+	msg := json.RawMessage(`{"type":"tmpfs"}`)
+	return &msg
+}
+
+// URLStrategy creates a volume from a tar archive the server fetches from a
+// URL, so that it doesn't pass through the client. The archive may be
+// compressed as for StreamIn.
+//
+// Creates that fail for the fetch return an InvalidRequestError naming the
+// strategy field at fault. Only those that fail with 502, when the
+// connection or the remote server failed, are worth retrying; those that
+// fail with 422, e.g. for a missing archive or a checksum mismatch, are not.
+type URLStrategy struct {
+	// The http or https URL of the archive.
+	URL string
+
+	// Checksum, if given as sha256:<hex>, is what the whole archive must
+	// hash to for the volume to be created.
+	Checksum string
+}
+
+func (strategy URLStrategy) Encode() *json.RawMessage {
+	payload, _ := json.Marshal(struct {
+		Type     string `json:"type"`
+		URL      string `json:"url"`
+		Checksum string `json:"checksum,omitempty"`
+	}{
+		Type:     "url",
+		URL:      strategy.URL,
+		Checksum: strategy.Checksum,
+	})
+
+	msg := json.RawMessage(payload)
+	return &msg
+}
+
 func FinalTTL(dur time.Duration) *time.Duration {
 	return &dur
 }
@@ -2,13 +2,16 @@ package client
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,15 +35,26 @@ type client struct {
 	nestedRoundTripper  http.RoundTripper
 
 	givenHttpClient *http.Client
+
+	retryPolicy RetryPolicy
 }
 
 func New(apiURL string, nestedRoundTripper http.RoundTripper) Client {
+	return NewWithRetryPolicy(apiURL, nestedRoundTripper, RetryPolicy{})
+}
+
+// NewWithRetryPolicy returns a client that retries its idempotent requests
+// according to the policy, on top of the connection retries every client
+// makes.
+func NewWithRetryPolicy(apiURL string, nestedRoundTripper http.RoundTripper, retryPolicy RetryPolicy) Client {
 	return &client{
 		requestGenerator: rata.NewRequestGenerator(apiURL, baggageclaim.Routes),
 
 		retryBackOffFactory: retryhttp.NewExponentialBackOffFactory(60 * time.Minute),
 
 		nestedRoundTripper: nestedRoundTripper,
+
+		retryPolicy: retryPolicy,
 	}
 }
 
@@ -73,15 +87,32 @@ func (c *client) CreateVolume(logger lager.Logger, handle string, volumeSpec bag
 
 	buffer := &bytes.Buffer{}
 	json.NewEncoder(buffer).Encode(baggageclaim.VolumeRequest{
-		Handle:       handle,
-		Strategy:     strategy.Encode(),
-		TTLInSeconds: uint(math.Ceil(volumeSpec.TTL.Seconds())),
-		Properties:   volumeSpec.Properties,
-		Privileged:   volumeSpec.Privileged,
+		Handle:              handle,
+		Strategy:            strategy.Encode(),
+		TTLInSeconds:        uint(math.Ceil(volumeSpec.TTL.Seconds())),
+		Properties:          volumeSpec.Properties,
+		Privileged:          volumeSpec.Privileged,
+		ExpectedSizeInBytes: volumeSpec.ExpectedSizeInBytes,
+		MutationHeavy:       volumeSpec.MutationHeavy,
+		SizeInBytes:         volumeSpec.SizeInBytes,
+		ReadOnly:            volumeSpec.ReadOnly,
+		MountOptions:        volumeSpec.MountOptions,
+		RenewTTLOnAccess:    volumeSpec.RenewTTLOnAccess,
+		IdempotencyKey:      volumeSpec.IdempotencyKey,
+		Encrypted:           volumeSpec.Encrypted,
 	})
 
 	request, _ := c.requestGenerator.CreateRequest(baggageclaim.CreateVolume, nil, buffer)
-	response, err := c.httpClient(logger).Do(request)
+
+	var response *http.Response
+	var err error
+	if volumeSpec.IdempotencyKey != "" {
+		// a retry gets back the volume the first attempt created, if it did
+		response, err = c.doIdempotent(logger, request)
+	} else {
+		response, err = c.httpClient(logger).Do(request)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -109,22 +140,20 @@ func (c *client) CreateVolume(logger lager.Logger, handle string, volumeSpec bag
 	return v, nil
 }
 
-func (c *client) ListVolumes(logger lager.Logger, properties baggageclaim.VolumeProperties) (baggageclaim.Volumes, error) {
-	if properties == nil {
-		properties = baggageclaim.VolumeProperties{}
-	}
+func (c *client) CloneVolume(logger lager.Logger, srcHandle string, handle string) (baggageclaim.Volume, error) {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(baggageclaim.CloneVolumeRequest{
+		Handle: handle,
+	})
 
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.ListVolumes, nil, nil)
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.CloneVolume, rata.Params{
+		"handle": srcHandle,
+	}, buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	queryString := request.URL.Query()
-	for key, val := range properties {
-		queryString.Add(key, val)
-	}
-
-	request.URL.RawQuery = queryString.Encode()
+	request.Header.Add("Content-type", "application/json")
 
 	response, err := c.httpClient(logger).Do(request)
 	if err != nil {
@@ -133,287 +162,1192 @@ func (c *client) ListVolumes(logger lager.Logger, properties baggageclaim.Volume
 
 	defer response.Body.Close()
 
-	if response.StatusCode != 200 {
+	if response.StatusCode != http.StatusCreated {
 		return nil, getError(response)
 	}
 
-	if header := response.Header.Get("Content-Type"); header != "application/json" {
-		return nil, fmt.Errorf("unexpected content-type of: %s", header)
-	}
-
-	var volumesResponse []baggageclaim.VolumeResponse
-	err = json.NewDecoder(response.Body).Decode(&volumesResponse)
+	var volumeResponse baggageclaim.VolumeResponse
+	err = json.NewDecoder(response.Body).Decode(&volumeResponse)
 	if err != nil {
 		return nil, err
 	}
 
-	var volumes baggageclaim.Volumes
-	for _, vr := range volumesResponse {
-		v, initialHeartbeatSuccess := c.newVolume(logger, vr)
-		if initialHeartbeatSuccess {
-			volumes = append(volumes, v)
-		}
-	}
-
-	return volumes, nil
-}
-
-func (c *client) LookupVolume(logger lager.Logger, handle string) (baggageclaim.Volume, bool, error) {
-	volumeResponse, found, err := c.getVolumeResponse(logger, handle)
-	if err != nil {
-		return nil, false, err
-	}
-	if !found {
-		return nil, found, nil
-	}
-
 	v, initialHeartbeatSuccess := c.newVolume(logger, volumeResponse)
 	if !initialHeartbeatSuccess {
-		return nil, false, nil
-	}
-	return v, true, nil
-}
-
-func (c *client) newVolume(logger lager.Logger, apiVolume baggageclaim.VolumeResponse) (baggageclaim.Volume, bool) {
-	volume := &clientVolume{
-		logger: logger,
-
-		handle: apiVolume.Handle,
-		path:   apiVolume.Path,
-
-		bcClient:     c,
-		heartbeating: new(sync.WaitGroup),
-		release:      make(chan *time.Duration, 1),
-	}
-
-	if apiVolume.TTLInSeconds == 0 {
-		return volume, true
-	}
-
-	initialHeartbeatSuccess := volume.startHeartbeating(logger, time.Duration(apiVolume.TTLInSeconds)*time.Second)
-
-	return volume, initialHeartbeatSuccess
-}
-
-func (c *client) streamIn(logger lager.Logger, destHandle string, path string, tarContent io.Reader) error {
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.StreamIn, rata.Params{
-		"handle": destHandle,
-	}, tarContent)
-
-	request.URL.RawQuery = url.Values{"path": []string{path}}.Encode()
-	if err != nil {
-		return err
-	}
-
-	response, err := c.httpClient(logger).Do(request)
-	if err != nil {
-		return err
+		return nil, volume.ErrVolumeDoesNotExist
 	}
 
-	defer response.Body.Close()
-	if response.StatusCode == http.StatusNoContent {
-		return nil
-	}
-	return getError(response)
+	return v, nil
 }
 
-func (c *client) streamOut(logger lager.Logger, srcHandle string, path string) (io.ReadCloser, error) {
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.StreamOut, rata.Params{
-		"handle": srcHandle,
-	}, nil)
+func (c *client) RenameVolume(logger lager.Logger, handle string, newHandle string) (baggageclaim.Volume, error) {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(baggageclaim.RenameVolumeRequest{
+		Handle: newHandle,
+	})
 
-	request.URL.RawQuery = url.Values{"path": []string{path}}.Encode()
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.RenameVolume, rata.Params{
+		"handle": handle,
+	}, buffer)
 	if err != nil {
 		return nil, err
 	}
 
+	request.Header.Add("Content-type", "application/json")
+
 	response, err := c.httpClient(logger).Do(request)
 	if err != nil {
 		return nil, err
 	}
 
+	defer response.Body.Close()
+
 	if response.StatusCode != http.StatusOK {
 		return nil, getError(response)
 	}
 
-	return response.Body, nil
-}
-
-func getError(response *http.Response) error {
-	var errorResponse *api.ErrorResponse
-	err := json.NewDecoder(response.Body).Decode(&errorResponse)
+	var volumeResponse baggageclaim.VolumeResponse
+	err = json.NewDecoder(response.Body).Decode(&volumeResponse)
 	if err != nil {
-		return err
-	}
-
-	if errorResponse.Message == api.ErrStreamOutNotFound.Error() {
-		return baggageclaim.ErrFileNotFound
+		return nil, err
 	}
 
-	if response.StatusCode == 404 {
-		return baggageclaim.ErrVolumeNotFound
+	v, initialHeartbeatSuccess := c.newVolume(logger, volumeResponse)
+	if !initialHeartbeatSuccess {
+		return nil, volume.ErrVolumeDoesNotExist
 	}
 
-	return errors.New(errorResponse.Message)
+	return v, nil
 }
 
-func (c *client) getVolumeResponse(logger lager.Logger, handle string) (baggageclaim.VolumeResponse, bool, error) {
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetVolume, rata.Params{
+func (c *client) PromoteVolume(logger lager.Logger, handle string) (baggageclaim.Volume, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.PromoteVolume, rata.Params{
 		"handle": handle,
 	}, nil)
 	if err != nil {
-		return baggageclaim.VolumeResponse{}, false, err
+		return nil, err
 	}
 
-	response, err := c.httpClient(logger).Do(request)
+	// promoting a volume again leaves it as it is
+	response, err := c.doIdempotent(logger, request)
 	if err != nil {
-		return baggageclaim.VolumeResponse{}, false, err
+		return nil, err
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		if response.StatusCode == http.StatusNotFound {
-			return baggageclaim.VolumeResponse{}, false, nil
-		}
-
-		return baggageclaim.VolumeResponse{}, false, getError(response)
-	}
-
-	if header := response.Header.Get("Content-Type"); header != "application/json" {
-		return baggageclaim.VolumeResponse{}, false, fmt.Errorf("unexpected content-type of: %s", header)
+		return nil, getError(response)
 	}
 
 	var volumeResponse baggageclaim.VolumeResponse
 	err = json.NewDecoder(response.Body).Decode(&volumeResponse)
 	if err != nil {
-		return baggageclaim.VolumeResponse{}, false, err
+		return nil, err
 	}
 
-	return volumeResponse, true, nil
+	v, initialHeartbeatSuccess := c.newVolume(logger, volumeResponse)
+	if !initialHeartbeatSuccess {
+		return nil, volume.ErrVolumeDoesNotExist
+	}
+
+	return v, nil
 }
 
-func (c *client) getVolumeStatsResponse(logger lager.Logger, handle string) (baggageclaim.VolumeStatsResponse, error) {
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetVolumeStats, rata.Params{
+func (c *client) RestoreVolume(logger lager.Logger, handle string) (baggageclaim.Volume, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.RestoreVolume, rata.Params{
 		"handle": handle,
 	}, nil)
 	if err != nil {
-		return baggageclaim.VolumeStatsResponse{}, err
+		return nil, err
 	}
 
-	response, err := c.httpClient(logger).Do(request)
+	// restoring a volume again returns it as it is
+	response, err := c.doIdempotent(logger, request)
 	if err != nil {
-		return baggageclaim.VolumeStatsResponse{}, err
+		return nil, err
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		if response.StatusCode == http.StatusNotFound {
-			return baggageclaim.VolumeStatsResponse{}, nil
-		}
-		return baggageclaim.VolumeStatsResponse{}, getError(response)
+		return nil, getError(response)
 	}
 
-	if header := response.Header.Get("Content-Type"); header != "application/json" {
-		return baggageclaim.VolumeStatsResponse{}, fmt.Errorf("unexpected content-type of: %s", header)
+	var volumeResponse baggageclaim.VolumeResponse
+	err = json.NewDecoder(response.Body).Decode(&volumeResponse)
+	if err != nil {
+		return nil, err
 	}
 
-	var volumeStatsResponse baggageclaim.VolumeStatsResponse
-	err = json.NewDecoder(response.Body).Decode(&volumeStatsResponse)
-	if err != nil {
-		return baggageclaim.VolumeStatsResponse{}, err
+	v, initialHeartbeatSuccess := c.newVolume(logger, volumeResponse)
+	if !initialHeartbeatSuccess {
+		return nil, volume.ErrVolumeDoesNotExist
 	}
 
-	return volumeStatsResponse, nil
+	return v, nil
 }
 
-func (c *client) setTTL(logger lager.Logger, handle string, ttl time.Duration) error {
-	buffer := &bytes.Buffer{}
-	json.NewEncoder(buffer).Encode(baggageclaim.TTLRequest{
-		Value: uint(math.Ceil(ttl.Seconds())),
-	})
+func (c *client) ListVolumes(logger lager.Logger, properties baggageclaim.VolumeProperties) (baggageclaim.Volumes, error) {
+	volumes, _, err := c.listVolumes(logger, properties, nil)
+	return volumes, err
+}
 
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetTTL, rata.Params{
-		"handle": handle,
-	}, buffer)
-	if err != nil {
-		return err
-	}
+func (c *client) ListVolumesPage(logger lager.Logger, properties baggageclaim.VolumeProperties, opts baggageclaim.ListVolumesOptions) (baggageclaim.Volumes, int, error) {
+	return c.listVolumes(logger, properties, &opts)
+}
 
-	request.Header.Add("Content-type", "application/json")
+func (c *client) listVolumes(logger lager.Logger, properties baggageclaim.VolumeProperties, opts *baggageclaim.ListVolumesOptions) (baggageclaim.Volumes, int, error) {
+	if properties == nil {
+		properties = baggageclaim.VolumeProperties{}
+	}
 
-	response, err := c.httpClient(logger).Do(request)
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.ListVolumes, nil, nil)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
-	defer response.Body.Close()
+	acceptGob(request)
 
-	if response.StatusCode != 204 {
-		return getError(response)
+	queryString := request.URL.Query()
+	for key, val := range properties {
+		queryString.Add(key, val)
 	}
 
-	return nil
-}
+	if opts != nil {
+		queryString.Set("limit", strconv.Itoa(opts.Limit))
+		queryString.Set("offset", strconv.Itoa(opts.Offset))
 
-func (c *client) destroy(logger lager.Logger, handle string) error {
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.DestroyVolume, rata.Params{
-		"handle": handle,
-	}, nil)
-	if err != nil {
-		return err
+		if opts.Sort != "" {
+			queryString.Set("sort", opts.Sort)
+		}
 	}
 
-	response, err := c.httpClient(logger).Do(request)
+	request.URL.RawQuery = queryString.Encode()
+
+	response, err := c.doIdempotent(logger, request)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
 	defer response.Body.Close()
 
-	if response.StatusCode != 204 {
-		return getError(response)
+	if response.StatusCode != 200 {
+		return nil, 0, getError(response)
 	}
 
-	return nil
-}
+	var volumesResponse []baggageclaim.VolumeResponse
+	err = decodeReadResponse(response, &volumesResponse)
+	if err != nil {
+		return nil, 0, err
+	}
 
-func (c *client) setPrivileged(logger lager.Logger, handle string, privileged bool) error {
-	buffer := &bytes.Buffer{}
-	json.NewEncoder(buffer).Encode(baggageclaim.PrivilegedRequest{
-		Value: privileged,
-	})
+	total := len(volumesResponse)
+	if opts != nil {
+		total, err = strconv.Atoi(response.Header.Get(baggageclaim.VolumeCountHeader))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
 
-	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetPrivileged, rata.Params{
-		"handle": handle,
-	}, buffer)
-	if err != nil {
-		return err
+	var volumes baggageclaim.Volumes
+	for _, vr := range volumesResponse {
+		v, initialHeartbeatSuccess := c.newVolume(logger, vr)
+		if initialHeartbeatSuccess {
+			volumes = append(volumes, v)
+		}
 	}
 
-	request.Header.Add("Content-type", "application/json")
+	return volumes, total, nil
+}
 
-	response, err := c.httpClient(logger).Do(request)
+func (c *client) ListDeletedVolumes(logger lager.Logger, properties baggageclaim.VolumeProperties) ([]baggageclaim.VolumeResponse, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.ListVolumes, nil, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer response.Body.Close()
+	acceptGob(request)
 
-	if response.StatusCode != 204 {
-		return getError(response)
+	queryString := request.URL.Query()
+	for key, val := range properties {
+		queryString.Add(key, val)
 	}
 
-	return nil
-}
+	queryString.Set("includeDeleted", "true")
 
-func (c *client) setProperty(logger lager.Logger, handle string, propertyName string, propertyValue string) error {
+	request.URL.RawQuery = queryString.Encode()
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		return nil, getError(response)
+	}
+
+	var volumesResponse []baggageclaim.VolumeResponse
+	err = decodeReadResponse(response, &volumesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []baggageclaim.VolumeResponse{}
+	for _, vr := range volumesResponse {
+		if vr.Deleted {
+			deleted = append(deleted, vr)
+		}
+	}
+
+	return deleted, nil
+}
+
+func (c *client) LookupVolume(logger lager.Logger, handle string) (baggageclaim.Volume, bool, error) {
+	volumeResponse, found, err := c.getVolumeResponse(logger, handle)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, found, nil
+	}
+
+	v, initialHeartbeatSuccess := c.newVolume(logger, volumeResponse)
+	if !initialHeartbeatSuccess {
+		return nil, false, nil
+	}
+	return v, true, nil
+}
+
+func (c *client) VolumeExists(logger lager.Logger, handle string) (bool, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.VolumeExists, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return false, err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		// a response to HEAD has no body to say what went wrong
+		return false, fmt.Errorf("checking for volume failed: %s", response.Status)
+	}
+}
+
+func (c *client) TotalUsage(logger lager.Logger, groupBy string) (baggageclaim.UsageResponse, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetUsage, nil, nil)
+	if err != nil {
+		return baggageclaim.UsageResponse{}, err
+	}
+
+	if groupBy != "" {
+		request.URL.RawQuery = url.Values{"group-by": {groupBy}}.Encode()
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return baggageclaim.UsageResponse{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return baggageclaim.UsageResponse{}, getError(response)
+	}
+
+	var usage baggageclaim.UsageResponse
+	err = json.NewDecoder(response.Body).Decode(&usage)
+	if err != nil {
+		return baggageclaim.UsageResponse{}, err
+	}
+
+	return usage, nil
+}
+
+func (c *client) DestroyVolumes(logger lager.Logger, handles []string) (map[string]error, error) {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(handles)
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.DestroyVolumes, nil, buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, getError(response)
+	}
+
+	var results []baggageclaim.DestroyVolumesResult
+	err = json.NewDecoder(response.Body).Decode(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := map[string]error{}
+	for _, result := range results {
+		if result.Error != "" {
+			failed[result.Handle] = errors.New(result.Error)
+		}
+	}
+
+	return failed, nil
+}
+
+func (c *client) DestroyVolumesWithProperties(logger lager.Logger, properties baggageclaim.VolumeProperties) (int, map[string]error, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.DestroyVolumesWithProperties, nil, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	queryString := request.URL.Query()
+	for key, val := range properties {
+		queryString.Add(key, val)
+	}
+
+	request.URL.RawQuery = queryString.Encode()
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, nil, getError(response)
+	}
+
+	var result baggageclaim.DestroyVolumesWithPropertiesResponse
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	failed := map[string]error{}
+	for _, failure := range result.Failed {
+		failed[failure.Handle] = errors.New(failure.Error)
+	}
+
+	return result.Destroyed, failed, nil
+}
+
+func (c *client) newVolume(logger lager.Logger, apiVolume baggageclaim.VolumeResponse) (baggageclaim.Volume, bool) {
+	volume := &clientVolume{
+		logger: logger,
+
+		handle: apiVolume.Handle,
+		path:   apiVolume.Path,
+
+		bcClient:     c,
+		heartbeating: new(sync.WaitGroup),
+		release:      make(chan *time.Duration, 1),
+	}
+
+	if apiVolume.TTLInSeconds == 0 {
+		return volume, true
+	}
+
+	initialHeartbeatSuccess := volume.startHeartbeating(logger, time.Duration(apiVolume.TTLInSeconds)*time.Second)
+
+	return volume, initialHeartbeatSuccess
+}
+
+func (c *client) streamIn(logger lager.Logger, destHandle string, path string, tarContent io.Reader, progress baggageclaim.ProgressFunc, delta bool, layer bool, leaseToken string, checksum string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.StreamIn, rata.Params{
+		"handle": destHandle,
+	}, tarContent)
+
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"path": []string{path}}
+	if delta {
+		query.Set("delta", "true")
+	}
+
+	if layer {
+		query.Set("layer", "true")
+	}
+
+	request.URL.RawQuery = query.Encode()
+
+	setLeaseToken(request, leaseToken)
+
+	if checksum != "" {
+		request.Header.Set(baggageclaim.StreamChecksumHeader, checksum)
+	}
+
+	if request.Body != nil && request.Body != http.NoBody {
+		total := request.ContentLength
+		if total == 0 {
+			total = -1
+		}
+
+		request.Body = newProgressReader(request.Body, total, progress)
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return getError(response)
+}
+
+func (c *client) copyIn(logger lager.Logger, destHandle string, path string, sourceHandle string, sourcePath string, leaseToken string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.CopyIn, rata.Params{
+		"handle": destHandle,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	request.URL.RawQuery = url.Values{
+		"path":        []string{path},
+		"source":      []string{sourceHandle},
+		"source-path": []string{sourcePath},
+	}.Encode()
+
+	setLeaseToken(request, leaseToken)
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return getError(response)
+}
+
+func (c *client) streamOut(logger lager.Logger, srcHandle string, path string, progress baggageclaim.ProgressFunc, sparse bool, canonical bool, offset int64) (io.ReadCloser, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.StreamOut, rata.Params{
+		"handle": srcHandle,
+	}, nil)
+
+	query := url.Values{"path": []string{path}, "format": []string{"tar"}}
+	if sparse {
+		query.Set("sparse", "true")
+	}
+
+	if canonical {
+		query.Set("canonical", "true")
+	}
+
+	request.URL.RawQuery = query.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// the transport would otherwise ask for gzip on its own, and the tar
+	// stream is often already compressed
+	request.Header.Set("Accept-Encoding", "identity")
+
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		// the stream from offset on
+
+	case http.StatusOK:
+		// servers that can't serve a range send the stream in full
+		if offset > 0 {
+			_, err := io.CopyN(ioutil.Discard, response.Body, offset)
+			if err != nil {
+				response.Body.Close()
+				return nil, err
+			}
+		}
+
+	default:
+		defer response.Body.Close()
+		return nil, getError(response)
+	}
+
+	return newProgressReader(response.Body, response.ContentLength, progress), nil
+}
+
+func getError(response *http.Response) error {
+	var errorResponse *api.ErrorResponse
+	err := json.NewDecoder(response.Body).Decode(&errorResponse)
+	if err != nil {
+		return err
+	}
+
+	if errorResponse.Message == api.ErrStreamOutNotFound.Error() {
+		return baggageclaim.ErrFileNotFound
+	}
+
+	if errorResponse.Message == api.ErrRangeNotSatisfiable.Error() {
+		return baggageclaim.ErrRangeNotSatisfiable
+	}
+
+	if errorResponse.Message == volume.ErrPropertyDoesNotExist.Error() {
+		return baggageclaim.ErrPropertyNotFound
+	}
+
+	if errorResponse.Message == volume.ErrPropertyConflict.Error() {
+		return baggageclaim.ErrPropertyConflict
+	}
+
+	if errorResponse.Message == volume.ErrVolumeIsLeased.Error() {
+		return baggageclaim.ErrVolumeIsLeased
+	}
+
+	if errorResponse.Message == volume.ErrLeaseNotHeld.Error() {
+		return baggageclaim.ErrLeaseNotHeld
+	}
+
+	if errorResponse.Message == volume.ErrIdempotencyKeyConflict.Error() {
+		return baggageclaim.ErrIdempotencyKeyConflict
+	}
+
+	if errorResponse.Message == volume.ErrInvalidPropertyName.Error() {
+		return baggageclaim.ErrInvalidPropertyName
+	}
+
+	if errorResponse.Message == volume.ErrPropertyValueTooLarge.Error() {
+		return baggageclaim.ErrPropertyValueTooLarge
+	}
+
+	if errorResponse.Message == volume.ErrStreamChecksumMismatch.Error() {
+		return baggageclaim.ErrStreamChecksumMismatch
+	}
+
+	if errorResponse.Message == volume.ErrVolumeGone.Error() {
+		return baggageclaim.ErrVolumeGone
+	}
+
+	if errorResponse.Message == volume.ErrTooManyStreams.Error() {
+		return baggageclaim.ErrTooManyStreams
+	}
+
+	if errorResponse.Message == volume.ErrSourceVolumeDoesNotExist.Error() {
+		return baggageclaim.ErrSourceVolumeNotFound
+	}
+
+	if response.StatusCode == http.StatusRequestEntityTooLarge {
+		return baggageclaim.ErrStreamTooLarge
+	}
+
+	if response.StatusCode == 404 {
+		return baggageclaim.ErrVolumeNotFound
+	}
+
+	if len(errorResponse.Fields) > 0 {
+		return baggageclaim.InvalidRequestError{
+			Message: errorResponse.Message,
+			Fields:  errorResponse.Fields,
+		}
+	}
+
+	return errors.New(errorResponse.Message)
+}
+
+func (c *client) getVolumeResponse(logger lager.Logger, handle string) (baggageclaim.VolumeResponse, bool, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetVolume, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return baggageclaim.VolumeResponse{}, false, err
+	}
+
+	acceptGob(request)
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return baggageclaim.VolumeResponse{}, false, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		if response.StatusCode == http.StatusNotFound {
+			return baggageclaim.VolumeResponse{}, false, nil
+		}
+
+		return baggageclaim.VolumeResponse{}, false, getError(response)
+	}
+
+	var volumeResponse baggageclaim.VolumeResponse
+	err = decodeReadResponse(response, &volumeResponse)
+	if err != nil {
+		return baggageclaim.VolumeResponse{}, false, err
+	}
+
+	return volumeResponse, true, nil
+}
+
+func (c *client) getVolumeStatsResponse(logger lager.Logger, handle string) (baggageclaim.VolumeStatsResponse, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetVolumeStats, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return baggageclaim.VolumeStatsResponse{}, err
+	}
+
+	acceptGob(request)
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return baggageclaim.VolumeStatsResponse{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		if response.StatusCode == http.StatusNotFound {
+			return baggageclaim.VolumeStatsResponse{}, nil
+		}
+		return baggageclaim.VolumeStatsResponse{}, getError(response)
+	}
+
+	var volumeStatsResponse baggageclaim.VolumeStatsResponse
+	err = decodeReadResponse(response, &volumeStatsResponse)
+	if err != nil {
+		return baggageclaim.VolumeStatsResponse{}, err
+	}
+
+	return volumeStatsResponse, nil
+}
+
+func (c *client) getDigest(logger lager.Logger, handle string) (string, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetDigest, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", getError(response)
+	}
+
+	var digestResponse baggageclaim.VolumeDigestResponse
+	err = json.NewDecoder(response.Body).Decode(&digestResponse)
+	if err != nil {
+		return "", err
+	}
+
+	return digestResponse.Digest, nil
+}
+
+func (c *client) getChildren(logger lager.Logger, handle string) ([]string, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetChildren, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, getError(response)
+	}
+
+	var children []string
+	err = json.NewDecoder(response.Body).Decode(&children)
+	if err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
+
+func (c *client) getManifest(logger lager.Logger, handle string, path string) ([]baggageclaim.ManifestEntry, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetManifest, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.URL.RawQuery = url.Values{"path": []string{path}}.Encode()
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, getError(response)
+	}
+
+	entries := []baggageclaim.ManifestEntry{}
+
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var entry baggageclaim.ManifestEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+func (c *client) listFiles(logger lager.Logger, handle string, path string) ([]baggageclaim.FileEntry, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.ListFiles, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.URL.RawQuery = url.Values{"path": []string{path}}.Encode()
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, getError(response)
+	}
+
+	var files []baggageclaim.FileEntry
+	err = json.NewDecoder(response.Body).Decode(&files)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// acceptGob asks for the gob encoding, which is much cheaper to decode than
+// JSON; servers that do not support it keep sending JSON.
+func acceptGob(request *http.Request) {
+	request.Header.Set("Accept", baggageclaim.GobContentType+", application/json")
+}
+
+// decodeReadResponse decodes the response of a read endpoint as whichever
+// encoding the server chose.
+func decodeReadResponse(response *http.Response, v interface{}) error {
+	switch header := response.Header.Get("Content-Type"); header {
+	case baggageclaim.GobContentType:
+		return gob.NewDecoder(response.Body).Decode(v)
+	case "application/json":
+		return json.NewDecoder(response.Body).Decode(v)
+	default:
+		return fmt.Errorf("unexpected content-type of: %s", header)
+	}
+}
+
+func (c *client) setTTL(logger lager.Logger, handle string, ttl time.Duration) error {
+	return c.putTTL(logger, handle, baggageclaim.TTLRequest{
+		Value: uint(math.Ceil(ttl.Seconds())),
+	})
+}
+
+func (c *client) setExpiresAt(logger lager.Logger, handle string, expiresAt time.Time) error {
+	return c.putTTL(logger, handle, baggageclaim.TTLRequest{
+		ExpiresAt: &expiresAt,
+	})
+}
+
+func (c *client) putTTL(logger lager.Logger, handle string, ttlRequest baggageclaim.TTLRequest) error {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(ttlRequest)
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetTTL, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) destroy(logger lager.Logger, handle string, leaseToken string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.DestroyVolume, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	setLeaseToken(request, leaseToken)
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) setPrivileged(logger lager.Logger, handle string, privileged bool) error {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(baggageclaim.PrivilegedRequest{
+		Value: privileged,
+	})
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetPrivileged, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) setSELinuxLabel(logger lager.Logger, handle string, label string) error {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(baggageclaim.SELinuxLabelRequest{
+		Label: label,
+	})
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetSELinuxLabel, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) touchAccess(logger lager.Logger, handle string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.TouchAccess, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) materialize(logger lager.Logger, handle string) (bool, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.Materialize, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return false, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return false, getError(response)
+	}
+
+	var materializeResponse baggageclaim.MaterializeResponse
+	err = json.NewDecoder(response.Body).Decode(&materializeResponse)
+	if err != nil {
+		return false, err
+	}
+
+	return materializeResponse.Mounted, nil
+}
+
+func (c *client) acquireLease(logger lager.Logger, handle string, token string, ttl time.Duration) (baggageclaim.LeaseResponse, error) {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(baggageclaim.LeaseRequest{
+		TTL:   uint(math.Ceil(ttl.Seconds())),
+		Token: token,
+	})
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.AcquireLease, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return baggageclaim.LeaseResponse{}, err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return baggageclaim.LeaseResponse{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return baggageclaim.LeaseResponse{}, getError(response)
+	}
+
+	var leaseResponse baggageclaim.LeaseResponse
+	err = json.NewDecoder(response.Body).Decode(&leaseResponse)
+	if err != nil {
+		return baggageclaim.LeaseResponse{}, err
+	}
+
+	return leaseResponse, nil
+}
+
+func (c *client) releaseLease(logger lager.Logger, handle string, token string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.ReleaseLease, rata.Params{
+		"handle": handle,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	setLeaseToken(request, token)
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func setLeaseToken(request *http.Request, leaseToken string) {
+	if leaseToken != "" {
+		request.Header.Set(baggageclaim.LeaseTokenHeader, leaseToken)
+	}
+}
+
+func (c *client) commit(logger lager.Logger, handle string, freeze bool) error {
 	buffer := &bytes.Buffer{}
-	json.NewEncoder(buffer).Encode(baggageclaim.PropertyRequest{
-		Value: propertyValue,
+	json.NewEncoder(buffer).Encode(baggageclaim.CommitRequest{
+		Freeze: freeze,
 	})
 
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.CommitVolume, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) setProperties(logger lager.Logger, handle string, properties baggageclaim.VolumeProperties, leaseToken string) error {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(properties)
+
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetProperties, rata.Params{
+		"handle": handle,
+	}, buffer)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-type", "application/json")
+	setLeaseToken(request, leaseToken)
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) deleteProperty(logger lager.Logger, handle string, propertyName string, leaseToken string) error {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.DeleteProperty, rata.Params{
+		"handle":   handle,
+		"property": propertyName,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	setLeaseToken(request, leaseToken)
+
+	response, err := c.httpClient(logger).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 204 {
+		return getError(response)
+	}
+
+	return nil
+}
+
+func (c *client) getProperty(logger lager.Logger, handle string, propertyName string) (string, bool, error) {
+	request, err := c.requestGenerator.CreateRequest(baggageclaim.GetProperty, rata.Params{
+		"handle":   handle,
+		"property": propertyName,
+	}, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	response, err := c.doIdempotent(logger, request)
+	if err != nil {
+		return "", false, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := getError(response)
+		if err == baggageclaim.ErrPropertyNotFound {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	var propertyResponse baggageclaim.PropertyResponse
+	err = json.NewDecoder(response.Body).Decode(&propertyResponse)
+	if err != nil {
+		return "", false, err
+	}
+
+	return propertyResponse.Value, true, nil
+}
+
+func (c *client) setProperty(logger lager.Logger, handle string, propertyName string, propertyRequest baggageclaim.PropertyRequest, leaseToken string) error {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(propertyRequest)
+
 	request, err := c.requestGenerator.CreateRequest(baggageclaim.SetProperty, rata.Params{
 		"handle":   handle,
 		"property": propertyName,
@@ -422,7 +1356,18 @@ func (c *client) setProperty(logger lager.Logger, handle string, propertyName st
 		return err
 	}
 
-	response, err := c.httpClient(logger).Do(request)
+	setLeaseToken(request, leaseToken)
+
+	var response *http.Response
+
+	// were a conditional set to have gone through, sending it again would
+	// find the property changed from under it
+	if propertyRequest.Expected != nil || propertyRequest.ExpectAbsent {
+		response, err = c.httpClient(logger).Do(request)
+	} else {
+		response, err = c.doIdempotent(logger, request)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"io"
+	"time"
+
+	"github.com/concourse/baggageclaim"
+)
+
+// progressInterval is the least time between calls to a ProgressFunc, so
+// that a slow one is not called for every read of a fast stream.
+const progressInterval = 250 * time.Millisecond
+
+type progressReader struct {
+	io.ReadCloser
+
+	total    int64
+	progress baggageclaim.ProgressFunc
+
+	transferred int64
+	reported    time.Time
+	finished    bool
+}
+
+// newProgressReader counts the bytes read from the reader, reporting them to
+// progress. The reader is returned as it is if progress is nil.
+func newProgressReader(reader io.ReadCloser, total int64, progress baggageclaim.ProgressFunc) io.ReadCloser {
+	if progress == nil {
+		return reader
+	}
+
+	return &progressReader{
+		ReadCloser: reader,
+
+		total:    total,
+		progress: progress,
+
+		reported: time.Now(),
+	}
+}
+
+func (reader *progressReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	reader.transferred += int64(n)
+
+	if err == io.EOF {
+		if !reader.finished {
+			reader.finished = true
+			reader.progress(reader.transferred, reader.total)
+		}
+
+		return n, err
+	}
+
+	if n > 0 {
+		now := time.Now()
+		if now.Sub(reader.reported) >= progressInterval {
+			reader.reported = now
+			reader.progress(reader.transferred, reader.total)
+		}
+	}
+
+	return n, err
+}
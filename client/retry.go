@@ -0,0 +1,98 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// RetryPolicy configures how idempotent requests - looking up, listing,
+// promoting, and destroying volumes, creating them with an idempotency key,
+// and setting their properties unconditionally - are retried when they fail with a connection error or
+// one of the retryable status codes. Other requests, streams among them, are
+// never retried, as their bodies can't be sent again.
+//
+// The zero value retries nothing.
+type RetryPolicy struct {
+	// Retries is the number of times a request is sent again after its first
+	// attempt fails.
+	Retries int
+
+	// BaseDelay is how long to wait before the first retry, doubling before
+	// each one after.
+	BaseDelay time.Duration
+
+	// RetryableStatusCodes are the response statuses to retry on. Without
+	// any, only connection errors are retried.
+	RetryableStatusCodes []int
+}
+
+func (policy RetryPolicy) delay(retry int) time.Duration {
+	return policy.BaseDelay << uint(retry)
+}
+
+func (policy RetryPolicy) retryable(statusCode int) bool {
+	for _, code := range policy.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// doIdempotent sends the request, sending it again as the retry policy
+// allows. The request's body, if it has one, must be replayable.
+func (c *client) doIdempotent(logger lager.Logger, request *http.Request) (*http.Response, error) {
+	httpClient := c.httpClient(logger)
+
+	for retry := 0; ; retry++ {
+		response, err := httpClient.Do(request)
+
+		if retry >= c.retryPolicy.Retries || (request.Body != nil && request.GetBody == nil) {
+			return response, err
+		}
+
+		if err == nil && !c.retryPolicy.retryable(response.StatusCode) {
+			return response, nil
+		}
+
+		if err != nil && request.Context().Err() != nil {
+			return nil, err
+		}
+
+		delay := c.retryPolicy.delay(retry)
+
+		data := lager.Data{"method": request.Method, "path": request.URL.Path, "retry": retry + 1, "delay": delay.String()}
+		if err != nil {
+			data["error"] = err.Error()
+		} else {
+			data["status"] = response.StatusCode
+
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		logger.Info("retrying", data)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		}
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			request.Body = body
+		}
+	}
+}
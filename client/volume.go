@@ -41,6 +41,28 @@ func (cv *clientVolume) SizeInBytes() (int64, error) {
 	return stats.SizeInBytes, nil
 }
 
+func (cv *clientVolume) QuotaInBytes() (int64, error) {
+	stats, err := cv.bcClient.getVolumeStatsResponse(cv.logger, cv.handle)
+	if err != nil {
+		return 0, err
+	}
+
+	return stats.QuotaInBytes, nil
+}
+
+func (cv *clientVolume) FileCount() (int64, error) {
+	stats, err := cv.bcClient.getVolumeStatsResponse(cv.logger, cv.handle)
+	if err != nil {
+		return 0, err
+	}
+
+	return stats.FileCount, nil
+}
+
+func (cv *clientVolume) Stats() (baggageclaim.VolumeStatsResponse, error) {
+	return cv.bcClient.getVolumeStatsResponse(cv.logger, cv.handle)
+}
+
 func (cv *clientVolume) Properties() (baggageclaim.VolumeProperties, error) {
 	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
 	if err != nil {
@@ -53,6 +75,66 @@ func (cv *clientVolume) Properties() (baggageclaim.VolumeProperties, error) {
 	return vr.Properties, nil
 }
 
+func (cv *clientVolume) Property(name string) (string, bool, error) {
+	return cv.bcClient.getProperty(cv.logger, cv.handle, name)
+}
+
+func (cv *clientVolume) RecordedDigest() (string, error) {
+	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", volume.ErrVolumeDoesNotExist
+	}
+
+	return vr.Digest, nil
+}
+
+func (cv *clientVolume) Digest() (string, error) {
+	return cv.bcClient.getDigest(cv.logger, cv.handle)
+}
+
+func (cv *clientVolume) ParentHandle() (string, error) {
+	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", volume.ErrVolumeDoesNotExist
+	}
+
+	return vr.ParentHandle, nil
+}
+
+func (cv *clientVolume) MountOptions() ([]string, error) {
+	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, volume.ErrVolumeDoesNotExist
+	}
+
+	return vr.MountOptions, nil
+}
+
+func (cv *clientVolume) Encrypted() (bool, error) {
+	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, volume.ErrVolumeDoesNotExist
+	}
+
+	return vr.Encrypted, nil
+}
+
+func (cv *clientVolume) Children() ([]string, error) {
+	return cv.bcClient.getChildren(cv.logger, cv.handle)
+}
+
 func (cv *clientVolume) Expiration() (time.Duration, time.Time, error) {
 	vr, found, err := cv.bcClient.getVolumeResponse(cv.logger, cv.handle)
 	if err != nil {
@@ -66,27 +148,138 @@ func (cv *clientVolume) Expiration() (time.Duration, time.Time, error) {
 }
 
 func (cv *clientVolume) StreamIn(path string, tarStream io.Reader) error {
-	return cv.bcClient.streamIn(cv.logger, cv.handle, path, tarStream)
+	return cv.bcClient.streamIn(cv.logger, cv.handle, path, tarStream, nil, false, false, "", "")
+}
+
+func (cv *clientVolume) StreamInDelta(path string, tarStream io.Reader) error {
+	return cv.bcClient.streamIn(cv.logger, cv.handle, path, tarStream, nil, true, false, "", "")
+}
+
+func (cv *clientVolume) StreamInLayer(path string, layer io.Reader) error {
+	return cv.bcClient.streamIn(cv.logger, cv.handle, path, layer, nil, false, true, "", "")
+}
+
+func (cv *clientVolume) StreamInWithChecksum(path string, tarStream io.Reader, checksum string) error {
+	return cv.bcClient.streamIn(cv.logger, cv.handle, path, tarStream, nil, false, false, "", checksum)
+}
+
+func (cv *clientVolume) CopyFrom(path string, sourceHandle string, sourcePath string) error {
+	return cv.bcClient.copyIn(cv.logger, cv.handle, path, sourceHandle, sourcePath, "")
+}
+
+func (cv *clientVolume) Manifest(path string) ([]baggageclaim.ManifestEntry, error) {
+	return cv.bcClient.getManifest(cv.logger, cv.handle, path)
+}
+
+func (cv *clientVolume) ListFiles(path string) ([]baggageclaim.FileEntry, error) {
+	return cv.bcClient.listFiles(cv.logger, cv.handle, path)
 }
 
 func (cv *clientVolume) StreamOut(path string) (io.ReadCloser, error) {
-	return cv.bcClient.streamOut(cv.logger, cv.handle, path)
+	return cv.bcClient.streamOut(cv.logger, cv.handle, path, nil, false, false, 0)
+}
+
+func (cv *clientVolume) StreamOutSparse(path string) (io.ReadCloser, error) {
+	return cv.bcClient.streamOut(cv.logger, cv.handle, path, nil, true, false, 0)
+}
+
+func (cv *clientVolume) StreamInWithProgress(path string, tarStream io.Reader, progress baggageclaim.ProgressFunc) error {
+	return cv.bcClient.streamIn(cv.logger, cv.handle, path, tarStream, progress, false, false, "", "")
+}
+
+func (cv *clientVolume) StreamOutCanonical(path string) (io.ReadCloser, error) {
+	return cv.bcClient.streamOut(cv.logger, cv.handle, path, nil, false, true, 0)
+}
+
+func (cv *clientVolume) StreamOutFrom(path string, offset int64) (io.ReadCloser, error) {
+	return cv.bcClient.streamOut(cv.logger, cv.handle, path, nil, false, false, offset)
+}
+
+func (cv *clientVolume) StreamOutWithProgress(path string, progress baggageclaim.ProgressFunc) (io.ReadCloser, error) {
+	return cv.bcClient.streamOut(cv.logger, cv.handle, path, progress, false, false, 0)
+}
+
+func (cv *clientVolume) TouchAccess() error {
+	return cv.bcClient.touchAccess(cv.logger, cv.handle)
+}
+
+func (cv *clientVolume) Materialize() (bool, error) {
+	return cv.bcClient.materialize(cv.logger, cv.handle)
+}
+
+func (cv *clientVolume) Commit(freeze bool) error {
+	return cv.bcClient.commit(cv.logger, cv.handle, freeze)
 }
 
 func (cv *clientVolume) SetTTL(ttl time.Duration) error {
 	return cv.bcClient.setTTL(cv.logger, cv.handle, ttl)
 }
 
+func (cv *clientVolume) SetExpiresAt(expiresAt time.Time) error {
+	return cv.bcClient.setExpiresAt(cv.logger, cv.handle, expiresAt)
+}
+
 func (cv *clientVolume) SetPrivileged(privileged bool) error {
 	return cv.bcClient.setPrivileged(cv.logger, cv.handle, privileged)
 }
 
+func (cv *clientVolume) SetSELinuxLabel(label string) error {
+	return cv.bcClient.setSELinuxLabel(cv.logger, cv.handle, label)
+}
+
 func (cv *clientVolume) Destroy() error {
-	return cv.bcClient.destroy(cv.logger, cv.handle)
+	return cv.bcClient.destroy(cv.logger, cv.handle, "")
 }
 
 func (cv *clientVolume) SetProperty(name string, value string) error {
-	return cv.bcClient.setProperty(cv.logger, cv.handle, name, value)
+	return cv.bcClient.setProperty(cv.logger, cv.handle, name, baggageclaim.PropertyRequest{
+		Value: value,
+	}, "")
+}
+
+func (cv *clientVolume) CompareAndSetProperty(name string, expected *string, value string) error {
+	return cv.bcClient.setProperty(cv.logger, cv.handle, name, baggageclaim.PropertyRequest{
+		Value:        value,
+		Expected:     expected,
+		ExpectAbsent: expected == nil,
+	}, "")
+}
+
+func (cv *clientVolume) SetProperties(properties baggageclaim.VolumeProperties) error {
+	return cv.bcClient.setProperties(cv.logger, cv.handle, properties, "")
+}
+
+func (cv *clientVolume) DeleteProperty(name string) error {
+	return cv.bcClient.deleteProperty(cv.logger, cv.handle, name, "")
+}
+
+func (cv *clientVolume) AcquireLease(ttl time.Duration) (string, time.Time, error) {
+	lease, err := cv.bcClient.acquireLease(cv.logger, cv.handle, "", ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return lease.Token, lease.ExpiresAt, nil
+}
+
+func (cv *clientVolume) RenewLease(token string, ttl time.Duration) (time.Time, error) {
+	lease, err := cv.bcClient.acquireLease(cv.logger, cv.handle, token, ttl)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return lease.ExpiresAt, nil
+}
+
+func (cv *clientVolume) ReleaseLease(token string) error {
+	return cv.bcClient.releaseLease(cv.logger, cv.handle, token)
+}
+
+func (cv *clientVolume) WithLease(token string) baggageclaim.Volume {
+	return &leasedVolume{
+		clientVolume: cv,
+		token:        token,
+	}
 }
 
 func (cv *clientVolume) Release(finalTTL *time.Duration) {
@@ -162,3 +355,62 @@ func (cv *clientVolume) heartbeatTick(logger lager.Logger, ttl time.Duration) bo
 
 	return true
 }
+
+// leasedVolume makes the stream-ins, copies, property changes, and destroys
+// of the volume with the token of the lease held on it, leaving the rest to
+// the clientVolume it wraps.
+type leasedVolume struct {
+	*clientVolume
+
+	token string
+}
+
+func (lv *leasedVolume) StreamIn(path string, tarStream io.Reader) error {
+	return lv.bcClient.streamIn(lv.logger, lv.handle, path, tarStream, nil, false, false, lv.token, "")
+}
+
+func (lv *leasedVolume) StreamInDelta(path string, tarStream io.Reader) error {
+	return lv.bcClient.streamIn(lv.logger, lv.handle, path, tarStream, nil, true, false, lv.token, "")
+}
+
+func (lv *leasedVolume) StreamInLayer(path string, layer io.Reader) error {
+	return lv.bcClient.streamIn(lv.logger, lv.handle, path, layer, nil, false, true, lv.token, "")
+}
+
+func (lv *leasedVolume) StreamInWithChecksum(path string, tarStream io.Reader, checksum string) error {
+	return lv.bcClient.streamIn(lv.logger, lv.handle, path, tarStream, nil, false, false, lv.token, checksum)
+}
+
+func (lv *leasedVolume) CopyFrom(path string, sourceHandle string, sourcePath string) error {
+	return lv.bcClient.copyIn(lv.logger, lv.handle, path, sourceHandle, sourcePath, lv.token)
+}
+
+func (lv *leasedVolume) StreamInWithProgress(path string, tarStream io.Reader, progress baggageclaim.ProgressFunc) error {
+	return lv.bcClient.streamIn(lv.logger, lv.handle, path, tarStream, progress, false, false, lv.token, "")
+}
+
+func (lv *leasedVolume) SetProperty(name string, value string) error {
+	return lv.bcClient.setProperty(lv.logger, lv.handle, name, baggageclaim.PropertyRequest{
+		Value: value,
+	}, lv.token)
+}
+
+func (lv *leasedVolume) CompareAndSetProperty(name string, expected *string, value string) error {
+	return lv.bcClient.setProperty(lv.logger, lv.handle, name, baggageclaim.PropertyRequest{
+		Value:        value,
+		Expected:     expected,
+		ExpectAbsent: expected == nil,
+	}, lv.token)
+}
+
+func (lv *leasedVolume) SetProperties(properties baggageclaim.VolumeProperties) error {
+	return lv.bcClient.setProperties(lv.logger, lv.handle, properties, lv.token)
+}
+
+func (lv *leasedVolume) DeleteProperty(name string) error {
+	return lv.bcClient.deleteProperty(lv.logger, lv.handle, name, lv.token)
+}
+
+func (lv *leasedVolume) Destroy() error {
+	return lv.bcClient.destroy(lv.logger, lv.handle, lv.token)
+}
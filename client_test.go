@@ -1,6 +1,7 @@
 package baggageclaim_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -183,6 +184,47 @@ var _ = Describe("Baggage Claim Client", func() {
 			})
 		})
 
+		Describe("Checking whether a volume exists", func() {
+			It("reports that it does without fetching it", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("HEAD", "/volumes/some-handle"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+				)
+
+				exists, err := bcClient.VolumeExists(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exists).To(BeTrue())
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(1))
+			})
+
+			It("reports that it does not when it is not found", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("HEAD", "/volumes/some-handle"),
+						ghttp.RespondWith(http.StatusNotFound, ""),
+					),
+				)
+
+				exists, err := bcClient.VolumeExists(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exists).To(BeFalse())
+			})
+
+			It("returns an error with the status when the check fails", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("HEAD", "/volumes/some-handle"),
+						ghttp.RespondWith(http.StatusInternalServerError, ""),
+					),
+				)
+
+				_, err := bcClient.VolumeExists(logger, "some-handle")
+				Expect(err).To(MatchError(ContainSubstring("500")))
+			})
+		})
+
 		Describe("Listing volumes", func() {
 			Context("when the inital heartbeat fails for a volume", func() {
 				It("it is omitted from the returned list of volumes", func() {
@@ -237,6 +279,62 @@ var _ = Describe("Baggage Claim Client", func() {
 			})
 		})
 
+		Describe("Listing a page of volumes", func() {
+			It("asks for the page and returns how many volumes there are", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes", "kind=some&limit=1&offset=2&sort=created-at"),
+						ghttp.RespondWithJSONEncoded(200, []volume.Volume{
+							{
+								Handle:     "some-handle",
+								Path:       "some-path",
+								Properties: volume.Properties{"kind": "some"},
+							},
+						}, http.Header{baggageclaim.VolumeCountHeader: {"5"}}),
+					),
+				)
+
+				volumes, total, err := bcClient.ListVolumesPage(logger, baggageclaim.VolumeProperties{"kind": "some"}, baggageclaim.ListVolumesOptions{
+					Limit:  1,
+					Offset: 2,
+					Sort:   baggageclaim.SortByCreatedAt,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(total).To(Equal(5))
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].Handle()).To(Equal("some-handle"))
+			})
+		})
+
+		Describe("Destroying volumes in bulk", func() {
+			It("returns the volumes that could not be destroyed", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes/destroy"),
+						ghttp.VerifyJSON(`["some-handle", "another-handle"]`),
+						ghttp.RespondWithJSONEncoded(200, []baggageclaim.DestroyVolumesResult{
+							{Handle: "some-handle"},
+							{Handle: "another-handle", Error: "failed to destroy volume"},
+						}),
+					),
+				)
+
+				failed, err := bcClient.DestroyVolumes(logger, []string{"some-handle", "another-handle"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failed).To(HaveLen(1))
+				Expect(failed).To(HaveKeyWithValue("another-handle", MatchError("failed to destroy volume")))
+			})
+
+			Context("when unexpected error occurs", func() {
+				It("returns error code and useful message", func() {
+					mockErrorResponse("POST", "/volumes/destroy", "lost baggage", http.StatusInternalServerError)
+					failed, err := bcClient.DestroyVolumes(logger, []string{"some-handle"})
+					Expect(failed).To(BeNil())
+					Expect(err).To(MatchError("lost baggage"))
+				})
+			})
+		})
+
 		Describe("Creating volumes", func() {
 			Context("when the inital heartbeat fails for the volume", func() {
 				It("reports that the volume could not be found", func() {
@@ -286,6 +384,164 @@ var _ = Describe("Baggage Claim Client", func() {
 				})
 			})
 
+			Context("when given mount options", func() {
+				It("asks for them, and reports those the server applied", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes"),
+							func(w http.ResponseWriter, r *http.Request) {
+								var request baggageclaim.VolumeRequest
+								Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+								Expect(request.MountOptions).To(Equal([]string{"noatime", "nodev"}))
+							},
+							ghttp.RespondWithJSONEncoded(201, volume.Volume{
+								Handle:       "some-handle",
+								Path:         "some-path",
+								Properties:   volume.Properties{},
+								MountOptions: []string{"noatime", "nodev"},
+							}),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/volumes/some-handle"),
+							ghttp.RespondWithJSONEncoded(200, baggageclaim.VolumeResponse{
+								Handle:       "some-handle",
+								Path:         "some-path",
+								MountOptions: []string{"noatime", "nodev"},
+							}),
+						),
+					)
+
+					createdVolume, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{
+						MountOptions: []string{"noatime", "nodev"},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(createdVolume.MountOptions()).To(Equal([]string{"noatime", "nodev"}))
+				})
+			})
+
+			Context("when the volume is to be encrypted", func() {
+				It("asks for it, and reports it as encrypted", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes"),
+							func(w http.ResponseWriter, r *http.Request) {
+								var request baggageclaim.VolumeRequest
+								Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+								Expect(request.Encrypted).To(BeTrue())
+							},
+							ghttp.RespondWithJSONEncoded(201, volume.Volume{
+								Handle:     "some-handle",
+								Path:       "some-path",
+								Properties: volume.Properties{},
+								Encrypted:  true,
+							}),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/volumes/some-handle"),
+							ghttp.RespondWithJSONEncoded(200, baggageclaim.VolumeResponse{
+								Handle:    "some-handle",
+								Path:      "some-path",
+								Encrypted: true,
+							}),
+						),
+					)
+
+					createdVolume, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{
+						Encrypted: true,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					encrypted, err := createdVolume.Encrypted()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(encrypted).To(BeTrue())
+				})
+			})
+
+			Context("when the volume is to renew its TTL on access", func() {
+				It("asks for it", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes"),
+							func(w http.ResponseWriter, r *http.Request) {
+								var request baggageclaim.VolumeRequest
+								Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+								Expect(request.RenewTTLOnAccess).To(BeTrue())
+							},
+							ghttp.RespondWithJSONEncoded(201, volume.Volume{
+								Handle:           "some-handle",
+								Path:             "some-path",
+								Properties:       volume.Properties{},
+								RenewTTLOnAccess: true,
+							}),
+						),
+					)
+
+					_, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{
+						RenewTTLOnAccess: true,
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when given an idempotency key", func() {
+				It("asks for it", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes"),
+							func(w http.ResponseWriter, r *http.Request) {
+								var request baggageclaim.VolumeRequest
+								Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+								Expect(request.IdempotencyKey).To(Equal("some-key"))
+							},
+							ghttp.RespondWithJSONEncoded(201, volume.Volume{
+								Handle:     "some-handle",
+								Path:       "some-path",
+								Properties: volume.Properties{},
+							}),
+						),
+					)
+
+					_, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{
+						IdempotencyKey: "some-key",
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns ErrIdempotencyKeyConflict when the key made another volume", func() {
+					mockErrorResponse("POST", "/volumes", volume.ErrIdempotencyKeyConflict.Error(), http.StatusConflict)
+
+					_, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{
+						IdempotencyKey: "some-key",
+					})
+					Expect(err).To(Equal(baggageclaim.ErrIdempotencyKeyConflict))
+				})
+			})
+
+			Context("when the server finds fault with the request's fields", func() {
+				It("returns an InvalidRequestError listing them", func() {
+					fields := []baggageclaim.FieldError{
+						{Field: "strategy.volume", Code: baggageclaim.FieldErrorRequired, Message: "no parent volume provided"},
+					}
+
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes"),
+							func(w http.ResponseWriter, r *http.Request) {
+								api.RespondWithFieldErrors(w, api.ErrCreateVolumeFailed, fields, 422)
+							},
+						),
+					)
+
+					_, err := bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
+					Expect(err).To(Equal(baggageclaim.InvalidRequestError{
+						Message: api.ErrCreateVolumeFailed.Error(),
+						Fields:  fields,
+					}))
+					Expect(err.Error()).To(ContainSubstring("strategy.volume: no parent volume provided"))
+				})
+			})
+
 			Context("when unexpected error occurs", func() {
 				It("returns error code and useful message", func() {
 					mockErrorResponse("POST", "/volumes", "lost baggage", http.StatusInternalServerError)
@@ -297,126 +553,149 @@ var _ = Describe("Baggage Claim Client", func() {
 			})
 		})
 
-		Describe("Stream in a volume", func() {
-			var vol baggageclaim.Volume
-			BeforeEach(func() {
+		Describe("Cloning volumes", func() {
+			It("asks for a clone with the handle", func() {
 				bcServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("POST", "/volumes"),
+						ghttp.VerifyRequest("POST", "/volumes/source-handle/clone"),
+						ghttp.VerifyJSONRepresenting(baggageclaim.CloneVolumeRequest{Handle: "clone-handle"}),
 						ghttp.RespondWithJSONEncoded(201, volume.Volume{
-							Handle:     "some-handle",
+							Handle:     "clone-handle",
 							Path:       "some-path",
 							Properties: volume.Properties{},
-							TTL:        volume.TTL(1),
+							TTL:        volume.TTL(0),
 							ExpiresAt:  time.Now().Add(time.Second),
 						}),
 					),
-					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
-						ghttp.RespondWith(http.StatusNoContent, ""),
-					),
 				)
-				var err error
-				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
-				Expect(err).ToNot(HaveOccurred())
+
+				clonedVolume, err := bcClient.CloneVolume(logger, "source-handle", "clone-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(clonedVolume.Handle()).To(Equal("clone-handle"))
 			})
 
-			It("streams the volume", func() {
-				bodyChan := make(chan []byte, 1)
+			Context("when the source does not exist", func() {
+				It("returns ErrVolumeNotFound", func() {
+					mockErrorResponse("POST", "/volumes/source-handle/clone", "failed to clone volume", http.StatusNotFound)
+					clonedVolume, err := bcClient.CloneVolume(logger, "source-handle", "clone-handle")
+					Expect(clonedVolume).To(BeNil())
+					Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+				})
+			})
+		})
 
+		Describe("Renaming volumes", func() {
+			It("asks for the volume to be given the handle", func() {
 				bcServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in"),
-						func(w http.ResponseWriter, r *http.Request) {
-							str, _ := ioutil.ReadAll(r.Body)
-							bodyChan <- str
-						},
-						ghttp.RespondWith(http.StatusNoContent, ""),
+						ghttp.VerifyRequest("POST", "/volumes/some-handle/rename"),
+						ghttp.VerifyJSONRepresenting(baggageclaim.RenameVolumeRequest{Handle: "new-handle"}),
+						ghttp.RespondWithJSONEncoded(200, volume.Volume{
+							Handle:     "new-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(0),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
 					),
 				)
-				err := vol.StreamIn(".", strings.NewReader("some tar content"))
-				Expect(err).ToNot(HaveOccurred())
 
-				Expect(bodyChan).To(Receive(Equal([]byte("some tar content"))))
+				renamedVolume, err := bcClient.RenameVolume(logger, "some-handle", "new-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(renamedVolume.Handle()).To(Equal("new-handle"))
 			})
 
-			Context("when unexpected error occurs", func() {
-				It("returns error code and useful message", func() {
-					mockErrorResponse("PUT", "/volumes/some-handle/stream-in", "lost baggage", http.StatusInternalServerError)
-					err := vol.StreamIn("./some/path/", strings.NewReader("even more tar"))
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(Equal("lost baggage"))
+			Context("when the handle is taken", func() {
+				It("returns the error", func() {
+					mockErrorResponse("POST", "/volumes/some-handle/rename", "volume already exists", http.StatusConflict)
+					renamedVolume, err := bcClient.RenameVolume(logger, "some-handle", "new-handle")
+					Expect(renamedVolume).To(BeNil())
+					Expect(err).To(MatchError("volume already exists"))
 				})
 			})
 		})
 
-		Describe("Stream out a volume", func() {
-			var vol baggageclaim.Volume
-			BeforeEach(func() {
+		Describe("Promoting volumes", func() {
+			It("asks for the volume to be promoted", func() {
 				bcServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("POST", "/volumes"),
-						ghttp.RespondWithJSONEncoded(201, volume.Volume{
+						ghttp.VerifyRequest("POST", "/volumes/some-handle/promote"),
+						ghttp.RespondWithJSONEncoded(200, volume.Volume{
 							Handle:     "some-handle",
 							Path:       "some-path",
 							Properties: volume.Properties{},
-							TTL:        volume.TTL(1),
+							TTL:        volume.TTL(0),
 							ExpiresAt:  time.Now().Add(time.Second),
 						}),
 					),
-					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
-						ghttp.RespondWith(http.StatusNoContent, ""),
-					),
 				)
-				var err error
-				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
-				Expect(err).ToNot(HaveOccurred())
+
+				promotedVolume, err := bcClient.PromoteVolume(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(promotedVolume.Handle()).To(Equal("some-handle"))
 			})
 
-			It("streams the volume", func() {
+			Context("when the volume does not exist", func() {
+				It("returns ErrVolumeNotFound", func() {
+					mockErrorResponse("POST", "/volumes/some-handle/promote", "failed to promote volume", http.StatusNotFound)
+					promotedVolume, err := bcClient.PromoteVolume(logger, "some-handle")
+					Expect(promotedVolume).To(BeNil())
+					Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+				})
+			})
+		})
+
+		Describe("Restoring volumes", func() {
+			It("asks for the volume to be restored", func() {
 				bcServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out"),
-						func(w http.ResponseWriter, r *http.Request) {
-							w.Write([]byte("some tar content"))
-						},
+						ghttp.VerifyRequest("POST", "/volumes/some-handle/restore"),
+						ghttp.RespondWithJSONEncoded(200, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(0),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
 					),
 				)
-				out, err := vol.StreamOut(".")
-				Expect(err).NotTo(HaveOccurred())
 
-				b, err := ioutil.ReadAll(out)
+				restoredVolume, err := bcClient.RestoreVolume(logger, "some-handle")
 				Expect(err).NotTo(HaveOccurred())
-
-				Expect(string(b)).To(Equal("some tar content"))
+				Expect(restoredVolume.Handle()).To(Equal("some-handle"))
 			})
 
-			Context("when error occurs", func() {
-				It("returns API error message", func() {
-					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", "lost baggage", http.StatusInternalServerError)
-					_, err := vol.StreamOut("./some/path/")
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(Equal("lost baggage"))
+			Context("when the retention window has passed", func() {
+				It("returns ErrVolumeGone", func() {
+					mockErrorResponse("POST", "/volumes/some-handle/restore", "volume was deleted too long ago to be restored", http.StatusGone)
+					restoredVolume, err := bcClient.RestoreVolume(logger, "some-handle")
+					Expect(restoredVolume).To(BeNil())
+					Expect(err).To(Equal(baggageclaim.ErrVolumeGone))
 				})
+			})
+		})
 
-				It("returns ErrVolumeNotFound", func() {
-					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", "lost baggage", http.StatusNotFound)
-					_, err := vol.StreamOut("./some/path/")
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
-				})
+		Describe("Listing deleted volumes", func() {
+			It("asks for them alongside the others, and returns only those deleted", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes", "includeDeleted=true&kind=some"),
+						ghttp.RespondWithJSONEncoded(200, []volume.Volume{
+							{Handle: "live-handle", Properties: volume.Properties{"kind": "some"}},
+							{Handle: "deleted-handle", Properties: volume.Properties{"kind": "some"}, Deleted: true},
+						}),
+					),
+				)
 
-				It("returns ErrFileNotFound", func() {
-					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", api.ErrStreamOutNotFound.Error(), http.StatusNotFound)
-					_, err := vol.StreamOut("./some/path/")
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(baggageclaim.ErrFileNotFound))
-				})
+				deleted, err := bcClient.ListDeletedVolumes(logger, baggageclaim.VolumeProperties{"kind": "some"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(HaveLen(1))
+				Expect(deleted[0].Handle).To(Equal("deleted-handle"))
+				Expect(deleted[0].Deleted).To(BeTrue())
 			})
 		})
 
-		Describe("Setting TTL on a volume", func() {
+		Describe("Stream in a volume", func() {
 			var vol baggageclaim.Volume
 			BeforeEach(func() {
 				bcServer.AppendHandlers(
@@ -440,14 +719,437 @@ var _ = Describe("Baggage Claim Client", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			Context("when error occurs", func() {
-				It("returns API error message", func() {
-					mockErrorResponse("PUT", "/volumes/some-handle/ttl", "lost baggage", http.StatusInternalServerError)
-					err := vol.SetTTL(time.Second)
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(Equal("lost baggage"))
-				})
-
+			It("streams the volume", func() {
+				bodyChan := make(chan []byte, 1)
+
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in"),
+						func(w http.ResponseWriter, r *http.Request) {
+							str, _ := ioutil.ReadAll(r.Body)
+							bodyChan <- str
+						},
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				err := vol.StreamIn(".", strings.NewReader("some tar content"))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(bodyChan).To(Receive(Equal([]byte("some tar content"))))
+			})
+
+			It("reports the bytes sent against the length of the stream", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in"),
+						func(w http.ResponseWriter, r *http.Request) {
+							ioutil.ReadAll(r.Body)
+						},
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				type report struct{ transferred, total int64 }
+				var reports []report
+
+				err := vol.StreamInWithProgress(".", strings.NewReader("some tar content"), func(transferred int64, total int64) {
+					reports = append(reports, report{transferred, total})
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(reports).To(Equal([]report{{16, 16}}))
+			})
+
+			It("streams a delta against the volume's manifest", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle/manifest", "path=some%2Fpath"),
+						ghttp.RespondWith(http.StatusOK, `{"path":"file","type":"file","mode":420,"size":4,"mtime":"1970-01-01T00:16:40Z","sha256":"some-sha"}
+{"path":"link","type":"symlink","mode":511,"mtime":"1970-01-01T00:16:40Z","target":"file"}
+`, http.Header{"Content-Type": []string{"application/x-ndjson"}}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in", "delta=true&path=some%2Fpath"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				entries, err := vol.Manifest("some/path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entries).To(Equal([]baggageclaim.ManifestEntry{
+					{Path: "file", Type: "file", Mode: 0644, Size: 4, ModTime: time.Unix(1000, 0).UTC(), SHA256: "some-sha"},
+					{Path: "link", Type: "symlink", Mode: 0777, ModTime: time.Unix(1000, 0).UTC(), Target: "file"},
+				}))
+
+				err = vol.StreamInDelta("some/path", strings.NewReader("some delta"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("streams an image layer in on top of what is at the path", func() {
+				bodyChan := make(chan []byte, 1)
+
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in", "layer=true&path=."),
+						func(w http.ResponseWriter, r *http.Request) {
+							str, _ := ioutil.ReadAll(r.Body)
+							bodyChan <- str
+						},
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				err := vol.StreamInLayer(".", strings.NewReader("some gzipped layer"))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(bodyChan).To(Receive(Equal([]byte("some gzipped layer"))))
+			})
+
+			It("sends the checksum the stream is to be checked against", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-in", "path=."),
+						ghttp.VerifyHeaderKV(baggageclaim.StreamChecksumHeader, "sha256:some-checksum"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				err := vol.StreamInWithChecksum(".", strings.NewReader("some tar content"), "sha256:some-checksum")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns ErrStreamChecksumMismatch when the stream does not match it", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/stream-in", volume.ErrStreamChecksumMismatch.Error(), 422)
+
+				err := vol.StreamInWithChecksum(".", strings.NewReader("some tar content"), "sha256:some-checksum")
+				Expect(err).To(Equal(baggageclaim.ErrStreamChecksumMismatch))
+			})
+
+			It("returns ErrTooManyStreams when the server is busy with others", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/stream-in", volume.ErrTooManyStreams.Error(), http.StatusServiceUnavailable)
+
+				err := vol.StreamIn(".", strings.NewReader("some tar content"))
+				Expect(err).To(Equal(baggageclaim.ErrTooManyStreams))
+			})
+
+			It("returns ErrStreamTooLarge when the volume has no room for the stream", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/stream-in", volume.StreamTooLargeError{Size: 2048, Room: 1024}.Error(), http.StatusRequestEntityTooLarge)
+
+				err := vol.StreamIn(".", strings.NewReader("some tar content"))
+				Expect(err).To(Equal(baggageclaim.ErrStreamTooLarge))
+			})
+
+			Context("when unexpected error occurs", func() {
+				It("returns error code and useful message", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/stream-in", "lost baggage", http.StatusInternalServerError)
+					err := vol.StreamIn("./some/path/", strings.NewReader("even more tar"))
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("lost baggage"))
+				})
+			})
+		})
+
+		Describe("Copying into a volume from another", func() {
+			var vol baggageclaim.Volume
+			BeforeEach(func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes"),
+						ghttp.RespondWithJSONEncoded(201, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(1),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				var err error
+				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("asks the server to copy the source path into the path", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/copy-in", "path=into&source=source-handle&source-path=some%2Fdir"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				err := vol.CopyFrom("into", "source-handle", "some/dir")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns ErrSourceVolumeNotFound when the source does not exist", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/copy-in", volume.ErrSourceVolumeDoesNotExist.Error(), http.StatusNotFound)
+
+				err := vol.CopyFrom("into", "source-handle", "some/dir")
+				Expect(err).To(Equal(baggageclaim.ErrSourceVolumeNotFound))
+			})
+
+			It("returns ErrFileNotFound when there is nothing at the source path", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/copy-in", api.ErrStreamOutNotFound.Error(), http.StatusNotFound)
+
+				err := vol.CopyFrom("into", "source-handle", "some/dir")
+				Expect(err).To(Equal(baggageclaim.ErrFileNotFound))
+			})
+
+			It("returns ErrVolumeNotFound when the volume does not exist", func() {
+				mockErrorResponse("PUT", "/volumes/some-handle/copy-in", api.ErrCopyInFailed.Error(), http.StatusNotFound)
+
+				err := vol.CopyFrom("into", "source-handle", "some/dir")
+				Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+			})
+		})
+
+		Describe("Stream out a volume", func() {
+			var vol baggageclaim.Volume
+			BeforeEach(func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes"),
+						ghttp.RespondWithJSONEncoded(201, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(1),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				var err error
+				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("streams the volume", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out"),
+						func(w http.ResponseWriter, r *http.Request) {
+							w.Write([]byte("some tar content"))
+						},
+					),
+				)
+				out, err := vol.StreamOut(".")
+				Expect(err).NotTo(HaveOccurred())
+
+				b, err := ioutil.ReadAll(out)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(b)).To(Equal("some tar content"))
+			})
+
+			It("asks for the holes of sparse files to be carried when streaming them", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out", "format=tar&path=.&sparse=true"),
+						ghttp.RespondWith(http.StatusOK, "some tar content"),
+					),
+				)
+
+				out, err := vol.StreamOutSparse(".")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ioutil.ReadAll(out)).To(Equal([]byte("some tar content")))
+			})
+
+			It("asks for a canonical tar when streaming one", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out", "canonical=true&format=tar&path=."),
+						ghttp.RespondWith(http.StatusOK, "some tar content"),
+					),
+				)
+
+				out, err := vol.StreamOutCanonical(".")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ioutil.ReadAll(out)).To(Equal([]byte("some tar content")))
+			})
+
+			It("reports the bytes read against the Content-Length", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out"),
+						ghttp.RespondWith(http.StatusOK, "some tar content", http.Header{"Content-Length": {"16"}}),
+					),
+				)
+
+				type report struct{ transferred, total int64 }
+				var reports []report
+
+				out, err := vol.StreamOutWithProgress(".", func(transferred int64, total int64) {
+					reports = append(reports, report{transferred, total})
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = ioutil.ReadAll(out)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.Close()).To(Succeed())
+
+				Expect(reports).To(Equal([]report{{16, 16}}))
+			})
+
+			Context("when resuming a stream", func() {
+				It("asks for the rest of the stream from the offset", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out"),
+							ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+							ghttp.RespondWith(http.StatusPartialContent, "tar content"),
+						),
+					)
+
+					out, err := vol.StreamOutFrom(".", 5)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ioutil.ReadAll(out)).To(Equal([]byte("tar content")))
+				})
+
+				It("skips to the offset when the server sends the stream in full", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PUT", "/volumes/some-handle/stream-out"),
+							ghttp.RespondWith(http.StatusOK, "some tar content"),
+						),
+					)
+
+					out, err := vol.StreamOutFrom(".", 5)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ioutil.ReadAll(out)).To(Equal([]byte("tar content")))
+				})
+
+				It("returns ErrRangeNotSatisfiable when the stream is no longer than the offset", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", api.ErrRangeNotSatisfiable.Error(), http.StatusRequestedRangeNotSatisfiable)
+
+					_, err := vol.StreamOutFrom(".", 16)
+					Expect(err).To(Equal(baggageclaim.ErrRangeNotSatisfiable))
+				})
+			})
+
+			Context("when error occurs", func() {
+				It("returns API error message", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", "lost baggage", http.StatusInternalServerError)
+					_, err := vol.StreamOut("./some/path/")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("lost baggage"))
+				})
+
+				It("returns ErrVolumeNotFound", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", "lost baggage", http.StatusNotFound)
+					_, err := vol.StreamOut("./some/path/")
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+				})
+
+				It("returns ErrFileNotFound", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/stream-out", api.ErrStreamOutNotFound.Error(), http.StatusNotFound)
+					_, err := vol.StreamOut("./some/path/")
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(baggageclaim.ErrFileNotFound))
+				})
+			})
+		})
+
+		Describe("Committing a volume", func() {
+			var vol baggageclaim.Volume
+			BeforeEach(func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes"),
+						ghttp.RespondWithJSONEncoded(201, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(1),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				var err error
+				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("commits the volume", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes/some-handle/commit"),
+						ghttp.VerifyJSONRepresenting(baggageclaim.CommitRequest{Freeze: true}),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				err := vol.Commit(true)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("when unexpected error occurs", func() {
+				It("returns error code and useful message", func() {
+					mockErrorResponse("POST", "/volumes/some-handle/commit", "lost baggage", http.StatusInternalServerError)
+					err := vol.Commit(false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("lost baggage"))
+				})
+			})
+		})
+
+		Describe("Setting TTL on a volume", func() {
+			var vol baggageclaim.Volume
+			BeforeEach(func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/volumes"),
+						ghttp.RespondWithJSONEncoded(201, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+							TTL:        volume.TTL(1),
+							ExpiresAt:  time.Now().Add(time.Second),
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				var err error
+				vol, err = bcClient.CreateVolume(logger, "some-handle", baggageclaim.VolumeSpec{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("sets when the volume expires", func() {
+				expiresAt := time.Unix(1234, 0).UTC()
+
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/ttl"),
+						ghttp.VerifyJSONRepresenting(baggageclaim.TTLRequest{ExpiresAt: &expiresAt}),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				err := vol.SetExpiresAt(expiresAt)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("when error occurs", func() {
+				It("returns API error message", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/ttl", "lost baggage", http.StatusInternalServerError)
+					err := vol.SetTTL(time.Second)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("lost baggage"))
+				})
+
 				It("returns ErrVolumeNotFound", func() {
 					mockErrorResponse("PUT", "/volumes/some-handle/ttl", "lost baggage", http.StatusNotFound)
 					err := vol.SetTTL(time.Second)
@@ -481,6 +1183,98 @@ var _ = Describe("Baggage Claim Client", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("has the server digest the volume", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle/digest"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.VolumeDigestResponse{Digest: "sha256:some-digest"}),
+					),
+				)
+				digest, err := vol.Digest()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(digest).To(Equal("sha256:some-digest"))
+			})
+
+			It("lists the volume's copy-on-write children", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle/children"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []string{"child-handle"}),
+					),
+				)
+				children, err := vol.Children()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(children).To(Equal([]string{"child-handle"}))
+			})
+
+			It("lists the files in the volume", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle/files", "path=some%2Fpath"),
+						ghttp.RespondWith(http.StatusOK, `[{"path":"file","type":"file","mode":420,"size":4,"mtime":"1970-01-01T00:16:40Z"}]`),
+					),
+				)
+
+				files, err := vol.ListFiles("some/path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(files).To(Equal([]baggageclaim.FileEntry{
+					{Path: "file", Type: "file", Mode: 0644, Size: 4, ModTime: time.Unix(1000, 0).UTC()},
+				}))
+			})
+
+			It("sets several properties in one request", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/properties"),
+						ghttp.VerifyJSONRepresenting(baggageclaim.VolumeProperties{"a": "b", "c": "d"}),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				err := vol.SetProperties(baggageclaim.VolumeProperties{"a": "b", "c": "d"})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("gets one property", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle/properties/key"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.PropertyResponse{Value: "value"}),
+					),
+				)
+				value, found, err := vol.Property("key")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(value).To(Equal("value"))
+			})
+
+			It("tells a missing property apart from a missing volume", func() {
+				mockErrorResponse("GET", "/volumes/some-handle/properties/key", volume.ErrPropertyDoesNotExist.Error(), http.StatusNotFound)
+				_, found, err := vol.Property("key")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
+
+				mockErrorResponse("GET", "/volumes/some-handle/properties/key", api.ErrGetPropertyFailed.Error(), http.StatusNotFound)
+				_, _, err = vol.Property("key")
+				Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+			})
+
+			It("deletes the property", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", "/volumes/some-handle/properties/key"),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+				err := vol.DeleteProperty("key")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns ErrVolumeNotFound when deleting a property from a missing volume", func() {
+				mockErrorResponse("DELETE", "/volumes/some-handle/properties/key", "lost baggage", http.StatusNotFound)
+				err := vol.DeleteProperty("key")
+				Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
+			})
+
 			Context("when error occurs", func() {
 				It("returns API error message", func() {
 					mockErrorResponse("PUT", "/volumes/some-handle/properties/key", "lost baggage", http.StatusInternalServerError)
@@ -495,6 +1289,110 @@ var _ = Describe("Baggage Claim Client", func() {
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(Equal(baggageclaim.ErrVolumeNotFound))
 				})
+
+				It("returns ErrInvalidPropertyName when the server refuses the name", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/properties/key", volume.ErrInvalidPropertyName.Error(), 422)
+					err := vol.SetProperty("key", "value")
+					Expect(err).To(Equal(baggageclaim.ErrInvalidPropertyName))
+				})
+
+				It("returns ErrPropertyValueTooLarge when the server refuses the value", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/properties/key", volume.ErrPropertyValueTooLarge.Error(), 422)
+					err := vol.SetProperty("key", "value")
+					Expect(err).To(Equal(baggageclaim.ErrPropertyValueTooLarge))
+				})
+			})
+
+			Context("when setting the property only if it is as expected", func() {
+				It("sends the value it is expected to have", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PUT", "/volumes/some-handle/properties/key"),
+							ghttp.VerifyBody([]byte(`{"value":"value","expected":"old-value"}`+"\n")),
+							ghttp.RespondWith(http.StatusNoContent, ""),
+						),
+					)
+
+					expected := "old-value"
+					Expect(vol.CompareAndSetProperty("key", &expected, "value")).To(Succeed())
+				})
+
+				It("expects the property to be absent without a value", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PUT", "/volumes/some-handle/properties/key"),
+							ghttp.VerifyBody([]byte(`{"value":"value","expect_absent":true}`+"\n")),
+							ghttp.RespondWith(http.StatusNoContent, ""),
+						),
+					)
+
+					Expect(vol.CompareAndSetProperty("key", nil, "value")).To(Succeed())
+				})
+
+				It("returns ErrPropertyConflict when the property is not as expected", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/properties/key", volume.ErrPropertyConflict.Error(), http.StatusConflict)
+
+					expected := "old-value"
+					err := vol.CompareAndSetProperty("key", &expected, "value")
+					Expect(err).To(Equal(baggageclaim.ErrPropertyConflict))
+				})
+			})
+
+			Context("when leasing the volume", func() {
+				It("takes out a lease for the TTL", func() {
+					expiresAt := time.Unix(123, 0).UTC()
+
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/volumes/some-handle/lease"),
+							ghttp.VerifyJSONRepresenting(baggageclaim.LeaseRequest{TTL: 60}),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.LeaseResponse{
+								Token:     "some-token",
+								ExpiresAt: expiresAt,
+							}),
+						),
+					)
+
+					token, leaseExpiresAt, err := vol.AcquireLease(time.Minute)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(token).To(Equal("some-token"))
+					Expect(leaseExpiresAt).To(Equal(expiresAt))
+				})
+
+				It("returns ErrLeaseNotHeld when renewing a lease that is gone", func() {
+					mockErrorResponse("POST", "/volumes/some-handle/lease", volume.ErrLeaseNotHeld.Error(), http.StatusConflict)
+
+					_, err := vol.RenewLease("some-token", time.Minute)
+					Expect(err).To(Equal(baggageclaim.ErrLeaseNotHeld))
+				})
+
+				It("makes changes through WithLease with the lease's token", func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PUT", "/volumes/some-handle/properties/key"),
+							ghttp.VerifyHeaderKV(baggageclaim.LeaseTokenHeader, "some-token"),
+							ghttp.RespondWith(http.StatusNoContent, ""),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("DELETE", "/volumes/some-handle/lease"),
+							ghttp.VerifyHeaderKV(baggageclaim.LeaseTokenHeader, "some-token"),
+							ghttp.RespondWith(http.StatusNoContent, ""),
+						),
+					)
+
+					leased := vol.WithLease("some-token")
+					Expect(leased.Handle()).To(Equal("some-handle"))
+					Expect(leased.SetProperty("key", "value")).To(Succeed())
+					Expect(vol.ReleaseLease("some-token")).To(Succeed())
+				})
+
+				It("returns ErrVolumeIsLeased when changed while leased", func() {
+					mockErrorResponse("PUT", "/volumes/some-handle/properties/key", volume.ErrVolumeIsLeased.Error(), http.StatusLocked)
+
+					err := vol.SetProperty("key", "value")
+					Expect(err).To(Equal(baggageclaim.ErrVolumeIsLeased))
+					Expect(bcServer.ReceivedRequests()).To(HaveLen(3))
+				})
 			})
 		})
 
@@ -522,6 +1420,66 @@ var _ = Describe("Baggage Claim Client", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			Context("when the server returns the stats", func() {
+				BeforeEach(func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/volumes/some-handle/stats"),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.VolumeStatsResponse{
+								SizeInBytes: 1024,
+								FileCount:   42,
+							}),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/volumes/some-handle/stats"),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.VolumeStatsResponse{
+								SizeInBytes: 1024,
+								FileCount:   42,
+							}),
+						),
+					)
+				})
+
+				It("returns the size and the file count", func() {
+					size, err := vol.SizeInBytes()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(size).To(Equal(int64(1024)))
+
+					fileCount, err := vol.FileCount()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fileCount).To(Equal(int64(42)))
+				})
+			})
+
+			Context("when getting all of the stats at once", func() {
+				BeforeEach(func() {
+					bcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/volumes/some-handle/stats"),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, baggageclaim.VolumeStatsResponse{
+								SizeInBytes: 1024,
+								FileCount:   42,
+								SizeExact:   true,
+								FreeBytes:   2048,
+								Driver:      "btrfs",
+							}),
+						),
+					)
+				})
+
+				It("returns them from a single request", func() {
+					stats, err := vol.Stats()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stats).To(Equal(baggageclaim.VolumeStatsResponse{
+						SizeInBytes: 1024,
+						FileCount:   42,
+						SizeExact:   true,
+						FreeBytes:   2048,
+						Driver:      "btrfs",
+					}))
+				})
+			})
+
 			Context("when unexpected error occurs", func() {
 				It("returns error code and useful message", func() {
 					mockErrorResponse("GET", "/volumes/some-handle/stats", "lost baggage", http.StatusInternalServerError)
@@ -531,5 +1489,121 @@ var _ = Describe("Baggage Claim Client", func() {
 				})
 			})
 		})
+
+		Describe("Retrying idempotent requests", func() {
+			BeforeEach(func() {
+				bcClient = client.NewWithRetryPolicy(bcServer.URL(), &http.Transport{DisableKeepAlives: true}, client.RetryPolicy{
+					Retries:              2,
+					BaseDelay:            time.Millisecond,
+					RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+				})
+			})
+
+			It("retries on a retryable status", func() {
+				mockErrorResponse("GET", "/volumes", "busy", http.StatusServiceUnavailable)
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []volume.Volume{}),
+					),
+				)
+
+				volumes, err := bcClient.ListVolumes(logger, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(BeEmpty())
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(2))
+			})
+
+			It("gives up after the configured number of retries", func() {
+				mockErrorResponse("GET", "/volumes", "busy", http.StatusServiceUnavailable)
+				mockErrorResponse("GET", "/volumes", "busy", http.StatusServiceUnavailable)
+				mockErrorResponse("GET", "/volumes", "still busy", http.StatusServiceUnavailable)
+
+				_, err := bcClient.ListVolumes(logger, nil)
+				Expect(err).To(MatchError("still busy"))
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(3))
+			})
+
+			It("sends the body again when retrying", func() {
+				mockErrorResponse("GET", "/volumes/some-handle", "busy", http.StatusServiceUnavailable)
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+						}),
+					),
+				)
+
+				vol, found, err := bcClient.LookupVolume(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				mockErrorResponse("PUT", "/volumes/some-handle/properties/key", "busy", http.StatusServiceUnavailable)
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/volumes/some-handle/properties/key"),
+						ghttp.VerifyBody([]byte(`{"value":"value"}`+"\n")),
+						ghttp.RespondWith(http.StatusNoContent, ""),
+					),
+				)
+
+				Expect(vol.SetProperty("key", "value")).To(Succeed())
+			})
+
+			It("does not retry on other statuses", func() {
+				mockErrorResponse("GET", "/volumes", "lost baggage", http.StatusInternalServerError)
+
+				_, err := bcClient.ListVolumes(logger, nil)
+				Expect(err).To(MatchError("lost baggage"))
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(1))
+			})
+
+			It("does not retry streams", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+						}),
+					),
+				)
+
+				vol, _, err := bcClient.LookupVolume(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				mockErrorResponse("PUT", "/volumes/some-handle/stream-in", "busy", http.StatusServiceUnavailable)
+
+				err = vol.StreamIn(".", strings.NewReader("some tar content"))
+				Expect(err).To(HaveOccurred())
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(2))
+			})
+
+			It("does not retry conditional property sets", func() {
+				bcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/volumes/some-handle"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, volume.Volume{
+							Handle:     "some-handle",
+							Path:       "some-path",
+							Properties: volume.Properties{},
+						}),
+					),
+				)
+
+				vol, _, err := bcClient.LookupVolume(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				mockErrorResponse("PUT", "/volumes/some-handle/properties/key", "busy", http.StatusServiceUnavailable)
+
+				err = vol.CompareAndSetProperty("key", nil, "value")
+				Expect(err).To(MatchError("busy"))
+				Expect(bcServer.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
 	})
 })
@@ -1,6 +1,38 @@
 package baggageclaim
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var ErrVolumeNotFound = errors.New("volume not found")
 var ErrFileNotFound = errors.New("file not found")
+var ErrPropertyNotFound = errors.New("property not found")
+var ErrPropertyConflict = errors.New("property does not have the expected value")
+var ErrVolumeIsLeased = errors.New("volume is leased")
+var ErrLeaseNotHeld = errors.New("lease is not held")
+var ErrRangeNotSatisfiable = errors.New("range is not satisfiable")
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was used to create another volume")
+var ErrInvalidPropertyName = errors.New("property name must be letters, digits, '.', '_', '-', and ':', and no longer than allowed")
+var ErrPropertyValueTooLarge = errors.New("property value is larger than allowed")
+var ErrStreamChecksumMismatch = errors.New("streamed content does not match its checksum")
+var ErrVolumeGone = errors.New("volume was deleted too long ago to be restored")
+var ErrTooManyStreams = errors.New("too many streams in progress; try again later")
+var ErrSourceVolumeNotFound = errors.New("source volume not found")
+var ErrStreamTooLarge = errors.New("stream is larger than the volume has room for")
+
+// InvalidRequestError is returned when the server refused a request for what
+// is wrong with its fields.
+type InvalidRequestError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (err InvalidRequestError) Error() string {
+	problems := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		problems[i] = field.Field + ": " + field.Message
+	}
+
+	return err.Message + " (" + strings.Join(problems, "; ") + ")"
+}
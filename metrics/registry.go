@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Registry holds the counters and histograms that are updated as things
+// happen, and writes them out in the Prometheus text format.
+type Registry struct {
+	metricsL sync.Mutex
+	metrics  []metric
+}
+
+type metric interface {
+	Write(w io.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (registry *Registry) NewCounter(name string, help string) *Counter {
+	counter := &Counter{name: name, help: help}
+	registry.register(counter)
+	return counter
+}
+
+func (registry *Registry) NewHistogram(name string, help string, buckets []float64) *Histogram {
+	histogram := NewHistogram(name, help, buckets)
+	registry.register(histogram)
+	return histogram
+}
+
+// Write writes every metric, in the order they were registered.
+func (registry *Registry) Write(w io.Writer) {
+	registry.metricsL.Lock()
+	metrics := registry.metrics
+	registry.metricsL.Unlock()
+
+	for _, metric := range metrics {
+		metric.Write(w)
+	}
+}
+
+func (registry *Registry) register(metric metric) {
+	registry.metricsL.Lock()
+	registry.metrics = append(registry.metrics, metric)
+	registry.metricsL.Unlock()
+}
+
+type Counter struct {
+	name string
+	help string
+
+	valueL sync.Mutex
+	value  float64
+}
+
+func (counter *Counter) Inc() {
+	counter.Add(1)
+}
+
+func (counter *Counter) Add(delta float64) {
+	counter.valueL.Lock()
+	counter.value += delta
+	counter.valueL.Unlock()
+}
+
+func (counter *Counter) Value() float64 {
+	counter.valueL.Lock()
+	defer counter.valueL.Unlock()
+
+	return counter.value
+}
+
+func (counter *Counter) Write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", counter.name, counter.help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", counter.name)
+	fmt.Fprintf(w, "%s %s\n", counter.name, formatFloat(counter.Value()))
+}
+
+// Histogram counts observations into cumulative buckets, each counting the
+// observations up to its upper bound.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	countsL sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewHistogram makes a histogram that is not registered anywhere, for
+// aggregates that are computed afresh each time they are written.
+func NewHistogram(name string, help string, buckets []float64) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (histogram *Histogram) Observe(value float64) {
+	histogram.countsL.Lock()
+	defer histogram.countsL.Unlock()
+
+	for i, bound := range histogram.buckets {
+		if value <= bound {
+			histogram.counts[i]++
+		}
+	}
+
+	histogram.sum += value
+	histogram.count++
+}
+
+func (histogram *Histogram) Count() uint64 {
+	histogram.countsL.Lock()
+	defer histogram.countsL.Unlock()
+
+	return histogram.count
+}
+
+func (histogram *Histogram) Sum() float64 {
+	histogram.countsL.Lock()
+	defer histogram.countsL.Unlock()
+
+	return histogram.sum
+}
+
+func (histogram *Histogram) Write(w io.Writer) {
+	histogram.countsL.Lock()
+	defer histogram.countsL.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", histogram.name, histogram.help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", histogram.name)
+
+	for i, bound := range histogram.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", histogram.name, formatFloat(bound), histogram.counts[i])
+	}
+
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", histogram.name, histogram.count)
+	fmt.Fprintf(w, "%s_sum %s\n", histogram.name, formatFloat(histogram.sum))
+	fmt.Fprintf(w, "%s_count %d\n", histogram.name, histogram.count)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
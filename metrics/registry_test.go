@@ -0,0 +1,69 @@
+package metrics_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/metrics"
+)
+
+var _ = Describe("Registry", func() {
+	var registry *metrics.Registry
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+	})
+
+	written := func() string {
+		buf := new(bytes.Buffer)
+		registry.Write(buf)
+		return buf.String()
+	}
+
+	It("writes counters", func() {
+		counter := registry.NewCounter("some_total", "Some things.")
+		counter.Inc()
+		counter.Add(2.5)
+
+		Expect(counter.Value()).To(Equal(3.5))
+		Expect(written()).To(Equal(
+			"# HELP some_total Some things.\n" +
+				"# TYPE some_total counter\n" +
+				"some_total 3.5\n",
+		))
+	})
+
+	It("writes histograms with cumulative buckets", func() {
+		histogram := registry.NewHistogram("some_seconds", "Some durations.", []float64{1, 10})
+		histogram.Observe(0.5)
+		histogram.Observe(5)
+		histogram.Observe(50)
+
+		Expect(histogram.Count()).To(Equal(uint64(3)))
+		Expect(histogram.Sum()).To(Equal(55.5))
+		Expect(written()).To(Equal(
+			"# HELP some_seconds Some durations.\n" +
+				"# TYPE some_seconds histogram\n" +
+				"some_seconds_bucket{le=\"1\"} 1\n" +
+				"some_seconds_bucket{le=\"10\"} 2\n" +
+				"some_seconds_bucket{le=\"+Inf\"} 3\n" +
+				"some_seconds_sum 55.5\n" +
+				"some_seconds_count 3\n",
+		))
+	})
+
+	It("writes metrics in the order they were registered", func() {
+		registry.NewCounter("b_total", "B.")
+		registry.NewCounter("a_total", "A.")
+
+		Expect(written()).To(MatchRegexp(`(?s)b_total 0\n.*a_total 0\n`))
+	})
+
+	It("does not write histograms made outside of it", func() {
+		metrics.NewHistogram("unregistered_seconds", "Not registered.", nil)
+
+		Expect(written()).To(BeEmpty())
+	})
+})
@@ -2,29 +2,135 @@ package reaper
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
+	"github.com/concourse/baggageclaim/metrics"
 	"github.com/concourse/baggageclaim/volume"
 	"github.com/hashicorp/go-multierror"
 )
 
+// RetryPolicy controls how the reaper treats volumes it failed to destroy,
+// e.g. because of an EBUSY from a mount that is still active.
+type RetryPolicy struct {
+	// InitialBackoff is how long to wait before retrying after the first
+	// failure, doubling with each failure after that up to MaxBackoff; it
+	// does not grow without a MaxBackoff. With no backoff, a failed destroy
+	// is retried on every pass.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// EscalateAfter is the number of failures after which the failure is
+	// logged as an error; 0 never escalates. With Quarantine, the volume is
+	// also no longer retried.
+	EscalateAfter int
+	Quarantine    bool
+}
+
+// EvictionPolicy controls how the reaper frees up space once the volumes
+// filesystem runs low on it. While evicting, expired volumes are reaped
+// without their grace period, as they are when inodes run low.
+type EvictionPolicy struct {
+	// LowWatermark is the number of free bytes below which the reaper starts
+	// evicting, and HighWatermark the number at which it stops, which may be
+	// some passes later; one below LowWatermark is taken to be LowWatermark.
+	// Eviction is disabled without a LowWatermark.
+	LowWatermark  uint64
+	HighWatermark uint64
+
+	// EvictUnexpired also evicts volumes that have yet to expire, least
+	// recently used first, once the expired ones are gone. Volumes that
+	// never expire are left alone.
+	EvictUnexpired bool
+}
+
+type DestroyFailure struct {
+	Handle        string    `json:"handle"`
+	Failures      int       `json:"failures"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	LastFailedAt  time.Time `json:"last_failed_at"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	Quarantined   bool      `json:"quarantined"`
+}
+
 type Reaper struct {
-	clock clock.Clock
-	repo  volume.Repository
+	clock       clock.Clock
+	repo        volume.Repository
+	gracePeriod time.Duration
+	retryPolicy RetryPolicy
+
+	// the most destroys attempted on each pass; 0 is unlimited
+	batchSize int
+
+	evictionPolicy EvictionPolicy
+
+	volumesReaped *metrics.Counter
+
+	// passes may be triggered on demand as well as on an interval
+	reapL sync.Mutex
+
+	// whether the last pass left off evicting; guarded by reapL
+	evicting bool
+
+	failuresL sync.Mutex
+	failures  map[string]DestroyFailure
 }
 
 func NewReaper(
 	clock clock.Clock,
 	repository volume.Repository,
+	gracePeriod time.Duration,
+	retryPolicy RetryPolicy,
+	batchSize int,
+	registry *metrics.Registry,
+	evictionPolicy EvictionPolicy,
 ) *Reaper {
+	if evictionPolicy.HighWatermark < evictionPolicy.LowWatermark {
+		evictionPolicy.HighWatermark = evictionPolicy.LowWatermark
+	}
+
 	return &Reaper{
-		clock: clock,
-		repo:  repository,
+		clock:       clock,
+		repo:        repository,
+		gracePeriod: gracePeriod,
+		retryPolicy: retryPolicy,
+
+		batchSize: batchSize,
+
+		evictionPolicy: evictionPolicy,
+
+		volumesReaped: registry.NewCounter("baggageclaim_volumes_reaped_total", "Volumes destroyed by the reaper."),
+
+		failures: map[string]DestroyFailure{},
 	}
 }
 
+// DestroyFailures returns the volumes that still exist after the reaper
+// failed to destroy them, ordered by handle.
+func (reaper *Reaper) DestroyFailures() []DestroyFailure {
+	reaper.failuresL.Lock()
+	defer reaper.failuresL.Unlock()
+
+	failures := make([]DestroyFailure, 0, len(reaper.failures))
+	for _, failure := range reaper.failures {
+		failures = append(failures, failure)
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Handle < failures[j].Handle
+	})
+
+	return failures
+}
+
 func (reaper *Reaper) Reap(logger lager.Logger) error {
+	reaper.reapL.Lock()
+	defer reaper.reapL.Unlock()
+
 	volumes, corruptedHandles, err := reaper.repo.ListVolumes(volume.Properties{})
 	if err != nil {
 		return fmt.Errorf("failed to list volumes: %s", err)
@@ -32,6 +138,19 @@ func (reaper *Reaper) Reap(logger lager.Logger) error {
 
 	reapingTime := reaper.clock.Now()
 
+	// when inodes run low, expired volumes are not given their grace period
+	inodesExhausted, err := reaper.repo.InodesExhausted()
+	if err != nil {
+		logger.Error("failed-to-check-free-inodes", err)
+		inodesExhausted = false
+	}
+
+	if inodesExhausted {
+		logger.Info("inodes-exhausted")
+	}
+
+	evicting := reaper.underDiskPressure(logger)
+
 	hasChildren := map[string]bool{}
 
 	for _, maybeChildVolume := range volumes {
@@ -50,39 +169,93 @@ func (reaper *Reaper) Reap(logger lager.Logger) error {
 
 	var destroyErrs *multierror.Error
 
-	for _, volume := range volumes {
-		if volume.TTL.IsUnlimited() {
+	attempts := 0
+
+	for _, vol := range volumes {
+		if vol.TTL.IsUnlimited() {
 			continue
 		}
 
-		if hasChildren[volume.Handle] {
+		if hasChildren[vol.Handle] {
 			continue
 		}
 
-		if reapingTime.After(volume.ExpiresAt) {
-			logger.Info("reaping", lager.Data{
-				"handle": volume.Handle,
-				"ttl":    volume.TTL,
-			})
+		if !reapingTime.After(vol.ExpiresAt) {
+			continue
+		}
 
-			err = reaper.repo.DestroyVolume(volume.Handle)
-			if err != nil {
-				destroyErrs = multierror.Append(
-					destroyErrs,
-					fmt.Errorf("failed to destroy %s: %s", volume.Handle, err),
-				)
+		reason := volume.DestroyReasonTTLExpiry
+
+		// expired volumes are kept around for the grace period so that a
+		// late SetTTL can still rescue them
+		if !reapingTime.After(vol.ExpiresAt.Add(reaper.gracePeriod)) {
+			if !inodesExhausted && !evicting {
+				logger.Debug("pending-destroy", lager.Data{
+					"handle":     vol.Handle,
+					"expired-at": vol.ExpiresAt,
+				})
 
 				continue
 			}
+
+			reason = volume.DestroyReasonDiskPressure
+		}
+
+		if !reaper.shouldAttempt(vol.Handle, reapingTime) {
+			continue
+		}
+
+		if reaper.batchFull(logger, attempts) {
+			break
+		}
+
+		attempts++
+
+		logger.Info("reaping", lager.Data{
+			"handle": vol.Handle,
+			"ttl":    vol.TTL,
+			"reason": reason,
+		})
+
+		reaped, err := reaper.destroy(logger, vol, reason, reapingTime)
+		if err != nil {
+			destroyErrs = multierror.Append(destroyErrs, err)
+			continue
+		}
+
+		// whatever it was reaped for, it freed up space
+		if reaped && evicting {
+			evicting = reaper.underDiskPressure(logger)
+		}
+	}
+
+	if evicting && reaper.evictionPolicy.EvictUnexpired {
+		err := reaper.evictUnexpired(logger, volumes, hasChildren, reapingTime, &attempts)
+		if err != nil {
+			destroyErrs = multierror.Append(destroyErrs, err)
 		}
 	}
 
 	for _, handle := range corruptedHandles {
+		if !reaper.shouldAttempt(handle, reapingTime) {
+			continue
+		}
+
+		if reaper.batchFull(logger, attempts) {
+			break
+		}
+
+		attempts++
+
 		logger.Info("reaping-corrupted-volume", lager.Data{
 			"handle": handle,
 		})
 
-		err = reaper.repo.DestroyVolumeAndDescendants(handle)
+		_, err = reaper.repo.DestroyVolumeAndDescendants(handle, volume.DestroyOptions{
+			Reason: volume.DestroyReasonCorrupted,
+		})
+
+		err = reaper.recordAttempt(logger, handle, reapingTime, err)
 		if err != nil {
 			destroyErrs = multierror.Append(
 				destroyErrs,
@@ -91,7 +264,260 @@ func (reaper *Reaper) Reap(logger lager.Logger) error {
 
 			continue
 		}
+
+		reaper.reaped(logger, handle)
+	}
+
+	// deleted volumes are kept for their retention window by the repository,
+	// which purges those whose window has passed
+	purged, err := reaper.repo.PurgeDeletedVolumes()
+	if err != nil {
+		destroyErrs = multierror.Append(destroyErrs, fmt.Errorf("failed to purge deleted volumes: %s", err))
+	}
+
+	for handle, err := range purged {
+		if err != nil {
+			destroyErrs = multierror.Append(destroyErrs, fmt.Errorf("failed to purge %s: %s", handle, err))
+			continue
+		}
+
+		reaper.reaped(logger, handle)
+	}
+
+	reaper.forgetVanished(volumes, corruptedHandles)
+
+	return destroyErrs.ErrorOrNil()
+}
+
+// evictUnexpired evicts volumes that have yet to expire, least recently used
+// first, until there is no longer disk pressure.
+func (reaper *Reaper) evictUnexpired(logger lager.Logger, volumes volume.Volumes, hasChildren map[string]bool, reapingTime time.Time, attempts *int) error {
+	var candidates volume.Volumes
+	for _, vol := range volumes {
+		if vol.TTL.IsUnlimited() || hasChildren[vol.Handle] || reapingTime.After(vol.ExpiresAt) {
+			continue
+		}
+
+		candidates = append(candidates, vol)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return lastUsedAt(candidates[i]).Before(lastUsedAt(candidates[j]))
+	})
+
+	var destroyErrs *multierror.Error
+
+	for _, vol := range candidates {
+		if !reaper.shouldAttempt(vol.Handle, reapingTime) {
+			continue
+		}
+
+		if reaper.batchFull(logger, *attempts) {
+			break
+		}
+
+		*attempts++
+
+		logger.Info("evicting-unexpired", lager.Data{
+			"handle":       vol.Handle,
+			"expires-at":   vol.ExpiresAt,
+			"last-used-at": lastUsedAt(vol),
+		})
+
+		reaped, err := reaper.destroy(logger, vol, volume.DestroyReasonDiskPressure, reapingTime)
+		if err != nil {
+			destroyErrs = multierror.Append(destroyErrs, err)
+			continue
+		}
+
+		if reaped && !reaper.underDiskPressure(logger) {
+			break
+		}
 	}
 
 	return destroyErrs.ErrorOrNil()
 }
+
+// lastUsedAt is when the volume was last accessed, or created if it has not
+// been since.
+func lastUsedAt(vol volume.Volume) time.Time {
+	if vol.LastAccessedAt.After(vol.CreatedAt) {
+		return vol.LastAccessedAt
+	}
+
+	return vol.CreatedAt
+}
+
+// destroy destroys the volume as it was listed, returning whether it was.
+//
+// Builds may still be streaming the volume's contents, e.g. when they have
+// not heartbeated in time; it is reaped on a later pass once they are done,
+// as it is once a lease on it has expired. Its TTL may also have been
+// renewed since it was listed, by an access or a SetTTL. None of these count
+// as failures.
+func (reaper *Reaper) destroy(logger lager.Logger, vol volume.Volume, reason volume.DestroyReason, now time.Time) (bool, error) {
+	err := reaper.repo.DestroyVolume(vol.Handle, volume.DestroyOptions{
+		Reason:        reason,
+		SpareStreamed: true,
+		ExpiredAt:     vol.ExpiresAt,
+	})
+	if err == volume.ErrVolumeIsStreaming {
+		logger.Info("skipped-streaming-volume", lager.Data{"handle": vol.Handle})
+		return false, nil
+	}
+
+	if err == volume.ErrVolumeWasRenewed {
+		logger.Info("skipped-renewed-volume", lager.Data{"handle": vol.Handle})
+		return false, nil
+	}
+
+	if err == volume.ErrVolumeIsLeased {
+		logger.Info("skipped-leased-volume", lager.Data{"handle": vol.Handle})
+		return false, nil
+	}
+
+	err = reaper.recordAttempt(logger, vol.Handle, now, err)
+	if err != nil {
+		return false, fmt.Errorf("failed to destroy %s: %s", vol.Handle, err)
+	}
+
+	reaper.reaped(logger, vol.Handle)
+
+	return true, nil
+}
+
+// underDiskPressure tells whether to evict, which the reaper does from when
+// free space falls below the low watermark until it is back up to the high
+// one. If free space can't be checked, it carries on as it was.
+func (reaper *Reaper) underDiskPressure(logger lager.Logger) bool {
+	policy := reaper.evictionPolicy
+	if policy.LowWatermark == 0 {
+		return false
+	}
+
+	freeBytes, err := reaper.repo.FreeBytes()
+	if err != nil {
+		logger.Error("failed-to-check-free-bytes", err)
+		return reaper.evicting
+	}
+
+	data := lager.Data{
+		"free-bytes":     freeBytes,
+		"low-watermark":  policy.LowWatermark,
+		"high-watermark": policy.HighWatermark,
+	}
+
+	if freeBytes < policy.LowWatermark && !reaper.evicting {
+		logger.Info("disk-pressure", data)
+		reaper.evicting = true
+	} else if freeBytes >= policy.HighWatermark && reaper.evicting {
+		logger.Info("disk-pressure-relieved", data)
+		reaper.evicting = false
+	}
+
+	return reaper.evicting
+}
+
+// batchFull tells whether the pass has attempted as many destroys as it may,
+// leaving the rest for the next one.
+func (reaper *Reaper) batchFull(logger lager.Logger, attempts int) bool {
+	if reaper.batchSize == 0 || attempts < reaper.batchSize {
+		return false
+	}
+
+	logger.Info("batch-full", lager.Data{"batch-size": reaper.batchSize})
+
+	return true
+}
+
+func (reaper *Reaper) reaped(logger lager.Logger, handle string) {
+	logger.Info("reaped", lager.Data{"handle": handle})
+	reaper.volumesReaped.Inc()
+}
+
+func (reaper *Reaper) shouldAttempt(handle string, now time.Time) bool {
+	reaper.failuresL.Lock()
+	defer reaper.failuresL.Unlock()
+
+	failure, found := reaper.failures[handle]
+	if !found {
+		return true
+	}
+
+	return !failure.Quarantined && !now.Before(failure.NextAttemptAt)
+}
+
+// recordAttempt tracks the outcome of a destroy, returning the error if it
+// failed. A volume that has disappeared in the meantime counts as destroyed.
+func (reaper *Reaper) recordAttempt(logger lager.Logger, handle string, now time.Time, err error) error {
+	reaper.failuresL.Lock()
+	defer reaper.failuresL.Unlock()
+
+	if err == nil || err == volume.ErrVolumeDoesNotExist {
+		delete(reaper.failures, handle)
+		return nil
+	}
+
+	failure, found := reaper.failures[handle]
+	if !found {
+		failure = DestroyFailure{
+			Handle:        handle,
+			FirstFailedAt: now,
+		}
+	}
+
+	failure.Failures++
+	failure.LastFailedAt = now
+	failure.LastError = err.Error()
+	failure.NextAttemptAt = now.Add(reaper.backoff(failure.Failures))
+
+	if reaper.retryPolicy.EscalateAfter > 0 && failure.Failures == reaper.retryPolicy.EscalateAfter {
+		failure.Quarantined = reaper.retryPolicy.Quarantine
+
+		logger.Error("destroy-keeps-failing", err, lager.Data{
+			"handle":      handle,
+			"failures":    failure.Failures,
+			"since":       failure.FirstFailedAt,
+			"quarantined": failure.Quarantined,
+		})
+	}
+
+	reaper.failures[handle] = failure
+
+	return err
+}
+
+func (reaper *Reaper) backoff(failures int) time.Duration {
+	backoff := reaper.retryPolicy.InitialBackoff
+	for i := 1; i < failures && backoff < reaper.retryPolicy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if reaper.retryPolicy.MaxBackoff > 0 && backoff > reaper.retryPolicy.MaxBackoff {
+		backoff = reaper.retryPolicy.MaxBackoff
+	}
+
+	return backoff
+}
+
+// forgetVanished drops failures for volumes that no longer exist, e.g.
+// because they were destroyed through the API.
+func (reaper *Reaper) forgetVanished(volumes volume.Volumes, corruptedHandles []string) {
+	existing := map[string]bool{}
+	for _, vol := range volumes {
+		existing[vol.Handle] = true
+	}
+
+	for _, handle := range corruptedHandles {
+		existing[handle] = true
+	}
+
+	reaper.failuresL.Lock()
+	defer reaper.failuresL.Unlock()
+
+	for handle := range reaper.failures {
+		if !existing[handle] {
+			delete(reaper.failures, handle)
+		}
+	}
+}
@@ -6,18 +6,26 @@ import (
 
 	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/baggageclaim/metrics"
 	. "github.com/concourse/baggageclaim/reaper"
 	"github.com/concourse/baggageclaim/volume"
 	"github.com/concourse/baggageclaim/volume/volumefakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Reaper", func() {
 	var (
-		repository *volumefakes.FakeRepository
-		clock      *fakeclock.FakeClock
+		repository  *volumefakes.FakeRepository
+		clock       *fakeclock.FakeClock
+		gracePeriod time.Duration
+		retryPolicy RetryPolicy
+		batchSize   int
+		registry    *metrics.Registry
+
+		evictionPolicy EvictionPolicy
 
 		reaper *Reaper
 	)
@@ -27,8 +35,15 @@ var _ = Describe("Reaper", func() {
 	BeforeEach(func() {
 		repository = new(volumefakes.FakeRepository)
 		clock = fakeclock.NewFakeClock(now)
+		gracePeriod = 0
+		retryPolicy = RetryPolicy{}
+		batchSize = 0
+		registry = metrics.NewRegistry()
+		evictionPolicy = EvictionPolicy{}
+	})
 
-		reaper = NewReaper(clock, repository)
+	JustBeforeEach(func() {
+		reaper = NewReaper(clock, repository, gracePeriod, retryPolicy, batchSize, registry, evictionPolicy)
 	})
 
 	Describe("Reap", func() {
@@ -82,8 +97,117 @@ var _ = Describe("Reaper", func() {
 				It("destroys it", func() {
 					Expect(repository.DestroyVolumeCallCount()).To(Equal(1))
 
-					handle := repository.DestroyVolumeArgsForCall(0)
+					handle, opts := repository.DestroyVolumeArgsForCall(0)
 					Expect(handle).To(Equal(expiringVolume10sec.Handle))
+					Expect(opts.Reason).To(Equal(volume.DestroyReasonTTLExpiry))
+				})
+
+				It("spares it while it is being streamed", func() {
+					_, opts := repository.DestroyVolumeArgsForCall(0)
+					Expect(opts.SpareStreamed).To(BeTrue())
+				})
+
+				It("spares it if it no longer expires when it was listed to", func() {
+					_, opts := repository.DestroyVolumeArgsForCall(0)
+					Expect(opts.ExpiredAt).To(Equal(expiringVolume10sec.ExpiresAt))
+				})
+
+				It("counts it as reaped", func() {
+					buffer := gbytes.NewBuffer()
+					registry.Write(buffer)
+					Expect(buffer).To(gbytes.Say("baggageclaim_volumes_reaped_total 1\n"))
+				})
+
+				Context("when it is being streamed", func() {
+					BeforeEach(func() {
+						repository.DestroyVolumeReturns(volume.ErrVolumeIsStreaming)
+					})
+
+					It("skips it without counting a failure", func() {
+						Expect(reapErr).NotTo(HaveOccurred())
+						Expect(reaper.DestroyFailures()).To(BeEmpty())
+
+						buffer := gbytes.NewBuffer()
+						registry.Write(buffer)
+						Expect(buffer).To(gbytes.Say("baggageclaim_volumes_reaped_total 0\n"))
+					})
+				})
+
+				Context("when its TTL was renewed since it was listed", func() {
+					BeforeEach(func() {
+						repository.DestroyVolumeReturns(volume.ErrVolumeWasRenewed)
+					})
+
+					It("skips it without counting a failure", func() {
+						Expect(reapErr).NotTo(HaveOccurred())
+						Expect(reaper.DestroyFailures()).To(BeEmpty())
+
+						buffer := gbytes.NewBuffer()
+						registry.Write(buffer)
+						Expect(buffer).To(gbytes.Say("baggageclaim_volumes_reaped_total 0\n"))
+					})
+				})
+
+				Context("when it is leased", func() {
+					BeforeEach(func() {
+						repository.DestroyVolumeReturns(volume.ErrVolumeIsLeased)
+					})
+
+					It("skips it without counting a failure", func() {
+						Expect(reapErr).NotTo(HaveOccurred())
+						Expect(reaper.DestroyFailures()).To(BeEmpty())
+					})
+				})
+
+				Context("when another has expired too, with a batch size of 1", func() {
+					BeforeEach(func() {
+						clock.Increment(10 * time.Second)
+						batchSize = 1
+					})
+
+					It("destroys at most one of them per pass", func() {
+						Expect(repository.DestroyVolumeCallCount()).To(Equal(1))
+
+						Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(Succeed())
+						Expect(repository.DestroyVolumeCallCount()).To(Equal(2))
+					})
+				})
+
+				Context("when a grace period is configured", func() {
+					BeforeEach(func() {
+						gracePeriod = 5 * time.Second
+					})
+
+					It("does not destroy it yet", func() {
+						Expect(repository.DestroyVolumeCallCount()).To(BeZero())
+					})
+
+					Context("when the grace period has passed", func() {
+						BeforeEach(func() {
+							clock.Increment(5 * time.Second)
+						})
+
+						It("destroys it", func() {
+							Expect(repository.DestroyVolumeCallCount()).To(Equal(1))
+
+							handle, _ := repository.DestroyVolumeArgsForCall(0)
+							Expect(handle).To(Equal(expiringVolume10sec.Handle))
+						})
+					})
+
+					Context("when inodes are exhausted", func() {
+						BeforeEach(func() {
+							repository.InodesExhaustedReturns(true, nil)
+						})
+
+						It("destroys it right away because of disk pressure", func() {
+							Expect(repository.DestroyVolumeCallCount()).To(Equal(1))
+
+							handle, opts := repository.DestroyVolumeArgsForCall(0)
+							Expect(handle).To(Equal(expiringVolume10sec.Handle))
+							Expect(opts.Reason).To(Equal(volume.DestroyReasonDiskPressure))
+						})
+					})
 				})
 
 				Context("when determining if a volume has a parent fails", func() {
@@ -132,6 +256,121 @@ var _ = Describe("Reaper", func() {
 				})
 			})
 
+			Context("when free space runs low", func() {
+				var (
+					leased    map[string]bool
+					destroyed []string
+				)
+
+				expiringVolume30sec := volume.Volume{
+					Handle:         "expiring-30sec",
+					TTL:            30,
+					ExpiresAt:      now.Add(30 * time.Second),
+					LastAccessedAt: now,
+				}
+
+				BeforeEach(func() {
+					gracePeriod = 5 * time.Second
+					evictionPolicy = EvictionPolicy{LowWatermark: 1000, HighWatermark: 1200}
+
+					clock.Increment(10*time.Second + 1)
+
+					accessedVolume20sec := expiringVolume20sec
+					accessedVolume20sec.LastAccessedAt = now.Add(5 * time.Second)
+
+					repository.ListVolumesReturns([]volume.Volume{
+						nonExpiringVolume,
+						expiringVolume10sec,
+						accessedVolume20sec,
+						expiringVolume30sec,
+					}, []string{}, nil)
+
+					leased = map[string]bool{}
+					destroyed = nil
+
+					repository.DestroyVolumeStub = func(handle string, _ volume.DestroyOptions) error {
+						if leased[handle] {
+							return volume.ErrVolumeIsLeased
+						}
+
+						destroyed = append(destroyed, handle)
+						return nil
+					}
+
+					// each destroy frees up 100 bytes
+					repository.FreeBytesStub = func() (uint64, error) {
+						return 900 + uint64(len(destroyed))*100, nil
+					}
+				})
+
+				It("evicts expired volumes without their grace period because of disk pressure", func() {
+					Expect(destroyed).To(Equal([]string{expiringVolume10sec.Handle}))
+
+					_, opts := repository.DestroyVolumeArgsForCall(0)
+					Expect(opts.Reason).To(Equal(volume.DestroyReasonDiskPressure))
+					Expect(opts.SpareStreamed).To(BeTrue())
+				})
+
+				It("keeps evicting on later passes until free space is back up to the high watermark", func() {
+					expiringVolume15sec := volume.Volume{
+						Handle:    "expiring-15sec",
+						TTL:       15,
+						ExpiresAt: now.Add(10 * time.Second),
+					}
+
+					repository.ListVolumesReturns([]volume.Volume{expiringVolume15sec}, []string{}, nil)
+
+					Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(Succeed())
+					Expect(destroyed).To(Equal([]string{expiringVolume10sec.Handle, expiringVolume15sec.Handle}))
+
+					repository.ListVolumesReturns([]volume.Volume{expiringVolume20sec}, []string{}, nil)
+					repository.FreeBytesStub = nil
+					repository.FreeBytesReturns(1200, nil)
+					clock.Increment(10 * time.Second)
+
+					Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(Succeed())
+					Expect(destroyed).To(HaveLen(2))
+				})
+
+				Context("when free space is above the low watermark", func() {
+					BeforeEach(func() {
+						repository.FreeBytesReturns(1000, nil)
+						repository.FreeBytesStub = nil
+					})
+
+					It("leaves expired volumes their grace period", func() {
+						Expect(repository.DestroyVolumeCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when unexpired volumes may be evicted too", func() {
+					BeforeEach(func() {
+						evictionPolicy.EvictUnexpired = true
+						evictionPolicy.HighWatermark = 1100
+					})
+
+					It("evicts them least recently used first until free space is back up to the high watermark", func() {
+						Expect(destroyed).To(Equal([]string{expiringVolume10sec.Handle, expiringVolume30sec.Handle}))
+
+						_, opts := repository.DestroyVolumeArgsForCall(1)
+						Expect(opts.Reason).To(Equal(volume.DestroyReasonDiskPressure))
+						Expect(opts.SpareStreamed).To(BeTrue())
+						Expect(opts.ExpiredAt).To(Equal(expiringVolume30sec.ExpiresAt))
+					})
+
+					Context("when the least recently used one is leased", func() {
+						BeforeEach(func() {
+							leased[expiringVolume30sec.Handle] = true
+						})
+
+						It("skips it for the next", func() {
+							Expect(reapErr).NotTo(HaveOccurred())
+							Expect(destroyed).To(Equal([]string{expiringVolume10sec.Handle, expiringVolume20sec.Handle}))
+						})
+					})
+				})
+			})
+
 			Context("when multiple volumes have expired", func() {
 				BeforeEach(func() {
 					clock.Increment(20*time.Second + 1)
@@ -140,16 +379,16 @@ var _ = Describe("Reaper", func() {
 				It("destroys the expired volumes", func() {
 					Expect(repository.DestroyVolumeCallCount()).To(Equal(2))
 
-					handle1 := repository.DestroyVolumeArgsForCall(0)
+					handle1, _ := repository.DestroyVolumeArgsForCall(0)
 					Expect(handle1).To(Equal(expiringVolume10sec.Handle))
 
-					handle2 := repository.DestroyVolumeArgsForCall(1)
+					handle2, _ := repository.DestroyVolumeArgsForCall(1)
 					Expect(handle2).To(Equal(expiringVolume20sec.Handle))
 				})
 
 				Context("when destroying any volumes fails", func() {
 					BeforeEach(func() {
-						repository.DestroyVolumeStub = func(handle string) error {
+						repository.DestroyVolumeStub = func(handle string, opts volume.DestroyOptions) error {
 							return errors.New("nope to " + handle)
 						}
 					})
@@ -159,6 +398,108 @@ var _ = Describe("Reaper", func() {
 						Expect(reapErr.Error()).To(ContainSubstring("failed to destroy expiring-10sec: nope to expiring-10sec"))
 						Expect(reapErr.Error()).To(ContainSubstring("failed to destroy expiring-20sec: nope to expiring-20sec"))
 					})
+
+					It("tracks the failures", func() {
+						Expect(reaper.DestroyFailures()).To(Equal([]DestroyFailure{
+							{
+								Handle:        "expiring-10sec",
+								Failures:      1,
+								FirstFailedAt: clock.Now(),
+								LastFailedAt:  clock.Now(),
+								LastError:     "nope to expiring-10sec",
+								NextAttemptAt: clock.Now(),
+							},
+							{
+								Handle:        "expiring-20sec",
+								Failures:      1,
+								FirstFailedAt: clock.Now(),
+								LastFailedAt:  clock.Now(),
+								LastError:     "nope to expiring-20sec",
+								NextAttemptAt: clock.Now(),
+							},
+						}))
+					})
+
+					It("retries on the next pass", func() {
+						Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(HaveOccurred())
+						Expect(repository.DestroyVolumeCallCount()).To(Equal(4))
+						Expect(reaper.DestroyFailures()[0].Failures).To(Equal(2))
+					})
+
+					Context("with a backoff", func() {
+						BeforeEach(func() {
+							retryPolicy.InitialBackoff = 10 * time.Second
+							retryPolicy.MaxBackoff = 15 * time.Second
+						})
+
+						It("waits for the backoff, doubling it up to the max", func() {
+							logger := lagertest.NewTestLogger("test")
+
+							Expect(reaper.Reap(logger)).To(Succeed())
+							Expect(repository.DestroyVolumeCallCount()).To(Equal(2))
+
+							clock.Increment(10 * time.Second)
+							Expect(reaper.Reap(logger)).To(HaveOccurred())
+							Expect(repository.DestroyVolumeCallCount()).To(Equal(4))
+							Expect(reaper.DestroyFailures()[0].NextAttemptAt).To(Equal(clock.Now().Add(15 * time.Second)))
+						})
+					})
+
+					Context("when a volume keeps failing", func() {
+						BeforeEach(func() {
+							retryPolicy.EscalateAfter = 2
+							retryPolicy.Quarantine = true
+						})
+
+						It("escalates and quarantines it", func() {
+							logger := lagertest.NewTestLogger("test")
+							Expect(reaper.Reap(logger)).To(HaveOccurred())
+							Expect(logger).To(gbytes.Say("destroy-keeps-failing"))
+							Expect(reaper.DestroyFailures()[0].Quarantined).To(BeTrue())
+
+							Expect(reaper.Reap(logger)).To(Succeed())
+							Expect(repository.DestroyVolumeCallCount()).To(Equal(4))
+						})
+					})
+
+					Context("when the volume is destroyed later", func() {
+						It("forgets the failure", func() {
+							repository.DestroyVolumeStub = nil
+							Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(Succeed())
+							Expect(reaper.DestroyFailures()).To(BeEmpty())
+						})
+					})
+
+					Context("when the volume goes away through other means", func() {
+						It("forgets the failure", func() {
+							repository.ListVolumesReturns(nil, nil, nil)
+							Expect(reaper.Reap(lagertest.NewTestLogger("test"))).To(Succeed())
+							Expect(reaper.DestroyFailures()).To(BeEmpty())
+						})
+					})
+				})
+			})
+
+			Context("when deleted volumes are past their retention window", func() {
+				BeforeEach(func() {
+					repository.PurgeDeletedVolumesReturns(map[string]error{
+						"purged-handle": nil,
+						"stuck-handle":  errors.New("nope"),
+					}, nil)
+				})
+
+				It("has the repository purge them", func() {
+					Expect(repository.PurgeDeletedVolumesCallCount()).To(Equal(1))
+				})
+
+				It("counts those purged as reaped", func() {
+					buffer := gbytes.NewBuffer()
+					registry.Write(buffer)
+					Expect(buffer).To(gbytes.Say("baggageclaim_volumes_reaped_total 1\n"))
+				})
+
+				It("returns the failures", func() {
+					Expect(reapErr).To(MatchError(ContainSubstring("failed to purge stuck-handle: nope")))
 				})
 			})
 
@@ -173,7 +514,10 @@ var _ = Describe("Reaper", func() {
 
 				It("destroys the corrupted volumes and all their descendents", func() {
 					Expect(repository.DestroyVolumeAndDescendantsCallCount()).To(Equal(1))
-					Expect(repository.DestroyVolumeAndDescendantsArgsForCall(0)).To(Equal("some-terrible-volume"))
+
+					handle, opts := repository.DestroyVolumeAndDescendantsArgsForCall(0)
+					Expect(handle).To(Equal("some-terrible-volume"))
+					Expect(opts.Reason).To(Equal(volume.DestroyReasonCorrupted))
 				})
 			})
 		})
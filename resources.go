@@ -2,37 +2,430 @@ package baggageclaim
 
 import (
 	"encoding/json"
+	"os"
 	"time"
 )
 
+// GobContentType is the media type of gob-encoded responses. The read
+// endpoints (listing volumes, getting a volume, and its stats) send it to
+// requests whose Accept header asks for it; everything else is JSON.
+const GobContentType = "application/x-gob"
+
+// A stream-out with a chunk-size is sent as a multipart/mixed response, each
+// part being the next chunk of the stream. The parts carry their position in
+// the stream and the hex SHA-256 of their contents in these headers, and a
+// stream-in sent the same way has both checked before it is extracted.
+const (
+	ChunkIndexHeader    = "X-Chunk-Index"
+	ChunkChecksumHeader = "X-Chunk-Sha256"
+)
+
+// DowngradedTrailer is sent at the end of a stream-out with downgrade=true,
+// once it is known what was changed, counting the setuid and setgid bits
+// cleared and the device nodes left out, e.g. "setuid=1, setgid=0, devices=2".
+const DowngradedTrailer = "X-Stream-Downgraded"
+
+// StreamOutFormatHeader is sent with a stream-out, saying whether it is a
+// tar ("tar") or the bytes of a single regular file ("file"). A stream-out
+// asks for either with format=tar or format=file; without it, regular files
+// are streamed as files and everything else as a tar.
+const StreamOutFormatHeader = "X-Stream-Format"
+
+// WhiteoutPrefix marks the entries of a delta stream-in that stand for
+// removed paths: an empty entry named "dir/.wh.name" removes "dir/name" from
+// the volume. A delta stream-in, sent with delta=true, applies only the
+// entries it has on top of what the volume already holds, and its whiteouts
+// must come before every other entry.
+const WhiteoutPrefix = ".wh."
+
+// OpaqueWhiteout marks a directory of a layer stream-in as opaque: an empty
+// entry named "dir/.wh..wh..opq" removes what the volume already held in
+// "dir", keeping what the layer has there itself. A layer stream-in, sent
+// with layer=true, is an OCI or Docker image layer, whose whiteouts may come
+// anywhere in it and only ever remove what was there before it.
+const OpaqueWhiteout = WhiteoutPrefix + WhiteoutPrefix + ".opq"
+
+// StreamSizeRecord is the PAX record a stream-in's tar may give the size of
+// the data its regular files hold in all, in a global header before its
+// first entry, for a stream that won't fit in the volume to be refused with
+// 413 before any of it is extracted. Without it, the stream is refused once
+// its entries are found to hold more than the volume has room for.
+const StreamSizeRecord = "BAGGAGECLAIM.size"
+
+// LeaseTokenHeader carries the token of the lease held on a volume, for a
+// stream-in, property change, or destroy of the volume to go ahead while it
+// is leased, and for the lease to be released.
+const LeaseTokenHeader = "X-Lease-Token"
+
+// StreamChecksumHeader carries the sha256:<hex> digest a stream-in's tar
+// must have once decoded and decompressed. It may be sent as a trailer
+// instead, declared in the Trailer header, by clients that only know it once
+// they have sent the stream. A stream-in that doesn't match is refused with
+// 422, and what it extracted removed.
+const StreamChecksumHeader = "X-Stream-Checksum"
+
+// RequestIDHeader carries the ID of a request, which every line the server
+// logs while serving it is tagged with. The server makes one up for requests
+// without one, or with one that is too long or not printable ASCII, and
+// responds with it either way.
+const RequestIDHeader = "X-Request-ID"
+
+// StreamBytesPerSecondHeader caps how fast a stream-in or stream-out is
+// streamed, in bytes per second. It can only lower the cap the server was
+// started with, if any.
+const StreamBytesPerSecondHeader = "X-Stream-Bytes-Per-Second"
+
+// ListVolumesOptions pages through and orders the volumes listed. With the
+// zero value, every volume is listed in no particular order.
+type ListVolumesOptions struct {
+	// Limit is the most volumes to list; zero means no limit.
+	Limit int
+
+	// Offset is how many of the matching volumes to skip.
+	Offset int
+
+	// Sort is SortByHandle or SortByCreatedAt. Volumes created at the same
+	// time are ordered by handle. Paged lists without one are ordered by
+	// handle, so that the pages don't overlap.
+	Sort string
+}
+
+const (
+	SortByHandle    = "handle"
+	SortByCreatedAt = "created-at"
+)
+
+// VolumeCountHeader carries the number of volumes matching a list request
+// that is paged or sorted, across all of its pages.
+const VolumeCountHeader = "Volume-Count"
+
 type VolumeRequest struct {
 	Handle       string           `json:"handle"`
 	Strategy     *json.RawMessage `json:"strategy"`
 	Properties   VolumeProperties `json:"properties"`
 	TTLInSeconds uint             `json:"ttl,omitempty"`
 	Privileged   bool             `json:"privileged,omitempty"`
+
+	// ExpectedSizeInBytes and MutationHeavy are hints the server may use
+	// to materialize a COW request as a full copy of the parent instead.
+	ExpectedSizeInBytes int64 `json:"expected_size_in_bytes,omitempty"`
+	MutationHeavy       bool  `json:"mutation_heavy,omitempty"`
+
+	// SizeInBytes, if set, limits how much the volume may hold. Writes past
+	// it fail with ENOSPC. It needs a driver with quota support.
+	SizeInBytes int64 `json:"size_in_bytes,omitempty"`
+
+	// ReadOnly freezes the volume once it has been created from its
+	// strategy, and mounts it read-only. It needs a driver that can.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// MountOptions are mount options the volume is given of its own, out of
+	// those its driver allows, e.g. noatime.
+	MountOptions []string `json:"mount_options,omitempty"`
+
+	// RenewTTLOnAccess starts the volume's TTL over each time it is streamed
+	// out, looked up, or touched, rather than only when it is set.
+	RenewTTLOnAccess bool `json:"renew_ttl_on_access,omitempty"`
+
+	// IdempotencyKey identifies the create across retries. A create with the
+	// key of a volume that was already created with it gets that volume back
+	// rather than another, or 409 if it asks for a different one.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Encrypted has the volume's data encrypted where it is stored, with a
+	// key derived from one the server holds, and read and written as it is
+	// through the volume's path and streams. It needs the server to have a
+	// master key and a driver that can encrypt, and a strategy that creates
+	// the volume afresh, e.g. empty, import, url, or copy, or from an
+	// encrypted parent. Volumes copied from an encrypted one are encrypted
+	// whether or not they ask to be.
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// FieldError is what is wrong with one field of a request. Field is its path
+// in the request's JSON, e.g. "strategy.volume", and Code is one of the
+// FieldError codes below, for clients to tell what is wrong without parsing
+// the message.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	// FieldErrorRequired is for a field that is missing or empty.
+	FieldErrorRequired = "required"
+
+	// FieldErrorMalformed is for a field that can't be decoded.
+	FieldErrorMalformed = "malformed"
+
+	// FieldErrorUnknownStrategy is for a strategy type the server doesn't
+	// know.
+	FieldErrorUnknownStrategy = "unknown-strategy"
+
+	// FieldErrorNegative is for a size that is less than 0.
+	FieldErrorNegative = "negative"
+
+	// FieldErrorTooLarge is for a size that is more than the server allows.
+	FieldErrorTooLarge = "too-large"
+
+	// FieldErrorConflict is for a field that can't be given along with the
+	// rest of the request, e.g. a size for a view.
+	FieldErrorConflict = "conflict"
+
+	// FieldErrorNotFound is for a volume named by a field that does not
+	// exist.
+	FieldErrorNotFound = "not-found"
+
+	// FieldErrorIsView is for a parent named by a field that is a view,
+	// which can't be the parent of another volume.
+	FieldErrorIsView = "is-view"
+
+	// FieldErrorNotAllowed is for a value the server doesn't allow, e.g. a
+	// mount option the driver can't apply.
+	FieldErrorNotAllowed = "not-allowed"
+
+	// FieldErrorFetchFailed is for a URL the server could not fetch.
+	FieldErrorFetchFailed = "fetch-failed"
+
+	// FieldErrorInvalidArchive is for a URL the server fetched something
+	// other than a tar archive from.
+	FieldErrorInvalidArchive = "invalid-archive"
+
+	// FieldErrorChecksumMismatch is for a checksum the fetched archive does
+	// not match.
+	FieldErrorChecksumMismatch = "checksum-mismatch"
+)
+
+// CloneVolumeRequest names the volume to clone the volume into. A handle is
+// generated if it is empty.
+type CloneVolumeRequest struct {
+	Handle string `json:"handle"`
+}
+
+// RenameVolumeRequest gives the volume its new handle.
+type RenameVolumeRequest struct {
+	Handle string `json:"handle"`
+}
+
+// DestroyVolumeResponse lists the volumes a forced destroy destroyed, or a
+// dry run would, each one's descendants before it.
+type DestroyVolumeResponse struct {
+	Destroyed []string `json:"destroyed"`
+}
+
+// DestroyVolumesResult is the outcome of destroying one of the volumes of a
+// bulk destroy. Error is empty if the volume was destroyed or did not exist.
+type DestroyVolumesResult struct {
+	Handle string `json:"handle"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DestroyVolumesWithPropertiesResponse counts the volumes that had the
+// properties and were destroyed, and lists the ones that could not be.
+type DestroyVolumesWithPropertiesResponse struct {
+	Destroyed int                    `json:"destroyed"`
+	Failed    []DestroyVolumesResult `json:"failed"`
 }
 
 type VolumeResponse struct {
-	Handle       string           `json:"handle"`
-	Path         string           `json:"path"`
-	Properties   VolumeProperties `json:"properties"`
-	TTLInSeconds uint             `json:"ttl,omitempty"`
-	ExpiresAt    time.Time        `json:"expires_at"`
+	Handle         string           `json:"handle"`
+	Path           string           `json:"path"`
+	Properties     VolumeProperties `json:"properties"`
+	TTLInSeconds   uint             `json:"ttl,omitempty"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	ParentHandle   string           `json:"parent_handle"`
+	PendingDestroy bool             `json:"pending_destroy"`
+	Committed      bool             `json:"committed"`
+	CommittedAt    time.Time        `json:"committed_at"`
+	Frozen         bool             `json:"frozen"`
+	ReadOnly       bool             `json:"read_only"`
+	MountOptions   []string         `json:"mount_options,omitempty"`
+	Encrypted      bool             `json:"encrypted"`
+	LastAccessedAt time.Time        `json:"last_accessed_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	ModifiedAt     time.Time        `json:"modified_at"`
+	Strategy       string           `json:"strategy,omitempty"`
+	Digest         string           `json:"digest,omitempty"`
+	Driver         string           `json:"driver,omitempty"`
+	FilesystemType string           `json:"filesystem_type,omitempty"`
+
+	RenewTTLOnAccess bool `json:"renew_ttl_on_access,omitempty"`
+
+	// Deleted is set for the volumes listed with includeDeleted that are
+	// kept after being destroyed until they are restored or purged, and
+	// DeletedAt is when they were destroyed.
+	Deleted   bool      `json:"deleted,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// VolumeDigestHeader carries the digest of the volume's contents in the
+// response to a stream-in.
+const VolumeDigestHeader = "Volume-Digest"
+
+type VolumeDigestResponse struct {
+	Digest string `json:"digest"`
+}
+
+// ManifestEntry is a line of the newline-delimited JSON listing of a
+// volume's contents from GET /volumes/:handle/manifest, in lexical order of
+// their paths. A delta stream-in carries the entries that differ from it.
+type ManifestEntry struct {
+	Path string `json:"path"`
+
+	// Type is "directory", "file", "symlink", or "other".
+	Type    string      `json:"type"`
+	Mode    os.FileMode `json:"mode"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"mtime"`
+
+	// SHA256 is the hex digest of a file's contents.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Target is where a symlink points.
+	Target string `json:"target,omitempty"`
+}
+
+// FileEntry is an entry of the JSON array listing what is under a path in a
+// volume from GET /volumes/:handle/files, in lexical order of their paths.
+type FileEntry struct {
+	Path string `json:"path"`
+
+	// Type is "directory", "file", "symlink", or "other".
+	Type    string      `json:"type"`
+	Mode    os.FileMode `json:"mode"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"mtime"`
+
+	// Target is where a symlink points.
+	Target string `json:"target,omitempty"`
+}
+
+type InfoResponse struct {
+	Driver string `json:"driver"`
+}
+
+// The statuses of a HealthResponse.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// HealthResponse is sent with 200 when every check passed and with 503,
+// the status being HealthDegraded, when any of them failed.
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is how one of the checks of a HealthResponse went. Error is
+// empty if it passed.
+type HealthCheck struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
 }
 
 type VolumeStatsResponse struct {
-	SizeInBytes int64 `json:"size_in_bytes"`
+	SizeInBytes  int64 `json:"size_in_bytes"`
+	FileCount    int64 `json:"file_count"`
+	QuotaInBytes int64 `json:"quota_in_bytes,omitempty"`
+
+	// SizeExact is whether SizeInBytes is the backing store's own accounting
+	// of the volume, e.g. a btrfs qgroup, rather than an estimate from
+	// walking its files. It is the same for every driver, so callers need not
+	// know which backs the volume.
+	SizeExact bool `json:"size_exact"`
+
+	// FreeBytes is what is free on the volume's backing store, or 0 if the
+	// server can't find it.
+	FreeBytes uint64 `json:"free_bytes"`
+
+	Driver         string `json:"driver,omitempty"`
+	FilesystemType string `json:"filesystem_type,omitempty"`
+}
+
+// UsageResponse is how much of the volumes filesystem the volumes use, in all
+// and, if grouped by a property, by their values for it.
+type UsageResponse struct {
+	UsedBytes int64  `json:"used_bytes"`
+	FreeBytes uint64 `json:"free_bytes"`
+	Volumes   int    `json:"volumes"`
+
+	GroupedBy string                        `json:"grouped_by,omitempty"`
+	Groups    map[string]UsageGroupResponse `json:"groups,omitempty"`
+	Ungrouped *UsageGroupResponse           `json:"ungrouped,omitempty"`
+
+	// Failed lists the volumes that could not be sized, which are left out.
+	Failed []string `json:"failed,omitempty"`
+}
+
+type UsageGroupResponse struct {
+	UsedBytes int64 `json:"used_bytes"`
+	Volumes   int   `json:"volumes"`
+}
+
+// EventResync is the server-sent event every stream of volume events starts
+// with. The events from before it are not sent, so a client that had been
+// tracking the volumes may have missed some and should list them again.
+const EventResync = "resync"
+
+// VolumeEventResponse is the data of a server-sent volume event, whose type
+// is created, destroyed, expired, property-changed, or renamed.
+type VolumeEventResponse struct {
+	Handle         string           `json:"handle"`
+	PreviousHandle string           `json:"previous_handle,omitempty"`
+	Properties     VolumeProperties `json:"properties"`
+	Reason         string           `json:"reason,omitempty"`
+	At             time.Time        `json:"at"`
 }
 
+type MaterializeResponse struct {
+	// Mounted is whether the volume's mount was gone and had to be mounted
+	// again.
+	Mounted bool `json:"mounted"`
+}
+
+// PropertyRequest sets a property to Value. With Expected, the property is
+// only set if it has that value, and with ExpectAbsent, only if the volume
+// does not have it yet; the request is refused with 409 Conflict otherwise.
 type PropertyRequest struct {
+	Value        string  `json:"value"`
+	Expected     *string `json:"expected,omitempty"`
+	ExpectAbsent bool    `json:"expect_absent,omitempty"`
+}
+
+// PropertyResponse is the value of one of a volume's properties.
+type PropertyResponse struct {
 	Value string `json:"value"`
 }
 
+// TTLRequest sets either how many seconds the volume has left, or when it
+// expires, but not both. A time that has passed expires the volume at once.
 type TTLRequest struct {
-	Value uint `json:"value"`
+	Value     uint       `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// LeaseRequest takes out a lease on a volume for TTL seconds or, with the
+// Token of the lease held on it, extends that lease by as much from now.
+type LeaseRequest struct {
+	TTL   uint   `json:"ttl"`
+	Token string `json:"token,omitempty"`
+}
+
+type LeaseResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type PrivilegedRequest struct {
 	Value bool `json:"value"`
 }
+
+type SELinuxLabelRequest struct {
+	Label string `json:"label"`
+}
+
+type CommitRequest struct {
+	Freeze bool `json:"freeze,omitempty"`
+}
@@ -3,29 +3,112 @@ package baggageclaim
 import "github.com/tedsuo/rata"
 
 const (
+	GetInfo    = "GetInfo"
+	GetMetrics = "GetMetrics"
+	GetHealth  = "GetHealth"
+
+	GetGCFailures = "GetGCFailures"
+
 	ListVolumes    = "ListVolumes"
 	GetVolume      = "GetVolume"
+	VolumeExists   = "VolumeExists"
 	GetVolumeStats = "GetVolumeStats"
+	GetUsage       = "GetUsage"
+	StreamEvents   = "StreamEvents"
+	GetDigest      = "GetDigest"
+	GetManifest    = "GetManifest"
+	ListFiles      = "ListFiles"
+	GetChildren    = "GetChildren"
 	CreateVolume   = "CreateVolume"
+	CloneVolume    = "CloneVolume"
+	RenameVolume   = "RenameVolume"
+	PromoteVolume  = "PromoteVolume"
+	RestoreVolume  = "RestoreVolume"
 	DestroyVolume  = "DestroyVolume"
+	DestroyVolumes = "DestroyVolumes"
+
+	DestroyVolumesWithProperties = "DestroyVolumesWithProperties"
 
-	SetProperty   = "SetProperty"
-	SetTTL        = "SetTTL"
-	SetPrivileged = "SetPrivileged"
-	StreamIn      = "StreamIn"
-	StreamOut     = "StreamOut"
+	GetProperty     = "GetProperty"
+	SetProperty     = "SetProperty"
+	SetProperties   = "SetProperties"
+	DeleteProperty  = "DeleteProperty"
+	SetTTL          = "SetTTL"
+	SetPrivileged   = "SetPrivileged"
+	SetSELinuxLabel = "SetSELinuxLabel"
+	StreamIn        = "StreamIn"
+	StreamOut       = "StreamOut"
+	CopyIn          = "CopyIn"
+	CommitVolume    = "CommitVolume"
+	DiffVolumes     = "DiffVolumes"
+	TouchAccess     = "TouchAccess"
+	Materialize     = "Materialize"
+	AcquireLease    = "AcquireLease"
+	ReleaseLease    = "ReleaseLease"
 )
 
 var Routes = rata.Routes{
+	{Path: "/info", Method: "GET", Name: GetInfo},
+	{Path: "/metrics", Method: "GET", Name: GetMetrics},
+	{Path: "/health", Method: "GET", Name: GetHealth},
+
+	{Path: "/gc/failures", Method: "GET", Name: GetGCFailures},
+
 	{Path: "/volumes", Method: "GET", Name: ListVolumes},
 	{Path: "/volumes", Method: "POST", Name: CreateVolume},
+	{Path: "/volumes/destroy", Method: "POST", Name: DestroyVolumes},
+	{Path: "/volumes", Method: "DELETE", Name: DestroyVolumesWithProperties},
 
+	// before /volumes/:handle, which they would otherwise match
+	{Path: "/volumes/usage", Method: "GET", Name: GetUsage},
+	{Path: "/volumes/events", Method: "GET", Name: StreamEvents},
+
+	// before the GET on the same path, which rata also registers for HEAD
+	{Path: "/volumes/:handle", Method: "HEAD", Name: VolumeExists},
 	{Path: "/volumes/:handle", Method: "GET", Name: GetVolume},
 	{Path: "/volumes/:handle/stats", Method: "GET", Name: GetVolumeStats},
+	{Path: "/volumes/:handle/digest", Method: "GET", Name: GetDigest},
+	{Path: "/volumes/:handle/manifest", Method: "GET", Name: GetManifest},
+	{Path: "/volumes/:handle/files", Method: "GET", Name: ListFiles},
+	{Path: "/volumes/:handle/children", Method: "GET", Name: GetChildren},
+	{Path: "/volumes/:handle/properties", Method: "PUT", Name: SetProperties},
+	{Path: "/volumes/:handle/properties/:property", Method: "GET", Name: GetProperty},
 	{Path: "/volumes/:handle/properties/:property", Method: "PUT", Name: SetProperty},
+	{Path: "/volumes/:handle/properties/:property", Method: "DELETE", Name: DeleteProperty},
 	{Path: "/volumes/:handle/ttl", Method: "PUT", Name: SetTTL},
 	{Path: "/volumes/:handle/privileged", Method: "PUT", Name: SetPrivileged},
+	{Path: "/volumes/:handle/selinux-label", Method: "PUT", Name: SetSELinuxLabel},
 	{Path: "/volumes/:handle/stream-in", Method: "PUT", Name: StreamIn},
 	{Path: "/volumes/:handle/stream-out", Method: "PUT", Name: StreamOut},
+	{Path: "/volumes/:handle/copy-in", Method: "PUT", Name: CopyIn},
+	{Path: "/volumes/:handle/commit", Method: "POST", Name: CommitVolume},
+	{Path: "/volumes/:handle/diff", Method: "GET", Name: DiffVolumes},
+	{Path: "/volumes/:handle/touch-access", Method: "POST", Name: TouchAccess},
+	{Path: "/volumes/:handle/materialize", Method: "POST", Name: Materialize},
+	{Path: "/volumes/:handle/lease", Method: "POST", Name: AcquireLease},
+	{Path: "/volumes/:handle/lease", Method: "DELETE", Name: ReleaseLease},
+	{Path: "/volumes/:handle/clone", Method: "POST", Name: CloneVolume},
+	{Path: "/volumes/:handle/rename", Method: "POST", Name: RenameVolume},
+	{Path: "/volumes/:handle/promote", Method: "POST", Name: PromoteVolume},
+	{Path: "/volumes/:handle/restore", Method: "POST", Name: RestoreVolume},
 	{Path: "/volumes/:handle", Method: "DELETE", Name: DestroyVolume},
 }
+
+// StreamRoutes move a volume's data in or out of it, or from one volume into
+// another. A server shutting down lets those in flight finish, refusing new
+// ones.
+var StreamRoutes = map[string]bool{
+	StreamIn:  true,
+	StreamOut: true,
+	CopyIn:    true,
+}
+
+// CreateOrDestroyRoutes create or destroy volumes, which a server shutting
+// down refuses to do.
+var CreateOrDestroyRoutes = map[string]bool{
+	CreateVolume:                 true,
+	CloneVolume:                  true,
+	DestroyVolume:                true,
+	DestroyVolumes:               true,
+	DestroyVolumesWithProperties: true,
+}
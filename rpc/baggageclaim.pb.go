@@ -0,0 +1,1499 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v4.25.1
+// source: baggageclaim.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Volume struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Handle           string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path             string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Properties       map[string]string      `protobuf:"bytes,3,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TtlInSeconds     uint32                 `protobuf:"varint,4,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	ExpiresAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	ParentHandle     string                 `protobuf:"bytes,6,opt,name=parent_handle,json=parentHandle,proto3" json:"parent_handle,omitempty"`
+	PendingDestroy   bool                   `protobuf:"varint,7,opt,name=pending_destroy,json=pendingDestroy,proto3" json:"pending_destroy,omitempty"`
+	Committed        bool                   `protobuf:"varint,8,opt,name=committed,proto3" json:"committed,omitempty"`
+	CommittedAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	Frozen           bool                   `protobuf:"varint,10,opt,name=frozen,proto3" json:"frozen,omitempty"`
+	ReadOnly         bool                   `protobuf:"varint,11,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	MountOptions     []string               `protobuf:"bytes,12,rep,name=mount_options,json=mountOptions,proto3" json:"mount_options,omitempty"`
+	LastAccessedAt   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_accessed_at,json=lastAccessedAt,proto3" json:"last_accessed_at,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ModifiedAt       *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
+	Strategy         string                 `protobuf:"bytes,16,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Digest           string                 `protobuf:"bytes,17,opt,name=digest,proto3" json:"digest,omitempty"`
+	Driver           string                 `protobuf:"bytes,18,opt,name=driver,proto3" json:"driver,omitempty"`
+	FilesystemType   string                 `protobuf:"bytes,19,opt,name=filesystem_type,json=filesystemType,proto3" json:"filesystem_type,omitempty"`
+	RenewTtlOnAccess bool                   `protobuf:"varint,20,opt,name=renew_ttl_on_access,json=renewTtlOnAccess,proto3" json:"renew_ttl_on_access,omitempty"`
+	Encrypted        bool                   `protobuf:"varint,21,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Volume) Reset() {
+	*x = Volume{}
+	mi := &file_baggageclaim_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Volume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Volume) ProtoMessage() {}
+
+func (x *Volume) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Volume.ProtoReflect.Descriptor instead.
+func (*Volume) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Volume) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Volume) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Volume) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *Volume) GetTtlInSeconds() uint32 {
+	if x != nil {
+		return x.TtlInSeconds
+	}
+	return 0
+}
+
+func (x *Volume) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Volume) GetParentHandle() string {
+	if x != nil {
+		return x.ParentHandle
+	}
+	return ""
+}
+
+func (x *Volume) GetPendingDestroy() bool {
+	if x != nil {
+		return x.PendingDestroy
+	}
+	return false
+}
+
+func (x *Volume) GetCommitted() bool {
+	if x != nil {
+		return x.Committed
+	}
+	return false
+}
+
+func (x *Volume) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *Volume) GetFrozen() bool {
+	if x != nil {
+		return x.Frozen
+	}
+	return false
+}
+
+func (x *Volume) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *Volume) GetMountOptions() []string {
+	if x != nil {
+		return x.MountOptions
+	}
+	return nil
+}
+
+func (x *Volume) GetLastAccessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAccessedAt
+	}
+	return nil
+}
+
+func (x *Volume) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Volume) GetModifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return nil
+}
+
+func (x *Volume) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *Volume) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Volume) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Volume) GetFilesystemType() string {
+	if x != nil {
+		return x.FilesystemType
+	}
+	return ""
+}
+
+func (x *Volume) GetRenewTtlOnAccess() bool {
+	if x != nil {
+		return x.RenewTtlOnAccess
+	}
+	return false
+}
+
+func (x *Volume) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+type CreateVolumeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// handle is generated if it is empty.
+	Handle string `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	// strategy is what the JSON strategy of the HTTP API has, e.g. a "type"
+	// of "cow" and the "volume" to create the volume from.
+	Strategy            map[string]string `protobuf:"bytes,2,rep,name=strategy,proto3" json:"strategy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Properties          map[string]string `protobuf:"bytes,3,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TtlInSeconds        uint32            `protobuf:"varint,4,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	Privileged          bool              `protobuf:"varint,5,opt,name=privileged,proto3" json:"privileged,omitempty"`
+	ExpectedSizeInBytes int64             `protobuf:"varint,6,opt,name=expected_size_in_bytes,json=expectedSizeInBytes,proto3" json:"expected_size_in_bytes,omitempty"`
+	MutationHeavy       bool              `protobuf:"varint,7,opt,name=mutation_heavy,json=mutationHeavy,proto3" json:"mutation_heavy,omitempty"`
+	SizeInBytes         int64             `protobuf:"varint,8,opt,name=size_in_bytes,json=sizeInBytes,proto3" json:"size_in_bytes,omitempty"`
+	ReadOnly            bool              `protobuf:"varint,9,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	MountOptions        []string          `protobuf:"bytes,10,rep,name=mount_options,json=mountOptions,proto3" json:"mount_options,omitempty"`
+	RenewTtlOnAccess    bool              `protobuf:"varint,11,opt,name=renew_ttl_on_access,json=renewTtlOnAccess,proto3" json:"renew_ttl_on_access,omitempty"`
+	// idempotency_key identifies the create across retries. A create with the
+	// key of a volume already created with it gets that volume back, or fails
+	// with ALREADY_EXISTS if it asks for a different one.
+	IdempotencyKey string `protobuf:"bytes,12,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// encrypted has the volume's data encrypted where it is stored, as for
+	// the HTTP API.
+	Encrypted     bool `protobuf:"varint,13,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVolumeRequest) Reset() {
+	*x = CreateVolumeRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVolumeRequest) ProtoMessage() {}
+
+func (x *CreateVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVolumeRequest.ProtoReflect.Descriptor instead.
+func (*CreateVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateVolumeRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *CreateVolumeRequest) GetStrategy() map[string]string {
+	if x != nil {
+		return x.Strategy
+	}
+	return nil
+}
+
+func (x *CreateVolumeRequest) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *CreateVolumeRequest) GetTtlInSeconds() uint32 {
+	if x != nil {
+		return x.TtlInSeconds
+	}
+	return 0
+}
+
+func (x *CreateVolumeRequest) GetPrivileged() bool {
+	if x != nil {
+		return x.Privileged
+	}
+	return false
+}
+
+func (x *CreateVolumeRequest) GetExpectedSizeInBytes() int64 {
+	if x != nil {
+		return x.ExpectedSizeInBytes
+	}
+	return 0
+}
+
+func (x *CreateVolumeRequest) GetMutationHeavy() bool {
+	if x != nil {
+		return x.MutationHeavy
+	}
+	return false
+}
+
+func (x *CreateVolumeRequest) GetSizeInBytes() int64 {
+	if x != nil {
+		return x.SizeInBytes
+	}
+	return 0
+}
+
+func (x *CreateVolumeRequest) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *CreateVolumeRequest) GetMountOptions() []string {
+	if x != nil {
+		return x.MountOptions
+	}
+	return nil
+}
+
+func (x *CreateVolumeRequest) GetRenewTtlOnAccess() bool {
+	if x != nil {
+		return x.RenewTtlOnAccess
+	}
+	return false
+}
+
+func (x *CreateVolumeRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateVolumeRequest) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+type DestroyVolumeRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Handle string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	// reason is recorded in the destroy audit log.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// force destroys the volume's descendants along with it.
+	Force bool `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	// missing_ok succeeds for a volume that does not exist.
+	MissingOk  bool   `protobuf:"varint,4,opt,name=missing_ok,json=missingOk,proto3" json:"missing_ok,omitempty"`
+	LeaseToken string `protobuf:"bytes,5,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`
+	// dry_run reports what would be destroyed without destroying anything.
+	// It fails as the destroy would, even with missing_ok.
+	DryRun        bool `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyVolumeRequest) Reset() {
+	*x = DestroyVolumeRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyVolumeRequest) ProtoMessage() {}
+
+func (x *DestroyVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyVolumeRequest.ProtoReflect.Descriptor instead.
+func (*DestroyVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DestroyVolumeRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *DestroyVolumeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DestroyVolumeRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *DestroyVolumeRequest) GetMissingOk() bool {
+	if x != nil {
+		return x.MissingOk
+	}
+	return false
+}
+
+func (x *DestroyVolumeRequest) GetLeaseToken() string {
+	if x != nil {
+		return x.LeaseToken
+	}
+	return ""
+}
+
+func (x *DestroyVolumeRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type DestroyVolumeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// destroyed lists the volumes a forced destroy destroyed, or a dry run
+	// would, each one's descendants before it.
+	Destroyed     []string `protobuf:"bytes,1,rep,name=destroyed,proto3" json:"destroyed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyVolumeResponse) Reset() {
+	*x = DestroyVolumeResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyVolumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyVolumeResponse) ProtoMessage() {}
+
+func (x *DestroyVolumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyVolumeResponse.ProtoReflect.Descriptor instead.
+func (*DestroyVolumeResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DestroyVolumeResponse) GetDestroyed() []string {
+	if x != nil {
+		return x.Destroyed
+	}
+	return nil
+}
+
+type ListVolumesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// properties are the properties the volumes must have.
+	Properties map[string]string `protobuf:"bytes,1,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// limit, offset, and sort page through the volumes, as the query
+	// parameters of the same names do. A limit of 0 lists all of them.
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        uint32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Sort          string `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVolumesRequest) Reset() {
+	*x = ListVolumesRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVolumesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVolumesRequest) ProtoMessage() {}
+
+func (x *ListVolumesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVolumesRequest.ProtoReflect.Descriptor instead.
+func (*ListVolumesRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListVolumesRequest) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *ListVolumesRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListVolumesRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListVolumesRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ListVolumesResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Volumes []*Volume              `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	// count is how many volumes there are across all pages.
+	Count         uint32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVolumesResponse) Reset() {
+	*x = ListVolumesResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVolumesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVolumesResponse) ProtoMessage() {}
+
+func (x *ListVolumesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVolumesResponse.ProtoReflect.Descriptor instead.
+func (*ListVolumesResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListVolumesResponse) GetVolumes() []*Volume {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *ListVolumesResponse) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVolumeRequest) Reset() {
+	*x = GetVolumeRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVolumeRequest) ProtoMessage() {}
+
+func (x *GetVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVolumeRequest.ProtoReflect.Descriptor instead.
+func (*GetVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetVolumeRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+type SetPropertyRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Handle string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Value  string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// expected, if set, is the value the property must have for it to be
+	// set, and expect_absent has it set only if the volume does not have it
+	// yet. The call fails with ABORTED otherwise.
+	Expected      *string `protobuf:"bytes,4,opt,name=expected,proto3,oneof" json:"expected,omitempty"`
+	ExpectAbsent  bool    `protobuf:"varint,5,opt,name=expect_absent,json=expectAbsent,proto3" json:"expect_absent,omitempty"`
+	LeaseToken    string  `protobuf:"bytes,6,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPropertyRequest) Reset() {
+	*x = SetPropertyRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPropertyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPropertyRequest) ProtoMessage() {}
+
+func (x *SetPropertyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPropertyRequest.ProtoReflect.Descriptor instead.
+func (*SetPropertyRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetPropertyRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *SetPropertyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SetPropertyRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SetPropertyRequest) GetExpected() string {
+	if x != nil && x.Expected != nil {
+		return *x.Expected
+	}
+	return ""
+}
+
+func (x *SetPropertyRequest) GetExpectAbsent() bool {
+	if x != nil {
+		return x.ExpectAbsent
+	}
+	return false
+}
+
+func (x *SetPropertyRequest) GetLeaseToken() string {
+	if x != nil {
+		return x.LeaseToken
+	}
+	return ""
+}
+
+type SetPropertyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPropertyResponse) Reset() {
+	*x = SetPropertyResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPropertyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPropertyResponse) ProtoMessage() {}
+
+func (x *SetPropertyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPropertyResponse.ProtoReflect.Descriptor instead.
+func (*SetPropertyResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{8}
+}
+
+type SetTTLRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Handle string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	// ttl_in_seconds, or expires_at if it is set, is when the volume expires.
+	// 0 never expires it.
+	TtlInSeconds  uint32                 `protobuf:"varint,2,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTTLRequest) Reset() {
+	*x = SetTTLRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTTLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTTLRequest) ProtoMessage() {}
+
+func (x *SetTTLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTTLRequest.ProtoReflect.Descriptor instead.
+func (*SetTTLRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetTTLRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *SetTTLRequest) GetTtlInSeconds() uint32 {
+	if x != nil {
+		return x.TtlInSeconds
+	}
+	return 0
+}
+
+func (x *SetTTLRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type SetTTLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTTLResponse) Reset() {
+	*x = SetTTLResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTTLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTTLResponse) ProtoMessage() {}
+
+func (x *SetTTLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTTLResponse.ProtoReflect.Descriptor instead.
+func (*SetTTLResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{10}
+}
+
+type StreamInRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// These are only read from the first message.
+	Handle          string `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path            string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	IdempotencyKey  string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	SelinuxLabel    string `protobuf:"bytes,4,opt,name=selinux_label,json=selinuxLabel,proto3" json:"selinux_label,omitempty"`
+	ContentEncoding string `protobuf:"bytes,5,opt,name=content_encoding,json=contentEncoding,proto3" json:"content_encoding,omitempty"`
+	Xattrs          bool   `protobuf:"varint,6,opt,name=xattrs,proto3" json:"xattrs,omitempty"`
+	Delta           bool   `protobuf:"varint,7,opt,name=delta,proto3" json:"delta,omitempty"`
+	Layer           bool   `protobuf:"varint,8,opt,name=layer,proto3" json:"layer,omitempty"`
+	LeaseToken      string `protobuf:"bytes,9,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`
+	// bytes_per_second can only lower the server's cap, if any.
+	BytesPerSecond int64  `protobuf:"varint,10,opt,name=bytes_per_second,json=bytesPerSecond,proto3" json:"bytes_per_second,omitempty"`
+	Chunk          []byte `protobuf:"bytes,11,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// checksum is the sha256:<hex> digest of the stream once decoded and
+	// decompressed. Unlike the fields above, it may be sent with any message,
+	// e.g. the last if it is only known then; the last one sent is checked.
+	Checksum      string `protobuf:"bytes,12,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamInRequest) Reset() {
+	*x = StreamInRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamInRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamInRequest) ProtoMessage() {}
+
+func (x *StreamInRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamInRequest.ProtoReflect.Descriptor instead.
+func (*StreamInRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamInRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetSelinuxLabel() string {
+	if x != nil {
+		return x.SelinuxLabel
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetContentEncoding() string {
+	if x != nil {
+		return x.ContentEncoding
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetXattrs() bool {
+	if x != nil {
+		return x.Xattrs
+	}
+	return false
+}
+
+func (x *StreamInRequest) GetDelta() bool {
+	if x != nil {
+		return x.Delta
+	}
+	return false
+}
+
+func (x *StreamInRequest) GetLayer() bool {
+	if x != nil {
+		return x.Layer
+	}
+	return false
+}
+
+func (x *StreamInRequest) GetLeaseToken() string {
+	if x != nil {
+		return x.LeaseToken
+	}
+	return ""
+}
+
+func (x *StreamInRequest) GetBytesPerSecond() int64 {
+	if x != nil {
+		return x.BytesPerSecond
+	}
+	return 0
+}
+
+func (x *StreamInRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *StreamInRequest) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type StreamInResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// digest is that of the volume's contents once streamed into.
+	Digest string `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// already_applied is set if the stream-in with the idempotency key had
+	// already been applied, and this one was not.
+	AlreadyApplied bool `protobuf:"varint,2,opt,name=already_applied,json=alreadyApplied,proto3" json:"already_applied,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StreamInResponse) Reset() {
+	*x = StreamInResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamInResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamInResponse) ProtoMessage() {}
+
+func (x *StreamInResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamInResponse.ProtoReflect.Descriptor instead.
+func (*StreamInResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StreamInResponse) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *StreamInResponse) GetAlreadyApplied() bool {
+	if x != nil {
+		return x.AlreadyApplied
+	}
+	return false
+}
+
+type StreamOutRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Handle string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path   string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// format is "tar", "file", or empty, as for the HTTP API.
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
+	ModifiedSince *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=modified_since,json=modifiedSince,proto3" json:"modified_since,omitempty"`
+	Consistent    bool                   `protobuf:"varint,5,opt,name=consistent,proto3" json:"consistent,omitempty"`
+	Xattrs        bool                   `protobuf:"varint,6,opt,name=xattrs,proto3" json:"xattrs,omitempty"`
+	Sparse        bool                   `protobuf:"varint,7,opt,name=sparse,proto3" json:"sparse,omitempty"`
+	// bytes_per_second can only lower the server's cap, if any.
+	BytesPerSecond int64 `protobuf:"varint,8,opt,name=bytes_per_second,json=bytesPerSecond,proto3" json:"bytes_per_second,omitempty"`
+	// canonical zeroes every mtime and leaves out owner names, so that trees
+	// with the same contents are streamed out as the same bytes.
+	Canonical     bool `protobuf:"varint,9,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOutRequest) Reset() {
+	*x = StreamOutRequest{}
+	mi := &file_baggageclaim_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOutRequest) ProtoMessage() {}
+
+func (x *StreamOutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOutRequest.ProtoReflect.Descriptor instead.
+func (*StreamOutRequest) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StreamOutRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *StreamOutRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StreamOutRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *StreamOutRequest) GetModifiedSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedSince
+	}
+	return nil
+}
+
+func (x *StreamOutRequest) GetConsistent() bool {
+	if x != nil {
+		return x.Consistent
+	}
+	return false
+}
+
+func (x *StreamOutRequest) GetXattrs() bool {
+	if x != nil {
+		return x.Xattrs
+	}
+	return false
+}
+
+func (x *StreamOutRequest) GetSparse() bool {
+	if x != nil {
+		return x.Sparse
+	}
+	return false
+}
+
+func (x *StreamOutRequest) GetBytesPerSecond() int64 {
+	if x != nil {
+		return x.BytesPerSecond
+	}
+	return 0
+}
+
+func (x *StreamOutRequest) GetCanonical() bool {
+	if x != nil {
+		return x.Canonical
+	}
+	return false
+}
+
+type StreamOutResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// format is what the path is streamed out as, and is only set on the
+	// first message.
+	Format        string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Chunk         []byte `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOutResponse) Reset() {
+	*x = StreamOutResponse{}
+	mi := &file_baggageclaim_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOutResponse) ProtoMessage() {}
+
+func (x *StreamOutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_baggageclaim_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOutResponse.ProtoReflect.Descriptor instead.
+func (*StreamOutResponse) Descriptor() ([]byte, []int) {
+	return file_baggageclaim_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamOutResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *StreamOutResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+var File_baggageclaim_proto protoreflect.FileDescriptor
+
+const file_baggageclaim_proto_rawDesc = "" +
+	"\n" +
+	"\x12baggageclaim.proto\x12\fbaggageclaim\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9f\a\n" +
+	"\x06Volume\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12D\n" +
+	"\n" +
+	"properties\x18\x03 \x03(\v2$.baggageclaim.Volume.PropertiesEntryR\n" +
+	"properties\x12$\n" +
+	"\x0ettl_in_seconds\x18\x04 \x01(\rR\fttlInSeconds\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12#\n" +
+	"\rparent_handle\x18\x06 \x01(\tR\fparentHandle\x12'\n" +
+	"\x0fpending_destroy\x18\a \x01(\bR\x0ependingDestroy\x12\x1c\n" +
+	"\tcommitted\x18\b \x01(\bR\tcommitted\x12=\n" +
+	"\fcommitted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x12\x16\n" +
+	"\x06frozen\x18\n" +
+	" \x01(\bR\x06frozen\x12\x1b\n" +
+	"\tread_only\x18\v \x01(\bR\breadOnly\x12#\n" +
+	"\rmount_options\x18\f \x03(\tR\fmountOptions\x12D\n" +
+	"\x10last_accessed_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\x0elastAccessedAt\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vmodified_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"modifiedAt\x12\x1a\n" +
+	"\bstrategy\x18\x10 \x01(\tR\bstrategy\x12\x16\n" +
+	"\x06digest\x18\x11 \x01(\tR\x06digest\x12\x16\n" +
+	"\x06driver\x18\x12 \x01(\tR\x06driver\x12'\n" +
+	"\x0ffilesystem_type\x18\x13 \x01(\tR\x0efilesystemType\x12-\n" +
+	"\x13renew_ttl_on_access\x18\x14 \x01(\bR\x10renewTtlOnAccess\x12\x1c\n" +
+	"\tencrypted\x18\x15 \x01(\bR\tencrypted\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc7\x05\n" +
+	"\x13CreateVolumeRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12K\n" +
+	"\bstrategy\x18\x02 \x03(\v2/.baggageclaim.CreateVolumeRequest.StrategyEntryR\bstrategy\x12Q\n" +
+	"\n" +
+	"properties\x18\x03 \x03(\v21.baggageclaim.CreateVolumeRequest.PropertiesEntryR\n" +
+	"properties\x12$\n" +
+	"\x0ettl_in_seconds\x18\x04 \x01(\rR\fttlInSeconds\x12\x1e\n" +
+	"\n" +
+	"privileged\x18\x05 \x01(\bR\n" +
+	"privileged\x123\n" +
+	"\x16expected_size_in_bytes\x18\x06 \x01(\x03R\x13expectedSizeInBytes\x12%\n" +
+	"\x0emutation_heavy\x18\a \x01(\bR\rmutationHeavy\x12\"\n" +
+	"\rsize_in_bytes\x18\b \x01(\x03R\vsizeInBytes\x12\x1b\n" +
+	"\tread_only\x18\t \x01(\bR\breadOnly\x12#\n" +
+	"\rmount_options\x18\n" +
+	" \x03(\tR\fmountOptions\x12-\n" +
+	"\x13renew_ttl_on_access\x18\v \x01(\bR\x10renewTtlOnAccess\x12'\n" +
+	"\x0fidempotency_key\x18\f \x01(\tR\x0eidempotencyKey\x12\x1c\n" +
+	"\tencrypted\x18\r \x01(\bR\tencrypted\x1a;\n" +
+	"\rStrategyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb5\x01\n" +
+	"\x14DestroyVolumeRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\x12\x1d\n" +
+	"\n" +
+	"missing_ok\x18\x04 \x01(\bR\tmissingOk\x12\x1f\n" +
+	"\vlease_token\x18\x05 \x01(\tR\n" +
+	"leaseToken\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\"5\n" +
+	"\x15DestroyVolumeResponse\x12\x1c\n" +
+	"\tdestroyed\x18\x01 \x03(\tR\tdestroyed\"\xe7\x01\n" +
+	"\x12ListVolumesRequest\x12P\n" +
+	"\n" +
+	"properties\x18\x01 \x03(\v20.baggageclaim.ListVolumesRequest.PropertiesEntryR\n" +
+	"properties\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\rR\x06offset\x12\x12\n" +
+	"\x04sort\x18\x04 \x01(\tR\x04sort\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"[\n" +
+	"\x13ListVolumesResponse\x12.\n" +
+	"\avolumes\x18\x01 \x03(\v2\x14.baggageclaim.VolumeR\avolumes\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\"*\n" +
+	"\x10GetVolumeRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\"\xca\x01\n" +
+	"\x12SetPropertyRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x1f\n" +
+	"\bexpected\x18\x04 \x01(\tH\x00R\bexpected\x88\x01\x01\x12#\n" +
+	"\rexpect_absent\x18\x05 \x01(\bR\fexpectAbsent\x12\x1f\n" +
+	"\vlease_token\x18\x06 \x01(\tR\n" +
+	"leaseTokenB\v\n" +
+	"\t_expected\"\x15\n" +
+	"\x13SetPropertyResponse\"\x88\x01\n" +
+	"\rSetTTLRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12$\n" +
+	"\x0ettl_in_seconds\x18\x02 \x01(\rR\fttlInSeconds\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x10\n" +
+	"\x0eSetTTLResponse\"\xf7\x02\n" +
+	"\x0fStreamInRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12#\n" +
+	"\rselinux_label\x18\x04 \x01(\tR\fselinuxLabel\x12)\n" +
+	"\x10content_encoding\x18\x05 \x01(\tR\x0fcontentEncoding\x12\x16\n" +
+	"\x06xattrs\x18\x06 \x01(\bR\x06xattrs\x12\x14\n" +
+	"\x05delta\x18\a \x01(\bR\x05delta\x12\x14\n" +
+	"\x05layer\x18\b \x01(\bR\x05layer\x12\x1f\n" +
+	"\vlease_token\x18\t \x01(\tR\n" +
+	"leaseToken\x12(\n" +
+	"\x10bytes_per_second\x18\n" +
+	" \x01(\x03R\x0ebytesPerSecond\x12\x14\n" +
+	"\x05chunk\x18\v \x01(\fR\x05chunk\x12\x1a\n" +
+	"\bchecksum\x18\f \x01(\tR\bchecksum\"S\n" +
+	"\x10StreamInResponse\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12'\n" +
+	"\x0falready_applied\x18\x02 \x01(\bR\x0ealreadyApplied\"\xb1\x02\n" +
+	"\x10StreamOutRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12A\n" +
+	"\x0emodified_since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rmodifiedSince\x12\x1e\n" +
+	"\n" +
+	"consistent\x18\x05 \x01(\bR\n" +
+	"consistent\x12\x16\n" +
+	"\x06xattrs\x18\x06 \x01(\bR\x06xattrs\x12\x16\n" +
+	"\x06sparse\x18\a \x01(\bR\x06sparse\x12(\n" +
+	"\x10bytes_per_second\x18\b \x01(\x03R\x0ebytesPerSecond\x12\x1c\n" +
+	"\tcanonical\x18\t \x01(\bR\tcanonical\"A\n" +
+	"\x11StreamOutResponse\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x14\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk2\xfe\x04\n" +
+	"\fBaggageclaim\x12G\n" +
+	"\fCreateVolume\x12!.baggageclaim.CreateVolumeRequest\x1a\x14.baggageclaim.Volume\x12X\n" +
+	"\rDestroyVolume\x12\".baggageclaim.DestroyVolumeRequest\x1a#.baggageclaim.DestroyVolumeResponse\x12R\n" +
+	"\vListVolumes\x12 .baggageclaim.ListVolumesRequest\x1a!.baggageclaim.ListVolumesResponse\x12A\n" +
+	"\tGetVolume\x12\x1e.baggageclaim.GetVolumeRequest\x1a\x14.baggageclaim.Volume\x12R\n" +
+	"\vSetProperty\x12 .baggageclaim.SetPropertyRequest\x1a!.baggageclaim.SetPropertyResponse\x12C\n" +
+	"\x06SetTTL\x12\x1b.baggageclaim.SetTTLRequest\x1a\x1c.baggageclaim.SetTTLResponse\x12K\n" +
+	"\bStreamIn\x12\x1d.baggageclaim.StreamInRequest\x1a\x1e.baggageclaim.StreamInResponse(\x01\x12N\n" +
+	"\tStreamOut\x12\x1e.baggageclaim.StreamOutRequest\x1a\x1f.baggageclaim.StreamOutResponse0\x01B'Z%github.com/concourse/baggageclaim/rpcb\x06proto3"
+
+var (
+	file_baggageclaim_proto_rawDescOnce sync.Once
+	file_baggageclaim_proto_rawDescData []byte
+)
+
+func file_baggageclaim_proto_rawDescGZIP() []byte {
+	file_baggageclaim_proto_rawDescOnce.Do(func() {
+		file_baggageclaim_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_baggageclaim_proto_rawDesc), len(file_baggageclaim_proto_rawDesc)))
+	})
+	return file_baggageclaim_proto_rawDescData
+}
+
+var file_baggageclaim_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_baggageclaim_proto_goTypes = []any{
+	(*Volume)(nil),                // 0: baggageclaim.Volume
+	(*CreateVolumeRequest)(nil),   // 1: baggageclaim.CreateVolumeRequest
+	(*DestroyVolumeRequest)(nil),  // 2: baggageclaim.DestroyVolumeRequest
+	(*DestroyVolumeResponse)(nil), // 3: baggageclaim.DestroyVolumeResponse
+	(*ListVolumesRequest)(nil),    // 4: baggageclaim.ListVolumesRequest
+	(*ListVolumesResponse)(nil),   // 5: baggageclaim.ListVolumesResponse
+	(*GetVolumeRequest)(nil),      // 6: baggageclaim.GetVolumeRequest
+	(*SetPropertyRequest)(nil),    // 7: baggageclaim.SetPropertyRequest
+	(*SetPropertyResponse)(nil),   // 8: baggageclaim.SetPropertyResponse
+	(*SetTTLRequest)(nil),         // 9: baggageclaim.SetTTLRequest
+	(*SetTTLResponse)(nil),        // 10: baggageclaim.SetTTLResponse
+	(*StreamInRequest)(nil),       // 11: baggageclaim.StreamInRequest
+	(*StreamInResponse)(nil),      // 12: baggageclaim.StreamInResponse
+	(*StreamOutRequest)(nil),      // 13: baggageclaim.StreamOutRequest
+	(*StreamOutResponse)(nil),     // 14: baggageclaim.StreamOutResponse
+	nil,                           // 15: baggageclaim.Volume.PropertiesEntry
+	nil,                           // 16: baggageclaim.CreateVolumeRequest.StrategyEntry
+	nil,                           // 17: baggageclaim.CreateVolumeRequest.PropertiesEntry
+	nil,                           // 18: baggageclaim.ListVolumesRequest.PropertiesEntry
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+}
+var file_baggageclaim_proto_depIdxs = []int32{
+	15, // 0: baggageclaim.Volume.properties:type_name -> baggageclaim.Volume.PropertiesEntry
+	19, // 1: baggageclaim.Volume.expires_at:type_name -> google.protobuf.Timestamp
+	19, // 2: baggageclaim.Volume.committed_at:type_name -> google.protobuf.Timestamp
+	19, // 3: baggageclaim.Volume.last_accessed_at:type_name -> google.protobuf.Timestamp
+	19, // 4: baggageclaim.Volume.created_at:type_name -> google.protobuf.Timestamp
+	19, // 5: baggageclaim.Volume.modified_at:type_name -> google.protobuf.Timestamp
+	16, // 6: baggageclaim.CreateVolumeRequest.strategy:type_name -> baggageclaim.CreateVolumeRequest.StrategyEntry
+	17, // 7: baggageclaim.CreateVolumeRequest.properties:type_name -> baggageclaim.CreateVolumeRequest.PropertiesEntry
+	18, // 8: baggageclaim.ListVolumesRequest.properties:type_name -> baggageclaim.ListVolumesRequest.PropertiesEntry
+	0,  // 9: baggageclaim.ListVolumesResponse.volumes:type_name -> baggageclaim.Volume
+	19, // 10: baggageclaim.SetTTLRequest.expires_at:type_name -> google.protobuf.Timestamp
+	19, // 11: baggageclaim.StreamOutRequest.modified_since:type_name -> google.protobuf.Timestamp
+	1,  // 12: baggageclaim.Baggageclaim.CreateVolume:input_type -> baggageclaim.CreateVolumeRequest
+	2,  // 13: baggageclaim.Baggageclaim.DestroyVolume:input_type -> baggageclaim.DestroyVolumeRequest
+	4,  // 14: baggageclaim.Baggageclaim.ListVolumes:input_type -> baggageclaim.ListVolumesRequest
+	6,  // 15: baggageclaim.Baggageclaim.GetVolume:input_type -> baggageclaim.GetVolumeRequest
+	7,  // 16: baggageclaim.Baggageclaim.SetProperty:input_type -> baggageclaim.SetPropertyRequest
+	9,  // 17: baggageclaim.Baggageclaim.SetTTL:input_type -> baggageclaim.SetTTLRequest
+	11, // 18: baggageclaim.Baggageclaim.StreamIn:input_type -> baggageclaim.StreamInRequest
+	13, // 19: baggageclaim.Baggageclaim.StreamOut:input_type -> baggageclaim.StreamOutRequest
+	0,  // 20: baggageclaim.Baggageclaim.CreateVolume:output_type -> baggageclaim.Volume
+	3,  // 21: baggageclaim.Baggageclaim.DestroyVolume:output_type -> baggageclaim.DestroyVolumeResponse
+	5,  // 22: baggageclaim.Baggageclaim.ListVolumes:output_type -> baggageclaim.ListVolumesResponse
+	0,  // 23: baggageclaim.Baggageclaim.GetVolume:output_type -> baggageclaim.Volume
+	8,  // 24: baggageclaim.Baggageclaim.SetProperty:output_type -> baggageclaim.SetPropertyResponse
+	10, // 25: baggageclaim.Baggageclaim.SetTTL:output_type -> baggageclaim.SetTTLResponse
+	12, // 26: baggageclaim.Baggageclaim.StreamIn:output_type -> baggageclaim.StreamInResponse
+	14, // 27: baggageclaim.Baggageclaim.StreamOut:output_type -> baggageclaim.StreamOutResponse
+	20, // [20:28] is the sub-list for method output_type
+	12, // [12:20] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_baggageclaim_proto_init() }
+func file_baggageclaim_proto_init() {
+	if File_baggageclaim_proto != nil {
+		return
+	}
+	file_baggageclaim_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_baggageclaim_proto_rawDesc), len(file_baggageclaim_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_baggageclaim_proto_goTypes,
+		DependencyIndexes: file_baggageclaim_proto_depIdxs,
+		MessageInfos:      file_baggageclaim_proto_msgTypes,
+	}.Build()
+	File_baggageclaim_proto = out.File
+	file_baggageclaim_proto_goTypes = nil
+	file_baggageclaim_proto_depIdxs = nil
+}
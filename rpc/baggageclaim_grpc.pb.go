@@ -0,0 +1,406 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.1
+// source: baggageclaim.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Baggageclaim_CreateVolume_FullMethodName  = "/baggageclaim.Baggageclaim/CreateVolume"
+	Baggageclaim_DestroyVolume_FullMethodName = "/baggageclaim.Baggageclaim/DestroyVolume"
+	Baggageclaim_ListVolumes_FullMethodName   = "/baggageclaim.Baggageclaim/ListVolumes"
+	Baggageclaim_GetVolume_FullMethodName     = "/baggageclaim.Baggageclaim/GetVolume"
+	Baggageclaim_SetProperty_FullMethodName   = "/baggageclaim.Baggageclaim/SetProperty"
+	Baggageclaim_SetTTL_FullMethodName        = "/baggageclaim.Baggageclaim/SetTTL"
+	Baggageclaim_StreamIn_FullMethodName      = "/baggageclaim.Baggageclaim/StreamIn"
+	Baggageclaim_StreamOut_FullMethodName     = "/baggageclaim.Baggageclaim/StreamOut"
+)
+
+// BaggageclaimClient is the client API for Baggageclaim service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Baggageclaim serves the volume API over gRPC, alongside the HTTP API. Each
+// call does what the HTTP route of the same name does, and fails with the
+// gRPC code closest to the HTTP status that route would respond with.
+type BaggageclaimClient interface {
+	CreateVolume(ctx context.Context, in *CreateVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	DestroyVolume(ctx context.Context, in *DestroyVolumeRequest, opts ...grpc.CallOption) (*DestroyVolumeResponse, error)
+	ListVolumes(ctx context.Context, in *ListVolumesRequest, opts ...grpc.CallOption) (*ListVolumesResponse, error)
+	GetVolume(ctx context.Context, in *GetVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	SetProperty(ctx context.Context, in *SetPropertyRequest, opts ...grpc.CallOption) (*SetPropertyResponse, error)
+	SetTTL(ctx context.Context, in *SetTTLRequest, opts ...grpc.CallOption) (*SetTTLResponse, error)
+	// StreamIn streams a tar into a volume, as chunks. The first message says
+	// where to stream it and how; the rest only carry chunks. Chunks are only
+	// received as fast as they are extracted, and canceling the call rolls
+	// back what had been written.
+	StreamIn(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[StreamInRequest, StreamInResponse], error)
+	// StreamOut streams a path in a volume out as chunks, of a tar or of the
+	// file at it. Chunks are only read from the volume as fast as they are
+	// sent.
+	StreamOut(ctx context.Context, in *StreamOutRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamOutResponse], error)
+}
+
+type baggageclaimClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBaggageclaimClient(cc grpc.ClientConnInterface) BaggageclaimClient {
+	return &baggageclaimClient{cc}
+}
+
+func (c *baggageclaimClient) CreateVolume(ctx context.Context, in *CreateVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, Baggageclaim_CreateVolume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) DestroyVolume(ctx context.Context, in *DestroyVolumeRequest, opts ...grpc.CallOption) (*DestroyVolumeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DestroyVolumeResponse)
+	err := c.cc.Invoke(ctx, Baggageclaim_DestroyVolume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) ListVolumes(ctx context.Context, in *ListVolumesRequest, opts ...grpc.CallOption) (*ListVolumesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVolumesResponse)
+	err := c.cc.Invoke(ctx, Baggageclaim_ListVolumes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) GetVolume(ctx context.Context, in *GetVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, Baggageclaim_GetVolume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) SetProperty(ctx context.Context, in *SetPropertyRequest, opts ...grpc.CallOption) (*SetPropertyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPropertyResponse)
+	err := c.cc.Invoke(ctx, Baggageclaim_SetProperty_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) SetTTL(ctx context.Context, in *SetTTLRequest, opts ...grpc.CallOption) (*SetTTLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetTTLResponse)
+	err := c.cc.Invoke(ctx, Baggageclaim_SetTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *baggageclaimClient) StreamIn(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[StreamInRequest, StreamInResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Baggageclaim_ServiceDesc.Streams[0], Baggageclaim_StreamIn_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamInRequest, StreamInResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Baggageclaim_StreamInClient = grpc.ClientStreamingClient[StreamInRequest, StreamInResponse]
+
+func (c *baggageclaimClient) StreamOut(ctx context.Context, in *StreamOutRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamOutResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Baggageclaim_ServiceDesc.Streams[1], Baggageclaim_StreamOut_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamOutRequest, StreamOutResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Baggageclaim_StreamOutClient = grpc.ServerStreamingClient[StreamOutResponse]
+
+// BaggageclaimServer is the server API for Baggageclaim service.
+// All implementations must embed UnimplementedBaggageclaimServer
+// for forward compatibility.
+//
+// Baggageclaim serves the volume API over gRPC, alongside the HTTP API. Each
+// call does what the HTTP route of the same name does, and fails with the
+// gRPC code closest to the HTTP status that route would respond with.
+type BaggageclaimServer interface {
+	CreateVolume(context.Context, *CreateVolumeRequest) (*Volume, error)
+	DestroyVolume(context.Context, *DestroyVolumeRequest) (*DestroyVolumeResponse, error)
+	ListVolumes(context.Context, *ListVolumesRequest) (*ListVolumesResponse, error)
+	GetVolume(context.Context, *GetVolumeRequest) (*Volume, error)
+	SetProperty(context.Context, *SetPropertyRequest) (*SetPropertyResponse, error)
+	SetTTL(context.Context, *SetTTLRequest) (*SetTTLResponse, error)
+	// StreamIn streams a tar into a volume, as chunks. The first message says
+	// where to stream it and how; the rest only carry chunks. Chunks are only
+	// received as fast as they are extracted, and canceling the call rolls
+	// back what had been written.
+	StreamIn(grpc.ClientStreamingServer[StreamInRequest, StreamInResponse]) error
+	// StreamOut streams a path in a volume out as chunks, of a tar or of the
+	// file at it. Chunks are only read from the volume as fast as they are
+	// sent.
+	StreamOut(*StreamOutRequest, grpc.ServerStreamingServer[StreamOutResponse]) error
+	mustEmbedUnimplementedBaggageclaimServer()
+}
+
+// UnimplementedBaggageclaimServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBaggageclaimServer struct{}
+
+func (UnimplementedBaggageclaimServer) CreateVolume(context.Context, *CreateVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVolume not implemented")
+}
+func (UnimplementedBaggageclaimServer) DestroyVolume(context.Context, *DestroyVolumeRequest) (*DestroyVolumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DestroyVolume not implemented")
+}
+func (UnimplementedBaggageclaimServer) ListVolumes(context.Context, *ListVolumesRequest) (*ListVolumesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVolumes not implemented")
+}
+func (UnimplementedBaggageclaimServer) GetVolume(context.Context, *GetVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolume not implemented")
+}
+func (UnimplementedBaggageclaimServer) SetProperty(context.Context, *SetPropertyRequest) (*SetPropertyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetProperty not implemented")
+}
+func (UnimplementedBaggageclaimServer) SetTTL(context.Context, *SetTTLRequest) (*SetTTLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTTL not implemented")
+}
+func (UnimplementedBaggageclaimServer) StreamIn(grpc.ClientStreamingServer[StreamInRequest, StreamInResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamIn not implemented")
+}
+func (UnimplementedBaggageclaimServer) StreamOut(*StreamOutRequest, grpc.ServerStreamingServer[StreamOutResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOut not implemented")
+}
+func (UnimplementedBaggageclaimServer) mustEmbedUnimplementedBaggageclaimServer() {}
+func (UnimplementedBaggageclaimServer) testEmbeddedByValue()                      {}
+
+// UnsafeBaggageclaimServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BaggageclaimServer will
+// result in compilation errors.
+type UnsafeBaggageclaimServer interface {
+	mustEmbedUnimplementedBaggageclaimServer()
+}
+
+func RegisterBaggageclaimServer(s grpc.ServiceRegistrar, srv BaggageclaimServer) {
+	// If the following call pancis, it indicates UnimplementedBaggageclaimServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Baggageclaim_ServiceDesc, srv)
+}
+
+func _Baggageclaim_CreateVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).CreateVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_CreateVolume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).CreateVolume(ctx, req.(*CreateVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_DestroyVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DestroyVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).DestroyVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_DestroyVolume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).DestroyVolume(ctx, req.(*DestroyVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_ListVolumes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVolumesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).ListVolumes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_ListVolumes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).ListVolumes(ctx, req.(*ListVolumesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_GetVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).GetVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_GetVolume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).GetVolume(ctx, req.(*GetVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_SetProperty_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPropertyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).SetProperty(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_SetProperty_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).SetProperty(ctx, req.(*SetPropertyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_SetTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTTLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BaggageclaimServer).SetTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Baggageclaim_SetTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BaggageclaimServer).SetTTL(ctx, req.(*SetTTLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Baggageclaim_StreamIn_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BaggageclaimServer).StreamIn(&grpc.GenericServerStream[StreamInRequest, StreamInResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Baggageclaim_StreamInServer = grpc.ClientStreamingServer[StreamInRequest, StreamInResponse]
+
+func _Baggageclaim_StreamOut_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOutRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BaggageclaimServer).StreamOut(m, &grpc.GenericServerStream[StreamOutRequest, StreamOutResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Baggageclaim_StreamOutServer = grpc.ServerStreamingServer[StreamOutResponse]
+
+// Baggageclaim_ServiceDesc is the grpc.ServiceDesc for Baggageclaim service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Baggageclaim_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "baggageclaim.Baggageclaim",
+	HandlerType: (*BaggageclaimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateVolume",
+			Handler:    _Baggageclaim_CreateVolume_Handler,
+		},
+		{
+			MethodName: "DestroyVolume",
+			Handler:    _Baggageclaim_DestroyVolume_Handler,
+		},
+		{
+			MethodName: "ListVolumes",
+			Handler:    _Baggageclaim_ListVolumes_Handler,
+		},
+		{
+			MethodName: "GetVolume",
+			Handler:    _Baggageclaim_GetVolume_Handler,
+		},
+		{
+			MethodName: "SetProperty",
+			Handler:    _Baggageclaim_SetProperty_Handler,
+		},
+		{
+			MethodName: "SetTTL",
+			Handler:    _Baggageclaim_SetTTL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamIn",
+			Handler:       _Baggageclaim_StreamIn_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamOut",
+			Handler:       _Baggageclaim_StreamOut_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "baggageclaim.proto",
+}
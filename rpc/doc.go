@@ -0,0 +1,5 @@
+// Package rpc holds the messages and service of the gRPC API, generated from
+// baggageclaim.proto. The service is implemented in the api package.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative baggageclaim.proto
@@ -0,0 +1,47 @@
+package uidgid
+
+import "fmt"
+
+// IDRange is a range of host IDs that the IDs of unprivileged volumes are
+// shifted into, e.g. a subordinate ID range of a rootless worker: ID n of an
+// unprivileged volume is UIDBase+n or GIDBase+n on the host, for n below
+// Size. The zero IDRange keeps the default mapping, which only shifts root,
+// to the highest valid ID, and leaves every other ID as it is.
+type IDRange struct {
+	UIDBase int
+	GIDBase int
+	Size    int
+}
+
+// Validate returns an error if the range can't be mapped on a host whose
+// highest valid IDs are maxUID and maxGID. The host IDs must not overlap the
+// IDs they are shifted from, or data that was already shifted, e.g. that of
+// an unprivileged parent copied for a copy-on-write volume, would be shifted
+// again.
+func (ids IDRange) Validate(maxUID int, maxGID int) error {
+	if ids.UIDBase < 0 || ids.GIDBase < 0 || ids.Size < 0 {
+		return fmt.Errorf("unprivileged ID bases and range may not be negative: %d, %d, %d", ids.UIDBase, ids.GIDBase, ids.Size)
+	}
+
+	if ids.Size == 0 {
+		return nil
+	}
+
+	if ids.UIDBase < ids.Size {
+		return fmt.Errorf("unprivileged UID base %d overlaps the %d IDs it maps", ids.UIDBase, ids.Size)
+	}
+
+	if ids.GIDBase < ids.Size {
+		return fmt.Errorf("unprivileged GID base %d overlaps the %d IDs it maps", ids.GIDBase, ids.Size)
+	}
+
+	if ids.UIDBase+ids.Size-1 > maxUID {
+		return fmt.Errorf("unprivileged UIDs %d-%d go past the highest valid UID %d", ids.UIDBase, ids.UIDBase+ids.Size-1, maxUID)
+	}
+
+	if ids.GIDBase+ids.Size-1 > maxGID {
+		return fmt.Errorf("unprivileged GIDs %d-%d go past the highest valid GID %d", ids.GIDBase, ids.GIDBase+ids.Size-1, maxGID)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package uidgid_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/uidgid"
+)
+
+var _ = Describe("IDRange", func() {
+	Describe("Validate", func() {
+		const maxUID = 4294967294
+		const maxGID = 4294967293
+
+		It("allows the zero range", func() {
+			Expect(uidgid.IDRange{}.Validate(maxUID, maxGID)).To(Succeed())
+		})
+
+		It("allows bases with no range, as they are not used", func() {
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 100000}.Validate(maxUID, maxGID)).To(Succeed())
+		})
+
+		It("allows a range from bases at least its size", func() {
+			Expect(uidgid.IDRange{UIDBase: 65536, GIDBase: 200000, Size: 65536}.Validate(maxUID, maxGID)).To(Succeed())
+		})
+
+		It("refuses negative bases and ranges", func() {
+			Expect(uidgid.IDRange{UIDBase: -1, GIDBase: 100000, Size: 65536}.Validate(maxUID, maxGID)).NotTo(Succeed())
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: -1, Size: 65536}.Validate(maxUID, maxGID)).NotTo(Succeed())
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 100000, Size: -1}.Validate(maxUID, maxGID)).NotTo(Succeed())
+		})
+
+		It("refuses bases that overlap the IDs they map", func() {
+			Expect(uidgid.IDRange{UIDBase: 65535, GIDBase: 100000, Size: 65536}.Validate(maxUID, maxGID)).NotTo(Succeed())
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 65535, Size: 65536}.Validate(maxUID, maxGID)).NotTo(Succeed())
+		})
+
+		It("allows a range ending at the highest valid IDs", func() {
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 100000, Size: 65536}.Validate(165535, 165535)).To(Succeed())
+		})
+
+		It("refuses a range going past the highest valid IDs", func() {
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 100000, Size: 65536}.Validate(165534, 165535)).NotTo(Succeed())
+			Expect(uidgid.IDRange{UIDBase: 100000, GIDBase: 100000, Size: 65536}.Validate(165535, 165534)).NotTo(Succeed())
+		})
+	})
+})
@@ -10,7 +10,20 @@ type uidGidMapper struct {
 	gids []syscall.SysProcIDMap
 }
 
-func NewPrivilegedMapper() Mapper {
+// NewPrivilegedMapper maps the IDs of unprivileged volumes back to what they
+// are shifted from, for volumes made privileged.
+func NewPrivilegedMapper(ids IDRange) Mapper {
+	if ids.Size > 0 {
+		return uidGidMapper{
+			uids: []syscall.SysProcIDMap{
+				{ContainerID: ids.UIDBase, HostID: 0, Size: ids.Size},
+			},
+			gids: []syscall.SysProcIDMap{
+				{ContainerID: ids.GIDBase, HostID: 0, Size: ids.Size},
+			},
+		}
+	}
+
 	maxID := min(MustGetMaxValidUID(), MustGetMaxValidGID())
 
 	return uidGidMapper{
@@ -25,7 +38,21 @@ func NewPrivilegedMapper() Mapper {
 	}
 }
 
-func NewUnprivilegedMapper() Mapper {
+// NewUnprivilegedMapper shifts IDs into the range, both for what an
+// unprivileged volume's commands write and for data already written, e.g.
+// that of a privileged parent copied for a copy-on-write volume.
+func NewUnprivilegedMapper(ids IDRange) Mapper {
+	if ids.Size > 0 {
+		return uidGidMapper{
+			uids: []syscall.SysProcIDMap{
+				{ContainerID: 0, HostID: ids.UIDBase, Size: ids.Size},
+			},
+			gids: []syscall.SysProcIDMap{
+				{ContainerID: 0, HostID: ids.GIDBase, Size: ids.Size},
+			},
+		}
+	}
+
 	maxID := min(MustGetMaxValidUID(), MustGetMaxValidGID())
 
 	return uidGidMapper{
@@ -52,12 +79,8 @@ func (m uidGidMapper) Apply(cmd *exec.Cmd) {
 
 func findMapping(idMap []syscall.SysProcIDMap, fromID int) int {
 	for _, id := range idMap {
-		if id.Size != 1 {
-			continue
-		}
-
-		if id.ContainerID == fromID {
-			return id.HostID
+		if fromID >= id.ContainerID && fromID < id.ContainerID+id.Size {
+			return id.HostID + fromID - id.ContainerID
 		}
 	}
 
@@ -0,0 +1,62 @@
+package uidgid_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/uidgid"
+)
+
+var _ = Describe("Mappers", func() {
+	ids := uidgid.IDRange{UIDBase: 100000, GIDBase: 200000, Size: 65536}
+
+	Describe("NewUnprivilegedMapper", func() {
+		Context("with a range", func() {
+			mapper := uidgid.NewUnprivilegedMapper(ids)
+
+			It("shifts IDs in the range onto the bases", func() {
+				Expect(mapMapper(mapper, 0, 0)).To(Equal([2]int{100000, 200000}))
+				Expect(mapMapper(mapper, 1000, 1001)).To(Equal([2]int{101000, 201001}))
+			})
+
+			It("shifts the last ID in the range onto the end of the host IDs", func() {
+				Expect(mapMapper(mapper, 65535, 65535)).To(Equal([2]int{165535, 265535}))
+			})
+
+			It("leaves IDs past the range as they are", func() {
+				Expect(mapMapper(mapper, 65536, 70000)).To(Equal([2]int{65536, 70000}))
+			})
+		})
+
+		Context("without a range", func() {
+			It("only shifts root, to the highest valid ID", func() {
+				mapper := uidgid.NewUnprivilegedMapper(uidgid.IDRange{})
+
+				Expect(mapMapper(mapper, 0, 0)).To(Equal([2]int{uidgid.MustGetMaxValidUID(), uidgid.MustGetMaxValidGID()}))
+				Expect(mapMapper(mapper, 1000, 1001)).To(Equal([2]int{1000, 1001}))
+			})
+		})
+	})
+
+	Describe("NewPrivilegedMapper", func() {
+		It("shifts IDs in the range back off the bases", func() {
+			mapper := uidgid.NewPrivilegedMapper(ids)
+
+			Expect(mapMapper(mapper, 100000, 200000)).To(Equal([2]int{0, 0}))
+			Expect(mapMapper(mapper, 101000, 201001)).To(Equal([2]int{1000, 1001}))
+			Expect(mapMapper(mapper, 165535, 265535)).To(Equal([2]int{65535, 65535}))
+		})
+
+		It("leaves IDs outside of the range as they are", func() {
+			mapper := uidgid.NewPrivilegedMapper(ids)
+
+			Expect(mapMapper(mapper, 99999, 199999)).To(Equal([2]int{99999, 199999}))
+			Expect(mapMapper(mapper, 165536, 265536)).To(Equal([2]int{165536, 265536}))
+		})
+	})
+})
+
+func mapMapper(mapper uidgid.Mapper, uid int, gid int) [2]int {
+	mappedUID, mappedGID := mapper.Map(uid, gid)
+	return [2]int{mappedUID, mappedGID}
+}
@@ -8,11 +8,11 @@ import (
 
 type noopMapper struct{}
 
-func NewPrivilegedMapper() Mapper {
+func NewPrivilegedMapper(ids IDRange) Mapper {
 	return noopMapper{}
 }
 
-func NewUnprivilegedMapper() Mapper {
+func NewUnprivilegedMapper(ids IDRange) Mapper {
 	return noopMapper{}
 }
 
@@ -0,0 +1,13 @@
+package uidgid_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestUidgid(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UID/GID Suite")
+}
@@ -0,0 +1,169 @@
+package volume
+
+import (
+	"sort"
+	"sync"
+)
+
+// childIndex maps the handles of volumes to those of their copy-on-write
+// children, so that destroying a volume doesn't have to look at every other
+// one to tell whether anything still depends on its data. Views are left
+// out, as their bases are released rather than kept; the repository keeps
+// another childIndex of bases and their views to tell when to release them.
+//
+// Like the property index, it is built from the first scan of the volumes,
+// and kept up to date by the repository from then on.
+type childIndex struct {
+	lock  sync.RWMutex
+	built bool
+
+	// parent -> children
+	children map[string]map[string]bool
+
+	// child -> parent
+	parents map[string]string
+}
+
+func newChildIndex() *childIndex {
+	return &childIndex{
+		children: map[string]map[string]bool{},
+		parents:  map[string]string{},
+	}
+}
+
+// Children returns the handles of the volume's copy-on-write children, in
+// order. If the index has not been built yet, it is built from scan first,
+// which returns the parent of each copy-on-write child by its handle.
+func (index *childIndex) Children(handle string, scan func() (map[string]string, error)) ([]string, error) {
+	err := index.build(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+
+	children := []string{}
+	for child := range index.children[handle] {
+		children = append(children, child)
+	}
+
+	sort.Strings(children)
+
+	return children, nil
+}
+
+func (index *childIndex) build(scan func() (map[string]string, error)) error {
+	index.lock.RLock()
+	built := index.built
+	index.lock.RUnlock()
+
+	if built {
+		return nil
+	}
+
+	// updates wait for the scan, so none are lost between it and the index
+	// being marked as built
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if index.built {
+		return nil
+	}
+
+	parents, err := scan()
+	if err != nil {
+		return err
+	}
+
+	for child, parent := range parents {
+		index.add(parent, child)
+	}
+
+	index.built = true
+
+	return nil
+}
+
+// Add records the volume as a copy-on-write child of the parent.
+func (index *childIndex) Add(parent string, child string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	// the first scan will pick it up
+	if !index.built {
+		return
+	}
+
+	index.add(parent, child)
+}
+
+// RemoveParent forgets that the volume is a child of its parent, keeping
+// the children it has of its own.
+func (index *childIndex) RemoveParent(child string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.removeParent(child)
+}
+
+// Remove forgets the volume, along with its tie to its parent.
+func (index *childIndex) Remove(handle string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.removeParent(handle)
+
+	for child := range index.children[handle] {
+		delete(index.parents, child)
+	}
+
+	delete(index.children, handle)
+}
+
+// Rename records the volume, and its ties to its parent and children, under
+// the new handle.
+func (index *childIndex) Rename(handle string, newHandle string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	parent, hasParent := index.parents[handle]
+	if hasParent {
+		index.removeParent(handle)
+		index.add(parent, newHandle)
+	}
+
+	children, hasChildren := index.children[handle]
+	if hasChildren {
+		delete(index.children, handle)
+		index.children[newHandle] = children
+
+		for child := range children {
+			index.parents[child] = newHandle
+		}
+	}
+}
+
+func (index *childIndex) add(parent string, child string) {
+	if index.children[parent] == nil {
+		index.children[parent] = map[string]bool{}
+	}
+
+	index.children[parent][child] = true
+	index.parents[child] = parent
+}
+
+func (index *childIndex) removeParent(child string) {
+	parent, found := index.parents[child]
+	if !found {
+		return
+	}
+
+	delete(index.children[parent], child)
+
+	if len(index.children[parent]) == 0 {
+		delete(index.children, parent)
+	}
+
+	delete(index.parents, child)
+}
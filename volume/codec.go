@@ -0,0 +1,194 @@
+package volume
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"sync"
+)
+
+// Codec is a content encoding that streams can be sent and received with.
+type Codec interface {
+	// Name is the coding's name as used in Accept-Encoding and
+	// Content-Encoding headers.
+	Name() string
+
+	// NewReader decodes the stream. Closing the reader reads whatever is
+	// left of the stream and reports whether it was encoded correctly.
+	NewReader(io.Reader) (io.ReadCloser, error)
+
+	// NewWriter encodes what is written to it into the destination, which
+	// is complete once the writer has been closed.
+	NewWriter(io.Writer) (io.WriteCloser, error)
+}
+
+// Aborter is implemented by the writers of codecs that write to the
+// destination on their own, e.g. from a process encoding what it is given.
+// Abort gives up on the stream rather than finish it, returning once nothing
+// more will be written to the destination. Writers without it are simply
+// left unclosed.
+type Aborter interface {
+	Abort() error
+}
+
+var (
+	codecsL sync.RWMutex
+	codecs  []Codec
+)
+
+func init() {
+	if _, err := exec.LookPath("zstd"); err == nil {
+		RegisterCodec(ZstdCodec{})
+	}
+
+	RegisterCodec(GzipCodec{})
+}
+
+// RegisterCodec makes the codec available to stream-in and stream-out.
+// Codecs registered first are preferred when a client accepts several.
+// Registering a codec under a name that is taken replaces the earlier one.
+func RegisterCodec(codec Codec) {
+	codecsL.Lock()
+	defer codecsL.Unlock()
+
+	for i, registered := range codecs {
+		if registered.Name() == codec.Name() {
+			codecs[i] = codec
+			return
+		}
+	}
+
+	codecs = append(codecs, codec)
+}
+
+// LookupCodec returns the codec registered under the name, if any.
+func LookupCodec(name string) (Codec, bool) {
+	codecsL.RLock()
+	defer codecsL.RUnlock()
+
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, true
+		}
+	}
+
+	return nil, false
+}
+
+// Codecs returns the registered codecs, most preferred first.
+func Codecs() []Codec {
+	codecsL.RLock()
+	defer codecsL.RUnlock()
+
+	return append([]Codec(nil), codecs...)
+}
+
+type GzipCodec struct{}
+
+func (GzipCodec) Name() string { return "gzip" }
+
+func (GzipCodec) NewReader(stream io.Reader) (io.ReadCloser, error) {
+	gzipReader, err := gzip.NewReader(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drainingReader{Reader: gzipReader, close: gzipReader.Close}, nil
+}
+
+func (GzipCodec) NewWriter(dest io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(dest), nil
+}
+
+// ZstdCodec encodes and decodes through the zstd binary, as xz payloads
+// are decompressed through xz.
+type ZstdCodec struct{}
+
+func (ZstdCodec) Name() string { return "zstd" }
+
+func (ZstdCodec) NewReader(stream io.Reader) (io.ReadCloser, error) {
+	zstdCommand := exec.Command("zstd", "--decompress", "--stdout", "--quiet")
+	zstdCommand.Stdin = stream
+
+	stdout, err := zstdCommand.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = zstdCommand.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return &drainingReader{Reader: stdout, close: zstdCommand.Wait}, nil
+}
+
+func (ZstdCodec) NewWriter(dest io.Writer) (io.WriteCloser, error) {
+	zstdCommand := exec.Command("zstd", "--stdout", "--quiet")
+	zstdCommand.Stdout = dest
+
+	stdin, err := zstdCommand.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = zstdCommand.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return &commandWriter{WriteCloser: stdin, command: zstdCommand}, nil
+}
+
+// drainingReader reads what is left of the stream when closed, so that a
+// decoder gets to verify its checksum even though tar stops reading at the
+// end of the archive.
+type drainingReader struct {
+	io.Reader
+
+	close func() error
+}
+
+func (reader *drainingReader) Close() error {
+	_, drainErr := io.Copy(ioutil.Discard, reader.Reader)
+
+	err := reader.close()
+	if err != nil {
+		return err
+	}
+
+	return drainErr
+}
+
+// commandWriter writes to the command's stdin, which is closed along with
+// it. The command writes to the destination until it exits.
+type commandWriter struct {
+	io.WriteCloser
+
+	command *exec.Cmd
+}
+
+func (writer *commandWriter) Close() error {
+	closeErr := writer.WriteCloser.Close()
+
+	err := writer.command.Wait()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// Abort kills the command and waits for it, and for what it had written to
+// be copied to the destination.
+func (writer *commandWriter) Abort() error {
+	killErr := writer.command.Process.Kill()
+
+	writer.WriteCloser.Close()
+
+	// the command was killed, so it failing is what is expected
+	writer.command.Wait()
+
+	return killErr
+}
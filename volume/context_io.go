@@ -0,0 +1,40 @@
+package volume
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader stops reading once the context is done, so that whatever is
+// consuming the stream gives up on it rather than wait for more.
+type contextReader struct {
+	io.Reader
+
+	ctx context.Context
+}
+
+func (reader *contextReader) Read(p []byte) (int, error) {
+	err := reader.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	return reader.Reader.Read(p)
+}
+
+// contextWriter stops writing once the context is done, so that nothing is
+// spent archiving what nobody is left to receive.
+type contextWriter struct {
+	io.Writer
+
+	ctx context.Context
+}
+
+func (writer *contextWriter) Write(p []byte) (int, error) {
+	err := writer.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	return writer.Writer.Write(p)
+}
@@ -0,0 +1,79 @@
+package volume
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// CopyStrategy creates a volume from a parent like COWStrategy, but copies
+// the parent's data into a fresh volume instead of layering on top of it.
+// This avoids the depth limits and copy-up cost of COW for volumes that are
+// large and heavily mutated.
+type CopyStrategy struct {
+	ParentHandle string
+}
+
+func (strategy CopyStrategy) Materialize(logger lager.Logger, handle string, fs Filesystem) (FilesystemInitVolume, error) {
+	if strategy.ParentHandle == "" {
+		logger.Info("parent-not-specified")
+		return nil, ErrNoParentVolumeProvided
+	}
+
+	parentVolume, found, err := fs.LookupVolume(strategy.ParentHandle)
+	if err != nil {
+		logger.Error("failed-to-lookup-parent", err)
+		return nil, err
+	}
+
+	if !found {
+		logger.Info("parent-not-found")
+		return nil, ErrParentVolumeNotFound
+	}
+
+	encrypted, err := parentVolume.LoadEncrypted()
+	if err != nil {
+		logger.Error("failed-to-load-parent-encrypted", err)
+		return nil, err
+	}
+
+	// a copy of encrypted data is kept encrypted too
+	newVolume := fs.NewVolume
+	if encrypted {
+		newVolume = fs.NewEncryptedVolume
+	}
+
+	initVolume, err := newVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	// copied as the parent's driver copies out of it, which for the naive
+	// driver is in Go rather than with cp
+	err = parentVolume.CopyData(parentVolume.DataPath(), initVolume.DataPath())
+	if err != nil {
+		logger.Error("failed-to-copy-parent", err)
+		initVolume.Destroy()
+		return nil, err
+	}
+
+	return initVolume, nil
+}
+
+func (CopyStrategy) Type() string {
+	return StrategyCopy
+}
+
+// copyData copies the contents of src into dest, keeping owners, modes,
+// times, and links. Anything but a directory is copied into dest by its name.
+// It shells out to cp, so it is only for drivers that don't do the copying
+// themselves, none of which are on Windows.
+func copyData(src string, dest string) error {
+	if info, err := os.Lstat(src); err == nil && info.IsDir() {
+		src = filepath.Clean(src) + "/."
+	}
+
+	return exec.Command("cp", "-a", src, dest).Run()
+}
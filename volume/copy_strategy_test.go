@@ -0,0 +1,163 @@
+package volume_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CopyStrategy", func() {
+	var (
+		strategy Strategy
+	)
+
+	BeforeEach(func() {
+		strategy = CopyStrategy{"parent-volume"}
+	})
+
+	Describe("Materialize", func() {
+		var (
+			fakeFilesystem *volumefakes.FakeFilesystem
+
+			materializedVolume FilesystemInitVolume
+			materializeErr     error
+		)
+
+		BeforeEach(func() {
+			fakeFilesystem = new(volumefakes.FakeFilesystem)
+		})
+
+		JustBeforeEach(func() {
+			materializedVolume, materializeErr = strategy.Materialize(
+				lagertest.NewTestLogger("test"),
+				"some-volume",
+				fakeFilesystem,
+			)
+		})
+
+		Context("when the parent volume can be found", func() {
+			var (
+				parentVolume *volumefakes.FakeFilesystemLiveVolume
+				parentDir    string
+			)
+
+			BeforeEach(func() {
+				var err error
+				parentDir, err = ioutil.TempDir("", "copy-strategy-parent")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(parentDir, "some-file"), []byte("some-content"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				parentVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				parentVolume.DataPathReturns(parentDir)
+				fakeFilesystem.LookupVolumeReturns(parentVolume, true, nil)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(parentDir)).To(Succeed())
+			})
+
+			Context("when creating the new volume succeeds", func() {
+				var (
+					fakeVolume *volumefakes.FakeFilesystemInitVolume
+					volumeDir  string
+				)
+
+				BeforeEach(func() {
+					var err error
+					volumeDir, err = ioutil.TempDir("", "copy-strategy-volume")
+					Expect(err).NotTo(HaveOccurred())
+
+					fakeVolume = new(volumefakes.FakeFilesystemInitVolume)
+					fakeVolume.DataPathReturns(volumeDir)
+					fakeFilesystem.NewVolumeReturns(fakeVolume, nil)
+				})
+
+				AfterEach(func() {
+					Expect(os.RemoveAll(volumeDir)).To(Succeed())
+				})
+
+				It("returns it", func() {
+					Expect(materializeErr).ToNot(HaveOccurred())
+					Expect(materializedVolume).To(Equal(fakeVolume))
+				})
+
+				It("created it with the correct handle", func() {
+					handle := fakeFilesystem.NewVolumeArgsForCall(0)
+					Expect(handle).To(Equal("some-volume"))
+				})
+
+				It("looked up the parent with the correct handle", func() {
+					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
+					Expect(handle).To(Equal("parent-volume"))
+				})
+
+				It("copies the parent's data into it as the parent's driver does", func() {
+					Expect(parentVolume.CopyDataCallCount()).To(Equal(1))
+					src, dest := parentVolume.CopyDataArgsForCall(0)
+					Expect(src).To(Equal(parentDir))
+					Expect(dest).To(Equal(volumeDir))
+				})
+
+				Context("when copying fails", func() {
+					BeforeEach(func() {
+						parentVolume.CopyDataReturns(errors.New("nope"))
+					})
+
+					It("returns an error", func() {
+						Expect(materializeErr).To(HaveOccurred())
+					})
+
+					It("destroys the new volume", func() {
+						Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+					})
+				})
+			})
+
+			Context("when creating the new volume fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeFilesystem.NewVolumeReturns(nil, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(materializeErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when no parent volume is given", func() {
+			BeforeEach(func() {
+				strategy = CopyStrategy{""}
+			})
+
+			It("returns ErrNoParentVolumeProvided", func() {
+				Expect(materializeErr).To(Equal(ErrNoParentVolumeProvided))
+			})
+
+			It("does not look it up", func() {
+				Expect(fakeFilesystem.LookupVolumeCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the parent handle does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrParentVolumeNotFound", func() {
+				Expect(materializeErr).To(Equal(ErrParentVolumeNotFound))
+			})
+		})
+	})
+})
@@ -32,3 +32,7 @@ func (strategy COWStrategy) Materialize(logger lager.Logger, handle string, fs F
 
 	return parentVolume.NewSubvolume(handle)
 }
+
+func (COWStrategy) Type() string {
+	return StrategyCopyOnWrite
+}
@@ -0,0 +1,123 @@
+package volume
+
+import "sync"
+
+// createKeyIndex maps the idempotency keys volumes were created with to their
+// handles, so that a retried create doesn't have to look at every volume to
+// find the one it already made. The keys themselves are kept with the
+// volumes, so they go along with them.
+//
+// Like the child index, it is built from the first scan of the volumes, and
+// kept up to date by the repository from then on.
+type createKeyIndex struct {
+	lock  sync.RWMutex
+	built bool
+
+	// key -> handle
+	handles map[string]string
+
+	// handle -> key
+	keys map[string]string
+}
+
+func newCreateKeyIndex() *createKeyIndex {
+	return &createKeyIndex{
+		handles: map[string]string{},
+		keys:    map[string]string{},
+	}
+}
+
+// Lookup returns the handle of the volume created with the key, if any. If
+// the index has not been built yet, it is built from scan first, which
+// returns the handle of each volume created with a key by its key.
+func (index *createKeyIndex) Lookup(key string, scan func() (map[string]string, error)) (string, bool, error) {
+	err := index.build(scan)
+	if err != nil {
+		return "", false, err
+	}
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+
+	handle, found := index.handles[key]
+
+	return handle, found, nil
+}
+
+func (index *createKeyIndex) build(scan func() (map[string]string, error)) error {
+	index.lock.RLock()
+	built := index.built
+	index.lock.RUnlock()
+
+	if built {
+		return nil
+	}
+
+	// updates wait for the scan, so none are lost between it and the index
+	// being marked as built
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if index.built {
+		return nil
+	}
+
+	handles, err := scan()
+	if err != nil {
+		return err
+	}
+
+	for key, handle := range handles {
+		index.add(key, handle)
+	}
+
+	index.built = true
+
+	return nil
+}
+
+// Add records the volume as created with the key.
+func (index *createKeyIndex) Add(key string, handle string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	// the first scan will pick it up
+	if !index.built {
+		return
+	}
+
+	index.add(key, handle)
+}
+
+// Remove forgets the volume's key.
+func (index *createKeyIndex) Remove(handle string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	key, found := index.keys[handle]
+	if !found {
+		return
+	}
+
+	delete(index.keys, handle)
+	delete(index.handles, key)
+}
+
+// Rename records the volume's key under the new handle.
+func (index *createKeyIndex) Rename(handle string, newHandle string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	key, found := index.keys[handle]
+	if !found {
+		return
+	}
+
+	delete(index.keys, handle)
+	index.add(key, newHandle)
+}
+
+func (index *createKeyIndex) add(key string, handle string) {
+	index.handles[key] = handle
+	index.keys[handle] = key
+}
@@ -0,0 +1,97 @@
+package volume
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+type DestroyReason string
+
+const (
+	DestroyReasonManual       DestroyReason = "manual"
+	DestroyReasonTTLExpiry    DestroyReason = "ttl-expiry"
+	DestroyReasonDiskPressure DestroyReason = "disk-pressure"
+	DestroyReasonForceCascade DestroyReason = "force-cascade"
+	DestroyReasonCorrupted    DestroyReason = "corrupted"
+)
+
+type DestroyOptions struct {
+	Reason DestroyReason
+
+	// Annotation is free-form text supplied by whoever asked for the
+	// destroy, recorded alongside the reason.
+	Annotation string
+
+	// SpareStreamed leaves a volume that is being streamed into or out of
+	// alone, failing with ErrVolumeIsStreaming. It is not carried over to
+	// destroys deferred until a volume's views are gone.
+	SpareStreamed bool
+
+	// ExpiredAt leaves a volume alone unless it still expires then, failing
+	// with ErrVolumeWasRenewed once its TTL has been renewed or set since.
+	// It is not carried over to destroys deferred until a volume's views are
+	// gone.
+	ExpiredAt time.Time
+
+	// LeaseToken is the token of the lease held on the volume, if any; a
+	// leased volume is otherwise left alone, failing with ErrVolumeIsLeased.
+	// It is not carried over to destroys deferred until a volume's views are
+	// gone, nor to the descendants of a volume destroyed along with it.
+	LeaseToken string
+}
+
+type DestroyAuditEntry struct {
+	Handle      string        `json:"handle"`
+	Reason      DestroyReason `json:"reason"`
+	Annotation  string        `json:"annotation,omitempty"`
+	Properties  Properties    `json:"properties,omitempty"`
+	DestroyedAt time.Time     `json:"destroyed_at"`
+}
+
+//go:generate counterfeiter . DestroyAuditLog
+
+type DestroyAuditLog interface {
+	Record(DestroyAuditEntry) error
+}
+
+// NoopDestroyAuditLog discards every entry; destroys are still logged.
+type NoopDestroyAuditLog struct{}
+
+func (NoopDestroyAuditLog) Record(DestroyAuditEntry) error {
+	return nil
+}
+
+type fileDestroyAuditLog struct {
+	fileL sync.Mutex
+	file  *os.File
+}
+
+// NewFileDestroyAuditLog appends one JSON object per line to the file at
+// path, creating it if needed. Existing entries are never rewritten.
+func NewFileDestroyAuditLog(path string) (DestroyAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileDestroyAuditLog{file: file}, nil
+}
+
+func (log *fileDestroyAuditLog) Record(entry DestroyAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	log.fileL.Lock()
+	defer log.fileL.Unlock()
+
+	_, err = log.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+
+	return log.file.Sync()
+}
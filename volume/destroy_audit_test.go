@@ -0,0 +1,65 @@
+package volume_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/concourse/baggageclaim/volume"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileDestroyAuditLog", func() {
+	var (
+		tmpdir  string
+		logPath string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "destroy-audit-log")
+		Expect(err).NotTo(HaveOccurred())
+
+		logPath = filepath.Join(tmpdir, "audit.log")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpdir)).To(Succeed())
+	})
+
+	It("appends one JSON line per entry, keeping existing entries", func() {
+		err := ioutil.WriteFile(logPath, []byte("{\"handle\":\"earlier\"}\n"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		auditLog, err := volume.NewFileDestroyAuditLog(logPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = auditLog.Record(volume.DestroyAuditEntry{
+			Handle:      "some-handle",
+			Reason:      volume.DestroyReasonManual,
+			Annotation:  "some-annotation",
+			DestroyedAt: time.Unix(100, 0).UTC(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = auditLog.Record(volume.DestroyAuditEntry{
+			Handle:      "another-handle",
+			Reason:      volume.DestroyReasonTTLExpiry,
+			DestroyedAt: time.Unix(200, 0).UTC(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(logPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchJSON(`{"handle":"earlier"}`))
+		Expect(lines[1]).To(MatchJSON(`{"handle":"some-handle","reason":"manual","annotation":"some-annotation","destroyed_at":"1970-01-01T00:01:40Z"}`))
+		Expect(lines[2]).To(MatchJSON(`{"handle":"another-handle","reason":"ttl-expiry","destroyed_at":"1970-01-01T00:03:20Z"}`))
+	})
+})
@@ -0,0 +1,189 @@
+package volume
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+type DiffChange string
+
+const (
+	DiffAdded    DiffChange = "added"
+	DiffRemoved  DiffChange = "removed"
+	DiffModified DiffChange = "modified"
+)
+
+type DiffEntry struct {
+	Path   string     `json:"path"`
+	Change DiffChange `json:"change"`
+}
+
+// diffTrees walks root and baseRoot side by side in lexical order, calling
+// emit for each path that was added, removed or modified in root relative to
+// baseRoot. Paths are slash-separated and relative to the roots.
+func diffTrees(root string, baseRoot string, emit func(DiffEntry) error) error {
+	return diffDirs(root, baseRoot, "", emit)
+}
+
+func diffDirs(root string, baseRoot string, rel string, emit func(DiffEntry) error) error {
+	infos, err := ioutil.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+
+	baseInfos, err := ioutil.ReadDir(filepath.Join(baseRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+
+	i, j := 0, 0
+	for i < len(infos) || j < len(baseInfos) {
+		switch {
+		case j >= len(baseInfos) || (i < len(infos) && infos[i].Name() < baseInfos[j].Name()):
+			err = emitTree(root, path.Join(rel, infos[i].Name()), infos[i], DiffAdded, emit)
+			i++
+		case i >= len(infos) || baseInfos[j].Name() < infos[i].Name():
+			err = emitTree(baseRoot, path.Join(rel, baseInfos[j].Name()), baseInfos[j], DiffRemoved, emit)
+			j++
+		default:
+			err = diffPaths(root, baseRoot, path.Join(rel, infos[i].Name()), infos[i], baseInfos[j], emit)
+			i++
+			j++
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func diffPaths(root string, baseRoot string, rel string, info os.FileInfo, baseInfo os.FileInfo, emit func(DiffEntry) error) error {
+	if info.IsDir() != baseInfo.IsDir() {
+		// a directory replaced by a file or vice versa; report the whole
+		// subtree on both sides
+		err := emitTree(baseRoot, rel, baseInfo, DiffRemoved, emit)
+		if err != nil {
+			return err
+		}
+
+		return emitTree(root, rel, info, DiffAdded, emit)
+	}
+
+	same, err := sameContents(filepath.Join(root, filepath.FromSlash(rel)), filepath.Join(baseRoot, filepath.FromSlash(rel)), info, baseInfo)
+	if err != nil {
+		return err
+	}
+
+	if !same {
+		err := emit(DiffEntry{Path: rel, Change: DiffModified})
+		if err != nil {
+			return err
+		}
+	}
+
+	if info.IsDir() {
+		return diffDirs(root, baseRoot, rel, emit)
+	}
+
+	return nil
+}
+
+func emitTree(root string, rel string, info os.FileInfo, change DiffChange, emit func(DiffEntry) error) error {
+	err := emit(DiffEntry{Path: rel, Change: change})
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	infos, err := ioutil.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+
+	for _, child := range infos {
+		err := emitTree(root, path.Join(rel, child.Name()), child, change, emit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameContents(path string, basePath string, info os.FileInfo, baseInfo os.FileInfo) (bool, error) {
+	if info.Mode() != baseInfo.Mode() {
+		return false, nil
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false, err
+		}
+
+		baseTarget, err := os.Readlink(basePath)
+		if err != nil {
+			return false, err
+		}
+
+		return target == baseTarget, nil
+
+	case info.Mode().IsRegular():
+		if info.Size() != baseInfo.Size() {
+			return false, nil
+		}
+
+		return sameFileContents(path, basePath)
+	}
+
+	return true, nil
+}
+
+func sameFileContents(path string, basePath string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+
+	defer file.Close()
+
+	baseFile, err := os.Open(basePath)
+	if err != nil {
+		return false, err
+	}
+
+	defer baseFile.Close()
+
+	buf := make([]byte, 32*1024)
+	baseBuf := make([]byte, 32*1024)
+
+	for {
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return false, err
+		}
+
+		baseN, baseErr := io.ReadFull(baseFile, baseBuf)
+		if baseErr != nil && baseErr != io.EOF && baseErr != io.ErrUnexpectedEOF {
+			return false, baseErr
+		}
+
+		if !bytes.Equal(buf[:n], baseBuf[:baseN]) {
+			return false, nil
+		}
+
+		if err != nil || baseErr != nil {
+			return err != nil && baseErr != nil, nil
+		}
+	}
+}
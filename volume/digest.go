@@ -0,0 +1,62 @@
+package volume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+const digestPrefix = "sha256:"
+
+// digestKinds are how each type of entry is written into a digest.
+var digestKinds = map[ManifestType]string{
+	ManifestDirectory: "d",
+	ManifestFile:      "f",
+	ManifestSymlink:   "l",
+	ManifestOther:     "o",
+}
+
+// treeDigest hashes the tree under root: the path, type, and permission bits
+// of everything in it, along with the contents of files and the targets of
+// symlinks. Owners and times are left out, and the tree is walked in lexical
+// order, so equivalent trees have the same digest however they were written.
+func treeDigest(root string) (string, error) {
+	digest := sha256.New()
+
+	err := walkManifest(root, func(entry ManifestEntry) error {
+		contents := entry.SHA256
+		if entry.Type == ManifestSymlink {
+			contents = entry.Target
+		}
+
+		// fields are separated by NULs, which cannot appear in paths
+		fmt.Fprintf(digest, "%s\x00%o\x00%s\x00%s\x00", digestKinds[entry.Type], entry.Mode, entry.Path, contents)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return digestPrefix + hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	digest := sha256.New()
+
+	_, err = io.Copy(digest, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
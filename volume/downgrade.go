@@ -0,0 +1,123 @@
+package volume
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	modeSetuid = 04000
+	modeSetgid = 02000
+)
+
+// DowngradeReport counts what a downgraded stream-out changed.
+type DowngradeReport struct {
+	SetuidCleared  int
+	SetgidCleared  int
+	DevicesSkipped int
+}
+
+func (report DowngradeReport) String() string {
+	return fmt.Sprintf("setuid=%d, setgid=%d, devices=%d", report.SetuidCleared, report.SetgidCleared, report.DevicesSkipped)
+}
+
+// downgradingWriter rewrites the tar stream written to it on its way to
+// dest, dropping what an unprivileged volume can't extract.
+type downgradingWriter struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+func newDowngradingWriter(dest io.Writer, report *DowngradeReport) *downgradingWriter {
+	pipeReader, pipeWriter := io.Pipe()
+
+	writer := &downgradingWriter{
+		pipe: pipeWriter,
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		err := downgradeTar(pipeReader, dest, report)
+		if err == nil {
+			// tar pads the stream past the end of the archive
+			_, err = io.Copy(ioutil.Discard, pipeReader)
+		}
+
+		// stop the source as well if dest went away
+		pipeReader.CloseWithError(err)
+
+		writer.done <- err
+	}()
+
+	return writer
+}
+
+func (writer *downgradingWriter) Write(p []byte) (int, error) {
+	return writer.pipe.Write(p)
+}
+
+// Finish ends the stream, failing it with streamErr if given, and waits for
+// the rest of it to be written.
+func (writer *downgradingWriter) Finish(streamErr error) error {
+	writer.pipe.CloseWithError(streamErr)
+
+	err := <-writer.done
+	if streamErr != nil {
+		return streamErr
+	}
+
+	return err
+}
+
+func downgradeTar(src io.Reader, dest io.Writer, report *DowngradeReport) error {
+	tarReader := tar.NewReader(src)
+	tarWriter := tar.NewWriter(dest)
+
+	// hard links to skipped devices have nothing to link to
+	skipped := map[string]bool{}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return tarWriter.Close()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case header.Typeflag == tar.TypeChar || header.Typeflag == tar.TypeBlock:
+			skipped[header.Name] = true
+			report.DevicesSkipped++
+			continue
+
+		case header.Typeflag == tar.TypeLink && skipped[header.Linkname]:
+			skipped[header.Name] = true
+			report.DevicesSkipped++
+			continue
+		}
+
+		if header.Mode&modeSetuid != 0 {
+			header.Mode &^= modeSetuid
+			report.SetuidCleared++
+		}
+
+		if header.Mode&modeSetgid != 0 {
+			header.Mode &^= modeSetgid
+			report.SetgidCleared++
+		}
+
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(tarWriter, tarReader)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -3,9 +3,148 @@ package volume
 //go:generate counterfeiter . Driver
 
 type Driver interface {
+	// Name is the name the driver is selected by, e.g. "btrfs".
+	Name() string
+
 	CreateVolume(path string) error
 	DestroyVolume(path string) error
-	GetVolumeSizeInBytes(path string) (int64, error)
+	GetVolumeStats(path string) (DriverStats, error)
 
 	CreateCopyOnWriteLayer(path string, parent string) error
 }
+
+// DriverStats is what a volume uses and what is left for it, as its driver
+// finds them.
+type DriverStats struct {
+	// UsedBytes is what the volume's own data takes up, not counting what it
+	// shares with a parent.
+	UsedBytes int64
+
+	// FileCount is the number of files and directories in the volume.
+	FileCount int64
+
+	// FreeBytes is what is free on the volume's backing store: the
+	// filesystem it is kept on, or its own tmpfs. It is 0 where it can't be
+	// found, e.g. on Windows.
+	FreeBytes uint64
+
+	// Exact is whether UsedBytes comes from the backing store's own
+	// accounting, e.g. a btrfs qgroup. Otherwise it is an estimate from
+	// walking the volume's files, which may be out of date.
+	Exact bool
+}
+
+// SnapshottingDriver is implemented by drivers that can cheaply take a
+// read-only, point-in-time copy of a volume. COW layers are not enough, as
+// with overlay changes to the parent show through.
+type SnapshottingDriver interface {
+	CreateSnapshot(path string, parent string) error
+}
+
+// CloningDriver is implemented by drivers that can make a writable copy of a
+// volume that is independent of it from then on, cheaply or, as with the
+// naive driver, in a way of their own. Other drivers have clones copied file
+// by file.
+type CloningDriver interface {
+	CreateClone(path string, source string) error
+}
+
+// CopyingDriver is implemented by drivers that can copy data from one volume
+// into another more cheaply than file by file, e.g. by sharing extents. As
+// with cp -a, a directory's contents are copied into dest, and anything else
+// is copied into it by its name. Other drivers have it copied file by file.
+type CopyingDriver interface {
+	CopyData(src string, dest string) error
+}
+
+// QuotaDriver is implemented by drivers that can limit how much a volume
+// may hold, failing writes past it with ENOSPC.
+type QuotaDriver interface {
+	SetVolumeQuota(path string, sizeInBytes int64) error
+
+	// GetVolumeQuota returns the volume's limit, or 0 if it has none.
+	GetVolumeQuota(path string) (int64, error)
+}
+
+// SizingDriver is implemented by drivers that can tell how much a volume uses
+// without walking its files. Other drivers have the volume walked, as for
+// its stats.
+type SizingDriver interface {
+	GetVolumeSize(path string) (int64, error)
+}
+
+// StatsCachingDriver is implemented by drivers that keep what walking a
+// volume found for its stats, as the walk is slow for large volumes.
+// ForgetVolumeStats is called once the volume's data has been changed through
+// the API, for its next stats to walk it again.
+type StatsCachingDriver interface {
+	ForgetVolumeStats(path string)
+}
+
+// ReadOnlyDriver is implemented by drivers that can keep anything from
+// writing to a volume's data, e.g. a container it is mounted into. COW
+// layers and clones of it are writable.
+type ReadOnlyDriver interface {
+	MakeReadOnly(path string) error
+}
+
+// MountOptionsDriver is implemented by drivers that can give each volume
+// mount options of its own, e.g. noatime. Its copy-on-write layers and clones
+// only have the options they are given themselves.
+type MountOptionsDriver interface {
+	// MountOptions returns the options that volumes may be given.
+	MountOptions() []string
+
+	// SetMountOptions applies the options to the volume, keeping it
+	// read-only if it is.
+	SetMountOptions(path string, options []string) error
+}
+
+// EncryptingDriver is implemented by drivers that can keep a volume's data
+// encrypted where it is stored, while whatever has its path reads and writes
+// it as it is, as long as its key is unlocked.
+type EncryptingDriver interface {
+	// CreateEncryptedVolume creates the volume as CreateVolume does, with
+	// everything written to it encrypted with the key. It is left unlocked.
+	CreateEncryptedVolume(path string, key []byte) error
+
+	// UnlockVolume makes the volume's data readable with its key again if it
+	// is not, e.g. once the host restarts, returning whether it had to.
+	UnlockVolume(path string, key []byte) (bool, error)
+
+	// LockVolume forgets the volume's key, so that its data can't be read
+	// until it is unlocked again. A volume that is already locked is left
+	// alone.
+	LockVolume(path string) error
+}
+
+// RenamingDriver is implemented by drivers that keep something of their own
+// for a volume going by its path, which has to be moved along with the volume
+// when it is renamed. It is moved before the volume is.
+type RenamingDriver interface {
+	RenameVolume(path string, newPath string) error
+}
+
+// PromotingDriver is implemented by drivers whose copy-on-write layers only
+// hold what was written to them, and need their parent's data to be there.
+// PromoteVolume gives the layer a full copy of its data of its own, so that
+// it no longer does. A crash part way through leaves the layer as it was or
+// promoted, and a layer that is not a copy-on-write one is left alone. It is
+// not called for a volume with copy-on-write layers of its own.
+type PromotingDriver interface {
+	PromoteVolume(path string) error
+}
+
+// MountChecker is implemented by drivers that need the volumes directory, or
+// a directory of their own, to be mounted a certain way.
+type MountChecker interface {
+	CheckMount(path string) error
+}
+
+// MountingDriver is implemented by drivers whose volumes are mounts, which
+// are gone once the host restarts.
+type MountingDriver interface {
+	// EnsureMounted mounts the volume at path again if it is not mounted,
+	// returning whether it had to.
+	EnsureMounted(path string) (bool, error)
+}
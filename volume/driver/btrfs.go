@@ -8,25 +8,42 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim/volume"
 )
 
+var ErrNotOnBtrfs = errors.New("volumes directory is not on a btrfs filesystem")
+
 type BtrFSDriver struct {
-	logger   lager.Logger
-	btrfsBin string
+	logger        lager.Logger
+	btrfsBin      string
+	statsCacheTTL time.Duration
+
+	usage usageCache
 }
 
+// NewBtrFSDriver returns a driver running the btrfs binary. What walking a
+// volume found for its stats is used for statsCacheTTL, unless it is
+// forgotten first as the volume is written to; 0 walks it each time.
 func NewBtrFSDriver(
 	logger lager.Logger,
 	btrfsBin string,
+	statsCacheTTL time.Duration,
 ) *BtrFSDriver {
 	return &BtrFSDriver{
-		logger:   logger,
-		btrfsBin: btrfsBin,
+		logger:        logger,
+		btrfsBin:      btrfsBin,
+		statsCacheTTL: statsCacheTTL,
 	}
 }
 
+func (driver *BtrFSDriver) Name() string {
+	return "btrfs"
+}
+
 func (driver *BtrFSDriver) CreateVolume(path string) error {
 	_, _, err := driver.run(driver.btrfsBin, "subvolume", "create", path)
 	if err != nil {
@@ -76,6 +93,8 @@ func (driver *BtrFSDriver) DestroyVolume(path string) error {
 		}
 	}
 
+	driver.usage.Forget(path)
+
 	return nil
 }
 
@@ -84,7 +103,178 @@ func (driver *BtrFSDriver) CreateCopyOnWriteLayer(path string, parent string) er
 	return err
 }
 
-func (driver *BtrFSDriver) GetVolumeSizeInBytes(path string) (int64, error) {
+func (driver *BtrFSDriver) CreateSnapshot(path string, parent string) error {
+	_, _, err := driver.run(driver.btrfsBin, "subvolume", "snapshot", "-r", parent, path)
+	return err
+}
+
+// CreateClone takes a writable snapshot, which shares extents with the
+// source but is otherwise as independent of it as any other subvolume.
+func (driver *BtrFSDriver) CreateClone(path string, source string) error {
+	_, _, err := driver.run(driver.btrfsBin, "subvolume", "snapshot", source, path)
+	return err
+}
+
+// CopyData copies with reflinks, which share extents with the source until
+// either is written to. Files that can't be reflinked, e.g. from a tmpfs
+// volume, are copied in full.
+func (driver *BtrFSDriver) CopyData(src string, dest string) error {
+	if info, err := os.Lstat(src); err == nil && info.IsDir() {
+		src = filepath.Clean(src) + "/."
+	}
+
+	_, _, err := driver.run("cp", "-a", "--reflink=auto", src, dest)
+	return err
+}
+
+// MakeReadOnly sets the subvolume's ro property. Snapshots taken of it
+// without -r are writable.
+func (driver *BtrFSDriver) MakeReadOnly(path string) error {
+	_, _, err := driver.run(driver.btrfsBin, "property", "set", "-ts", path, "ro", "true")
+	return err
+}
+
+// btrfsCompressOption is the prefix of the mount options that set how a
+// volume's data is compressed.
+const btrfsCompressOption = "compress="
+
+// MountOptions are how the volume's data is compressed, as subvolumes share
+// the mount of the volumes directory and cannot be given options of their
+// own otherwise.
+func (driver *BtrFSDriver) MountOptions() []string {
+	return []string{
+		btrfsCompressOption + "lzo",
+		btrfsCompressOption + "no",
+		btrfsCompressOption + "zlib",
+		btrfsCompressOption + "zstd",
+	}
+}
+
+// SetMountOptions sets the subvolume's compression property, which applies to
+// what is written to it from then on. Snapshots taken of it keep it.
+func (driver *BtrFSDriver) SetMountOptions(path string, options []string) error {
+	for _, option := range options {
+		if !strings.HasPrefix(option, btrfsCompressOption) {
+			return fmt.Errorf("unknown mount option: %s", option)
+		}
+
+		_, _, err := driver.run(driver.btrfsBin, "property", "set", "-ts", path, "compression", strings.TrimPrefix(option, btrfsCompressOption))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckMount makes sure the volumes directory is still on btrfs, e.g. that the
+// loopback image it may have been given has not been unmounted from it.
+func (driver *BtrFSDriver) CheckMount(path string) error {
+	onBtrfs, err := isBtrfs(path)
+	if err != nil {
+		return err
+	}
+
+	if !onBtrfs {
+		return ErrNotOnBtrfs
+	}
+
+	return nil
+}
+
+// GetVolumeStats returns the exclusive size of the volume's subvolume from
+// its qgroup, which is exact. Without quotas enabled on the filesystem it is
+// estimated from walking the volume, which is needed for its file count
+// anyway, or from its last walk if that is within the stats cache TTL.
+func (driver *BtrFSDriver) GetVolumeStats(path string) (volume.DriverStats, error) {
+	walkedSize, fileCount, err := driver.usage.Walk(path, driver.statsCacheTTL)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	free, err := freeBytes(path)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	stats := volume.DriverStats{
+		FileCount: fileCount,
+		FreeBytes: free,
+	}
+
+	size, err := driver.exclusiveSize(path)
+	if err != nil {
+		driver.logger.Info("walking-volume-without-qgroup", lager.Data{"path": path, "error": err.Error()})
+
+		stats.UsedBytes = walkedSize
+
+		return stats, nil
+	}
+
+	stats.UsedBytes = size
+	stats.Exact = true
+
+	return stats, nil
+}
+
+// ForgetVolumeStats drops what the volume's last walk found.
+func (driver *BtrFSDriver) ForgetVolumeStats(path string) {
+	driver.usage.Forget(path)
+}
+
+// GetVolumeSize returns the exclusive size of the volume's subvolume from its
+// qgroup, like its stats do. Without quotas enabled on the filesystem there
+// is no qgroup to read, so the volume is walked instead.
+func (driver *BtrFSDriver) GetVolumeSize(path string) (int64, error) {
+	size, err := driver.exclusiveSize(path)
+	if err == nil {
+		return size, nil
+	}
+
+	driver.logger.Info("walking-volume-without-qgroup", lager.Data{"path": path, "error": err.Error()})
+
+	size, _, err = walkUsage(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// SetVolumeQuota limits the exclusive size of the volume's subvolume, so
+// that data shared with a COW volume's parent doesn't count against it.
+func (driver *BtrFSDriver) SetVolumeQuota(path string, sizeInBytes int64) error {
+	_, _, err := driver.run(driver.btrfsBin, "qgroup", "limit", "-e", fmt.Sprintf("%d", sizeInBytes), path)
+	return err
+}
+
+func (driver *BtrFSDriver) GetVolumeQuota(path string) (int64, error) {
+	output, _, err := driver.run(driver.btrfsBin, "qgroup", "show", "-F", "-e", "--raw", path)
+	if err != nil {
+		return 0, err
+	}
+
+	qgroupsLines := strings.Split(strings.TrimSpace(output), "\n")
+	qgroupFields := strings.Fields(qgroupsLines[len(qgroupsLines)-1])
+
+	if len(qgroupFields) != 4 {
+		return 0, errors.New("unable-to-parse-btrfs-qgroup-show")
+	}
+
+	if qgroupFields[3] == "none" {
+		return 0, nil
+	}
+
+	var maxExclusive int64
+	_, err = fmt.Sscanf(qgroupFields[3], "%d", &maxExclusive)
+	if err != nil {
+		return 0, err
+	}
+
+	return maxExclusive, nil
+}
+
+func (driver *BtrFSDriver) exclusiveSize(path string) (int64, error) {
 	output, _, err := driver.run(driver.btrfsBin, "qgroup", "show", "-F", "--raw", path)
 	if err != nil {
 		return 0, err
@@ -47,7 +47,7 @@ var _ = Describe("BtrFS", func() {
 		err = filesystem.Create(100 * 1024 * 1024)
 		Expect(err).NotTo(HaveOccurred())
 
-		fsDriver = driver.NewBtrFSDriver(logger, "btrfs")
+		fsDriver = driver.NewBtrFSDriver(logger, "btrfs", 0)
 	})
 
 	AfterEach(func() {
@@ -120,6 +120,28 @@ var _ = Describe("BtrFS", func() {
 		})
 	})
 
+	Describe("CopyData", func() {
+		It("copies a directory's contents into the destination", func() {
+			sourcePath := filepath.Join(volumeDir, "source-volume")
+			Expect(fsDriver.CreateVolume(sourcePath)).To(Succeed())
+
+			destPath := filepath.Join(volumeDir, "dest-volume")
+			Expect(fsDriver.CreateVolume(destPath)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(sourcePath, "some-dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(sourcePath, "some-dir", "some-file"), []byte("some-contents"), 0644)).To(Succeed())
+
+			Expect(fsDriver.CopyData(sourcePath, destPath)).To(Succeed())
+			Expect(ioutil.ReadFile(filepath.Join(destPath, "some-dir", "some-file"))).To(Equal([]byte("some-contents")))
+
+			Expect(fsDriver.CopyData(filepath.Join(sourcePath, "some-dir", "some-file"), destPath)).To(Succeed())
+			Expect(ioutil.ReadFile(filepath.Join(destPath, "some-file"))).To(Equal([]byte("some-contents")))
+
+			Expect(fsDriver.DestroyVolume(destPath)).To(Succeed())
+			Expect(fsDriver.DestroyVolume(sourcePath)).To(Succeed())
+		})
+	})
+
 	Describe("GetVolumeSize", func() {
 		var parentVolumePath string
 		var childVolumePath string
@@ -150,8 +172,10 @@ var _ = Describe("BtrFS", func() {
 			err := fsDriver.CreateVolume(childVolumePath)
 			Expect(err).NotTo(HaveOccurred())
 
-			originalSize, err := fsDriver.GetVolumeSizeInBytes(childVolumePath)
+			originalStats, err := fsDriver.GetVolumeStats(childVolumePath)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(originalStats.Exact).To(BeTrue())
+			Expect(originalStats.FreeBytes).NotTo(BeZero())
 
 			size := 1024 * 1024 * 2
 			bs := make([]byte, size) // 2 MiB
@@ -164,11 +188,11 @@ var _ = Describe("BtrFS", func() {
 			timeout := 2 * time.Minute // btrfs periodic commit happens every 30 seconds
 			Eventually(func() int64 {
 				GinkgoRecover()
-				newSize, err := fsDriver.GetVolumeSizeInBytes(childVolumePath)
+				newStats, err := fsDriver.GetVolumeStats(childVolumePath)
 
 				Expect(err).NotTo(HaveOccurred())
-				return newSize
-			}, timeout, 1*time.Second).Should(Equal(int64(size) + originalSize))
+				return newStats.UsedBytes
+			}, timeout, 1*time.Second).Should(Equal(int64(size) + originalStats.UsedBytes))
 		})
 	})
 })
@@ -0,0 +1,14 @@
+package driver
+
+import "syscall"
+
+const btrfsSuperMagic = 0x9123683e
+
+func isBtrfs(p string) (bool, error) {
+	var fsStat syscall.Statfs_t
+	if err := syscall.Statfs(p, &fsStat); err != nil {
+		return false, err
+	}
+
+	return fsStat.Type == btrfsSuperMagic, nil
+}
@@ -0,0 +1,7 @@
+// +build !linux
+
+package driver
+
+func isBtrfs(p string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"syscall"
+)
+
+// mountOption is an option that a volume's mount may be given of its own, as
+// it applies to the mount rather than to what is mounted. statfs reports it
+// as statFlag.
+type mountOption struct {
+	flag     uintptr
+	statFlag int64
+}
+
+var mountOptions = map[string]mountOption{
+	"noatime":    {syscall.MS_NOATIME, 0x400},
+	"nodiratime": {syscall.MS_NODIRATIME, 0x800},
+	"relatime":   {syscall.MS_RELATIME, 0x1000},
+	"nodev":      {syscall.MS_NODEV, 0x4},
+	"noexec":     {syscall.MS_NOEXEC, 0x8},
+	"nosuid":     {syscall.MS_NOSUID, 0x2},
+}
+
+const statReadOnly = 0x1
+
+func mountOptionNames() []string {
+	names := []string{}
+	for name := range mountOptions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// remountWithOptions gives the mount at path the options and none of the
+// others, keeping it read-only if it is.
+func remountWithOptions(path string, options []string) error {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return err
+	}
+
+	flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND)
+	if int64(stat.Flags)&statReadOnly != 0 {
+		flags |= syscall.MS_RDONLY
+	}
+
+	for _, name := range options {
+		option, found := mountOptions[name]
+		if !found {
+			return fmt.Errorf("unknown mount option: %s", name)
+		}
+
+		flags |= option.flag
+	}
+
+	return syscall.Mount("", path, "", flags, "")
+}
+
+// remountReadOnly makes the mount at path read-only, keeping the options it
+// has.
+func remountReadOnly(path string) error {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return err
+	}
+
+	flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND | syscall.MS_RDONLY)
+	for _, option := range mountOptions {
+		if int64(stat.Flags)&option.statFlag != 0 {
+			flags |= option.flag
+		}
+	}
+
+	return syscall.Mount("", path, "", flags, "")
+}
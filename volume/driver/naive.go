@@ -2,14 +2,100 @@ package driver
 
 import (
 	"os"
+	"time"
+
+	"github.com/concourse/baggageclaim/volume"
 )
 
-type NaiveDriver struct{}
+// DefaultCopyBufferSize is the size of the buffer the naive driver copies
+// files through when it isn't given one.
+const DefaultCopyBufferSize = 128 * 1024
+
+// NaiveDriver keeps volumes as plain directories, copying the whole parent
+// for each copy-on-write layer and clone. How it copies can be tuned for the
+// host; its zero value copies one file at a time through a buffer of
+// DefaultCopyBufferSize, and walks a volume each time for its stats.
+type NaiveDriver struct {
+	// CopyBufferSize is the size in bytes of the buffer each file is copied
+	// through. 0 uses DefaultCopyBufferSize.
+	CopyBufferSize int
+
+	// CopyParallelism is how many files and directories are copied at once,
+	// each directory's entries being independent of those of the others. 0
+	// or 1 copies them one at a time.
+	CopyParallelism int
+
+	// StatsCacheTTL is how long what walking a volume found is used for its
+	// stats before it is walked again, unless it is forgotten first as the
+	// volume is written to. 0 walks it each time.
+	StatsCacheTTL time.Duration
+
+	usage usageCache
+}
+
+func (driver *NaiveDriver) Name() string {
+	return "naive"
+}
 
 func (driver *NaiveDriver) CreateVolume(path string) error {
 	return os.Mkdir(path, 0755)
 }
 
 func (driver *NaiveDriver) DestroyVolume(path string) error {
-	return os.RemoveAll(path)
+	err := os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+
+	driver.usage.Forget(path)
+
+	return nil
+}
+
+// GetVolumeStats walks the volume, or uses what its last walk found if that
+// is within StatsCacheTTL. Either way its size is an estimate, added up
+// from its files rather than read from the filesystem's accounting.
+func (driver *NaiveDriver) GetVolumeStats(path string) (volume.DriverStats, error) {
+	size, count, err := driver.usage.Walk(path, driver.StatsCacheTTL)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	free, err := freeBytes(path)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	return volume.DriverStats{
+		UsedBytes: size,
+		FileCount: count,
+		FreeBytes: free,
+	}, nil
+}
+
+// ForgetVolumeStats drops what the volume's last walk found.
+func (driver *NaiveDriver) ForgetVolumeStats(path string) {
+	driver.usage.Forget(path)
+}
+
+// CreateClone copies the source as for a copy-on-write layer, which is just
+// as independent of it.
+func (driver *NaiveDriver) CreateClone(path string, source string) error {
+	return driver.CreateCopyOnWriteLayer(path, source)
+}
+
+func (driver *NaiveDriver) copyBufferSize() int {
+	if driver.CopyBufferSize <= 0 {
+		return DefaultCopyBufferSize
+	}
+
+	return driver.CopyBufferSize
+}
+
+func (driver *NaiveDriver) copyParallelism() int {
+	if driver.CopyParallelism <= 1 {
+		return 1
+	}
+
+	return driver.CopyParallelism
 }
@@ -0,0 +1,327 @@
+// +build !windows
+
+package driver
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// treeCopier copies a directory as cp -a would, keeping modes, times, owners,
+// symlinks, and hard links. The entries of each directory are independent of
+// each other, so they are copied at once while there are slots for them, and
+// one after the other in the copying goroutine otherwise.
+//
+// Owners are only kept when copying as root, as cp does. They are kept as
+// they are, so repository namespacing of the copy for whether it is
+// privileged works as it does for any other volume.
+type treeCopier struct {
+	buffers sync.Pool
+	slots   chan struct{}
+	chown   bool
+
+	// merge copies over what is already at the destination, as when copying
+	// into a volume rather than making a new one
+	merge bool
+
+	linksLock sync.Mutex
+	links     map[fileID]*copiedLink
+}
+
+// copiedLink is the first copy of a file with several hard links, which the
+// copies of the others are linked to once it is done.
+type copiedLink struct {
+	path string
+	done chan struct{}
+	err  error
+}
+
+func newTreeCopier(bufferSize int, parallelism int) *treeCopier {
+	return &treeCopier{
+		buffers: sync.Pool{
+			New: func() interface{} {
+				buffer := make([]byte, bufferSize)
+				return &buffer
+			},
+		},
+
+		// the copying goroutine is one of them
+		slots: make(chan struct{}, parallelism-1),
+		chown: os.Geteuid() == 0,
+
+		links: map[fileID]*copiedLink{},
+	}
+}
+
+// Copy copies the directory at src, following it if it is a symlink, to dest,
+// which must not exist.
+func (copier *treeCopier) Copy(src string, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	return copier.copyDir(src, dest, info)
+}
+
+// CopyInto copies what is at src into the directory at dest as cp -a does,
+// copying over what is already there: a directory's contents are merged
+// into dest, which is given its mode and times, and anything else is copied
+// into dest by its name.
+func (copier *treeCopier) CopyInto(src string, dest string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	copier.merge = true
+
+	if !info.IsDir() {
+		return copier.copyEntry(src, filepath.Join(dest, info.Name()), info)
+	}
+
+	err = os.Chmod(dest, 0700)
+	if err != nil {
+		return err
+	}
+
+	return copier.copyEntries(src, dest, info)
+}
+
+func (copier *treeCopier) copyDir(src string, dest string, info os.FileInfo) error {
+	// writable until its entries are in, whatever its mode
+	err := os.Mkdir(dest, 0700)
+	if err != nil {
+		return err
+	}
+
+	return copier.copyEntries(src, dest, info)
+}
+
+func (copier *treeCopier) copyEntries(src string, dest string, info os.FileInfo) error {
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, len(entries))
+
+	wg := new(sync.WaitGroup)
+
+entries:
+	for i, entry := range entries {
+		i, entry := i, entry
+
+		copyEntry := func() {
+			errs[i] = copier.copyEntry(filepath.Join(src, entry.Name()), filepath.Join(dest, entry.Name()), entry)
+		}
+
+		select {
+		case copier.slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-copier.slots }()
+
+				copyEntry()
+			}()
+		default:
+			copyEntry()
+
+			if errs[i] != nil {
+				break entries
+			}
+		}
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return copier.applyInfo(dest, info)
+}
+
+func (copier *treeCopier) copyEntry(src string, dest string, info os.FileInfo) error {
+	if copier.merge {
+		merged, err := copier.replace(dest, info)
+		if err != nil {
+			return err
+		}
+
+		if merged {
+			return copier.copyEntries(src, dest, info)
+		}
+	}
+
+	if info.IsDir() {
+		return copier.copyDir(src, dest, info)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return copier.copySymlink(src, dest, info)
+	}
+
+	_, id, linked := diskUsage(info)
+	if linked {
+		return copier.copyLinked(src, dest, info, id)
+	}
+
+	return copier.copyFile(src, dest, info)
+}
+
+// replace makes way for the copy of an entry at dest, removing what is there
+// unless both are directories, in which case it returns true for the entry's
+// contents to be merged into it. A directory is not replaced with anything
+// else unless it is empty, as with cp.
+func (copier *treeCopier) replace(dest string, info os.FileInfo) (bool, error) {
+	existing, err := os.Lstat(dest)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if existing.IsDir() && info.IsDir() {
+		// writable until its entries are in, as for a new one
+		return true, os.Chmod(dest, 0700)
+	}
+
+	return false, os.Remove(dest)
+}
+
+// copyLinked copies the first of a file's hard links found, and links the
+// copies of the others to it. Another goroutine may be copying it, in which
+// case the copy is waited for.
+func (copier *treeCopier) copyLinked(src string, dest string, info os.FileInfo, id fileID) error {
+	copier.linksLock.Lock()
+	first, found := copier.links[id]
+	if !found {
+		first = &copiedLink{path: dest, done: make(chan struct{})}
+		copier.links[id] = first
+	}
+	copier.linksLock.Unlock()
+
+	if !found {
+		first.err = copier.copyFile(src, dest, info)
+		close(first.done)
+		return first.err
+	}
+
+	<-first.done
+
+	if first.err != nil {
+		return first.err
+	}
+
+	return os.Link(first.path, dest)
+}
+
+func (copier *treeCopier) copyFile(src string, dest string, info os.FileInfo) error {
+	var err error
+	if info.Mode().IsRegular() {
+		err = copier.copyContents(src, dest)
+	} else {
+		err = copier.copyNode(dest, info)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return copier.applyInfo(dest, info)
+}
+
+func (copier *treeCopier) copyContents(src string, dest string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+
+	file, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	buffer := copier.buffers.Get().(*[]byte)
+	defer copier.buffers.Put(buffer)
+
+	// hidden from io.CopyBuffer so that it goes through the buffer, rather
+	// than the file picking how to copy
+	_, err = io.CopyBuffer(struct{ io.Writer }{file}, struct{ io.Reader }{source}, *buffer)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// copyNode makes devices, fifos, and sockets anew, as they have no contents
+// to copy.
+func (copier *treeCopier) copyNode(dest string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return &os.PathError{Op: "copy", Path: dest, Err: syscall.ENOTSUP}
+	}
+
+	return syscall.Mknod(dest, uint32(stat.Mode), int(stat.Rdev))
+}
+
+func (copier *treeCopier) copySymlink(src string, dest string, info os.FileInfo) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+
+	err = os.Symlink(target, dest)
+	if err != nil {
+		return err
+	}
+
+	if copier.chown {
+		return copier.lchown(dest, info)
+	}
+
+	return nil
+}
+
+// applyInfo sets the owner, mode, and modification time of the original on
+// the copy. The access time is set to the modification time, as when
+// extracting entries without one.
+func (copier *treeCopier) applyInfo(path string, info os.FileInfo) error {
+	if copier.chown {
+		err := copier.lchown(path, info)
+		if err != nil {
+			return err
+		}
+	}
+
+	// chmod after chown, which clears setuid and setgid
+	err := os.Chmod(path, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+func (copier *treeCopier) lchown(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return os.Lchown(path, int(stat.Uid), int(stat.Gid))
+}
@@ -0,0 +1,84 @@
+package driver_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+// BenchmarkNaiveCopyManySmallFiles copies a volume of 5000 4KiB files in 50
+// directories at each copy parallelism.
+func BenchmarkNaiveCopyManySmallFiles(b *testing.B) {
+	parent := naiveCopyParent(b, 50, 100, 4*1024)
+	defer os.RemoveAll(parent)
+
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism-%d", parallelism), func(b *testing.B) {
+			benchmarkNaiveCopy(b, parent, 50*100*4*1024, &driver.NaiveDriver{CopyParallelism: parallelism})
+		})
+	}
+}
+
+// BenchmarkNaiveCopyLargeFiles copies a volume of 4 64MiB files at each copy
+// buffer size.
+func BenchmarkNaiveCopyLargeFiles(b *testing.B) {
+	parent := naiveCopyParent(b, 1, 4, 64*1024*1024)
+	defer os.RemoveAll(parent)
+
+	for _, bufferSize := range []int{4 * 1024, 128 * 1024, 1024 * 1024} {
+		b.Run(fmt.Sprintf("buffer-%d", bufferSize), func(b *testing.B) {
+			benchmarkNaiveCopy(b, parent, 4*64*1024*1024, &driver.NaiveDriver{CopyBufferSize: bufferSize})
+		})
+	}
+}
+
+func benchmarkNaiveCopy(b *testing.B, parent string, size int64, fsDriver *driver.NaiveDriver) {
+	layersDir, err := ioutil.TempDir("", "naive-copy-benchmark")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer os.RemoveAll(layersDir)
+
+	b.SetBytes(size)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := fsDriver.CreateCopyOnWriteLayer(filepath.Join(layersDir, fmt.Sprintf("layer-%d", i)), parent)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func naiveCopyParent(b *testing.B, dirs int, filesPerDir int, fileSize int) string {
+	parent, err := ioutil.TempDir("", "naive-copy-parent")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	contents := bytes.Repeat([]byte("x"), fileSize)
+
+	for d := 0; d < dirs; d++ {
+		dir := filepath.Join(parent, fmt.Sprintf("dir-%d", d))
+
+		err := os.Mkdir(dir, 0755)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for f := 0; f < filesPerDir; f++ {
+			err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%d", f)), contents, 0644)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	return parent
+}
@@ -0,0 +1,203 @@
+// +build !windows
+
+package driver_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("Naive copying", func() {
+	var (
+		tempDir  string
+		fsDriver *driver.NaiveDriver
+
+		parentPath string
+		layerPath  string
+		modTime    time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "baggageclaim_naive_copy_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		fsDriver = &driver.NaiveDriver{}
+
+		parentPath = filepath.Join(tempDir, "parent")
+		layerPath = filepath.Join(tempDir, "layer")
+		modTime = time.Unix(1000, 0)
+
+		Expect(fsDriver.CreateVolume(parentPath)).To(Succeed())
+
+		for i := 0; i < 10; i++ {
+			dir := filepath.Join(parentPath, fmt.Sprintf("dir-%d", i))
+			Expect(os.MkdirAll(filepath.Join(dir, "nested"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "nested", "some-file"), []byte(fmt.Sprintf("contents-%d", i)), 0644)).To(Succeed())
+		}
+
+		executable := filepath.Join(parentPath, "dir-0", "executable")
+		Expect(ioutil.WriteFile(executable, []byte("#!/bin/sh"), 0755)).To(Succeed())
+		Expect(os.Chmod(executable, 0750|os.ModeSetgid)).To(Succeed())
+		Expect(os.Chtimes(executable, modTime, modTime)).To(Succeed())
+
+		Expect(os.Symlink("nested/some-file", filepath.Join(parentPath, "dir-1", "some-symlink"))).To(Succeed())
+
+		for i := 2; i < 10; i++ {
+			Expect(os.Link(filepath.Join(parentPath, "dir-0", "nested", "some-file"), filepath.Join(parentPath, fmt.Sprintf("dir-%d", i), "some-link"))).To(Succeed())
+		}
+
+		Expect(syscall.Mkfifo(filepath.Join(parentPath, "dir-2", "some-fifo"), 0600)).To(Succeed())
+
+		Expect(os.Chmod(filepath.Join(parentPath, "dir-3"), 0555)).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(parentPath, "dir-3"), modTime, modTime)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		// so that the read-only dirs can be emptied without root
+		os.Chmod(filepath.Join(parentPath, "dir-3"), 0755)
+		os.Chmod(filepath.Join(layerPath, "dir-3"), 0755)
+
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	itCopiesTheParent := func() {
+		It("copies the parent's contents, modes, and times", func() {
+			Expect(fsDriver.CreateCopyOnWriteLayer(layerPath, parentPath)).To(Succeed())
+
+			for i := 0; i < 10; i++ {
+				contents, err := ioutil.ReadFile(filepath.Join(layerPath, fmt.Sprintf("dir-%d", i), "nested", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal(fmt.Sprintf("contents-%d", i)))
+			}
+
+			info, err := os.Stat(filepath.Join(layerPath, "dir-0", "executable"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(0750 | os.ModeSetgid))
+			Expect(info.ModTime()).To(Equal(modTime))
+
+			info, err = os.Stat(filepath.Join(layerPath, "dir-3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0555)))
+			Expect(info.ModTime()).To(Equal(modTime))
+
+			info, err = os.Lstat(filepath.Join(layerPath, "dir-2", "some-fifo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode() & os.ModeNamedPipe).NotTo(BeZero())
+		})
+
+		It("keeps symlinks as symlinks", func() {
+			Expect(fsDriver.CreateCopyOnWriteLayer(layerPath, parentPath)).To(Succeed())
+
+			target, err := os.Readlink(filepath.Join(layerPath, "dir-1", "some-symlink"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal("nested/some-file"))
+		})
+
+		It("keeps hard links linked to each other, but not to the parent's", func() {
+			Expect(fsDriver.CreateCopyOnWriteLayer(layerPath, parentPath)).To(Succeed())
+
+			original, err := os.Stat(filepath.Join(layerPath, "dir-0", "nested", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			for i := 2; i < 10; i++ {
+				link, err := os.Stat(filepath.Join(layerPath, fmt.Sprintf("dir-%d", i), "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.SameFile(original, link)).To(BeTrue())
+			}
+
+			parentFile, err := os.Stat(filepath.Join(parentPath, "dir-0", "nested", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.SameFile(original, parentFile)).To(BeFalse())
+		})
+
+		It("keeps owners when copying as root", func() {
+			if os.Geteuid() != 0 {
+				Skip("owners can only be kept as root")
+			}
+
+			Expect(os.Lchown(filepath.Join(parentPath, "dir-4", "nested", "some-file"), 1234, 5678)).To(Succeed())
+			Expect(os.Lchown(filepath.Join(parentPath, "dir-1", "some-symlink"), 4321, 8765)).To(Succeed())
+
+			Expect(fsDriver.CreateCopyOnWriteLayer(layerPath, parentPath)).To(Succeed())
+
+			info, err := os.Lstat(filepath.Join(layerPath, "dir-4", "nested", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(1234))
+			Expect(info.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(5678))
+
+			info, err = os.Lstat(filepath.Join(layerPath, "dir-1", "some-symlink"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(4321))
+			Expect(info.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(8765))
+		})
+	}
+
+	Context("one file at a time", func() {
+		itCopiesTheParent()
+	})
+
+	Context("with a small buffer, many files at once", func() {
+		BeforeEach(func() {
+			fsDriver.CopyBufferSize = 3
+			fsDriver.CopyParallelism = 8
+		})
+
+		itCopiesTheParent()
+	})
+
+	It("clones by copying", func() {
+		Expect(fsDriver.CreateClone(layerPath, parentPath)).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(layerPath, "dir-5", "nested", "some-file"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("contents-5"))
+	})
+
+	It("fails when the layer already exists", func() {
+		Expect(os.Mkdir(layerPath, 0755)).To(Succeed())
+		Expect(fsDriver.CreateCopyOnWriteLayer(layerPath, parentPath)).NotTo(Succeed())
+	})
+
+	Describe("CopyData", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(layerPath, "dir-5", "nested"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(layerPath, "dir-5", "nested", "some-file"), []byte("stale"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(layerPath, "dir-5", "kept-file"), []byte("kept"), 0644)).To(Succeed())
+		})
+
+		It("merges a directory's contents into dest, copying over what is there", func() {
+			Expect(fsDriver.CopyData(parentPath, layerPath)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filepath.Join(layerPath, "dir-5", "nested", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("contents-5"))
+
+			contents, err = ioutil.ReadFile(filepath.Join(layerPath, "dir-5", "kept-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("kept"))
+
+			info, err := os.Stat(filepath.Join(layerPath, "dir-3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0555)))
+		})
+
+		It("copies anything else into dest by its name", func() {
+			Expect(fsDriver.CopyData(filepath.Join(parentPath, "dir-0", "executable"), filepath.Join(layerPath, "dir-5"))).To(Succeed())
+
+			info, err := os.Stat(filepath.Join(layerPath, "dir-5", "executable"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(0750 | os.ModeSetgid))
+			Expect(info.ModTime()).To(Equal(modTime))
+		})
+	})
+})
@@ -0,0 +1,194 @@
+package driver
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+// fscryptAddKeyArg is the argument to FS_IOC_ADD_ENCRYPTION_KEY, which is
+// followed by the raw key.
+type fscryptAddKeyArg struct {
+	unix.FscryptAddKeyArg
+	raw [unix.FSCRYPT_MAX_KEY_SIZE]byte
+}
+
+// CreateEncryptedVolume creates the volume with a v2 fscrypt policy, adding
+// the key to the filesystem holding it. The filesystem must have encryption
+// enabled, e.g. ext4 with the encrypt feature.
+func (driver *NaiveDriver) CreateEncryptedVolume(path string, key []byte) error {
+	err := driver.CreateVolume(path)
+	if err != nil {
+		return err
+	}
+
+	identifier, err := addFscryptKey(path, key)
+	if err != nil {
+		return err
+	}
+
+	policy := unix.FscryptPolicyV2{
+		Version:                   unix.FSCRYPT_POLICY_V2,
+		Contents_encryption_mode:  unix.FSCRYPT_MODE_AES_256_XTS,
+		Filenames_encryption_mode: unix.FSCRYPT_MODE_AES_256_CTS,
+		Flags:                     unix.FSCRYPT_POLICY_FLAGS_PAD_32,
+		Master_key_identifier:     identifier,
+	}
+
+	return withDir(path, func(fd int) error {
+		return fscryptError(ioctl(fd, unix.FS_IOC_SET_ENCRYPTION_POLICY, unsafe.Pointer(&policy)))
+	})
+}
+
+// UnlockVolume adds the volume's key back to its filesystem unless it is
+// already there, as it is until the filesystem is unmounted.
+func (driver *NaiveDriver) UnlockVolume(path string, key []byte) (bool, error) {
+	identifier, err := fscryptIdentifier(path)
+	if err != nil {
+		return false, err
+	}
+
+	status := unix.FscryptGetKeyStatusArg{Key_spec: fscryptKeySpec(identifier)}
+
+	err = withDir(path, func(fd int) error {
+		return ioctl(fd, unix.FS_IOC_GET_ENCRYPTION_KEY_STATUS, unsafe.Pointer(&status))
+	})
+	if err != nil {
+		return false, fscryptError(err)
+	}
+
+	if status.Status == unix.FSCRYPT_KEY_STATUS_PRESENT {
+		return false, nil
+	}
+
+	_, err = addFscryptKey(path, key)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LockVolume removes the volume's key from its filesystem. Files that are
+// still open stay readable by whatever has them open until they are closed.
+// A volume that isn't there, or isn't encrypted, has nothing to lock, nor
+// does one on a filesystem that can't encrypt.
+func (driver *NaiveDriver) LockVolume(path string) error {
+	identifier, err := fscryptIdentifier(path)
+	if err == unix.ENODATA || err == volume.ErrEncryptionNotSupported || os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	remove := unix.FscryptRemoveKeyArg{Key_spec: fscryptKeySpec(identifier)}
+
+	err = withDir(path, func(fd int) error {
+		return ioctl(fd, unix.FS_IOC_REMOVE_ENCRYPTION_KEY, unsafe.Pointer(&remove))
+	})
+	if err == unix.ENOKEY {
+		return nil
+	}
+
+	return fscryptError(err)
+}
+
+// addFscryptKey adds the key to the filesystem holding path, returning the
+// identifier the kernel derived for it.
+func addFscryptKey(path string, key []byte) ([16]byte, error) {
+	var identifier [16]byte
+
+	if len(key) > unix.FSCRYPT_MAX_KEY_SIZE {
+		return identifier, volume.ErrEncryptionNotSupported
+	}
+
+	arg := &fscryptAddKeyArg{}
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	arg.Raw_size = uint32(len(key))
+	copy(arg.raw[:], key)
+
+	defer func() {
+		for i := range arg.raw {
+			arg.raw[i] = 0
+		}
+	}()
+
+	err := withDir(path, func(fd int) error {
+		return ioctl(fd, unix.FS_IOC_ADD_ENCRYPTION_KEY, unsafe.Pointer(arg))
+	})
+	if err != nil {
+		return identifier, fscryptError(err)
+	}
+
+	copy(identifier[:], arg.Key_spec.U[:])
+
+	return identifier, nil
+}
+
+// fscryptIdentifier returns the identifier of the key the directory is
+// encrypted with, or ENODATA if it isn't.
+func fscryptIdentifier(path string) ([16]byte, error) {
+	var identifier [16]byte
+
+	arg := unix.FscryptGetPolicyExArg{Size: uint64(unsafe.Sizeof(unix.FscryptPolicyV2{}))}
+
+	err := withDir(path, func(fd int) error {
+		return ioctl(fd, unix.FS_IOC_GET_ENCRYPTION_POLICY_EX, unsafe.Pointer(&arg))
+	})
+	if err == unix.ENODATA {
+		return identifier, err
+	}
+
+	if err != nil {
+		return identifier, fscryptError(err)
+	}
+
+	policy := (*unix.FscryptPolicyV2)(unsafe.Pointer(&arg.Policy))
+	if policy.Version != unix.FSCRYPT_POLICY_V2 {
+		return identifier, volume.ErrEncryptionNotSupported
+	}
+
+	return policy.Master_key_identifier, nil
+}
+
+func fscryptKeySpec(identifier [16]byte) unix.FscryptKeySpecifier {
+	spec := unix.FscryptKeySpecifier{Type: unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER}
+	copy(spec.U[:], identifier[:])
+	return spec
+}
+
+// fscryptError returns ErrEncryptionNotSupported for errors that mean the
+// filesystem or kernel can't encrypt directories.
+func fscryptError(err error) error {
+	switch err {
+	case unix.EOPNOTSUPP, unix.ENOTTY:
+		return volume.ErrEncryptionNotSupported
+	default:
+		return err
+	}
+}
+
+func withDir(path string, do func(int) error) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer dir.Close()
+
+	return do(int(dir.Fd()))
+}
+
+func ioctl(fd int, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
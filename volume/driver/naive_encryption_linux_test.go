@@ -0,0 +1,83 @@
+package driver_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("Naive encryption", func() {
+	var (
+		tempDir    string
+		volumePath string
+		key        []byte
+		fsDriver   *driver.NaiveDriver
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "baggageclaim_naive_encryption_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		volumePath = filepath.Join(tempDir, "volume")
+		key = bytes.Repeat([]byte{0x42}, 64)
+
+		fsDriver = &driver.NaiveDriver{}
+
+		err = fsDriver.CreateEncryptedVolume(volumePath, key)
+		if err == volume.ErrEncryptionNotSupported {
+			os.RemoveAll(tempDir)
+			Skip("the filesystem does not support encryption")
+		}
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(fsDriver.LockVolume(volumePath)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("reads and writes the volume as it is while it is unlocked", func() {
+		Expect(ioutil.WriteFile(filepath.Join(volumePath, "some-file"), []byte("some-contents"), 0644)).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(volumePath, "some-file"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-contents"))
+
+		unlocked, err := fsDriver.UnlockVolume(volumePath, key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unlocked).To(BeFalse())
+	})
+
+	It("can't find the volume's files once it is locked, until it is unlocked", func() {
+		Expect(ioutil.WriteFile(filepath.Join(volumePath, "some-file"), []byte("some-contents"), 0644)).To(Succeed())
+
+		Expect(fsDriver.LockVolume(volumePath)).To(Succeed())
+
+		_, err := ioutil.ReadFile(filepath.Join(volumePath, "some-file"))
+		Expect(err).To(HaveOccurred())
+
+		unlocked, err := fsDriver.UnlockVolume(volumePath, key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unlocked).To(BeTrue())
+
+		contents, err := ioutil.ReadFile(filepath.Join(volumePath, "some-file"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-contents"))
+	})
+
+	It("leaves a volume that isn't encrypted alone when locking it", func() {
+		otherPath := filepath.Join(tempDir, "other-volume")
+		Expect(fsDriver.CreateVolume(otherPath)).To(Succeed())
+
+		Expect(fsDriver.LockVolume(otherPath)).To(Succeed())
+	})
+})
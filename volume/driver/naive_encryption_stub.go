@@ -0,0 +1,17 @@
+// +build !linux
+
+package driver
+
+import "github.com/concourse/baggageclaim/volume"
+
+func (driver *NaiveDriver) CreateEncryptedVolume(path string, key []byte) error {
+	return volume.ErrEncryptionNotSupported
+}
+
+func (driver *NaiveDriver) UnlockVolume(path string, key []byte) (bool, error) {
+	return false, volume.ErrEncryptionNotSupported
+}
+
+func (driver *NaiveDriver) LockVolume(path string) error {
+	return nil
+}
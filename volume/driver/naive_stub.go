@@ -2,31 +2,14 @@
 
 package driver
 
-import (
-	"bytes"
-	"fmt"
-	"os/exec"
-)
-
+// CreateCopyOnWriteLayer copies the parent, as there is nothing to layer on
+// top of it with.
 func (driver *NaiveDriver) CreateCopyOnWriteLayer(path string, parent string) error {
-	return exec.Command("cp", "-r", parent, path).Run()
+	return newTreeCopier(driver.copyBufferSize(), driver.copyParallelism()).Copy(parent, path)
 }
 
-func (driver *NaiveDriver) GetVolumeSizeInBytes(path string) (int64, error) {
-	stdout := &bytes.Buffer{}
-	cmd := exec.Command("du", "-s", path)
-	cmd.Stdout = stdout
-
-	err := cmd.Run()
-	if err != nil {
-		return 0, err
-	}
-
-	var size int64
-	_, err = fmt.Sscanf(stdout.String(), "%d", &size)
-	if err != nil {
-		return 0, err
-	}
-
-	return size * 512, nil
+// CopyData copies in Go as for copy-on-write layers, over whatever is already
+// at dest, rather than shelling out to cp.
+func (driver *NaiveDriver) CopyData(src string, dest string) error {
+	return newTreeCopier(driver.copyBufferSize(), driver.copyParallelism()).CopyInto(src, dest)
 }
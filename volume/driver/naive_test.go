@@ -0,0 +1,113 @@
+package driver_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("Naive", func() {
+	var (
+		tempDir  string
+		fsDriver *driver.NaiveDriver
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "baggageclaim_naive_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		fsDriver = &driver.NaiveDriver{}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	Describe("GetVolumeStats", func() {
+		var volumePath string
+
+		BeforeEach(func() {
+			volumePath = filepath.Join(tempDir, "volume")
+			Expect(fsDriver.CreateVolume(volumePath)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(volumePath, "some-dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(volumePath, "some-dir", "some-file"), make([]byte, 64*1024), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(volumePath, "other-file"), []byte("other"), 0644)).To(Succeed())
+		})
+
+		It("returns the estimated size and the number of entries below the path", func() {
+			stats, err := fsDriver.GetVolumeStats(volumePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stats.FileCount).To(Equal(int64(3)))
+			Expect(stats.UsedBytes).To(BeNumerically(">=", 64*1024))
+			Expect(stats.Exact).To(BeFalse())
+		})
+
+		It("counts hard-linked content only once towards the size", func() {
+			before, err := fsDriver.GetVolumeStats(volumePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = os.Link(filepath.Join(volumePath, "some-dir", "some-file"), filepath.Join(volumePath, "some-link"))
+			Expect(err).NotTo(HaveOccurred())
+
+			after, err := fsDriver.GetVolumeStats(volumePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(after.FileCount).To(Equal(int64(4)))
+			Expect(after.UsedBytes).To(Equal(before.UsedBytes))
+		})
+
+		Context("with a stats cache TTL", func() {
+			BeforeEach(func() {
+				fsDriver.StatsCacheTTL = time.Hour
+			})
+
+			It("returns what the last walk found until the volume is destroyed", func() {
+				before, err := fsDriver.GetVolumeStats(volumePath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ioutil.WriteFile(filepath.Join(volumePath, "new-file"), make([]byte, 64*1024), 0644)).To(Succeed())
+
+				cached, err := fsDriver.GetVolumeStats(volumePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cached.UsedBytes).To(Equal(before.UsedBytes))
+				Expect(cached.FileCount).To(Equal(int64(3)))
+
+				Expect(fsDriver.DestroyVolume(volumePath)).To(Succeed())
+				Expect(fsDriver.CreateVolume(volumePath)).To(Succeed())
+
+				recreated, err := fsDriver.GetVolumeStats(volumePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recreated.FileCount).To(BeZero())
+			})
+
+			It("walks the volume again once its stats are forgotten", func() {
+				_, err := fsDriver.GetVolumeStats(volumePath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ioutil.WriteFile(filepath.Join(volumePath, "new-file"), []byte("new"), 0644)).To(Succeed())
+
+				fsDriver.ForgetVolumeStats(volumePath)
+
+				walked, err := fsDriver.GetVolumeStats(volumePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(walked.FileCount).To(Equal(int64(4)))
+			})
+		})
+
+		Context("when the path does not exist", func() {
+			It("returns an error", func() {
+				_, err := fsDriver.GetVolumeStats(filepath.Join(tempDir, "bogus"))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -2,36 +2,46 @@ package driver
 
 import (
 	"bytes"
-	"errors"
+	"fmt"
+	"os"
 	"os/exec"
-	"regexp"
-	"strconv"
+	"path/filepath"
 	"syscall"
 )
 
+// CreateCopyOnWriteLayer copies the parent with robocopy, using as many
+// threads as the driver's parallelism. Robocopy picks its own buffers.
 func (driver *NaiveDriver) CreateCopyOnWriteLayer(path string, parent string) error {
-	_, err := robocopy("/e", "/nfl", "/ndl", parent, path)
+	args := []string{"/e", "/nfl", "/ndl"}
+	if parallelism := driver.copyParallelism(); parallelism > 1 {
+		args = append(args, fmt.Sprintf("/mt:%d", parallelism))
+	}
+
+	_, err := robocopy(append(args, parent, path)...)
 	return err
 }
 
-func (driver *NaiveDriver) GetVolumeSizeInBytes(path string) (int64, error) {
-	re := regexp.MustCompile("Bytes :\\s*(\\d*)")
-	stdout, err := robocopy("/l", "/nfl", "/ndl", path, "\\\\localhost\\C$\\nul", "/e", "/bytes")
+// CopyData copies with robocopy as for copy-on-write layers, as there is no
+// cp to shell out to. A file is copied by naming it out of its directory.
+func (driver *NaiveDriver) CopyData(src string, dest string) error {
+	args := []string{"/e", "/nfl", "/ndl"}
+	if parallelism := driver.copyParallelism(); parallelism > 1 {
+		args = append(args, fmt.Sprintf("/mt:%d", parallelism))
+	}
 
+	info, err := os.Stat(src)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	matches := re.FindStringSubmatch(stdout)
-
-	if matches != nil {
-		size, err := strconv.ParseInt(matches[1], 10, 64)
-		if err != nil {
-			return 0, err
-		}
-		return size, nil
+	if info.IsDir() {
+		args = append(args, src, dest)
+	} else {
+		args = append(args, filepath.Dir(src), dest, filepath.Base(src))
 	}
-	return 0, errors.New("Unable to extract size from robocopy output")
+
+	_, err = robocopy(args...)
+	return err
 }
 
 func robocopy(args ...string) (string, error) {
@@ -1,17 +1,47 @@
 package driver
 
 import (
-	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
+
+	"github.com/concourse/baggageclaim/volume"
 )
 
 type OverlayDriver struct {
 	OverlaysDir string
+
+	// StatsCacheTTL is how long what walking a volume's layer dir found is
+	// used for its stats before it is walked again, unless it is forgotten
+	// first as the volume is written to. 0 walks it each time.
+	StatsCacheTTL time.Duration
+
+	usage usageCache
+}
+
+func (driver *OverlayDriver) Name() string {
+	return "overlay"
+}
+
+// CheckMount makes sure the overlays directory is still there, as it may be on
+// a mount of its own.
+func (driver *OverlayDriver) CheckMount(path string) error {
+	info, err := os.Stat(driver.OverlaysDir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("overlays directory is not a directory: %s", driver.OverlaysDir)
+	}
+
+	return nil
 }
 
 func (driver *OverlayDriver) CreateVolume(path string) error {
@@ -46,6 +76,18 @@ func (driver *OverlayDriver) DestroyVolume(path string) error {
 		return err
 	}
 
+	err = os.RemoveAll(driver.promotingDir(path))
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(driver.promotedDir(path))
+	if err != nil {
+		return err
+	}
+
+	driver.usage.Forget(driver.layerDir(path))
+
 	return os.RemoveAll(path)
 }
 
@@ -83,23 +125,228 @@ func (driver *OverlayDriver) CreateCopyOnWriteLayer(path string, parent string)
 	return syscall.Mount("overlay", path, "overlay", 0, opts)
 }
 
-func (driver *OverlayDriver) GetVolumeSizeInBytes(path string) (int64, error) {
-	stdout := &bytes.Buffer{}
-	cmd := exec.Command("du", "-s", driver.layerDir(path))
-	cmd.Stdout = stdout
+func (driver *OverlayDriver) EnsureMounted(path string) (bool, error) {
+	// a promotion that got as far as its copy is finished rather than the
+	// copy-on-write layer mounted again
+	_, err := os.Stat(driver.promotedDir(path))
+	if err == nil {
+		return true, driver.finishPromotion(path)
+	}
+
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	mounted, err := isMountPoint(path)
+	if err != nil {
+		return false, err
+	}
+
+	if mounted {
+		return false, nil
+	}
+
+	// only copy-on-write layers have a work dir
+	_, err = os.Stat(driver.workDir(path))
+	if os.IsNotExist(err) {
+		return true, syscall.Mount(driver.layerDir(path), path, "", syscall.MS_BIND, "")
+	}
 
-	err := cmd.Run()
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
-	var size int64
-	_, err = fmt.Sscanf(stdout.String(), "%d", &size)
+	ancestry, err := driver.ancestry(path)
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
-	return size, nil
+	opts := fmt.Sprintf(
+		"lowerdir=%s,upperdir=%s,workdir=%s",
+		strings.Join(ancestry[1:], ":"),
+		ancestry[0],
+		driver.workDir(path),
+	)
+
+	return true, syscall.Mount("overlay", path, "overlay", 0, opts)
+}
+
+// RenameVolume moves the layer and work dirs, which are named after the
+// volume's handle. The volume's mount moves along with its dir, and keeps
+// using the dirs it was mounted with.
+func (driver *OverlayDriver) RenameVolume(path string, newPath string) error {
+	err := os.Rename(driver.layerDir(path), driver.layerDir(newPath))
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(driver.workDir(path), driver.workDir(newPath))
+	if err != nil && !os.IsNotExist(err) {
+		os.Rename(driver.layerDir(newPath), driver.layerDir(path))
+		return err
+	}
+
+	driver.usage.Forget(driver.layerDir(path))
+
+	return nil
+}
+
+// PromoteVolume copies what a copy-on-write layer shows, its ancestry's data
+// and all, into a layer of its own, which is mounted in place of the overlay.
+// The copy is made aside and has its name once complete; a promotion that
+// fails or crashes before then leaves the layer as it was, and one that gets
+// further is finished by promoting it again or by EnsureMounted.
+func (driver *OverlayDriver) PromoteVolume(path string) error {
+	_, err := os.Stat(driver.promotedDir(path))
+	if err == nil {
+		return driver.finishPromotion(path)
+	}
+
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	// only copy-on-write layers have a work dir
+	_, err = os.Stat(driver.workDir(path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// writes made while copying would be lost once the copy is mounted, so
+	// the overlay is made read-only first, which fails while anything has a
+	// file in it open for writing
+	err = syscall.Mount("", path, "", syscall.MS_REMOUNT|syscall.MS_RDONLY, "")
+	if err != nil {
+		return err
+	}
+
+	err = driver.copyLayer(path)
+	if err != nil {
+		syscall.Mount("", path, "", syscall.MS_REMOUNT, "")
+		return err
+	}
+
+	return driver.finishPromotion(path)
+}
+
+func (driver *OverlayDriver) copyLayer(path string) error {
+	promotingDir := driver.promotingDir(path)
+
+	// left over from a promotion that crashed while copying
+	err := os.RemoveAll(promotingDir)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(promotingDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.Command("cp", "-a", path+"/.", promotingDir).CombinedOutput()
+	if err != nil {
+		os.RemoveAll(promotingDir)
+		return fmt.Errorf("copying layer: %s: %s", err, output)
+	}
+
+	err = os.MkdirAll(filepath.Dir(driver.promotedDir(path)), 0755)
+	if err != nil {
+		os.RemoveAll(promotingDir)
+		return err
+	}
+
+	err = os.Rename(promotingDir, driver.promotedDir(path))
+	if err != nil {
+		os.RemoveAll(promotingDir)
+		return err
+	}
+
+	return nil
+}
+
+// finishPromotion replaces the layer dir with the promoted copy and mounts it
+// as a plain layer, without a work dir. Each step is safe to redo, so it can
+// pick up after any of them.
+func (driver *OverlayDriver) finishPromotion(path string) error {
+	mounted, err := isMountPoint(path)
+	if err != nil {
+		return err
+	}
+
+	if mounted {
+		err = syscall.Unmount(path, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.RemoveAll(driver.workDir(path))
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(driver.layerDir(path))
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(driver.promotedDir(path), driver.layerDir(path))
+	if err != nil {
+		return err
+	}
+
+	driver.usage.Forget(driver.layerDir(path))
+
+	return syscall.Mount(driver.layerDir(path), path, "", syscall.MS_BIND, "")
+}
+
+// MakeReadOnly remounts the volume read-only. COW layers on top of it mount
+// its layer dir rather than the volume, so they are writable.
+func (driver *OverlayDriver) MakeReadOnly(path string) error {
+	return remountReadOnly(path)
+}
+
+func (driver *OverlayDriver) MountOptions() []string {
+	return mountOptionNames()
+}
+
+// SetMountOptions remounts the volume with the options. COW layers on top of
+// it mount its layer dir rather than the volume, so they don't have them.
+func (driver *OverlayDriver) SetMountOptions(path string, options []string) error {
+	return remountWithOptions(path, options)
+}
+
+// GetVolumeStats estimates what the volume uses from walking its layer dir,
+// which only holds what it changed from its parent, or from its last walk if
+// that is within StatsCacheTTL. What is free is that of the overlays dir's
+// filesystem, which the layer dir is on.
+func (driver *OverlayDriver) GetVolumeStats(path string) (volume.DriverStats, error) {
+	layerDir := driver.layerDir(path)
+
+	size, count, err := driver.usage.Walk(layerDir, driver.StatsCacheTTL)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	free, err := freeBytes(layerDir)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	return volume.DriverStats{
+		UsedBytes: size,
+		FileCount: count,
+		FreeBytes: free,
+	}, nil
+}
+
+// ForgetVolumeStats drops what the last walk of the volume's layer dir found.
+func (driver *OverlayDriver) ForgetVolumeStats(path string) {
+	driver.usage.Forget(driver.layerDir(path))
 }
 
 func (driver *OverlayDriver) layerDir(path string) string {
@@ -110,6 +357,14 @@ func (driver *OverlayDriver) workDir(path string) string {
 	return filepath.Join(driver.OverlaysDir, "work", driver.pathId(path))
 }
 
+func (driver *OverlayDriver) promotingDir(path string) string {
+	return filepath.Join(driver.OverlaysDir, "promoting", driver.pathId(path))
+}
+
+func (driver *OverlayDriver) promotedDir(path string) string {
+	return filepath.Join(driver.OverlaysDir, "promoted", driver.pathId(path))
+}
+
 func (driver *OverlayDriver) ancestry(path string) ([]string, error) {
 	ancestry := []string{}
 
@@ -135,3 +390,72 @@ func (driver *OverlayDriver) ancestry(path string) ([]string, error) {
 func (driver *OverlayDriver) pathId(path string) string {
 	return filepath.Base(filepath.Dir(path))
 }
+
+// isMountPoint returns whether something is mounted at the path. The mount
+// points are compared once symlinks are resolved, as the volumes dir may be
+// reached through one, or the path would never match what it was mounted as
+// and each check would mount it again.
+func isMountPoint(path string) (bool, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	mountInfo, err := ioutil.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(string(mountInfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		// spaces and the like are octal-escaped, as in "\040"
+		mountPoint := unescapeMountPoint(fields[4])
+
+		// only mount points that could be the path are resolved, rather
+		// than every one on the host, any of which may hang
+		if filepath.Base(mountPoint) != filepath.Base(resolved) {
+			continue
+		}
+
+		if mountPoint == resolved {
+			return true, nil
+		}
+
+		resolvedMountPoint, err := filepath.EvalSymlinks(mountPoint)
+		if err == nil && resolvedMountPoint == resolved {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func unescapeMountPoint(field string) string {
+	if !strings.Contains(field, "\\") {
+		return field
+	}
+
+	unescaped := []byte{}
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			code, err := strconv.ParseUint(field[i+1:i+4], 8, 8)
+			if err == nil {
+				unescaped = append(unescaped, byte(code))
+				i += 3
+				continue
+			}
+		}
+
+		unescaped = append(unescaped, field[i])
+	}
+
+	return string(unescaped)
+}
@@ -0,0 +1,194 @@
+package driver_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("Overlay", func() {
+	var (
+		tempDir  string
+		liveDir  string
+		fsDriver *driver.OverlayDriver
+
+		parentPath string
+	)
+
+	// volumePath lays the volume out as the filesystem does, with a link to
+	// its parent's dir next to its data for the driver to find its ancestry
+	volumePath := func(handle string, parent string) string {
+		volumeDir := filepath.Join(liveDir, handle)
+		Expect(os.MkdirAll(volumeDir, 0755)).To(Succeed())
+
+		if parent != "" {
+			Expect(os.Symlink(filepath.Join(liveDir, parent), filepath.Join(volumeDir, "parent"))).To(Succeed())
+		}
+
+		return filepath.Join(volumeDir, "volume")
+	}
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("needs to mount overlays")
+		}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "baggageclaim_overlay_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		liveDir = filepath.Join(tempDir, "live")
+
+		fsDriver = &driver.OverlayDriver{OverlaysDir: filepath.Join(tempDir, "overlays")}
+
+		parentPath = volumePath("parent-handle", "")
+		Expect(fsDriver.CreateVolume(parentPath)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(parentPath, "parent-file"), []byte("parent"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if tempDir == "" {
+			return
+		}
+
+		Expect(fsDriver.DestroyVolume(parentPath)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("keeps a volume's data in its layer dir", func() {
+		Expect(ioutil.ReadFile(filepath.Join(tempDir, "overlays", "parent-handle", "parent-file"))).To(Equal([]byte("parent")))
+	})
+
+	Describe("CreateCopyOnWriteLayer", func() {
+		var childPath string
+
+		BeforeEach(func() {
+			childPath = volumePath("child-handle", "parent-handle")
+			Expect(fsDriver.CreateCopyOnWriteLayer(childPath, parentPath)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			if _, err := os.Stat(childPath); err == nil {
+				Expect(fsDriver.DestroyVolume(childPath)).To(Succeed())
+			}
+		})
+
+		It("layers the child over its parent's data", func() {
+			Expect(ioutil.ReadFile(filepath.Join(childPath, "parent-file"))).To(Equal([]byte("parent")))
+		})
+
+		It("keeps what is written to the child from the parent", func() {
+			Expect(ioutil.WriteFile(filepath.Join(childPath, "parent-file"), []byte("child"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(childPath, "child-file"), []byte("child"), 0644)).To(Succeed())
+
+			Expect(ioutil.ReadFile(filepath.Join(parentPath, "parent-file"))).To(Equal([]byte("parent")))
+			Expect(filepath.Join(parentPath, "child-file")).NotTo(BeAnExistingFile())
+		})
+
+		It("only counts what the child changed towards its stats", func() {
+			Expect(ioutil.WriteFile(filepath.Join(childPath, "child-file"), []byte("child"), 0644)).To(Succeed())
+
+			stats, err := fsDriver.GetVolumeStats(childPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.FileCount).To(Equal(int64(1)))
+		})
+
+		Describe("PromoteVolume", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(filepath.Join(childPath, "child-file"), []byte("child"), 0644)).To(Succeed())
+				Expect(fsDriver.PromoteVolume(childPath)).To(Succeed())
+			})
+
+			It("gives the child its parent's data of its own, along with what it wrote", func() {
+				Expect(ioutil.ReadFile(filepath.Join(tempDir, "overlays", "child-handle", "parent-file"))).To(Equal([]byte("parent")))
+				Expect(ioutil.ReadFile(filepath.Join(tempDir, "overlays", "child-handle", "child-file"))).To(Equal([]byte("child")))
+
+				Expect(ioutil.WriteFile(filepath.Join(parentPath, "parent-file"), []byte("changed"), 0644)).To(Succeed())
+				Expect(ioutil.ReadFile(filepath.Join(childPath, "parent-file"))).To(Equal([]byte("parent")))
+			})
+
+			It("leaves the child writable", func() {
+				Expect(ioutil.WriteFile(filepath.Join(childPath, "other-file"), []byte("other"), 0644)).To(Succeed())
+				Expect(ioutil.ReadFile(filepath.Join(tempDir, "overlays", "child-handle", "other-file"))).To(Equal([]byte("other")))
+			})
+
+			It("does nothing when the child is promoted again", func() {
+				Expect(fsDriver.PromoteVolume(childPath)).To(Succeed())
+				Expect(ioutil.ReadFile(filepath.Join(childPath, "child-file"))).To(Equal([]byte("child")))
+			})
+
+			It("leaves the child to be destroyed along with its own layer", func() {
+				Expect(fsDriver.DestroyVolume(childPath)).To(Succeed())
+
+				Expect(childPath).NotTo(BeADirectory())
+				Expect(filepath.Join(tempDir, "overlays", "child-handle")).NotTo(BeADirectory())
+			})
+		})
+	})
+
+	Describe("PromoteVolume", func() {
+		It("leaves a volume that is not a copy-on-write layer as it is", func() {
+			Expect(fsDriver.PromoteVolume(parentPath)).To(Succeed())
+			Expect(ioutil.ReadFile(filepath.Join(parentPath, "parent-file"))).To(Equal([]byte("parent")))
+		})
+	})
+
+	Describe("EnsureMounted", func() {
+		It("leaves a mounted volume as it is", func() {
+			mounted, err := fsDriver.EnsureMounted(parentPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mounted).To(BeFalse())
+		})
+
+		Context("when the volume is reached through a symlink", func() {
+			var linkedPath string
+
+			BeforeEach(func() {
+				Expect(os.Symlink(liveDir, filepath.Join(tempDir, "linked-live"))).To(Succeed())
+				linkedPath = filepath.Join(tempDir, "linked-live", "parent-handle", "volume")
+			})
+
+			It("sees that it is mounted instead of mounting it again", func() {
+				mounted, err := fsDriver.EnsureMounted(linkedPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounted).To(BeFalse())
+
+				mounted, err = fsDriver.EnsureMounted(linkedPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounted).To(BeFalse())
+			})
+		})
+
+		It("mounts a copy-on-write layer over its parent again once it is unmounted", func() {
+			childPath := volumePath("child-handle", "parent-handle")
+			Expect(fsDriver.CreateCopyOnWriteLayer(childPath, parentPath)).To(Succeed())
+			Expect(syscall.Unmount(childPath, 0)).To(Succeed())
+
+			mounted, err := fsDriver.EnsureMounted(childPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mounted).To(BeTrue())
+
+			Expect(ioutil.ReadFile(filepath.Join(childPath, "parent-file"))).To(Equal([]byte("parent")))
+
+			Expect(fsDriver.DestroyVolume(childPath)).To(Succeed())
+		})
+	})
+
+	Describe("DestroyVolume", func() {
+		It("removes the volume and its layer dir", func() {
+			otherPath := volumePath("other-handle", "")
+			Expect(fsDriver.CreateVolume(otherPath)).To(Succeed())
+
+			Expect(fsDriver.DestroyVolume(otherPath)).To(Succeed())
+
+			Expect(otherPath).NotTo(BeADirectory())
+			Expect(filepath.Join(tempDir, "overlays", "other-handle")).NotTo(BeADirectory())
+		})
+	})
+})
@@ -0,0 +1,190 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+var ErrTmpfsLayers = errors.New("tmpfs volumes cannot have copy-on-write layers")
+
+// TmpfsDriver keeps each volume's data in a tmpfs of its own, so that it is
+// held in memory and gone once the volume is destroyed. A volume's quota is
+// the size of its tmpfs.
+//
+// The size is also written next to the volume's data, for the tmpfs to be
+// mounted again at that size, empty, once the host restarts.
+type TmpfsDriver struct {
+	// StatsCacheTTL is how long the file count walking a volume found is
+	// used for its stats before it is walked again, unless it is forgotten
+	// first as the volume is written to. 0 walks it each time.
+	StatsCacheTTL time.Duration
+
+	usage usageCache
+}
+
+func (driver *TmpfsDriver) Name() string {
+	return "tmpfs"
+}
+
+func (driver *TmpfsDriver) CreateVolume(path string) error {
+	err := os.Mkdir(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = syscall.Mount("tmpfs", path, "tmpfs", 0, "mode=0755")
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+func (driver *TmpfsDriver) DestroyVolume(path string) error {
+	mounted, err := isMountPoint(path)
+	if err != nil {
+		return err
+	}
+
+	if mounted {
+		err = syscall.Unmount(path, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.RemoveAll(driver.sizeFile(path))
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+
+	driver.usage.Forget(path)
+
+	return nil
+}
+
+func (driver *TmpfsDriver) CreateCopyOnWriteLayer(path string, parent string) error {
+	return ErrTmpfsLayers
+}
+
+// GetVolumeStats returns what the volume's tmpfs has in use, which counts
+// against its size, rather than what its files would take up on disk. Both
+// it and what is free are the tmpfs's own, so they are exact. Only its file
+// count is walked for, and cached as for StatsCacheTTL.
+func (driver *TmpfsDriver) GetVolumeStats(path string) (volume.DriverStats, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	_, count, err := driver.usage.Walk(path, driver.StatsCacheTTL)
+	if err != nil {
+		return volume.DriverStats{}, err
+	}
+
+	return volume.DriverStats{
+		UsedBytes: tmpfsUsed(stat),
+		FileCount: count,
+		FreeBytes: uint64(stat.Bavail) * uint64(stat.Bsize),
+		Exact:     true,
+	}, nil
+}
+
+func (driver *TmpfsDriver) GetVolumeSize(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return tmpfsUsed(stat), nil
+}
+
+func tmpfsUsed(stat syscall.Statfs_t) int64 {
+	return int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize)
+}
+
+// SetVolumeQuota resizes the volume's tmpfs. It fails if the tmpfs already
+// holds more than the new size.
+func (driver *TmpfsDriver) SetVolumeQuota(path string, sizeInBytes int64) error {
+	err := syscall.Mount("", path, "", syscall.MS_REMOUNT, fmt.Sprintf("size=%d", sizeInBytes))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(driver.sizeFile(path), []byte(strconv.FormatInt(sizeInBytes, 10)), 0644)
+}
+
+func (driver *TmpfsDriver) GetVolumeQuota(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Blocks) * int64(stat.Bsize), nil
+}
+
+// MakeReadOnly remounts the volume's tmpfs read-only, keeping its size.
+func (driver *TmpfsDriver) MakeReadOnly(path string) error {
+	return remountReadOnly(path)
+}
+
+func (driver *TmpfsDriver) MountOptions() []string {
+	return mountOptionNames()
+}
+
+func (driver *TmpfsDriver) SetMountOptions(path string, options []string) error {
+	return remountWithOptions(path, options)
+}
+
+// EnsureMounted mounts an empty tmpfs at the volume's size again, its data
+// having been lost along with the previous one.
+func (driver *TmpfsDriver) EnsureMounted(path string) (bool, error) {
+	mounted, err := isMountPoint(path)
+	if err != nil {
+		return false, err
+	}
+
+	if mounted {
+		return false, nil
+	}
+
+	opts := "mode=0755"
+
+	size, err := ioutil.ReadFile(driver.sizeFile(path))
+	if err == nil {
+		opts += ",size=" + strings.TrimSpace(string(size))
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	err = syscall.Mount("tmpfs", path, "tmpfs", 0, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sizeFile is kept in the volume's dir, so that it moves along with the
+// volume when it is renamed.
+func (driver *TmpfsDriver) sizeFile(path string) string {
+	return filepath.Join(filepath.Dir(path), "tmpfs-size")
+}
@@ -0,0 +1,131 @@
+package driver_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+var _ = Describe("Tmpfs", func() {
+	var (
+		tempDir    string
+		volumePath string
+		fsDriver   *driver.TmpfsDriver
+	)
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("needs to mount a tmpfs")
+		}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "baggageclaim_tmpfs_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		volumePath = filepath.Join(tempDir, "volume")
+
+		fsDriver = &driver.TmpfsDriver{}
+
+		err = fsDriver.CreateVolume(volumePath)
+		if err != nil {
+			os.RemoveAll(tempDir)
+			Skip("cannot mount a tmpfs: " + err.Error())
+		}
+	})
+
+	AfterEach(func() {
+		if tempDir == "" {
+			return
+		}
+
+		Expect(fsDriver.DestroyVolume(volumePath)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("mounts a tmpfs for the volume", func() {
+		var stat syscall.Statfs_t
+		Expect(syscall.Statfs(volumePath, &stat)).To(Succeed())
+		Expect(stat.Type).To(BeEquivalentTo(0x01021994))
+	})
+
+	It("limits the volume to its quota, and reports what it uses against it", func() {
+		Expect(fsDriver.SetVolumeQuota(volumePath, 1024*1024)).To(Succeed())
+
+		quota, err := fsDriver.GetVolumeQuota(volumePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota).To(Equal(int64(1024 * 1024)))
+
+		Expect(ioutil.WriteFile(filepath.Join(volumePath, "some-file"), make([]byte, 512*1024), 0644)).To(Succeed())
+
+		stats, err := fsDriver.GetVolumeStats(volumePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.UsedBytes).To(BeNumerically(">=", 512*1024))
+		Expect(stats.FileCount).To(Equal(int64(1)))
+		Expect(stats.FreeBytes).To(BeNumerically("<=", 512*1024))
+		Expect(stats.Exact).To(BeTrue())
+
+		err = ioutil.WriteFile(filepath.Join(volumePath, "other-file"), make([]byte, 1024*1024), 0644)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("mounts the volume again, empty and at its size, once its mount is gone", func() {
+		Expect(fsDriver.SetVolumeQuota(volumePath, 1024*1024)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(volumePath, "some-file"), []byte("some-content"), 0644)).To(Succeed())
+
+		remounted, err := fsDriver.EnsureMounted(volumePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remounted).To(BeFalse())
+
+		Expect(syscall.Unmount(volumePath, 0)).To(Succeed())
+
+		remounted, err = fsDriver.EnsureMounted(volumePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remounted).To(BeTrue())
+
+		Expect(filepath.Join(volumePath, "some-file")).NotTo(BeAnExistingFile())
+
+		quota, err := fsDriver.GetVolumeQuota(volumePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota).To(Equal(int64(1024 * 1024)))
+	})
+
+	It("gives the volume mount options of its own, keeping them when made read-only", func() {
+		Expect(fsDriver.MountOptions()).To(ContainElement("noexec"))
+
+		Expect(fsDriver.SetMountOptions(volumePath, []string{"noexec", "nodev"})).To(Succeed())
+
+		var stat syscall.Statfs_t
+		Expect(syscall.Statfs(volumePath, &stat)).To(Succeed())
+		Expect(stat.Flags & 0x8).NotTo(BeZero())
+		Expect(stat.Flags & 0x4).NotTo(BeZero())
+
+		Expect(fsDriver.MakeReadOnly(volumePath)).To(Succeed())
+
+		Expect(syscall.Statfs(volumePath, &stat)).To(Succeed())
+		Expect(stat.Flags & 0x1).NotTo(BeZero())
+		Expect(stat.Flags & 0x8).NotTo(BeZero())
+
+		Expect(fsDriver.SetMountOptions(volumePath, []string{"nosuid"})).To(Succeed())
+
+		Expect(syscall.Statfs(volumePath, &stat)).To(Succeed())
+		Expect(stat.Flags & 0x1).NotTo(BeZero())
+		Expect(stat.Flags & 0x8).To(BeZero())
+		Expect(stat.Flags & 0x2).NotTo(BeZero())
+	})
+
+	It("removes the volume and its data when destroyed", func() {
+		Expect(fsDriver.SetVolumeQuota(volumePath, 1024*1024)).To(Succeed())
+
+		Expect(fsDriver.DestroyVolume(volumePath)).To(Succeed())
+		Expect(volumePath).NotTo(BeADirectory())
+		Expect(filepath.Join(tempDir, "tmpfs-size")).NotTo(BeAnExistingFile())
+
+		Expect(fsDriver.CreateVolume(volumePath)).To(Succeed())
+	})
+})
@@ -0,0 +1,105 @@
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// walkUsage computes the disk usage in bytes of everything below root, along
+// with the number of entries (not counting root itself), in a single walk.
+// Entries that disappear while walking, e.g. because of a concurrent
+// stream-in, are skipped rather than failing the walk. Hard-linked files are
+// only counted once.
+func walkUsage(root string) (int64, int64, error) {
+	var size, count int64
+
+	seen := map[fileID]bool{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path != root && os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if path != root {
+			count++
+		}
+
+		usage, id, linked := diskUsage(info)
+		if linked {
+			if seen[id] {
+				return nil
+			}
+
+			seen[id] = true
+		}
+
+		size += usage
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return size, count, nil
+}
+
+// usageCache keeps what walking volumes found, for their stats to be polled
+// without walking large volumes each time. Its zero value is ready to use.
+type usageCache struct {
+	lock    sync.Mutex
+	entries map[string]cachedUsage
+}
+
+type cachedUsage struct {
+	size     int64
+	count    int64
+	walkedAt time.Time
+}
+
+// Walk returns the usage of everything below root as walkUsage does, or as
+// it did when last walked if that was less than ttl ago. A ttl of 0 always
+// walks.
+func (cache *usageCache) Walk(root string, ttl time.Duration) (int64, int64, error) {
+	now := time.Now()
+
+	cache.lock.Lock()
+	cached, found := cache.entries[root]
+	cache.lock.Unlock()
+
+	if found && now.Sub(cached.walkedAt) < ttl {
+		return cached.size, cached.count, nil
+	}
+
+	size, count, err := walkUsage(root)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if ttl <= 0 {
+		return size, count, nil
+	}
+
+	cache.lock.Lock()
+	if cache.entries == nil {
+		cache.entries = map[string]cachedUsage{}
+	}
+
+	cache.entries[root] = cachedUsage{size: size, count: count, walkedAt: now}
+	cache.lock.Unlock()
+
+	return size, count, nil
+}
+
+// Forget drops what was found for root, e.g. once its volume is destroyed.
+func (cache *usageCache) Forget(root string) {
+	cache.lock.Lock()
+	delete(cache.entries, root)
+	cache.lock.Unlock()
+}
@@ -0,0 +1,36 @@
+// +build !windows
+
+package driver
+
+import (
+	"os"
+	"syscall"
+)
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func diskUsage(info os.FileInfo) (int64, fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size(), fileID{}, false
+	}
+
+	id := fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}
+
+	return int64(stat.Blocks) * 512, id, !info.IsDir() && stat.Nlink > 1
+}
+
+// freeBytes returns what is free to unprivileged users on the filesystem
+// path is on.
+func freeBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -7,3 +7,7 @@ type EmptyStrategy struct{}
 func (EmptyStrategy) Materialize(logger lager.Logger, handle string, fs Filesystem) (FilesystemInitVolume, error) {
 	return fs.NewVolume(handle)
 }
+
+func (EmptyStrategy) Type() string {
+	return StrategyEmpty
+}
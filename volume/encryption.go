@@ -0,0 +1,65 @@
+package volume
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// MinMasterKeySize is the fewest bytes a master key may have.
+const MinMasterKeySize = 32
+
+// volumeKeySaltSize is the size of the salt each encrypted volume's key is
+// derived with.
+const volumeKeySaltSize = 32
+
+var ErrMasterKeyTooShort = fmt.Errorf("master key must be at least %d bytes", MinMasterKeySize)
+var ErrStrategyNotEncryptable = errors.New("volumes created by the strategy cannot be encrypted")
+
+// MasterKey is the key the server holds that each encrypted volume's key is
+// derived from, along with the volume's handle and a random salt of its own.
+// The salt is kept in the volume's metadata and overwritten when the volume
+// is destroyed, so that its key can't be derived again even with the master
+// key, and what is left of its data on disk can't be read.
+type MasterKey []byte
+
+// LoadMasterKey reads the master key from the file, taking its contents as
+// they are.
+func LoadMasterKey(path string) (MasterKey, error) {
+	key, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) < MinMasterKeySize {
+		return nil, ErrMasterKeyTooShort
+	}
+
+	return MasterKey(key), nil
+}
+
+// volumeKey derives the key of the volume that was created with the handle
+// and salt, sized for AES-256-XTS. The handle is the one the volume was
+// created with, as the key stays the same when it is renamed.
+func (key MasterKey) volumeKey(handle string, salt []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write([]byte("baggageclaim volume key\x00"))
+	mac.Write([]byte(handle))
+	mac.Write([]byte{0})
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
+
+// encryptingFilesystem has strategies create their volume encrypted. Those
+// that create it some other way than by NewVolume, e.g. as a copy-on-write
+// layer, leave it as they would otherwise, which is for the repository to
+// check.
+type encryptingFilesystem struct {
+	Filesystem
+}
+
+func (fs encryptingFilesystem) NewVolume(handle string) (FilesystemInitVolume, error) {
+	return fs.NewEncryptedVolume(handle)
+}
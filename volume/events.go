@@ -0,0 +1,98 @@
+package volume
+
+import (
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventCreated         EventType = "created"
+	EventDestroyed       EventType = "destroyed"
+	EventExpired         EventType = "expired"
+	EventPropertyChanged EventType = "property-changed"
+	EventRenamed         EventType = "renamed"
+	EventRestored        EventType = "restored"
+)
+
+// Event is a transition in the lifecycle of a volume.
+type Event struct {
+	Type   EventType
+	Handle string
+
+	// PreviousHandle is the handle the volume had before, for renamed
+	// events.
+	PreviousHandle string
+
+	// Properties are the volume's properties after the transition; those it
+	// had when destroyed for destroyed and expired events.
+	Properties Properties
+
+	// Reason is why the volume was destroyed, for destroyed and expired
+	// events.
+	Reason DestroyReason
+
+	At time.Time
+}
+
+// EventSink is told about every lifecycle transition of the repository's
+// volumes, while the volume's lock is held. It must not block.
+type EventSink interface {
+	Publish(Event)
+}
+
+// NoopEventSink discards every event.
+type NoopEventSink struct{}
+
+func (NoopEventSink) Publish(Event) {}
+
+// EventHub fans the events published to it out to its subscribers.
+type EventHub struct {
+	subscribersL sync.Mutex
+	subscribers  map[chan Event]struct{}
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Publish hands the event to every subscriber without waiting on any. A
+// subscriber with buffer events already waiting is unsubscribed instead, so
+// that it finds out it has missed some.
+func (hub *EventHub) Publish(event Event) {
+	hub.subscribersL.Lock()
+	defer hub.subscribersL.Unlock()
+
+	for events := range hub.subscribers {
+		select {
+		case events <- event:
+		default:
+			delete(hub.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Subscribe returns a channel of the events published from now on, and a
+// function to stop receiving them. The channel is closed once unsubscribed,
+// whether by that function or for falling behind.
+func (hub *EventHub) Subscribe(buffer int) (<-chan Event, func()) {
+	events := make(chan Event, buffer)
+
+	hub.subscribersL.Lock()
+	hub.subscribers[events] = struct{}{}
+	hub.subscribersL.Unlock()
+
+	return events, func() {
+		hub.subscribersL.Lock()
+		defer hub.subscribersL.Unlock()
+
+		if _, found := hub.subscribers[events]; found {
+			delete(hub.subscribers, events)
+			close(events)
+		}
+	}
+}
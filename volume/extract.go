@@ -0,0 +1,530 @@
+package volume
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnsafeTarEntry = errors.New("tar entry would be extracted outside of the destination")
+var ErrCyclicTarLinks = errors.New("tar hard links refer to each other in a cycle")
+
+// files up to this size are read into memory and handed to a worker; larger
+// ones are written by the reader, straight from the stream
+const maxBufferedTarEntry = 1024 * 1024
+
+// sparseBlockSize is the size of the runs of zeros in a sparse file that are
+// left as holes rather than written.
+const sparseBlockSize = 4096
+
+var zeroBlock = make([]byte, sparseBlockSize)
+
+type tarEntry struct {
+	path   string
+	target string
+	header *tar.Header
+}
+
+type tarExtractor struct {
+	dest string
+
+	// each directory's files go to the same worker, as creating files in a
+	// directory takes a lock on it
+	jobs    []chan tarFileJob
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+
+	errL sync.Mutex
+	err  error
+
+	// a later entry for a path must not race the write of an earlier one
+	seen map[string]bool
+
+	// links are made once every file has been written, so that nothing in
+	// the archive can be written through a symlink from the same archive
+	links []tarEntry
+
+	dirs []tarEntry
+}
+
+type tarFileJob struct {
+	path     string
+	header   *tar.Header
+	contents []byte
+}
+
+// extractConcurrently unpacks the tar stream into dest, writing the contents
+// of regular files on a pool of workers. Entries are still read in order,
+// and everything else is done by the reader: parent directories are created
+// before the files in them, links once every file is in place, and
+// directory modes and times last, as writing into a directory changes them.
+// Hard links may come before what they link to, even when that is another
+// hard link.
+func extractConcurrently(stream io.Reader, dest string, concurrency int) (bool, error) {
+	extractor := &tarExtractor{
+		dest: filepath.Clean(dest),
+		jobs: make([]chan tarFileJob, concurrency),
+		seen: map[string]bool{},
+	}
+
+	for i := range extractor.jobs {
+		extractor.jobs[i] = make(chan tarFileJob, 1)
+
+		extractor.workers.Add(1)
+		go extractor.work(extractor.jobs[i])
+	}
+
+	badStream, err := extractor.read(tar.NewReader(stream))
+
+	for _, jobs := range extractor.jobs {
+		close(jobs)
+	}
+
+	extractor.workers.Wait()
+
+	if err != nil {
+		return badStream, err
+	}
+
+	err = extractor.firstError()
+	if err != nil {
+		return false, err
+	}
+
+	err = extractor.finish()
+	if err == ErrUnsafeTarEntry || err == ErrCyclicTarLinks {
+		return true, err
+	}
+
+	return false, err
+}
+
+func (extractor *tarExtractor) work(jobs <-chan tarFileJob) {
+	defer extractor.workers.Done()
+
+	for job := range jobs {
+		// once something has failed the stream is abandoned anyway
+		if extractor.firstError() == nil {
+			extractor.fail(writeTarFile(job.path, job.header, bytes.NewReader(job.contents)))
+		}
+
+		extractor.pending.Done()
+	}
+}
+
+func (extractor *tarExtractor) read(tarReader *tar.Reader) (bool, error) {
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+
+		if err != nil {
+			return true, err
+		}
+
+		err = extractor.firstError()
+		if err != nil {
+			return false, err
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		path, err := extractor.resolve(header.Name)
+		if err != nil {
+			return true, err
+		}
+
+		if extractor.seen[path] {
+			extractor.pending.Wait()
+			extractor.dropDeferred(path)
+		}
+
+		extractor.seen[path] = true
+
+		if header.Typeflag != tar.TypeDir {
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+			if err != nil {
+				return false, err
+			}
+
+			extractor.dirs = append(extractor.dirs, tarEntry{path: path, header: header})
+
+		case tar.TypeReg, tar.TypeRegA, tar.TypeGNUSparse:
+			if header.Size > maxBufferedTarEntry {
+				contents := &errorTrackingReader{Reader: tarReader}
+
+				err = writeTarFile(path, header, contents)
+				if contents.err != nil {
+					return true, contents.err
+				}
+
+				if err != nil {
+					return false, err
+				}
+
+				continue
+			}
+
+			contents, err := ioutil.ReadAll(tarReader)
+			if err != nil {
+				return true, err
+			}
+
+			extractor.pending.Add(1)
+			extractor.worker(filepath.Dir(path)) <- tarFileJob{path: path, header: header, contents: contents}
+
+		case tar.TypeSymlink:
+			extractor.links = append(extractor.links, tarEntry{path: path, target: header.Linkname, header: header})
+
+		case tar.TypeLink:
+			target, err := extractor.resolve(header.Linkname)
+			if err != nil {
+				return true, err
+			}
+
+			extractor.links = append(extractor.links, tarEntry{path: path, target: target, header: header})
+
+		default:
+			err = writeTarNode(path, header)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+func (extractor *tarExtractor) finish() error {
+	// symlinks first, so that hard links to them have something to link to
+	hardLinks := []tarEntry{}
+	for _, link := range extractor.links {
+		if link.header.Typeflag == tar.TypeLink {
+			hardLinks = append(hardLinks, link)
+			continue
+		}
+
+		err := extractor.writeLink(link)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := extractor.writeHardLinks(hardLinks)
+	if err != nil {
+		return err
+	}
+
+	// innermost first, as a directory's mode may not allow changing the
+	// ones inside it
+	for i := len(extractor.dirs) - 1; i >= 0; i-- {
+		err := applyTarDirHeader(extractor.dirs[i].path, extractor.dirs[i].header)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHardLinks makes each hard link once its target is in place, which for
+// a link to another link means after that one, wherever it is in the stream.
+func (extractor *tarExtractor) writeHardLinks(links []tarEntry) error {
+	unwritten := map[string]bool{}
+	for _, link := range links {
+		unwritten[link.path] = true
+	}
+
+	for len(links) > 0 {
+		waiting := links[:0]
+		for _, link := range links {
+			if unwritten[link.target] {
+				waiting = append(waiting, link)
+				continue
+			}
+
+			err := extractor.writeLink(link)
+			if err != nil {
+				return err
+			}
+
+			delete(unwritten, link.path)
+		}
+
+		if len(waiting) == len(links) {
+			return ErrCyclicTarLinks
+		}
+
+		links = waiting
+	}
+
+	return nil
+}
+
+// writeLink makes the link unless it, or the target of a hard link, is
+// reached through a symlink out of the destination, which the symlinks made
+// just before may have introduced.
+func (extractor *tarExtractor) writeLink(link tarEntry) error {
+	paths := []string{link.path}
+	if link.header.Typeflag == tar.TypeLink {
+		paths = append(paths, link.target)
+	}
+
+	for _, path := range paths {
+		safe, err := resolvesWithin(extractor.dest, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		if !safe {
+			return ErrUnsafeTarEntry
+		}
+	}
+
+	return writeTarLink(link)
+}
+
+func (extractor *tarExtractor) worker(dir string) chan<- tarFileJob {
+	hash := fnv.New32a()
+	hash.Write([]byte(dir))
+
+	return extractor.jobs[hash.Sum32()%uint32(len(extractor.jobs))]
+}
+
+func (extractor *tarExtractor) resolve(name string) (string, error) {
+	path := filepath.Join(extractor.dest, name)
+	if !within(extractor.dest, path) {
+		return "", ErrUnsafeTarEntry
+	}
+
+	return path, nil
+}
+
+// dropDeferred forgets the link or directory an earlier entry for the path
+// left to be made or finished, as a later entry replaces it.
+func (extractor *tarExtractor) dropDeferred(path string) {
+	links := extractor.links[:0]
+	for _, link := range extractor.links {
+		if link.path != path {
+			links = append(links, link)
+		}
+	}
+
+	extractor.links = links
+
+	dirs := extractor.dirs[:0]
+	for _, dir := range extractor.dirs {
+		if dir.path != path {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	extractor.dirs = dirs
+}
+
+func (extractor *tarExtractor) fail(err error) {
+	if err == nil {
+		return
+	}
+
+	extractor.errL.Lock()
+	if extractor.err == nil {
+		extractor.err = err
+	}
+	extractor.errL.Unlock()
+}
+
+func (extractor *tarExtractor) firstError() error {
+	extractor.errL.Lock()
+	defer extractor.errL.Unlock()
+
+	return extractor.err
+}
+
+func writeTarFile(path string, header *tar.Header, contents io.Reader) error {
+	// replace rather than write into an existing file, which may be a hard
+	// link shared with another path
+	err := removeExisting(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if isSparseTarEntry(header) {
+		err = writeSparse(file, contents)
+	} else {
+		_, err = io.Copy(file, contents)
+	}
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return applyTarHeader(path, header)
+}
+
+// isSparseTarEntry returns whether the entry is a sparse file, in either the
+// GNU format or the PAX one. Its holes read as zeros.
+func isSparseTarEntry(header *tar.Header) bool {
+	if header.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+
+	for key := range header.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeSparse writes the contents to the file leaving a hole for each block
+// of zeros, and truncates it to the size of the contents so that a hole at
+// the end is kept as well.
+func writeSparse(file *os.File, contents io.Reader) error {
+	buffer := make([]byte, 16*sparseBlockSize)
+
+	var offset int64
+	for {
+		n, err := io.ReadFull(contents, buffer)
+
+		for start := 0; start < n; start += sparseBlockSize {
+			end := start + sparseBlockSize
+			if end > n {
+				end = n
+			}
+
+			block := buffer[start:end]
+			if !bytes.Equal(block, zeroBlock[:len(block)]) {
+				_, writeErr := file.WriteAt(block, offset+int64(start))
+				if writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+
+		offset += int64(n)
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return file.Truncate(offset)
+}
+
+func writeTarLink(link tarEntry) error {
+	err := removeExisting(link.path)
+	if err != nil {
+		return err
+	}
+
+	if link.header.Typeflag == tar.TypeLink {
+		return os.Link(link.target, link.path)
+	}
+
+	err = os.Symlink(link.target, link.path)
+	if err != nil {
+		return err
+	}
+
+	if os.Geteuid() == 0 {
+		return os.Lchown(link.path, link.header.Uid, link.header.Gid)
+	}
+
+	return nil
+}
+
+// applyTarHeader sets the owner, mode, and times recorded for the entry. The
+// owner is only kept when extracting as root, as tar does.
+func applyTarHeader(path string, header *tar.Header) error {
+	if os.Geteuid() == 0 {
+		err := os.Lchown(path, header.Uid, header.Gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	// chmod after chown, which clears setuid and setgid
+	err := os.Chmod(path, tarHeaderMode(header))
+	if err != nil {
+		return err
+	}
+
+	accessTime, modTime := tarHeaderTimes(header)
+
+	return os.Chtimes(path, accessTime, modTime)
+}
+
+func tarHeaderMode(header *tar.Header) os.FileMode {
+	return header.FileInfo().Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+}
+
+// tarHeaderTimes are the access and modification times recorded for the
+// entry; an entry without an access time gets its modification time.
+func tarHeaderTimes(header *tar.Header) (time.Time, time.Time) {
+	accessTime := header.AccessTime
+	if accessTime.IsZero() {
+		accessTime = header.ModTime
+	}
+
+	return accessTime, header.ModTime
+}
+
+func removeExisting(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// errorTrackingReader remembers read errors, telling a broken stream apart
+// from a failure to write what was read.
+type errorTrackingReader struct {
+	io.Reader
+
+	err error
+}
+
+func (reader *errorTrackingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		reader.err = err
+	}
+
+	return n, err
+}
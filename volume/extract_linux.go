@@ -0,0 +1,87 @@
+package volume
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func writeTarNode(path string, header *tar.Header) error {
+	var mode uint32
+	switch header.Typeflag {
+	case tar.TypeChar:
+		mode = syscall.S_IFCHR
+	case tar.TypeBlock:
+		mode = syscall.S_IFBLK
+	case tar.TypeFifo:
+		mode = syscall.S_IFIFO
+	default:
+		return fmt.Errorf("unsupported tar entry type %q for %s", header.Typeflag, header.Name)
+	}
+
+	err := removeExisting(path)
+	if err != nil {
+		return err
+	}
+
+	err = syscall.Mknod(path, mode|uint32(header.Mode&07777), deviceNumber(header.Devmajor, header.Devminor))
+	if err != nil {
+		return err
+	}
+
+	return applyTarHeader(path, header)
+}
+
+// deviceNumber encodes a device number the way the kernel's makedev does.
+func deviceNumber(major int64, minor int64) int {
+	return int((minor & 0xff) | ((major & 0xfff) << 8) | ((minor &^ 0xff) << 12) | ((major &^ 0xfff) << 32))
+}
+
+// applyTarDirHeader sets what the entry records on the directory at the
+// path, unless something else has been put there since, which is left
+// alone. Nothing is followed: if a symlink replaced the directory, what it
+// points to may be anywhere.
+func applyTarDirHeader(path string, header *tar.Header) error {
+	// chmod through a descriptor, as fchmodat can't be kept from following
+	// a symlink
+	dir, err := os.OpenFile(path, os.O_RDONLY|syscall.O_DIRECTORY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		if os.IsNotExist(err) || errors.Is(err, syscall.ELOOP) || errors.Is(err, syscall.ENOTDIR) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer dir.Close()
+
+	if os.Geteuid() == 0 {
+		err := dir.Chown(header.Uid, header.Gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dir.Chmod(tarHeaderMode(header))
+	if err != nil {
+		return err
+	}
+
+	accessTime, modTime := tarHeaderTimes(header)
+
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, []unix.Timespec{timespec(accessTime), timespec(modTime)}, unix.AT_SYMLINK_NOFOLLOW)
+}
+
+// timespec leaves the time as it is for a zero time, as os.Chtimes does.
+func timespec(t time.Time) unix.Timespec {
+	if t.IsZero() {
+		return unix.Timespec{Nsec: unix.UTIME_OMIT}
+	}
+
+	return unix.NsecToTimespec(t.UnixNano())
+}
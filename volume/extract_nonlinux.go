@@ -0,0 +1,33 @@
+// +build !linux
+
+package volume
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+)
+
+func writeTarNode(path string, header *tar.Header) error {
+	return fmt.Errorf("unsupported tar entry type %q for %s", header.Typeflag, header.Name)
+}
+
+// applyTarDirHeader sets what the entry records on the directory at the
+// path, unless something else has been put there since, which is left
+// alone.
+func applyTarDirHeader(path string, header *tar.Header) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	return applyTarHeader(path, header)
+}
@@ -1,18 +1,65 @@
 package volume
 
 import (
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/concourse/baggageclaim"
 )
 
+var ErrSnapshotsNotSupported = errors.New("driver does not support snapshots")
+var ErrQuotasNotSupported = errors.New("driver does not support volume sizes")
+var ErrReadOnlyNotSupported = errors.New("driver does not support read-only volumes")
+var ErrPromoteWithChildren = errors.New("driver cannot promote a volume with copy-on-write children of its own")
+var ErrTmpfsNotSupported = errors.New("tmpfs volumes are not enabled")
+var ErrCopyOnWriteOfTmpfs = errors.New("a tmpfs volume cannot be the parent of a copy-on-write volume")
+var ErrEncryptionNotEnabled = errors.New("encrypted volumes are not enabled")
+var ErrEncryptionNotSupported = errors.New("driver does not support encrypted volumes")
+
 //go:generate counterfeiter . Filesystem
 
 type Filesystem interface {
 	NewVolume(string) (FilesystemInitVolume, error)
+
+	// NewTmpfsVolume creates a volume whose data is held in memory by the
+	// tmpfs driver, rather than by the filesystem's driver. It returns
+	// ErrTmpfsNotSupported if there is no tmpfs driver.
+	NewTmpfsVolume(string) (FilesystemInitVolume, error)
+
+	// NewEncryptedVolume creates a volume whose data is encrypted with a key
+	// of its own. It returns ErrEncryptionNotEnabled if there is no master
+	// key, and ErrEncryptionNotSupported if the driver cannot encrypt.
+	NewEncryptedVolume(string) (FilesystemInitVolume, error)
+
+	// LookupVolume and ListVolumes leave out deleted volumes, which are kept
+	// where they are, data and all, until they are restored or purged.
 	LookupVolume(string) (FilesystemLiveVolume, bool, error)
 	ListVolumes() ([]FilesystemLiveVolume, error)
+
+	// LookupDeletedVolume and ListDeletedVolumes find only deleted volumes.
+	LookupDeletedVolume(string) (FilesystemLiveVolume, bool, error)
+	ListDeletedVolumes() ([]FilesystemLiveVolume, error)
+
+	// FreeInodes is how many more inodes may be created on the volumes
+	// filesystem, and whether there is a limit to them at all: btrfs, for
+	// one, has no fixed number of inodes, and makes them as it needs them.
+	FreeInodes() (uint64, bool, error)
+
+	// FreeBytes is how much more may be written to the volumes filesystem.
+	FreeBytes() (uint64, error)
+
+	// CheckMount returns an error if the volumes directory is gone, or is not
+	// mounted the way the driver needs it to be.
+	CheckMount() error
+
+	// ProbeWrite writes a small file to the volumes directory, syncs it, and
+	// removes it again.
+	ProbeWrite() error
 }
 
 //go:generate counterfeiter . FilesystemVolume
@@ -31,10 +78,57 @@ type FilesystemVolume interface {
 
 	LoadTTL() (TTL, time.Time, error)
 	StoreTTL(TTL) (time.Time, error)
+	StoreExpiry(TTL, time.Time) error
+
+	// LoadRenewTTLOnAccess returns whether the volume's TTL starts over each
+	// time the volume is accessed.
+	LoadRenewTTLOnAccess() (bool, error)
+	StoreRenewTTLOnAccess() error
 
 	LoadPrivileged() (bool, error)
 	StorePrivileged(bool) error
 
+	LoadCommitted() (time.Time, bool, error)
+	StoreCommitted(bool) (time.Time, error)
+
+	LoadLastAccessed() (time.Time, error)
+	StoreLastAccessed() (time.Time, error)
+
+	LoadCreated() (time.Time, string, error)
+	StoreCreated(string) (time.Time, error)
+
+	LoadModified() (time.Time, error)
+	StoreModified() (time.Time, error)
+
+	LoadDigest() (string, error)
+	StoreDigest(string) error
+
+	LoadStreamInKeys() (map[string]time.Time, error)
+	StoreStreamInKeys(map[string]time.Time) error
+
+	// LoadCreateKey returns the idempotency key the volume was created with
+	// and the fingerprint of its create, or nothing if it had none.
+	LoadCreateKey() (string, string, error)
+	StoreCreateKey(key string, fingerprint string) error
+
+	LoadReleased() (DestroyOptions, bool, error)
+	StoreReleased(DestroyOptions) error
+
+	LoadReadOnly() (bool, error)
+
+	// LoadMountOptions returns the mount options the volume was given, if any.
+	LoadMountOptions() ([]string, error)
+
+	// LoadEncrypted returns whether the volume's data is encrypted. A view's
+	// is if its base's is.
+	LoadEncrypted() (bool, error)
+
+	// LoadBacking returns the name of the driver that created the volume and
+	// the type of filesystem its data was on then. For volumes created
+	// before they were recorded, they are the current driver and the type
+	// of filesystem the data is on now.
+	LoadBacking() (string, string, error)
+
 	Parent() (FilesystemLiveVolume, bool, error)
 
 	Destroy() error
@@ -45,6 +139,19 @@ type FilesystemVolume interface {
 type FilesystemInitVolume interface {
 	FilesystemVolume
 
+	// SetQuota limits how much the volume may hold.
+	SetQuota(sizeInBytes int64) error
+
+	// MakeReadOnly keeps the volume's data from being written to through
+	// its path, not just through the API. A view's data is its base's, so
+	// a view is only recorded as read-only.
+	MakeReadOnly() error
+
+	// SetMountOptions gives the volume the mount options, which must be out
+	// of those its driver allows. It returns a MountOptionsError listing
+	// those it cannot be given.
+	SetMountOptions(options []string) error
+
 	Initialize() (FilesystemLiveVolume, error)
 }
 
@@ -53,29 +160,97 @@ type FilesystemInitVolume interface {
 type FilesystemLiveVolume interface {
 	FilesystemVolume
 
-	SizeInBytes() (int64, error)
+	Stats() (VolumeStats, error)
+
+	// LoadDeleted returns the destroy the volume is deleted after and when
+	// it was, if it is. StoreDeleted deletes the volume as far as the rest of
+	// the filesystem's lookups are concerned, and ClearDeleted undoes it.
+	LoadDeleted() (DestroyOptions, time.Time, bool, error)
+	StoreDeleted(DestroyOptions, time.Time) error
+	ClearDeleted() error
+
+	// Size is the SizeInBytes of the volume's stats, found as cheaply as the
+	// driver can.
+	Size() (int64, error)
+
+	// Room is how much more may be written to the volume's data before it is
+	// full, by its quota or by the free space on the filesystem it is on,
+	// and whether there is a limit to it that could be found. The quota is
+	// only counted if the driver can tell how much of it is used without
+	// walking the volume.
+	Room() (uint64, bool, error)
 
 	NewSubvolume(handle string) (FilesystemInitVolume, error)
+
+	// NewView creates a volume whose data is this volume's data, shared
+	// rather than copied. The view's parent is this volume.
+	NewView(handle string) (FilesystemInitVolume, error)
+	IsView() (bool, error)
+
+	// NewClone creates a volume whose data is a copy of this volume's data,
+	// with no tie to this volume: writes to either are not seen by the other.
+	NewClone(handle string) (FilesystemInitVolume, error)
+
+	// CopyData copies what is at src to dest, either of which may be in
+	// another volume's data, as cheaply as the volume's driver can. A
+	// directory's contents are copied into dest, and anything else is copied
+	// into it by its name.
+	CopyData(src string, dest string) error
+
+	// Snapshot takes a read-only, point-in-time copy of the volume's data,
+	// returning its path and a func to release it. It returns
+	// ErrSnapshotsNotSupported if the driver cannot take one.
+	Snapshot() (string, func() error, error)
+
+	// Materialize makes sure the volume's data is in place, mounting it again
+	// if the driver's mount of it is gone and unlocking it again if it is
+	// encrypted and its key is gone. It returns whether it had to.
+	Materialize() (bool, error)
+
+	// Rename moves the volume to the handle without touching its data, and
+	// points its views and copy-on-write children at it there. If any of it
+	// fails, what was done is undone.
+	Rename(handle string) (FilesystemLiveVolume, error)
+
+	// Promote gives a copy-on-write child a copy of its parent's data of its
+	// own and forgets its parent, which can then be destroyed. Views and
+	// volumes without a parent are left alone. It returns
+	// ErrPromoteWithChildren if the driver cannot promote a volume that has
+	// copy-on-write children.
+	Promote() error
 }
 
 const (
 	initDirname = "init" // volumes being initialized
 	liveDirname = "live" // volumes accessible via API
 	deadDirname = "dead" // volumes being torn down
+
+	snapshotsDirname = "snapshots" // point-in-time copies being streamed out
 )
 
 type filesystem struct {
 	driver Driver
 
+	// tmpfsDriver backs the volumes created by NewTmpfsVolume, if any may be
+	tmpfsDriver Driver
+
+	// masterKey is what encrypted volumes' keys are derived from, if any may
+	// be created
+	masterKey MasterKey
+
+	dir     string
 	initDir string
 	liveDir string
 	deadDir string
+
+	snapshotsDir string
 }
 
-func NewFilesystem(driver Driver, parentDir string) (Filesystem, error) {
+func NewFilesystem(driver Driver, parentDir string, tmpfsDriver Driver, masterKey MasterKey) (Filesystem, error) {
 	initDir := filepath.Join(parentDir, initDirname)
 	liveDir := filepath.Join(parentDir, liveDirname)
 	deadDir := filepath.Join(parentDir, deadDirname)
+	snapshotsDir := filepath.Join(parentDir, snapshotsDirname)
 
 	err := os.MkdirAll(initDir, 0755)
 	if err != nil {
@@ -92,15 +267,70 @@ func NewFilesystem(driver Driver, parentDir string) (Filesystem, error) {
 		return nil, err
 	}
 
+	err = os.MkdirAll(snapshotsDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
 	return &filesystem{
-		driver: driver,
+		driver:      driver,
+		tmpfsDriver: tmpfsDriver,
+		masterKey:   masterKey,
 
+		dir:     parentDir,
 		initDir: initDir,
 		liveDir: liveDir,
 		deadDir: deadDir,
+
+		snapshotsDir: snapshotsDir,
 	}, nil
 }
 
+func (fs *filesystem) CheckMount() error {
+	info, err := os.Stat(fs.liveDir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.New("live volumes directory is not a directory: " + fs.liveDir)
+	}
+
+	checker, ok := fs.driver.(MountChecker)
+	if !ok {
+		return nil
+	}
+
+	return checker.CheckMount(fs.dir)
+}
+
+// the probe's contents are a block's worth, so that it fails on a full
+// filesystem rather than fitting in what is left of one
+var writeProbeContents = make([]byte, 4096)
+
+func (fs *filesystem) ProbeWrite() error {
+	probe, err := ioutil.TempFile(fs.dir, ".write-probe-")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(probe.Name())
+
+	_, err = probe.Write(writeProbeContents)
+	if err != nil {
+		probe.Close()
+		return err
+	}
+
+	err = probe.Sync()
+	if err != nil {
+		probe.Close()
+		return err
+	}
+
+	return probe.Close()
+}
+
 func (fs *filesystem) NewVolume(handle string) (FilesystemInitVolume, error) {
 	volume, err := fs.initRawVolume(handle)
 	if err != nil {
@@ -116,7 +346,89 @@ func (fs *filesystem) NewVolume(handle string) (FilesystemInitVolume, error) {
 	return volume, nil
 }
 
+func (fs *filesystem) NewTmpfsVolume(handle string) (FilesystemInitVolume, error) {
+	if fs.tmpfsDriver == nil {
+		return nil, ErrTmpfsNotSupported
+	}
+
+	volume, err := fs.initRawVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	// recorded up front, as it is what the volume's driver is told by
+	err = (&Metadata{volume.dir}).StoreBacking(fs.tmpfsDriver.Name(), "tmpfs")
+	if err != nil {
+		volume.cleanup()
+		return nil, err
+	}
+
+	err = fs.tmpfsDriver.CreateVolume(volume.DataPath())
+	if err != nil {
+		volume.cleanup()
+		return nil, err
+	}
+
+	return volume, nil
+}
+
+func (fs *filesystem) NewEncryptedVolume(handle string) (FilesystemInitVolume, error) {
+	volume, err := fs.newEncryptedVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return volume, nil
+}
+
+func (fs *filesystem) newEncryptedVolume(handle string) (*initVolume, error) {
+	if fs.masterKey == nil {
+		return nil, ErrEncryptionNotEnabled
+	}
+
+	encrypting, ok := fs.driver.(EncryptingDriver)
+	if !ok {
+		return nil, ErrEncryptionNotSupported
+	}
+
+	volume, err := fs.initRawVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, volumeKeySaltSize)
+	_, err = rand.Read(salt)
+	if err != nil {
+		volume.cleanup()
+		return nil, err
+	}
+
+	// the salt is kept before anything is encrypted with the key, so that
+	// there is never data without a way to read it
+	err = (&Metadata{volume.dir}).StoreEncryption(handle, salt)
+	if err != nil {
+		volume.cleanup()
+		return nil, err
+	}
+
+	err = encrypting.CreateEncryptedVolume(volume.DataPath(), fs.masterKey.volumeKey(handle, salt))
+	if err != nil {
+		volume.cleanup()
+		return nil, err
+	}
+
+	return volume, nil
+}
+
 func (fs *filesystem) LookupVolume(handle string) (FilesystemLiveVolume, bool, error) {
+	return fs.lookupVolume(handle, false)
+}
+
+func (fs *filesystem) LookupDeletedVolume(handle string) (FilesystemLiveVolume, bool, error) {
+	return fs.lookupVolume(handle, true)
+}
+
+func (fs *filesystem) lookupVolume(handle string, deleted bool) (FilesystemLiveVolume, bool, error) {
 	volumePath := fs.liveVolumePath(handle)
 
 	info, err := os.Stat(volumePath)
@@ -132,17 +444,36 @@ func (fs *filesystem) LookupVolume(handle string) (FilesystemLiveVolume, bool, e
 		return nil, false, nil
 	}
 
-	return &liveVolume{
+	volume := &liveVolume{
 		baseVolume: baseVolume{
 			fs: fs,
 
 			handle: handle,
 			dir:    volumePath,
 		},
-	}, true, nil
+	}
+
+	isDeleted, err := volume.isDeleted()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if isDeleted != deleted {
+		return nil, false, nil
+	}
+
+	return volume, true, nil
 }
 
 func (fs *filesystem) ListVolumes() ([]FilesystemLiveVolume, error) {
+	return fs.listVolumes(false)
+}
+
+func (fs *filesystem) ListDeletedVolumes() ([]FilesystemLiveVolume, error) {
+	return fs.listVolumes(true)
+}
+
+func (fs *filesystem) listVolumes(deleted bool) ([]FilesystemLiveVolume, error) {
 	liveDirs, err := ioutil.ReadDir(fs.liveDir)
 	if err != nil {
 		return nil, err
@@ -153,14 +484,27 @@ func (fs *filesystem) ListVolumes() ([]FilesystemLiveVolume, error) {
 	for _, liveDir := range liveDirs {
 		handle := liveDir.Name()
 
-		response = append(response, &liveVolume{
+		volume := &liveVolume{
 			baseVolume: baseVolume{
 				fs: fs,
 
 				handle: handle,
 				dir:    fs.liveVolumePath(handle),
 			},
-		})
+		}
+
+		// a volume that can't be told to be deleted is listed as live, to be
+		// found corrupted when it is looked at
+		isDeleted, err := volume.isDeleted()
+		if err != nil {
+			isDeleted = false
+		}
+
+		if isDeleted != deleted {
+			continue
+		}
+
+		response = append(response, volume)
 	}
 
 	return response, nil
@@ -239,6 +583,18 @@ func (base *baseVolume) StoreTTL(ttl TTL) (time.Time, error) {
 	return (&Metadata{base.dir}).StoreTTL(ttl)
 }
 
+func (base *baseVolume) StoreExpiry(ttl TTL, expiresAt time.Time) error {
+	return (&Metadata{base.dir}).StoreExpiry(ttl, expiresAt)
+}
+
+func (base *baseVolume) LoadRenewTTLOnAccess() (bool, error) {
+	return (&Metadata{base.dir}).RenewTTLOnAccess()
+}
+
+func (base *baseVolume) StoreRenewTTLOnAccess() error {
+	return (&Metadata{base.dir}).StoreRenewTTLOnAccess()
+}
+
 func (base *baseVolume) LoadPrivileged() (bool, error) {
 	return (&Metadata{base.dir}).IsPrivileged()
 }
@@ -247,6 +603,146 @@ func (base *baseVolume) StorePrivileged(isPrivileged bool) error {
 	return (&Metadata{base.dir}).StorePrivileged(isPrivileged)
 }
 
+func (base *baseVolume) LoadReadOnly() (bool, error) {
+	return (&Metadata{base.dir}).ReadOnly()
+}
+
+func (base *baseVolume) LoadMountOptions() ([]string, error) {
+	return (&Metadata{base.dir}).MountOptions()
+}
+
+func (base *baseVolume) LoadEncrypted() (bool, error) {
+	// a view's data is its base's
+	isView, err := base.IsView()
+	if err != nil {
+		return false, err
+	}
+
+	if isView {
+		parent, found, err := base.Parent()
+		if err != nil || !found {
+			return false, err
+		}
+
+		return parent.LoadEncrypted()
+	}
+
+	_, _, encrypted, err := (&Metadata{base.dir}).Encryption()
+	return encrypted, err
+}
+
+// key returns the key the volume's own data is encrypted with, if it is.
+func (base *baseVolume) key() ([]byte, bool, error) {
+	handle, salt, encrypted, err := (&Metadata{base.dir}).Encryption()
+	if err != nil || !encrypted {
+		return nil, false, err
+	}
+
+	if base.fs.masterKey == nil {
+		return nil, false, ErrEncryptionNotEnabled
+	}
+
+	return base.fs.masterKey.volumeKey(handle, salt), true, nil
+}
+
+func (base *baseVolume) LoadBacking() (string, string, error) {
+	driver, filesystemType, err := (&Metadata{base.dir}).Backing()
+	if err != nil {
+		return "", "", err
+	}
+
+	if driver == "" {
+		driver = base.driver().Name()
+
+		filesystemType, err = filesystemTypeOf(base.DataPath())
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return driver, filesystemType, nil
+}
+
+func (base *baseVolume) LoadCommitted() (time.Time, bool, error) {
+	return (&Metadata{base.dir}).Committed()
+}
+
+func (base *baseVolume) StoreCommitted(frozen bool) (time.Time, error) {
+	return (&Metadata{base.dir}).StoreCommitted(frozen)
+}
+
+func (base *baseVolume) LoadLastAccessed() (time.Time, error) {
+	return (&Metadata{base.dir}).LastAccessed()
+}
+
+func (base *baseVolume) StoreLastAccessed() (time.Time, error) {
+	return (&Metadata{base.dir}).StoreLastAccessed()
+}
+
+func (base *baseVolume) LoadCreated() (time.Time, string, error) {
+	return (&Metadata{base.dir}).Created()
+}
+
+func (base *baseVolume) StoreCreated(strategy string) (time.Time, error) {
+	return (&Metadata{base.dir}).StoreCreated(strategy)
+}
+
+func (base *baseVolume) LoadModified() (time.Time, error) {
+	return (&Metadata{base.dir}).Modified()
+}
+
+// StoreModified also has the driver forget what it found the last time it
+// walked the volume for its stats, which no longer holds.
+func (base *baseVolume) StoreModified() (time.Time, error) {
+	if cacher, ok := base.driver().(StatsCachingDriver); ok {
+		cacher.ForgetVolumeStats(base.DataPath())
+	}
+
+	return (&Metadata{base.dir}).StoreModified()
+}
+
+func (base *baseVolume) LoadDigest() (string, error) {
+	return (&Metadata{base.dir}).Digest()
+}
+
+func (base *baseVolume) StoreDigest(digest string) error {
+	return (&Metadata{base.dir}).StoreDigest(digest)
+}
+
+func (base *baseVolume) LoadStreamInKeys() (map[string]time.Time, error) {
+	return (&Metadata{base.dir}).StreamInKeys()
+}
+
+func (base *baseVolume) StoreStreamInKeys(keys map[string]time.Time) error {
+	return (&Metadata{base.dir}).StoreStreamInKeys(keys)
+}
+
+func (base *baseVolume) LoadCreateKey() (string, string, error) {
+	return (&Metadata{base.dir}).CreateKey()
+}
+
+func (base *baseVolume) StoreCreateKey(key string, fingerprint string) error {
+	return (&Metadata{base.dir}).StoreCreateKey(key, fingerprint)
+}
+
+func (base *baseVolume) LoadReleased() (DestroyOptions, bool, error) {
+	return (&Metadata{base.dir}).Released()
+}
+
+func (base *baseVolume) StoreReleased(opts DestroyOptions) error {
+	return (&Metadata{base.dir}).StoreReleased(opts)
+}
+
+// IsView reports whether the volume's data is a link to another volume's.
+func (base *baseVolume) IsView() (bool, error) {
+	info, err := os.Lstat(base.DataPath())
+	if err != nil {
+		return false, err
+	}
+
+	return info.Mode()&os.ModeSymlink != 0, nil
+}
+
 func (base *baseVolume) Parent() (FilesystemLiveVolume, bool, error) {
 	parentDir, err := filepath.EvalSymlinks(base.parentLink())
 	if os.IsNotExist(err) {
@@ -287,7 +783,37 @@ func (base *baseVolume) Destroy() error {
 	return deadVol.Destroy()
 }
 
+// driver is the driver that backs the volume's data: the tmpfs driver for
+// volumes created by NewTmpfsVolume, and the filesystem's driver otherwise.
+func (base *baseVolume) driver() Driver {
+	if base.isTmpfs() {
+		return base.fs.tmpfsDriver
+	}
+
+	return base.fs.driver
+}
+
+// isTmpfs returns whether the volume's data is held by the tmpfs driver. A
+// volume whose backing can't be read is taken to be the filesystem driver's;
+// whatever is done with it fails all the same.
+func (base *baseVolume) isTmpfs() bool {
+	if base.fs.tmpfsDriver == nil {
+		return false
+	}
+
+	driver, _, err := (&Metadata{base.dir}).Backing()
+
+	return err == nil && driver == base.fs.tmpfsDriver.Name()
+}
+
+// cleanup removes the volume's dir, shredding its key material first if it
+// has any.
 func (base *baseVolume) cleanup() error {
+	err := (&Metadata{base.dir}).ShredEncryption()
+	if err != nil {
+		return err
+	}
+
 	return os.RemoveAll(base.dir)
 }
 
@@ -299,41 +825,142 @@ type initVolume struct {
 	baseVolume
 }
 
-func (vol *initVolume) Initialize() (FilesystemLiveVolume, error) {
-	liveDir := vol.fs.liveVolumePath(vol.handle)
+func (vol *initVolume) SetQuota(sizeInBytes int64) error {
+	quotas, ok := vol.driver().(QuotaDriver)
+	if !ok {
+		return ErrQuotasNotSupported
+	}
+
+	return quotas.SetVolumeQuota(vol.DataPath(), sizeInBytes)
+}
 
-	err := os.Rename(vol.dir, liveDir)
+func (vol *initVolume) MakeReadOnly() error {
+	isView, err := vol.IsView()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &liveVolume{
-		baseVolume: baseVolume{
-			fs: vol.fs,
+	if !isView {
+		readOnly, ok := vol.driver().(ReadOnlyDriver)
+		if !ok {
+			return ErrReadOnlyNotSupported
+		}
 
-			handle: vol.handle,
-			dir:    liveDir,
-		},
-	}, nil
-}
+		err = readOnly.MakeReadOnly(vol.DataPath())
+		if err != nil {
+			return err
+		}
+	}
 
-type liveVolume struct {
-	baseVolume
+	return (&Metadata{vol.dir}).StoreReadOnly()
 }
 
-func (vol *liveVolume) NewSubvolume(handle string) (FilesystemInitVolume, error) {
-	child, err := vol.fs.initRawVolume(handle)
+func (vol *initVolume) SetMountOptions(options []string) error {
+	var invalid MountOptionsError
+
+	// a view's data is its base's, and mounted as it is
+	isView, err := vol.IsView()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = vol.fs.driver.CreateCopyOnWriteLayer(child.DataPath(), vol.DataPath())
-	if err != nil {
-		child.cleanup()
-		return nil, err
+	if isView {
+		invalid.add(baggageclaim.FieldErrorConflict, "views are mounted as their base is")
+		return invalid
 	}
 
-	err = os.Symlink(vol.dir, child.parentLink())
+	mountOptions, ok := vol.driver().(MountOptionsDriver)
+	if !ok {
+		invalid.add(baggageclaim.FieldErrorNotAllowed, "the %s driver does not support mount options", vol.driver().Name())
+		return invalid
+	}
+
+	options, err = checkMountOptions(options, mountOptions.MountOptions())
+	if err != nil {
+		return err
+	}
+
+	err = mountOptions.SetMountOptions(vol.DataPath(), options)
+	if err != nil {
+		return err
+	}
+
+	return (&Metadata{vol.dir}).StoreMountOptions(options)
+}
+
+// Initialize records what the volume's data is backed by and makes the
+// volume live.
+func (vol *initVolume) Initialize() (FilesystemLiveVolume, error) {
+	filesystemType, err := filesystemTypeOf(vol.DataPath())
+	if err != nil {
+		return nil, err
+	}
+
+	err = (&Metadata{vol.dir}).StoreBacking(vol.driver().Name(), filesystemType)
+	if err != nil {
+		return nil, err
+	}
+
+	liveDir := vol.fs.liveVolumePath(vol.handle)
+
+	err = os.Rename(vol.dir, liveDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &liveVolume{
+		baseVolume: baseVolume{
+			fs: vol.fs,
+
+			handle: vol.handle,
+			dir:    liveDir,
+		},
+	}, nil
+}
+
+type liveVolume struct {
+	baseVolume
+}
+
+func (vol *liveVolume) NewSubvolume(handle string) (FilesystemInitVolume, error) {
+	if vol.isTmpfs() {
+		return nil, ErrCopyOnWriteOfTmpfs
+	}
+
+	encrypted, err := vol.LoadEncrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	var child *initVolume
+	if encrypted {
+		// a layer on top of encrypted data could not be encrypted itself, so
+		// the child is a copy encrypted with a key of its own
+		child, err = vol.fs.newEncryptedVolume(handle)
+		if err != nil {
+			return nil, err
+		}
+
+		err = vol.CopyData(vol.DataPath(), child.DataPath())
+		if err != nil {
+			child.Destroy()
+			return nil, err
+		}
+	} else {
+		child, err = vol.fs.initRawVolume(handle)
+		if err != nil {
+			return nil, err
+		}
+
+		// the child is the filesystem driver's, as the layer is
+		err = vol.fs.driver.CreateCopyOnWriteLayer(child.DataPath(), vol.DataPath())
+		if err != nil {
+			child.cleanup()
+			return nil, err
+		}
+	}
+
+	err = os.Symlink(vol.dir, child.parentLink())
 	if err != nil {
 		child.Destroy()
 		return nil, err
@@ -342,8 +969,539 @@ func (vol *liveVolume) NewSubvolume(handle string) (FilesystemInitVolume, error)
 	return child, nil
 }
 
-func (vol *liveVolume) SizeInBytes() (int64, error) {
-	return vol.fs.driver.GetVolumeSizeInBytes(vol.DataPath())
+func (vol *liveVolume) NewView(handle string) (FilesystemInitVolume, error) {
+	child, err := vol.fs.initRawVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPath, err := filepath.Abs(vol.DataPath())
+	if err != nil {
+		child.cleanup()
+		return nil, err
+	}
+
+	err = os.Symlink(dataPath, child.DataPath())
+	if err != nil {
+		child.cleanup()
+		return nil, err
+	}
+
+	err = os.Symlink(vol.dir, child.parentLink())
+	if err != nil {
+		child.cleanup()
+		return nil, err
+	}
+
+	return child, nil
+}
+
+func (vol *liveVolume) NewClone(handle string) (FilesystemInitVolume, error) {
+	encrypted, err := vol.LoadEncrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	// a clone of encrypted data is encrypted with a key of its own, so it is
+	// copied onto rather than cloned
+	if encrypted {
+		clone, err := vol.fs.newEncryptedVolume(handle)
+		if err != nil {
+			return nil, err
+		}
+
+		err = vol.CopyData(vol.DataPath(), clone.DataPath())
+		if err != nil {
+			clone.Destroy()
+			return nil, err
+		}
+
+		return clone, nil
+	}
+
+	clone, err := vol.fs.initRawVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	// a clone is the filesystem driver's, so a tmpfs volume's is copied onto
+	// it rather than cloned
+	if cloner, ok := vol.fs.driver.(CloningDriver); ok && !vol.isTmpfs() {
+		err = cloner.CreateClone(clone.DataPath(), vol.DataPath())
+		if err != nil {
+			clone.cleanup()
+			return nil, err
+		}
+
+		return clone, nil
+	}
+
+	err = vol.fs.driver.CreateVolume(clone.DataPath())
+	if err != nil {
+		clone.cleanup()
+		return nil, err
+	}
+
+	err = vol.CopyData(vol.DataPath(), clone.DataPath())
+	if err != nil {
+		clone.Destroy()
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+func (vol *liveVolume) Rename(handle string) (FilesystemLiveVolume, error) {
+	renamed := &liveVolume{
+		baseVolume: baseVolume{
+			fs: vol.fs,
+
+			handle: handle,
+			dir:    vol.fs.liveVolumePath(handle),
+		},
+	}
+
+	// os.Rename would replace an empty dir rather than fail
+	_, err := os.Lstat(renamed.dir)
+	if err == nil {
+		return nil, &os.LinkError{Op: "rename", Old: vol.dir, New: renamed.dir, Err: os.ErrExist}
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	renamer, renames := vol.driver().(RenamingDriver)
+	if renames {
+		err = renamer.RenameVolume(vol.DataPath(), renamed.DataPath())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = os.Rename(vol.dir, renamed.dir)
+	if err == nil {
+		err = vol.fs.repointChildren(vol, renamed)
+		if err == nil {
+			return renamed, nil
+		}
+
+		vol.fs.repointChildren(renamed, vol)
+		os.Rename(renamed.dir, vol.dir)
+	}
+
+	if renames {
+		renamer.RenameVolume(renamed.DataPath(), vol.DataPath())
+	}
+
+	return nil, err
+}
+
+// repointChildren points the links of the volumes whose parent is from,
+// whether live or still being initialized, at to instead.
+func (fs *filesystem) repointChildren(from *liveVolume, to *liveVolume) error {
+	fromData, err := filepath.Abs(from.DataPath())
+	if err != nil {
+		return err
+	}
+
+	toData, err := filepath.Abs(to.DataPath())
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{fs.liveDir, fs.initDir} {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			child := baseVolume{fs: fs, handle: entry.Name(), dir: filepath.Join(dir, entry.Name())}
+
+			err = repointLink(child.parentLink(), from.dir, to.dir)
+			if err != nil {
+				return err
+			}
+
+			// a view's data is a link to its base's
+			err = repointLink(child.DataPath(), fromData, toData)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// repointLink replaces the link with one to the new target if it is a link to
+// the old one, leaving anything else alone.
+func repointLink(link string, oldTarget string, newTarget string) error {
+	info, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	target, err := os.Readlink(link)
+	if err != nil {
+		return err
+	}
+
+	if target != oldTarget {
+		return nil
+	}
+
+	// the link is replaced in one go, so that it is never missing
+	tmpLink := link + ".repointing"
+
+	err = os.Symlink(newTarget, tmpLink)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpLink, link)
+	if err != nil {
+		os.Remove(tmpLink)
+		return err
+	}
+
+	return nil
+}
+
+func (vol *liveVolume) LoadDeleted() (DestroyOptions, time.Time, bool, error) {
+	return (&Metadata{vol.dir}).Deleted()
+}
+
+func (vol *liveVolume) StoreDeleted(opts DestroyOptions, deletedAt time.Time) error {
+	return (&Metadata{vol.dir}).StoreDeleted(opts, deletedAt)
+}
+
+func (vol *liveVolume) ClearDeleted() error {
+	return (&Metadata{vol.dir}).ClearDeleted()
+}
+
+func (vol *liveVolume) isDeleted() (bool, error) {
+	_, err := os.Stat(filepath.Join(vol.dir, deletedFileName))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (vol *liveVolume) Stats() (VolumeStats, error) {
+	driverStats, err := vol.driver().GetVolumeStats(vol.DataPath())
+	if err != nil {
+		return VolumeStats{}, err
+	}
+
+	var quota int64
+	if quotas, ok := vol.driver().(QuotaDriver); ok {
+		quota, err = quotas.GetVolumeQuota(vol.DataPath())
+		if err != nil {
+			return VolumeStats{}, err
+		}
+	}
+
+	driver, filesystemType, err := vol.LoadBacking()
+	if err != nil {
+		return VolumeStats{}, err
+	}
+
+	return VolumeStats{
+		SizeInBytes:  driverStats.UsedBytes,
+		FileCount:    driverStats.FileCount,
+		SizeExact:    driverStats.Exact,
+		FreeBytes:    driverStats.FreeBytes,
+		QuotaInBytes: quota,
+
+		Driver:         driver,
+		FilesystemType: filesystemType,
+	}, nil
+}
+
+func (vol *liveVolume) CopyData(src string, dest string) error {
+	if copier, ok := vol.driver().(CopyingDriver); ok {
+		return copier.CopyData(src, dest)
+	}
+
+	return copyData(src, dest)
+}
+
+func (vol *liveVolume) Size() (int64, error) {
+	if sizer, ok := vol.driver().(SizingDriver); ok {
+		return sizer.GetVolumeSize(vol.DataPath())
+	}
+
+	stats, err := vol.driver().GetVolumeStats(vol.DataPath())
+	if err != nil {
+		return 0, err
+	}
+
+	return stats.UsedBytes, nil
+}
+
+func (vol *liveVolume) Room() (uint64, bool, error) {
+	room, bounded, err := freeBytes(vol.DataPath())
+	if err != nil {
+		return 0, false, err
+	}
+
+	quotas, ok := vol.driver().(QuotaDriver)
+	if !ok {
+		return room, bounded, nil
+	}
+
+	quota, err := quotas.GetVolumeQuota(vol.DataPath())
+	if err != nil {
+		return 0, false, err
+	}
+
+	if quota == 0 {
+		return room, bounded, nil
+	}
+
+	// walking the volume for its size on every stream-in would cost more
+	// than refusing streams early saves; writes past the quota fail anyway
+	sizer, ok := vol.driver().(SizingDriver)
+	if !ok {
+		return room, bounded, nil
+	}
+
+	size, err := sizer.GetVolumeSize(vol.DataPath())
+	if err != nil {
+		return 0, false, err
+	}
+
+	var left uint64
+	if size < quota {
+		left = uint64(quota - size)
+	}
+
+	if !bounded || left < room {
+		room = left
+	}
+
+	return room, true, nil
+}
+
+func (vol *liveVolume) Snapshot() (string, func() error, error) {
+	snapshotter, ok := vol.driver().(SnapshottingDriver)
+	if !ok {
+		return "", nil, ErrSnapshotsNotSupported
+	}
+
+	dir, err := ioutil.TempDir(vol.fs.snapshotsDir, vol.handle+"-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	snapshotPath := filepath.Join(dir, "volume")
+
+	err = snapshotter.CreateSnapshot(snapshotPath, vol.DataPath())
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+
+	return snapshotPath, func() error {
+		err := vol.driver().DestroyVolume(snapshotPath)
+		if err != nil {
+			return err
+		}
+
+		return os.RemoveAll(dir)
+	}, nil
+}
+
+func (vol *liveVolume) Materialize() (bool, error) {
+	remounted, err := vol.remount()
+	if err != nil {
+		return false, err
+	}
+
+	unlocked, err := vol.unlock()
+	if err != nil {
+		return false, err
+	}
+
+	return remounted || unlocked, nil
+}
+
+// unlock gives the driver the volume's key again if its data is encrypted,
+// returning whether it had to.
+func (vol *liveVolume) unlock() (bool, error) {
+	key, encrypted, err := vol.key()
+	if err != nil || !encrypted {
+		return false, err
+	}
+
+	encrypting, ok := vol.driver().(EncryptingDriver)
+	if !ok {
+		return false, ErrEncryptionNotSupported
+	}
+
+	return encrypting.UnlockVolume(vol.DataPath(), key)
+}
+
+// remount mounts the volume's data again if the driver's mount of it is gone,
+// returning whether it had to.
+func (vol *liveVolume) remount() (bool, error) {
+	mounter, ok := vol.driver().(MountingDriver)
+	if !ok {
+		return false, nil
+	}
+
+	// a view's data is its base's
+	isView, err := vol.IsView()
+	if err != nil {
+		return false, err
+	}
+
+	if isView {
+		return false, nil
+	}
+
+	remounted, err := mounter.EnsureMounted(vol.DataPath())
+	if err != nil || !remounted {
+		return remounted, err
+	}
+
+	return true, vol.restoreMount()
+}
+
+// restoreMount gives the volume its mount options and makes it read-only
+// again if it was, after the driver has mounted it afresh.
+func (vol *liveVolume) restoreMount() error {
+	options, err := vol.LoadMountOptions()
+	if err != nil {
+		return err
+	}
+
+	if len(options) > 0 {
+		mountOptions, ok := vol.driver().(MountOptionsDriver)
+		if !ok {
+			return fmt.Errorf("the %s driver does not support mount options", vol.driver().Name())
+		}
+
+		err = mountOptions.SetMountOptions(vol.DataPath(), options)
+		if err != nil {
+			return err
+		}
+	}
+
+	readOnly, err := vol.LoadReadOnly()
+	if err != nil {
+		return err
+	}
+
+	if !readOnly {
+		return nil
+	}
+
+	readOnlyDriver, ok := vol.driver().(ReadOnlyDriver)
+	if !ok {
+		return ErrReadOnlyNotSupported
+	}
+
+	return readOnlyDriver.MakeReadOnly(vol.DataPath())
+}
+
+func (vol *liveVolume) Promote() error {
+	_, err := os.Lstat(vol.parentLink())
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// a view's data is its base's, and stays so
+	isView, err := vol.IsView()
+	if err != nil {
+		return err
+	}
+
+	if isView {
+		return nil
+	}
+
+	// drivers that copy a child's data when creating it have nothing to do
+	promoter, ok := vol.driver().(PromotingDriver)
+	if ok {
+		hasChildren, err := vol.fs.hasCopyOnWriteChildren(vol)
+		if err != nil {
+			return err
+		}
+
+		if hasChildren {
+			return ErrPromoteWithChildren
+		}
+
+		err = promoter.PromoteVolume(vol.DataPath())
+
+		// the driver mounts it afresh, whether or not it got far enough to
+		// mount the promoted copy
+		mountErr := vol.restoreMount()
+
+		if err != nil {
+			return err
+		}
+
+		if mountErr != nil {
+			return mountErr
+		}
+	}
+
+	// removed last, as the driver may need it to tell the volume's ancestry
+	// until its data is its own; a volume left with the link after a crash
+	// is promoted again without copying anything
+	return os.Remove(vol.parentLink())
+}
+
+// hasCopyOnWriteChildren returns whether any volume other than a view,
+// whether live or still being initialized, has the volume as its parent.
+func (fs *filesystem) hasCopyOnWriteChildren(parent *liveVolume) (bool, error) {
+	for _, dir := range []string{fs.liveDir, fs.initDir} {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return false, err
+		}
+
+		for _, entry := range entries {
+			child := baseVolume{fs: fs, handle: entry.Name(), dir: filepath.Join(dir, entry.Name())}
+
+			target, err := os.Readlink(child.parentLink())
+			if os.IsNotExist(err) || (err == nil && target != parent.dir) {
+				continue
+			}
+
+			if err != nil {
+				return false, err
+			}
+
+			isView, err := child.IsView()
+			if err != nil && !os.IsNotExist(err) {
+				return false, err
+			}
+
+			if !isView {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
 }
 
 type deadVolume struct {
@@ -351,10 +1509,42 @@ type deadVolume struct {
 }
 
 func (vol *deadVolume) Destroy() error {
-	err := vol.fs.driver.DestroyVolume(vol.DataPath())
-	if err != nil {
+	isView, err := vol.IsView()
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
 
+	// a view's data belongs to its base
+	if !isView {
+		err = vol.shred()
+		if err != nil {
+			return err
+		}
+
+		err = vol.driver().DestroyVolume(vol.DataPath())
+		if err != nil {
+			return err
+		}
+	}
+
 	return vol.cleanup()
 }
+
+// shred has the driver forget the volume's key, if its data is encrypted,
+// and then shreds its key material, so that the data can't be read from
+// then on, even if removing it fails part way or is recovered from the disk.
+func (vol *deadVolume) shred() error {
+	_, _, encrypted, err := (&Metadata{vol.dir}).Encryption()
+	if err != nil || !encrypted {
+		return err
+	}
+
+	if encrypting, ok := vol.driver().(EncryptingDriver); ok {
+		err = encrypting.LockVolume(vol.DataPath())
+		if err != nil {
+			return err
+		}
+	}
+
+	return (&Metadata{vol.dir}).ShredEncryption()
+}
@@ -0,0 +1,39 @@
+package volume
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// filesystemMagics names the filesystems volumes are commonly kept on, by the
+// f_type statfs reports for them.
+var filesystemMagics = map[int64]string{
+	0x9123683e: "btrfs",
+	0xef53:     "ext4", // shared with ext2 and ext3
+	0x58465342: "xfs",
+	0x01021994: "tmpfs",
+	0x794c7630: "overlay",
+	0x2fc12fc1: "zfs",
+	0x6969:     "nfs",
+	0x65735546: "fuse",
+	0xf2f52010: "f2fs",
+}
+
+// filesystemTypeOf returns the type of the filesystem path is on, or its
+// magic number in hex if it isn't one of the known ones.
+func filesystemTypeOf(path string) (string, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return "", err
+	}
+
+	magic := int64(stat.Type)
+
+	name, found := filesystemMagics[magic]
+	if !found {
+		return fmt.Sprintf("0x%x", magic), nil
+	}
+
+	return name, nil
+}
@@ -0,0 +1,7 @@
+// +build !linux
+
+package volume
+
+func filesystemTypeOf(path string) (string, error) {
+	return "", nil
+}
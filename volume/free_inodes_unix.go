@@ -0,0 +1,20 @@
+// +build !windows
+
+package volume
+
+import "syscall"
+
+func (fs *filesystem) FreeInodes() (uint64, bool, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(fs.liveDir, &stat)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// filesystems that make inodes as they need them report none at all
+	if stat.Files == 0 {
+		return 0, false, nil
+	}
+
+	return uint64(stat.Ffree), true, nil
+}
@@ -0,0 +1,6 @@
+package volume
+
+// NTFS has no fixed inode table to run out of.
+func (fs *filesystem) FreeInodes() (uint64, bool, error) {
+	return 0, false, nil
+}
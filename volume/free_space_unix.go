@@ -0,0 +1,22 @@
+// +build !windows
+
+package volume
+
+import "syscall"
+
+func (fs *filesystem) FreeBytes() (uint64, error) {
+	free, _, err := freeBytes(fs.dir)
+	return free, err
+}
+
+// freeBytes returns what is free to unprivileged users on the filesystem path
+// is on, and whether it could be found.
+func freeBytes(path string) (uint64, bool, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
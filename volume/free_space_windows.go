@@ -0,0 +1,12 @@
+package volume
+
+import "math"
+
+// Free space is not checked on Windows.
+func (fs *filesystem) FreeBytes() (uint64, error) {
+	return math.MaxUint64, nil
+}
+
+func freeBytes(path string) (uint64, bool, error) {
+	return 0, false, nil
+}
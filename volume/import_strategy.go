@@ -27,3 +27,7 @@ func (strategy ImportStrategy) Materialize(logger lager.Logger, handle string, f
 
 	return initVolume, nil
 }
+
+func (ImportStrategy) Type() string {
+	return StrategyImport
+}
@@ -0,0 +1,242 @@
+package volume
+
+import (
+	"context"
+	"io"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+
+	"github.com/concourse/baggageclaim/metrics"
+)
+
+var durationBuckets = []float64{
+	0.01,
+	0.1,
+	1,
+	10,
+	60,
+	300,
+}
+
+type instrumentedRepository struct {
+	Repository
+
+	clock clock.Clock
+
+	volumesCreated   *metrics.Counter
+	volumesDestroyed *metrics.Counter
+	volumesReleased  *metrics.Counter
+	volumesRenamed   *metrics.Counter
+	volumesPurged    *metrics.Counter
+	bytesStreamedIn  *metrics.Counter
+	bytesStreamedOut *metrics.Counter
+
+	createDurations    *metrics.Histogram
+	streamInDurations  *metrics.Histogram
+	streamOutDurations *metrics.Histogram
+}
+
+// NewInstrumentedRepository wraps the repository, counting the volumes it
+// creates, destroys, renames and purges and the bytes streamed in and out of
+// them, and timing creates and streams. Only calls that succeed are timed.
+// A base that is only released, as it still has views, is counted as such
+// rather than as destroyed.
+func NewInstrumentedRepository(repo Repository, clock clock.Clock, registry *metrics.Registry) Repository {
+	return &instrumentedRepository{
+		Repository: repo,
+
+		clock: clock,
+
+		volumesCreated:   registry.NewCounter("baggageclaim_volumes_created_total", "Volumes created."),
+		volumesDestroyed: registry.NewCounter("baggageclaim_volumes_destroyed_total", "Volumes destroyed."),
+		volumesReleased:  registry.NewCounter("baggageclaim_volumes_released_total", "Volumes asked to be destroyed that were released instead, as they still had views."),
+		volumesRenamed:   registry.NewCounter("baggageclaim_volumes_renamed_total", "Volumes renamed."),
+		volumesPurged:    registry.NewCounter("baggageclaim_volumes_purged_total", "Deleted volumes purged once their retention window passed."),
+		bytesStreamedIn:  registry.NewCounter("baggageclaim_streamed_in_bytes_total", "Bytes streamed into volumes, as sent."),
+		bytesStreamedOut: registry.NewCounter("baggageclaim_streamed_out_bytes_total", "Bytes streamed out of volumes, before any content encoding."),
+
+		createDurations:    registry.NewHistogram("baggageclaim_volume_create_duration_seconds", "Time taken to create volumes.", durationBuckets),
+		streamInDurations:  registry.NewHistogram("baggageclaim_stream_in_duration_seconds", "Time taken to stream into volumes.", durationBuckets),
+		streamOutDurations: registry.NewHistogram("baggageclaim_stream_out_duration_seconds", "Time taken to stream out of volumes.", durationBuckets),
+	}
+}
+
+func (repo *instrumentedRepository) CreateVolume(handle string, strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool, opts CreateOptions) (Volume, error) {
+	start := repo.clock.Now()
+
+	volume, err := repo.Repository.CreateVolume(handle, strategy, properties, ttlInSeconds, isPrivileged, opts)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	repo.createDurations.Observe(repo.clock.Since(start).Seconds())
+	repo.volumesCreated.Inc()
+
+	return volume, nil
+}
+
+func (repo *instrumentedRepository) CloneVolume(srcHandle string, handle string) (Volume, error) {
+	volume, err := repo.Repository.CloneVolume(srcHandle, handle)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	repo.volumesCreated.Inc()
+
+	return volume, nil
+}
+
+func (repo *instrumentedRepository) RenameVolume(handle string, newHandle string) (Volume, error) {
+	volume, err := repo.Repository.RenameVolume(handle, newHandle)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	repo.volumesRenamed.Inc()
+
+	return volume, nil
+}
+
+func (repo *instrumentedRepository) DestroyVolume(handle string, opts DestroyOptions) error {
+	err := repo.Repository.DestroyVolume(handle, opts)
+	if err != nil {
+		return err
+	}
+
+	repo.countDestroyed(handle)
+
+	return nil
+}
+
+func (repo *instrumentedRepository) DestroyVolumeAndDescendants(handle string, opts DestroyOptions) ([]string, error) {
+	destroyed, err := repo.Repository.DestroyVolumeAndDescendants(handle, opts)
+
+	// including those destroyed before it failed
+	for _, destroyedHandle := range destroyed {
+		repo.countDestroyed(destroyedHandle)
+	}
+
+	return destroyed, err
+}
+
+func (repo *instrumentedRepository) DestroyVolumes(handles []string, opts DestroyOptions) map[string]error {
+	errs := repo.Repository.DestroyVolumes(handles, opts)
+
+	for handle, err := range errs {
+		if err == nil {
+			repo.countDestroyed(handle)
+		}
+	}
+
+	return errs
+}
+
+func (repo *instrumentedRepository) DestroyVolumesWithProperties(properties Properties, opts DestroyOptions) (map[string]error, error) {
+	errs, err := repo.Repository.DestroyVolumesWithProperties(properties, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for handle, err := range errs {
+		if err == nil {
+			repo.countDestroyed(handle)
+		}
+	}
+
+	return errs, nil
+}
+
+func (repo *instrumentedRepository) PurgeDeletedVolumes() (map[string]error, error) {
+	errs, err := repo.Repository.PurgeDeletedVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, err := range errs {
+		if err == nil {
+			repo.volumesPurged.Inc()
+		}
+	}
+
+	return errs, nil
+}
+
+// countDestroyed counts a volume that was destroyed successfully, which is
+// still there if it was only released. Volumes that do not exist count as
+// destroyed, as they do for the repository.
+func (repo *instrumentedRepository) countDestroyed(handle string) {
+	exists, err := repo.Repository.VolumeExists(handle)
+	if err == nil && exists {
+		repo.volumesReleased.Inc()
+		return
+	}
+
+	repo.volumesDestroyed.Inc()
+}
+
+func (repo *instrumentedRepository) StreamIn(ctx context.Context, handle string, path string, stream io.Reader, opts StreamInOptions) (bool, error) {
+	start := repo.clock.Now()
+
+	var bytesRead int64
+
+	badStream, err := repo.Repository.StreamIn(ctx, handle, path, &countingReader{Reader: stream, count: &bytesRead}, opts)
+
+	repo.bytesStreamedIn.Add(float64(bytesRead))
+
+	if err != nil {
+		return badStream, err
+	}
+
+	repo.streamInDurations.Observe(repo.clock.Since(start).Seconds())
+
+	return false, nil
+}
+
+func (repo *instrumentedRepository) StreamOut(ctx context.Context, handle string, path string, dest io.Writer, opts StreamOutOptions) error {
+	start := repo.clock.Now()
+
+	var bytesWritten int64
+
+	err := repo.Repository.StreamOut(ctx, handle, path, &countingWriter{Writer: dest, count: &bytesWritten}, opts)
+
+	repo.bytesStreamedOut.Add(float64(bytesWritten))
+
+	if err != nil {
+		return err
+	}
+
+	repo.streamOutDurations.Observe(repo.clock.Since(start).Seconds())
+
+	return nil
+}
+
+func (repo *instrumentedRepository) StreamOutDiff(handle string, baseHandle string, dest io.Writer) error {
+	var bytesWritten int64
+
+	err := repo.Repository.StreamOutDiff(handle, baseHandle, &countingWriter{Writer: dest, count: &bytesWritten})
+
+	repo.bytesStreamedOut.Add(float64(bytesWritten))
+
+	return err
+}
+
+// WithLogData keeps counting and timing what the tagged repository does, as
+// the repository it wraps would otherwise be returned bare.
+func (repo *instrumentedRepository) WithLogData(data lager.Data) Repository {
+	tagged := *repo
+	tagged.Repository = repo.Repository.WithLogData(data)
+	return &tagged
+}
+
+type countingWriter struct {
+	io.Writer
+
+	count *int64
+}
+
+func (writer *countingWriter) Write(p []byte) (int, error) {
+	n, err := writer.Writer.Write(p)
+	*writer.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,304 @@
+package volume_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/metrics"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+)
+
+var _ = Describe("Instrumented Repository", func() {
+	var (
+		fakeRepository *volumefakes.FakeRepository
+		fakeClock      *fakeclock.FakeClock
+		registry       *metrics.Registry
+
+		repo volume.Repository
+	)
+
+	BeforeEach(func() {
+		fakeRepository = new(volumefakes.FakeRepository)
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 0))
+		registry = metrics.NewRegistry()
+
+		repo = volume.NewInstrumentedRepository(fakeRepository, fakeClock, registry)
+	})
+
+	written := func() string {
+		buf := new(bytes.Buffer)
+		registry.Write(buf)
+		return buf.String()
+	}
+
+	Describe("CreateVolume", func() {
+		BeforeEach(func() {
+			fakeRepository.CreateVolumeStub = func(string, volume.Strategy, volume.Properties, uint, bool, volume.CreateOptions) (volume.Volume, error) {
+				fakeClock.Increment(2 * time.Second)
+				return volume.Volume{Handle: "some-handle"}, nil
+			}
+		})
+
+		It("creates the volume in the wrapped repository", func() {
+			opts := volume.CreateOptions{
+				SizeInBytes:      2,
+				ReadOnly:         true,
+				MountOptions:     []string{"noatime"},
+				RenewTTLOnAccess: true,
+				IdempotencyKey:   "some-key",
+				Encrypted:        true,
+			}
+
+			created, err := repo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 1, true, opts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Handle).To(Equal("some-handle"))
+
+			handle, _, _, ttl, privileged, createOpts := fakeRepository.CreateVolumeArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(ttl).To(Equal(uint(1)))
+			Expect(privileged).To(BeTrue())
+			Expect(createOpts).To(Equal(opts))
+		})
+
+		It("counts and times the create", func() {
+			_, err := repo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_created_total 1\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_volume_create_duration_seconds_bucket{le=\"1\"} 0\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_volume_create_duration_seconds_bucket{le=\"10\"} 1\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_volume_create_duration_seconds_sum 2\n"))
+		})
+
+		Context("when the create fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeRepository.CreateVolumeStub = nil
+				fakeRepository.CreateVolumeReturns(volume.Volume{}, disaster)
+			})
+
+			It("returns the error without counting it", func() {
+				_, err := repo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, false, volume.CreateOptions{})
+				Expect(err).To(Equal(disaster))
+
+				Expect(written()).To(ContainSubstring("baggageclaim_volumes_created_total 0\n"))
+				Expect(written()).To(ContainSubstring("baggageclaim_volume_create_duration_seconds_count 0\n"))
+			})
+		})
+	})
+
+	Describe("destroying volumes", func() {
+		It("counts each volume destroyed", func() {
+			Expect(repo.DestroyVolume("some-handle", volume.DestroyOptions{})).To(Succeed())
+
+			fakeRepository.DestroyVolumeAndDescendantsReturns([]string{"other-child", "other-handle"}, nil)
+
+			destroyed, err := repo.DestroyVolumeAndDescendants("other-handle", volume.DestroyOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destroyed).To(HaveLen(2))
+
+			fakeRepository.DestroyVolumesReturns(map[string]error{
+				"a": nil,
+				"b": errors.New("nope"),
+				"c": nil,
+			})
+
+			errs := repo.DestroyVolumes([]string{"a", "b", "c"}, volume.DestroyOptions{})
+			Expect(errs).To(HaveLen(3))
+
+			fakeRepository.DestroyVolumesWithPropertiesReturns(map[string]error{
+				"d": nil,
+				"e": errors.New("nope"),
+			}, nil)
+
+			errs, err = repo.DestroyVolumesWithProperties(volume.Properties{"some": "property"}, volume.DestroyOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(HaveLen(2))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_destroyed_total 6\n"))
+		})
+
+		It("does not count a destroy that fails", func() {
+			fakeRepository.DestroyVolumeReturns(errors.New("nope"))
+
+			Expect(repo.DestroyVolume("some-handle", volume.DestroyOptions{})).NotTo(Succeed())
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_destroyed_total 0\n"))
+		})
+
+		It("counts a base that is still there for its views as released", func() {
+			fakeRepository.VolumeExistsStub = func(handle string) (bool, error) {
+				return handle == "some-base", nil
+			}
+
+			Expect(repo.DestroyVolume("some-base", volume.DestroyOptions{})).To(Succeed())
+
+			fakeRepository.DestroyVolumesReturns(map[string]error{
+				"some-base": nil,
+				"some-view": nil,
+			})
+
+			repo.DestroyVolumes([]string{"some-base", "some-view"}, volume.DestroyOptions{})
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_released_total 2\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_destroyed_total 1\n"))
+		})
+	})
+
+	Describe("RenameVolume", func() {
+		It("renames the volume in the wrapped repository, counting it", func() {
+			fakeRepository.RenameVolumeReturns(volume.Volume{Handle: "new-handle"}, nil)
+
+			renamed, err := repo.RenameVolume("some-handle", "new-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed.Handle).To(Equal("new-handle"))
+
+			handle, newHandle := fakeRepository.RenameVolumeArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(newHandle).To(Equal("new-handle"))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_renamed_total 1\n"))
+		})
+
+		It("does not count a rename that fails", func() {
+			fakeRepository.RenameVolumeReturns(volume.Volume{}, volume.ErrVolumeAlreadyExists)
+
+			_, err := repo.RenameVolume("some-handle", "new-handle")
+			Expect(err).To(Equal(volume.ErrVolumeAlreadyExists))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_renamed_total 0\n"))
+		})
+	})
+
+	Describe("PurgeDeletedVolumes", func() {
+		It("counts each volume purged", func() {
+			fakeRepository.PurgeDeletedVolumesReturns(map[string]error{
+				"a": nil,
+				"b": errors.New("nope"),
+				"c": nil,
+			}, nil)
+
+			errs, err := repo.PurgeDeletedVolumes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(HaveLen(3))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_purged_total 2\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_destroyed_total 0\n"))
+		})
+
+		It("returns the error when the deleted volumes can't be listed", func() {
+			disaster := errors.New("nope")
+			fakeRepository.PurgeDeletedVolumesReturns(nil, disaster)
+
+			_, err := repo.PurgeDeletedVolumes()
+			Expect(err).To(Equal(disaster))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_purged_total 0\n"))
+		})
+	})
+
+	Describe("StreamIn", func() {
+		BeforeEach(func() {
+			fakeRepository.StreamInStub = func(ctx context.Context, handle string, path string, stream io.Reader, opts volume.StreamInOptions) (bool, error) {
+				fakeClock.Increment(30 * time.Second)
+				_, err := ioutil.ReadAll(stream)
+				return false, err
+			}
+		})
+
+		It("counts the bytes read from the stream and times the stream", func() {
+			_, err := repo.StreamIn(context.Background(), "some-handle", ".", strings.NewReader("some-data"), volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(written()).To(ContainSubstring("baggageclaim_streamed_in_bytes_total 9\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_stream_in_duration_seconds_bucket{le=\"60\"} 1\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_stream_in_duration_seconds_sum 30\n"))
+		})
+
+		Context("when the stream is bad", func() {
+			BeforeEach(func() {
+				fakeRepository.StreamInStub = func(ctx context.Context, handle string, path string, stream io.Reader, opts volume.StreamInOptions) (bool, error) {
+					ioutil.ReadAll(stream)
+					return true, errors.New("nope")
+				}
+			})
+
+			It("returns the failure, still counting the bytes read", func() {
+				badStream, err := repo.StreamIn(context.Background(), "some-handle", ".", strings.NewReader("bad"), volume.StreamInOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(badStream).To(BeTrue())
+
+				Expect(written()).To(ContainSubstring("baggageclaim_streamed_in_bytes_total 3\n"))
+				Expect(written()).To(ContainSubstring("baggageclaim_stream_in_duration_seconds_count 0\n"))
+			})
+		})
+	})
+
+	Describe("StreamOut", func() {
+		BeforeEach(func() {
+			fakeRepository.StreamOutStub = func(ctx context.Context, handle string, path string, dest io.Writer, opts volume.StreamOutOptions) error {
+				fakeClock.Increment(time.Second)
+				_, err := dest.Write([]byte("some-data"))
+				return err
+			}
+		})
+
+		It("writes to the destination, counting the bytes and timing the stream", func() {
+			dest := new(bytes.Buffer)
+
+			Expect(repo.StreamOut(context.Background(), "some-handle", ".", dest, volume.StreamOutOptions{})).To(Succeed())
+			Expect(dest.String()).To(Equal("some-data"))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_streamed_out_bytes_total 9\n"))
+			Expect(written()).To(ContainSubstring("baggageclaim_stream_out_duration_seconds_sum 1\n"))
+		})
+
+		It("counts the bytes of diffs streamed out too", func() {
+			fakeRepository.StreamOutDiffStub = func(handle string, baseHandle string, dest io.Writer) error {
+				_, err := dest.Write([]byte("diff"))
+				return err
+			}
+
+			Expect(repo.StreamOutDiff("some-handle", "base-handle", new(bytes.Buffer))).To(Succeed())
+
+			Expect(written()).To(ContainSubstring("baggageclaim_streamed_out_bytes_total 4\n"))
+		})
+	})
+
+	Describe("WithLogData", func() {
+		It("keeps counting what the tagged repository does", func() {
+			taggedRepository := new(volumefakes.FakeRepository)
+			fakeRepository.WithLogDataReturns(taggedRepository)
+
+			tagged := repo.WithLogData(lager.Data{"request-id": "some-request"})
+			Expect(fakeRepository.WithLogDataArgsForCall(0)).To(Equal(lager.Data{"request-id": "some-request"}))
+
+			Expect(tagged.DestroyVolume("some-handle", volume.DestroyOptions{})).To(Succeed())
+			Expect(taggedRepository.DestroyVolumeCallCount()).To(Equal(1))
+
+			Expect(written()).To(ContainSubstring("baggageclaim_volumes_destroyed_total 1\n"))
+		})
+	})
+
+	It("passes everything else through to the wrapped repository", func() {
+		fakeRepository.GetVolumeReturns(volume.Volume{Handle: "some-handle"}, true, nil)
+
+		found, ok, err := repo.GetVolume("some-handle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(found.Handle).To(Equal("some-handle"))
+	})
+})
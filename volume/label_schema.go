@@ -0,0 +1,40 @@
+package volume
+
+import "regexp"
+
+// LabelSchema restricts the values of the property with the same name. A
+// value is accepted if it is one of Values, or if it matches Pattern, which
+// must be anchored for all of the value to have to match it.
+type LabelSchema struct {
+	Values  []string
+	Pattern *regexp.Regexp
+}
+
+func (schema LabelSchema) Allows(value string) bool {
+	for _, allowed := range schema.Values {
+		if value == allowed {
+			return true
+		}
+	}
+
+	return schema.Pattern != nil && schema.Pattern.MatchString(value)
+}
+
+// LabelSchemas maps property names to their schema. Properties without a
+// schema are free-form.
+type LabelSchemas map[string]LabelSchema
+
+func (schemas LabelSchemas) Validate(properties Properties) error {
+	for name, value := range properties {
+		schema, found := schemas[name]
+		if !found {
+			continue
+		}
+
+		if !schema.Allows(value) {
+			return ErrInvalidPropertyValue
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package volume_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+var _ = Describe("LabelSchemas", func() {
+	var schemas volume.LabelSchemas
+
+	BeforeEach(func() {
+		schemas = volume.LabelSchemas{
+			"size-class": volume.LabelSchema{Values: []string{"small", "medium", "large"}},
+			"team":       volume.LabelSchema{Pattern: regexp.MustCompile(`^[a-z-]+$`)},
+		}
+	})
+
+	It("accepts values allowed by the schema", func() {
+		Expect(schemas.Validate(volume.Properties{"size-class": "medium", "team": "some-team"})).To(Succeed())
+	})
+
+	It("accepts any value for properties without a schema", func() {
+		Expect(schemas.Validate(volume.Properties{"anything": "Goes Here"})).To(Succeed())
+	})
+
+	It("rejects values outside of the allowed set", func() {
+		Expect(schemas.Validate(volume.Properties{"size-class": "smal"})).To(Equal(volume.ErrInvalidPropertyValue))
+	})
+
+	It("rejects values not matching the pattern", func() {
+		Expect(schemas.Validate(volume.Properties{"team": "Some Team"})).To(Equal(volume.ErrInvalidPropertyValue))
+	})
+
+	It("accepts everything when no schemas are registered", func() {
+		Expect(volume.LabelSchemas(nil).Validate(volume.Properties{"size-class": "bogus"})).To(Succeed())
+	})
+})
@@ -0,0 +1,155 @@
+package volume
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrVolumeIsLeased = errors.New("volume is leased")
+var ErrLeaseNotHeld = errors.New("lease is not held")
+var ErrInvalidLeaseDuration = errors.New("lease duration must be positive")
+
+// Lease is an advisory lease on a volume. While it is held, stream-ins,
+// property changes, and destroys of the volume fail with ErrVolumeIsLeased
+// unless they are given its token. It is gone once it expires, so that a
+// holder that goes away doesn't keep the volume leased.
+type Lease struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// leaseTable keeps the leases on volumes by their handles. It is only held
+// in memory, so that the leases are all gone once the server restarts
+// rather than wait out their time.
+type leaseTable struct {
+	lock   sync.Mutex
+	leases map[string]Lease
+}
+
+func newLeaseTable() *leaseTable {
+	return &leaseTable{
+		leases: map[string]Lease{},
+	}
+}
+
+// Acquire takes out a new lease on the volume without a token, or extends
+// the one held with it. It returns ErrVolumeIsLeased while another lease is
+// held, and ErrLeaseNotHeld if the token's lease has expired or been
+// released.
+func (table *leaseTable) Acquire(handle string, token string, now time.Time, duration time.Duration) (Lease, error) {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+
+	held, isHeld := table.held(handle, now)
+
+	switch {
+	case isHeld && held.Token != token:
+		return Lease{}, ErrVolumeIsLeased
+
+	case !isHeld && token != "":
+		return Lease{}, ErrLeaseNotHeld
+
+	case token == "":
+		var err error
+		token, err = newLeaseToken()
+		if err != nil {
+			return Lease{}, err
+		}
+	}
+
+	lease := Lease{
+		Token:     token,
+		ExpiresAt: now.Add(duration),
+	}
+
+	table.leases[handle] = lease
+
+	return lease, nil
+}
+
+// Release gives up the lease held with the token. Releasing a lease that
+// has already expired or been released does nothing; one held with another
+// token is left alone, returning ErrVolumeIsLeased.
+func (table *leaseTable) Release(handle string, token string, now time.Time) error {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+
+	held, isHeld := table.held(handle, now)
+	if !isHeld {
+		return nil
+	}
+
+	if held.Token != token {
+		return ErrVolumeIsLeased
+	}
+
+	delete(table.leases, handle)
+
+	return nil
+}
+
+// Check returns ErrVolumeIsLeased if a lease is held on the volume with
+// another token than the one given.
+func (table *leaseTable) Check(handle string, token string, now time.Time) error {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+
+	held, isHeld := table.held(handle, now)
+	if isHeld && held.Token != token {
+		return ErrVolumeIsLeased
+	}
+
+	return nil
+}
+
+// Remove forgets the lease on a volume that is gone.
+func (table *leaseTable) Remove(handle string) {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+
+	delete(table.leases, handle)
+}
+
+// Rename moves the lease on the volume, if any, over to its new handle.
+func (table *leaseTable) Rename(handle string, newHandle string) {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+
+	lease, found := table.leases[handle]
+	if !found {
+		return
+	}
+
+	delete(table.leases, handle)
+	table.leases[newHandle] = lease
+}
+
+// held returns the lease on the volume unless it has expired, forgetting it
+// if it has.
+func (table *leaseTable) held(handle string, now time.Time) (Lease, bool) {
+	lease, found := table.leases[handle]
+	if !found {
+		return Lease{}, false
+	}
+
+	if !now.Before(lease.ExpiresAt) {
+		delete(table.leases, handle)
+		return Lease{}, false
+	}
+
+	return lease, true
+}
+
+func newLeaseToken() (string, error) {
+	token := make([]byte, 16)
+
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
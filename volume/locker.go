@@ -2,6 +2,8 @@ package volume
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -59,3 +61,90 @@ func (m *lockManager) Unlock(key string) {
 	m.mutex.Unlock()
 	<-entry.ch
 }
+
+//go:generate counterfeiter . PathLockManager
+
+// PathLockManager grants locks on sub-paths of a volume. Locks on paths that
+// do not overlap (neither is an ancestor of the other) are held concurrently,
+// while overlapping locks are serialized. The empty path is the volume root
+// and overlaps every other path.
+type PathLockManager interface {
+	Lock(handle string, path string)
+	Unlock(handle string, path string)
+}
+
+type pathLockManager struct {
+	held  map[string][]string
+	mutex sync.Mutex
+	cond  *sync.Cond
+}
+
+func NewPathLockManager() PathLockManager {
+	m := &pathLockManager{
+		held: map[string][]string{},
+	}
+
+	m.cond = sync.NewCond(&m.mutex)
+
+	return m
+}
+
+func (m *pathLockManager) Lock(handle string, path string) {
+	path = normalizeSubPath(path)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for m.overlapsHeld(handle, path) {
+		m.cond.Wait()
+	}
+
+	m.held[handle] = append(m.held[handle], path)
+}
+
+func (m *pathLockManager) Unlock(handle string, path string) {
+	path = normalizeSubPath(path)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	paths := m.held[handle]
+	for i, heldPath := range paths {
+		if heldPath == path {
+			paths = append(paths[:i], paths[i+1:]...)
+			break
+		}
+	}
+
+	if len(paths) == 0 {
+		delete(m.held, handle)
+	} else {
+		m.held[handle] = paths
+	}
+
+	m.cond.Broadcast()
+}
+
+func (m *pathLockManager) overlapsHeld(handle string, path string) bool {
+	for _, heldPath := range m.held[handle] {
+		if pathsOverlap(heldPath, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathsOverlap(a string, b string) bool {
+	if a == "" || b == "" || a == b {
+		return true
+	}
+
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// normalizeSubPath turns a sub-path of a volume into a clean, slash-separated
+// relative path, with the volume root being the empty string.
+func normalizeSubPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+path)), "/")
+}
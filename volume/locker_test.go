@@ -67,3 +67,72 @@ var _ = Describe("KeyedLock", func() {
 		})
 	})
 })
+
+var _ = Describe("PathLock", func() {
+	var pathLockManager volume.PathLockManager
+
+	BeforeEach(func() {
+		pathLockManager = volume.NewPathLockManager()
+	})
+
+	lockInBackground := func(handle string, path string) chan struct{} {
+		lockedCh := make(chan struct{})
+		go func() {
+			pathLockManager.Lock(handle, path)
+			close(lockedCh)
+		}()
+		return lockedCh
+	}
+
+	Describe("Lock", func() {
+		Context("when a disjoint path of the same volume is locked", func() {
+			It("allows access concurrently", func() {
+				pathLockManager.Lock("some-handle", "a")
+				Eventually(lockInBackground("some-handle", "b")).Should(BeClosed())
+			})
+		})
+
+		Context("when the same path of another volume is locked", func() {
+			It("allows access concurrently", func() {
+				pathLockManager.Lock("some-handle", "a")
+				Eventually(lockInBackground("other-handle", "a")).Should(BeClosed())
+			})
+		})
+
+		Context("when a sibling with a common name prefix is locked", func() {
+			It("allows access concurrently", func() {
+				pathLockManager.Lock("some-handle", "a")
+				Eventually(lockInBackground("some-handle", "ab")).Should(BeClosed())
+			})
+		})
+
+		overlapping := []struct {
+			description string
+			held        string
+			requested   string
+		}{
+			{"the same path", "a", "a"},
+			{"the same path spelled differently", "a/b", "./a//b/"},
+			{"a descendant", "a", "a/b"},
+			{"an ancestor", "a/b", "a"},
+			{"the volume root", "", "a"},
+			{"anything below the root", "a", "/"},
+		}
+
+		for _, example := range overlapping {
+			example := example
+
+			Context("when "+example.description+" is locked", func() {
+				It("blocks until it is unlocked", func() {
+					pathLockManager.Lock("some-handle", example.held)
+
+					lockedCh := lockInBackground("some-handle", example.requested)
+					Consistently(lockedCh).ShouldNot(BeClosed())
+
+					pathLockManager.Unlock("some-handle", example.held)
+					Eventually(lockedCh).Should(BeClosed())
+				})
+			})
+		}
+	})
+})
@@ -0,0 +1,121 @@
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type ManifestType string
+
+const (
+	ManifestDirectory ManifestType = "directory"
+	ManifestFile      ManifestType = "file"
+	ManifestSymlink   ManifestType = "symlink"
+	ManifestOther     ManifestType = "other"
+)
+
+// ManifestEntry describes a path in a volume, for a client to tell which of
+// its own files differ from the volume's before streaming in only those.
+type ManifestEntry struct {
+	Path    string       `json:"path"`
+	Type    ManifestType `json:"type"`
+	Mode    os.FileMode  `json:"mode"`
+	Size    int64        `json:"size,omitempty"`
+	ModTime time.Time    `json:"mtime"`
+
+	// SHA256 is the hex digest of a file's contents.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Target is where a symlink points.
+	Target string `json:"target,omitempty"`
+}
+
+// FileEntry describes a path in a volume as it is listed, without reading
+// its contents.
+type FileEntry struct {
+	Path    string       `json:"path"`
+	Type    ManifestType `json:"type"`
+	Mode    os.FileMode  `json:"mode"`
+	Size    int64        `json:"size,omitempty"`
+	ModTime time.Time    `json:"mtime"`
+
+	// Target is where a symlink points.
+	Target string `json:"target,omitempty"`
+}
+
+// walkManifest calls emit for everything under root in lexical order, with
+// slash-separated paths relative to it. The volume's digest is made from the
+// same entries, so a delta that leaves every entry as the manifest shows it
+// digests the same as the full stream.
+func walkManifest(root string, emit func(ManifestEntry) error) error {
+	return walkFiles(root, func(path string, file FileEntry) error {
+		entry := ManifestEntry{
+			Path:    file.Path,
+			Type:    file.Type,
+			Mode:    file.Mode,
+			Size:    file.Size,
+			ModTime: file.ModTime,
+			Target:  file.Target,
+		}
+
+		if entry.Type == ManifestFile {
+			var err error
+			entry.SHA256, err = fileDigest(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		return emit(entry)
+	})
+}
+
+// walkFiles calls emit for everything under root in lexical order, with the
+// path to it and its entry.
+func walkFiles(root string, emit func(string, FileEntry) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		mode := info.Mode()
+
+		entry := FileEntry{
+			Path:    filepath.ToSlash(rel),
+			Mode:    mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky),
+			ModTime: info.ModTime(),
+		}
+
+		switch {
+		case mode.IsDir():
+			entry.Type = ManifestDirectory
+
+		case mode.IsRegular():
+			entry.Type = ManifestFile
+			entry.Size = info.Size()
+
+		case mode&os.ModeSymlink != 0:
+			entry.Type = ManifestSymlink
+
+			entry.Target, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+		default:
+			entry.Type = ManifestOther
+		}
+
+		return emit(path, entry)
+	})
+}
@@ -2,6 +2,7 @@ package volume
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,6 +14,20 @@ const (
 	propertiesFileName   = "properties.json"
 	ttlFileName          = "ttl.json"
 	isPrivilegedFileName = "privileged.json"
+	committedFileName    = "committed.json"
+	accessedFileName     = "accessed.json"
+	createdFileName      = "created.json"
+	modifiedFileName     = "modified.json"
+	digestFileName       = "digest.json"
+	streamInsFileName    = "stream-ins.json"
+	createKeyFileName    = "create-key.json"
+	releasedFileName     = "released.json"
+	readOnlyFileName     = "read-only.json"
+	backingFileName      = "backing.json"
+	mountOptionsFileName = "mount-options.json"
+	ttlRenewalFileName   = "ttl-renewal.json"
+	encryptionFileName   = "encryption.json"
+	deletedFileName      = "deleted.json"
 )
 
 type Metadata struct {
@@ -65,6 +80,30 @@ func (md *Metadata) StoreTTL(ttl TTL) (time.Time, error) {
 	return md.ttlFile().WriteTTL(ttl)
 }
 
+// StoreExpiry records the TTL along with when it expires, rather than
+// counting the TTL from now.
+func (md *Metadata) StoreExpiry(ttl TTL, expiresAt time.Time) error {
+	return md.ttlFile().WriteExpiry(ttl, expiresAt)
+}
+
+// TTL Renewal File
+func (md *Metadata) RenewTTLOnAccess() (bool, error) {
+	properties, err := md.ttlRenewalFile().Properties()
+	if err != nil {
+		return false, err
+	}
+
+	return properties.OnAccess, nil
+}
+
+func (md *Metadata) StoreRenewTTLOnAccess() error {
+	return md.ttlRenewalFile().WriteRenewOnAccess()
+}
+
+func (md *Metadata) ttlRenewalFile() *ttlRenewalFile {
+	return &ttlRenewalFile{path: filepath.Join(md.path, ttlRenewalFileName)}
+}
+
 func (md *Metadata) isPrivilegedFile() *isPrivilegedFile {
 	return &isPrivilegedFile{path: filepath.Join(md.path, isPrivilegedFileName)}
 }
@@ -77,6 +116,291 @@ func (md *Metadata) StorePrivileged(isPrivileged bool) error {
 	return md.isPrivilegedFile().WritePrivileged(isPrivileged)
 }
 
+// Committed File
+func (md *Metadata) Committed() (time.Time, bool, error) {
+	properties, err := md.committedFile().Properties()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if properties.CommittedAt == 0 {
+		return time.Time{}, properties.Frozen, nil
+	}
+
+	return time.Unix(properties.CommittedAt, 0), properties.Frozen, nil
+}
+
+func (md *Metadata) StoreCommitted(frozen bool) (time.Time, error) {
+	return md.committedFile().WriteCommitted(frozen)
+}
+
+func (md *Metadata) committedFile() *committedFile {
+	return &committedFile{path: filepath.Join(md.path, committedFileName)}
+}
+
+// Accessed File
+func (md *Metadata) LastAccessed() (time.Time, error) {
+	properties, err := md.accessedFile().Properties()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if properties.LastAccessedAt == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(properties.LastAccessedAt, 0), nil
+}
+
+func (md *Metadata) StoreLastAccessed() (time.Time, error) {
+	return md.accessedFile().WriteLastAccessed()
+}
+
+func (md *Metadata) accessedFile() *accessedFile {
+	return &accessedFile{path: filepath.Join(md.path, accessedFileName)}
+}
+
+// Created File
+func (md *Metadata) Created() (time.Time, string, error) {
+	properties, err := md.createdFile().Properties()
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if properties.CreatedAt == 0 {
+		return time.Time{}, properties.Strategy, nil
+	}
+
+	return time.Unix(properties.CreatedAt, 0), properties.Strategy, nil
+}
+
+func (md *Metadata) StoreCreated(strategy string) (time.Time, error) {
+	return md.createdFile().WriteCreated(strategy)
+}
+
+func (md *Metadata) createdFile() *createdFile {
+	return &createdFile{path: filepath.Join(md.path, createdFileName)}
+}
+
+// Modified File
+func (md *Metadata) Modified() (time.Time, error) {
+	properties, err := md.modifiedFile().Properties()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if properties.ModifiedAt == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(properties.ModifiedAt, 0), nil
+}
+
+func (md *Metadata) StoreModified() (time.Time, error) {
+	return md.modifiedFile().WriteModified()
+}
+
+func (md *Metadata) modifiedFile() *modifiedFile {
+	return &modifiedFile{path: filepath.Join(md.path, modifiedFileName)}
+}
+
+// Digest File
+func (md *Metadata) Digest() (string, error) {
+	properties, err := md.digestFile().Properties()
+	if err != nil {
+		return "", err
+	}
+
+	return properties.Digest, nil
+}
+
+func (md *Metadata) StoreDigest(digest string) error {
+	return md.digestFile().WriteDigest(digest)
+}
+
+func (md *Metadata) digestFile() *digestFile {
+	return &digestFile{path: filepath.Join(md.path, digestFileName)}
+}
+
+// Stream-ins File
+func (md *Metadata) StreamInKeys() (map[string]time.Time, error) {
+	properties, err := md.streamInsFile().Properties()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]time.Time, len(properties))
+	for key, appliedAt := range properties {
+		keys[key] = time.Unix(appliedAt, 0)
+	}
+
+	return keys, nil
+}
+
+func (md *Metadata) StoreStreamInKeys(keys map[string]time.Time) error {
+	return md.streamInsFile().WriteStreamInKeys(keys)
+}
+
+func (md *Metadata) streamInsFile() *streamInsFile {
+	return &streamInsFile{path: filepath.Join(md.path, streamInsFileName)}
+}
+
+// Create Key File
+func (md *Metadata) CreateKey() (string, string, error) {
+	properties, err := md.createKeyFile().Properties()
+	if err != nil {
+		return "", "", err
+	}
+
+	return properties.Key, properties.Fingerprint, nil
+}
+
+func (md *Metadata) StoreCreateKey(key string, fingerprint string) error {
+	return md.createKeyFile().WriteCreateKey(key, fingerprint)
+}
+
+func (md *Metadata) createKeyFile() *createKeyFile {
+	return &createKeyFile{path: filepath.Join(md.path, createKeyFileName)}
+}
+
+// Released File
+func (md *Metadata) Released() (DestroyOptions, bool, error) {
+	properties, err := md.releasedFile().Properties()
+	if err != nil {
+		return DestroyOptions{}, false, err
+	}
+
+	if properties == nil {
+		return DestroyOptions{}, false, nil
+	}
+
+	return DestroyOptions{
+		Reason:     properties.Reason,
+		Annotation: properties.Annotation,
+	}, true, nil
+}
+
+func (md *Metadata) StoreReleased(opts DestroyOptions) error {
+	return md.releasedFile().WriteReleased(opts)
+}
+
+func (md *Metadata) releasedFile() *releasedFile {
+	return &releasedFile{path: filepath.Join(md.path, releasedFileName)}
+}
+
+// Deleted File
+//
+// Deleted returns the destroy the volume is kept around after, and when it
+// was, if it is deleted.
+func (md *Metadata) Deleted() (DestroyOptions, time.Time, bool, error) {
+	properties, err := md.deletedFile().Properties()
+	if err != nil {
+		return DestroyOptions{}, time.Time{}, false, err
+	}
+
+	if properties == nil {
+		return DestroyOptions{}, time.Time{}, false, nil
+	}
+
+	return DestroyOptions{
+		Reason:     properties.Reason,
+		Annotation: properties.Annotation,
+	}, properties.DeletedAt, true, nil
+}
+
+func (md *Metadata) StoreDeleted(opts DestroyOptions, deletedAt time.Time) error {
+	return md.deletedFile().WriteDeleted(opts, deletedAt)
+}
+
+func (md *Metadata) ClearDeleted() error {
+	return md.deletedFile().Clear()
+}
+
+func (md *Metadata) deletedFile() *deletedFile {
+	return &deletedFile{path: filepath.Join(md.path, deletedFileName)}
+}
+
+// Backing File
+func (md *Metadata) Backing() (string, string, error) {
+	properties, err := md.backingFile().Properties()
+	if err != nil {
+		return "", "", err
+	}
+
+	return properties.Driver, properties.FilesystemType, nil
+}
+
+func (md *Metadata) StoreBacking(driver string, filesystemType string) error {
+	return md.backingFile().WriteBacking(driver, filesystemType)
+}
+
+func (md *Metadata) backingFile() *backingFile {
+	return &backingFile{path: filepath.Join(md.path, backingFileName)}
+}
+
+// Read-only File
+func (md *Metadata) ReadOnly() (bool, error) {
+	properties, err := md.readOnlyFile().Properties()
+	if err != nil {
+		return false, err
+	}
+
+	return properties.ReadOnly, nil
+}
+
+func (md *Metadata) StoreReadOnly() error {
+	return md.readOnlyFile().WriteReadOnly()
+}
+
+func (md *Metadata) readOnlyFile() *readOnlyFile {
+	return &readOnlyFile{path: filepath.Join(md.path, readOnlyFileName)}
+}
+
+// Mount Options File
+func (md *Metadata) MountOptions() ([]string, error) {
+	properties, err := md.mountOptionsFile().Properties()
+	if err != nil {
+		return nil, err
+	}
+
+	return properties.MountOptions, nil
+}
+
+func (md *Metadata) StoreMountOptions(options []string) error {
+	return md.mountOptionsFile().WriteMountOptions(options)
+}
+
+func (md *Metadata) mountOptionsFile() *mountOptionsFile {
+	return &mountOptionsFile{path: filepath.Join(md.path, mountOptionsFileName)}
+}
+
+// Encryption File
+//
+// Encryption returns the handle and salt the volume's key was derived with,
+// and whether it is encrypted at all.
+func (md *Metadata) Encryption() (string, []byte, bool, error) {
+	properties, err := md.encryptionFile().Properties()
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	return properties.Handle, properties.Salt, len(properties.Salt) > 0, nil
+}
+
+func (md *Metadata) StoreEncryption(handle string, salt []byte) error {
+	return md.encryptionFile().WriteEncryption(handle, salt)
+}
+
+// ShredEncryption overwrites the volume's salt before removing it, so that
+// its key can't be derived again.
+func (md *Metadata) ShredEncryption() error {
+	return md.encryptionFile().Shred()
+}
+
+func (md *Metadata) encryptionFile() *encryptionFile {
+	return &encryptionFile{path: filepath.Join(md.path, encryptionFileName)}
+}
+
 func (md *Metadata) ExpiresAt() (time.Time, error) {
 	properties, err := md.ttlFile().Properties()
 	if err != nil {
@@ -100,17 +424,21 @@ type ttlProperties struct {
 }
 
 func (tf *ttlFile) WriteTTL(ttl TTL) (time.Time, error) {
-	expiresAt := time.Now().Add(ttl.Duration()).Unix()
+	expiresAt := time.Unix(time.Now().Add(ttl.Duration()).Unix(), 0)
 
-	err := writeMetadataFile(tf.path, ttlProperties{
-		TTL:       ttl,
-		ExpiresAt: expiresAt,
-	})
+	err := tf.WriteExpiry(ttl, expiresAt)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	return time.Unix(expiresAt, 0), nil
+	return expiresAt, nil
+}
+
+func (tf *ttlFile) WriteExpiry(ttl TTL, expiresAt time.Time) error {
+	return writeMetadataFile(tf.path, ttlProperties{
+		TTL:       ttl,
+		ExpiresAt: expiresAt.Unix(),
+	})
 }
 
 func (tf *ttlFile) Properties() (ttlProperties, error) {
@@ -123,6 +451,32 @@ func (tf *ttlFile) Properties() (ttlProperties, error) {
 	return properties, nil
 }
 
+type ttlRenewalFile struct {
+	path string
+}
+
+type ttlRenewalProperties struct {
+	OnAccess bool `json:"on_access"`
+}
+
+func (trf *ttlRenewalFile) WriteRenewOnAccess() error {
+	return writeMetadataFile(trf.path, ttlRenewalProperties{
+		OnAccess: true,
+	})
+}
+
+// Properties returns the zero value for volumes whose TTL is only renewed by
+// setting it.
+func (trf *ttlRenewalFile) Properties() (ttlRenewalProperties, error) {
+	var properties ttlRenewalProperties
+	err := readOptionalMetadataFile(trf.path, &properties)
+	if err != nil {
+		return ttlRenewalProperties{}, err
+	}
+
+	return properties, nil
+}
+
 type isPrivilegedFile struct {
 	path string
 }
@@ -142,6 +496,443 @@ func (ipf *isPrivilegedFile) IsPrivileged() (bool, error) {
 	return isPrivileged, nil
 }
 
+type committedFile struct {
+	path string
+}
+
+type committedProperties struct {
+	CommittedAt int64 `json:"committed_at"`
+	Frozen      bool  `json:"frozen"`
+}
+
+func (cf *committedFile) WriteCommitted(frozen bool) (time.Time, error) {
+	committedAt := time.Now().Unix()
+
+	err := writeMetadataFile(cf.path, committedProperties{
+		CommittedAt: committedAt,
+		Frozen:      frozen,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(committedAt, 0), nil
+}
+
+// Properties returns the zero value for volumes that have never been
+// committed, including volumes created before commits were recorded.
+func (cf *committedFile) Properties() (committedProperties, error) {
+	var properties committedProperties
+	err := readOptionalMetadataFile(cf.path, &properties)
+	if err != nil {
+		return committedProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type accessedFile struct {
+	path string
+}
+
+type accessedProperties struct {
+	LastAccessedAt int64 `json:"last_accessed_at"`
+}
+
+func (af *accessedFile) WriteLastAccessed() (time.Time, error) {
+	lastAccessedAt := time.Now().Unix()
+
+	err := writeMetadataFile(af.path, accessedProperties{
+		LastAccessedAt: lastAccessedAt,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(lastAccessedAt, 0), nil
+}
+
+// Properties returns the zero value for volumes that have never been
+// accessed.
+func (af *accessedFile) Properties() (accessedProperties, error) {
+	var properties accessedProperties
+	err := readOptionalMetadataFile(af.path, &properties)
+	if err != nil {
+		return accessedProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type createdFile struct {
+	path string
+}
+
+type createdProperties struct {
+	CreatedAt int64  `json:"created_at"`
+	Strategy  string `json:"strategy"`
+}
+
+func (cf *createdFile) WriteCreated(strategy string) (time.Time, error) {
+	createdAt := time.Now().Unix()
+
+	err := writeMetadataFile(cf.path, createdProperties{
+		CreatedAt: createdAt,
+		Strategy:  strategy,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(createdAt, 0), nil
+}
+
+// Properties returns the zero value for volumes created before their
+// creation was recorded.
+func (cf *createdFile) Properties() (createdProperties, error) {
+	var properties createdProperties
+	err := readOptionalMetadataFile(cf.path, &properties)
+	if err != nil {
+		return createdProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type modifiedFile struct {
+	path string
+}
+
+type modifiedProperties struct {
+	ModifiedAt int64 `json:"modified_at"`
+}
+
+func (mf *modifiedFile) WriteModified() (time.Time, error) {
+	modifiedAt := time.Now().Unix()
+
+	err := writeMetadataFile(mf.path, modifiedProperties{
+		ModifiedAt: modifiedAt,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(modifiedAt, 0), nil
+}
+
+// Properties returns the zero value for volumes that have not been modified
+// since they were created, or not since their modifications were recorded.
+func (mf *modifiedFile) Properties() (modifiedProperties, error) {
+	var properties modifiedProperties
+	err := readOptionalMetadataFile(mf.path, &properties)
+	if err != nil {
+		return modifiedProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type digestFile struct {
+	path string
+}
+
+type digestProperties struct {
+	Digest string `json:"digest"`
+}
+
+func (df *digestFile) WriteDigest(digest string) error {
+	return writeMetadataFile(df.path, digestProperties{
+		Digest: digest,
+	})
+}
+
+// Properties returns the zero value for volumes that have never been
+// streamed in to.
+func (df *digestFile) Properties() (digestProperties, error) {
+	var properties digestProperties
+	err := readOptionalMetadataFile(df.path, &properties)
+	if err != nil {
+		return digestProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type streamInsFile struct {
+	path string
+}
+
+// streamInsProperties maps idempotency keys to when the stream-in carrying
+// them was applied.
+type streamInsProperties map[string]int64
+
+func (sf *streamInsFile) WriteStreamInKeys(keys map[string]time.Time) error {
+	properties := make(streamInsProperties, len(keys))
+	for key, appliedAt := range keys {
+		properties[key] = appliedAt.Unix()
+	}
+
+	return writeMetadataFile(sf.path, properties)
+}
+
+// Properties returns no keys for volumes that have never been streamed into
+// with an idempotency key.
+func (sf *streamInsFile) Properties() (streamInsProperties, error) {
+	var properties streamInsProperties
+	err := readOptionalMetadataFile(sf.path, &properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+type createKeyFile struct {
+	path string
+}
+
+// createKeyProperties records the idempotency key the volume was created
+// with, along with the fingerprint of what it was asked to be created as.
+type createKeyProperties struct {
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func (cf *createKeyFile) WriteCreateKey(key string, fingerprint string) error {
+	return writeMetadataFile(cf.path, createKeyProperties{
+		Key:         key,
+		Fingerprint: fingerprint,
+	})
+}
+
+// Properties returns no key for volumes that were created without one.
+func (cf *createKeyFile) Properties() (createKeyProperties, error) {
+	var properties createKeyProperties
+	err := readOptionalMetadataFile(cf.path, &properties)
+	if err != nil {
+		return createKeyProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type releasedFile struct {
+	path string
+}
+
+// releasedProperties records the destroy that was deferred because the
+// volume still has views, to be carried out once the last view is gone.
+type releasedProperties struct {
+	Reason     DestroyReason `json:"reason"`
+	Annotation string        `json:"annotation,omitempty"`
+}
+
+func (rf *releasedFile) WriteReleased(opts DestroyOptions) error {
+	return writeMetadataFile(rf.path, releasedProperties{
+		Reason:     opts.Reason,
+		Annotation: opts.Annotation,
+	})
+}
+
+// Properties returns nil for volumes that have not been released.
+func (rf *releasedFile) Properties() (*releasedProperties, error) {
+	var properties *releasedProperties
+	err := readOptionalMetadataFile(rf.path, &properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+type deletedFile struct {
+	path string
+}
+
+// deletedProperties records the destroy that a volume is kept around after
+// until it is purged, or restored.
+type deletedProperties struct {
+	Reason     DestroyReason `json:"reason"`
+	Annotation string        `json:"annotation,omitempty"`
+	DeletedAt  time.Time     `json:"deleted_at"`
+}
+
+func (df *deletedFile) WriteDeleted(opts DestroyOptions, deletedAt time.Time) error {
+	return writeMetadataFile(df.path, deletedProperties{
+		Reason:     opts.Reason,
+		Annotation: opts.Annotation,
+		DeletedAt:  deletedAt,
+	})
+}
+
+// Properties returns nil for volumes that are not deleted.
+func (df *deletedFile) Properties() (*deletedProperties, error) {
+	var properties *deletedProperties
+	err := readOptionalMetadataFile(df.path, &properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+func (df *deletedFile) Clear() error {
+	err := os.Remove(df.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+type backingFile struct {
+	path string
+}
+
+type backingProperties struct {
+	Driver         string `json:"driver"`
+	FilesystemType string `json:"filesystem_type"`
+}
+
+func (bf *backingFile) WriteBacking(driver string, filesystemType string) error {
+	return writeMetadataFile(bf.path, backingProperties{
+		Driver:         driver,
+		FilesystemType: filesystemType,
+	})
+}
+
+// Properties returns the zero value for volumes created before their backing
+// was recorded.
+func (bf *backingFile) Properties() (backingProperties, error) {
+	var properties backingProperties
+	err := readOptionalMetadataFile(bf.path, &properties)
+	if err != nil {
+		return backingProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type readOnlyFile struct {
+	path string
+}
+
+type readOnlyProperties struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+func (rof *readOnlyFile) WriteReadOnly() error {
+	return writeMetadataFile(rof.path, readOnlyProperties{
+		ReadOnly: true,
+	})
+}
+
+// Properties returns the zero value for volumes that were not made read-only.
+func (rof *readOnlyFile) Properties() (readOnlyProperties, error) {
+	var properties readOnlyProperties
+	err := readOptionalMetadataFile(rof.path, &properties)
+	if err != nil {
+		return readOnlyProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type mountOptionsFile struct {
+	path string
+}
+
+type mountOptionsProperties struct {
+	MountOptions []string `json:"mount_options"`
+}
+
+func (mof *mountOptionsFile) WriteMountOptions(options []string) error {
+	return writeMetadataFile(mof.path, mountOptionsProperties{
+		MountOptions: options,
+	})
+}
+
+// Properties returns the zero value for volumes that were not given mount
+// options.
+func (mof *mountOptionsFile) Properties() (mountOptionsProperties, error) {
+	var properties mountOptionsProperties
+	err := readOptionalMetadataFile(mof.path, &properties)
+	if err != nil {
+		return mountOptionsProperties{}, err
+	}
+
+	return properties, nil
+}
+
+type encryptionFile struct {
+	path string
+}
+
+type encryptionProperties struct {
+	Handle string `json:"handle"`
+	Salt   []byte `json:"salt"`
+}
+
+func (ef *encryptionFile) WriteEncryption(handle string, salt []byte) error {
+	return writeMetadataFile(ef.path, encryptionProperties{
+		Handle: handle,
+		Salt:   salt,
+	})
+}
+
+// Properties returns the zero value for volumes that are not encrypted.
+func (ef *encryptionFile) Properties() (encryptionProperties, error) {
+	var properties encryptionProperties
+	err := readOptionalMetadataFile(ef.path, &properties)
+	if err != nil {
+		return encryptionProperties{}, err
+	}
+
+	return properties, nil
+}
+
+// Shred overwrites the file where it is, which the file having been written
+// once and renamed into place means is where the salt was written, and syncs
+// it before removing it.
+func (ef *encryptionFile) Shred() error {
+	file, err := os.OpenFile(ef.path, os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err == nil {
+		_, err = file.Write(make([]byte, info.Size()))
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(ef.path)
+}
+
+func readOptionalMetadataFile(path string, properties interface{}) error {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return readMetadataFile(path, properties)
+}
+
 func readMetadataFile(path string, properties interface{}) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -160,12 +951,10 @@ func readMetadataFile(path string, properties interface{}) error {
 	return nil
 }
 
+// writeMetadataFile replaces the file atomically, so that concurrent readers
+// see either the old or the new contents but never a partial write.
 func writeMetadataFile(path string, properties interface{}) error {
-	file, err := os.OpenFile(
-		path,
-		os.O_WRONLY|os.O_CREATE,
-		0644,
-	)
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
 	if err != nil {
 		if _, ok := err.(*os.PathError); ok {
 			return ErrVolumeDoesNotExist
@@ -174,7 +963,29 @@ func writeMetadataFile(path string, properties interface{}) error {
 		return err
 	}
 
-	defer file.Close()
+	err = json.NewEncoder(file).Encode(properties)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+
+	// the rename makes the new contents visible all at once, but only once
+	// they are on disk can a crash not leave the file empty in their place
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
 
-	return json.NewEncoder(file).Encode(properties)
+	return nil
 }
@@ -0,0 +1,80 @@
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/concourse/baggageclaim"
+)
+
+// MountOptionsError lists what is wrong with the mount options a volume was
+// asked to be given.
+type MountOptionsError struct {
+	Fields []baggageclaim.FieldError
+}
+
+func (err MountOptionsError) Error() string {
+	problems := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		problems[i] = field.Message
+	}
+
+	return "invalid mount options: " + strings.Join(problems, "; ")
+}
+
+func (err *MountOptionsError) add(code string, format string, args ...interface{}) {
+	err.Fields = append(err.Fields, baggageclaim.FieldError{
+		Field:   "mount_options",
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkMountOptions returns the options without repeats, or an error listing
+// each one that is not allowed, or that sets something an earlier one set to
+// something else, e.g. compress=zstd after compress=lzo.
+func checkMountOptions(options []string, allowed []string) ([]string, error) {
+	isAllowed := map[string]bool{}
+	for _, option := range allowed {
+		isAllowed[option] = true
+	}
+
+	var invalid MountOptionsError
+
+	checked := []string{}
+	seen := map[string]bool{}
+	setBy := map[string]string{}
+
+	for _, option := range options {
+		if seen[option] {
+			continue
+		}
+
+		seen[option] = true
+
+		if !isAllowed[option] {
+			invalid.add(baggageclaim.FieldErrorNotAllowed, "%q is not one of %s", option, strings.Join(allowed, ", "))
+			continue
+		}
+
+		if i := strings.Index(option, "="); i != -1 {
+			key := option[:i]
+
+			earlier, found := setBy[key]
+			if found {
+				invalid.add(baggageclaim.FieldErrorConflict, "%q conflicts with %q", option, earlier)
+				continue
+			}
+
+			setBy[key] = option
+		}
+
+		checked = append(checked, option)
+	}
+
+	if len(invalid.Fields) > 0 {
+		return nil, invalid
+	}
+
+	return checked, nil
+}
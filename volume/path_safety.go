@@ -0,0 +1,85 @@
+package volume
+
+import (
+	"archive/tar"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnsafeSubPath = errors.New("sub-path would be outside of the volume")
+
+// within tells whether path is root or lies beneath it. Both must be clean.
+func within(root string, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// resolvesWithin tells whether path, with any symlinks already on disk
+// followed, stays beneath root. The parts of path that don't exist yet are
+// taken as they are.
+func resolvesWithin(root string, path string) (bool, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false, err
+	}
+
+	existing := path
+	for {
+		_, err := os.Lstat(existing)
+		if err == nil {
+			break
+		}
+
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return false, nil
+		}
+
+		existing = parent
+	}
+
+	realPath, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return false, err
+	}
+
+	return within(realRoot, realPath), nil
+}
+
+// checkTarEntry returns ErrUnsafeTarEntry if extracting the entry into dest
+// would write outside of it, or if it is a symlink pointing outside of the
+// volume at root. The absolute symlinks of a layer are taken to point into
+// dest, which is the root of the image.
+func checkTarEntry(header *tar.Header, dest string, root string, layer bool) error {
+	path := filepath.Join(dest, header.Name)
+	if !within(dest, path) {
+		return ErrUnsafeTarEntry
+	}
+
+	switch header.Typeflag {
+	case tar.TypeLink:
+		// hard links are named relative to the archive's root
+		if !within(dest, filepath.Join(dest, header.Linkname)) {
+			return ErrUnsafeTarEntry
+		}
+
+	case tar.TypeSymlink:
+		target := header.Linkname
+		if layer && filepath.IsAbs(target) {
+			target = filepath.Join(dest, target)
+		} else if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+
+		if !within(root, filepath.Clean(target)) {
+			return ErrUnsafeTarEntry
+		}
+	}
+
+	return nil
+}
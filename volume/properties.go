@@ -28,3 +28,50 @@ func (p Properties) UpdateProperty(name string, value string) Properties {
 
 	return updatedProperties
 }
+
+func (p Properties) UpdateProperties(other Properties) Properties {
+	updatedProperties := Properties{}
+
+	for k, v := range p {
+		updatedProperties[k] = v
+	}
+
+	for k, v := range other {
+		updatedProperties[k] = v
+	}
+
+	return updatedProperties
+}
+
+// PropertyExpectation is what one of a volume's properties must be for a
+// change to it to go ahead.
+type PropertyExpectation struct {
+	// Value is the value the property must have.
+	Value string
+
+	// Absent is whether the volume must not have the property at all, in
+	// which case Value is ignored.
+	Absent bool
+}
+
+// MetBy returns whether the named property of the properties is as expected.
+func (expectation PropertyExpectation) MetBy(properties Properties, name string) bool {
+	value, found := properties[name]
+	if expectation.Absent {
+		return !found
+	}
+
+	return found && value == expectation.Value
+}
+
+func (p Properties) DeleteProperty(name string) Properties {
+	updatedProperties := Properties{}
+
+	for k, v := range p {
+		if k != name {
+			updatedProperties[k] = v
+		}
+	}
+
+	return updatedProperties
+}
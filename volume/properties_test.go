@@ -127,4 +127,47 @@ var _ = Describe("Properties Superset", func() {
 			Expect(updatedProperties).To(Equal(volume.Properties{"some": "other-property"}))
 		})
 	})
+
+	Describe("Update Properties", func() {
+		It("sets each of the properties, keeping the rest", func() {
+			properties := volume.Properties{"some": "property", "other": "property"}
+			updatedProperties := properties.UpdateProperties(volume.Properties{"some": "other-property", "new": "property"})
+
+			Expect(updatedProperties).To(Equal(volume.Properties{
+				"some":  "other-property",
+				"other": "property",
+				"new":   "property",
+			}))
+		})
+
+		It("does not modify the original object", func() {
+			properties := volume.Properties{}
+			properties.UpdateProperties(volume.Properties{"some": "property"})
+
+			Expect(properties).To(Equal(volume.Properties{}))
+		})
+	})
+
+	Describe("Delete Property", func() {
+		It("removes the property", func() {
+			properties := volume.Properties{"some": "property", "other": "property"}
+			updatedProperties := properties.DeleteProperty("some")
+
+			Expect(updatedProperties).To(Equal(volume.Properties{"other": "property"}))
+		})
+
+		It("does not modify the original object", func() {
+			properties := volume.Properties{"some": "property"}
+			properties.DeleteProperty("some")
+
+			Expect(properties).To(Equal(volume.Properties{"some": "property"}))
+		})
+
+		It("leaves the properties alone if it's not present", func() {
+			properties := volume.Properties{"some": "property"}
+			updatedProperties := properties.DeleteProperty("other")
+
+			Expect(updatedProperties).To(Equal(volume.Properties{"some": "property"}))
+		})
+	})
 })
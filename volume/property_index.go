@@ -0,0 +1,202 @@
+package volume
+
+import (
+	"sort"
+	"sync"
+)
+
+// propertyIndex maps the values of a configured set of properties to the
+// handles of the volumes that have them, so that queries on those properties
+// don't have to load every volume.
+//
+// It is built from the first scan of the volumes, and kept up to date by the
+// repository from then on. Until then, and for queries on other properties,
+// the repository scans.
+type propertyIndex struct {
+	names map[string]bool
+
+	lock  sync.RWMutex
+	built bool
+
+	// property name -> value -> handles
+	handles map[string]map[string]map[string]bool
+
+	// handle -> its indexed properties
+	properties map[string]Properties
+}
+
+func newPropertyIndex(names []string) *propertyIndex {
+	if len(names) == 0 {
+		return nil
+	}
+
+	index := &propertyIndex{
+		names:      map[string]bool{},
+		handles:    map[string]map[string]map[string]bool{},
+		properties: map[string]Properties{},
+	}
+
+	for _, name := range names {
+		index.names[name] = true
+		index.handles[name] = map[string]map[string]bool{}
+	}
+
+	return index
+}
+
+// Covers returns whether the query is on at least one indexed property.
+func (index *propertyIndex) Covers(query Properties) bool {
+	if index == nil {
+		return false
+	}
+
+	for name := range query {
+		if index.names[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lookup returns the handles of the volumes matching the query on its
+// indexed properties, in order. The rest of the query is left to the caller.
+// If the index has not been built yet, it is built from scan first.
+func (index *propertyIndex) Lookup(query Properties, scan func() (map[string]Properties, error)) ([]string, error) {
+	err := index.build(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+
+	var smallest map[string]bool
+	for name, value := range query {
+		if !index.names[name] {
+			continue
+		}
+
+		matching := index.handles[name][value]
+		if smallest == nil || len(matching) < len(smallest) {
+			smallest = matching
+		}
+	}
+
+	handles := []string{}
+
+	for handle := range smallest {
+		properties := index.properties[handle]
+
+		matches := true
+		for name, value := range query {
+			if index.names[name] && properties[name] != value {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			handles = append(handles, handle)
+		}
+	}
+
+	sort.Strings(handles)
+
+	return handles, nil
+}
+
+func (index *propertyIndex) build(scan func() (map[string]Properties, error)) error {
+	index.lock.RLock()
+	built := index.built
+	index.lock.RUnlock()
+
+	if built {
+		return nil
+	}
+
+	// updates wait for the scan, so none are lost between it and the index
+	// being marked as built
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if index.built {
+		return nil
+	}
+
+	volumes, err := scan()
+	if err != nil {
+		return err
+	}
+
+	for handle, properties := range volumes {
+		index.update(handle, properties)
+	}
+
+	index.built = true
+
+	return nil
+}
+
+// Update records the properties of the volume, replacing what was recorded
+// for it before.
+func (index *propertyIndex) Update(handle string, properties Properties) {
+	if index == nil {
+		return
+	}
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	// the first scan will pick it up
+	if !index.built {
+		return
+	}
+
+	index.update(handle, properties)
+}
+
+// Remove forgets the volume.
+func (index *propertyIndex) Remove(handle string) {
+	if index == nil {
+		return
+	}
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.remove(handle)
+}
+
+func (index *propertyIndex) update(handle string, properties Properties) {
+	index.remove(handle)
+
+	indexed := Properties{}
+	for name, value := range properties {
+		if !index.names[name] {
+			continue
+		}
+
+		indexed[name] = value
+
+		if index.handles[name][value] == nil {
+			index.handles[name][value] = map[string]bool{}
+		}
+
+		index.handles[name][value][handle] = true
+	}
+
+	index.properties[handle] = indexed
+}
+
+func (index *propertyIndex) remove(handle string) {
+	for name, value := range index.properties[handle] {
+		delete(index.handles[name][value], handle)
+
+		if len(index.handles[name][value]) == 0 {
+			delete(index.handles[name], value)
+		}
+	}
+
+	delete(index.properties, handle)
+}
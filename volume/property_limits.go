@@ -0,0 +1,53 @@
+package volume
+
+// PropertyLimits bound the properties volumes may be given, checked before
+// they are stored; properties already stored are left as they are. Whatever
+// the limits, names may only have letters, digits, '.', '_', '-', and ':',
+// so that they are safe to put in paths and log lines.
+type PropertyLimits struct {
+	// MaxNameLength is the most bytes a name may have. 0 leaves it unbounded.
+	MaxNameLength int
+
+	// MaxValueSize is the most bytes a value may have. 0 leaves it
+	// unbounded.
+	MaxValueSize int
+}
+
+func (limits PropertyLimits) Validate(properties Properties) error {
+	for name, value := range properties {
+		if !limits.allowsName(name) {
+			return ErrInvalidPropertyName
+		}
+
+		if limits.MaxValueSize > 0 && len(value) > limits.MaxValueSize {
+			return ErrPropertyValueTooLarge
+		}
+	}
+
+	return nil
+}
+
+func (limits PropertyLimits) allowsName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	if limits.MaxNameLength > 0 && len(name) > limits.MaxNameLength {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+
+		switch {
+		case c >= 'a' && c <= 'z',
+			c >= 'A' && c <= 'Z',
+			c >= '0' && c <= '9',
+			c == '.', c == '_', c == '-', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
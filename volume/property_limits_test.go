@@ -0,0 +1,50 @@
+package volume_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/baggageclaim/volume"
+)
+
+var _ = Describe("PropertyLimits", func() {
+	var limits volume.PropertyLimits
+
+	BeforeEach(func() {
+		limits = volume.PropertyLimits{MaxNameLength: 16, MaxValueSize: 8}
+	})
+
+	It("accepts names of letters, digits, '.', '_', '-', and ':'", func() {
+		Expect(limits.Validate(volume.Properties{"Some.name_1-a:b": "value"})).To(Succeed())
+	})
+
+	It("rejects names with anything else in them", func() {
+		for _, name := range []string{"some/name", "some\nname", "some name", "some\x00name", "naïve"} {
+			Expect(limits.Validate(volume.Properties{name: "value"})).To(Equal(volume.ErrInvalidPropertyName), name)
+		}
+	})
+
+	It("rejects empty names", func() {
+		Expect(limits.Validate(volume.Properties{"": "value"})).To(Equal(volume.ErrInvalidPropertyName))
+	})
+
+	It("rejects names longer than the limit", func() {
+		Expect(limits.Validate(volume.Properties{strings.Repeat("a", 16): "value"})).To(Succeed())
+		Expect(limits.Validate(volume.Properties{strings.Repeat("a", 17): "value"})).To(Equal(volume.ErrInvalidPropertyName))
+	})
+
+	It("rejects values larger than the limit", func() {
+		Expect(limits.Validate(volume.Properties{"name": strings.Repeat("v", 8)})).To(Succeed())
+		Expect(limits.Validate(volume.Properties{"name": strings.Repeat("v", 9)})).To(Equal(volume.ErrPropertyValueTooLarge))
+	})
+
+	It("accepts values with any characters in them", func() {
+		Expect(limits.Validate(volume.Properties{"name": "a/b\nc"})).To(Succeed())
+	})
+
+	It("leaves lengths unbounded without limits", func() {
+		Expect(volume.PropertyLimits{}.Validate(volume.Properties{strings.Repeat("a", 1024): strings.Repeat("v", 1024*1024)})).To(Succeed())
+	})
+})
@@ -1,61 +1,363 @@
 package volume
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/concourse/baggageclaim/uidgid"
 
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 )
 
 var ErrVolumeDoesNotExist = errors.New("volume does not exist")
 var ErrVolumeIsCorrupted = errors.New("volume is corrupted")
+var ErrVolumeIsFrozen = errors.New("volume is frozen")
+var ErrInvalidPropertyValue = errors.New("property value does not match its label schema")
+var ErrInvalidPropertyName = errors.New("property name must be letters, digits, '.', '_', '-', and ':', and no longer than allowed")
+var ErrPropertyValueTooLarge = errors.New("property value is larger than allowed")
+var ErrStreamInAlreadyApplied = errors.New("stream has already been applied")
+var ErrStreamChecksumMismatch = errors.New("streamed content does not match its checksum")
+var ErrInsufficientInodes = errors.New("too few free inodes left on the volumes filesystem")
+var ErrVolumeAlreadyExists = errors.New("volume already exists")
+var ErrNoPropertiesToSelectBy = errors.New("no properties to select volumes by")
+var ErrVolumeIsStreaming = errors.New("volume is being streamed")
+var ErrNotARegularFile = errors.New("not a regular file")
+var ErrStreamOutOptionsNeedTar = errors.New("modified-since, downgrade, and xattrs only apply to tar streams")
+var ErrInvalidHandle = errors.New("handle must be a non-empty name without slashes")
+var ErrPropertyDoesNotExist = errors.New("property does not exist")
+var ErrVolumeHasChildren = errors.New("volume has copy-on-write children")
+var ErrVolumeWasRenewed = errors.New("volume's TTL was renewed")
+var ErrPropertyConflict = errors.New("property does not have the expected value")
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was used to create another volume")
+var ErrVolumeGone = errors.New("volume was deleted too long ago to be restored")
+var ErrParentVolumeDeleted = errors.New("volume's parent is deleted, and must be restored first")
+var ErrSourceVolumeDoesNotExist = errors.New("source volume does not exist")
+var ErrSourcePathDoesNotExist = errors.New("source path does not exist")
+var ErrCopyOverlapsSource = errors.New("cannot copy a path into or out of itself")
 
 //go:generate counterfeiter . Repository
 
 type Repository interface {
 	ListVolumes(queryProperties Properties) (Volumes, []string, error)
 	GetVolume(handle string) (Volume, bool, error)
+
+	// VolumeExists is whether GetVolume would find the volume, without
+	// loading any more of it than that. Deleted volumes are not found. It is
+	// not an access of the volume, so its TTL is left alone.
+	VolumeExists(handle string) (bool, error)
+
 	GetVolumeStats(handle string) (VolumeStats, bool, error)
-	CreateVolume(handle string, strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool) (Volume, error)
-	DestroyVolume(handle string) error
-	DestroyVolumeAndDescendants(handle string) error
 
-	SetProperty(handle string, propertyName string, propertyValue string) error
+	// TotalUsage sums up the sizes of the volumes, grouping them by the
+	// value they have for the groupBy property unless it is empty.
+	TotalUsage(groupBy string) (Usage, error)
+
+	// CreateVolume creates a volume with the handle by the strategy, as the
+	// options ask. It returns ErrVolumeAlreadyExists if the handle is taken.
+	CreateVolume(handle string, strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool, opts CreateOptions) (Volume, error)
+
+	// CloneVolume creates a writable copy of the source volume, with its
+	// properties, TTL and its renewal, and privileges, that has no tie to the source
+	// afterwards. It returns ErrVolumeAlreadyExists if the handle is taken.
+	CloneVolume(srcHandle string, handle string) (Volume, error)
+
+	// RenameVolume gives the volume another handle without touching its
+	// data; its views and copy-on-write children follow it. It returns
+	// ErrVolumeAlreadyExists if the handle is taken, and ErrVolumeIsStreaming
+	// if the volume is being streamed in or out.
+	RenameVolume(handle string, newHandle string) (Volume, error)
+
+	// Promote turns a copy-on-write child into a volume with all of its data
+	// of its own, so that its parent can be destroyed. Other volumes are
+	// returned as they are. It returns ErrVolumeIsStreaming if the volume is
+	// being streamed in or out.
+	Promote(handle string) (Volume, error)
+
+	DestroyVolume(handle string, opts DestroyOptions) error
+
+	// DestroyVolumeAndDescendants returns the handles of the volumes it
+	// destroyed, each one's descendants before it, including those destroyed
+	// before it failed.
+	DestroyVolumeAndDescendants(handle string, opts DestroyOptions) ([]string, error)
+
+	// PlanDestroy returns the handles of the volumes DestroyVolume would
+	// destroy, or DestroyVolumeAndDescendants with descendants, in the order
+	// it would, without destroying anything. It fails as they would for a
+	// volume that does not exist, is leased, or, without descendants, has
+	// copy-on-write children. A released base destroyed along with its last
+	// view is not among them, as it isn't for the destroys.
+	PlanDestroy(handle string, opts DestroyOptions, descendants bool) ([]string, error)
+
+	// DestroyVolumes destroys each of the volumes, returning the error it
+	// failed with by handle. Volumes that do not exist count as destroyed.
+	DestroyVolumes(handles []string, opts DestroyOptions) map[string]error
+
+	// DestroyVolumesWithProperties destroys the volumes that have all of the
+	// properties, returning the error each failed with by handle, or nil if
+	// it was destroyed. A volume whose properties stop matching before it is
+	// destroyed is left alone. It returns ErrNoPropertiesToSelectBy rather
+	// than destroy every volume.
+	DestroyVolumesWithProperties(properties Properties, opts DestroyOptions) (map[string]error, error)
+
+	// RestoreVolume brings back a volume that was deleted rather than
+	// destroyed, as it was, with its TTL starting over. It returns
+	// ErrVolumeGone once the volume's retention window has passed, whether
+	// or not it has been purged yet, and ErrParentVolumeDeleted while the
+	// volume's parent or base is deleted too. A volume that is not deleted
+	// is returned as it is.
+	RestoreVolume(handle string) (Volume, error)
+
+	// ListDeletedVolumes lists the deleted volumes that have the properties
+	// and have yet to be purged, along with the handles of those that are
+	// corrupted.
+	ListDeletedVolumes(queryProperties Properties) (Volumes, []string, error)
+
+	// PurgeDeletedVolumes destroys the deleted volumes whose retention
+	// window has passed for good, returning the error each failed with by
+	// handle, or nil if it was purged. Volumes that deleted volumes still
+	// depend on are left for a later purge.
+	PurgeDeletedVolumes() (map[string]error, error)
+
+	// GetProperty returns the value of one of the volume's properties. It
+	// returns ErrPropertyDoesNotExist if the volume does not have it.
+	GetProperty(handle string, propertyName string) (string, error)
+
+	// SetProperty sets one of the volume's properties. With an expectation,
+	// it is only set if the property is as expected when the volume's lock
+	// is taken, and ErrPropertyConflict is returned otherwise.
+	SetProperty(handle string, propertyName string, propertyValue string, expected *PropertyExpectation, leaseToken string) error
+
+	// SetProperties sets all of the properties on the volume at once, leaving
+	// its other properties as they are. If any of them are invalid, none of
+	// them are set.
+	SetProperties(handle string, properties Properties, leaseToken string) error
+
+	// DeleteProperty removes the property from the volume. Removing a
+	// property the volume does not have does nothing.
+	DeleteProperty(handle string, propertyName string, leaseToken string) error
+
+	// AcquireLease takes out a lease on the volume for the duration or, with
+	// the token of the lease held on it, extends that lease. Leases are
+	// advisory: while one is held, stream-ins, property changes, and
+	// destroys of the volume that are not given its token fail with
+	// ErrVolumeIsLeased, but those already under way when it is taken are
+	// not stopped.
+	AcquireLease(handle string, token string, duration time.Duration) (Lease, error)
+
+	// ReleaseLease gives up the lease held on the volume with the token.
+	ReleaseLease(handle string, token string) error
+
 	SetTTL(handle string, ttl uint) error
+
+	// SetExpiresAt sets the volume to expire at the given time, which may
+	// have already passed.
+	SetExpiresAt(handle string, expiresAt time.Time) error
+
 	SetPrivileged(handle string, privileged bool) error
+	SetSELinuxLabel(handle string, label string) error
+	CommitVolume(handle string, freeze bool) error
+	TouchAccess(handle string) error
+
+	// Materialize gets the volume ready for use ahead of time, mounting it
+	// again if its mount is gone and reading in its metadata. It returns
+	// whether the volume had to be mounted.
+	Materialize(handle string) (bool, error)
 
-	StreamIn(handle string, path string, stream io.Reader) (bool, error)
-	StreamOut(handle string, path string, dest io.Writer) error
+	// StreamIn and StreamOut give up once ctx is done, returning its error.
+	// What a stream-in extracted by then is removed again. A stream-in is
+	// extracted in place, as it may replace or delete what is already in the
+	// volume, so what it has extracted so far is in the volume for anything
+	// that looks, e.g. by the path GetVolume returns, until it either
+	// finishes or is removed.
+	//
+	// Once as many streams as the repository's StreamLimits allow are running
+	// in the same direction, they wait for one to finish, and return
+	// ErrTooManyStreams if none does in time.
+	StreamIn(ctx context.Context, handle string, path string, stream io.Reader, opts StreamInOptions) (bool, error)
+	StreamOut(ctx context.Context, handle string, path string, dest io.Writer, opts StreamOutOptions) error
+
+	// CopyIn copies from the source volume into the path in the volume, on
+	// the server, as StreamIn would the source's StreamOut of it, and as
+	// cheaply as the driver can. It returns ErrVolumeDoesNotExist if the
+	// volume doesn't exist, ErrSourceVolumeDoesNotExist if the source
+	// doesn't, and ErrSourcePathDoesNotExist if there is nothing at the path
+	// in it. Paths that overlap in the same volume return
+	// ErrCopyOverlapsSource. A copy counts as a stream-in against the
+	// repository's StreamLimits.
+	CopyIn(ctx context.Context, handle string, path string, sourceHandle string, opts CopyInOptions) error
+
+	DiffVolumes(handle string, baseHandle string, emit func(DiffEntry) error) error
+
+	// Manifest calls emit for everything under the path in the volume, for
+	// a delta stream-in to be made against.
+	Manifest(handle string, path string, emit func(ManifestEntry) error) error
+
+	// ListFiles lists everything under the path in the volume, in lexical
+	// order, without reading any of it.
+	ListFiles(handle string, path string) ([]FileEntry, error)
+
+	StreamOutDiff(handle string, baseHandle string, dest io.Writer) error
 
 	VolumeParent(handle string) (Volume, bool, error)
+
+	// VolumeChildren returns the handles of the volume's copy-on-write
+	// children, in order. Views of it are left out.
+	VolumeChildren(handle string) ([]string, error)
+
+	// VolumeDigest computes the digest of the volume's contents as they are
+	// now, without recording it.
+	VolumeDigest(handle string) (string, error)
+
+	InodesExhausted() (bool, error)
+
+	// FreeBytes is how much more may be written to the volumes filesystem.
+	FreeBytes() (uint64, error)
+
+	// WithLogData returns the repository tagging everything it logs with the
+	// data, e.g. the ID of the request it is to serve. It shares its volumes,
+	// locks, and indexes with this one.
+	WithLogData(data lager.Data) Repository
 }
 
 type repository struct {
 	logger lager.Logger
 
+	clock clock.Clock
+
 	filesystem Filesystem
 
 	locker LockManager
 
+	streamInLocker PathLockManager
+
+	labelSchemas LabelSchemas
+
+	propertyLimits PropertyLimits
+
+	streamInIdempotencyWindow time.Duration
+
+	destroyAuditLog DestroyAuditLog
+
+	minFreeInodes uint64
+
+	streamInConcurrency int
+
+	// what the modes of what tar extracts into unprivileged volumes are
+	// masked with
+	extractionUmask os.FileMode
+
+	propertyIndex *propertyIndex
+	childIndex    *childIndex
+	viewIndex     *childIndex
+	createKeys    *createKeyIndex
+
+	leases *leaseTable
+
+	events EventSink
+
+	// volumes are deleted rather than destroyed, and kept for this long
+	// before they are purged, unless it is 0
+	deletedRetention time.Duration
+
+	// when recently purged volumes were purged, so that restoring them still
+	// returns ErrVolumeGone for another retention window
+	purgedL *sync.Mutex
+	purged  map[string]time.Time
+
+	streamsL *sync.Mutex
+	streams  map[string]int
+
+	// the latest of the digests taken of each volume being streamed into,
+	// which is the only one of them to be recorded
+	digestTickets map[string]uint64
+
+	// the slots streams in and out take while they run, and how long they
+	// wait for one
+	streamsIn          streamSlots
+	streamsOut         streamSlots
+	streamQueueTimeout time.Duration
+
 	namespacer func(bool) uidgid.Namespacer
 }
 
 func NewRepository(
 	logger lager.Logger,
+	clock clock.Clock,
 	filesystem Filesystem,
 	locker LockManager,
+	streamInLocker PathLockManager,
 	privilegedNamespacer uidgid.Namespacer,
 	unprivilegedNamespacer uidgid.Namespacer,
+	labelSchemas LabelSchemas,
+	streamInIdempotencyWindow time.Duration,
+	destroyAuditLog DestroyAuditLog,
+	minFreeInodes uint64,
+	streamInConcurrency int,
+	indexedProperties []string,
+	events EventSink,
+	propertyLimits PropertyLimits,
+	deletedRetention time.Duration,
+	streamLimits StreamLimits,
+	extractionUmask os.FileMode,
 ) Repository {
 	return &repository{
 		logger:     logger,
+		clock:      clock,
 		filesystem: filesystem,
 		locker:     locker,
 
+		streamInLocker: streamInLocker,
+
+		labelSchemas: labelSchemas,
+
+		propertyLimits: propertyLimits,
+
+		streamInIdempotencyWindow: streamInIdempotencyWindow,
+
+		destroyAuditLog: destroyAuditLog,
+
+		minFreeInodes: minFreeInodes,
+
+		streamInConcurrency: streamInConcurrency,
+		extractionUmask:     extractionUmask,
+
+		propertyIndex: newPropertyIndex(indexedProperties),
+		childIndex:    newChildIndex(),
+		viewIndex:     newChildIndex(),
+		createKeys:    newCreateKeyIndex(),
+
+		leases: newLeaseTable(),
+
+		events: events,
+
+		deletedRetention: deletedRetention,
+
+		purgedL: &sync.Mutex{},
+		purged:  map[string]time.Time{},
+
+		streamsL:      &sync.Mutex{},
+		streams:       map[string]int{},
+		digestTickets: map[string]uint64{},
+
+		streamsIn:          newStreamSlots(streamLimits.MaxStreamsIn),
+		streamsOut:         newStreamSlots(streamLimits.MaxStreamsOut),
+		streamQueueTimeout: streamLimits.QueueTimeout,
+
 		namespacer: func(privileged bool) uidgid.Namespacer {
 			if privileged {
 				return privilegedNamespacer
@@ -66,396 +368,3548 @@ func NewRepository(
 	}
 }
 
-func (repo *repository) DestroyVolume(handle string) error {
-	repo.locker.Lock(handle)
-	defer repo.locker.Unlock(handle)
-
-	logger := repo.logger.Session("destroy-volume", lager.Data{
-		"volume": handle,
-	})
-
-	volume, found, err := repo.filesystem.LookupVolume(handle)
+// DestroyVolume destroys the volume, unless it still has views, in which case
+// it is released, frozen as they are, and destroyed along with its last
+// view. It returns
+// ErrVolumeHasChildren while copy-on-write children depend on its data;
+// DestroyVolumeAndDescendants destroys them along with it.
+//
+// With a retention window, the volume is deleted instead: it is kept as it
+// is, but as good as destroyed until it is restored, or purged once the
+// window has passed. Volumes destroyed under disk pressure are destroyed all
+// the same, to free up space.
+func (repo *repository) DestroyVolume(handle string, opts DestroyOptions) error {
+	baseHandle, baseOpts, err := repo.destroyVolume(handle, opts)
 	if err != nil {
-		logger.Error("failed-to-lookup-volume", err)
 		return err
 	}
 
-	if !found {
-		logger.Info("volume-not-found")
-		return ErrVolumeDoesNotExist
+	if baseHandle != "" {
+		return repo.DestroyVolume(baseHandle, baseOpts)
 	}
 
-	err = volume.Destroy()
-	if err != nil {
-		logger.Error("failed-to-destroy", err)
-		return err
-	}
+	return nil
+}
 
-	logger.Info("destroyed")
+// DestroyVolumes locks every volume up front and lists the views once for
+// all of them, rather than per volume as DestroyVolume does.
+func (repo *repository) DestroyVolumes(handles []string, opts DestroyOptions) map[string]error {
+	logger := repo.logger.Session("destroy-volumes", lager.Data{
+		"volumes": len(handles),
+		"reason":  opts.Reason,
+	})
 
-	return nil
+	return repo.destroyVolumes(logger, handles, opts, nil)
 }
 
-func (repo *repository) DestroyVolumeAndDescendants(handle string) error {
-	allVolumes, err := repo.filesystem.ListVolumes()
+func (repo *repository) DestroyVolumesWithProperties(properties Properties, opts DestroyOptions) (map[string]error, error) {
+	logger := repo.logger.Session("destroy-volumes-with-properties", lager.Data{
+		"properties": properties,
+		"reason":     opts.Reason,
+	})
+
+	if len(properties) == 0 {
+		return nil, ErrNoPropertiesToSelectBy
+	}
+
+	volumes, _, err := repo.ListVolumes(properties)
 	if err != nil {
-		return err
+		logger.Error("failed-to-list-volumes", err)
+		return nil, err
 	}
 
-	found := false
-	for _, candidate := range allVolumes {
-		if candidate.Handle() == handle {
-			found = true
+	handles := make([]string, len(volumes))
+	for i, volume := range volumes {
+		handles[i] = volume.Handle
+	}
+
+	logger.Info("selected", lager.Data{"volumes": len(handles)})
+
+	// the properties may have been changed between listing the volumes and
+	// locking them
+	stillMatches := func(handle string) (bool, error) {
+		liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+		if err != nil || !found {
+			return false, err
+		}
+
+		volumeProperties, err := liveVolume.LoadProperties()
+		if err != nil {
+			return false, err
 		}
+
+		return volumeProperties.HasProperties(properties), nil
 	}
-	if !found {
-		return ErrVolumeDoesNotExist
+
+	return repo.destroyVolumes(logger, handles, opts, stillMatches), nil
+}
+
+// destroyVolumes destroys the volumes together, leaving out those selected
+// says not to destroy once they are locked, unless it is nil.
+func (repo *repository) destroyVolumes(logger lager.Logger, handles []string, opts DestroyOptions, selected func(string) (bool, error)) map[string]error {
+	unique := map[string]bool{}
+	for _, handle := range handles {
+		unique[handle] = true
 	}
 
-	for _, candidate := range allVolumes {
-		candidateParent, found, err := candidate.Parent()
-		if err != nil {
-			continue
+	locked := make([]string, 0, len(unique))
+	for handle := range unique {
+		locked = append(locked, handle)
+	}
+
+	// in order, so that batches sharing volumes can't deadlock
+	sort.Strings(locked)
+
+	for _, handle := range locked {
+		repo.locker.Lock(handle)
+	}
+
+	results := map[string]error{}
+	bases := map[string]DestroyOptions{}
+
+	pending := locked
+	for len(pending) > 0 {
+		// volumes whose copy-on-write children are in the batch too are
+		// tried again once those are gone
+		waiting := []string{}
+
+		for _, handle := range pending {
+			if selected != nil {
+				isSelected, err := selected(handle)
+				if err != nil {
+					results[handle] = err
+					continue
+				}
+
+				if !isSelected {
+					continue
+				}
+			}
+
+			baseHandle, baseOpts, err := repo.destroyLockedVolume(handle, opts)
+			if err == ErrVolumeDoesNotExist {
+				err = nil
+			}
+
+			results[handle] = err
+
+			if err == ErrVolumeHasChildren {
+				waiting = append(waiting, handle)
+			}
+
+			if baseHandle != "" {
+				bases[baseHandle] = baseOpts
+			}
 		}
-		if !found {
-			continue
+
+		if len(waiting) == len(pending) {
+			break
 		}
 
-		if candidateParent.Handle() == handle {
-			err = repo.DestroyVolumeAndDescendants(candidate.Handle())
-			if err != nil {
-				return err
-			}
+		pending = waiting
+	}
+
+	for _, handle := range locked {
+		repo.locker.Unlock(handle)
+	}
+
+	// a released base is destroyed along with its last view, but failing
+	// to is no reason to retry the view; it may also have been destroyed
+	// in the batch already
+	for baseHandle, baseOpts := range bases {
+		err := repo.DestroyVolume(baseHandle, baseOpts)
+		if err != nil && err != ErrVolumeDoesNotExist {
+			logger.Error("failed-to-destroy-released-base", err, lager.Data{"base": baseHandle})
 		}
 	}
 
-	return repo.DestroyVolume(handle)
+	return results
 }
 
-func (repo *repository) CreateVolume(handle string, strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool) (Volume, error) {
-	logger := repo.logger.Session("create-volume", lager.Data{"handle": handle})
+// destroyVolume returns the handle of the released base volume to destroy
+// when the last of its views has been destroyed.
+func (repo *repository) destroyVolume(handle string, opts DestroyOptions) (string, DestroyOptions, error) {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	return repo.destroyLockedVolume(handle, opts)
+}
+
+func (repo *repository) destroyLockedVolume(handle string, opts DestroyOptions) (string, DestroyOptions, error) {
+	logger := repo.logger.Session("destroy-volume", lager.Data{
+		"volume":     handle,
+		"reason":     opts.Reason,
+		"annotation": opts.Annotation,
+	})
 
-	initVolume, err := strategy.Materialize(logger, handle, repo.filesystem)
+	volume, found, err := repo.filesystem.LookupVolume(handle)
 	if err != nil {
-		logger.Error("failed-to-materialize-strategy", err)
-		return Volume{}, err
+		logger.Error("failed-to-lookup-volume", err)
+		return "", DestroyOptions{}, err
 	}
 
-	var initialized bool
-	defer func() {
-		if !initialized {
-			initVolume.Destroy()
-		}
-	}()
+	if !found {
+		logger.Info("volume-not-found")
+		return "", DestroyOptions{}, ErrVolumeDoesNotExist
+	}
 
-	err = initVolume.StoreProperties(properties)
+	err = repo.checkLease(logger, handle, opts.LeaseToken)
 	if err != nil {
-		logger.Error("failed-to-set-properties", err)
-		return Volume{}, err
+		return "", DestroyOptions{}, err
 	}
 
-	ttl := TTL(ttlInSeconds)
+	if opts.SpareStreamed && repo.isStreaming(handle) {
+		logger.Info("sparing-streamed-volume")
+		return "", DestroyOptions{}, ErrVolumeIsStreaming
+	}
 
-	expiresAt, err := initVolume.StoreTTL(ttl)
-	if err != nil {
-		logger.Error("failed-to-set-properties", err)
-		return Volume{}, err
+	if !opts.ExpiredAt.IsZero() {
+		_, expiresAt, err := volume.LoadTTL()
+		if err != nil {
+			logger.Error("failed-to-load-ttl", err)
+			return "", DestroyOptions{}, err
+		}
+
+		if !expiresAt.Equal(opts.ExpiredAt) {
+			logger.Info("sparing-renewed-volume", lager.Data{"expires-at": expiresAt})
+			return "", DestroyOptions{}, ErrVolumeWasRenewed
+		}
 	}
 
-	err = initVolume.StorePrivileged(isPrivileged)
+	children, err := repo.childIndex.Children(handle, repo.scanChildren)
 	if err != nil {
-		logger.Error("failed-to-set-privileged", err)
-		return Volume{}, err
+		logger.Error("failed-to-list-children", err)
+		return "", DestroyOptions{}, err
 	}
 
-	err = repo.namespacer(isPrivileged).NamespacePath(logger, initVolume.DataPath())
-	if err != nil {
-		logger.Error("failed-to-namespace-data", err)
-		return Volume{}, err
+	if len(children) > 0 {
+		logger.Info("volume-has-children", lager.Data{"children": children})
+		return "", DestroyOptions{}, ErrVolumeHasChildren
 	}
 
-	liveVolume, err := initVolume.Initialize()
+	views, err := repo.viewIndex.Children(handle, repo.scanViews)
 	if err != nil {
-		logger.Error("failed-to-initialize-volume", err)
-		return Volume{}, err
+		logger.Error("failed-to-list-views", err)
+		return "", DestroyOptions{}, err
 	}
 
-	initialized = true
+	if len(views) > 0 {
+		// what is still there of it is only for its views, which are
+		// frozen, so it is frozen too rather than written to under them
+		_, err = volume.StoreCommitted(true)
+		if err != nil {
+			logger.Error("failed-to-freeze", err)
+			return "", DestroyOptions{}, err
+		}
 
-	return Volume{
-		Handle:     liveVolume.Handle(),
-		Path:       liveVolume.DataPath(),
-		Properties: properties,
-		TTL:        ttl,
-		ExpiresAt:  expiresAt,
-	}, nil
-}
+		err = volume.StoreReleased(opts)
+		if err != nil {
+			logger.Error("failed-to-release", err)
+			return "", DestroyOptions{}, err
+		}
 
-func (repo *repository) ListVolumes(queryProperties Properties) (Volumes, []string, error) {
-	logger := repo.logger.Session("list-volumes")
+		logger.Info("released", lager.Data{"views": views})
+		return "", DestroyOptions{}, nil
+	}
 
-	liveVolumes, err := repo.filesystem.ListVolumes()
+	var base FilesystemLiveVolume
+
+	isView, err := volume.IsView()
 	if err != nil {
-		logger.Error("failed-to-list-volumes", err)
-		return nil, nil, err
+		logger.Error("failed-to-check-view", err)
+		return "", DestroyOptions{}, err
 	}
 
-	healthyVolumes := make(Volumes, 0, len(liveVolumes))
-	corruptedVolumeHandles := []string{}
-
-	for _, liveVolume := range liveVolumes {
-		volume, err := repo.volumeFrom(liveVolume)
-		if err == ErrVolumeDoesNotExist {
-			continue
+	if isView {
+		base, _, err = volume.Parent()
+		if err != nil {
+			logger.Error("failed-to-lookup-base", err)
 		}
+	}
+
+	// the properties are gone along with the volume, so grab them first
+	properties, err := volume.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-load-properties", err)
+	}
+
+	destroyedAt := repo.clock.Now()
 
+	if repo.deletes(opts) {
+		err = volume.StoreDeleted(opts, destroyedAt)
 		if err != nil {
-			corruptedVolumeHandles = append(corruptedVolumeHandles, liveVolume.Handle())
-			logger.Error("failed-hydrating-volume", err)
-			continue
+			logger.Error("failed-to-delete", err)
+			return "", DestroyOptions{}, err
 		}
 
-		if volume.Properties.HasProperties(queryProperties) {
-			healthyVolumes = append(healthyVolumes, volume)
+		logger.Info("deleted", lager.Data{"retention": repo.deletedRetention})
+	} else {
+		err = volume.Destroy()
+		if err != nil {
+			logger.Error("failed-to-destroy", err)
+			return "", DestroyOptions{}, err
 		}
+
+		logger.Info("destroyed")
 	}
 
-	return healthyVolumes, corruptedVolumeHandles, nil
+	repo.propertyIndex.Remove(handle)
+	repo.childIndex.Remove(handle)
+	repo.viewIndex.Remove(handle)
+	repo.createKeys.Remove(handle)
+	repo.leases.Remove(handle)
+
+	// the volume is already gone, or as good as, so failing to record it is
+	// not a failure to destroy
+	err = repo.destroyAuditLog.Record(DestroyAuditEntry{
+		Handle:      handle,
+		Reason:      opts.Reason,
+		Annotation:  opts.Annotation,
+		Properties:  properties,
+		DestroyedAt: destroyedAt,
+	})
+	if err != nil {
+		logger.Error("failed-to-record-audit-entry", err)
+	}
+
+	eventType := EventDestroyed
+	if opts.Reason == DestroyReasonTTLExpiry {
+		eventType = EventExpired
+	}
+
+	repo.events.Publish(Event{
+		Type:       eventType,
+		Handle:     handle,
+		Properties: properties,
+		Reason:     opts.Reason,
+		At:         destroyedAt,
+	})
+
+	if base == nil {
+		return "", DestroyOptions{}, nil
+	}
+
+	baseOpts, released, err := base.LoadReleased()
+	if err != nil {
+		logger.Error("failed-to-check-base", err)
+		return "", DestroyOptions{}, nil
+	}
+
+	if !released {
+		return "", DestroyOptions{}, nil
+	}
+
+	return base.Handle(), baseOpts, nil
 }
 
-func (repo *repository) GetVolume(handle string) (Volume, bool, error) {
-	logger := repo.logger.Session("get-volume", lager.Data{
+// deletes returns whether destroying a volume with the options deletes it
+// rather than destroying it.
+func (repo *repository) deletes(opts DestroyOptions) bool {
+	return repo.deletedRetention > 0 && opts.Reason != DestroyReasonDiskPressure
+}
+
+func (repo *repository) RestoreVolume(handle string) (Volume, error) {
+	logger := repo.logger.Session("restore-volume", lager.Data{
 		"volume": handle,
 	})
 
-	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	deleted, found, err := repo.filesystem.LookupDeletedVolume(handle)
 	if err != nil {
 		logger.Error("failed-to-lookup-volume", err)
-		return Volume{}, false, err
+		return Volume{}, err
 	}
 
 	if !found {
-		logger.Info("volume-not-found")
-		return Volume{}, false, nil
-	}
+		// restoring a volume again returns it as it is
+		live, found, err := repo.filesystem.LookupVolume(handle)
+		if err != nil {
+			logger.Error("failed-to-lookup-volume", err)
+			return Volume{}, err
+		}
 
-	volume, err := repo.volumeFrom(liveVolume)
-	if err == ErrVolumeDoesNotExist {
+		if found {
+			logger.Info("volume-not-deleted")
+			return repo.volumeFrom(live)
+		}
+
+		if repo.recentlyPurged(handle) {
+			logger.Info("volume-was-purged")
+			return Volume{}, ErrVolumeGone
+		}
+
+		logger.Info("volume-not-found")
+		return Volume{}, ErrVolumeDoesNotExist
+	}
+
+	_, deletedAt, _, err := deleted.LoadDeleted()
+	if err != nil {
+		logger.Error("failed-to-load-deleted", err)
+		return Volume{}, err
+	}
+
+	if !repo.clock.Now().Before(deletedAt.Add(repo.deletedRetention)) {
+		logger.Info("retention-window-passed", lager.Data{"deleted-at": deletedAt})
+		return Volume{}, ErrVolumeGone
+	}
+
+	parent, found, err := deleted.Parent()
+	if err != nil {
+		logger.Error("failed-to-lookup-parent", err)
+		return Volume{}, err
+	}
+
+	if found {
+		_, found, err = repo.filesystem.LookupVolume(parent.Handle())
+		if err != nil {
+			logger.Error("failed-to-lookup-parent", err)
+			return Volume{}, err
+		}
+
+		if !found {
+			logger.Info("parent-is-deleted", lager.Data{"parent": parent.Handle()})
+			return Volume{}, ErrParentVolumeDeleted
+		}
+	}
+
+	err = deleted.ClearDeleted()
+	if err != nil {
+		logger.Error("failed-to-restore", err)
+		return Volume{}, err
+	}
+
+	// it would otherwise be reaped again straight away if it expired in the
+	// meantime, as it does when it is destroyed for expiring
+	ttl, _, err := deleted.LoadTTL()
+	if err == nil && !ttl.IsUnlimited() {
+		_, err = deleted.StoreTTL(ttl)
+	}
+
+	if err != nil {
+		logger.Error("failed-to-renew-ttl", err)
+	}
+
+	logger.Info("restored", lager.Data{"deleted-at": deletedAt})
+
+	volume, err := repo.volumeFrom(deleted)
+	if err != nil {
+		logger.Error("failed-to-hydrate-volume", err)
+		return Volume{}, ErrVolumeIsCorrupted
+	}
+
+	repo.propertyIndex.Update(handle, volume.Properties)
+
+	if volume.ParentHandle != "" {
+		repo.childIndex.Add(volume.ParentHandle, handle)
+	}
+
+	if isView, err := deleted.IsView(); err == nil && isView {
+		if base, found, err := deleted.Parent(); err == nil && found {
+			repo.viewIndex.Add(base.Handle(), handle)
+		}
+	}
+
+	// another volume may have been created with its key since
+	key, _, err := deleted.LoadCreateKey()
+	if err == nil && key != "" {
+		_, taken, err := repo.createKeys.Lookup(key, repo.scanCreateKeys)
+		if err == nil && !taken {
+			repo.createKeys.Add(key, handle)
+		}
+	}
+
+	repo.events.Publish(Event{
+		Type:       EventRestored,
+		Handle:     handle,
+		Properties: volume.Properties,
+		At:         repo.clock.Now(),
+	})
+
+	return volume, nil
+}
+
+func (repo *repository) ListDeletedVolumes(queryProperties Properties) (Volumes, []string, error) {
+	logger := repo.logger.Session("list-deleted-volumes")
+
+	deletedVolumes, err := repo.filesystem.ListDeletedVolumes()
+	if err != nil {
+		logger.Error("failed-to-list-volumes", err)
+		return nil, nil, err
+	}
+
+	healthyVolumes := make(Volumes, 0, len(deletedVolumes))
+	corruptedVolumeHandles := []string{}
+
+	for _, deletedVolume := range deletedVolumes {
+		volume, err := repo.volumeFrom(deletedVolume)
+		if err == ErrVolumeDoesNotExist {
+			continue
+		}
+
+		if err == nil {
+			_, volume.DeletedAt, volume.Deleted, err = deletedVolume.LoadDeleted()
+		}
+
+		if err != nil {
+			corruptedVolumeHandles = append(corruptedVolumeHandles, deletedVolume.Handle())
+			logger.Error("failed-hydrating-volume", err)
+			continue
+		}
+
+		// restored since it was listed
+		if !volume.Deleted {
+			continue
+		}
+
+		if volume.Properties.HasProperties(queryProperties) {
+			healthyVolumes = append(healthyVolumes, volume)
+		}
+	}
+
+	return healthyVolumes, corruptedVolumeHandles, nil
+}
+
+func (repo *repository) PurgeDeletedVolumes() (map[string]error, error) {
+	logger := repo.logger.Session("purge-deleted-volumes")
+
+	deletedVolumes, err := repo.filesystem.ListDeletedVolumes()
+	if err != nil {
+		logger.Error("failed-to-list-volumes", err)
+		return nil, err
+	}
+
+	// a deleted volume's parent or base is deleted as well, and kept until
+	// the volume is purged
+	parents := map[string]bool{}
+	for _, deletedVolume := range deletedVolumes {
+		parent, found, err := deletedVolume.Parent()
+		if err == nil && found {
+			parents[parent.Handle()] = true
+		}
+	}
+
+	now := repo.clock.Now()
+
+	results := map[string]error{}
+	for _, deletedVolume := range deletedVolumes {
+		handle := deletedVolume.Handle()
+
+		if parents[handle] {
+			continue
+		}
+
+		purged, err := repo.purgeVolume(logger, handle, now)
+		if err != nil || purged {
+			results[handle] = err
+		}
+	}
+
+	repo.forgetPurged(now)
+
+	return results, nil
+}
+
+// purgeVolume destroys the deleted volume if its retention window has passed
+// by now, returning whether it did.
+func (repo *repository) purgeVolume(logger lager.Logger, handle string, now time.Time) (bool, error) {
+	logger = logger.Session("purge-volume", lager.Data{"volume": handle})
+
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	deleted, found, err := repo.filesystem.LookupDeletedVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return false, err
+	}
+
+	// restored since it was listed
+	if !found {
+		return false, nil
+	}
+
+	_, deletedAt, _, err := deleted.LoadDeleted()
+	if err != nil {
+		logger.Error("failed-to-load-deleted", err)
+		return false, err
+	}
+
+	if now.Before(deletedAt.Add(repo.deletedRetention)) {
+		return false, nil
+	}
+
+	err = deleted.Destroy()
+	if err != nil {
+		logger.Error("failed-to-destroy", err)
+		return false, err
+	}
+
+	logger.Info("purged", lager.Data{"deleted-at": deletedAt})
+
+	repo.purgedL.Lock()
+	repo.purged[handle] = now
+	repo.purgedL.Unlock()
+
+	return true, nil
+}
+
+func (repo *repository) recentlyPurged(handle string) bool {
+	repo.purgedL.Lock()
+	defer repo.purgedL.Unlock()
+
+	_, found := repo.purged[handle]
+
+	return found
+}
+
+// forgetPurged forgets the volumes purged more than a retention window ago.
+func (repo *repository) forgetPurged(now time.Time) {
+	repo.purgedL.Lock()
+	defer repo.purgedL.Unlock()
+
+	for handle, purgedAt := range repo.purged {
+		if now.After(purgedAt.Add(repo.deletedRetention)) {
+			delete(repo.purged, handle)
+		}
+	}
+}
+
+// scanChildren returns the parent of each copy-on-write child by its handle.
+func (repo *repository) scanChildren() (map[string]string, error) {
+	allVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := map[string]string{}
+	for _, candidate := range allVolumes {
+		isView, err := candidate.IsView()
+		if err != nil || isView {
+			continue
+		}
+
+		candidateParent, found, err := candidate.Parent()
+		if err != nil || !found {
+			continue
+		}
+
+		parents[candidate.Handle()] = candidateParent.Handle()
+	}
+
+	return parents, nil
+}
+
+func (repo *repository) scanViews() (map[string]string, error) {
+	allVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	bases := map[string]string{}
+	for _, candidate := range allVolumes {
+		isView, err := candidate.IsView()
+		if err != nil || !isView {
+			continue
+		}
+
+		candidateBase, found, err := candidate.Parent()
+		if err != nil || !found {
+			continue
+		}
+
+		bases[candidate.Handle()] = candidateBase.Handle()
+	}
+
+	return bases, nil
+}
+
+// DestroyVolumeAndDescendants destroys the volume with the given options,
+// and each of its descendants with the force-cascade reason.
+func (repo *repository) DestroyVolumeAndDescendants(handle string, opts DestroyOptions) ([]string, error) {
+	var destroyed []string
+
+	err := repo.cascade(handle, opts, func(handle string, opts DestroyOptions) error {
+		err := repo.DestroyVolume(handle, opts)
+		if err != nil {
+			return err
+		}
+
+		destroyed = append(destroyed, handle)
+
+		return nil
+	})
+
+	return destroyed, err
+}
+
+func (repo *repository) PlanDestroy(handle string, opts DestroyOptions, descendants bool) ([]string, error) {
+	var planned []string
+
+	plan := func(handle string, opts DestroyOptions) error {
+		// with descendants, the children are planned to be gone first
+		err := repo.checkDestroy(handle, opts, !descendants)
+		if err != nil {
+			return err
+		}
+
+		planned = append(planned, handle)
+
+		return nil
+	}
+
+	var err error
+	if descendants {
+		err = repo.cascade(handle, opts, plan)
+	} else {
+		err = plan(handle, opts)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return planned, nil
+}
+
+// cascade calls destroy for each of the volume's descendants with the
+// force-cascade reason, each one's descendants before it, and then for the
+// volume itself with the given options. It stops at the first failure.
+func (repo *repository) cascade(handle string, opts DestroyOptions, destroy func(string, DestroyOptions) error) error {
+	allVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	children := map[string][]string{}
+	for _, candidate := range allVolumes {
+		if candidate.Handle() == handle {
+			found = true
+		}
+
+		candidateParent, hasParent, err := candidate.Parent()
+		if err != nil || !hasParent {
+			continue
+		}
+
+		children[candidateParent.Handle()] = append(children[candidateParent.Handle()], candidate.Handle())
+	}
+
+	if !found {
+		return ErrVolumeDoesNotExist
+	}
+
+	var visit func(string, DestroyOptions) error
+	visit = func(handle string, opts DestroyOptions) error {
+		cascadeOpts := DestroyOptions{
+			Reason:     DestroyReasonForceCascade,
+			Annotation: "ancestor " + handle + " destroyed",
+		}
+
+		for _, child := range children[handle] {
+			err := visit(child, cascadeOpts)
+			if err != nil {
+				return err
+			}
+		}
+
+		return destroy(handle, opts)
+	}
+
+	return visit(handle, opts)
+}
+
+// checkDestroy fails as destroying the volume would before it got to
+// destroying anything.
+func (repo *repository) checkDestroy(handle string, opts DestroyOptions, checkChildren bool) error {
+	logger := repo.logger.Session("check-destroy", lager.Data{
+		"volume": handle,
+	})
+
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, opts.LeaseToken)
+	if err != nil {
+		return err
+	}
+
+	if !checkChildren {
+		return nil
+	}
+
+	children, err := repo.childIndex.Children(handle, repo.scanChildren)
+	if err != nil {
+		logger.Error("failed-to-list-children", err)
+		return err
+	}
+
+	if len(children) > 0 {
+		logger.Info("volume-has-children", lager.Data{"children": children})
+		return ErrVolumeHasChildren
+	}
+
+	return nil
+}
+
+func (repo *repository) CreateVolume(handle string, strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool, opts CreateOptions) (Volume, error) {
+	logger := repo.logger.Session("create-volume", lager.Data{"handle": handle})
+
+	var fingerprint string
+	if opts.IdempotencyKey != "" {
+		fingerprint = createFingerprint(strategy, properties, ttlInSeconds, isPrivileged, opts)
+
+		// a retry waits for the create it is retrying, rather than racing it
+		repo.locker.Lock(createKeyLock(opts.IdempotencyKey))
+		defer repo.locker.Unlock(createKeyLock(opts.IdempotencyKey))
+
+		created, found, err := repo.createdWithKey(logger, opts.IdempotencyKey, fingerprint)
+		if err != nil {
+			return Volume{}, err
+		}
+
+		if found {
+			logger.Info("already-created", lager.Data{"volume": created.Handle})
+			return created, nil
+		}
+	}
+
+	err := repo.validateProperties(properties)
+	if err != nil {
+		logger.Info("invalid-properties", lager.Data{"error": err.Error()})
+		return Volume{}, err
+	}
+
+	err = repo.guardFreeInodes(logger)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	taken, err := repo.handleTaken(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, err
+	}
+
+	if taken {
+		logger.Info("volume-already-exists")
+		return Volume{}, ErrVolumeAlreadyExists
+	}
+
+	var parentHandle string
+	switch parent := strategy.(type) {
+	case ViewStrategy:
+		parentHandle = parent.BaseHandle
+	case COWStrategy:
+		parentHandle = parent.ParentHandle
+	}
+
+	_, isView := strategy.(ViewStrategy)
+	if parentHandle != "" {
+		// keep the parent from being destroyed or renamed before the child
+		// is live
+		repo.locker.Lock(parentHandle)
+		defer repo.locker.Unlock(parentHandle)
+	}
+
+	filesystem := repo.filesystem
+	if opts.Encrypted {
+		filesystem = encryptingFilesystem{repo.filesystem}
+	}
+
+	initVolume, err := strategy.Materialize(logger, handle, filesystem)
+	if err != nil {
+		if os.IsExist(err) {
+			// another volume with the handle is being created
+			logger.Info("volume-already-exists")
+			return Volume{}, ErrVolumeAlreadyExists
+		}
+
+		logger.Error("failed-to-materialize-strategy", err)
+		return Volume{}, err
+	}
+
+	var initialized bool
+	defer func() {
+		if !initialized {
+			initVolume.Destroy()
+		}
+	}()
+
+	// a copy of an encrypted volume is encrypted whether asked to be or not
+	isEncrypted, err := initVolume.LoadEncrypted()
+	if err != nil {
+		logger.Error("failed-to-load-encrypted", err)
+		return Volume{}, err
+	}
+
+	if opts.Encrypted && !isEncrypted {
+		logger.Info("strategy-not-encryptable", lager.Data{"strategy": strategy.Type()})
+		return Volume{}, ErrStrategyNotEncryptable
+	}
+
+	err = initVolume.StoreProperties(properties)
+	if err != nil {
+		logger.Error("failed-to-set-properties", err)
+		return Volume{}, err
+	}
+
+	if opts.SizeInBytes > 0 {
+		err = initVolume.SetQuota(opts.SizeInBytes)
+		if err != nil {
+			logger.Error("failed-to-set-quota", err, lager.Data{"size-in-bytes": opts.SizeInBytes})
+			return Volume{}, err
+		}
+	}
+
+	ttl := TTL(ttlInSeconds)
+
+	expiresAt, err := initVolume.StoreTTL(ttl)
+	if err != nil {
+		logger.Error("failed-to-set-properties", err)
+		return Volume{}, err
+	}
+
+	if opts.RenewTTLOnAccess {
+		err = initVolume.StoreRenewTTLOnAccess()
+		if err != nil {
+			logger.Error("failed-to-set-ttl-renewal", err)
+			return Volume{}, err
+		}
+	}
+
+	if isView {
+		isPrivileged, err = repo.basePrivileged(initVolume)
+		if err != nil {
+			logger.Error("failed-to-load-base-privileged", err)
+			return Volume{}, err
+		}
+	}
+
+	err = initVolume.StorePrivileged(isPrivileged)
+	if err != nil {
+		logger.Error("failed-to-set-privileged", err)
+		return Volume{}, err
+	}
+
+	createdAt, err := initVolume.StoreCreated(strategy.Type())
+	if err != nil {
+		logger.Error("failed-to-set-created", err)
+		return Volume{}, err
+	}
+
+	if opts.IdempotencyKey != "" {
+		err = initVolume.StoreCreateKey(opts.IdempotencyKey, fingerprint)
+		if err != nil {
+			logger.Error("failed-to-set-create-key", err)
+			return Volume{}, err
+		}
+	}
+
+	// views share the base's data, so they are frozen from the start rather
+	// than namespaced
+	if !isView {
+		err = repo.namespacer(isPrivileged).NamespacePath(logger, initVolume.DataPath())
+		if err != nil {
+			logger.Error("failed-to-namespace-data", err)
+			return Volume{}, err
+		}
+	}
+
+	mountOptions := opts.MountOptions
+	if len(mountOptions) > 0 {
+		err = initVolume.SetMountOptions(mountOptions)
+		if err != nil {
+			logger.Error("failed-to-set-mount-options", err, lager.Data{"mount-options": mountOptions})
+			return Volume{}, err
+		}
+
+		mountOptions, err = initVolume.LoadMountOptions()
+		if err != nil {
+			logger.Error("failed-to-load-mount-options", err)
+			return Volume{}, err
+		}
+	}
+
+	// made read-only after being given its mount options, which would be
+	// refused by a read-only subvolume with btrfs
+	if opts.ReadOnly {
+		err = initVolume.MakeReadOnly()
+		if err != nil {
+			logger.Error("failed-to-make-read-only", err)
+			return Volume{}, err
+		}
+	}
+
+	// a read-only volume is frozen too, so that the API refuses to change
+	// its contents or privileges as well as anything else would
+	frozen := isView || opts.ReadOnly
+
+	var committedAt time.Time
+	if frozen {
+		committedAt, err = initVolume.StoreCommitted(true)
+		if err != nil {
+			logger.Error("failed-to-freeze-volume", err)
+			return Volume{}, err
+		}
+	}
+
+	liveVolume, err := initVolume.Initialize()
+	if err != nil {
+		if os.IsExist(err) {
+			// a volume with the handle went live since it was looked up
+			logger.Info("volume-already-exists")
+			return Volume{}, ErrVolumeAlreadyExists
+		}
+
+		logger.Error("failed-to-initialize-volume", err)
+		return Volume{}, err
+	}
+
+	initialized = true
+
+	repo.propertyIndex.Update(handle, properties)
+
+	if parentHandle != "" && !isView {
+		repo.childIndex.Add(parentHandle, handle)
+	}
+
+	if isView {
+		repo.viewIndex.Add(parentHandle, handle)
+	}
+
+	if opts.IdempotencyKey != "" {
+		repo.createKeys.Add(opts.IdempotencyKey, handle)
+	}
+
+	repo.events.Publish(Event{
+		Type:       EventCreated,
+		Handle:     handle,
+		Properties: properties,
+		At:         createdAt,
+	})
+
+	// the volume exists by now, so it is returned without them rather than
+	// failing the create
+	driver, filesystemType, err := liveVolume.LoadBacking()
+	if err != nil {
+		logger.Error("failed-to-load-backing", err)
+	}
+
+	// a view's data is its base's rather than derived from it
+	cowParentHandle := parentHandle
+	if isView {
+		cowParentHandle = ""
+	}
+
+	return Volume{
+		Handle:     liveVolume.Handle(),
+		Path:       liveVolume.DataPath(),
+		Properties: properties,
+		TTL:        ttl,
+		ExpiresAt:  expiresAt,
+
+		RenewTTLOnAccess: opts.RenewTTLOnAccess,
+
+		ParentHandle: cowParentHandle,
+
+		CreatedAt:  createdAt,
+		ModifiedAt: createdAt,
+		Strategy:   strategy.Type(),
+
+		Committed:   frozen,
+		CommittedAt: committedAt,
+		Frozen:      frozen,
+		ReadOnly:    opts.ReadOnly,
+
+		MountOptions: mountOptions,
+		Encrypted:    isEncrypted,
+
+		Driver:         driver,
+		FilesystemType: filesystemType,
+	}, nil
+}
+
+// createdWithKey returns the volume that was created with the idempotency
+// key, if any, as long as it was asked to be created as the fingerprint says.
+// handleTaken returns whether a volume has the handle, deleted or not.
+func (repo *repository) handleTaken(handle string) (bool, error) {
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil || found {
+		return found, err
+	}
+
+	_, found, err = repo.filesystem.LookupDeletedVolume(handle)
+
+	return found, err
+}
+
+func (repo *repository) createdWithKey(logger lager.Logger, key string, fingerprint string) (Volume, bool, error) {
+	handle, found, err := repo.createKeys.Lookup(key, repo.scanCreateKeys)
+	if err != nil {
+		logger.Error("failed-to-scan-create-keys", err)
+		return Volume{}, false, err
+	}
+
+	if !found {
+		return Volume{}, false, nil
+	}
+
+	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, false, err
+	}
+
+	if !found {
+		// gone without the repository knowing, so the key is free again
+		repo.createKeys.Remove(handle)
+		return Volume{}, false, nil
+	}
+
+	_, createdFingerprint, err := liveVolume.LoadCreateKey()
+	if err != nil {
+		logger.Error("failed-to-load-create-key", err)
+		return Volume{}, false, err
+	}
+
+	if createdFingerprint != fingerprint {
+		logger.Info("idempotency-key-conflict", lager.Data{"volume": handle})
+		return Volume{}, false, ErrIdempotencyKeyConflict
+	}
+
+	volume, err := repo.volumeFrom(liveVolume)
+	if err == ErrVolumeDoesNotExist {
+		return Volume{}, false, nil
+	}
+
+	if err != nil {
+		logger.Error("failed-to-hydrate-volume", err)
+		return Volume{}, false, err
+	}
+
+	return volume, true, nil
+}
+
+func (repo *repository) scanCreateKeys() (map[string]string, error) {
+	allVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	handles := map[string]string{}
+	for _, candidate := range allVolumes {
+		key, _, err := candidate.LoadCreateKey()
+		if err != nil || key == "" {
+			continue
+		}
+
+		handles[key] = candidate.Handle()
+	}
+
+	return handles, nil
+}
+
+// createKeyLock is what creates with the idempotency key lock, apart from the
+// handles of volumes.
+func createKeyLock(key string) string {
+	return "create-key/" + key
+}
+
+// createFingerprint identifies what a volume was asked to be created as, for
+// a retry of its create to be told apart from another create reusing its
+// idempotency key. The handle is left out, as it may have been generated for
+// each attempt.
+func createFingerprint(strategy Strategy, properties Properties, ttlInSeconds uint, isPrivileged bool, opts CreateOptions) string {
+	// maps are encoded in the order of their keys, so the same request
+	// always encodes the same
+	encoded, _ := json.Marshal(struct {
+		Strategy         string     `json:"strategy"`
+		Properties       Properties `json:"properties"`
+		TTLInSeconds     uint       `json:"ttl"`
+		Privileged       bool       `json:"privileged"`
+		SizeInBytes      int64      `json:"size_in_bytes"`
+		ReadOnly         bool       `json:"read_only"`
+		MountOptions     []string   `json:"mount_options"`
+		RenewTTLOnAccess bool       `json:"renew_ttl_on_access"`
+
+		// left out unless set, so that keys used before volumes could be
+		// encrypted still match
+		Encrypted bool `json:"encrypted,omitempty"`
+	}{
+		Strategy:         fmt.Sprintf("%#v", strategy),
+		Properties:       properties,
+		TTLInSeconds:     ttlInSeconds,
+		Privileged:       isPrivileged,
+		SizeInBytes:      opts.SizeInBytes,
+		ReadOnly:         opts.ReadOnly,
+		MountOptions:     opts.MountOptions,
+		RenewTTLOnAccess: opts.RenewTTLOnAccess,
+		Encrypted:        opts.Encrypted,
+	})
+
+	sum := sha256.Sum256(encoded)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (repo *repository) RenameVolume(handle string, newHandle string) (Volume, error) {
+	logger := repo.logger.Session("rename-volume", lager.Data{
+		"volume":     handle,
+		"new-handle": newHandle,
+	})
+
+	if newHandle == "" || newHandle == "." || newHandle == ".." || strings.ContainsAny(newHandle, `/\`) {
+		logger.Info("invalid-handle")
+		return Volume{}, ErrInvalidHandle
+	}
+
+	// stream-ins, stream-outs, and anything else done to the volume start
+	// with its lock, so none is under way while it is held unless counted
+	// as a stream
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return Volume{}, ErrVolumeDoesNotExist
+	}
+
+	if repo.isStreaming(handle) {
+		logger.Info("volume-is-streaming")
+		return Volume{}, ErrVolumeIsStreaming
+	}
+
+	renamed, err := liveVolume.Rename(newHandle)
+	if err != nil {
+		if os.IsExist(err) {
+			logger.Info("volume-already-exists")
+			return Volume{}, ErrVolumeAlreadyExists
+		}
+
+		logger.Error("failed-to-rename", err)
+		return Volume{}, err
+	}
+
+	logger.Info("renamed")
+
+	volume, err := repo.volumeFrom(renamed)
+	if err != nil {
+		logger.Error("failed-to-hydrate-volume", err)
+		return Volume{}, ErrVolumeIsCorrupted
+	}
+
+	repo.propertyIndex.Remove(handle)
+	repo.propertyIndex.Update(newHandle, volume.Properties)
+	repo.childIndex.Rename(handle, newHandle)
+	repo.viewIndex.Rename(handle, newHandle)
+	repo.createKeys.Rename(handle, newHandle)
+	repo.leases.Rename(handle, newHandle)
+
+	repo.events.Publish(Event{
+		Type:           EventRenamed,
+		Handle:         newHandle,
+		PreviousHandle: handle,
+		Properties:     volume.Properties,
+		At:             repo.clock.Now(),
+	})
+
+	return volume, nil
+}
+
+func (repo *repository) Promote(handle string) (Volume, error) {
+	logger := repo.logger.Session("promote", lager.Data{
+		"volume": handle,
+	})
+
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return Volume{}, ErrVolumeDoesNotExist
+	}
+
+	if repo.isStreaming(handle) {
+		logger.Info("volume-is-streaming")
+		return Volume{}, ErrVolumeIsStreaming
+	}
+
+	err = liveVolume.Promote()
+	if err != nil {
+		if err == ErrPromoteWithChildren {
+			logger.Info("volume-has-children")
+			return Volume{}, err
+		}
+
+		logger.Error("failed-to-promote", err)
+		return Volume{}, err
+	}
+
+	logger.Info("promoted")
+
+	repo.childIndex.RemoveParent(handle)
+
+	volume, err := repo.volumeFrom(liveVolume)
+	if err != nil {
+		logger.Error("failed-to-hydrate-volume", err)
+		return Volume{}, ErrVolumeIsCorrupted
+	}
+
+	return volume, nil
+}
+
+func (repo *repository) CloneVolume(srcHandle string, handle string) (Volume, error) {
+	logger := repo.logger.Session("clone-volume", lager.Data{
+		"source": srcHandle,
+		"handle": handle,
+	})
+
+	err := repo.guardFreeInodes(logger)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	// keep the source from being streamed into or destroyed while it is
+	// copied, so that the clone is of one point in time; the locks are taken
+	// in the same order as stream-ins take them
+	repo.streamInLocker.Lock(srcHandle, "")
+	defer repo.streamInLocker.Unlock(srcHandle, "")
+
+	repo.locker.Lock(srcHandle)
+	defer repo.locker.Unlock(srcHandle)
+
+	source, found, err := repo.filesystem.LookupVolume(srcHandle)
+	if err != nil {
+		logger.Error("failed-to-lookup-source", err)
+		return Volume{}, err
+	}
+
+	if !found {
+		logger.Info("source-not-found")
+		return Volume{}, ErrVolumeDoesNotExist
+	}
+
+	taken, err := repo.handleTaken(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, err
+	}
+
+	if taken {
+		logger.Info("volume-already-exists")
+		return Volume{}, ErrVolumeAlreadyExists
+	}
+
+	properties, err := source.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-load-properties", err)
+		return Volume{}, err
+	}
+
+	ttl, _, err := source.LoadTTL()
+	if err != nil {
+		logger.Error("failed-to-load-ttl", err)
+		return Volume{}, err
+	}
+
+	renewTTLOnAccess, err := source.LoadRenewTTLOnAccess()
+	if err != nil {
+		logger.Error("failed-to-load-ttl-renewal", err)
+		return Volume{}, err
+	}
+
+	isPrivileged, err := source.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-load-privileged", err)
+		return Volume{}, err
+	}
+
+	initVolume, err := source.NewClone(handle)
+	if err != nil {
+		if os.IsExist(err) {
+			// another volume with the handle is being created
+			logger.Info("volume-already-exists")
+			return Volume{}, ErrVolumeAlreadyExists
+		}
+
+		logger.Error("failed-to-clone", err)
+		return Volume{}, err
+	}
+
+	var initialized bool
+	defer func() {
+		if !initialized {
+			initVolume.Destroy()
+		}
+	}()
+
+	err = initVolume.StoreProperties(properties)
+	if err != nil {
+		logger.Error("failed-to-set-properties", err)
+		return Volume{}, err
+	}
+
+	expiresAt, err := initVolume.StoreTTL(ttl)
+	if err != nil {
+		logger.Error("failed-to-set-ttl", err)
+		return Volume{}, err
+	}
+
+	if renewTTLOnAccess {
+		err = initVolume.StoreRenewTTLOnAccess()
+		if err != nil {
+			logger.Error("failed-to-set-ttl-renewal", err)
+			return Volume{}, err
+		}
+	}
+
+	// the copied data is already namespaced as the source's was
+	err = initVolume.StorePrivileged(isPrivileged)
+	if err != nil {
+		logger.Error("failed-to-set-privileged", err)
+		return Volume{}, err
+	}
+
+	createdAt, err := initVolume.StoreCreated(StrategyClone)
+	if err != nil {
+		logger.Error("failed-to-set-created", err)
+		return Volume{}, err
+	}
+
+	// the clone of an encrypted volume is encrypted with a key of its own
+	encrypted, err := initVolume.LoadEncrypted()
+	if err != nil {
+		logger.Error("failed-to-load-encrypted", err)
+		return Volume{}, err
+	}
+
+	liveVolume, err := initVolume.Initialize()
+	if err != nil {
+		logger.Error("failed-to-initialize-volume", err)
+		return Volume{}, err
+	}
+
+	initialized = true
+
+	repo.propertyIndex.Update(handle, properties)
+
+	repo.events.Publish(Event{
+		Type:       EventCreated,
+		Handle:     handle,
+		Properties: properties,
+		At:         createdAt,
+	})
+
+	logger.Info("cloned")
+
+	return Volume{
+		Handle:     liveVolume.Handle(),
+		Path:       liveVolume.DataPath(),
+		Properties: properties,
+		TTL:        ttl,
+		ExpiresAt:  expiresAt,
+
+		RenewTTLOnAccess: renewTTLOnAccess,
+
+		Encrypted: encrypted,
+
+		CreatedAt:  createdAt,
+		ModifiedAt: createdAt,
+		Strategy:   StrategyClone,
+	}, nil
+}
+
+func (repo *repository) basePrivileged(view FilesystemInitVolume) (bool, error) {
+	base, found, err := view.Parent()
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		return false, ErrParentVolumeNotFound
+	}
+
+	return base.LoadPrivileged()
+}
+
+func (repo *repository) ListVolumes(queryProperties Properties) (Volumes, []string, error) {
+	logger := repo.logger.Session("list-volumes")
+
+	if repo.propertyIndex.Covers(queryProperties) {
+		return repo.listIndexedVolumes(logger, queryProperties)
+	}
+
+	return repo.listAllVolumes(logger, queryProperties)
+}
+
+func (repo *repository) listIndexedVolumes(logger lager.Logger, queryProperties Properties) (Volumes, []string, error) {
+	handles, err := repo.propertyIndex.Lookup(queryProperties, func() (map[string]Properties, error) {
+		volumes, _, err := repo.listAllVolumes(logger, Properties{})
+		if err != nil {
+			return nil, err
+		}
+
+		properties := map[string]Properties{}
+		for _, volume := range volumes {
+			properties[volume.Handle] = volume.Properties
+		}
+
+		return properties, nil
+	})
+	if err != nil {
+		logger.Error("failed-to-build-property-index", err)
+		return nil, nil, err
+	}
+
+	healthyVolumes := make(Volumes, 0, len(handles))
+	corruptedVolumeHandles := []string{}
+
+	for _, handle := range handles {
+		liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+		if err != nil {
+			logger.Error("failed-to-lookup-volume", err, lager.Data{"volume": handle})
+			return nil, nil, err
+		}
+
+		if !found {
+			continue
+		}
+
+		volume, err := repo.volumeFrom(liveVolume)
+		if err == ErrVolumeDoesNotExist {
+			continue
+		}
+
+		if err != nil {
+			corruptedVolumeHandles = append(corruptedVolumeHandles, handle)
+			logger.Error("failed-hydrating-volume", err)
+			continue
+		}
+
+		// the rest of the query is not indexed
+		if volume.Properties.HasProperties(queryProperties) {
+			healthyVolumes = append(healthyVolumes, volume)
+		}
+	}
+
+	return healthyVolumes, corruptedVolumeHandles, nil
+}
+
+func (repo *repository) listAllVolumes(logger lager.Logger, queryProperties Properties) (Volumes, []string, error) {
+	liveVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		logger.Error("failed-to-list-volumes", err)
+		return nil, nil, err
+	}
+
+	healthyVolumes := make(Volumes, 0, len(liveVolumes))
+	corruptedVolumeHandles := []string{}
+
+	for _, liveVolume := range liveVolumes {
+		volume, err := repo.volumeFrom(liveVolume)
+		if err == ErrVolumeDoesNotExist {
+			continue
+		}
+
+		if err != nil {
+			corruptedVolumeHandles = append(corruptedVolumeHandles, liveVolume.Handle())
+			logger.Error("failed-hydrating-volume", err)
+			continue
+		}
+
+		if volume.Properties.HasProperties(queryProperties) {
+			healthyVolumes = append(healthyVolumes, volume)
+		}
+	}
+
+	return healthyVolumes, corruptedVolumeHandles, nil
+}
+
+func (repo *repository) GetVolume(handle string) (Volume, bool, error) {
+	logger := repo.logger.Session("get-volume", lager.Data{
+		"volume": handle,
+	})
+
+	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Volume{}, false, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return Volume{}, false, nil
+	}
+
+	err = repo.renewTTLOnAccess(logger, handle)
+	if err != nil && err != ErrVolumeDoesNotExist {
+		logger.Error("failed-to-renew-ttl", err)
+	}
+
+	volume, err := repo.volumeFrom(liveVolume)
+	if err == ErrVolumeDoesNotExist {
 		return Volume{}, false, nil
 	}
 
-	if err != nil {
-		logger.Error("failed-to-hydrate-volume", err)
-		return Volume{}, false, err
-	}
+	if err != nil {
+		logger.Error("failed-to-hydrate-volume", err)
+		return Volume{}, false, err
+	}
+
+	return volume, true, nil
+}
+
+func (repo *repository) VolumeExists(handle string) (bool, error) {
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		repo.logger.Error("failed-to-lookup-volume", err, lager.Data{"volume": handle})
+		return false, err
+	}
+
+	return found, nil
+}
+
+func (repo *repository) GetVolumeStats(handle string) (VolumeStats, bool, error) {
+	logger := repo.logger.Session("get-volume-stats", lager.Data{
+		"volume": handle,
+	})
+
+	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return VolumeStats{}, false, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return VolumeStats{}, false, nil
+	}
+
+	stats, err := liveVolume.Stats()
+	if err != nil {
+		logger.Error("failed-to-get-volume-stats", err)
+		return VolumeStats{}, false, err
+	}
+
+	return stats, true, nil
+}
+
+func (repo *repository) TotalUsage(groupBy string) (Usage, error) {
+	logger := repo.logger.Session("total-usage", lager.Data{
+		"group-by": groupBy,
+	})
+
+	freeBytes, err := repo.filesystem.FreeBytes()
+	if err != nil {
+		logger.Error("failed-to-get-free-bytes", err)
+		return Usage{}, err
+	}
+
+	liveVolumes, err := repo.filesystem.ListVolumes()
+	if err != nil {
+		logger.Error("failed-to-list-volumes", err)
+		return Usage{}, err
+	}
+
+	usage := Usage{
+		FreeBytes: freeBytes,
+		GroupedBy: groupBy,
+	}
+
+	if groupBy != "" {
+		usage.Groups = map[string]UsageGroup{}
+		usage.Ungrouped = &UsageGroup{}
+	}
+
+	for _, liveVolume := range liveVolumes {
+		handle := liveVolume.Handle()
+
+		var properties Properties
+		var err error
+		if groupBy != "" {
+			properties, err = liveVolume.LoadProperties()
+		}
+
+		var size int64
+		if err == nil {
+			size, err = liveVolume.Size()
+		}
+
+		if err == ErrVolumeDoesNotExist || os.IsNotExist(err) {
+			// destroyed since it was listed
+			continue
+		}
+
+		if err != nil {
+			logger.Error("failed-to-size-volume", err, lager.Data{"volume": handle})
+			usage.Failed = append(usage.Failed, handle)
+			continue
+		}
+
+		usage.add(size)
+
+		if groupBy == "" {
+			continue
+		}
+
+		value, found := properties[groupBy]
+		if !found {
+			usage.Ungrouped.add(size)
+			continue
+		}
+
+		group := usage.Groups[value]
+		group.add(size)
+		usage.Groups[value] = group
+	}
+
+	return usage, nil
+}
+
+func (repo *repository) GetProperty(handle string, propertyName string) (string, error) {
+	logger := repo.logger.Session("get-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return "", err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return "", ErrVolumeDoesNotExist
+	}
+
+	properties, err := volume.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-read-properties", err)
+		return "", err
+	}
+
+	value, found := properties[propertyName]
+	if !found {
+		logger.Debug("property-not-found")
+		return "", ErrPropertyDoesNotExist
+	}
+
+	return value, nil
+}
+
+func (repo *repository) SetProperty(handle string, propertyName string, propertyValue string, expected *PropertyExpectation, leaseToken string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("set-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, leaseToken)
+	if err != nil {
+		return err
+	}
+
+	err = repo.validateProperties(Properties{propertyName: propertyValue})
+	if err != nil {
+		logger.Info("invalid-property", lager.Data{"error": err.Error()})
+		return err
+	}
+
+	properties, err := volume.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-read-properties", err, lager.Data{
+			"volume": handle,
+		})
+		return err
+	}
+
+	if expected != nil && !expected.MetBy(properties, propertyName) {
+		logger.Info("property-conflict", lager.Data{"expected": *expected})
+		return ErrPropertyConflict
+	}
+
+	properties = properties.UpdateProperty(propertyName, propertyValue)
+
+	err = volume.StoreProperties(properties)
+	if err != nil {
+		logger.Error("failed-to-store-properties", err)
+		return err
+	}
+
+	repo.propertyIndex.Update(handle, properties)
+
+	repo.events.Publish(Event{
+		Type:       EventPropertyChanged,
+		Handle:     handle,
+		Properties: properties,
+		At:         repo.clock.Now(),
+	})
+
+	// the property is set, so a failure to record when must not fail it
+	_, err = volume.StoreModified()
+	if err != nil {
+		logger.Error("failed-to-record-modification", err)
+	}
+
+	return nil
+}
+
+func (repo *repository) SetProperties(handle string, newProperties Properties, leaseToken string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("set-properties", lager.Data{
+		"volume":     handle,
+		"properties": newProperties,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, leaseToken)
+	if err != nil {
+		return err
+	}
+
+	err = repo.validateProperties(newProperties)
+	if err != nil {
+		logger.Info("invalid-properties", lager.Data{"error": err.Error()})
+		return err
+	}
+
+	properties, err := volume.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-read-properties", err)
+		return err
+	}
+
+	properties = properties.UpdateProperties(newProperties)
+
+	// the properties file is replaced whole, so either all of them are set
+	// or none are
+	err = volume.StoreProperties(properties)
+	if err != nil {
+		logger.Error("failed-to-store-properties", err)
+		return err
+	}
+
+	repo.propertyIndex.Update(handle, properties)
+
+	repo.events.Publish(Event{
+		Type:       EventPropertyChanged,
+		Handle:     handle,
+		Properties: properties,
+		At:         repo.clock.Now(),
+	})
+
+	_, err = volume.StoreModified()
+	if err != nil {
+		logger.Error("failed-to-record-modification", err)
+	}
+
+	return nil
+}
+
+func (repo *repository) DeleteProperty(handle string, propertyName string, leaseToken string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("delete-property", lager.Data{
+		"volume":   handle,
+		"property": propertyName,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, leaseToken)
+	if err != nil {
+		return err
+	}
+
+	properties, err := volume.LoadProperties()
+	if err != nil {
+		logger.Error("failed-to-read-properties", err)
+		return err
+	}
+
+	if _, found := properties[propertyName]; !found {
+		return nil
+	}
+
+	properties = properties.DeleteProperty(propertyName)
+
+	err = volume.StoreProperties(properties)
+	if err != nil {
+		logger.Error("failed-to-store-properties", err)
+		return err
+	}
+
+	repo.propertyIndex.Update(handle, properties)
+
+	repo.events.Publish(Event{
+		Type:       EventPropertyChanged,
+		Handle:     handle,
+		Properties: properties,
+		At:         repo.clock.Now(),
+	})
+
+	_, err = volume.StoreModified()
+	if err != nil {
+		logger.Error("failed-to-record-modification", err)
+	}
+
+	return nil
+}
+
+func (repo *repository) AcquireLease(handle string, token string, duration time.Duration) (Lease, error) {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("acquire-lease", lager.Data{
+		"volume":   handle,
+		"duration": duration.String(),
+		"renewing": token != "",
+	})
+
+	if duration <= 0 {
+		logger.Info("invalid-lease-duration")
+		return Lease{}, ErrInvalidLeaseDuration
+	}
+
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return Lease{}, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return Lease{}, ErrVolumeDoesNotExist
+	}
+
+	lease, err := repo.leases.Acquire(handle, token, repo.clock.Now(), duration)
+	if err == ErrVolumeIsLeased || err == ErrLeaseNotHeld {
+		logger.Info("lease-not-acquired", lager.Data{"error": err.Error()})
+		return Lease{}, err
+	}
+
+	if err != nil {
+		logger.Error("failed-to-acquire-lease", err)
+		return Lease{}, err
+	}
+
+	logger.Debug("acquired", lager.Data{"expires-at": lease.ExpiresAt})
+
+	return lease, nil
+}
+
+func (repo *repository) ReleaseLease(handle string, token string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("release-lease", lager.Data{
+		"volume": handle,
+	})
+
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	err = repo.leases.Release(handle, token, repo.clock.Now())
+	if err != nil {
+		logger.Info("volume-is-leased")
+		return err
+	}
+
+	logger.Debug("released")
+
+	return nil
+}
+
+// checkLease returns ErrVolumeIsLeased if a lease is held on the volume with
+// another token than the one given.
+// validateProperties checks the properties against the limits, then
+// against their label schemas.
+func (repo *repository) validateProperties(properties Properties) error {
+	err := repo.propertyLimits.Validate(properties)
+	if err != nil {
+		return err
+	}
+
+	return repo.labelSchemas.Validate(properties)
+}
+
+func (repo *repository) checkLease(logger lager.Logger, handle string, token string) error {
+	err := repo.leases.Check(handle, token, repo.clock.Now())
+	if err != nil {
+		logger.Info("volume-is-leased")
+	}
+
+	return err
+}
+
+func (repo *repository) SetTTL(handle string, ttl uint) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("set-ttl", lager.Data{
+		"volume": handle,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	_, err = volume.StoreTTL(TTL(ttl))
+	if err != nil {
+		logger.Error("failed-to-store-ttl", err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) SetExpiresAt(handle string, expiresAt time.Time) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("set-expires-at", lager.Data{
+		"volume":     handle,
+		"expires-at": expiresAt,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	// a TTL of zero would never expire, so a volume that is already due
+	// keeps a TTL of a second, and is pending destroy straight away
+	ttl := TTL(1)
+
+	remaining := expiresAt.Sub(repo.clock.Now())
+	if remaining > time.Second {
+		ttl = TTL(math.Ceil(remaining.Seconds()))
+	}
+
+	err = volume.StoreExpiry(ttl, expiresAt)
+	if err != nil {
+		logger.Error("failed-to-store-expiry", err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) TouchAccess(handle string) error {
+	logger := repo.logger.Session("touch-access", lager.Data{
+		"volume": handle,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	_, err = volume.StoreLastAccessed()
+	if err != nil {
+		logger.Error("failed-to-store-last-accessed", err)
+		return err
+	}
+
+	err = repo.renewTTLOnAccess(logger, handle)
+	if err != nil {
+		logger.Error("failed-to-renew-ttl", err)
+		return err
+	}
+
+	return nil
+}
+
+// renewTTLOnAccess starts the volume's TTL over if it was created to renew
+// on access. It holds the volume's lock while it does, so that destroying
+// the volume for having expired either happens first or sees the renewal.
+func (repo *repository) renewTTLOnAccess(logger lager.Logger, handle string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrVolumeDoesNotExist
+	}
+
+	renew, err := volume.LoadRenewTTLOnAccess()
+	if err != nil || !renew {
+		return err
+	}
+
+	ttl, _, err := volume.LoadTTL()
+	if err != nil || ttl.IsUnlimited() {
+		return err
+	}
+
+	expiresAt, err := volume.StoreTTL(ttl)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("renewed-ttl", lager.Data{"ttl": ttl, "expires-at": expiresAt})
+
+	return nil
+}
+
+func (repo *repository) Materialize(handle string) (bool, error) {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("materialize", lager.Data{
+		"volume": handle,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return false, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return false, ErrVolumeDoesNotExist
+	}
+
+	mounted, err := volume.Materialize()
+	if err != nil {
+		logger.Error("failed-to-mount", err)
+		return false, err
+	}
+
+	if mounted {
+		logger.Info("mounted")
+	}
+
+	_, err = repo.volumeFrom(volume)
+	if err != nil {
+		logger.Error("failed-to-read-metadata", err)
+		return false, err
+	}
+
+	_, err = ioutil.ReadDir(volume.DataPath())
+	if err != nil {
+		logger.Error("failed-to-read-data", err)
+		return false, err
+	}
+
+	return mounted, nil
+}
+
+func (repo *repository) SetPrivileged(handle string, privileged bool) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	logger := repo.logger.Session("set-privileged", lager.Data{
+		"volume": handle,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	_, frozen, err := volume.LoadCommitted()
+	if err != nil {
+		logger.Error("failed-to-load-committed", err)
+		return err
+	}
+
+	if frozen {
+		logger.Info("volume-is-frozen")
+		return ErrVolumeIsFrozen
+	}
+
+	err = repo.namespacer(privileged).NamespacePath(logger, volume.DataPath())
+	if err != nil {
+		logger.Error("failed-to-namespace-volume", err)
+		return err
+	}
+
+	err = volume.StorePrivileged(privileged)
+	if err != nil {
+		logger.Error("failed-to-store-privileged", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetSELinuxLabel applies the label to everything in the volume. It is a
+// no-op on systems without SELinux.
+func (repo *repository) SetSELinuxLabel(handle string, label string) error {
+	logger := repo.logger.Session("set-selinux-label", lager.Data{
+		"volume": handle,
+		"label":  label,
+	})
+
+	if !validSELinuxLabel(label) {
+		logger.Info("invalid-label")
+		return ErrInvalidSELinuxLabel
+	}
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	// wait for in-flight streams, so that what they write is relabeled too
+	repo.streamInLocker.Lock(handle, "")
+	defer repo.streamInLocker.Unlock(handle, "")
+
+	_, frozen, err := volume.LoadCommitted()
+	if err != nil {
+		logger.Error("failed-to-load-committed", err)
+		return err
+	}
+
+	if frozen {
+		logger.Info("volume-is-frozen")
+		return ErrVolumeIsFrozen
+	}
+
+	privileged, err := volume.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-check-if-volume-is-privileged", err)
+		return err
+	}
+
+	err = repo.relabel(volume.DataPath(), label, privileged)
+	if err != nil {
+		logger.Error("failed-to-relabel", err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) CommitVolume(handle string, freeze bool) error {
+	logger := repo.logger.Session("commit-volume", lager.Data{
+		"volume": handle,
+		"freeze": freeze,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	// wait for any in-flight streams into the volume to finish; this may take
+	// a while, so the volume lock is only taken for the metadata update below
+	repo.streamInLocker.Lock(handle, "")
+	defer repo.streamInLocker.Unlock(handle, "")
+
+	err = syncTree(volume.DataPath())
+	if err != nil {
+		logger.Error("failed-to-sync-data", err)
+		return err
+	}
+
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	_, frozen, err := volume.LoadCommitted()
+	if err != nil {
+		logger.Error("failed-to-load-committed", err)
+		return err
+	}
+
+	// a frozen volume stays frozen
+	_, err = volume.StoreCommitted(frozen || freeze)
+	if err != nil {
+		logger.Error("failed-to-store-committed", err)
+		return err
+	}
+
+	logger.Info("committed")
+
+	return nil
+}
+
+func (repo *repository) StreamIn(ctx context.Context, handle string, path string, stream io.Reader, opts StreamInOptions) (bool, error) {
+	logger := repo.logger.Session("stream-in", lager.Data{
+		"volume":          handle,
+		"sub-path":        path,
+		"idempotency-key": opts.IdempotencyKey,
+		"selinux-label":   opts.SELinuxLabel,
+	})
+
+	if opts.SELinuxLabel != "" && !validSELinuxLabel(opts.SELinuxLabel) {
+		logger.Info("invalid-selinux-label")
+		return false, ErrInvalidSELinuxLabel
+	}
+
+	if !validContentEncoding(opts.ContentEncoding) {
+		logger.Info("unsupported-content-encoding", lager.Data{"content-encoding": opts.ContentEncoding})
+		return false, ErrUnsupportedContentEncoding
+	}
+
+	// a checksum that is already known is checked before any of the stream
+	// is read; one that comes after it is checked once it has been
+	if checksum := expectedChecksum(opts); checksum != "" && !validChecksum(checksum) {
+		logger.Info("invalid-checksum")
+		return false, ErrInvalidChecksum
+	}
+
+	err := repo.takeStreamSlot(ctx, logger, repo.streamsIn)
+	if err != nil {
+		return false, err
+	}
+
+	defer repo.streamsIn.release()
+
+	repo.startStream(handle)
+	defer repo.finishStream(handle)
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return false, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return false, ErrVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, opts.LeaseToken)
+	if err != nil {
+		return false, err
+	}
+
+	destinationPath := filepath.Join(volume.DataPath(), path)
+
+	logger = logger.WithData(lager.Data{
+		"full-path": destinationPath,
+	})
+
+	if !within(filepath.Clean(volume.DataPath()), destinationPath) {
+		logger.Info("unsafe-sub-path")
+		return false, ErrUnsafeSubPath
+	}
+
+	repo.streamInLocker.Lock(handle, path)
+	defer repo.streamInLocker.Unlock(handle, path)
+
+	_, frozen, err := volume.LoadCommitted()
+	if err != nil {
+		logger.Error("failed-to-load-committed", err)
+		return false, err
+	}
+
+	if frozen {
+		logger.Info("volume-is-frozen")
+		return false, ErrVolumeIsFrozen
+	}
+
+	if opts.IdempotencyKey != "" {
+		applied, err := repo.streamInApplied(volume, opts.IdempotencyKey)
+		if err != nil {
+			logger.Error("failed-to-load-stream-in-keys", err)
+			return false, err
+		}
+
+		if applied {
+			logger.Info("already-applied")
+			return false, ErrStreamInAlreadyApplied
+		}
+	}
+
+	err = repo.guardFreeInodes(logger)
+	if err != nil {
+		return false, err
+	}
+
+	// the sub-path may also lead out of the volume through a symlink already
+	// in it
+	safe, err := resolvesWithin(volume.DataPath(), destinationPath)
+	if err != nil {
+		logger.Error("failed-to-resolve-destination-path", err)
+		return false, err
+	}
+
+	if !safe {
+		logger.Info("unsafe-sub-path")
+		return false, ErrUnsafeSubPath
+	}
+
+	// only namespace what this stream owns: the destination and any parent
+	// directories created for it, not paths other streams may be writing
+	namespacePath := topmostMissingDir(volume.DataPath(), destinationPath)
+
+	_, err = os.Lstat(destinationPath)
+	destinationExisted := err == nil
+
+	err = os.MkdirAll(destinationPath, 0755)
+	if err != nil {
+		logger.Error("failed-to-create-destination-path", err)
+		return false, err
+	}
+
+	privileged, err := volume.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-check-if-volume-is-privileged", err)
+		return false, err
+	}
+
+	err = repo.namespacer(privileged).NamespacePath(logger, namespacePath)
+	if err != nil {
+		logger.Error("failed-to-namespace-path", err)
+		return false, err
+	}
+
+	// a partial extraction is cleaned up the same whether the disk filled up
+	// or the stream was given up on; entries is nil if nothing was extracted.
+	// it is not staged elsewhere first, as deltas and hard links refer to
+	// what is already at the destination
+	removeExtracted := func(entries *extractedEntries) {
+		if !destinationExisted {
+			os.RemoveAll(namespacePath)
+		} else if entries != nil {
+			entries.Remove(destinationPath)
+		}
+	}
+
+	canceled := func(entries *extractedEntries) (bool, error) {
+		logger.Info("canceled")
+		removeExtracted(entries)
+		return false, ctx.Err()
+	}
+
+	// a stream that breaks off part way, e.g. at a chunk failing its check,
+	// leaves what came before it extracted, so it is rolled back too
+	body := &errorTrackingReader{Reader: stream}
+
+	stream = &contextReader{Reader: body, ctx: ctx}
+
+	if opts.BytesPerSecond > 0 {
+		stream = newThrottledReader(ctx, repo.clock, stream, opts.BytesPerSecond)
+	}
+
+	decoded, checkDecoding, err := decodeContent(stream, opts.ContentEncoding)
+	if err != nil {
+		if ctx.Err() != nil {
+			return canceled(nil)
+		}
+
+		logger.Info("failed-to-decode-stream", lager.Data{"error": err.Error()})
+		return true, err
+	}
+
+	tarStream, closeStream, err := decompressStream(decoded)
+	if err != nil {
+		if ctx.Err() != nil {
+			return canceled(nil)
+		}
+
+		if decodeErr := checkDecoding(); decodeErr != nil {
+			logger.Info("failed-to-decode-stream", lager.Data{"error": decodeErr.Error()})
+			return true, decodeErr
+		}
+
+		if readErr, ok := err.(streamReadError); ok {
+			logger.Error("failed-to-read-stream", readErr.err)
+			return false, readErr.err
+		}
+
+		logger.Info("unknown-stream-format", lager.Data{"error": err.Error()})
+		return true, err
+	}
+
+	var digest hash.Hash
+	if opts.Checksum != nil {
+		digest = sha256.New()
+		tarStream = io.TeeReader(tarStream, digest)
+	}
+
+	// a volume whose room can't be found is streamed into until it fills up,
+	// which is still cleaned up after
+	room := uint64(math.MaxUint64)
+	if left, bounded, err := volume.Room(); err != nil {
+		logger.Info("failed-to-find-room", lager.Data{"error": err.Error()})
+	} else if bounded {
+		room = left
+	}
+
+	trackedStream, entries := trackExtractedEntries(tarStream, destinationPath, filepath.Clean(volume.DataPath()), opts.Delta, opts.Layer, room)
+
+	badStream, err := repo.streamIn(ctx, trackedStream, destinationPath, privileged, opts.Xattrs)
+
+	entries.Stop()
+
+	if err == nil && digest != nil {
+		// the tar ends before the stream does if it was padded, but the
+		// digest is of all of it, and a checksum sent after the stream is
+		// only there once the rest of it has been read too
+		_, err = io.Copy(ioutil.Discard, tarStream)
+		if err == nil {
+			_, err = io.Copy(ioutil.Discard, stream)
+		}
+	}
+
+	closeErr := closeStream()
+
+	decodeErr := checkDecoding()
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return canceled(entries)
+		}
+
+		if body.err != nil {
+			logger.Error("failed-to-read-stream", body.err)
+
+			removeExtracted(entries)
+
+			return false, body.err
+		}
+
+		if tooLarge, ok := entries.err.(StreamTooLargeError); ok {
+			logger.Info("stream-too-large", lager.Data{"size": tooLarge.Size, "room": tooLarge.Room})
+
+			removeExtracted(entries)
+
+			return false, tooLarge
+		}
+
+		if isNoSpace(err) {
+			logger.Error("ran-out-of-space", err, lager.Data{"bytes-written": entries.bytesRead})
+
+			removeExtracted(entries)
+
+			return false, NoSpaceError{BytesWritten: entries.bytesRead}
+		}
+
+		if entries.err == ErrUnsafeTarEntry {
+			logger.Info("unsafe-tar-entry", lager.Data{"entry": entries.unsafeEntry})
+
+			removeExtracted(entries)
+
+			return true, ErrUnsafeTarEntry
+		}
+
+		if decodeErr != nil {
+			logger.Info("failed-to-decode-stream", lager.Data{"error": decodeErr.Error()})
+			return true, decodeErr
+		}
+
+		if entries.err != nil {
+			return true, entries.err
+		}
+
+		return badStream, err
+	}
+
+	if decodeErr != nil {
+		logger.Info("failed-to-decode-stream", lager.Data{"error": decodeErr.Error()})
+		return true, decodeErr
+	}
+
+	if closeErr != nil {
+		logger.Info("failed-to-decompress-stream", lager.Data{"error": closeErr.Error()})
+		return true, closeErr
+	}
+
+	if digest != nil {
+		err = checkStreamChecksum(logger, expectedChecksum(opts), digest)
+		if err != nil {
+			removeExtracted(entries)
+			return true, err
+		}
+	}
+
+	if opts.Layer {
+		err = entries.ApplyWhiteouts(filepath.Clean(volume.DataPath()))
+		if err == ErrUnsafeTarEntry {
+			logger.Info("unsafe-whiteout")
+			return true, err
+		}
+
+		if err != nil {
+			logger.Error("failed-to-apply-whiteouts", err)
+			return false, err
+		}
+	}
+
+	if opts.SELinuxLabel != "" {
+		err = repo.relabel(destinationPath, opts.SELinuxLabel, privileged)
+		if err != nil {
+			logger.Error("failed-to-relabel", err)
+			return false, err
+		}
+	}
+
+	_, err = volume.StoreModified()
+	if err != nil {
+		logger.Error("failed-to-record-modification", err)
+	}
+
+	// like the key below, the digest is only a record of what landed
+	err = repo.recordDigest(handle, volume)
+	if err != nil {
+		logger.Error("failed-to-record-digest", err)
+	}
+
+	if opts.IdempotencyKey != "" {
+		// the stream has landed, so a failure to record it must not fail the
+		// request; a retry would apply it again
+		err = repo.recordStreamIn(handle, volume, opts.IdempotencyKey)
+		if err != nil {
+			logger.Error("failed-to-record-stream-in-key", err)
+		}
+	}
+
+	return false, nil
+}
+
+func (repo *repository) CopyIn(ctx context.Context, handle string, path string, sourceHandle string, opts CopyInOptions) error {
+	logger := repo.logger.Session("copy-in", lager.Data{
+		"volume":          handle,
+		"sub-path":        path,
+		"source":          sourceHandle,
+		"source-sub-path": opts.SourcePath,
+	})
+
+	if handle == sourceHandle && pathsOverlap(normalizeSubPath(path), normalizeSubPath(opts.SourcePath)) {
+		logger.Info("copy-overlaps-source")
+		return ErrCopyOverlapsSource
+	}
+
+	err := repo.takeStreamSlot(ctx, logger, repo.streamsIn)
+	if err != nil {
+		return err
+	}
+
+	defer repo.streamsIn.release()
+
+	repo.startStream(handle)
+	defer repo.finishStream(handle)
+
+	// the source is streamed out of as far as destroys are concerned
+	repo.startStream(sourceHandle)
+	defer repo.finishStream(sourceHandle)
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	source, found, err := repo.filesystem.LookupVolume(sourceHandle)
+	if err != nil {
+		logger.Error("failed-to-lookup-source", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("source-not-found")
+		return ErrSourceVolumeDoesNotExist
+	}
+
+	err = repo.checkLease(logger, handle, opts.LeaseToken)
+	if err != nil {
+		return err
+	}
+
+	destinationPath := filepath.Join(volume.DataPath(), path)
+	sourcePath := filepath.Join(source.DataPath(), opts.SourcePath)
+
+	logger = logger.WithData(lager.Data{
+		"full-path":        destinationPath,
+		"source-full-path": sourcePath,
+	})
+
+	if !within(filepath.Clean(volume.DataPath()), destinationPath) || !within(filepath.Clean(source.DataPath()), sourcePath) {
+		logger.Info("unsafe-sub-path")
+		return ErrUnsafeSubPath
+	}
+
+	unlock := repo.lockCopyPaths(handle, path, sourceHandle, opts.SourcePath)
+	defer unlock()
+
+	_, frozen, err := volume.LoadCommitted()
+	if err != nil {
+		logger.Error("failed-to-load-committed", err)
+		return err
+	}
+
+	if frozen {
+		logger.Info("volume-is-frozen")
+		return ErrVolumeIsFrozen
+	}
+
+	err = repo.guardFreeInodes(logger)
+	if err != nil {
+		return err
+	}
+
+	for _, paths := range [][2]string{{volume.DataPath(), destinationPath}, {source.DataPath(), sourcePath}} {
+		safe, err := resolvesWithin(paths[0], paths[1])
+		if err != nil {
+			logger.Error("failed-to-resolve-path", err)
+			return err
+		}
+
+		if !safe {
+			logger.Info("unsafe-sub-path")
+			return ErrUnsafeSubPath
+		}
+	}
+
+	sourceInfo, err := os.Lstat(sourcePath)
+	if os.IsNotExist(err) {
+		logger.Info("source-path-not-found")
+		return ErrSourcePathDoesNotExist
+	}
+
+	if err != nil {
+		logger.Error("failed-to-stat-source-path", err)
+		return err
+	}
+
+	privileged, err := volume.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-check-if-volume-is-privileged", err)
+		return err
+	}
+
+	sourcePrivileged, err := source.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-check-if-source-is-privileged", err)
+		return err
+	}
+
+	namespacePath := topmostMissingDir(volume.DataPath(), destinationPath)
+
+	_, err = os.Lstat(destinationPath)
+	destinationExisted := err == nil
+
+	err = os.MkdirAll(destinationPath, 0755)
+	if err != nil {
+		logger.Error("failed-to-create-destination-path", err)
+		return err
+	}
+
+	err = repo.namespacer(privileged).NamespacePath(logger, namespacePath)
+	if err != nil {
+		logger.Error("failed-to-namespace-path", err)
+		return err
+	}
+
+	if ctx.Err() != nil {
+		logger.Info("canceled")
+		return ctx.Err()
+	}
+
+	err = volume.CopyData(sourcePath, destinationPath)
+	if err != nil {
+		logger.Error("failed-to-copy", err)
+
+		if !destinationExisted {
+			os.RemoveAll(namespacePath)
+		}
+
+		return err
+	}
+
+	// what was copied is owned as it was in the source, which is only right
+	// for the volume if both are namespaced alike
+	if privileged != sourcePrivileged {
+		copiedPath := destinationPath
+		if !sourceInfo.IsDir() {
+			copiedPath = filepath.Join(destinationPath, sourceInfo.Name())
+		}
+
+		err = repo.namespacer(privileged).NamespacePath(logger, copiedPath)
+		if err != nil {
+			logger.Error("failed-to-namespace-copy", err)
+			return err
+		}
+	}
+
+	_, err = volume.StoreModified()
+	if err != nil {
+		logger.Error("failed-to-record-modification", err)
+	}
+
+	err = repo.recordDigest(handle, volume)
+	if err != nil {
+		logger.Error("failed-to-record-digest", err)
+	}
+
+	_, err = source.StoreLastAccessed()
+	if err != nil {
+		logger.Error("failed-to-store-source-last-accessed", err)
+	}
+
+	err = repo.renewTTLOnAccess(logger, sourceHandle)
+	if err != nil {
+		logger.Error("failed-to-renew-source-ttl", err)
+	}
+
+	return nil
+}
+
+// lockCopyPaths locks the paths a copy writes to and reads from as a
+// stream-in would, always in the same order, so that copies going both ways
+// between two volumes can't each hold the lock the other waits on.
+func (repo *repository) lockCopyPaths(handle string, path string, sourceHandle string, sourcePath string) func() {
+	locks := [][2]string{{handle, path}, {sourceHandle, sourcePath}}
+	if sourceHandle < handle || (sourceHandle == handle && sourcePath < path) {
+		locks[0], locks[1] = locks[1], locks[0]
+	}
+
+	for _, lock := range locks {
+		repo.streamInLocker.Lock(lock[0], lock[1])
+	}
+
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			repo.streamInLocker.Unlock(locks[i][0], locks[i][1])
+		}
+	}
+}
+
+func expectedChecksum(opts StreamInOptions) string {
+	if opts.Checksum == nil {
+		return ""
+	}
+
+	return opts.Checksum()
+}
+
+// checkStreamChecksum compares the digest of what was streamed with the
+// checksum it was expected to have, if any.
+func checkStreamChecksum(logger lager.Logger, expected string, digest hash.Hash) error {
+	if expected == "" {
+		return nil
+	}
+
+	if !validChecksum(expected) {
+		logger.Info("invalid-checksum")
+		return ErrInvalidChecksum
+	}
+
+	actual := digestPrefix + hex.EncodeToString(digest.Sum(nil))
+	if actual != expected {
+		logger.Info("checksum-mismatch", lager.Data{"expected": expected, "actual": actual})
+		return ErrStreamChecksumMismatch
+	}
+
+	return nil
+}
+
+func (repo *repository) streamInApplied(volume FilesystemLiveVolume, key string) (bool, error) {
+	keys, err := volume.LoadStreamInKeys()
+	if err != nil {
+		return false, err
+	}
+
+	appliedAt, found := keys[key]
+	if !found {
+		return false, nil
+	}
+
+	return repo.clock.Now().Sub(appliedAt) < repo.streamInIdempotencyWindow, nil
+}
+
+func (repo *repository) recordStreamIn(handle string, volume FilesystemLiveVolume, key string) error {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	keys, err := volume.LoadStreamInKeys()
+	if err != nil {
+		return err
+	}
+
+	if keys == nil {
+		keys = map[string]time.Time{}
+	}
+
+	now := repo.clock.Now()
+
+	for existingKey, appliedAt := range keys {
+		if now.Sub(appliedAt) >= repo.streamInIdempotencyWindow {
+			delete(keys, existingKey)
+		}
+	}
+
+	keys[key] = now
+
+	return volume.StoreStreamInKeys(keys)
+}
+
+// takeStreamSlot takes one of the slots for a stream to run, once there is
+// one to be had.
+func (repo *repository) takeStreamSlot(ctx context.Context, logger lager.Logger, slots streamSlots) error {
+	err := slots.acquire(ctx, repo.clock, repo.streamQueueTimeout)
+	if err == ErrTooManyStreams {
+		logger.Info("too-many-streams", lager.Data{"queue-timeout": repo.streamQueueTimeout.String()})
+	}
+
+	return err
+}
+
+// startStream counts a stream into or out of the volume until finishStream is
+// called, for destroys that spare streamed volumes. It is counted under the
+// volume's lock, so that such a destroy either sees it or has destroyed the
+// volume before the stream looks it up.
+func (repo *repository) startStream(handle string) {
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	repo.streamsL.Lock()
+	repo.streams[handle]++
+	repo.streamsL.Unlock()
+}
+
+func (repo *repository) finishStream(handle string) {
+	repo.streamsL.Lock()
+	defer repo.streamsL.Unlock()
+
+	repo.streams[handle]--
+	if repo.streams[handle] == 0 {
+		delete(repo.streams, handle)
+		delete(repo.digestTickets, handle)
+	}
+}
+
+func (repo *repository) isStreaming(handle string) bool {
+	repo.streamsL.Lock()
+	defer repo.streamsL.Unlock()
+
+	return repo.streams[handle] > 0
+}
+
+// recordDigest digests the whole volume once a stream into it has landed.
+// The volume is read outside of its lock, so as not to hold up destroys and
+// property updates for the whole of it; streams into other paths of the
+// volume may land meanwhile, so the digest is only recorded if no stream
+// that landed since has taken one of its own, which covers them all.
+func (repo *repository) recordDigest(handle string, volume FilesystemLiveVolume) error {
+	repo.streamsL.Lock()
+	repo.digestTickets[handle]++
+	ticket := repo.digestTickets[handle]
+	repo.streamsL.Unlock()
+
+	digest, err := volumeDigest(volume)
+	if err != nil {
+		return err
+	}
+
+	repo.locker.Lock(handle)
+	defer repo.locker.Unlock(handle)
+
+	repo.streamsL.Lock()
+	latest := repo.digestTickets[handle] == ticket
+	repo.streamsL.Unlock()
+
+	if !latest {
+		return nil
+	}
+
+	return volume.StoreDigest(digest)
+}
+
+// volumeDigest digests what the volume holds. The data of a view is a
+// symlink to that of the volume it views, which walking it would not
+// follow, so a view is digested as what it views.
+func volumeDigest(volume FilesystemLiveVolume) (string, error) {
+	root := volume.DataPath()
+
+	isView, err := volume.IsView()
+	if err != nil {
+		return "", err
+	}
+
+	if isView {
+		root, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return treeDigest(root)
+}
+
+func (repo *repository) VolumeDigest(handle string) (string, error) {
+	logger := repo.logger.Session("volume-digest", lager.Data{
+		"volume": handle,
+	})
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return "", err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return "", ErrVolumeDoesNotExist
+	}
+
+	digest, err := volumeDigest(volume)
+	if err != nil {
+		logger.Error("failed-to-digest-volume", err)
+		return "", err
+	}
+
+	return digest, nil
+}
+
+func (repo *repository) StreamOut(ctx context.Context, handle string, path string, dest io.Writer, opts StreamOutOptions) error {
+	logger := repo.logger.Session("stream-in", lager.Data{
+		"volume":   handle,
+		"sub-path": path,
+	})
+
+	err := repo.takeStreamSlot(ctx, logger, repo.streamsOut)
+	if err != nil {
+		return err
+	}
+
+	defer repo.streamsOut.release()
+
+	repo.startStream(handle)
+	defer repo.finishStream(handle)
+
+	volume, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return ErrVolumeDoesNotExist
+	}
+
+	isPrivileged, err := volume.LoadPrivileged()
+	if err != nil {
+		logger.Error("failed-to-check-if-volume-is-privileged", err)
+		return err
+	}
+
+	dataPath := volume.DataPath()
+
+	if opts.Consistent {
+		snapshotPath, release, err := repo.consistentView(logger, handle, volume)
+		if err != nil {
+			return err
+		}
+
+		defer release()
+
+		dataPath = snapshotPath
+	}
+
+	srcPath := filepath.Join(dataPath, path)
+
+	logger = logger.WithData(lager.Data{
+		"full-path": srcPath,
+	})
+
+	format, err := streamOutFormat(dataPath, srcPath, opts)
+	if err != nil {
+		logger.Info("cannot-stream-out-as-requested", lager.Data{"format": opts.Format, "error": err.Error()})
+		return err
+	}
+
+	if opts.StreamedAs != nil {
+		*opts.StreamedAs = format
+	}
+
+	dest = &contextWriter{Writer: dest, ctx: ctx}
+
+	if opts.BytesPerSecond > 0 {
+		dest = newThrottledWriter(ctx, repo.clock, dest, opts.BytesPerSecond)
+	}
+
+	sparse := opts.Sparse
+
+	var downgrading *downgradingWriter
+	if opts.Downgrade != nil {
+		downgrading = newDowngradingWriter(dest, opts.Downgrade)
+		dest = downgrading
+
+		// the rewritten stream has every file in full
+		sparse = false
+	}
+
+	if format == StreamOutFile {
+		err = streamOutFile(dest, srcPath)
+	} else if !opts.ModifiedSince.IsZero() {
+		err = repo.streamOutModifiedSince(ctx, dest, srcPath, isPrivileged, opts.Xattrs, sparse, opts.Canonical, opts.ModifiedSince)
+	} else {
+		err = repo.streamOut(ctx, dest, srcPath, isPrivileged, opts.Xattrs, sparse, opts.Canonical)
+	}
+
+	if downgrading != nil {
+		err = downgrading.Finish(err)
+		if err == nil {
+			logger.Info("downgraded", lager.Data{"report": opts.Downgrade.String()})
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			logger.Info("canceled")
+			return ctx.Err()
+		}
+
+		return err
+	}
+
+	_, err = volume.StoreLastAccessed()
+	if err != nil {
+		logger.Error("failed-to-store-last-accessed", err)
+	}
+
+	// the volume still counts as streamed while its TTL is renewed, so the
+	// reaper leaves it be until then
+	err = repo.renewTTLOnAccess(logger, handle)
+	if err != nil {
+		logger.Error("failed-to-renew-ttl", err)
+	}
+
+	return nil
+}
+
+// streamOutFormat decides how src, in the volume data at root, is streamed.
+// A regular file is only streamed as a file if it is within the volume once
+// symlinks are followed, as it is read outside of the volume's namespace.
+func streamOutFormat(root string, src string, opts StreamOutOptions) (StreamOutFormat, error) {
+	tarOnly := !opts.ModifiedSince.IsZero() || opts.Downgrade != nil || opts.Xattrs || opts.Sparse || opts.Canonical
+
+	switch opts.Format {
+	case StreamOutTar:
+		return StreamOutTar, nil
+
+	case StreamOutFile:
+		if tarOnly {
+			return "", ErrStreamOutOptionsNeedTar
+		}
+
+	default:
+		if tarOnly {
+			return StreamOutTar, nil
+		}
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.Mode().IsRegular() {
+		if opts.Format == StreamOutFile {
+			return "", ErrNotARegularFile
+		}
+
+		return StreamOutTar, nil
+	}
+
+	safe, err := resolvesWithin(root, src)
+	if err != nil {
+		return "", err
+	}
+
+	if !safe {
+		if opts.Format == StreamOutFile {
+			return "", ErrUnsafeSubPath
+		}
+
+		return StreamOutTar, nil
+	}
+
+	return StreamOutFile, nil
+}
+
+func streamOutFile(w io.Writer, src string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// consistentView returns a path from which the volume's data can be streamed
+// without seeing in-progress stream-ins, and a func to call once done. It
+// streams from a snapshot when the driver can take one; otherwise it flushes
+// the volume and keeps stream-ins out until released.
+func (repo *repository) consistentView(logger lager.Logger, handle string, volume FilesystemLiveVolume) (string, func(), error) {
+	repo.streamInLocker.Lock(handle, "")
+
+	snapshotPath, releaseSnapshot, err := volume.Snapshot()
+	if err == nil {
+		repo.streamInLocker.Unlock(handle, "")
+
+		return snapshotPath, func() {
+			err := releaseSnapshot()
+			if err != nil {
+				logger.Error("failed-to-release-snapshot", err)
+			}
+		}, nil
+	}
+
+	if err != ErrSnapshotsNotSupported {
+		repo.streamInLocker.Unlock(handle, "")
+		logger.Error("failed-to-snapshot", err)
+		return "", nil, err
+	}
+
+	err = syncTree(volume.DataPath())
+	if err != nil {
+		repo.streamInLocker.Unlock(handle, "")
+		logger.Error("failed-to-sync-data", err)
+		return "", nil, err
+	}
+
+	return volume.DataPath(), func() {
+		repo.streamInLocker.Unlock(handle, "")
+	}, nil
+}
+
+func (repo *repository) streamOutModifiedSince(ctx context.Context, w io.Writer, src string, privileged bool, xattrs bool, sparse bool, canonical bool, since time.Time) error {
+	fileInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !fileInfo.IsDir() {
+		return repo.streamOutPaths(ctx, w, filepath.Dir(src), privileged, xattrs, sparse, canonical, func(add func(string) error) error {
+			if !fileInfo.ModTime().After(since) {
+				return nil
+			}
+
+			return add(filepath.Base(src))
+		})
+	}
+
+	return repo.streamOutPaths(ctx, w, src, privileged, xattrs, sparse, canonical, func(add func(string) error) error {
+		return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// directories are always included to preserve structure
+			if !info.IsDir() && !info.ModTime().After(since) {
+				return nil
+			}
 
-	return volume, true, nil
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+
+			return add(filepath.ToSlash(rel))
+		})
+	})
 }
 
-func (repo *repository) GetVolumeStats(handle string) (VolumeStats, bool, error) {
-	logger := repo.logger.Session("get-volume-stats", lager.Data{
+func (repo *repository) DiffVolumes(handle string, baseHandle string, emit func(DiffEntry) error) error {
+	logger := repo.logger.Session("diff-volumes", lager.Data{
 		"volume": handle,
+		"base":   baseHandle,
 	})
 
-	liveVolume, found, err := repo.filesystem.LookupVolume(handle)
-	if err != nil {
-		logger.Error("failed-to-lookup-volume", err)
-		return VolumeStats{}, false, err
-	}
-
-	if !found {
-		logger.Info("volume-not-found")
-		return VolumeStats{}, false, nil
-	}
-
-	size, err := liveVolume.SizeInBytes()
+	volume, baseVolume, err := repo.lookupDiffVolumes(logger, handle, baseHandle)
 	if err != nil {
-		logger.Error("failed-to-get-volume-stats", err)
-		return VolumeStats{}, false, err
-	}
-
-	stats := VolumeStats{
-		SizeInBytes: size,
+		return err
 	}
 
-	return stats, true, nil
+	return diffTrees(volume.DataPath(), baseVolume.DataPath(), emit)
 }
 
-func (repo *repository) SetProperty(handle string, propertyName string, propertyValue string) error {
-	repo.locker.Lock(handle)
-	defer repo.locker.Unlock(handle)
-
-	logger := repo.logger.Session("set-property", lager.Data{
+func (repo *repository) Manifest(handle string, path string, emit func(ManifestEntry) error) error {
+	logger := repo.logger.Session("manifest", lager.Data{
 		"volume":   handle,
-		"property": propertyName,
+		"sub-path": path,
 	})
 
-	volume, found, err := repo.filesystem.LookupVolume(handle)
+	root, err := repo.listingRoot(logger, handle, path)
 	if err != nil {
-		logger.Error("failed-to-lookup-volume", err)
 		return err
 	}
 
-	if !found {
-		logger.Info("volume-not-found")
-		return ErrVolumeDoesNotExist
-	}
+	return walkManifest(root, emit)
+}
 
-	properties, err := volume.LoadProperties()
+func (repo *repository) ListFiles(handle string, path string) ([]FileEntry, error) {
+	logger := repo.logger.Session("list-files", lager.Data{
+		"volume":   handle,
+		"sub-path": path,
+	})
+
+	root, err := repo.listingRoot(logger, handle, path)
 	if err != nil {
-		logger.Error("failed-to-read-properties", err, lager.Data{
-			"volume": handle,
-		})
-		return err
+		return nil, err
 	}
 
-	properties = properties.UpdateProperty(propertyName, propertyValue)
+	files := []FileEntry{}
 
-	err = volume.StoreProperties(properties)
+	err = walkFiles(root, func(_ string, file FileEntry) error {
+		files = append(files, file)
+		return nil
+	})
 	if err != nil {
-		logger.Error("failed-to-store-properties", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return files, nil
 }
 
-func (repo *repository) SetTTL(handle string, ttl uint) error {
-	repo.locker.Lock(handle)
-	defer repo.locker.Unlock(handle)
-
-	logger := repo.logger.Session("set-ttl", lager.Data{
-		"volume": handle,
-	})
-
+// listingRoot is where the path is in the volume, as long as it does not
+// lead out of it.
+func (repo *repository) listingRoot(logger lager.Logger, handle string, path string) (string, error) {
 	volume, found, err := repo.filesystem.LookupVolume(handle)
 	if err != nil {
 		logger.Error("failed-to-lookup-volume", err)
-		return err
+		return "", err
 	}
 
 	if !found {
 		logger.Info("volume-not-found")
-		return ErrVolumeDoesNotExist
+		return "", ErrVolumeDoesNotExist
 	}
 
-	_, err = volume.StoreTTL(TTL(ttl))
+	root := filepath.Join(volume.DataPath(), path)
+
+	safe, err := resolvesWithin(volume.DataPath(), root)
 	if err != nil {
-		logger.Error("failed-to-store-ttl", err)
-		return err
+		logger.Error("failed-to-resolve-path", err)
+		return "", err
 	}
 
-	return nil
-}
+	if !within(filepath.Clean(volume.DataPath()), root) || !safe {
+		logger.Info("unsafe-sub-path")
+		return "", ErrUnsafeSubPath
+	}
 
-func (repo *repository) SetPrivileged(handle string, privileged bool) error {
-	repo.locker.Lock(handle)
-	defer repo.locker.Unlock(handle)
+	return root, nil
+}
 
-	logger := repo.logger.Session("set-privileged", lager.Data{
+func (repo *repository) StreamOutDiff(handle string, baseHandle string, dest io.Writer) error {
+	logger := repo.logger.Session("stream-out-diff", lager.Data{
 		"volume": handle,
+		"base":   baseHandle,
 	})
 
-	volume, found, err := repo.filesystem.LookupVolume(handle)
+	err := repo.takeStreamSlot(context.Background(), logger, repo.streamsOut)
 	if err != nil {
-		logger.Error("failed-to-lookup-volume", err)
 		return err
 	}
 
-	if !found {
-		logger.Info("volume-not-found")
-		return ErrVolumeDoesNotExist
-	}
+	defer repo.streamsOut.release()
 
-	err = repo.namespacer(privileged).NamespacePath(logger, volume.DataPath())
+	volume, baseVolume, err := repo.lookupDiffVolumes(logger, handle, baseHandle)
 	if err != nil {
-		logger.Error("failed-to-namespace-volume", err)
 		return err
 	}
 
-	err = volume.StorePrivileged(privileged)
+	isPrivileged, err := volume.LoadPrivileged()
 	if err != nil {
-		logger.Error("failed-to-store-privileged", err)
+		logger.Error("failed-to-check-if-volume-is-privileged", err)
 		return err
 	}
 
-	return nil
-}
+	return repo.streamOutPaths(context.Background(), dest, volume.DataPath(), isPrivileged, false, false, false, func(add func(string) error) error {
+		return diffTrees(volume.DataPath(), baseVolume.DataPath(), func(entry DiffEntry) error {
+			// removals can't be expressed in a plain tar
+			if entry.Change == DiffRemoved {
+				return nil
+			}
 
-func (repo *repository) StreamIn(handle string, path string, stream io.Reader) (bool, error) {
-	logger := repo.logger.Session("stream-in", lager.Data{
-		"volume":   handle,
-		"sub-path": path,
+			return add(entry.Path)
+		})
 	})
+}
 
+func (repo *repository) lookupDiffVolumes(logger lager.Logger, handle string, baseHandle string) (FilesystemLiveVolume, FilesystemLiveVolume, error) {
 	volume, found, err := repo.filesystem.LookupVolume(handle)
 	if err != nil {
 		logger.Error("failed-to-lookup-volume", err)
-		return false, err
+		return nil, nil, err
 	}
 
 	if !found {
 		logger.Info("volume-not-found")
-		return false, ErrVolumeDoesNotExist
-	}
-
-	destinationPath := filepath.Join(volume.DataPath(), path)
-
-	logger = logger.WithData(lager.Data{
-		"full-path": destinationPath,
-	})
-
-	err = os.MkdirAll(destinationPath, 0755)
-	if err != nil {
-		logger.Error("failed-to-create-destination-path", err)
-		return false, err
+		return nil, nil, ErrVolumeDoesNotExist
 	}
 
-	privileged, err := volume.LoadPrivileged()
+	baseVolume, found, err := repo.filesystem.LookupVolume(baseHandle)
 	if err != nil {
-		logger.Error("failed-to-check-if-volume-is-privileged", err)
-		return false, err
+		logger.Error("failed-to-lookup-base-volume", err)
+		return nil, nil, err
 	}
 
-	err = repo.namespacer(privileged).NamespacePath(logger, volume.DataPath())
-	if err != nil {
-		logger.Error("failed-to-namespace-path", err)
-		return false, err
+	if !found {
+		logger.Info("base-volume-not-found")
+		return nil, nil, ErrVolumeDoesNotExist
 	}
 
-	return repo.streamIn(stream, destinationPath, privileged)
+	return volume, baseVolume, nil
 }
 
-func (repo *repository) StreamOut(handle string, path string, dest io.Writer) error {
-	logger := repo.logger.Session("stream-in", lager.Data{
-		"volume":   handle,
-		"sub-path": path,
-	})
+// InodesExhausted reports whether the volumes filesystem has fewer free
+// inodes than the configured minimum. It is always false when no minimum is
+// configured, or when the filesystem has no limit to its inodes.
+func (repo *repository) InodesExhausted() (bool, error) {
+	if repo.minFreeInodes == 0 {
+		return false, nil
+	}
 
-	volume, found, err := repo.filesystem.LookupVolume(handle)
+	free, bounded, err := repo.filesystem.FreeInodes()
 	if err != nil {
-		logger.Error("failed-to-lookup-volume", err)
-		return err
+		return false, err
 	}
 
-	if !found {
-		logger.Info("volume-not-found")
-		return ErrVolumeDoesNotExist
-	}
+	return bounded && free < repo.minFreeInodes, nil
+}
 
-	srcPath := filepath.Join(volume.DataPath(), path)
+func (repo *repository) FreeBytes() (uint64, error) {
+	return repo.filesystem.FreeBytes()
+}
 
-	logger = logger.WithData(lager.Data{
-		"full-path": srcPath,
-	})
+func (repo *repository) WithLogData(data lager.Data) Repository {
+	tagged := *repo
+	tagged.logger = repo.logger.WithData(data)
+	return &tagged
+}
 
-	isPrivileged, err := volume.LoadPrivileged()
+func (repo *repository) guardFreeInodes(logger lager.Logger) error {
+	exhausted, err := repo.InodesExhausted()
 	if err != nil {
-		logger.Error("failed-to-check-if-volume-is-privileged", err)
+		logger.Error("failed-to-check-free-inodes", err)
 		return err
 	}
 
-	return repo.streamOut(dest, srcPath, isPrivileged)
+	if exhausted {
+		logger.Info("inodes-exhausted", lager.Data{"min-free-inodes": repo.minFreeInodes})
+		return ErrInsufficientInodes
+	}
+
+	return nil
 }
 
 func (repo *repository) VolumeParent(handle string) (Volume, bool, error) {
@@ -491,6 +3945,51 @@ func (repo *repository) VolumeParent(handle string) (Volume, bool, error) {
 	return volume, true, nil
 }
 
+func (repo *repository) VolumeChildren(handle string) ([]string, error) {
+	logger := repo.logger.Session("volume-children", lager.Data{
+		"volume": handle,
+	})
+
+	_, found, err := repo.filesystem.LookupVolume(handle)
+	if err != nil {
+		logger.Error("failed-to-lookup-volume", err)
+		return nil, err
+	}
+
+	if !found {
+		logger.Info("volume-not-found")
+		return nil, ErrVolumeDoesNotExist
+	}
+
+	children, err := repo.childIndex.Children(handle, repo.scanChildren)
+	if err != nil {
+		logger.Error("failed-to-list-children", err)
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// copyOnWriteParent returns the handle of the volume's parent if it is a
+// copy-on-write child, and "" otherwise.
+func copyOnWriteParent(liveVolume FilesystemLiveVolume) (string, error) {
+	isView, err := liveVolume.IsView()
+	if err != nil {
+		return "", err
+	}
+
+	if isView {
+		return "", nil
+	}
+
+	parentVolume, found, err := liveVolume.Parent()
+	if err != nil || !found {
+		return "", err
+	}
+
+	return parentVolume.Handle(), nil
+}
+
 func (repo *repository) volumeFrom(liveVolume FilesystemLiveVolume) (Volume, error) {
 	properties, err := liveVolume.LoadProperties()
 	if err != nil {
@@ -502,11 +4001,74 @@ func (repo *repository) volumeFrom(liveVolume FilesystemLiveVolume) (Volume, err
 		return Volume{}, err
 	}
 
+	renewTTLOnAccess, err := liveVolume.LoadRenewTTLOnAccess()
+	if err != nil {
+		return Volume{}, err
+	}
+
 	isPrivileged, err := liveVolume.LoadPrivileged()
 	if err != nil {
 		return Volume{}, err
 	}
 
+	committedAt, frozen, err := liveVolume.LoadCommitted()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	lastAccessedAt, err := liveVolume.LoadLastAccessed()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	createdAt, strategy, err := liveVolume.LoadCreated()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	modifiedAt, err := liveVolume.LoadModified()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	digest, err := liveVolume.LoadDigest()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	readOnly, err := liveVolume.LoadReadOnly()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	mountOptions, err := liveVolume.LoadMountOptions()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	encrypted, err := liveVolume.LoadEncrypted()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	driver, filesystemType, err := liveVolume.LoadBacking()
+	if err != nil {
+		return Volume{}, err
+	}
+
+	parentHandle, err := copyOnWriteParent(liveVolume)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	if createdAt.IsZero() {
+		createdAt = dataModTime(liveVolume)
+	}
+
+	if modifiedAt.IsZero() {
+		modifiedAt = createdAt
+	}
+
 	return Volume{
 		Handle:     liveVolume.Handle(),
 		Path:       liveVolume.DataPath(),
@@ -514,5 +4076,80 @@ func (repo *repository) volumeFrom(liveVolume FilesystemLiveVolume) (Volume, err
 		TTL:        ttl,
 		ExpiresAt:  expiresAt,
 		Privileged: isPrivileged,
+
+		ParentHandle: parentHandle,
+
+		PendingDestroy: !ttl.IsUnlimited() && repo.clock.Now().After(expiresAt),
+
+		RenewTTLOnAccess: renewTTLOnAccess,
+
+		Committed:   !committedAt.IsZero(),
+		CommittedAt: committedAt,
+		Frozen:      frozen,
+		ReadOnly:    readOnly,
+
+		MountOptions: mountOptions,
+		Encrypted:    encrypted,
+
+		LastAccessedAt: lastAccessedAt,
+
+		CreatedAt:  createdAt,
+		ModifiedAt: modifiedAt,
+		Strategy:   strategy,
+
+		Digest: digest,
+
+		Driver:         driver,
+		FilesystemType: filesystemType,
 	}, nil
 }
+
+// dataModTime is the best guess at when a volume created before its
+// timestamps were recorded was created or modified. It is zero if the
+// volume's data can't be looked at.
+func dataModTime(liveVolume FilesystemLiveVolume) time.Time {
+	info, err := os.Stat(liveVolume.DataPath())
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime().Truncate(time.Second)
+}
+
+// topmostMissingDir returns the highest directory between root and path that
+// does not exist yet, or path itself if it already exists.
+func topmostMissingDir(root string, path string) string {
+	missing := path
+
+	for dir := path; dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+
+		missing = dir
+	}
+
+	return missing
+}
+
+// syncTree flushes every file and directory below root to stable storage.
+func syncTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		return file.Sync()
+	})
+}
@@ -1,12 +1,31 @@
 package volume_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/baggageclaim"
 	"github.com/concourse/baggageclaim/uidgid/uidgidfakes"
 	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
 	"github.com/concourse/baggageclaim/volume/volumefakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,27 +34,60 @@ import (
 var _ = Describe("Repository", func() {
 	var (
 		logger                     *lagertest.TestLogger
+		fakeClock                  *fakeclock.FakeClock
 		fakeFilesystem             *volumefakes.FakeFilesystem
 		fakeLocker                 *volumefakes.FakeLockManager
+		fakeStreamInLocker         *volumefakes.FakePathLockManager
 		fakePrivilegedNamespacer   *uidgidfakes.FakeNamespacer
 		fakeUnprivilegedNamespacer *uidgidfakes.FakeNamespacer
+		labelSchemas               volume.LabelSchemas
+		fakeDestroyAuditLog        *volumefakes.FakeDestroyAuditLog
+		minFreeInodes              uint64
+		streamInConcurrency        int
+		indexedProperties          []string
+		propertyLimits             volume.PropertyLimits
+		deletedRetention           time.Duration
 
 		repository volume.Repository
 	)
 
 	BeforeEach(func() {
 		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Unix(100, 0))
 		fakeFilesystem = new(volumefakes.FakeFilesystem)
 		fakeLocker = new(volumefakes.FakeLockManager)
+		fakeStreamInLocker = new(volumefakes.FakePathLockManager)
 		fakePrivilegedNamespacer = new(uidgidfakes.FakeNamespacer)
 		fakeUnprivilegedNamespacer = new(uidgidfakes.FakeNamespacer)
+		labelSchemas = nil
+		fakeDestroyAuditLog = new(volumefakes.FakeDestroyAuditLog)
+		minFreeInodes = 0
+		streamInConcurrency = 1
+		indexedProperties = nil
+		propertyLimits = volume.PropertyLimits{}
+		deletedRetention = 0
+	})
 
+	JustBeforeEach(func() {
 		repository = volume.NewRepository(
 			logger,
+			fakeClock,
 			fakeFilesystem,
 			fakeLocker,
+			fakeStreamInLocker,
 			fakePrivilegedNamespacer,
 			fakeUnprivilegedNamespacer,
+			labelSchemas,
+			time.Minute,
+			fakeDestroyAuditLog,
+			minFreeInodes,
+			streamInConcurrency,
+			indexedProperties,
+			volume.NoopEventSink{},
+			propertyLimits,
+			deletedRetention,
+			volume.StreamLimits{},
+			0,
 		)
 	})
 
@@ -45,6 +97,11 @@ var _ = Describe("Repository", func() {
 			properties   volume.Properties
 			ttlInSeconds uint
 			privileged   bool
+			sizeInBytes  int64
+			readOnly     bool
+
+			renewTTLOnAccess bool
+			encrypted        bool
 
 			createdVolume volume.Volume
 			createErr     error
@@ -55,6 +112,10 @@ var _ = Describe("Repository", func() {
 			properties = volume.Properties{"some": "properties"}
 			ttlInSeconds = 42
 			privileged = false
+			sizeInBytes = 0
+			readOnly = false
+			renewTTLOnAccess = false
+			encrypted = false
 		})
 
 		JustBeforeEach(func() {
@@ -64,9 +125,57 @@ var _ = Describe("Repository", func() {
 				properties,
 				ttlInSeconds,
 				privileged,
+				volume.CreateOptions{
+					SizeInBytes:      sizeInBytes,
+					ReadOnly:         readOnly,
+					RenewTTLOnAccess: renewTTLOnAccess,
+					Encrypted:        encrypted,
+				},
 			)
 		})
 
+		Context("when a property is not allowed by its label schema", func() {
+			BeforeEach(func() {
+				labelSchemas = volume.LabelSchemas{
+					"some": volume.LabelSchema{Values: []string{"other-properties"}},
+				}
+			})
+
+			It("returns ErrInvalidPropertyValue", func() {
+				Expect(createErr).To(Equal(volume.ErrInvalidPropertyValue))
+			})
+
+			It("does not materialize the volume", func() {
+				Expect(fakeStrategy.MaterializeCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when a property's name has a slash in it", func() {
+			BeforeEach(func() {
+				properties = volume.Properties{"some/property": "some-value"}
+			})
+
+			It("returns ErrInvalidPropertyName without materializing the volume", func() {
+				Expect(createErr).To(Equal(volume.ErrInvalidPropertyName))
+				Expect(fakeStrategy.MaterializeCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when fewer inodes than the minimum are free", func() {
+			BeforeEach(func() {
+				minFreeInodes = 1000
+				fakeFilesystem.FreeInodesReturns(999, true, nil)
+			})
+
+			It("returns ErrInsufficientInodes", func() {
+				Expect(createErr).To(Equal(volume.ErrInsufficientInodes))
+			})
+
+			It("does not materialize the volume", func() {
+				Expect(fakeStrategy.MaterializeCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when a new volume can be materialized with the strategy", func() {
 			var fakeInitVolume *volumefakes.FakeFilesystemInitVolume
 
@@ -76,14 +185,20 @@ var _ = Describe("Repository", func() {
 			})
 
 			Context("when setting the properties, ttl and privileged succeeds", func() {
-				var expiresAt time.Time
+				var (
+					expiresAt time.Time
+					createdAt time.Time
+				)
 
 				BeforeEach(func() {
 					expiresAt = time.Now()
+					createdAt = time.Unix(42, 0)
 					fakeInitVolume.StorePropertiesReturns(nil)
 					fakeInitVolume.StoreTTLReturns(expiresAt, nil)
 					fakeInitVolume.DataPathReturns("init-data-path")
 					fakeInitVolume.StorePrivilegedReturns(nil)
+					fakeInitVolume.StoreCreatedReturns(createdAt, nil)
+					fakeStrategy.TypeReturns("some-strategy")
 				})
 
 				Context("when the volume can be initialized", func() {
@@ -107,9 +222,17 @@ var _ = Describe("Repository", func() {
 							Properties: properties,
 							TTL:        volume.TTL(ttlInSeconds),
 							ExpiresAt:  expiresAt,
+							CreatedAt:  createdAt,
+							ModifiedAt: createdAt,
+							Strategy:   "some-strategy",
 						}))
 					})
 
+					It("records the strategy the volume was created with", func() {
+						Expect(fakeInitVolume.StoreCreatedCallCount()).To(Equal(1))
+						Expect(fakeInitVolume.StoreCreatedArgsForCall(0)).To(Equal("some-strategy"))
+					})
+
 					It("materialized with the correct volume, fs, and driver", func() {
 						_, handle, fs := fakeStrategy.MaterializeArgsForCall(0)
 						Expect(handle).ToNot(BeEmpty())
@@ -120,6 +243,158 @@ var _ = Describe("Repository", func() {
 						Expect(fakeInitVolume.DestroyCallCount()).To(Equal(0))
 					})
 
+					It("leaves the volume unlimited", func() {
+						Expect(fakeInitVolume.SetQuotaCallCount()).To(BeZero())
+					})
+
+					It("leaves the volume writable", func() {
+						Expect(fakeInitVolume.MakeReadOnlyCallCount()).To(BeZero())
+						Expect(fakeInitVolume.StoreCommittedCallCount()).To(BeZero())
+					})
+
+					It("leaves the volume's TTL to be renewed by setting it", func() {
+						Expect(fakeInitVolume.StoreRenewTTLOnAccessCallCount()).To(BeZero())
+					})
+
+					Context("when the volume is to be encrypted", func() {
+						BeforeEach(func() {
+							encrypted = true
+						})
+
+						Context("when the strategy creates it encrypted", func() {
+							BeforeEach(func() {
+								fakeInitVolume.LoadEncryptedReturns(true, nil)
+							})
+
+							It("has the strategy create it with an encrypting filesystem", func() {
+								_, _, fs := fakeStrategy.MaterializeArgsForCall(0)
+								Expect(fs).NotTo(Equal(fakeFilesystem))
+
+								_, err := fs.NewVolume("some-handle")
+								Expect(err).NotTo(HaveOccurred())
+								Expect(fakeFilesystem.NewVolumeCallCount()).To(BeZero())
+								Expect(fakeFilesystem.NewEncryptedVolumeCallCount()).To(Equal(1))
+								Expect(fakeFilesystem.NewEncryptedVolumeArgsForCall(0)).To(Equal("some-handle"))
+							})
+
+							It("returns it as encrypted", func() {
+								Expect(createErr).NotTo(HaveOccurred())
+								Expect(createdVolume.Encrypted).To(BeTrue())
+							})
+						})
+
+						Context("when the strategy creates it unencrypted", func() {
+							BeforeEach(func() {
+								fakeInitVolume.LoadEncryptedReturns(false, nil)
+							})
+
+							It("returns ErrStrategyNotEncryptable", func() {
+								Expect(createErr).To(Equal(volume.ErrStrategyNotEncryptable))
+							})
+
+							It("destroys the initializing volume", func() {
+								Expect(fakeInitVolume.DestroyCallCount()).To(Equal(1))
+							})
+						})
+					})
+
+					Context("when the volume is to renew its TTL on access", func() {
+						BeforeEach(func() {
+							renewTTLOnAccess = true
+						})
+
+						It("records it", func() {
+							Expect(fakeInitVolume.StoreRenewTTLOnAccessCallCount()).To(Equal(1))
+						})
+
+						It("returns it as renewing its TTL on access", func() {
+							Expect(createdVolume.RenewTTLOnAccess).To(BeTrue())
+						})
+
+						Context("when recording it fails", func() {
+							disaster := errors.New("nope")
+
+							BeforeEach(func() {
+								fakeInitVolume.StoreRenewTTLOnAccessReturns(disaster)
+							})
+
+							It("returns the error", func() {
+								Expect(createErr).To(Equal(disaster))
+							})
+
+							It("destroys the initializing volume", func() {
+								Expect(fakeInitVolume.DestroyCallCount()).To(Equal(1))
+							})
+						})
+					})
+
+					Context("when the volume is to be read-only", func() {
+						var committedAt time.Time
+
+						BeforeEach(func() {
+							readOnly = true
+
+							committedAt = time.Unix(123, 0)
+							fakeInitVolume.StoreCommittedReturns(committedAt, nil)
+						})
+
+						It("makes it read-only", func() {
+							Expect(fakeInitVolume.MakeReadOnlyCallCount()).To(Equal(1))
+							Expect(fakeUnprivilegedNamespacer.NamespacePathCallCount()).To(Equal(1))
+						})
+
+						It("freezes it", func() {
+							Expect(fakeInitVolume.StoreCommittedCallCount()).To(Equal(1))
+							Expect(fakeInitVolume.StoreCommittedArgsForCall(0)).To(BeTrue())
+						})
+
+						It("returns it as read-only and frozen", func() {
+							Expect(createdVolume.ReadOnly).To(BeTrue())
+							Expect(createdVolume.Frozen).To(BeTrue())
+							Expect(createdVolume.Committed).To(BeTrue())
+							Expect(createdVolume.CommittedAt).To(Equal(committedAt))
+						})
+
+						Context("when the driver cannot make it read-only", func() {
+							BeforeEach(func() {
+								fakeInitVolume.MakeReadOnlyReturns(volume.ErrReadOnlyNotSupported)
+							})
+
+							It("returns the error", func() {
+								Expect(createErr).To(Equal(volume.ErrReadOnlyNotSupported))
+							})
+
+							It("destroys the initializing volume", func() {
+								Expect(fakeInitVolume.DestroyCallCount()).To(Equal(1))
+							})
+						})
+					})
+
+					Context("when a size is given", func() {
+						BeforeEach(func() {
+							sizeInBytes = 1024 * 1024
+						})
+
+						It("limits the volume to it", func() {
+							Expect(fakeInitVolume.SetQuotaCallCount()).To(Equal(1))
+							Expect(fakeInitVolume.SetQuotaArgsForCall(0)).To(Equal(int64(1024 * 1024)))
+						})
+
+						Context("when the volume cannot be limited", func() {
+							BeforeEach(func() {
+								fakeInitVolume.SetQuotaReturns(volume.ErrQuotasNotSupported)
+							})
+
+							It("returns the error", func() {
+								Expect(createErr).To(Equal(volume.ErrQuotasNotSupported))
+							})
+
+							It("destroys the initializing volume", func() {
+								Expect(fakeInitVolume.DestroyCallCount()).To(Equal(1))
+							})
+						})
+					})
+
 					Context("when the volume is privileged", func() {
 						BeforeEach(func() {
 							privileged = true
@@ -204,6 +479,17 @@ var _ = Describe("Repository", func() {
 						Expect(createErr).To(Equal(disaster))
 					})
 				})
+
+				Context("when a volume with the handle went live in the meantime", func() {
+					BeforeEach(func() {
+						fakeInitVolume.InitializeReturns(nil, &os.LinkError{Op: "rename", Err: syscall.ENOTEMPTY})
+					})
+
+					It("cleans up the volume and returns ErrVolumeAlreadyExists", func() {
+						Expect(createErr).To(Equal(volume.ErrVolumeAlreadyExists))
+						Expect(fakeInitVolume.DestroyCallCount()).To(Equal(1))
+					})
+				})
 			})
 
 			Context("when storing the properties fails", func() {
@@ -267,6 +553,28 @@ var _ = Describe("Repository", func() {
 			})
 		})
 
+		Context("when a volume with the handle already exists", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(new(volumefakes.FakeFilesystemLiveVolume), true, nil)
+			})
+
+			It("returns ErrVolumeAlreadyExists without materializing the volume", func() {
+				Expect(createErr).To(Equal(volume.ErrVolumeAlreadyExists))
+				Expect(fakeStrategy.MaterializeCallCount()).To(BeZero())
+				Expect(fakeFilesystem.LookupVolumeArgsForCall(0)).To(Equal("some-handle"))
+			})
+		})
+
+		Context("when a volume with the handle is being created", func() {
+			BeforeEach(func() {
+				fakeStrategy.MaterializeReturns(nil, &os.PathError{Op: "mkdir", Err: syscall.EEXIST})
+			})
+
+			It("returns ErrVolumeAlreadyExists", func() {
+				Expect(createErr).To(Equal(volume.ErrVolumeAlreadyExists))
+			})
+		})
+
 		Context("when creating the volume fails", func() {
 			disaster := errors.New("nope")
 
@@ -280,139 +588,529 @@ var _ = Describe("Repository", func() {
 		})
 	})
 
-	Describe("DestroyVolume", func() {
-		var destroyErr error
+	Describe("CloneVolume", func() {
+		var (
+			fakeSource    *volumefakes.FakeFilesystemLiveVolume
+			fakeClone     *volumefakes.FakeFilesystemInitVolume
+			fakeLiveClone *volumefakes.FakeFilesystemLiveVolume
+			existing      bool
+
+			clonedVolume volume.Volume
+			cloneErr     error
+		)
+
+		BeforeEach(func() {
+			fakeLiveClone = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveClone.HandleReturns("clone-handle")
+			fakeLiveClone.DataPathReturns("/clone/data")
+
+			fakeClone = new(volumefakes.FakeFilesystemInitVolume)
+			fakeClone.StoreTTLReturns(fakeClock.Now().Add(42*time.Second), nil)
+			fakeClone.StoreCreatedReturns(fakeClock.Now(), nil)
+			fakeClone.InitializeReturns(fakeLiveClone, nil)
+
+			fakeSource = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeSource.LoadPropertiesReturns(volume.Properties{"some": "property"}, nil)
+			fakeSource.LoadTTLReturns(42, fakeClock.Now().Add(time.Second), nil)
+			fakeSource.LoadPrivilegedReturns(true, nil)
+			fakeSource.NewCloneReturns(fakeClone, nil)
+
+			existing = false
+
+			fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
+				switch handle {
+				case "source-handle":
+					return fakeSource, true, nil
+				case "clone-handle":
+					if existing {
+						return fakeLiveClone, true, nil
+					}
+				}
+
+				return nil, false, nil
+			}
+		})
 
 		JustBeforeEach(func() {
-			destroyErr = repository.DestroyVolume("some-volume")
+			clonedVolume, cloneErr = repository.CloneVolume("source-handle", "clone-handle")
 		})
 
-		Context("when the volume can be found", func() {
-			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+		It("clones the source into a volume with its properties, TTL, and privileges", func() {
+			Expect(cloneErr).NotTo(HaveOccurred())
+
+			Expect(fakeSource.NewCloneCallCount()).To(Equal(1))
+			Expect(fakeSource.NewCloneArgsForCall(0)).To(Equal("clone-handle"))
+
+			Expect(fakeClone.StorePropertiesArgsForCall(0)).To(Equal(volume.Properties{"some": "property"}))
+			Expect(fakeClone.StoreTTLArgsForCall(0)).To(Equal(volume.TTL(42)))
+			Expect(fakeClone.StorePrivilegedArgsForCall(0)).To(BeTrue())
+			Expect(fakeClone.StoreRenewTTLOnAccessCallCount()).To(BeZero())
+			Expect(fakeClone.StoreCreatedArgsForCall(0)).To(Equal(volume.StrategyClone))
+			Expect(fakeClone.InitializeCallCount()).To(Equal(1))
+			Expect(fakeClone.DestroyCallCount()).To(BeZero())
+
+			Expect(clonedVolume).To(Equal(volume.Volume{
+				Handle:     "clone-handle",
+				Path:       "/clone/data",
+				Properties: volume.Properties{"some": "property"},
+				TTL:        42,
+				ExpiresAt:  fakeClock.Now().Add(42 * time.Second),
+				CreatedAt:  fakeClock.Now(),
+				ModifiedAt: fakeClock.Now(),
+				Strategy:   volume.StrategyClone,
+			}))
+		})
 
+		Context("when the source renews its TTL on access", func() {
 			BeforeEach(func() {
-				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+				fakeSource.LoadRenewTTLOnAccessReturns(true, nil)
 			})
 
-			Context("when destroying the volume succeeds", func() {
-				BeforeEach(func() {
-					fakeVolume.DestroyReturns(nil)
-				})
+			It("has the clone renew its TTL on access too", func() {
+				Expect(fakeClone.StoreRenewTTLOnAccessCallCount()).To(Equal(1))
+				Expect(clonedVolume.RenewTTLOnAccess).To(BeTrue())
+			})
+		})
 
-				It("returns nil", func() {
-					Expect(destroyErr).To(BeNil())
-				})
+		It("does not namespace the copied data again", func() {
+			Expect(fakePrivilegedNamespacer.NamespacePathCallCount()).To(BeZero())
+			Expect(fakeUnprivilegedNamespacer.NamespacePathCallCount()).To(BeZero())
+		})
 
-				It("looked up using the correct handle", func() {
-					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
-					Expect(handle).To(Equal("some-volume"))
-				})
-			})
+		It("keeps stream-ins and destroys out of the source while cloning", func() {
+			Expect(fakeStreamInLocker.LockCallCount()).To(Equal(1))
+			handle, path := fakeStreamInLocker.LockArgsForCall(0)
+			Expect(handle).To(Equal("source-handle"))
+			Expect(path).To(BeEmpty())
+			Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
 
-			Context("when destroying the volume fails", func() {
-				disaster := errors.New("nope")
+			Expect(fakeLocker.LockCallCount()).To(Equal(1))
+			Expect(fakeLocker.LockArgsForCall(0)).To(Equal("source-handle"))
+			Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+		})
 
-				BeforeEach(func() {
-					fakeVolume.DestroyReturns(disaster)
-				})
+		Context("when the source does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeStub = nil
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
 
-				It("returns the error", func() {
-					Expect(destroyErr).To(Equal(disaster))
-				})
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(cloneErr).To(Equal(volume.ErrVolumeDoesNotExist))
 			})
 		})
 
-		Context("when looking up the volume fails", func() {
-			disaster := errors.New("nope")
+		Context("when a volume with the handle already exists", func() {
+			BeforeEach(func() {
+				existing = true
+			})
+
+			It("returns ErrVolumeAlreadyExists without cloning", func() {
+				Expect(cloneErr).To(Equal(volume.ErrVolumeAlreadyExists))
+				Expect(fakeSource.NewCloneCallCount()).To(BeZero())
+			})
+		})
 
+		Context("when a volume with the handle is being created", func() {
 			BeforeEach(func() {
-				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
+				fakeSource.NewCloneReturns(nil, os.ErrExist)
 			})
 
-			It("returns the error", func() {
-				Expect(destroyErr).To(Equal(disaster))
+			It("returns ErrVolumeAlreadyExists", func() {
+				Expect(cloneErr).To(Equal(volume.ErrVolumeAlreadyExists))
 			})
 		})
 
-		Context("when the volume can not be found", func() {
+		Context("when initializing the clone fails", func() {
+			disaster := errors.New("nope")
+
 			BeforeEach(func() {
-				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+				fakeClone.InitializeReturns(nil, disaster)
 			})
 
-			It("returns ErrVolumeDoesNotExist", func() {
-				Expect(destroyErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			It("destroys the clone and returns the error", func() {
+				Expect(cloneErr).To(Equal(disaster))
+				Expect(fakeClone.DestroyCallCount()).To(Equal(1))
 			})
 		})
 	})
 
-	Describe("DestroyVolumeAndDescendants", func() {
-		var destroyErr error
+	Describe("InodesExhausted", func() {
+		var (
+			exhausted bool
+			checkErr  error
+		)
 
 		JustBeforeEach(func() {
-			destroyErr = repository.DestroyVolumeAndDescendants("parent")
+			exhausted, checkErr = repository.InodesExhausted()
 		})
 
-		Context("when the volume and its children can be found", func() {
-			var (
-				fakeParent     *volumefakes.FakeFilesystemLiveVolume
-				fakeChild      *volumefakes.FakeFilesystemLiveVolume
-				fakeSibling    *volumefakes.FakeFilesystemLiveVolume
-				fakeGrandchild *volumefakes.FakeFilesystemLiveVolume
-				fakeRoommate   *volumefakes.FakeFilesystemLiveVolume
-			)
+		Context("when no minimum is configured", func() {
+			It("is never exhausted, without checking the filesystem", func() {
+				Expect(checkErr).NotTo(HaveOccurred())
+				Expect(exhausted).To(BeFalse())
+				Expect(fakeFilesystem.FreeInodesCallCount()).To(BeZero())
+			})
+		})
 
+		Context("when a minimum is configured", func() {
 			BeforeEach(func() {
-				fakeParent = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeChild = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeSibling = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeGrandchild = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeRoommate = new(volumefakes.FakeFilesystemLiveVolume)
+				minFreeInodes = 1000
+			})
 
-				fakeParent.HandleReturns("parent")
-				fakeChild.HandleReturns("child")
-				fakeSibling.HandleReturns("sibling")
-				fakeGrandchild.HandleReturns("grandchild")
-				fakeRoommate.HandleReturns("unrelated")
+			It("is exhausted below the minimum", func() {
+				fakeFilesystem.FreeInodesReturns(999, true, nil)
+				exhausted, checkErr = repository.InodesExhausted()
+				Expect(checkErr).NotTo(HaveOccurred())
+				Expect(exhausted).To(BeTrue())
+			})
 
-				fakeChild.ParentReturns(fakeParent, true, nil)
-				fakeSibling.ParentReturns(fakeParent, true, nil)
-				fakeGrandchild.ParentReturns(fakeChild, true, nil)
+			It("is not exhausted at the minimum", func() {
+				fakeFilesystem.FreeInodesReturns(1000, true, nil)
+				exhausted, checkErr = repository.InodesExhausted()
+				Expect(checkErr).NotTo(HaveOccurred())
+				Expect(exhausted).To(BeFalse())
+			})
 
-				fakeFilesystem.ListVolumesReturns([]volume.FilesystemLiveVolume{
-					fakeParent,
-					fakeChild,
-					fakeSibling,
-					fakeGrandchild,
-					fakeRoommate,
-				}, nil)
-				fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
-					if handle == "child" {
-						return fakeChild, true, nil
-					}
-					if handle == "parent" {
-						return fakeParent, true, nil
-					}
-					if handle == "grandchild" {
-						return fakeGrandchild, true, nil
-					}
-					if handle == "sibling" {
-						return fakeSibling, true, nil
-					}
-					if handle == "unrelated" {
-						return fakeRoommate, true, nil
-					}
-					return nil, false, nil
-				}
+			It("is never exhausted when the filesystem has no limit to its inodes, as on btrfs", func() {
+				fakeFilesystem.FreeInodesReturns(0, false, nil)
+				exhausted, checkErr = repository.InodesExhausted()
+				Expect(checkErr).NotTo(HaveOccurred())
+				Expect(exhausted).To(BeFalse())
 			})
 
-			It("wipes out the whole family", func() {
-				Expect(fakeParent.DestroyCallCount()).To(Equal(1))
-				Expect(fakeChild.DestroyCallCount()).To(Equal(1))
-				Expect(fakeSibling.DestroyCallCount()).To(Equal(1))
+			Context("when checking the filesystem fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeFilesystem.FreeInodesReturns(0, false, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(checkErr).To(Equal(disaster))
+				})
+			})
+		})
+	})
+
+	Describe("DestroyVolume", func() {
+		var (
+			destroyOpts volume.DestroyOptions
+			destroyErr  error
+		)
+
+		BeforeEach(func() {
+			destroyOpts = volume.DestroyOptions{
+				Reason:     volume.DestroyReasonManual,
+				Annotation: "some-annotation",
+			}
+		})
+
+		JustBeforeEach(func() {
+			destroyErr = repository.DestroyVolume("some-volume", destroyOpts)
+		})
+
+		Context("when the volume can be found", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"some": "property"}, nil)
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			Context("when destroying the volume succeeds", func() {
+				BeforeEach(func() {
+					fakeVolume.DestroyReturns(nil)
+				})
+
+				It("returns nil", func() {
+					Expect(destroyErr).To(BeNil())
+				})
+
+				It("records the reason in the audit log", func() {
+					Expect(fakeDestroyAuditLog.RecordCallCount()).To(Equal(1))
+					Expect(fakeDestroyAuditLog.RecordArgsForCall(0)).To(Equal(volume.DestroyAuditEntry{
+						Handle:      "some-volume",
+						Reason:      volume.DestroyReasonManual,
+						Annotation:  "some-annotation",
+						Properties:  volume.Properties{"some": "property"},
+						DestroyedAt: fakeClock.Now(),
+					}))
+				})
+
+				Context("when recording the audit entry fails", func() {
+					BeforeEach(func() {
+						fakeDestroyAuditLog.RecordReturns(errors.New("nope"))
+					})
+
+					It("still returns nil", func() {
+						Expect(destroyErr).To(BeNil())
+					})
+				})
+
+				It("looked up using the correct handle", func() {
+					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
+					Expect(handle).To(Equal("some-volume"))
+				})
+			})
+
+			Context("when asked to destroy the volume only if it still expires when it did", func() {
+				BeforeEach(func() {
+					destroyOpts.Reason = volume.DestroyReasonTTLExpiry
+					destroyOpts.ExpiredAt = time.Unix(90, 0)
+				})
+
+				Context("when it does", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadTTLReturns(60, time.Unix(90, 0), nil)
+					})
+
+					It("destroys it", func() {
+						Expect(destroyErr).NotTo(HaveOccurred())
+						Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+					})
+				})
+
+				Context("when its TTL has been renewed since", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadTTLReturns(60, time.Unix(160, 0), nil)
+					})
+
+					It("leaves it alone", func() {
+						Expect(destroyErr).To(Equal(volume.ErrVolumeWasRenewed))
+						Expect(fakeVolume.DestroyCallCount()).To(BeZero())
+					})
+				})
+			})
+
+			Context("when the volume still has views", func() {
+				BeforeEach(func() {
+					fakeVolume.HandleReturns("some-volume")
+
+					fakeView := new(volumefakes.FakeFilesystemLiveVolume)
+					fakeView.HandleReturns("some-view")
+					fakeView.IsViewReturns(true, nil)
+					fakeView.ParentReturns(fakeVolume, true, nil)
+
+					fakeFilesystem.ListVolumesReturns([]volume.FilesystemLiveVolume{fakeVolume, fakeView}, nil)
+				})
+
+				It("returns nil", func() {
+					Expect(destroyErr).To(BeNil())
+				})
+
+				It("releases the volume instead of destroying it", func() {
+					Expect(fakeVolume.DestroyCallCount()).To(BeZero())
+					Expect(fakeVolume.StoreReleasedCallCount()).To(Equal(1))
+					Expect(fakeVolume.StoreReleasedArgsForCall(0)).To(Equal(volume.DestroyOptions{
+						Reason:     volume.DestroyReasonManual,
+						Annotation: "some-annotation",
+					}))
+				})
+
+				It("freezes the volume, as its views are", func() {
+					Expect(fakeVolume.StoreCommittedCallCount()).To(Equal(1))
+					Expect(fakeVolume.StoreCommittedArgsForCall(0)).To(BeTrue())
+				})
+
+				It("does not record an audit entry", func() {
+					Expect(fakeDestroyAuditLog.RecordCallCount()).To(BeZero())
+				})
+
+				It("keeps its views indexed rather than listing every volume again", func() {
+					listed := fakeFilesystem.ListVolumesCallCount()
+
+					Expect(repository.DestroyVolume("some-volume", destroyOpts)).To(Succeed())
+					Expect(fakeVolume.StoreReleasedCallCount()).To(Equal(2))
+
+					Expect(fakeFilesystem.ListVolumesCallCount()).To(Equal(listed))
+				})
+			})
+
+			Context("when the volume is the last view of a released base", func() {
+				var fakeBase *volumefakes.FakeFilesystemLiveVolume
+
+				BeforeEach(func() {
+					fakeBase = new(volumefakes.FakeFilesystemLiveVolume)
+					fakeBase.HandleReturns("some-base")
+					fakeBase.LoadReleasedReturns(volume.DestroyOptions{
+						Reason: volume.DestroyReasonTTLExpiry,
+					}, true, nil)
+
+					fakeVolume.IsViewReturns(true, nil)
+					fakeVolume.ParentReturns(fakeBase, true, nil)
+
+					fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
+						if handle == "some-base" {
+							return fakeBase, true, nil
+						}
+
+						return fakeVolume, true, nil
+					}
+				})
+
+				It("destroys both the view and the base", func() {
+					Expect(destroyErr).To(BeNil())
+					Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+					Expect(fakeBase.DestroyCallCount()).To(Equal(1))
+				})
+
+				It("records the base with the reason it was released for", func() {
+					Expect(fakeDestroyAuditLog.RecordCallCount()).To(Equal(2))
+					entry := fakeDestroyAuditLog.RecordArgsForCall(1)
+					Expect(entry.Handle).To(Equal("some-base"))
+					Expect(entry.Reason).To(Equal(volume.DestroyReasonTTLExpiry))
+				})
+			})
+
+			Context("when the volume is a view of a base that has not been released", func() {
+				var fakeBase *volumefakes.FakeFilesystemLiveVolume
+
+				BeforeEach(func() {
+					fakeBase = new(volumefakes.FakeFilesystemLiveVolume)
+					fakeVolume.IsViewReturns(true, nil)
+					fakeVolume.ParentReturns(fakeBase, true, nil)
+				})
+
+				It("leaves the base alone", func() {
+					Expect(destroyErr).To(BeNil())
+					Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+					Expect(fakeBase.DestroyCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when destroying the volume fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.DestroyReturns(disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(destroyErr).To(Equal(disaster))
+				})
+
+				It("does not record an audit entry", func() {
+					Expect(fakeDestroyAuditLog.RecordCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("when looking up the volume fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
+			})
+
+			It("returns the error", func() {
+				Expect(destroyErr).To(Equal(disaster))
+			})
+		})
+
+		Context("when the volume can not be found", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(destroyErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("DestroyVolumeAndDescendants", func() {
+		var (
+			destroyed  []string
+			destroyErr error
+		)
+
+		JustBeforeEach(func() {
+			destroyed, destroyErr = repository.DestroyVolumeAndDescendants("parent", volume.DestroyOptions{
+				Reason: volume.DestroyReasonCorrupted,
+			})
+		})
+
+		Context("when the volume and its children can be found", func() {
+			var (
+				fakeParent     *volumefakes.FakeFilesystemLiveVolume
+				fakeChild      *volumefakes.FakeFilesystemLiveVolume
+				fakeSibling    *volumefakes.FakeFilesystemLiveVolume
+				fakeGrandchild *volumefakes.FakeFilesystemLiveVolume
+				fakeRoommate   *volumefakes.FakeFilesystemLiveVolume
+			)
+
+			BeforeEach(func() {
+				fakeParent = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeChild = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeSibling = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeGrandchild = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeRoommate = new(volumefakes.FakeFilesystemLiveVolume)
+
+				fakeParent.HandleReturns("parent")
+				fakeChild.HandleReturns("child")
+				fakeSibling.HandleReturns("sibling")
+				fakeGrandchild.HandleReturns("grandchild")
+				fakeRoommate.HandleReturns("unrelated")
+
+				fakeChild.ParentReturns(fakeParent, true, nil)
+				fakeSibling.ParentReturns(fakeParent, true, nil)
+				fakeGrandchild.ParentReturns(fakeChild, true, nil)
+
+				fakeFilesystem.ListVolumesReturns([]volume.FilesystemLiveVolume{
+					fakeParent,
+					fakeChild,
+					fakeSibling,
+					fakeGrandchild,
+					fakeRoommate,
+				}, nil)
+				fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
+					if handle == "child" {
+						return fakeChild, true, nil
+					}
+					if handle == "parent" {
+						return fakeParent, true, nil
+					}
+					if handle == "grandchild" {
+						return fakeGrandchild, true, nil
+					}
+					if handle == "sibling" {
+						return fakeSibling, true, nil
+					}
+					if handle == "unrelated" {
+						return fakeRoommate, true, nil
+					}
+					return nil, false, nil
+				}
+			})
+
+			It("wipes out the whole family", func() {
+				Expect(fakeParent.DestroyCallCount()).To(Equal(1))
+				Expect(fakeChild.DestroyCallCount()).To(Equal(1))
+				Expect(fakeSibling.DestroyCallCount()).To(Equal(1))
 				Expect(fakeGrandchild.DestroyCallCount()).To(Equal(1))
 				Expect(fakeRoommate.DestroyCallCount()).To(Equal(0))
 			})
+
+			It("returns what it destroyed, each volume's descendants before it", func() {
+				Expect(destroyed).To(Equal([]string{"grandchild", "child", "sibling", "parent"}))
+			})
+
+			It("records the descendants as cascaded and the volume with the given reason", func() {
+				reasons := map[string]volume.DestroyReason{}
+				for i := 0; i < fakeDestroyAuditLog.RecordCallCount(); i++ {
+					entry := fakeDestroyAuditLog.RecordArgsForCall(i)
+					reasons[entry.Handle] = entry.Reason
+				}
+
+				Expect(reasons).To(Equal(map[string]volume.DestroyReason{
+					"parent":     volume.DestroyReasonCorrupted,
+					"child":      volume.DestroyReasonForceCascade,
+					"sibling":    volume.DestroyReasonForceCascade,
+					"grandchild": volume.DestroyReasonForceCascade,
+				}))
+			})
 		})
 
 		Context("when looking up the volume fails", func() {
@@ -511,36 +1209,40 @@ var _ = Describe("Repository", func() {
 				It("returns all volumes", func() {
 					Expect(volumes).To(Equal(volume.Volumes{
 						{
-							Handle:     "handle-1",
-							Path:       "handle-1-data-path",
-							Properties: volume.Properties{"a": "a", "b": "b"},
-							TTL:        1,
-							ExpiresAt:  time.Unix(1, 0),
-							Privileged: true,
+							Handle:         "handle-1",
+							Path:           "handle-1-data-path",
+							Properties:     volume.Properties{"a": "a", "b": "b"},
+							TTL:            1,
+							ExpiresAt:      time.Unix(1, 0),
+							PendingDestroy: true,
+							Privileged:     true,
 						},
 						{
-							Handle:     "handle-2",
-							Path:       "handle-2-data-path",
-							Properties: volume.Properties{"a": "a"},
-							TTL:        2,
-							ExpiresAt:  time.Unix(2, 0),
-							Privileged: false,
+							Handle:         "handle-2",
+							Path:           "handle-2-data-path",
+							Properties:     volume.Properties{"a": "a"},
+							TTL:            2,
+							ExpiresAt:      time.Unix(2, 0),
+							PendingDestroy: true,
+							Privileged:     false,
 						},
 						{
-							Handle:     "handle-3",
-							Path:       "handle-3-data-path",
-							Properties: volume.Properties{"b": "b"},
-							TTL:        3,
-							ExpiresAt:  time.Unix(3, 0),
-							Privileged: true,
+							Handle:         "handle-3",
+							Path:           "handle-3-data-path",
+							Properties:     volume.Properties{"b": "b"},
+							TTL:            3,
+							ExpiresAt:      time.Unix(3, 0),
+							PendingDestroy: true,
+							Privileged:     true,
 						},
 						{
-							Handle:     "handle-4",
-							Path:       "handle-4-data-path",
-							Properties: volume.Properties{},
-							TTL:        4,
-							ExpiresAt:  time.Unix(4, 0),
-							Privileged: false,
+							Handle:         "handle-4",
+							Path:           "handle-4-data-path",
+							Properties:     volume.Properties{},
+							TTL:            4,
+							ExpiresAt:      time.Unix(4, 0),
+							PendingDestroy: true,
+							Privileged:     false,
 						},
 					}))
 				})
@@ -554,28 +1256,31 @@ var _ = Describe("Repository", func() {
 						It("is not included in the response", func() {
 							Expect(volumes).To(Equal(volume.Volumes{
 								{
-									Handle:     "handle-1",
-									Path:       "handle-1-data-path",
-									Properties: volume.Properties{"a": "a", "b": "b"},
-									TTL:        1,
-									ExpiresAt:  time.Unix(1, 0),
-									Privileged: true,
+									Handle:         "handle-1",
+									Path:           "handle-1-data-path",
+									Properties:     volume.Properties{"a": "a", "b": "b"},
+									TTL:            1,
+									ExpiresAt:      time.Unix(1, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 								{
-									Handle:     "handle-3",
-									Path:       "handle-3-data-path",
-									Properties: volume.Properties{"b": "b"},
-									TTL:        3,
-									ExpiresAt:  time.Unix(3, 0),
-									Privileged: true,
+									Handle:         "handle-3",
+									Path:           "handle-3-data-path",
+									Properties:     volume.Properties{"b": "b"},
+									TTL:            3,
+									ExpiresAt:      time.Unix(3, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 								{
-									Handle:     "handle-4",
-									Path:       "handle-4-data-path",
-									Properties: volume.Properties{},
-									TTL:        4,
-									ExpiresAt:  time.Unix(4, 0),
-									Privileged: false,
+									Handle:         "handle-4",
+									Path:           "handle-4-data-path",
+									Properties:     volume.Properties{},
+									TTL:            4,
+									ExpiresAt:      time.Unix(4, 0),
+									PendingDestroy: true,
+									Privileged:     false,
 								},
 							}))
 						})
@@ -589,28 +1294,31 @@ var _ = Describe("Repository", func() {
 						It("returns corrupted and working volumes", func() {
 							Expect(volumes).To(Equal(volume.Volumes{
 								{
-									Handle:     "handle-1",
-									Path:       "handle-1-data-path",
-									Properties: volume.Properties{"a": "a", "b": "b"},
-									TTL:        1,
-									ExpiresAt:  time.Unix(1, 0),
-									Privileged: true,
+									Handle:         "handle-1",
+									Path:           "handle-1-data-path",
+									Properties:     volume.Properties{"a": "a", "b": "b"},
+									TTL:            1,
+									ExpiresAt:      time.Unix(1, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 								{
-									Handle:     "handle-3",
-									Path:       "handle-3-data-path",
-									Properties: volume.Properties{"b": "b"},
-									TTL:        3,
-									ExpiresAt:  time.Unix(3, 0),
-									Privileged: true,
+									Handle:         "handle-3",
+									Path:           "handle-3-data-path",
+									Properties:     volume.Properties{"b": "b"},
+									TTL:            3,
+									ExpiresAt:      time.Unix(3, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 								{
-									Handle:     "handle-4",
-									Path:       "handle-4-data-path",
-									Properties: volume.Properties{},
-									TTL:        4,
-									ExpiresAt:  time.Unix(4, 0),
-									Privileged: false,
+									Handle:         "handle-4",
+									Path:           "handle-4-data-path",
+									Properties:     volume.Properties{},
+									TTL:            4,
+									ExpiresAt:      time.Unix(4, 0),
+									PendingDestroy: true,
+									Privileged:     false,
 								},
 							}))
 
@@ -628,20 +1336,22 @@ var _ = Describe("Repository", func() {
 				It("returns only volumes whose properties match", func() {
 					Expect(volumes).To(Equal(volume.Volumes{
 						{
-							Handle:     "handle-1",
-							Path:       "handle-1-data-path",
-							Properties: volume.Properties{"a": "a", "b": "b"},
-							TTL:        1,
-							ExpiresAt:  time.Unix(1, 0),
-							Privileged: true,
+							Handle:         "handle-1",
+							Path:           "handle-1-data-path",
+							Properties:     volume.Properties{"a": "a", "b": "b"},
+							TTL:            1,
+							ExpiresAt:      time.Unix(1, 0),
+							PendingDestroy: true,
+							Privileged:     true,
 						},
 						{
-							Handle:     "handle-2",
-							Path:       "handle-2-data-path",
-							Properties: volume.Properties{"a": "a"},
-							TTL:        2,
-							ExpiresAt:  time.Unix(2, 0),
-							Privileged: false,
+							Handle:         "handle-2",
+							Path:           "handle-2-data-path",
+							Properties:     volume.Properties{"a": "a"},
+							TTL:            2,
+							ExpiresAt:      time.Unix(2, 0),
+							PendingDestroy: true,
+							Privileged:     false,
 						},
 					}))
 				})
@@ -655,12 +1365,13 @@ var _ = Describe("Repository", func() {
 						It("is not included in the response", func() {
 							Expect(volumes).To(Equal(volume.Volumes{
 								{
-									Handle:     "handle-1",
-									Path:       "handle-1-data-path",
-									Properties: volume.Properties{"a": "a", "b": "b"},
-									TTL:        1,
-									ExpiresAt:  time.Unix(1, 0),
-									Privileged: true,
+									Handle:         "handle-1",
+									Path:           "handle-1-data-path",
+									Properties:     volume.Properties{"a": "a", "b": "b"},
+									TTL:            1,
+									ExpiresAt:      time.Unix(1, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 							}))
 						})
@@ -674,12 +1385,13 @@ var _ = Describe("Repository", func() {
 						It("returns corrupted and working volumes", func() {
 							Expect(volumes).To(Equal(volume.Volumes{
 								{
-									Handle:     "handle-1",
-									Path:       "handle-1-data-path",
-									Properties: volume.Properties{"a": "a", "b": "b"},
-									TTL:        1,
-									ExpiresAt:  time.Unix(1, 0),
-									Privileged: true,
+									Handle:         "handle-1",
+									Path:           "handle-1-data-path",
+									Properties:     volume.Properties{"a": "a", "b": "b"},
+									TTL:            1,
+									ExpiresAt:      time.Unix(1, 0),
+									PendingDestroy: true,
+									Privileged:     true,
 								},
 							}))
 
@@ -688,6 +1400,60 @@ var _ = Describe("Repository", func() {
 					})
 				})
 			})
+
+			Context("when the queried property is indexed", func() {
+				BeforeEach(func() {
+					indexedProperties = []string{"a"}
+					queryProperties = volume.Properties{"a": "a", "b": "b"}
+
+					fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
+						for _, fakeVolume := range []*volumefakes.FakeFilesystemLiveVolume{fakeVolume1, fakeVolume2, fakeVolume3, fakeVolume4} {
+							if fakeVolume.Handle() == handle {
+								return fakeVolume, true, nil
+							}
+						}
+
+						return nil, false, nil
+					}
+				})
+
+				It("returns only volumes matching the whole query", func() {
+					Expect(listErr).ToNot(HaveOccurred())
+					Expect(volumes).To(HaveLen(1))
+					Expect(volumes[0].Handle).To(Equal("handle-1"))
+				})
+
+				It("only scans the volumes the first time", func() {
+					Expect(fakeFilesystem.ListVolumesCallCount()).To(Equal(1))
+
+					volumes, _, err := repository.ListVolumes(volume.Properties{"a": "a"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(volumes).To(HaveLen(2))
+
+					Expect(fakeFilesystem.ListVolumesCallCount()).To(Equal(1))
+					Expect(fakeFilesystem.LookupVolumeArgsForCall(fakeFilesystem.LookupVolumeCallCount() - 1)).To(Equal("handle-2"))
+				})
+
+				It("picks up property changes", func() {
+					fakeVolume3.LoadPropertiesReturns(volume.Properties{"b": "b"}, nil)
+					Expect(repository.SetProperty("handle-3", "a", "a", nil, "")).To(Succeed())
+					fakeVolume3.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
+
+					volumes, _, err := repository.ListVolumes(volume.Properties{"a": "a", "b": "b"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(volumes).To(HaveLen(2))
+					Expect(volumes[1].Handle).To(Equal("handle-3"))
+				})
+
+				It("forgets destroyed volumes", func() {
+					Expect(repository.DestroyVolume("handle-2", volume.DestroyOptions{})).To(Succeed())
+
+					volumes, _, err := repository.ListVolumes(volume.Properties{"a": "a"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(volumes).To(HaveLen(1))
+					Expect(volumes[0].Handle).To(Equal("handle-1"))
+				})
+			})
 		})
 
 		Context("when listing the volumes on the filesystem fails", func() {
@@ -740,27 +1506,157 @@ var _ = Describe("Repository", func() {
 			It("returns the volume and true", func() {
 				Expect(found).To(BeTrue())
 				Expect(foundVolume).To(Equal(volume.Volume{
-					Handle:     "some-volume",
-					Path:       "some-data-path",
-					Properties: volume.Properties{"a": "a", "b": "b"},
-					TTL:        1,
-					ExpiresAt:  time.Unix(1, 0),
-					Privileged: true,
+					Handle:         "some-volume",
+					Path:           "some-data-path",
+					Properties:     volume.Properties{"a": "a", "b": "b"},
+					TTL:            1,
+					ExpiresAt:      time.Unix(1, 0),
+					PendingDestroy: true,
+					Privileged:     true,
 				}))
 			})
 
-			Context("when hydrating one the volume fails", func() {
-				Context("with ErrVolumeDoesNotExist", func() {
-					BeforeEach(func() {
-						fakeVolume.LoadPropertiesReturns(nil, volume.ErrVolumeDoesNotExist)
-					})
+			Context("when the volume records when it was created and modified", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadCreatedReturns(time.Unix(10, 0), "some-strategy", nil)
+					fakeVolume.LoadModifiedReturns(time.Unix(20, 0), nil)
+				})
 
-					It("succeeds", func() {
-						Expect(getErr).ToNot(HaveOccurred())
-					})
+				It("returns both timestamps", func() {
+					Expect(foundVolume.CreatedAt).To(Equal(time.Unix(10, 0)))
+					Expect(foundVolume.ModifiedAt).To(Equal(time.Unix(20, 0)))
+				})
+			})
 
-					It("returns no volume and false", func() {
-						Expect(found).To(BeFalse())
+			Context("when the volume has a recorded digest", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadDigestReturns("sha256:some-digest", nil)
+				})
+
+				It("returns it", func() {
+					Expect(foundVolume.Digest).To(Equal("sha256:some-digest"))
+				})
+			})
+
+			Context("when the volume has never been modified", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadCreatedReturns(time.Unix(10, 0), "some-strategy", nil)
+				})
+
+				It("was last modified when it was created", func() {
+					Expect(foundVolume.ModifiedAt).To(Equal(time.Unix(10, 0)))
+				})
+			})
+
+			Context("when the volume predates recording when it was created", func() {
+				var dataDir string
+
+				BeforeEach(func() {
+					var err error
+					dataDir, err = ioutil.TempDir("", "get-volume-data")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(os.Chtimes(dataDir, time.Unix(30, 0), time.Unix(30, 0))).To(Succeed())
+
+					fakeVolume.DataPathReturns(dataDir)
+				})
+
+				AfterEach(func() {
+					Expect(os.RemoveAll(dataDir)).To(Succeed())
+				})
+
+				It("takes both timestamps from the data directory", func() {
+					Expect(foundVolume.CreatedAt).To(Equal(time.Unix(30, 0)))
+					Expect(foundVolume.ModifiedAt).To(Equal(time.Unix(30, 0)))
+				})
+			})
+
+			Context("when the volume has not expired yet by the repository's clock", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadTTLReturns(1, time.Unix(101, 0), nil)
+				})
+
+				It("is not pending destroy", func() {
+					Expect(foundVolume.PendingDestroy).To(BeFalse())
+				})
+
+				Context("and then the clock passes the expiry", func() {
+					BeforeEach(func() {
+						fakeClock.Increment(2 * time.Second)
+					})
+
+					It("is pending destroy", func() {
+						Expect(foundVolume.PendingDestroy).To(BeTrue())
+					})
+				})
+			})
+
+			Context("when the volume has an unlimited TTL", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadTTLReturns(0, time.Time{}, nil)
+				})
+
+				It("is not pending destroy", func() {
+					Expect(foundVolume.PendingDestroy).To(BeFalse())
+				})
+			})
+
+			It("leaves its TTL alone", func() {
+				Expect(fakeVolume.StoreTTLCallCount()).To(BeZero())
+			})
+
+			Context("when the volume renews its TTL on access", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadRenewTTLOnAccessReturns(true, nil)
+				})
+
+				It("starts its TTL over while holding its lock", func() {
+					Expect(fakeVolume.StoreTTLCallCount()).To(Equal(1))
+					Expect(fakeVolume.StoreTTLArgsForCall(0)).To(Equal(volume.TTL(1)))
+
+					Expect(fakeLocker.LockCallCount()).To(Equal(1))
+					Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-volume"))
+					Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+				})
+
+				It("returns it as renewing its TTL on access", func() {
+					Expect(foundVolume.RenewTTLOnAccess).To(BeTrue())
+				})
+
+				Context("when its TTL is unlimited", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadTTLReturns(0, time.Time{}, nil)
+					})
+
+					It("leaves it unlimited", func() {
+						Expect(fakeVolume.StoreTTLCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when renewing its TTL fails", func() {
+					BeforeEach(func() {
+						fakeVolume.StoreTTLReturns(time.Time{}, errors.New("nope"))
+					})
+
+					It("still returns the volume", func() {
+						Expect(getErr).NotTo(HaveOccurred())
+						Expect(found).To(BeTrue())
+					})
+				})
+			})
+
+			Context("when hydrating one the volume fails", func() {
+				Context("with ErrVolumeDoesNotExist", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadPropertiesReturns(nil, volume.ErrVolumeDoesNotExist)
+					})
+
+					It("succeeds", func() {
+						Expect(getErr).ToNot(HaveOccurred())
+					})
+
+					It("returns no volume and false", func() {
+						Expect(found).To(BeFalse())
 						Expect(foundVolume).To(BeZero())
 					})
 				})
@@ -807,13 +1703,60 @@ var _ = Describe("Repository", func() {
 		})
 	})
 
-	Describe("SetProperty", func() {
+	Describe("VolumeExists", func() {
+		It("finds the volume by its handle, without loading any of it", func() {
+			fakeVolume := new(volumefakes.FakeFilesystemLiveVolume)
+			fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+
+			exists, err := repository.VolumeExists("some-volume")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+
+			Expect(fakeFilesystem.LookupVolumeArgsForCall(0)).To(Equal("some-volume"))
+			Expect(fakeVolume.Invocations()).To(BeEmpty())
+		})
+
+		It("does not find a volume that is not in the filesystem", func() {
+			fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+
+			exists, err := repository.VolumeExists("some-volume")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("returns the error when the filesystem cannot be looked in", func() {
+			disaster := errors.New("disaster")
+			fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
+
+			_, err := repository.VolumeExists("some-volume")
+			Expect(err).To(Equal(disaster))
+		})
+	})
+
+	Describe("WithLogData", func() {
+		It("tags everything the repository logs with the data", func() {
+			fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+
+			_, found, err := repository.WithLogData(lager.Data{"request-id": "some-request"}).GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			Expect(logger.Logs()).To(HaveLen(1))
+			Expect(logger.Logs()[0].Message).To(Equal("test.get-volume.volume-not-found"))
+			Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("request-id", "some-request"))
+			Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("volume", "some-handle"))
+		})
+	})
+
+	Describe("GetVolumeStats", func() {
 		var (
-			setErr error
+			stats    volume.VolumeStats
+			found    bool
+			statsErr error
 		)
 
 		JustBeforeEach(func() {
-			setErr = repository.SetProperty("some-volume", "some-property", "some-value")
+			stats, found, statsErr = repository.GetVolumeStats("some-volume")
 		})
 
 		Context("when the volume is found in the filesystem", func() {
@@ -821,59 +1764,32 @@ var _ = Describe("Repository", func() {
 
 			BeforeEach(func() {
 				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeVolume.HandleReturns("some-volume")
-				fakeVolume.DataPathReturns("some-data-path")
-				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
-				fakeVolume.LoadTTLReturns(1, time.Unix(1, 0), nil)
+				fakeVolume.StatsReturns(volume.VolumeStats{
+					SizeInBytes: 1024,
+					FileCount:   42,
+				}, nil)
 
 				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
 			})
 
-			Context("when storing the new properties succeeds", func() {
-				BeforeEach(func() {
-					fakeVolume.StorePropertiesReturns(nil)
-				})
-
-				It("succeeds", func() {
-					Expect(setErr).ToNot(HaveOccurred())
-				})
-
-				It("found it by looking for the right handle", func() {
-					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
-					Expect(handle).To(Equal("some-volume"))
-				})
-
-				It("stored the right properties", func() {
-					newProperties := fakeVolume.StorePropertiesArgsForCall(0)
-					Expect(newProperties).To(Equal(volume.Properties{
-						"a":             "a",
-						"b":             "b",
-						"some-property": "some-value",
-					}))
-				})
-			})
-
-			Context("when storing the new properties fails", func() {
-				disaster := errors.New("nope")
-
-				BeforeEach(func() {
-					fakeVolume.StorePropertiesReturns(disaster)
-				})
-
-				It("returns the error", func() {
-					Expect(setErr).To(Equal(disaster))
-				})
+			It("returns the size and file count", func() {
+				Expect(statsErr).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(stats).To(Equal(volume.VolumeStats{
+					SizeInBytes: 1024,
+					FileCount:   42,
+				}))
 			})
 
-			Context("when hydrating the volume fails", func() {
+			Context("when getting the stats fails", func() {
 				disaster := errors.New("nope")
 
 				BeforeEach(func() {
-					fakeVolume.LoadPropertiesReturns(nil, disaster)
+					fakeVolume.StatsReturns(volume.VolumeStats{}, disaster)
 				})
 
 				It("returns the error", func() {
-					Expect(setErr).To(Equal(disaster))
+					Expect(statsErr).To(Equal(disaster))
 				})
 			})
 		})
@@ -883,31 +1799,21 @@ var _ = Describe("Repository", func() {
 				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
 			})
 
-			It("returns ErrVolumeDoesNotExist", func() {
-				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
-			})
-		})
-
-		Context("when looking up the volume on the filesystem fails", func() {
-			disaster := errors.New("nope")
-
-			BeforeEach(func() {
-				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
-			})
-
-			It("returns the error", func() {
-				Expect(setErr).To(Equal(disaster))
+			It("returns false", func() {
+				Expect(statsErr).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
 			})
 		})
 	})
 
-	Describe("SetTTL", func() {
+	Describe("GetProperty", func() {
 		var (
-			setErr error
+			value  string
+			getErr error
 		)
 
 		JustBeforeEach(func() {
-			setErr = repository.SetTTL("some-volume", 42)
+			value, getErr = repository.GetProperty("some-volume", "a")
 		})
 
 		Context("when the volume is found in the filesystem", func() {
@@ -915,83 +1821,67 @@ var _ = Describe("Repository", func() {
 
 			BeforeEach(func() {
 				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
-				fakeVolume.HandleReturns("some-volume")
-				fakeVolume.DataPathReturns("some-data-path")
-				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
-				fakeVolume.LoadTTLReturns(1, time.Unix(1, 0), nil)
-				fakeVolume.LoadPrivilegedReturns(false, nil)
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "some-value", "b": "b"}, nil)
 
 				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
 			})
 
-			Context("when storing the new properties succeeds", func() {
-				var expiresAt time.Time
+			It("returns the property's value", func() {
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(value).To(Equal("some-value"))
+				Expect(fakeFilesystem.LookupVolumeArgsForCall(0)).To(Equal("some-volume"))
+			})
 
+			Context("when the volume does not have the property", func() {
 				BeforeEach(func() {
-					expiresAt = time.Now()
-					fakeVolume.StoreTTLReturns(expiresAt, nil)
-				})
-
-				It("succeeds", func() {
-					Expect(setErr).ToNot(HaveOccurred())
-				})
-
-				It("found it by looking for the right handle", func() {
-					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
-					Expect(handle).To(Equal("some-volume"))
+					fakeVolume.LoadPropertiesReturns(volume.Properties{"b": "b"}, nil)
 				})
 
-				It("stored the right ttl", func() {
-					newTTL := fakeVolume.StoreTTLArgsForCall(0)
-					Expect(newTTL).To(Equal(volume.TTL(42)))
+				It("returns ErrPropertyDoesNotExist", func() {
+					Expect(getErr).To(Equal(volume.ErrPropertyDoesNotExist))
 				})
 			})
 
-			Context("when storing the new ttl fails", func() {
+			Context("when loading the properties fails", func() {
 				disaster := errors.New("nope")
 
 				BeforeEach(func() {
-					fakeVolume.StoreTTLReturns(time.Time{}, disaster)
+					fakeVolume.LoadPropertiesReturns(nil, disaster)
 				})
 
 				It("returns the error", func() {
-					Expect(setErr).To(Equal(disaster))
+					Expect(getErr).To(Equal(disaster))
 				})
 			})
 		})
 
-		Context("when the volume is not found on the filesystem", func() {
+		Context("when the volume is not found", func() {
 			BeforeEach(func() {
 				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
 			})
 
 			It("returns ErrVolumeDoesNotExist", func() {
-				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
-			})
-		})
-
-		Context("when looking up the volume on the filesystem fails", func() {
-			disaster := errors.New("nope")
-
-			BeforeEach(func() {
-				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
-			})
-
-			It("returns the error", func() {
-				Expect(setErr).To(Equal(disaster))
+				Expect(getErr).To(Equal(volume.ErrVolumeDoesNotExist))
 			})
 		})
 	})
 
-	Describe("VolumeParent", func() {
+	Describe("SetProperty", func() {
 		var (
-			parent    volume.Volume
-			found     bool
-			parentErr error
+			expected *volume.PropertyExpectation
+
+			setErr error
 		)
 
+		var propertyName string
+
+		BeforeEach(func() {
+			expected = nil
+			propertyName = "some-property"
+		})
+
 		JustBeforeEach(func() {
-			parent, found, parentErr = repository.VolumeParent("some-volume")
+			setErr = repository.SetProperty("some-volume", propertyName, "some-value", expected, "")
 		})
 
 		Context("when the volume is found in the filesystem", func() {
@@ -1003,83 +1893,196 @@ var _ = Describe("Repository", func() {
 				fakeVolume.DataPathReturns("some-data-path")
 				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
 				fakeVolume.LoadTTLReturns(1, time.Unix(1, 0), nil)
-				fakeVolume.LoadPrivilegedReturns(false, nil)
 
 				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
 			})
 
-			Context("when the volume has a parent", func() {
-				var parentVolume *volumefakes.FakeFilesystemLiveVolume
-
+			Context("when storing the new properties succeeds", func() {
 				BeforeEach(func() {
-					parentVolume = new(volumefakes.FakeFilesystemLiveVolume)
-					parentVolume.HandleReturns("parent-volume")
-					parentVolume.DataPathReturns("parent-data-path")
-					parentVolume.LoadPropertiesReturns(volume.Properties{"parent": "property"}, nil)
-					parentVolume.LoadTTLReturns(2, time.Unix(2, 0), nil)
-					parentVolume.LoadPrivilegedReturns(true, nil)
-
-					fakeVolume.ParentReturns(parentVolume, true, nil)
+					fakeVolume.StorePropertiesReturns(nil)
 				})
 
 				It("succeeds", func() {
-					Expect(parentErr).ToNot(HaveOccurred())
+					Expect(setErr).ToNot(HaveOccurred())
 				})
 
-				It("found the child volume by looking for the right handle", func() {
+				It("found it by looking for the right handle", func() {
 					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
 					Expect(handle).To(Equal("some-volume"))
 				})
 
-				It("returns the parent volume and true", func() {
-					Expect(found).To(BeTrue())
-					Expect(parent).To(Equal(volume.Volume{
-						Handle:     "parent-volume",
-						Path:       "parent-data-path",
-						Properties: volume.Properties{"parent": "property"},
-						TTL:        2,
-						ExpiresAt:  time.Unix(2, 0),
-						Privileged: true,
+				It("stored the right properties", func() {
+					newProperties := fakeVolume.StorePropertiesArgsForCall(0)
+					Expect(newProperties).To(Equal(volume.Properties{
+						"a":             "a",
+						"b":             "b",
+						"some-property": "some-value",
 					}))
 				})
 
-				Context("when hydrating the parent volume fails", func() {
-					disaster := errors.New("nope")
+				It("records that the volume was modified", func() {
+					Expect(fakeVolume.StoreModifiedCallCount()).To(Equal(1))
+				})
 
+				Context("when recording the modification fails", func() {
 					BeforeEach(func() {
-						parentVolume.LoadPropertiesReturns(nil, disaster)
+						fakeVolume.StoreModifiedReturns(time.Time{}, errors.New("nope"))
 					})
 
-					It("returns the special error", func() {
-						Expect(parentErr).To(Equal(volume.ErrVolumeIsCorrupted))
+					It("still succeeds", func() {
+						Expect(setErr).ToNot(HaveOccurred())
 					})
 				})
-			})
 
-			Context("when the volume does not have a parent", func() {
-				BeforeEach(func() {
-					fakeVolume.ParentReturns(nil, false, nil)
+				Context("when the property is expected to have the value it has", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadPropertiesReturns(volume.Properties{"some-property": "old-value"}, nil)
+						expected = &volume.PropertyExpectation{Value: "old-value"}
+					})
+
+					It("sets it", func() {
+						Expect(setErr).ToNot(HaveOccurred())
+						Expect(fakeVolume.StorePropertiesArgsForCall(0)).To(Equal(volume.Properties{
+							"some-property": "some-value",
+						}))
+					})
 				})
 
-				It("succeeds", func() {
-					Expect(parentErr).ToNot(HaveOccurred())
+				Context("when the property is expected to have another value", func() {
+					BeforeEach(func() {
+						fakeVolume.LoadPropertiesReturns(volume.Properties{"some-property": "old-value"}, nil)
+						expected = &volume.PropertyExpectation{Value: "other-value"}
+					})
+
+					It("returns ErrPropertyConflict, leaving the properties as they were", func() {
+						Expect(setErr).To(Equal(volume.ErrPropertyConflict))
+						Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+						Expect(fakeVolume.StoreModifiedCallCount()).To(BeZero())
+					})
 				})
 
-				It("returns no volume and false", func() {
-					Expect(found).To(BeFalse())
-					Expect(parent).To(BeZero())
+				Context("when a property the volume does not have is expected to have a value", func() {
+					BeforeEach(func() {
+						expected = &volume.PropertyExpectation{Value: ""}
+					})
+
+					It("returns ErrPropertyConflict", func() {
+						Expect(setErr).To(Equal(volume.ErrPropertyConflict))
+						Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when the property is expected to be absent", func() {
+					BeforeEach(func() {
+						expected = &volume.PropertyExpectation{Absent: true}
+					})
+
+					It("sets it if the volume does not have it", func() {
+						Expect(setErr).ToNot(HaveOccurred())
+						Expect(fakeVolume.StorePropertiesArgsForCall(0)).To(HaveKeyWithValue("some-property", "some-value"))
+					})
+
+					Context("when the volume has it", func() {
+						BeforeEach(func() {
+							fakeVolume.LoadPropertiesReturns(volume.Properties{"some-property": ""}, nil)
+						})
+
+						It("returns ErrPropertyConflict", func() {
+							Expect(setErr).To(Equal(volume.ErrPropertyConflict))
+							Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+						})
+					})
 				})
 			})
 
-			Context("when getting the parent volume fails", func() {
+			Context("when storing the new properties fails", func() {
 				disaster := errors.New("nope")
 
 				BeforeEach(func() {
-					fakeVolume.ParentReturns(nil, false, disaster)
+					fakeVolume.StorePropertiesReturns(disaster)
 				})
 
 				It("returns the error", func() {
-					Expect(parentErr).To(Equal(disaster))
+					Expect(setErr).To(Equal(disaster))
+				})
+
+				It("does not record a modification", func() {
+					Expect(fakeVolume.StoreModifiedCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when hydrating the volume fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.LoadPropertiesReturns(nil, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(setErr).To(Equal(disaster))
+				})
+			})
+
+			Context("when the value is not allowed by the property's label schema", func() {
+				BeforeEach(func() {
+					labelSchemas = volume.LabelSchemas{
+						"some-property": volume.LabelSchema{Values: []string{"other-value"}},
+					}
+				})
+
+				It("returns ErrInvalidPropertyValue", func() {
+					Expect(setErr).To(Equal(volume.ErrInvalidPropertyValue))
+				})
+
+				It("does not store the properties", func() {
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the name has a newline in it", func() {
+				BeforeEach(func() {
+					propertyName = "some\nproperty"
+				})
+
+				It("returns ErrInvalidPropertyName without storing the properties", func() {
+					Expect(setErr).To(Equal(volume.ErrInvalidPropertyName))
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the name is longer than allowed", func() {
+				BeforeEach(func() {
+					propertyLimits.MaxNameLength = len("some-property") - 1
+				})
+
+				It("returns ErrInvalidPropertyName without storing the properties", func() {
+					Expect(setErr).To(Equal(volume.ErrInvalidPropertyName))
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the value is larger than allowed", func() {
+				BeforeEach(func() {
+					propertyLimits.MaxValueSize = len("some-value") - 1
+				})
+
+				It("returns ErrPropertyValueTooLarge without storing the properties", func() {
+					Expect(setErr).To(Equal(volume.ErrPropertyValueTooLarge))
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the name and value are within the limits", func() {
+				BeforeEach(func() {
+					propertyLimits = volume.PropertyLimits{
+						MaxNameLength: len("some-property"),
+						MaxValueSize:  len("some-value"),
+					}
+				})
+
+				It("stores the properties", func() {
+					Expect(setErr).ToNot(HaveOccurred())
+					Expect(fakeVolume.StorePropertiesCallCount()).To(Equal(1))
 				})
 			})
 		})
@@ -1090,7 +2093,7 @@ var _ = Describe("Repository", func() {
 			})
 
 			It("returns ErrVolumeDoesNotExist", func() {
-				Expect(parentErr).To(Equal(volume.ErrVolumeDoesNotExist))
+				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
 			})
 		})
 
@@ -1102,8 +2105,5602 @@ var _ = Describe("Repository", func() {
 			})
 
 			It("returns the error", func() {
-				Expect(parentErr).To(Equal(disaster))
+				Expect(setErr).To(Equal(disaster))
 			})
 		})
 	})
-})
+
+	Describe("SetProperties", func() {
+		var (
+			setErr error
+		)
+
+		JustBeforeEach(func() {
+			setErr = repository.SetProperties("some-volume", volume.Properties{"a": "new-a", "c": "c"}, "")
+		})
+
+		Context("when the volume is found in the filesystem", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.HandleReturns("some-volume")
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
+
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			It("stores all of the properties at once, keeping the others", func() {
+				Expect(setErr).ToNot(HaveOccurred())
+
+				Expect(fakeVolume.StorePropertiesCallCount()).To(Equal(1))
+				Expect(fakeVolume.StorePropertiesArgsForCall(0)).To(Equal(volume.Properties{
+					"a": "new-a",
+					"b": "b",
+					"c": "c",
+				}))
+			})
+
+			It("records that the volume was modified", func() {
+				Expect(fakeVolume.StoreModifiedCallCount()).To(Equal(1))
+			})
+
+			It("holds the volume's lock", func() {
+				Expect(fakeLocker.LockCallCount()).To(Equal(1))
+				Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-volume"))
+				Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+			})
+
+			Context("when one of the values is not allowed by its label schema", func() {
+				BeforeEach(func() {
+					labelSchemas = volume.LabelSchemas{
+						"c": volume.LabelSchema{Values: []string{"other-c"}},
+					}
+				})
+
+				It("returns ErrInvalidPropertyValue without storing any of them", func() {
+					Expect(setErr).To(Equal(volume.ErrInvalidPropertyValue))
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when one of the values is larger than allowed", func() {
+				BeforeEach(func() {
+					propertyLimits.MaxValueSize = len("new-a") - 1
+				})
+
+				It("returns ErrPropertyValueTooLarge without storing any of them", func() {
+					Expect(setErr).To(Equal(volume.ErrPropertyValueTooLarge))
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when storing the properties fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.StorePropertiesReturns(disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(setErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("DeleteProperty", func() {
+		var (
+			deleteErr error
+		)
+
+		JustBeforeEach(func() {
+			deleteErr = repository.DeleteProperty("some-volume", "a", "")
+		})
+
+		Context("when the volume is found in the filesystem", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.HandleReturns("some-volume")
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
+
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			It("stores the properties without it", func() {
+				Expect(deleteErr).ToNot(HaveOccurred())
+
+				Expect(fakeVolume.StorePropertiesCallCount()).To(Equal(1))
+				Expect(fakeVolume.StorePropertiesArgsForCall(0)).To(Equal(volume.Properties{"b": "b"}))
+			})
+
+			It("records that the volume was modified", func() {
+				Expect(fakeVolume.StoreModifiedCallCount()).To(Equal(1))
+			})
+
+			It("holds the volume's lock", func() {
+				Expect(fakeLocker.LockCallCount()).To(Equal(1))
+				Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-volume"))
+				Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+			})
+
+			Context("when the volume does not have the property", func() {
+				BeforeEach(func() {
+					fakeVolume.LoadPropertiesReturns(volume.Properties{"b": "b"}, nil)
+				})
+
+				It("succeeds without storing anything", func() {
+					Expect(deleteErr).ToNot(HaveOccurred())
+					Expect(fakeVolume.StorePropertiesCallCount()).To(BeZero())
+					Expect(fakeVolume.StoreModifiedCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when storing the properties fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.StorePropertiesReturns(disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(deleteErr).To(Equal(disaster))
+				})
+			})
+
+			Context("when hydrating the volume fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.LoadPropertiesReturns(nil, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(deleteErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(deleteErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("SetTTL", func() {
+		var (
+			setErr error
+		)
+
+		JustBeforeEach(func() {
+			setErr = repository.SetTTL("some-volume", 42)
+		})
+
+		Context("when the volume is found in the filesystem", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.HandleReturns("some-volume")
+				fakeVolume.DataPathReturns("some-data-path")
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
+				fakeVolume.LoadTTLReturns(1, time.Unix(1, 0), nil)
+				fakeVolume.LoadPrivilegedReturns(false, nil)
+
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			Context("when storing the new properties succeeds", func() {
+				var expiresAt time.Time
+
+				BeforeEach(func() {
+					expiresAt = time.Now()
+					fakeVolume.StoreTTLReturns(expiresAt, nil)
+				})
+
+				It("succeeds", func() {
+					Expect(setErr).ToNot(HaveOccurred())
+				})
+
+				It("found it by looking for the right handle", func() {
+					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
+					Expect(handle).To(Equal("some-volume"))
+				})
+
+				It("stored the right ttl", func() {
+					newTTL := fakeVolume.StoreTTLArgsForCall(0)
+					Expect(newTTL).To(Equal(volume.TTL(42)))
+				})
+			})
+
+			Context("when storing the new ttl fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.StoreTTLReturns(time.Time{}, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(setErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+
+		Context("when looking up the volume on the filesystem fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
+			})
+
+			It("returns the error", func() {
+				Expect(setErr).To(Equal(disaster))
+			})
+		})
+	})
+
+	Describe("SetExpiresAt", func() {
+		var (
+			expiresAt time.Time
+			setErr    error
+		)
+
+		BeforeEach(func() {
+			expiresAt = fakeClock.Now().Add(90*time.Second + 500*time.Millisecond)
+		})
+
+		JustBeforeEach(func() {
+			setErr = repository.SetExpiresAt("some-volume", expiresAt)
+		})
+
+		Context("when the volume is found in the filesystem", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.HandleReturns("some-volume")
+
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			It("stores the expiry along with the TTL remaining, rounded up", func() {
+				Expect(setErr).ToNot(HaveOccurred())
+
+				Expect(fakeVolume.StoreExpiryCallCount()).To(Equal(1))
+				ttl, storedExpiresAt := fakeVolume.StoreExpiryArgsForCall(0)
+				Expect(ttl).To(Equal(volume.TTL(91)))
+				Expect(storedExpiresAt).To(Equal(expiresAt))
+			})
+
+			It("holds the volume's lock", func() {
+				Expect(fakeLocker.LockCallCount()).To(Equal(1))
+				Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-volume"))
+				Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+			})
+
+			Context("when the expiry has already passed", func() {
+				BeforeEach(func() {
+					expiresAt = fakeClock.Now().Add(-time.Hour)
+				})
+
+				It("stores the expiry with a limited TTL, so that it is pending destroy", func() {
+					Expect(setErr).ToNot(HaveOccurred())
+
+					ttl, storedExpiresAt := fakeVolume.StoreExpiryArgsForCall(0)
+					Expect(ttl.IsUnlimited()).To(BeFalse())
+					Expect(storedExpiresAt).To(Equal(expiresAt))
+				})
+			})
+
+			Context("when storing the expiry fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.StoreExpiryReturns(disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(setErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(setErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("StreamIn", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+			subPath        string
+			streamInOpts   volume.StreamInOptions
+			ctx            context.Context
+			archive        []byte
+
+			streamErr error
+		)
+
+		checksumOf := func(contents []byte) string {
+			sum := sha256.Sum256(contents)
+			return "sha256:" + hex.EncodeToString(sum[:])
+		}
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-data")
+			Expect(err).NotTo(HaveOccurred())
+
+			tarBuffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(tarBuffer)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0600, Size: 4})).To(Succeed())
+			_, err = tarWriter.Write([]byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
+
+			archive = tarBuffer.Bytes()
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			subPath = "some/sub-path"
+			streamInOpts = volume.StreamInOptions{}
+			ctx = context.Background()
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			_, streamErr = repository.StreamIn(ctx, "some-handle", subPath, bytes.NewReader(archive), streamInOpts)
+		})
+
+		It("extracts the stream into the sub-path", func() {
+			Expect(streamErr).NotTo(HaveOccurred())
+			Expect(ioutil.ReadFile(filepath.Join(dataDir, "some", "sub-path", "some-file"))).To(Equal([]byte("data")))
+		})
+
+		It("holds the path lock for the sub-path while streaming", func() {
+			Expect(fakeStreamInLocker.LockCallCount()).To(Equal(1))
+			handle, path := fakeStreamInLocker.LockArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(path).To(Equal("some/sub-path"))
+
+			Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+			handle, path = fakeStreamInLocker.UnlockArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(path).To(Equal("some/sub-path"))
+		})
+
+		It("namespaces only the directories created for the stream", func() {
+			Expect(fakePrivilegedNamespacer.NamespacePathCallCount()).To(Equal(1))
+			_, path := fakePrivilegedNamespacer.NamespacePathArgsForCall(0)
+			Expect(path).To(Equal(filepath.Join(dataDir, "some")))
+		})
+
+		Context("when the sub-path already exists", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+			})
+
+			It("namespaces only the sub-path", func() {
+				Expect(fakePrivilegedNamespacer.NamespacePathCallCount()).To(Equal(1))
+				_, path := fakePrivilegedNamespacer.NamespacePathArgsForCall(0)
+				Expect(path).To(Equal(filepath.Join(dataDir, "some", "sub-path")))
+			})
+		})
+
+		Context("when the context is canceled", func() {
+			BeforeEach(func() {
+				canceled, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				ctx = canceled
+			})
+
+			It("returns the context's error", func() {
+				Expect(streamErr).To(Equal(context.Canceled))
+			})
+
+			It("removes the directories created for the stream", func() {
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+
+			It("does not record that the volume was modified", func() {
+				Expect(fakeLiveVolume.StoreModifiedCallCount()).To(BeZero())
+			})
+
+			Context("when the sub-path already exists", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+				})
+
+				It("leaves the sub-path in place", func() {
+					Expect(filepath.Join(dataDir, "some", "sub-path")).To(BeADirectory())
+					Expect(filepath.Join(dataDir, "some", "sub-path", "some-file")).NotTo(BeAnExistingFile())
+				})
+			})
+		})
+
+		Context("when the archive has V7 headers, without the ustar magic", func() {
+			BeforeEach(func() {
+				tarBuffer := new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarBuffer)
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0600, Size: 4, Format: tar.FormatUSTAR})).To(Succeed())
+				_, err := tarWriter.Write([]byte("data"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tarWriter.Close()).To(Succeed())
+
+				archive = tarBuffer.Bytes()
+
+				// a V7 header ends with its link name, so clear everything
+				// after it and sum the header up again
+				header := archive[:512]
+				for i := 257; i < len(header); i++ {
+					header[i] = 0
+				}
+
+				copy(header[148:156], "        ")
+
+				var sum int64
+				for _, b := range header {
+					sum += int64(b)
+				}
+
+				copy(header[148:156], fmt.Sprintf("%06o\x00 ", sum))
+			})
+
+			It("extracts it", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some", "sub-path", "some-file"))).To(Equal([]byte("data")))
+			})
+		})
+
+		Context("when the stream is neither compressed nor a tar archive", func() {
+			BeforeEach(func() {
+				archive = bytes.Repeat([]byte("<html>some error page</html>"), 64)
+			})
+
+			It("returns ErrUnknownStreamFormat", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnknownStreamFormat))
+			})
+		})
+
+		Context("when the start of the stream can't be read", func() {
+			var disaster error
+
+			BeforeEach(func() {
+				disaster = errors.New("connection reset")
+			})
+
+			It("returns the read error rather than saying it is a bad stream", func() {
+				stream, writer := io.Pipe()
+				writer.CloseWithError(disaster)
+
+				badStream, err := repository.StreamIn(ctx, "some-handle", subPath, stream, streamInOpts)
+				Expect(err).To(Equal(disaster))
+				Expect(badStream).To(BeFalse())
+			})
+		})
+
+		It("records that the volume was modified", func() {
+			Expect(fakeLiveVolume.StoreModifiedCallCount()).To(Equal(1))
+		})
+
+		It("records the digest of the volume's contents under the volume lock", func() {
+			Expect(fakeLiveVolume.StoreDigestCallCount()).To(Equal(1))
+
+			// once to count the stream, and once to record the digest
+			Expect(fakeLocker.LockCallCount()).To(Equal(2))
+			Expect(fakeLocker.LockArgsForCall(1)).To(Equal("some-handle"))
+			Expect(fakeLocker.UnlockCallCount()).To(Equal(2))
+
+			digest, err := repository.VolumeDigest("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLiveVolume.StoreDigestArgsForCall(0)).To(Equal(digest))
+		})
+
+		Context("when another stream lands while the volume is digested", func() {
+			BeforeEach(func() {
+				otherArchive := new(bytes.Buffer)
+				tarWriter := tar.NewWriter(otherArchive)
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "other-file", Mode: 0600, Size: 5})).To(Succeed())
+				_, err := tarWriter.Write([]byte("other"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tarWriter.Close()).To(Succeed())
+
+				// the lock taken to record the first stream's digest, which was
+				// taken before the other stream landed
+				fakeLocker.LockStub = func(string) {
+					if fakeLocker.LockCallCount() == 2 {
+						_, err := repository.StreamIn(context.Background(), "some-handle", "other/sub-path", otherArchive, volume.StreamInOptions{})
+						Expect(err).NotTo(HaveOccurred())
+					}
+				}
+			})
+
+			It("only records the digest taken after both had landed", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(fakeLiveVolume.StoreDigestCallCount()).To(Equal(1))
+
+				digest, err := repository.VolumeDigest("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeLiveVolume.StoreDigestArgsForCall(0)).To(Equal(digest))
+			})
+		})
+
+		It("does not record anything without an idempotency key", func() {
+			Expect(fakeLiveVolume.StoreStreamInKeysCallCount()).To(BeZero())
+		})
+
+		Context("with an idempotency key", func() {
+			BeforeEach(func() {
+				streamInOpts.IdempotencyKey = "some-key"
+			})
+
+			Context("that has not been applied yet", func() {
+				BeforeEach(func() {
+					fakeLiveVolume.LoadStreamInKeysReturns(map[string]time.Time{
+						"stale-key":  fakeClock.Now().Add(-time.Hour),
+						"recent-key": fakeClock.Now().Add(-time.Second),
+					}, nil)
+				})
+
+				It("extracts the stream", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(filepath.Join(dataDir, "some", "sub-path", "some-file")).To(BeARegularFile())
+				})
+
+				It("records the key under the volume lock, dropping expired keys", func() {
+					Expect(fakeLiveVolume.StoreStreamInKeysCallCount()).To(Equal(1))
+					Expect(fakeLiveVolume.StoreStreamInKeysArgsForCall(0)).To(Equal(map[string]time.Time{
+						"recent-key": fakeClock.Now().Add(-time.Second),
+						"some-key":   fakeClock.Now(),
+					}))
+
+					// once to count the stream, once to record the digest,
+					// and once for the key
+					Expect(fakeLocker.LockCallCount()).To(Equal(3))
+					Expect(fakeLocker.LockArgsForCall(2)).To(Equal("some-handle"))
+					Expect(fakeLocker.UnlockCallCount()).To(Equal(3))
+				})
+
+				Context("when recording the key fails", func() {
+					BeforeEach(func() {
+						fakeLiveVolume.StoreStreamInKeysReturns(errors.New("nope"))
+					})
+
+					It("still succeeds, as the stream has been applied", func() {
+						Expect(streamErr).NotTo(HaveOccurred())
+					})
+				})
+			})
+
+			Context("that was applied within the window", func() {
+				BeforeEach(func() {
+					fakeLiveVolume.LoadStreamInKeysReturns(map[string]time.Time{
+						"some-key": fakeClock.Now().Add(-30 * time.Second),
+					}, nil)
+				})
+
+				It("returns ErrStreamInAlreadyApplied without extracting anything", func() {
+					Expect(streamErr).To(Equal(volume.ErrStreamInAlreadyApplied))
+					Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+					Expect(fakeLiveVolume.StoreStreamInKeysCallCount()).To(BeZero())
+				})
+			})
+
+			Context("that was applied before the window", func() {
+				BeforeEach(func() {
+					fakeLiveVolume.LoadStreamInKeysReturns(map[string]time.Time{
+						"some-key": fakeClock.Now().Add(-time.Minute),
+					}, nil)
+				})
+
+				It("applies the stream again", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(filepath.Join(dataDir, "some", "sub-path", "some-file")).To(BeARegularFile())
+				})
+			})
+		})
+
+		Context("with a malformed SELinux label", func() {
+			BeforeEach(func() {
+				streamInOpts.SELinuxLabel = "not a label"
+			})
+
+			It("returns ErrInvalidSELinuxLabel without extracting anything", func() {
+				Expect(streamErr).To(Equal(volume.ErrInvalidSELinuxLabel))
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+		})
+
+		Context("when the volume is frozen", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.LoadCommittedReturns(time.Now(), true, nil)
+			})
+
+			It("returns ErrVolumeIsFrozen without extracting anything", func() {
+				Expect(streamErr).To(Equal(volume.ErrVolumeIsFrozen))
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+
+			It("releases the path lock", func() {
+				Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the volume does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(streamErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+
+		Context("when the stream matches its checksum", func() {
+			BeforeEach(func() {
+				checksum := checksumOf(archive)
+				streamInOpts.Checksum = func() string { return checksum }
+			})
+
+			It("extracts it, recording that the volume was modified", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some", "sub-path", "some-file"))).To(Equal([]byte("data")))
+				Expect(fakeLiveVolume.StoreModifiedCallCount()).To(Equal(1))
+			})
+
+			Context("when the stream is compressed", func() {
+				BeforeEach(func() {
+					compressed := new(bytes.Buffer)
+					gzipWriter := gzip.NewWriter(compressed)
+					_, err := gzipWriter.Write(archive)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(gzipWriter.Close()).To(Succeed())
+
+					archive = compressed.Bytes()
+				})
+
+				It("checks the checksum of the tar once decompressed", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(ioutil.ReadFile(filepath.Join(dataDir, "some", "sub-path", "some-file"))).To(Equal([]byte("data")))
+				})
+			})
+
+			Context("when the tar is padded", func() {
+				BeforeEach(func() {
+					archive = append(archive, make([]byte, 4096)...)
+				})
+
+				It("checks the checksum of all of it", func() {
+					Expect(streamErr).To(Equal(volume.ErrStreamChecksumMismatch))
+				})
+			})
+		})
+
+		Context("when the stream does not match its checksum", func() {
+			BeforeEach(func() {
+				checksum := checksumOf([]byte("something else"))
+				streamInOpts.Checksum = func() string { return checksum }
+			})
+
+			It("returns ErrStreamChecksumMismatch", func() {
+				Expect(streamErr).To(Equal(volume.ErrStreamChecksumMismatch))
+			})
+
+			It("removes the directories created for the stream", func() {
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+
+			It("does not record that the volume was modified", func() {
+				Expect(fakeLiveVolume.StoreModifiedCallCount()).To(BeZero())
+				Expect(fakeLiveVolume.StoreDigestCallCount()).To(BeZero())
+			})
+
+			Context("when the sub-path already exists", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(dataDir, "some", "sub-path", "other-file"), []byte("kept"), 0644)).To(Succeed())
+				})
+
+				It("removes only what the stream extracted", func() {
+					Expect(filepath.Join(dataDir, "some", "sub-path", "some-file")).NotTo(BeAnExistingFile())
+					Expect(filepath.Join(dataDir, "some", "sub-path", "other-file")).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		Context("when the checksum is only known once the stream has been read", func() {
+			BeforeEach(func() {
+				expected := checksumOf(archive)
+
+				asked := 0
+				streamInOpts.Checksum = func() string {
+					asked++
+					if asked == 1 {
+						return ""
+					}
+
+					return expected
+				}
+			})
+
+			It("checks it then", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some", "sub-path", "some-file"))).To(Equal([]byte("data")))
+			})
+		})
+
+		Context("when the checksum is malformed", func() {
+			BeforeEach(func() {
+				streamInOpts.Checksum = func() string { return "md5:d41d8cd98f00b204e9800998ecf8427e" }
+			})
+
+			It("returns ErrInvalidChecksum without reading the stream", func() {
+				Expect(streamErr).To(Equal(volume.ErrInvalidChecksum))
+				Expect(fakeFilesystem.LookupVolumeCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("StreamIn when the disk fills up", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+
+			streamErr error
+		)
+
+		BeforeEach(func() {
+			if runtime.GOOS != "linux" || os.Geteuid() != 0 {
+				Skip("needs to mount a tmpfs")
+			}
+
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-full-disk")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = exec.Command("mount", "-t", "tmpfs", "-o", "size=256k", "tmpfs", dataDir).Run()
+			if err != nil {
+				os.RemoveAll(dataDir)
+				Skip("cannot mount a tmpfs: " + err.Error())
+			}
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+		})
+
+		AfterEach(func() {
+			Expect(exec.Command("umount", dataDir).Run()).To(Succeed())
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			tarBuffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(tarBuffer)
+
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "small-file", Mode: 0600, Size: 4})).To(Succeed())
+			_, err := tarWriter.Write([]byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "big-file", Mode: 0600, Size: 1024 * 1024})).To(Succeed())
+			_, err = tarWriter.Write(make([]byte, 1024*1024))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tarWriter.Close()).To(Succeed())
+
+			_, streamErr = repository.StreamIn(context.Background(), "some-handle", "some/sub-path", tarBuffer, volume.StreamInOptions{})
+		})
+
+		It("returns a NoSpaceError with the bytes written", func() {
+			Expect(streamErr).To(BeAssignableToTypeOf(volume.NoSpaceError{}))
+			Expect(streamErr.(volume.NoSpaceError).BytesWritten).To(BeNumerically(">", 0))
+		})
+
+		It("removes the directories created for the stream", func() {
+			Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+		})
+
+		Context("when the sub-path already exists", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(dataDir, "some", "sub-path", "existing-file"), []byte("data"), 0644)).To(Succeed())
+			})
+
+			It("removes only what the stream extracted", func() {
+				Expect(filepath.Join(dataDir, "some", "sub-path", "existing-file")).To(BeARegularFile())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "small-file")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "big-file")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("StreamIn a stream that breaks off part way", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+
+			disaster  error
+			badStream bool
+			streamErr error
+		)
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-broken-off")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			disaster = errors.New("chunk does not match its checksum")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			stream, writer := io.Pipe()
+
+			go func() {
+				defer GinkgoRecover()
+
+				// the first file in full, and only the start of the second
+				tarWriter := tar.NewWriter(writer)
+
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "first-file", Mode: 0600, Size: 4})).To(Succeed())
+				_, err := tarWriter.Write([]byte("data"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "second-file", Mode: 0600, Size: 1024 * 1024})).To(Succeed())
+				_, err = tarWriter.Write(make([]byte, 1024))
+				Expect(err).NotTo(HaveOccurred())
+
+				writer.CloseWithError(disaster)
+			}()
+
+			badStream, streamErr = repository.StreamIn(context.Background(), "some-handle", "some/sub-path", stream, volume.StreamInOptions{})
+		})
+
+		It("returns the read error rather than saying it is a bad stream", func() {
+			Expect(streamErr).To(Equal(disaster))
+			Expect(badStream).To(BeFalse())
+		})
+
+		It("removes the directories created for the stream", func() {
+			Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+		})
+
+		It("does not record that the volume was modified", func() {
+			Expect(fakeLiveVolume.StoreModifiedCallCount()).To(BeZero())
+		})
+
+		Context("when the sub-path already exists", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(dataDir, "some", "sub-path", "existing-file"), []byte("data"), 0644)).To(Succeed())
+			})
+
+			It("removes only what the stream extracted", func() {
+				Expect(filepath.Join(dataDir, "some", "sub-path", "existing-file")).To(BeARegularFile())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "first-file")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "second-file")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("StreamIn into a volume without room for it", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+
+			tarBuffer *bytes.Buffer
+			tarWriter *tar.Writer
+
+			streamErr error
+		)
+
+		writeFile := func(name string, size int) {
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(size)})).To(Succeed())
+			_, err := tarWriter.Write(make([]byte, size))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-no-room")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeLiveVolume.RoomReturns(1024, true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			tarBuffer = new(bytes.Buffer)
+			tarWriter = tar.NewWriter(tarBuffer)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			Expect(tarWriter.Close()).To(Succeed())
+
+			_, streamErr = repository.StreamIn(context.Background(), "some-handle", "some/sub-path", tarBuffer, volume.StreamInOptions{})
+		})
+
+		Context("when its files hold more than that", func() {
+			BeforeEach(func() {
+				writeFile("small-file", 4)
+				writeFile("big-file", 2048)
+			})
+
+			It("returns a StreamTooLargeError with what they held by then", func() {
+				Expect(streamErr).To(Equal(volume.StreamTooLargeError{Size: 2052, Room: 1024}))
+			})
+
+			It("removes the directories created for the stream", func() {
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+
+			Context("when the files it holds replace ones as large", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(dataDir, "some", "sub-path", "big-file"), make([]byte, 2048), 0644)).To(Succeed())
+				})
+
+				It("streams them in", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(filepath.Join(dataDir, "some", "sub-path", "small-file")).To(BeARegularFile())
+				})
+			})
+		})
+
+		Context("when it says up front that it holds more than that", func() {
+			BeforeEach(func() {
+				Expect(tarWriter.WriteHeader(&tar.Header{
+					Typeflag:   tar.TypeXGlobalHeader,
+					PAXRecords: map[string]string{baggageclaim.StreamSizeRecord: "4096"},
+				})).To(Succeed())
+
+				writeFile("small-file", 4)
+			})
+
+			It("refuses it before any of it is extracted", func() {
+				Expect(streamErr).To(Equal(volume.StreamTooLargeError{Size: 4096, Room: 1024}))
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+		})
+
+		Context("when the volume's room can't be found", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.RoomReturns(0, false, errors.New("disaster"))
+
+				writeFile("big-file", 2048)
+			})
+
+			It("streams it in all the same", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "big-file")).To(BeARegularFile())
+			})
+		})
+	})
+
+	Describe("StreamIn with concurrent extraction", func() {
+		var (
+			dataDir   string
+			tarBuffer *bytes.Buffer
+			tarWriter *tar.Writer
+
+			badStream bool
+			streamErr error
+		)
+
+		writeEntry := func(header *tar.Header, contents string) {
+			header.Size = int64(len(contents))
+			if header.ModTime.IsZero() {
+				header.ModTime = time.Unix(1000, 0)
+			}
+
+			Expect(tarWriter.WriteHeader(header)).To(Succeed())
+			_, err := tarWriter.Write([]byte(contents))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-concurrent-data")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLiveVolume := new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			streamInConcurrency = 4
+
+			tarBuffer = new(bytes.Buffer)
+			tarWriter = tar.NewWriter(tarBuffer)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			Expect(tarWriter.Close()).To(Succeed())
+
+			badStream, streamErr = repository.StreamIn(context.Background(), "some-handle", ".", tarBuffer, volume.StreamInOptions{})
+		})
+
+		Context("with many files in nested directories", func() {
+			BeforeEach(func() {
+				// files come before the entries of the directories they are in,
+				// which must be created for them and have their modes applied last
+				for i := 0; i < 50; i++ {
+					writeEntry(&tar.Header{Name: fmt.Sprintf("dir-%d/sub/file-%d", i%5, i), Mode: 0640}, fmt.Sprintf("contents-%d", i))
+				}
+
+				for i := 0; i < 5; i++ {
+					writeEntry(&tar.Header{Name: fmt.Sprintf("dir-%d/", i), Typeflag: tar.TypeDir, Mode: 0700, ModTime: time.Unix(2000, 0)}, "")
+				}
+			})
+
+			It("extracts every file with its contents, mode and time", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				for i := 0; i < 50; i++ {
+					path := filepath.Join(dataDir, fmt.Sprintf("dir-%d", i%5), "sub", fmt.Sprintf("file-%d", i))
+					Expect(ioutil.ReadFile(path)).To(Equal([]byte(fmt.Sprintf("contents-%d", i))))
+
+					info, err := os.Stat(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+					Expect(info.ModTime()).To(Equal(time.Unix(1000, 0)))
+				}
+			})
+
+			It("applies directory modes and times after writing into them", func() {
+				info, err := os.Stat(filepath.Join(dataDir, "dir-0"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+				Expect(info.ModTime()).To(Equal(time.Unix(2000, 0)))
+			})
+		})
+
+		Context("with links", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "some-contents")
+				writeEntry(&tar.Header{Name: "some-symlink", Typeflag: tar.TypeSymlink, Linkname: "some-file"}, "")
+				writeEntry(&tar.Header{Name: "some-hardlink", Typeflag: tar.TypeLink, Linkname: "some-file"}, "")
+			})
+
+			It("creates them once the files are written", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				target, err := os.Readlink(filepath.Join(dataDir, "some-symlink"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target).To(Equal("some-file"))
+
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some-hardlink"))).To(Equal([]byte("some-contents")))
+			})
+		})
+
+		Context("with a directory replaced by a later symlink", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0600}, "some-contents")
+				writeEntry(&tar.Header{Name: "a/", Typeflag: tar.TypeDir, Mode: 0777, ModTime: time.Unix(2000, 0)}, "")
+				writeEntry(&tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "some-file"}, "")
+			})
+
+			It("leaves the directory's mode and times off what the symlink points to", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				target, err := os.Readlink(filepath.Join(dataDir, "a"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target).To(Equal("some-file"))
+
+				info, err := os.Stat(filepath.Join(dataDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+				Expect(info.ModTime()).To(Equal(time.Unix(1000, 0)))
+			})
+		})
+
+		Context("with a hard link before its target", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-hardlink", Typeflag: tar.TypeLink, Linkname: "dir/some-file"}, "")
+				writeEntry(&tar.Header{Name: "dir/some-file", Mode: 0644}, "some-contents")
+			})
+
+			It("links it to the target", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				linkInfo, err := os.Stat(filepath.Join(dataDir, "some-hardlink"))
+				Expect(err).NotTo(HaveOccurred())
+				targetInfo, err := os.Stat(filepath.Join(dataDir, "dir", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.SameFile(linkInfo, targetInfo)).To(BeTrue())
+			})
+		})
+
+		Context("with a hard link to a hard link after it", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "first-link", Typeflag: tar.TypeLink, Linkname: "second-link"}, "")
+				writeEntry(&tar.Header{Name: "second-link", Typeflag: tar.TypeLink, Linkname: "some-file"}, "")
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "some-contents")
+			})
+
+			It("makes the links in the order they depend on each other", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "first-link"))).To(Equal([]byte("some-contents")))
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "second-link"))).To(Equal([]byte("some-contents")))
+			})
+		})
+
+		Context("with hard links to each other", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "first-link", Typeflag: tar.TypeLink, Linkname: "second-link"}, "")
+				writeEntry(&tar.Header{Name: "second-link", Typeflag: tar.TypeLink, Linkname: "first-link"}, "")
+			})
+
+			It("rejects the stream", func() {
+				Expect(streamErr).To(Equal(volume.ErrCyclicTarLinks))
+				Expect(badStream).To(BeTrue())
+			})
+		})
+
+		Context("with a hard link through a symlink out of the destination", func() {
+			var outsideDir string
+
+			BeforeEach(func() {
+				var err error
+				outsideDir, err = ioutil.TempDir("", "stream-in-outside")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ioutil.WriteFile(filepath.Join(outsideDir, "secret"), []byte("secret"), 0600)).To(Succeed())
+
+				writeEntry(&tar.Header{Name: "stolen", Typeflag: tar.TypeLink, Linkname: "escape/secret"}, "")
+				writeEntry(&tar.Header{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: outsideDir}, "")
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(outsideDir)).To(Succeed())
+			})
+
+			It("rejects the stream without linking to the file", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+				Expect(filepath.Join(dataDir, "stolen")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("with a symlink that a later entry is written through", func() {
+			var outsideDir string
+
+			BeforeEach(func() {
+				var err error
+				outsideDir, err = ioutil.TempDir("", "stream-in-outside")
+				Expect(err).NotTo(HaveOccurred())
+
+				writeEntry(&tar.Header{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: outsideDir}, "")
+				writeEntry(&tar.Header{Name: "escape/some-file", Mode: 0644}, "gotcha")
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(outsideDir)).To(Succeed())
+			})
+
+			It("does not write outside of the destination", func() {
+				Expect(filepath.Join(outsideDir, "some-file")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("with the same path more than once", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "first")
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "second")
+			})
+
+			It("keeps the last one", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some-file"))).To(Equal([]byte("second")))
+			})
+		})
+
+		Context("with an entry for the destination itself", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}, "")
+				writeEntry(&tar.Header{Name: "./some-file", Mode: 0644}, "some-contents")
+			})
+
+			It("extracts the stream", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(dataDir, "some-file"))).To(Equal([]byte("some-contents")))
+			})
+		})
+
+		Context("with an entry outside of the destination", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "../some-file", Mode: 0644}, "gotcha")
+			})
+
+			It("rejects the stream", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+				Expect(filepath.Join(filepath.Dir(dataDir), "some-file")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("StreamIn with paths outside of the volume", func() {
+		var (
+			dataDir    string
+			outsideDir string
+			subPath    string
+			tarBuffer  *bytes.Buffer
+			tarWriter  *tar.Writer
+
+			badStream bool
+			streamErr error
+		)
+
+		writeEntry := func(header *tar.Header, contents string) {
+			header.Size = int64(len(contents))
+			Expect(tarWriter.WriteHeader(header)).To(Succeed())
+			_, err := tarWriter.Write([]byte(contents))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-in-unsafe-data")
+			Expect(err).NotTo(HaveOccurred())
+
+			outsideDir, err = ioutil.TempDir("", "stream-in-unsafe-outside")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLiveVolume := new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			subPath = "some/sub-path"
+
+			tarBuffer = new(bytes.Buffer)
+			tarWriter = tar.NewWriter(tarBuffer)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+			Expect(os.RemoveAll(outsideDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			Expect(tarWriter.Close()).To(Succeed())
+
+			badStream, streamErr = repository.StreamIn(context.Background(), "some-handle", subPath, tarBuffer, volume.StreamInOptions{})
+		})
+
+		Context("when the sub-path climbs out of the volume", func() {
+			BeforeEach(func() {
+				subPath = "../" + filepath.Base(outsideDir)
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "gotcha")
+			})
+
+			It("returns ErrUnsafeSubPath without extracting anything", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeSubPath))
+				Expect(badStream).To(BeFalse())
+				Expect(filepath.Join(outsideDir, "some-file")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the sub-path leads out of the volume through a symlink", func() {
+			BeforeEach(func() {
+				Expect(os.Symlink(outsideDir, filepath.Join(dataDir, "escape"))).To(Succeed())
+
+				subPath = "escape/some-dir"
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "gotcha")
+			})
+
+			It("returns ErrUnsafeSubPath without extracting anything", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeSubPath))
+				Expect(filepath.Join(outsideDir, "some-dir")).NotTo(BeADirectory())
+			})
+		})
+
+		Context("with an entry that climbs out of the destination", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-file", Mode: 0644}, "fine")
+				writeEntry(&tar.Header{Name: "../../../" + filepath.Base(outsideDir) + "/some-file", Mode: 0644}, "gotcha")
+			})
+
+			It("rejects the whole stream as bad, removing what it extracted", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+
+				Expect(filepath.Join(outsideDir, "some-file")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(dataDir, "some")).NotTo(BeADirectory())
+			})
+		})
+
+		Context("with a hard link to a file outside of the destination", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-link", Typeflag: tar.TypeLink, Linkname: "../../escape"}, "")
+			})
+
+			It("rejects the stream as bad", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+			})
+		})
+
+		Context("with a symlink pointing outside of the volume", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-link", Typeflag: tar.TypeSymlink, Linkname: outsideDir}, "")
+			})
+
+			It("rejects the stream as bad, removing what it extracted", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+				Expect(filepath.Join(dataDir, "some", "sub-path", "some-link")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("with a relative symlink climbing out of the volume", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-dir/some-link", Typeflag: tar.TypeSymlink, Linkname: "../../../../escape"}, "")
+			})
+
+			It("rejects the stream as bad", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+			})
+		})
+
+		Context("with a symlink to elsewhere in the volume", func() {
+			BeforeEach(func() {
+				writeEntry(&tar.Header{Name: "some-link", Typeflag: tar.TypeSymlink, Linkname: "../../other-dir"}, "")
+			})
+
+			It("extracts it", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				target, err := os.Readlink(filepath.Join(dataDir, "some", "sub-path", "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target).To(Equal("../../other-dir"))
+			})
+		})
+
+		Context("with an entry written through a symlink already in the volume", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(dataDir, "some", "sub-path"), 0755)).To(Succeed())
+				Expect(os.Symlink(outsideDir, filepath.Join(dataDir, "some", "sub-path", "escape"))).To(Succeed())
+
+				writeEntry(&tar.Header{Name: "escape/some-file", Mode: 0644}, "gotcha")
+			})
+
+			It("rejects the stream as bad without writing through it", func() {
+				Expect(streamErr).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+				Expect(filepath.Join(outsideDir, "some-file")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("StreamOut", func() {
+		var (
+			dataDir        string
+			snapshotDir    string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+			streamOutOpts  volume.StreamOutOptions
+			streamOutPath  string
+			released       bool
+			ctx            context.Context
+
+			streamed  *bytes.Buffer
+			streamErr error
+		)
+
+		writeFile := func(dir string, contents string) {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "some-file"), []byte(contents), 0644)).To(Succeed())
+		}
+
+		streamedFile := func() string {
+			tarReader := tar.NewReader(streamed)
+			for {
+				header, err := tarReader.Next()
+				Expect(err).NotTo(HaveOccurred())
+
+				if filepath.Clean(header.Name) == "some-file" {
+					contents, err := ioutil.ReadAll(tarReader)
+					Expect(err).NotTo(HaveOccurred())
+					return string(contents)
+				}
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "stream-out-data")
+			Expect(err).NotTo(HaveOccurred())
+			writeFile(dataDir, "live")
+
+			snapshotDir, err = ioutil.TempDir("", "stream-out-snapshot")
+			Expect(err).NotTo(HaveOccurred())
+			writeFile(snapshotDir, "snapshot")
+
+			released = false
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.LoadPrivilegedReturns(true, nil)
+			fakeLiveVolume.SnapshotReturns(snapshotDir, func() error {
+				released = true
+				return nil
+			}, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			streamOutOpts = volume.StreamOutOptions{}
+			streamOutPath = ""
+			ctx = context.Background()
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+			Expect(os.RemoveAll(snapshotDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			streamed = new(bytes.Buffer)
+			streamErr = repository.StreamOut(ctx, "some-handle", streamOutPath, streamed, streamOutOpts)
+		})
+
+		Context("when the path is a regular file", func() {
+			var streamedAs volume.StreamOutFormat
+
+			BeforeEach(func() {
+				streamOutPath = "some-file"
+
+				streamedAs = ""
+				streamOutOpts.StreamedAs = &streamedAs
+			})
+
+			It("streams the file's bytes", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(streamed.String()).To(Equal("live"))
+				Expect(streamedAs).To(Equal(volume.StreamOutFile))
+			})
+
+			Context("when the stream is capped", func() {
+				var startedAt time.Time
+
+				BeforeEach(func() {
+					streamOutOpts.BytesPerSecond = 2
+					startedAt = fakeClock.Now()
+				})
+
+				Context("when the clock moves on", func() {
+					BeforeEach(func() {
+						go func() {
+							defer GinkgoRecover()
+
+							for i := 0; i < 4; i++ {
+								fakeClock.WaitForWatcherAndIncrement(500 * time.Millisecond)
+							}
+						}()
+					})
+
+					It("takes as long as the cap allows", func() {
+						Expect(streamErr).NotTo(HaveOccurred())
+						Expect(streamed.String()).To(Equal("live"))
+						Expect(fakeClock.Since(startedAt)).To(Equal(2 * time.Second))
+					})
+				})
+
+				Context("when the stream is canceled while waiting", func() {
+					BeforeEach(func() {
+						var cancel context.CancelFunc
+						ctx, cancel = context.WithCancel(ctx)
+
+						go func() {
+							defer GinkgoRecover()
+
+							Eventually(fakeClock.WatcherCount).Should(Equal(1))
+							cancel()
+						}()
+					})
+
+					It("gives up on it", func() {
+						Expect(streamErr).To(Equal(context.Canceled))
+						Expect(streamed.String()).To(Equal("l"))
+					})
+				})
+			})
+
+			Context("when a tar is requested", func() {
+				BeforeEach(func() {
+					streamOutOpts.Format = volume.StreamOutTar
+				})
+
+				It("streams a tar of it", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(streamedFile()).To(Equal("live"))
+					Expect(streamedAs).To(Equal(volume.StreamOutTar))
+				})
+			})
+
+			Context("when only files changed since a time are requested", func() {
+				BeforeEach(func() {
+					streamOutOpts.ModifiedSince = time.Unix(1, 0)
+				})
+
+				It("streams a tar of it", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(streamedAs).To(Equal(volume.StreamOutTar))
+				})
+
+				Context("when a file is requested", func() {
+					BeforeEach(func() {
+						streamOutOpts.Format = volume.StreamOutFile
+					})
+
+					It("returns ErrStreamOutOptionsNeedTar", func() {
+						Expect(streamErr).To(Equal(volume.ErrStreamOutOptionsNeedTar))
+						Expect(streamed.Len()).To(BeZero())
+					})
+				})
+			})
+
+			Context("when it is a symlink to a file outside of the volume", func() {
+				var outside string
+
+				BeforeEach(func() {
+					outside = filepath.Join(snapshotDir, "some-file")
+					Expect(os.Symlink(outside, filepath.Join(dataDir, "some-link"))).To(Succeed())
+
+					streamOutPath = "some-link"
+				})
+
+				It("streams a tar of the link", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(streamedAs).To(Equal(volume.StreamOutTar))
+
+					header, err := tar.NewReader(streamed).Next()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(header.Typeflag).To(Equal(byte(tar.TypeSymlink)))
+					Expect(header.Linkname).To(Equal(outside))
+				})
+
+				Context("when a file is requested", func() {
+					BeforeEach(func() {
+						streamOutOpts.Format = volume.StreamOutFile
+					})
+
+					It("returns ErrUnsafeSubPath", func() {
+						Expect(streamErr).To(Equal(volume.ErrUnsafeSubPath))
+					})
+				})
+			})
+		})
+
+		Context("when a file is requested at a directory", func() {
+			BeforeEach(func() {
+				streamOutOpts.Format = volume.StreamOutFile
+			})
+
+			It("returns ErrNotARegularFile", func() {
+				Expect(streamErr).To(Equal(volume.ErrNotARegularFile))
+			})
+		})
+
+		Context("when a file is requested at a path that does not exist", func() {
+			BeforeEach(func() {
+				streamOutPath = "bogus-file"
+				streamOutOpts.Format = volume.StreamOutFile
+			})
+
+			It("returns the not-exist error", func() {
+				Expect(os.IsNotExist(streamErr)).To(BeTrue())
+			})
+		})
+
+		Context("when the context is canceled", func() {
+			BeforeEach(func() {
+				canceled, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				ctx = canceled
+			})
+
+			It("returns the context's error without recording an access", func() {
+				Expect(streamErr).To(Equal(context.Canceled))
+				Expect(fakeLiveVolume.StoreLastAccessedCallCount()).To(BeZero())
+			})
+		})
+
+		It("streams the live data without snapshotting or locking", func() {
+			Expect(streamErr).NotTo(HaveOccurred())
+			Expect(streamedFile()).To(Equal("live"))
+			Expect(fakeLiveVolume.SnapshotCallCount()).To(BeZero())
+			Expect(fakeStreamInLocker.LockCallCount()).To(BeZero())
+		})
+
+		Context("when a consistent view is requested", func() {
+			BeforeEach(func() {
+				streamOutOpts.Consistent = true
+			})
+
+			It("streams the snapshot and releases it", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+				Expect(streamedFile()).To(Equal("snapshot"))
+				Expect(released).To(BeTrue())
+			})
+
+			It("keeps stream-ins out of the whole volume while snapshotting", func() {
+				Expect(fakeStreamInLocker.LockCallCount()).To(Equal(1))
+				handle, path := fakeStreamInLocker.LockArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(path).To(Equal(""))
+				Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+			})
+
+			Context("when the driver cannot snapshot", func() {
+				var streamedWhenUnlocked int
+
+				BeforeEach(func() {
+					fakeLiveVolume.SnapshotReturns("", nil, volume.ErrSnapshotsNotSupported)
+
+					streamedWhenUnlocked = 0
+					fakeStreamInLocker.UnlockStub = func(string, string) {
+						streamedWhenUnlocked = streamed.Len()
+					}
+				})
+
+				It("streams the live data", func() {
+					Expect(streamErr).NotTo(HaveOccurred())
+					Expect(streamedFile()).To(Equal("live"))
+				})
+
+				It("keeps stream-ins out until done", func() {
+					Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+					Expect(streamedWhenUnlocked).NotTo(BeZero())
+				})
+			})
+
+			Context("when snapshotting fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeLiveVolume.SnapshotReturns("", nil, disaster)
+				})
+
+				It("returns the error and lets stream-ins in again", func() {
+					Expect(streamErr).To(Equal(disaster))
+					Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when a downgrade is requested", func() {
+			var madeDevice bool
+
+			BeforeEach(func() {
+				streamOutOpts.Downgrade = &volume.DowngradeReport{}
+
+				Expect(os.Chmod(filepath.Join(dataDir, "some-file"), 0644|os.ModeSetuid|os.ModeSetgid)).To(Succeed())
+
+				madeDevice = exec.Command("mknod", filepath.Join(dataDir, "some-device"), "c", "1", "3").Run() == nil
+				if madeDevice {
+					Expect(os.Link(filepath.Join(dataDir, "some-device"), filepath.Join(dataDir, "device-link"))).To(Succeed())
+				}
+			})
+
+			streamedHeaders := func() map[string]*tar.Header {
+				headers := map[string]*tar.Header{}
+
+				tarReader := tar.NewReader(streamed)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						return headers
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					headers[filepath.Clean(header.Name)] = header
+				}
+			}
+
+			It("clears setuid and setgid bits and counts them", func() {
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				headers := streamedHeaders()
+				Expect(headers).To(HaveKey("some-file"))
+				Expect(headers["some-file"].FileInfo().Mode()).To(Equal(os.FileMode(0644)))
+
+				Expect(streamOutOpts.Downgrade.SetuidCleared).To(Equal(1))
+				Expect(streamOutOpts.Downgrade.SetgidCleared).To(Equal(1))
+			})
+
+			It("leaves out device nodes and links to them", func() {
+				if !madeDevice {
+					Skip("cannot create device nodes")
+				}
+
+				Expect(streamErr).NotTo(HaveOccurred())
+
+				headers := streamedHeaders()
+				Expect(headers).To(HaveKey("some-file"))
+				Expect(headers).NotTo(HaveKey("some-device"))
+				Expect(headers).NotTo(HaveKey("device-link"))
+
+				Expect(streamOutOpts.Downgrade.DevicesSkipped).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("SetPrivileged", func() {
+		var (
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+
+			setErr error
+		)
+
+		BeforeEach(func() {
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns("/some/data/path")
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+		})
+
+		JustBeforeEach(func() {
+			setErr = repository.SetPrivileged("some-handle", true)
+		})
+
+		It("namespaces the volume and stores the flag", func() {
+			Expect(setErr).NotTo(HaveOccurred())
+
+			Expect(fakePrivilegedNamespacer.NamespacePathCallCount()).To(Equal(1))
+			_, path := fakePrivilegedNamespacer.NamespacePathArgsForCall(0)
+			Expect(path).To(Equal("/some/data/path"))
+
+			Expect(fakeLiveVolume.StorePrivilegedCallCount()).To(Equal(1))
+			Expect(fakeLiveVolume.StorePrivilegedArgsForCall(0)).To(BeTrue())
+		})
+
+		Context("when the volume is frozen", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.LoadCommittedReturns(time.Now(), true, nil)
+			})
+
+			It("returns ErrVolumeIsFrozen", func() {
+				Expect(setErr).To(Equal(volume.ErrVolumeIsFrozen))
+			})
+
+			It("does not touch the volume", func() {
+				Expect(fakePrivilegedNamespacer.NamespacePathCallCount()).To(BeZero())
+				Expect(fakeLiveVolume.StorePrivilegedCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("SetSELinuxLabel", func() {
+		var (
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+			label          string
+
+			relabelErr error
+		)
+
+		BeforeEach(func() {
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns("/some/data")
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			label = "system_u:object_r:container_file_t:s0:c1,c2"
+		})
+
+		JustBeforeEach(func() {
+			relabelErr = repository.SetSELinuxLabel("some-handle", label)
+		})
+
+		It("waits for streams into any part of the volume", func() {
+			// without SELinux on the test machine the relabel itself is a no-op
+			Expect(relabelErr).NotTo(HaveOccurred())
+
+			Expect(fakeStreamInLocker.LockCallCount()).To(Equal(1))
+			handle, path := fakeStreamInLocker.LockArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(path).To(BeEmpty())
+			Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when the label is malformed", func() {
+			BeforeEach(func() {
+				label = "container_file_t"
+			})
+
+			It("returns ErrInvalidSELinuxLabel without looking up the volume", func() {
+				Expect(relabelErr).To(Equal(volume.ErrInvalidSELinuxLabel))
+				Expect(fakeFilesystem.LookupVolumeCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the volume is frozen", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.LoadCommittedReturns(time.Now(), true, nil)
+			})
+
+			It("returns ErrVolumeIsFrozen", func() {
+				Expect(relabelErr).To(Equal(volume.ErrVolumeIsFrozen))
+			})
+		})
+
+		Context("when the volume does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(relabelErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("CommitVolume", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+			freeze         bool
+
+			commitErr error
+		)
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "commit-data")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.WriteFile(filepath.Join(dataDir, "some-file"), []byte("data"), 0644)).To(Succeed())
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+
+			freeze = false
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			commitErr = repository.CommitVolume("some-handle", freeze)
+		})
+
+		It("stores the commit without freezing the volume", func() {
+			Expect(commitErr).NotTo(HaveOccurred())
+			Expect(fakeLiveVolume.StoreCommittedCallCount()).To(Equal(1))
+			Expect(fakeLiveVolume.StoreCommittedArgsForCall(0)).To(BeFalse())
+		})
+
+		It("waits for streams into any part of the volume", func() {
+			Expect(fakeStreamInLocker.LockCallCount()).To(Equal(1))
+			handle, path := fakeStreamInLocker.LockArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(path).To(BeEmpty())
+
+			Expect(fakeStreamInLocker.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("while waiting for streams to finish", func() {
+			var volumeLocksWhileWaiting int
+
+			BeforeEach(func() {
+				volumeLocksWhileWaiting = -1
+				fakeStreamInLocker.LockStub = func(string, string) {
+					volumeLocksWhileWaiting = fakeLocker.LockCallCount() - fakeLocker.UnlockCallCount()
+				}
+			})
+
+			It("does not hold the volume lock", func() {
+				Expect(volumeLocksWhileWaiting).To(BeZero())
+			})
+
+			It("takes the volume lock for the metadata update", func() {
+				Expect(fakeLocker.LockCallCount()).To(Equal(1))
+				Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-handle"))
+				Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when freezing", func() {
+			BeforeEach(func() {
+				freeze = true
+			})
+
+			It("stores the volume as frozen", func() {
+				Expect(fakeLiveVolume.StoreCommittedCallCount()).To(Equal(1))
+				Expect(fakeLiveVolume.StoreCommittedArgsForCall(0)).To(BeTrue())
+			})
+		})
+
+		Context("when the volume is already frozen", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.LoadCommittedReturns(time.Now(), true, nil)
+			})
+
+			It("keeps it frozen", func() {
+				Expect(fakeLiveVolume.StoreCommittedCallCount()).To(Equal(1))
+				Expect(fakeLiveVolume.StoreCommittedArgsForCall(0)).To(BeTrue())
+			})
+		})
+
+		Context("when storing the commit fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeLiveVolume.StoreCommittedReturns(time.Time{}, disaster)
+			})
+
+			It("returns the error", func() {
+				Expect(commitErr).To(Equal(disaster))
+			})
+		})
+
+		Context("when the volume does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(commitErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+
+			It("does not wait for streams", func() {
+				Expect(fakeStreamInLocker.LockCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("DestroyVolumes", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "destroy-volumes")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				fakeDestroyAuditLog,
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			for _, handle := range []string{"handle-a", "handle-b", "handle-c"} {
+				_, err = realRepo.CreateVolume(handle, volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("destroys each of the volumes", func() {
+			errs := realRepo.DestroyVolumes([]string{"handle-a", "handle-c"}, volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(errs).To(Equal(map[string]error{"handle-a": nil, "handle-c": nil}))
+
+			volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Handle).To(Equal("handle-b"))
+
+			Expect(fakeDestroyAuditLog.RecordCallCount()).To(Equal(2))
+		})
+
+		It("counts volumes that do not exist as destroyed", func() {
+			errs := realRepo.DestroyVolumes([]string{"handle-a", "bogus-handle", "handle-a"}, volume.DestroyOptions{})
+			Expect(errs).To(Equal(map[string]error{"handle-a": nil, "bogus-handle": nil}))
+		})
+
+		It("destroys a released base along with the last of its views", func() {
+			_, err := realRepo.CreateVolume("some-view", volume.ViewStrategy{BaseHandle: "handle-b"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			errs := realRepo.DestroyVolumes([]string{"handle-b", "some-view"}, volume.DestroyOptions{})
+			Expect(errs).To(Equal(map[string]error{"handle-b": nil, "some-view": nil}))
+
+			_, found, err := realRepo.GetVolume("handle-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("releases a base whose views are not being destroyed", func() {
+			_, err := realRepo.CreateVolume("some-view", volume.ViewStrategy{BaseHandle: "handle-b"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			errs := realRepo.DestroyVolumes([]string{"handle-b"}, volume.DestroyOptions{})
+			Expect(errs).To(Equal(map[string]error{"handle-b": nil}))
+
+			released, found, err := realRepo.GetVolume("handle-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(released.Frozen).To(BeTrue())
+
+			_, err = realRepo.StreamIn(context.Background(), "handle-b", ".", new(bytes.Buffer), volume.StreamInOptions{})
+			Expect(err).To(Equal(volume.ErrVolumeIsFrozen))
+		})
+
+		It("destroys a released base once its last view is destroyed on its own", func() {
+			_, err := realRepo.CreateVolume("some-view", volume.ViewStrategy{BaseHandle: "handle-b"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.DestroyVolume("handle-b", volume.DestroyOptions{})).To(Succeed())
+			Expect(realRepo.DestroyVolume("some-view", volume.DestroyOptions{})).To(Succeed())
+
+			_, found, err := realRepo.GetVolume("handle-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		Describe("DestroyVolumesWithProperties", func() {
+			BeforeEach(func() {
+				Expect(realRepo.SetProperty("handle-a", "build-id", "42", nil, "")).To(Succeed())
+				Expect(realRepo.SetProperty("handle-b", "build-id", "43", nil, "")).To(Succeed())
+				Expect(realRepo.SetProperty("handle-c", "build-id", "42", nil, "")).To(Succeed())
+			})
+
+			It("destroys the volumes that have the properties", func() {
+				errs, err := realRepo.DestroyVolumesWithProperties(volume.Properties{"build-id": "42"}, volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errs).To(Equal(map[string]error{"handle-a": nil, "handle-c": nil}))
+
+				volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].Handle).To(Equal("handle-b"))
+
+				Expect(fakeDestroyAuditLog.RecordCallCount()).To(Equal(2))
+			})
+
+			It("destroys nothing when no volume has them", func() {
+				errs, err := realRepo.DestroyVolumesWithProperties(volume.Properties{"build-id": "44"}, volume.DestroyOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errs).To(BeEmpty())
+
+				volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(HaveLen(3))
+			})
+
+			It("refuses to destroy every volume", func() {
+				_, err := realRepo.DestroyVolumesWithProperties(volume.Properties{}, volume.DestroyOptions{})
+				Expect(err).To(Equal(volume.ErrNoPropertiesToSelectBy))
+
+				volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(HaveLen(3))
+			})
+		})
+	})
+
+	Describe("TouchAccess", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "touch-access-volumes")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("records the access time", func() {
+			Expect(realRepo.TouchAccess("some-handle")).To(Succeed())
+
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.LastAccessedAt).NotTo(BeZero())
+		})
+
+		It("never exposes a partially written access time to concurrent listings", func() {
+			handles := []string{}
+			for i := 0; i < 100; i++ {
+				handle := fmt.Sprintf("touched-handle-%d", i)
+				_, err := realRepo.CreateVolume(handle, volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				handles = append(handles, handle)
+			}
+
+			done := make(chan struct{})
+
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+
+				// the first touch creates the file, which is when a
+				// non-atomic write could be observed empty
+				for _, handle := range handles {
+					Expect(realRepo.TouchAccess(handle)).To(Succeed())
+				}
+			}()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				volumes, corrupted, err := realRepo.ListVolumes(volume.Properties{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(corrupted).To(BeEmpty())
+				Expect(volumes).To(HaveLen(len(handles) + 1))
+			}
+		})
+
+		It("returns ErrVolumeDoesNotExist for a missing volume", func() {
+			Expect(realRepo.TouchAccess("bogus-handle")).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+	})
+
+	Describe("Materialize", func() {
+		var (
+			dataDir        string
+			fakeLiveVolume *volumefakes.FakeFilesystemLiveVolume
+
+			mounted        bool
+			materializeErr error
+		)
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "materialize-data")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLiveVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeLiveVolume.DataPathReturns(dataDir)
+			fakeLiveVolume.MaterializeReturns(true, nil)
+			fakeFilesystem.LookupVolumeReturns(fakeLiveVolume, true, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			mounted, materializeErr = repository.Materialize("some-handle")
+		})
+
+		It("mounts the volume and reads in its metadata under the volume lock", func() {
+			Expect(materializeErr).NotTo(HaveOccurred())
+			Expect(mounted).To(BeTrue())
+
+			Expect(fakeLiveVolume.MaterializeCallCount()).To(Equal(1))
+			Expect(fakeLiveVolume.LoadPropertiesCallCount()).To(Equal(1))
+
+			Expect(fakeLocker.LockCallCount()).To(Equal(1))
+			Expect(fakeLocker.LockArgsForCall(0)).To(Equal("some-handle"))
+			Expect(fakeLocker.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when the volume was already mounted", func() {
+			BeforeEach(func() {
+				fakeLiveVolume.MaterializeReturns(false, nil)
+			})
+
+			It("reports that there was nothing to do", func() {
+				Expect(materializeErr).NotTo(HaveOccurred())
+				Expect(mounted).To(BeFalse())
+			})
+		})
+
+		Context("when mounting fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeLiveVolume.MaterializeReturns(false, disaster)
+			})
+
+			It("returns the error", func() {
+				Expect(materializeErr).To(Equal(disaster))
+			})
+		})
+
+		Context("when the volume does not exist", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(materializeErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("VolumeParent", func() {
+		var (
+			parent    volume.Volume
+			found     bool
+			parentErr error
+		)
+
+		JustBeforeEach(func() {
+			parent, found, parentErr = repository.VolumeParent("some-volume")
+		})
+
+		Context("when the volume is found in the filesystem", func() {
+			var fakeVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeVolume.HandleReturns("some-volume")
+				fakeVolume.DataPathReturns("some-data-path")
+				fakeVolume.LoadPropertiesReturns(volume.Properties{"a": "a", "b": "b"}, nil)
+				fakeVolume.LoadTTLReturns(1, time.Unix(1, 0), nil)
+				fakeVolume.LoadPrivilegedReturns(false, nil)
+
+				fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+			})
+
+			Context("when the volume has a parent", func() {
+				var parentVolume *volumefakes.FakeFilesystemLiveVolume
+
+				BeforeEach(func() {
+					parentVolume = new(volumefakes.FakeFilesystemLiveVolume)
+					parentVolume.HandleReturns("parent-volume")
+					parentVolume.DataPathReturns("parent-data-path")
+					parentVolume.LoadPropertiesReturns(volume.Properties{"parent": "property"}, nil)
+					parentVolume.LoadTTLReturns(2, time.Unix(2, 0), nil)
+					parentVolume.LoadPrivilegedReturns(true, nil)
+
+					fakeVolume.ParentReturns(parentVolume, true, nil)
+				})
+
+				It("succeeds", func() {
+					Expect(parentErr).ToNot(HaveOccurred())
+				})
+
+				It("found the child volume by looking for the right handle", func() {
+					handle := fakeFilesystem.LookupVolumeArgsForCall(0)
+					Expect(handle).To(Equal("some-volume"))
+				})
+
+				It("returns the parent volume and true", func() {
+					Expect(found).To(BeTrue())
+					Expect(parent).To(Equal(volume.Volume{
+						Handle:         "parent-volume",
+						Path:           "parent-data-path",
+						Properties:     volume.Properties{"parent": "property"},
+						TTL:            2,
+						ExpiresAt:      time.Unix(2, 0),
+						PendingDestroy: true,
+						Privileged:     true,
+					}))
+				})
+
+				Context("when hydrating the parent volume fails", func() {
+					disaster := errors.New("nope")
+
+					BeforeEach(func() {
+						parentVolume.LoadPropertiesReturns(nil, disaster)
+					})
+
+					It("returns the special error", func() {
+						Expect(parentErr).To(Equal(volume.ErrVolumeIsCorrupted))
+					})
+				})
+			})
+
+			Context("when the volume does not have a parent", func() {
+				BeforeEach(func() {
+					fakeVolume.ParentReturns(nil, false, nil)
+				})
+
+				It("succeeds", func() {
+					Expect(parentErr).ToNot(HaveOccurred())
+				})
+
+				It("returns no volume and false", func() {
+					Expect(found).To(BeFalse())
+					Expect(parent).To(BeZero())
+				})
+			})
+
+			Context("when getting the parent volume fails", func() {
+				disaster := errors.New("nope")
+
+				BeforeEach(func() {
+					fakeVolume.ParentReturns(nil, false, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(parentErr).To(Equal(disaster))
+				})
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				Expect(parentErr).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+
+		Context("when looking up the volume on the filesystem fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, disaster)
+			})
+
+			It("returns the error", func() {
+				Expect(parentErr).To(Equal(disaster))
+			})
+		})
+	})
+
+	Describe("delta stream-ins", func() {
+		var (
+			fullDir  string
+			deltaDir string
+		)
+
+		type entry struct {
+			header   *tar.Header
+			contents string
+		}
+
+		tarOf := func(entries ...entry) *bytes.Buffer {
+			buffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(buffer)
+
+			for _, entry := range entries {
+				entry.header.Size = int64(len(entry.contents))
+				entry.header.ModTime = time.Unix(1000, 0)
+				Expect(tarWriter.WriteHeader(entry.header)).To(Succeed())
+
+				_, err := tarWriter.Write([]byte(entry.contents))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(tarWriter.Close()).To(Succeed())
+
+			return buffer
+		}
+
+		file := func(name string, contents string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}, contents: contents}
+		}
+
+		dir := func(name string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755}}
+		}
+
+		whiteout := func(name string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}}
+		}
+
+		manifest := func(handle string) []volume.ManifestEntry {
+			entries := []volume.ManifestEntry{}
+			err := repository.Manifest(handle, ".", func(entry volume.ManifestEntry) error {
+				entries = append(entries, entry)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			return entries
+		}
+
+		BeforeEach(func() {
+			var err error
+			fullDir, err = ioutil.TempDir("", "stream-in-full")
+			Expect(err).NotTo(HaveOccurred())
+
+			deltaDir, err = ioutil.TempDir("", "stream-in-delta")
+			Expect(err).NotTo(HaveOccurred())
+
+			fullVolume := new(volumefakes.FakeFilesystemLiveVolume)
+			fullVolume.DataPathReturns(fullDir)
+			fullVolume.LoadPrivilegedReturns(true, nil)
+
+			deltaVolume := new(volumefakes.FakeFilesystemLiveVolume)
+			deltaVolume.DataPathReturns(deltaDir)
+			deltaVolume.LoadPrivilegedReturns(true, nil)
+
+			fakeFilesystem.LookupVolumeStub = func(handle string) (volume.FilesystemLiveVolume, bool, error) {
+				if handle == "full" {
+					return fullVolume, true, nil
+				}
+
+				return deltaVolume, true, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			_, err := repository.StreamIn(context.Background(), "delta", ".", tarOf(
+				dir("dir/"),
+				file("dir/changed", "old"),
+				file("dir/kept", "same"),
+				dir("removed-dir/"),
+				file("removed-dir/file", "gone"),
+				file("removed-file", "gone"),
+			), volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(fullDir)).To(Succeed())
+			Expect(os.RemoveAll(deltaDir)).To(Succeed())
+		})
+
+		It("lists the volume's contents in lexical order", func() {
+			entries := manifest("delta")
+
+			paths := []string{}
+			for _, entry := range entries {
+				paths = append(paths, entry.Path)
+			}
+
+			Expect(paths).To(Equal([]string{"dir", "dir/changed", "dir/kept", "removed-dir", "removed-dir/file", "removed-file"}))
+
+			Expect(entries[0].Type).To(Equal(volume.ManifestDirectory))
+			Expect(entries[0].Mode).To(Equal(os.FileMode(0755)))
+
+			sum := sha256.Sum256([]byte("same"))
+			Expect(entries[2]).To(Equal(volume.ManifestEntry{
+				Path:    "dir/kept",
+				Type:    volume.ManifestFile,
+				Mode:    0644,
+				Size:    4,
+				ModTime: time.Unix(1000, 0),
+				SHA256:  hex.EncodeToString(sum[:]),
+			}))
+		})
+
+		It("lists the same entries as files, without their digests", func() {
+			files, err := repository.ListFiles("delta", "dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(files).To(Equal([]volume.FileEntry{
+				{Path: "changed", Type: volume.ManifestFile, Mode: 0644, Size: 3, ModTime: time.Unix(1000, 0)},
+				{Path: "kept", Type: volume.ManifestFile, Mode: 0644, Size: 4, ModTime: time.Unix(1000, 0)},
+			}))
+		})
+
+		It("produces the same tree as a full stream", func() {
+			_, err := repository.StreamIn(context.Background(), "full", ".", tarOf(
+				dir("dir/"),
+				file("dir/added", "added"),
+				file("dir/changed", "new"),
+				file("dir/kept", "same"),
+				entry{header: &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/kept"}},
+			), volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repository.StreamIn(context.Background(), "delta", ".", tarOf(
+				whiteout(".wh.removed-dir"),
+				whiteout(".wh.removed-file"),
+				file("dir/added", "added"),
+				file("dir/changed", "new"),
+				entry{header: &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/kept"}},
+			), volume.StreamInOptions{Delta: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(deltaDir, "removed-dir")).NotTo(BeADirectory())
+			Expect(filepath.Join(deltaDir, "removed-file")).NotTo(BeAnExistingFile())
+
+			fullDigest, err := repository.VolumeDigest("full")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repository.VolumeDigest("delta")).To(Equal(fullDigest))
+		})
+
+		It("extracts whiteouts as they are when not streaming a delta", func() {
+			_, err := repository.StreamIn(context.Background(), "delta", ".", tarOf(
+				whiteout(".wh.removed-file"),
+			), volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(deltaDir, ".wh.removed-file")).To(BeAnExistingFile())
+			Expect(filepath.Join(deltaDir, "removed-file")).To(BeAnExistingFile())
+		})
+
+		It("rejects a whiteout after another entry", func() {
+			badStream, err := repository.StreamIn(context.Background(), "delta", ".", tarOf(
+				file("dir/added", "added"),
+				whiteout(".wh.removed-file"),
+			), volume.StreamInOptions{Delta: true})
+			Expect(err).To(Equal(volume.ErrInvalidWhiteout))
+			Expect(badStream).To(BeTrue())
+
+			Expect(filepath.Join(deltaDir, "removed-file")).To(BeAnExistingFile())
+		})
+
+		It("rejects a whiteout of the directory it is in", func() {
+			badStream, err := repository.StreamIn(context.Background(), "delta", ".", tarOf(
+				whiteout("dir/.wh.."),
+			), volume.StreamInOptions{Delta: true})
+			Expect(err).To(Equal(volume.ErrInvalidWhiteout))
+			Expect(badStream).To(BeTrue())
+
+			Expect(filepath.Join(deltaDir, "dir")).To(BeADirectory())
+		})
+	})
+
+	Describe("layer stream-ins", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+			base       volume.Volume
+		)
+
+		type entry struct {
+			header   *tar.Header
+			contents string
+		}
+
+		layerOf := func(entries ...entry) *bytes.Buffer {
+			buffer := new(bytes.Buffer)
+			gzipWriter := gzip.NewWriter(buffer)
+			tarWriter := tar.NewWriter(gzipWriter)
+
+			for _, entry := range entries {
+				entry.header.Size = int64(len(entry.contents))
+				Expect(tarWriter.WriteHeader(entry.header)).To(Succeed())
+
+				_, err := tarWriter.Write([]byte(entry.contents))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(tarWriter.Close()).To(Succeed())
+			Expect(gzipWriter.Close()).To(Succeed())
+
+			return buffer
+		}
+
+		file := func(name string, contents string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}, contents: contents}
+		}
+
+		dir := func(name string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755}}
+		}
+
+		whiteout := func(name string) entry {
+			return entry{header: &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}}
+		}
+
+		tree := func(root string) map[string]string {
+			found := map[string]string{}
+			err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if err != nil || path == root {
+					return err
+				}
+
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+
+				switch {
+				case info.Mode()&os.ModeSymlink != 0:
+					target, err := os.Readlink(path)
+					if err != nil {
+						return err
+					}
+
+					found[rel] = "-> " + target
+				case info.IsDir():
+					found[rel] = "/"
+				default:
+					contents, err := ioutil.ReadFile(path)
+					if err != nil {
+						return err
+					}
+
+					found[rel] = string(contents)
+				}
+
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			return found
+		}
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-layers")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			base, err = realRepo.CreateVolume("base-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+				dir("etc/"),
+				file("etc/a", "a"),
+				file("etc/b", "b"),
+				dir("opt/"),
+				dir("opt/dir/"),
+				file("opt/dir/x", "x"),
+				file("opt/dir/y", "y"),
+				entry{header: &tar.Header{Name: "opt/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/a"}},
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("gives the same tree as the layers stacked by a container runtime, leaving the layers below alone", func() {
+			child, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "base-handle"}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			badStream, err := realRepo.StreamIn(context.Background(), "child-handle", ".", layerOf(
+				file("etc/c", "c"),
+				whiteout("etc/.wh.a"),
+				whiteout("etc/.wh.c"),
+				dir("etc/b/"),
+				file("etc/b/inner", "inner"),
+				dir("opt/dir/"),
+				file("opt/dir/z", "z"),
+				whiteout("opt/dir/.wh..wh..opq"),
+				whiteout(".wh..wh.plnk"),
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(badStream).To(BeFalse())
+
+			Expect(tree(child.Path)).To(Equal(map[string]string{
+				"etc":         "/",
+				"etc/b":       "/",
+				"etc/b/inner": "inner",
+				"etc/c":       "c",
+				"opt":         "/",
+				"opt/dir":     "/",
+				"opt/dir/z":   "z",
+				"opt/link":    "-> /etc/a",
+			}))
+
+			Expect(tree(base.Path)).To(Equal(map[string]string{
+				"etc":       "/",
+				"etc/a":     "a",
+				"etc/b":     "b",
+				"opt":       "/",
+				"opt/dir":   "/",
+				"opt/dir/x": "x",
+				"opt/dir/y": "y",
+				"opt/link":  "-> /etc/a",
+			}))
+		})
+
+		It("rejects a whiteout with contents as a bad stream", func() {
+			badStream, err := realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+				file("etc/.wh.a", "not empty"),
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).To(Equal(volume.ErrInvalidLayerWhiteout))
+			Expect(badStream).To(BeTrue())
+		})
+
+		It("rejects a whiteout of the directory it is in as a bad stream", func() {
+			badStream, err := realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+				whiteout("etc/.wh.."),
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).To(Equal(volume.ErrInvalidLayerWhiteout))
+			Expect(badStream).To(BeTrue())
+
+			Expect(tree(base.Path)).To(HaveKey("etc/a"))
+		})
+
+		It("rejects a layer that is not one as a bad stream", func() {
+			badStream, err := realRepo.StreamIn(context.Background(), "base-handle", ".", bytes.NewBufferString("not a layer"), volume.StreamInOptions{Layer: true})
+			Expect(err).To(HaveOccurred())
+			Expect(badStream).To(BeTrue())
+		})
+
+		It("refuses to write or apply a whiteout through a symlink of the layer", func() {
+			outside, err := ioutil.TempDir("", "volume-layers-outside")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(outside)
+
+			Expect(ioutil.WriteFile(filepath.Join(outside, "kept"), []byte("kept"), 0644)).To(Succeed())
+
+			for _, name := range []string{"escape/.wh.kept", "escape/written"} {
+				badStream, err := realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+					entry{header: &tar.Header{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: outside}},
+					file(name, ""),
+				), volume.StreamInOptions{Layer: true})
+				Expect(err).To(Equal(volume.ErrUnsafeTarEntry))
+				Expect(badStream).To(BeTrue())
+			}
+
+			Expect(filepath.Join(outside, "kept")).To(BeAnExistingFile())
+			Expect(filepath.Join(outside, "written")).NotTo(BeAnExistingFile())
+		})
+
+		It("refuses to apply a whiteout through a symlink a layer below put out of the volume", func() {
+			outside, err := ioutil.TempDir("", "volume-layers-outside")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(outside)
+
+			Expect(ioutil.WriteFile(filepath.Join(outside, "kept"), []byte("kept"), 0644)).To(Succeed())
+
+			_, err = realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+				entry{header: &tar.Header{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: outside}},
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			badStream, err := realRepo.StreamIn(context.Background(), "base-handle", ".", layerOf(
+				whiteout("escape/.wh.kept"),
+			), volume.StreamInOptions{Layer: true})
+			Expect(err).To(Equal(volume.ErrUnsafeTarEntry))
+			Expect(badStream).To(BeTrue())
+
+			Expect(filepath.Join(outside, "kept")).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("VolumeDigest", func() {
+		var (
+			dataDir    string
+			fakeVolume *volumefakes.FakeFilesystemLiveVolume
+		)
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "volume-digest")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeVolume = new(volumefakes.FakeFilesystemLiveVolume)
+			fakeVolume.DataPathReturns(dataDir)
+			fakeFilesystem.LookupVolumeReturns(fakeVolume, true, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+		})
+
+		digest := func() string {
+			digest, err := repository.VolumeDigest("some-volume")
+			Expect(err).NotTo(HaveOccurred())
+			return digest
+		}
+
+		writeTree := func(reversed bool) {
+			entries := []func(){
+				func() { Expect(os.MkdirAll(filepath.Join(dataDir, "dir"), 0755)).To(Succeed()) },
+				func() {
+					Expect(os.MkdirAll(filepath.Join(dataDir, "dir"), 0755)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(dataDir, "dir", "file"), []byte("contents"), 0644)).To(Succeed())
+				},
+				func() {
+					Expect(ioutil.WriteFile(filepath.Join(dataDir, "other-file"), []byte("other"), 0600)).To(Succeed())
+				},
+				func() { Expect(os.Symlink("dir/file", filepath.Join(dataDir, "link"))).To(Succeed()) },
+			}
+
+			if reversed {
+				for i := len(entries) - 1; i >= 0; i-- {
+					entries[i]()
+				}
+			} else {
+				for _, entry := range entries {
+					entry()
+				}
+			}
+		}
+
+		It("is the same for the same tree, however it was written", func() {
+			writeTree(false)
+			first := digest()
+			Expect(first).To(HavePrefix("sha256:"))
+
+			Expect(os.RemoveAll(dataDir)).To(Succeed())
+			Expect(os.MkdirAll(dataDir, 0755)).To(Succeed())
+
+			writeTree(true)
+			Expect(os.Chtimes(filepath.Join(dataDir, "other-file"), time.Unix(1, 0), time.Unix(1, 0))).To(Succeed())
+
+			Expect(digest()).To(Equal(first))
+		})
+
+		It("changes with the contents of a file", func() {
+			writeTree(false)
+			first := digest()
+
+			Expect(ioutil.WriteFile(filepath.Join(dataDir, "other-file"), []byte("changed"), 0600)).To(Succeed())
+
+			Expect(digest()).NotTo(Equal(first))
+		})
+
+		It("changes with the mode of a file", func() {
+			writeTree(false)
+			first := digest()
+
+			Expect(os.Chmod(filepath.Join(dataDir, "other-file"), 0644)).To(Succeed())
+
+			Expect(digest()).NotTo(Equal(first))
+		})
+
+		It("changes with the target of a symlink", func() {
+			writeTree(false)
+			first := digest()
+
+			Expect(os.Remove(filepath.Join(dataDir, "link"))).To(Succeed())
+			Expect(os.Symlink("other-file", filepath.Join(dataDir, "link"))).To(Succeed())
+
+			Expect(digest()).NotTo(Equal(first))
+		})
+
+		It("changes when a file is renamed", func() {
+			writeTree(false)
+			first := digest()
+
+			Expect(os.Rename(filepath.Join(dataDir, "other-file"), filepath.Join(dataDir, "renamed-file"))).To(Succeed())
+
+			Expect(digest()).NotTo(Equal(first))
+		})
+
+		It("does not record the digest", func() {
+			digest()
+			Expect(fakeVolume.StoreDigestCallCount()).To(BeZero())
+		})
+
+		Context("when the volume is a view", func() {
+			var viewDir string
+
+			BeforeEach(func() {
+				writeTree(false)
+
+				var err error
+				viewDir, err = ioutil.TempDir("", "volume-digest-view")
+				Expect(err).NotTo(HaveOccurred())
+
+				viewData := filepath.Join(viewDir, "volume")
+				Expect(os.Symlink(dataDir, viewData)).To(Succeed())
+
+				viewedVolume := new(volumefakes.FakeFilesystemLiveVolume)
+				viewedVolume.DataPathReturns(dataDir)
+				fakeFilesystem.LookupVolumeReturnsOnCall(0, viewedVolume, true, nil)
+
+				fakeVolume.DataPathReturns(viewData)
+				fakeVolume.IsViewReturns(true, nil)
+				fakeFilesystem.LookupVolumeReturnsOnCall(1, fakeVolume, true, nil)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(viewDir)).To(Succeed())
+			})
+
+			It("digests what it views", func() {
+				viewed := digest()
+				Expect(viewed).NotTo(Equal("sha256:" + hex.EncodeToString(sha256.New().Sum(nil))))
+				Expect(digest()).To(Equal(viewed))
+			})
+		})
+
+		Context("when the volume is not found on the filesystem", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrVolumeDoesNotExist", func() {
+				_, err := repository.VolumeDigest("some-volume")
+				Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("read-only volumes", func() {
+		var (
+			volumesDir     string
+			readOnlyDriver *readOnlyNaiveDriver
+			realRepo       volume.Repository
+
+			createdVolume volume.Volume
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "read-only-volumes")
+			Expect(err).NotTo(HaveOccurred())
+
+			readOnlyDriver = &readOnlyNaiveDriver{}
+
+			filesystem, err := volume.NewFilesystem(readOnlyDriver, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			createdVolume, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{ReadOnly: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("has the driver make the volume read-only", func() {
+			Expect(readOnlyDriver.readOnly).To(HaveLen(1))
+			Expect(createdVolume.ReadOnly).To(BeTrue())
+		})
+
+		It("reports the volume as read-only and frozen", func() {
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.ReadOnly).To(BeTrue())
+			Expect(vol.Frozen).To(BeTrue())
+		})
+
+		It("refuses stream-ins and privilege changes", func() {
+			_, err := realRepo.StreamIn(context.Background(), "some-handle", ".", new(bytes.Buffer), volume.StreamInOptions{})
+			Expect(err).To(Equal(volume.ErrVolumeIsFrozen))
+
+			Expect(realRepo.SetPrivileged("some-handle", true)).To(Equal(volume.ErrVolumeIsFrozen))
+		})
+
+		It("still allows its properties to be set", func() {
+			Expect(realRepo.SetProperty("some-handle", "some", "property", nil, "")).To(Succeed())
+		})
+
+		It("creates COW volumes from it writable", func() {
+			child, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(child.ReadOnly).To(BeFalse())
+			Expect(child.Frozen).To(BeFalse())
+
+			Expect(readOnlyDriver.readOnly).To(HaveLen(1))
+
+			vol, found, err := realRepo.GetVolume("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.ReadOnly).To(BeFalse())
+		})
+
+		It("creates COW volumes from it read-only when they ask to be", func() {
+			child, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{ReadOnly: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(child.ReadOnly).To(BeTrue())
+
+			Expect(readOnlyDriver.readOnly).To(HaveLen(2))
+		})
+
+		Context("when the driver cannot make volumes read-only", func() {
+			It("refuses to create them", func() {
+				filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				naiveRepo := volume.NewRepository(
+					logger,
+					fakeClock,
+					filesystem,
+					volume.NewLockManager(),
+					volume.NewPathLockManager(),
+					fakePrivilegedNamespacer,
+					fakeUnprivilegedNamespacer,
+					nil,
+					time.Minute,
+					volume.NoopDestroyAuditLog{},
+					0,
+					1,
+					nil,
+					volume.NoopEventSink{},
+					volume.PropertyLimits{},
+					0,
+					volume.StreamLimits{},
+					0,
+				)
+
+				_, err = naiveRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{ReadOnly: true})
+				Expect(err).To(Equal(volume.ErrReadOnlyNotSupported))
+
+				_, found, err := naiveRepo.GetVolume("other-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("volumes with mount options", func() {
+		var (
+			volumesDir         string
+			mountOptionsDriver *mountOptionsNaiveDriver
+			realRepo           volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "mount-options")
+			Expect(err).NotTo(HaveOccurred())
+
+			mountOptionsDriver = &mountOptionsNaiveDriver{options: map[string][]string{}}
+
+			filesystem, err := volume.NewFilesystem(mountOptionsDriver, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("has the driver apply them once each, and reports them", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{MountOptions: []string{"noatime", "compress=zstd", "noatime"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdVolume.MountOptions).To(Equal([]string{"noatime", "compress=zstd"}))
+			Expect(mountOptionsDriver.options).To(Equal(map[string][]string{
+				"some-handle": {"noatime", "compress=zstd"},
+			}))
+
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.MountOptions).To(Equal([]string{"noatime", "compress=zstd"}))
+		})
+
+		It("gives a volume created without them none", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdVolume.MountOptions).To(BeEmpty())
+			Expect(mountOptionsDriver.options).To(BeEmpty())
+		})
+
+		It("refuses options the driver does not allow, or that set something twice, without creating the volume", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{MountOptions: []string{"bogus", "compress=zstd", "compress=lzo"}})
+			Expect(err).To(BeAssignableToTypeOf(volume.MountOptionsError{}))
+			Expect(err.(volume.MountOptionsError).Fields).To(Equal([]baggageclaim.FieldError{
+				{Field: "mount_options", Code: baggageclaim.FieldErrorNotAllowed, Message: `"bogus" is not one of compress=lzo, compress=zstd, noatime`},
+				{Field: "mount_options", Code: baggageclaim.FieldErrorConflict, Message: `"compress=lzo" conflicts with "compress=zstd"`},
+			}))
+
+			_, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(mountOptionsDriver.options).To(BeEmpty())
+		})
+
+		It("refuses them for views, which are mounted as their base is", func() {
+			_, err := realRepo.CreateVolume("base-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "base-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{MountOptions: []string{"noatime"}})
+			Expect(err).To(BeAssignableToTypeOf(volume.MountOptionsError{}))
+			Expect(err.(volume.MountOptionsError).Fields[0].Code).To(Equal(baggageclaim.FieldErrorConflict))
+		})
+
+		It("applies them again once the volume has been mounted afresh", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{MountOptions: []string{"noatime"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			delete(mountOptionsDriver.options, "some-handle")
+			mountOptionsDriver.unmounted = true
+
+			mounted, err := realRepo.Materialize("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mounted).To(BeTrue())
+			Expect(mountOptionsDriver.options).To(Equal(map[string][]string{
+				"some-handle": {"noatime"},
+			}))
+		})
+
+		Context("when the driver does not support them", func() {
+			BeforeEach(func() {
+				filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				realRepo = volume.NewRepository(
+					logger,
+					fakeClock,
+					filesystem,
+					volume.NewLockManager(),
+					volume.NewPathLockManager(),
+					fakePrivilegedNamespacer,
+					fakeUnprivilegedNamespacer,
+					nil,
+					time.Minute,
+					volume.NoopDestroyAuditLog{},
+					0,
+					1,
+					nil,
+					volume.NoopEventSink{},
+					volume.PropertyLimits{},
+					0,
+					volume.StreamLimits{},
+					0,
+				)
+			})
+
+			It("refuses them", func() {
+				_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{MountOptions: []string{"noatime"}})
+				Expect(err).To(Equal(volume.MountOptionsError{Fields: []baggageclaim.FieldError{
+					{Field: "mount_options", Code: baggageclaim.FieldErrorNotAllowed, Message: "the naive driver does not support mount options"},
+				}}))
+			})
+		})
+	})
+
+	Describe("TotalUsage", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "total-usage")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			for handle, team := range map[string]string{"handle-a": "main", "handle-b": "main", "handle-c": "other"} {
+				vol, err := realRepo.CreateVolume(handle, volume.EmptyStrategy{}, volume.Properties{"team": team}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(vol.Path, "some-file"), bytes.Repeat([]byte("x"), 64*1024), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err = realRepo.CreateVolume("handle-d", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		sizeOf := func(handles ...string) int64 {
+			var size int64
+			for _, handle := range handles {
+				stats, found, err := realRepo.GetVolumeStats(handle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				size += stats.SizeInBytes
+			}
+
+			return size
+		}
+
+		It("sums up the sizes of the volumes", func() {
+			usage, err := realRepo.TotalUsage("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.Volumes).To(Equal(4))
+			Expect(usage.UsedBytes).To(Equal(sizeOf("handle-a", "handle-b", "handle-c", "handle-d")))
+			Expect(usage.UsedBytes).To(BeNumerically(">=", 3*64*1024))
+			Expect(usage.FreeBytes).NotTo(BeZero())
+			Expect(usage.Groups).To(BeNil())
+			Expect(usage.Ungrouped).To(BeNil())
+		})
+
+		It("groups them by the value of a property", func() {
+			usage, err := realRepo.TotalUsage("team")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.Volumes).To(Equal(4))
+			Expect(usage.GroupedBy).To(Equal("team"))
+			Expect(usage.Groups).To(Equal(map[string]volume.UsageGroup{
+				"main":  {UsedBytes: sizeOf("handle-a", "handle-b"), Volumes: 2},
+				"other": {UsedBytes: sizeOf("handle-c"), Volumes: 1},
+			}))
+			Expect(usage.Ungrouped).To(Equal(&volume.UsageGroup{UsedBytes: sizeOf("handle-d"), Volumes: 1}))
+		})
+
+		Context("when a volume cannot be sized", func() {
+			It("leaves it out and lists it as failed", func() {
+				sized := new(volumefakes.FakeFilesystemLiveVolume)
+				sized.HandleReturns("sized")
+				sized.SizeReturns(100, nil)
+
+				broken := new(volumefakes.FakeFilesystemLiveVolume)
+				broken.HandleReturns("broken")
+				broken.SizeReturns(0, errors.New("qgroup show failed"))
+
+				gone := new(volumefakes.FakeFilesystemLiveVolume)
+				gone.HandleReturns("gone")
+				gone.SizeReturns(0, volume.ErrVolumeDoesNotExist)
+
+				fakeFilesystem.ListVolumesReturns([]volume.FilesystemLiveVolume{sized, broken, gone}, nil)
+				fakeFilesystem.FreeBytesReturns(200, nil)
+
+				usage, err := repository.TotalUsage("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(usage).To(Equal(volume.Usage{
+					UsedBytes: 100,
+					FreeBytes: 200,
+					Volumes:   1,
+					Failed:    []string{"broken"},
+				}))
+			})
+		})
+	})
+
+	Describe("the driver and filesystem type of volumes", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-backing")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("reports what the volume was created on", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdVolume.Driver).To(Equal("naive"))
+			Expect(createdVolume.FilesystemType).NotTo(BeEmpty())
+
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.Driver).To(Equal("naive"))
+			Expect(vol.FilesystemType).To(Equal(createdVolume.FilesystemType))
+
+			volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Driver).To(Equal("naive"))
+			Expect(volumes[0].FilesystemType).To(Equal(createdVolume.FilesystemType))
+
+			stats, found, err := realRepo.GetVolumeStats("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(stats.Driver).To(Equal("naive"))
+			Expect(stats.FilesystemType).To(Equal(createdVolume.FilesystemType))
+		})
+
+		Context("when the volume was created before they were recorded", func() {
+			It("reports the current driver and filesystem type", func() {
+				createdVolume, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.Remove(filepath.Join(volumesDir, "live", "some-handle", "backing.json"))).To(Succeed())
+
+				vol, found, err := realRepo.GetVolume("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(vol.Driver).To(Equal("naive"))
+				Expect(vol.FilesystemType).To(Equal(createdVolume.FilesystemType))
+			})
+		})
+	})
+
+	Describe("destroying volumes that are being streamed", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+
+			streamWriter *io.PipeWriter
+			streamDone   chan struct{}
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-streaming")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var streamReader *io.PipeReader
+			streamReader, streamWriter = io.Pipe()
+
+			streamDone = make(chan struct{})
+			go func() {
+				defer close(streamDone)
+
+				// the stream is not a tar, so fails once it is closed
+				realRepo.StreamIn(context.Background(), "some-handle", ".", streamReader, volume.StreamInOptions{})
+			}()
+
+			// the stream is counted before anything is read from it, so once
+			// this write returns it is
+			_, err = streamWriter.Write([]byte{0})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			streamWriter.Close()
+			Eventually(streamDone).Should(BeClosed())
+
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("spares them when asked to", func() {
+			err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonTTLExpiry, SpareStreamed: true})
+			Expect(err).To(Equal(volume.ErrVolumeIsStreaming))
+
+			_, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("destroys them otherwise", func() {
+			err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("spares them no longer once the stream has finished", func() {
+			Expect(streamWriter.Close()).To(Succeed())
+			Eventually(streamDone).Should(BeClosed())
+
+			err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonTTLExpiry, SpareStreamed: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("stream limits", func() {
+		var (
+			volumesDir   string
+			queueTimeout time.Duration
+			realRepo     volume.Repository
+
+			streamWriter *io.PipeWriter
+			streamDone   chan struct{}
+		)
+
+		BeforeEach(func() {
+			queueTimeout = 0
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-stream-limits")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{MaxStreamsIn: 1, MaxStreamsOut: 1, QueueTimeout: queueTimeout},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var streamReader *io.PipeReader
+			streamReader, streamWriter = io.Pipe()
+
+			streamDone = make(chan struct{})
+			go func() {
+				defer close(streamDone)
+
+				// the stream is not a tar, so fails once it is closed
+				realRepo.StreamIn(context.Background(), "some-handle", ".", streamReader, volume.StreamInOptions{})
+			}()
+
+			// the slot is taken before anything is read from the stream, so
+			// once this write returns it is
+			_, err = streamWriter.Write([]byte{0})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			streamWriter.Close()
+			Eventually(streamDone).Should(BeClosed())
+
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		streamIn := func(ctx context.Context) error {
+			buffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(buffer)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Typeflag: tar.TypeReg, Mode: 0644, Size: 4})).To(Succeed())
+			_, err := tarWriter.Write([]byte("some"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
+
+			_, err = realRepo.StreamIn(ctx, "some-handle", "some-dir", buffer, volume.StreamInOptions{})
+			return err
+		}
+
+		It("refuses streams in beyond the cap", func() {
+			Expect(streamIn(context.Background())).To(Equal(volume.ErrTooManyStreams))
+		})
+
+		It("streams out apart from the streams in", func() {
+			Expect(realRepo.StreamOut(context.Background(), "some-handle", ".", ioutil.Discard, volume.StreamOutOptions{})).To(Succeed())
+		})
+
+		It("streams in again once the stream has finished", func() {
+			Expect(streamWriter.Close()).To(Succeed())
+			Eventually(streamDone).Should(BeClosed())
+
+			Expect(streamIn(context.Background())).To(Succeed())
+		})
+
+		Context("with a queue timeout", func() {
+			BeforeEach(func() {
+				queueTimeout = time.Minute
+			})
+
+			It("waits for the stream to finish", func() {
+				queued := make(chan error, 1)
+				go func() {
+					queued <- streamIn(context.Background())
+				}()
+
+				Consistently(queued).ShouldNot(Receive())
+
+				Expect(streamWriter.Close()).To(Succeed())
+				Eventually(queued).Should(Receive(BeNil()))
+			})
+
+			It("refuses the stream once the timeout passes", func() {
+				queued := make(chan error, 1)
+				go func() {
+					queued <- streamIn(context.Background())
+				}()
+
+				fakeClock.WaitForWatcherAndIncrement(time.Minute)
+				Eventually(queued).Should(Receive(Equal(volume.ErrTooManyStreams)))
+			})
+
+			It("gives up once the context is done", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+
+				queued := make(chan error, 1)
+				go func() {
+					queued <- streamIn(ctx)
+				}()
+
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				cancel()
+				Eventually(queued).Should(Receive(Equal(context.Canceled)))
+			})
+		})
+	})
+
+	Describe("copying into a volume", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+
+			sourcePath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-copy-in")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			source, err := realRepo.CreateVolume("source-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			sourcePath = source.Path
+			Expect(os.MkdirAll(filepath.Join(sourcePath, "some-dir", "nested-dir"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(sourcePath, "some-dir", "some-file"), []byte("some-contents"), 0640)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(sourcePath, "some-dir", "nested-dir", "nested-file"), []byte("nested-contents"), 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		destinationPath := func(path ...string) string {
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			return filepath.Join(append([]string{vol.Path}, path...)...)
+		}
+
+		It("copies a directory's contents into the path", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", "into/here", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(destinationPath("into", "here", "some-file"))).To(Equal([]byte("some-contents")))
+			Expect(ioutil.ReadFile(destinationPath("into", "here", "nested-dir", "nested-file"))).To(Equal([]byte("nested-contents")))
+
+			info, err := os.Stat(destinationPath("into", "here", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+		})
+
+		It("copies a file into the path by its name", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", "into", "source-handle", volume.CopyInOptions{SourcePath: "some-dir/some-file"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(destinationPath("into", "some-file"))).To(Equal([]byte("some-contents")))
+		})
+
+		It("copies between paths of the same volume that don't overlap", func() {
+			err := realRepo.CopyIn(context.Background(), "source-handle", "other-dir", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(filepath.Join(sourcePath, "other-dir", "some-file"))).To(Equal([]byte("some-contents")))
+		})
+
+		It("records the digest of what the volume holds afterwards", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", ".", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+			Expect(err).NotTo(HaveOccurred())
+
+			digest, err := realRepo.VolumeDigest("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			vol, _, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Digest).To(Equal(digest))
+		})
+
+		It("returns ErrVolumeDoesNotExist if the volume doesn't exist", func() {
+			err := realRepo.CopyIn(context.Background(), "bogus-handle", ".", "source-handle", volume.CopyInOptions{})
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+
+		It("returns ErrSourceVolumeDoesNotExist if the source doesn't exist", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", ".", "bogus-handle", volume.CopyInOptions{})
+			Expect(err).To(Equal(volume.ErrSourceVolumeDoesNotExist))
+		})
+
+		It("returns ErrSourcePathDoesNotExist if there is nothing at the source path", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", "into", "source-handle", volume.CopyInOptions{SourcePath: "bogus-dir"})
+			Expect(err).To(Equal(volume.ErrSourcePathDoesNotExist))
+
+			Expect(destinationPath("into")).NotTo(BeADirectory())
+		})
+
+		It("refuses paths that overlap in the same volume", func() {
+			err := realRepo.CopyIn(context.Background(), "source-handle", "some-dir/nested-dir/copy", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+			Expect(err).To(Equal(volume.ErrCopyOverlapsSource))
+		})
+
+		It("refuses source paths that lead out of the source", func() {
+			err := realRepo.CopyIn(context.Background(), "some-handle", ".", "source-handle", volume.CopyInOptions{SourcePath: "../../some-handle"})
+			Expect(err).To(Equal(volume.ErrUnsafeSubPath))
+		})
+
+		It("refuses a frozen volume", func() {
+			Expect(realRepo.CommitVolume("some-handle", true)).To(Succeed())
+
+			err := realRepo.CopyIn(context.Background(), "some-handle", ".", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+			Expect(err).To(Equal(volume.ErrVolumeIsFrozen))
+		})
+
+		Context("when the volume is unprivileged and the source isn't", func() {
+			BeforeEach(func() {
+				_, err := realRepo.CreateVolume("unprivileged-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("namespaces what was copied", func() {
+				callsBefore := fakeUnprivilegedNamespacer.NamespacePathCallCount()
+
+				err := realRepo.CopyIn(context.Background(), "unprivileged-handle", "into", "source-handle", volume.CopyInOptions{SourcePath: "some-dir"})
+				Expect(err).NotTo(HaveOccurred())
+
+				vol, _, err := realRepo.GetVolume("unprivileged-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeUnprivilegedNamespacer.NamespacePathCallCount()).To(Equal(callsBefore + 2))
+
+				_, namespacedPath := fakeUnprivilegedNamespacer.NamespacePathArgsForCall(callsBefore + 1)
+				Expect(namespacedPath).To(Equal(filepath.Join(vol.Path, "into")))
+			})
+		})
+	})
+
+	Describe("volumes that renew their TTL on access", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-renewal")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("renewing-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{RenewTTLOnAccess: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("expiring-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			// both are already due by the repository's clock
+			Expect(realRepo.SetExpiresAt("renewing-handle", time.Unix(50, 0))).To(Succeed())
+			Expect(realRepo.SetExpiresAt("expiring-handle", time.Unix(50, 0))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		reap := func(handle string) error {
+			return realRepo.DestroyVolume(handle, volume.DestroyOptions{
+				Reason:        volume.DestroyReasonTTLExpiry,
+				SpareStreamed: true,
+				ExpiredAt:     time.Unix(50, 0),
+			})
+		}
+
+		It("keeps a volume that was streamed out from being reaped for having expired before", func() {
+			Expect(realRepo.StreamOut(context.Background(), "renewing-handle", ".", ioutil.Discard, volume.StreamOutOptions{})).To(Succeed())
+
+			renewed, found, err := realRepo.GetVolume("renewing-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(renewed.RenewTTLOnAccess).To(BeTrue())
+			Expect(renewed.ExpiresAt).To(BeTemporally(">", time.Unix(50, 0)))
+
+			Expect(reap("renewing-handle")).To(Equal(volume.ErrVolumeWasRenewed))
+		})
+
+		It("keeps a volume that was touched from being reaped for having expired before", func() {
+			Expect(realRepo.TouchAccess("renewing-handle")).To(Succeed())
+
+			Expect(reap("renewing-handle")).To(Equal(volume.ErrVolumeWasRenewed))
+		})
+
+		It("reaps a volume that was not accessed", func() {
+			Expect(reap("renewing-handle")).To(Succeed())
+		})
+
+		It("reaps a volume without renewal no matter how it was accessed", func() {
+			Expect(realRepo.StreamOut(context.Background(), "expiring-handle", ".", ioutil.Discard, volume.StreamOutOptions{})).To(Succeed())
+
+			_, _, err := realRepo.GetVolume("expiring-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reap("expiring-handle")).To(Succeed())
+		})
+	})
+
+	Describe("leases", func() {
+		var (
+			volumesDir string
+			realRepo   volume.Repository
+		)
+
+		someTar := func() *bytes.Buffer {
+			buffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(buffer)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Typeflag: tar.TypeReg, Mode: 0644, Size: 4})).To(Succeed())
+			_, err := tarWriter.Write([]byte("some"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
+			return buffer
+		}
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-leases")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("are held the same by the repository tagged for a request", func() {
+			lease, err := realRepo.WithLogData(lager.Data{"request-id": "some-request"}).AcquireLease("some-handle", "", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.AcquireLease("some-handle", "", time.Minute)
+			Expect(err).To(Equal(volume.ErrVolumeIsLeased))
+
+			Expect(realRepo.ReleaseLease("some-handle", lease.Token)).To(Succeed())
+		})
+
+		Context("while a lease is held", func() {
+			var lease volume.Lease
+
+			BeforeEach(func() {
+				var err error
+				lease, err = realRepo.AcquireLease("some-handle", "", time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("gives the lease a token, expiring it after its duration", func() {
+				Expect(lease.Token).NotTo(BeEmpty())
+				Expect(lease.ExpiresAt).To(Equal(fakeClock.Now().Add(time.Minute)))
+			})
+
+			It("refuses changes to the volume made without the token", func() {
+				_, err := realRepo.StreamIn(context.Background(), "some-handle", ".", someTar(), volume.StreamInOptions{})
+				Expect(err).To(Equal(volume.ErrVolumeIsLeased))
+
+				Expect(realRepo.SetProperty("some-handle", "some-property", "some-value", nil, "")).To(Equal(volume.ErrVolumeIsLeased))
+				Expect(realRepo.SetProperties("some-handle", volume.Properties{"some-property": "some-value"}, "")).To(Equal(volume.ErrVolumeIsLeased))
+				Expect(realRepo.DeleteProperty("some-handle", "some-property", "other-token")).To(Equal(volume.ErrVolumeIsLeased))
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{})).To(Equal(volume.ErrVolumeIsLeased))
+
+				_, found, err := realRepo.GetVolume("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+			})
+
+			It("lets changes made with the token through", func() {
+				_, err := realRepo.StreamIn(context.Background(), "some-handle", ".", someTar(), volume.StreamInOptions{LeaseToken: lease.Token})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(realRepo.SetProperty("some-handle", "some-property", "some-value", nil, lease.Token)).To(Succeed())
+				Expect(realRepo.SetProperties("some-handle", volume.Properties{"other-property": "other-value"}, lease.Token)).To(Succeed())
+				Expect(realRepo.DeleteProperty("some-handle", "other-property", lease.Token)).To(Succeed())
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{LeaseToken: lease.Token})).To(Succeed())
+			})
+
+			It("lets the volume be read without the token", func() {
+				Expect(realRepo.StreamOut(context.Background(), "some-handle", ".", ioutil.Discard, volume.StreamOutOptions{})).To(Succeed())
+			})
+
+			It("refuses a lease of another holder", func() {
+				_, err := realRepo.AcquireLease("some-handle", "", time.Minute)
+				Expect(err).To(Equal(volume.ErrVolumeIsLeased))
+
+				Expect(realRepo.ReleaseLease("some-handle", "other-token")).To(Equal(volume.ErrVolumeIsLeased))
+			})
+
+			It("extends the lease from now when acquired again with its token", func() {
+				fakeClock.Increment(50 * time.Second)
+
+				extended, err := realRepo.AcquireLease("some-handle", lease.Token, time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(extended.Token).To(Equal(lease.Token))
+				Expect(extended.ExpiresAt).To(Equal(fakeClock.Now().Add(time.Minute)))
+
+				fakeClock.Increment(50 * time.Second)
+
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{})).To(Equal(volume.ErrVolumeIsLeased))
+			})
+
+			It("lets the volume be changed again once released", func() {
+				Expect(realRepo.ReleaseLease("some-handle", lease.Token)).To(Succeed())
+
+				Expect(realRepo.SetProperty("some-handle", "some-property", "some-value", nil, "")).To(Succeed())
+
+				_, err := realRepo.AcquireLease("some-handle", lease.Token, time.Minute)
+				Expect(err).To(Equal(volume.ErrLeaseNotHeld))
+			})
+
+			It("lets the volume be changed again once expired", func() {
+				fakeClock.Increment(time.Minute)
+
+				Expect(realRepo.SetProperty("some-handle", "some-property", "some-value", nil, "")).To(Succeed())
+				Expect(realRepo.ReleaseLease("some-handle", lease.Token)).To(Succeed())
+
+				_, err := realRepo.AcquireLease("some-handle", lease.Token, time.Minute)
+				Expect(err).To(Equal(volume.ErrLeaseNotHeld))
+			})
+
+			It("carries the lease over to the volume's new handle when renamed", func() {
+				_, err := realRepo.RenameVolume("some-handle", "new-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(realRepo.SetProperty("new-handle", "some-property", "some-value", nil, "")).To(Equal(volume.ErrVolumeIsLeased))
+				Expect(realRepo.SetProperty("new-handle", "some-property", "some-value", nil, lease.Token)).To(Succeed())
+			})
+
+			It("forgets the lease once the volume is destroyed", func() {
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{LeaseToken: lease.Token})).To(Succeed())
+
+				_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(realRepo.SetProperty("some-handle", "some-property", "some-value", nil, "")).To(Succeed())
+			})
+		})
+
+		It("refuses a lease that would not last", func() {
+			_, err := realRepo.AcquireLease("some-handle", "", 0)
+			Expect(err).To(Equal(volume.ErrInvalidLeaseDuration))
+		})
+
+		It("refuses to lease a volume that does not exist", func() {
+			_, err := realRepo.AcquireLease("bogus-handle", "", time.Minute)
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+
+			Expect(realRepo.ReleaseLease("bogus-handle", "some-token")).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+	})
+
+	Describe("RenameVolume", func() {
+		var (
+			volumesDir string
+			hub        *volume.EventHub
+			realRepo   volume.Repository
+
+			events      <-chan volume.Event
+			unsubscribe func()
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-rename")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			hub = volume.NewEventHub()
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				[]string{"some"},
+				hub,
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			parent, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(parent.Path, "some-file"), []byte("some-content"), 0644)).To(Succeed())
+
+			events, unsubscribe = hub.Subscribe(10)
+		})
+
+		AfterEach(func() {
+			unsubscribe()
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("moves the volume and its data to the new handle", func() {
+			renamed, err := realRepo.RenameVolume("some-handle", "new-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed.Handle).To(Equal("new-handle"))
+			Expect(renamed.Properties).To(Equal(volume.Properties{"some": "property"}))
+			Expect(ioutil.ReadFile(filepath.Join(renamed.Path, "some-file"))).To(Equal([]byte("some-content")))
+
+			_, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			_, found, err = realRepo.GetVolume("new-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			volumes, _, err := realRepo.ListVolumes(volume.Properties{"some": "property"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Handle).To(Equal("new-handle"))
+		})
+
+		It("publishes the rename", func() {
+			_, err := realRepo.RenameVolume("some-handle", "new-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(Receive(Equal(volume.Event{
+				Type:           volume.EventRenamed,
+				Handle:         "new-handle",
+				PreviousHandle: "some-handle",
+				Properties:     volume.Properties{"some": "property"},
+				At:             fakeClock.Now(),
+			})))
+		})
+
+		It("keeps the volume's children and views resolving to it", func() {
+			_, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			view, err := realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.RenameVolume("some-handle", "new-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, child := range []string{"child-handle", "view-handle"} {
+				parent, found, err := realRepo.VolumeParent(child)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(parent.Handle).To(Equal("new-handle"))
+			}
+
+			Expect(ioutil.ReadFile(filepath.Join(view.Path, "some-file"))).To(Equal([]byte("some-content")))
+
+			err = realRepo.DestroyVolume("new-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+
+			err = realRepo.DestroyVolume("child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = realRepo.DestroyVolume("new-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, found, err := realRepo.GetVolume("new-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue(), "the base of a view is only released")
+		})
+
+		It("returns ErrVolumeAlreadyExists when the handle is taken", func() {
+			_, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.RenameVolume("some-handle", "other-handle")
+			Expect(err).To(Equal(volume.ErrVolumeAlreadyExists))
+
+			_, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("returns ErrVolumeDoesNotExist when there is no such volume", func() {
+			_, err := realRepo.RenameVolume("bogus-handle", "new-handle")
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+
+		It("returns ErrInvalidHandle for handles that are not names", func() {
+			for _, handle := range []string{"", ".", "..", "some/handle"} {
+				_, err := realRepo.RenameVolume("some-handle", handle)
+				Expect(err).To(Equal(volume.ErrInvalidHandle), handle)
+			}
+		})
+
+		Context("while the volume is being streamed into", func() {
+			var (
+				streamWriter *io.PipeWriter
+				streamDone   chan struct{}
+			)
+
+			BeforeEach(func() {
+				var streamReader *io.PipeReader
+				streamReader, streamWriter = io.Pipe()
+
+				streamDone = make(chan struct{})
+				go func() {
+					defer close(streamDone)
+					realRepo.StreamIn(context.Background(), "some-handle", ".", streamReader, volume.StreamInOptions{})
+				}()
+
+				_, err := streamWriter.Write([]byte{0})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				streamWriter.Close()
+				Eventually(streamDone).Should(BeClosed())
+			})
+
+			It("returns ErrVolumeIsStreaming, and renames once the stream is done", func() {
+				_, err := realRepo.RenameVolume("some-handle", "new-handle")
+				Expect(err).To(Equal(volume.ErrVolumeIsStreaming))
+
+				Expect(streamWriter.Close()).To(Succeed())
+				Eventually(streamDone).Should(BeClosed())
+
+				_, err = realRepo.RenameVolume("some-handle", "new-handle")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Promote", func() {
+		var (
+			volumesDir      string
+			promotingDriver *promotingNaiveDriver
+			realRepo        volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-promote")
+			Expect(err).NotTo(HaveOccurred())
+
+			promotingDriver = &promotingNaiveDriver{}
+
+			filesystem, err := volume.NewFilesystem(promotingDriver, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				[]string{"some"},
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			parent, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(parent.Path, "some-file"), []byte("some-content"), 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("cuts a copy-on-write child loose from its parent, which can then be destroyed", func() {
+			child, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			promoted, err := realRepo.Promote("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(promoted.Handle).To(Equal("child-handle"))
+			Expect(promoted.Properties).To(Equal(volume.Properties{"some": "property"}))
+			Expect(promotingDriver.promoted).To(Equal([]string{child.Path}))
+
+			_, found, err := realRepo.VolumeParent("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, found, err = realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			Expect(ioutil.ReadFile(filepath.Join(child.Path, "some-file"))).To(Equal([]byte("some-content")))
+		})
+
+		It("leaves volumes that are not copy-on-write children as they are", func() {
+			_, err := realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, handle := range []string{"some-handle", "view-handle"} {
+				promoted, err := realRepo.Promote(handle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(promoted.Handle).To(Equal(handle))
+			}
+
+			Expect(promotingDriver.promoted).To(BeEmpty())
+
+			parent, found, err := realRepo.VolumeParent("view-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(parent.Handle).To(Equal("some-handle"))
+		})
+
+		It("can be done again", func() {
+			_, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.Promote("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.Promote("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(promotingDriver.promoted).To(HaveLen(1))
+		})
+
+		It("promotes a child that only has views of it", func() {
+			_, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "child-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.Promote("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns ErrPromoteWithChildren when the driver cannot promote a child with copy-on-write children", func() {
+			_, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("grandchild-handle", volume.COWStrategy{ParentHandle: "child-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.Promote("child-handle")
+			Expect(err).To(Equal(volume.ErrPromoteWithChildren))
+			Expect(promotingDriver.promoted).To(BeEmpty())
+
+			_, found, err := realRepo.VolumeParent("child-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("returns ErrVolumeDoesNotExist when there is no such volume", func() {
+			_, err := realRepo.Promote("bogus-handle")
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+	})
+
+	Describe("volumes with copy-on-write children", func() {
+		var (
+			volumesDir string
+			filesystem volume.Filesystem
+			realRepo   volume.Repository
+		)
+
+		newRepository := func() volume.Repository {
+			return volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				[]string{"some"},
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		}
+
+		exists := func(handle string) bool {
+			_, found, err := realRepo.GetVolume(handle)
+			Expect(err).NotTo(HaveOccurred())
+			return found
+		}
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-destroy-children")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err = volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = newRepository()
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		Describe("destroying them", func() {
+			It("returns ErrVolumeHasChildren, leaving the volume as it is", func() {
+				err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+
+				Expect(exists("some-handle")).To(BeTrue())
+			})
+
+			It("destroys the volume once its children are gone", func() {
+				err := realRepo.DestroyVolume("child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(exists("some-handle")).To(BeFalse())
+			})
+
+			It("destroys the volume along with its descendants when asked to", func() {
+				_, err := realRepo.CreateVolume("grandchild-handle", volume.COWStrategy{ParentHandle: "child-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				destroyed, err := realRepo.DestroyVolumeAndDescendants("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(destroyed).To(Equal([]string{"grandchild-handle", "child-handle", "some-handle"}))
+
+				for _, handle := range []string{"some-handle", "child-handle", "grandchild-handle"} {
+					Expect(exists(handle)).To(BeFalse(), handle)
+				}
+			})
+
+			It("plans the same destroys without destroying anything", func() {
+				_, err := realRepo.CreateVolume("grandchild-handle", volume.COWStrategy{ParentHandle: "child-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				planned, err := realRepo.PlanDestroy("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual}, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(planned).To(Equal([]string{"grandchild-handle", "child-handle", "some-handle"}))
+
+				planned, err = realRepo.PlanDestroy("grandchild-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(planned).To(Equal([]string{"grandchild-handle"}))
+
+				_, err = realRepo.PlanDestroy("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual}, false)
+				Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+
+				_, err = realRepo.PlanDestroy("bogus-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual}, true)
+				Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+
+				for _, handle := range []string{"some-handle", "child-handle", "grandchild-handle"} {
+					Expect(exists(handle)).To(BeTrue(), handle)
+				}
+			})
+
+			It("knows of the children a volume had before the repository was created", func() {
+				realRepo = newRepository()
+
+				err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+			})
+
+			It("keeps track of children and parents that are renamed", func() {
+				_, err := realRepo.RenameVolume("child-handle", "new-child-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = realRepo.RenameVolume("some-handle", "new-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = realRepo.DestroyVolume("new-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+
+				err = realRepo.DestroyVolume("new-child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = realRepo.DestroyVolume("new-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not count views as children", func() {
+				err := realRepo.DestroyVolume("child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("destroys a volume and its children destroyed in the same batch", func() {
+				errs := realRepo.DestroyVolumes([]string{"some-handle", "child-handle"}, volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(errs).To(Equal(map[string]error{"some-handle": nil, "child-handle": nil}))
+
+				Expect(exists("some-handle")).To(BeFalse())
+				Expect(exists("child-handle")).To(BeFalse())
+			})
+		})
+
+		It("records the parent of a copy-on-write child, and of no other volume", func() {
+			_, err := realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			expected := map[string]string{
+				"some-handle":  "",
+				"child-handle": "some-handle",
+				"view-handle":  "",
+			}
+
+			for handle, parentHandle := range expected {
+				vol, found, err := realRepo.GetVolume(handle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(vol.ParentHandle).To(Equal(parentHandle), handle)
+			}
+		})
+
+		It("returns the parent of a copy-on-write child as it is created", func() {
+			grandchild, err := realRepo.CreateVolume("grandchild-handle", volume.COWStrategy{ParentHandle: "child-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(grandchild.ParentHandle).To(Equal("child-handle"))
+		})
+
+		Describe("VolumeChildren", func() {
+			It("returns the copy-on-write children of the volume, leaving out views", func() {
+				_, err := realRepo.CreateVolume("other-child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(realRepo.VolumeChildren("some-handle")).To(Equal([]string{"child-handle", "other-child-handle"}))
+				Expect(realRepo.VolumeChildren("child-handle")).To(BeEmpty())
+			})
+
+			It("leaves out children once they are destroyed", func() {
+				err := realRepo.DestroyVolume("child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(realRepo.VolumeChildren("some-handle")).To(BeEmpty())
+			})
+
+			It("returns ErrVolumeDoesNotExist when there is no such volume", func() {
+				_, err := realRepo.VolumeChildren("bogus-handle")
+				Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+
+	Describe("creating volumes with an idempotency key", func() {
+		var (
+			volumesDir string
+			filesystem volume.Filesystem
+			realRepo   volume.Repository
+		)
+
+		newRepository := func() volume.Repository {
+			return volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		}
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-create-key")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err = volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = newRepository()
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("returns the volume already created with the key instead of creating another", func() {
+			vol, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Handle).To(Equal("some-handle"))
+
+			_, found, err := realRepo.GetVolume("other-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("returns ErrIdempotencyKeyConflict when the key was used with other parameters", func() {
+			_, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "other-property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).To(Equal(volume.ErrIdempotencyKeyConflict))
+
+			_, err = realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 120, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).To(Equal(volume.ErrIdempotencyKeyConflict))
+		})
+
+		It("finds the key after a restart", func() {
+			realRepo = newRepository()
+
+			vol, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Handle).To(Equal("some-handle"))
+		})
+
+		It("creates a new volume with the key once the old one is destroyed", func() {
+			err := realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+
+			vol, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "other-property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Handle).To(Equal("other-handle"))
+		})
+
+		It("follows the volume when it is renamed", func() {
+			_, err := realRepo.RenameVolume("some-handle", "renamed-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			vol, err := realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{IdempotencyKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol.Handle).To(Equal("renamed-handle"))
+		})
+	})
+
+	Describe("tmpfs volumes", func() {
+		var (
+			volumesDir  string
+			tmpfsDriver *tmpfsNaiveDriver
+			realRepo    volume.Repository
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-tmpfs")
+			Expect(err).NotTo(HaveOccurred())
+
+			tmpfsDriver = &tmpfsNaiveDriver{quotas: map[string]int64{}}
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, tmpfsDriver, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("backs the volume with the tmpfs driver, limited to its size", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdVolume.Driver).To(Equal("tmpfs"))
+			Expect(tmpfsDriver.quotas).To(Equal(map[string]int64{"some-handle": 1024 * 1024}))
+
+			Expect(ioutil.WriteFile(filepath.Join(createdVolume.Path, "some-file"), []byte("some-content"), 0644)).To(Succeed())
+
+			stats, found, err := realRepo.GetVolumeStats("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(stats.Driver).To(Equal("tmpfs"))
+			Expect(stats.QuotaInBytes).To(Equal(int64(1024 * 1024)))
+			Expect(stats.FileCount).To(Equal(int64(1)))
+		})
+
+		It("streams in and out and keeps properties like any other volume", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+
+			tarBuffer := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(tarBuffer)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-file", Mode: 0600, Size: 4})).To(Succeed())
+			_, err = tarWriter.Write([]byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
+
+			_, err = realRepo.StreamIn(context.Background(), "some-handle", ".", tarBuffer, volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			streamed := new(bytes.Buffer)
+			Expect(realRepo.StreamOut(context.Background(), "some-handle", "some-file", streamed, volume.StreamOutOptions{Format: volume.StreamOutTar})).To(Succeed())
+
+			tarReader := tar.NewReader(streamed)
+			_, err = tarReader.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.ReadAll(tarReader)).To(Equal([]byte("data")))
+
+			Expect(realRepo.SetProperty("some-handle", "other", "property", nil, "")).To(Succeed())
+
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.Properties).To(Equal(volume.Properties{"some": "property", "other": "property"}))
+		})
+
+		It("has the tmpfs driver destroy its data", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+			Expect(tmpfsDriver.destroyed).To(Equal([]string{
+				filepath.Join(volumesDir, "dead", "some-handle", "volume"),
+			}))
+
+			Expect(createdVolume.Path).NotTo(BeADirectory())
+		})
+
+		It("returns ErrCopyOnWriteOfTmpfs for copy-on-write children of them", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).To(Equal(volume.ErrCopyOnWriteOfTmpfs))
+		})
+
+		It("copies them onto the filesystem's driver when cloning", func() {
+			createdVolume, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(createdVolume.Path, "some-file"), []byte("some-content"), 0644)).To(Succeed())
+
+			clone, err := realRepo.CreateVolume("copy-handle", volume.CopyStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clone.Driver).To(Equal("naive"))
+			Expect(ioutil.ReadFile(filepath.Join(clone.Path, "some-file"))).To(Equal([]byte("some-content")))
+		})
+
+		Context("when the filesystem has no tmpfs driver", func() {
+			BeforeEach(func() {
+				filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				realRepo = volume.NewRepository(
+					logger,
+					fakeClock,
+					filesystem,
+					volume.NewLockManager(),
+					volume.NewPathLockManager(),
+					fakePrivilegedNamespacer,
+					fakeUnprivilegedNamespacer,
+					nil,
+					time.Minute,
+					volume.NoopDestroyAuditLog{},
+					0,
+					1,
+					nil,
+					volume.NoopEventSink{},
+					volume.PropertyLimits{},
+					0,
+					volume.StreamLimits{},
+					0,
+				)
+			})
+
+			It("returns ErrTmpfsNotSupported", func() {
+				_, err := realRepo.CreateVolume("some-handle", volume.TmpfsStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+				Expect(err).To(Equal(volume.ErrTmpfsNotSupported))
+			})
+		})
+	})
+
+	Describe("lifecycle events", func() {
+		var (
+			volumesDir string
+			hub        *volume.EventHub
+			realRepo   volume.Repository
+
+			events      <-chan volume.Event
+			unsubscribe func()
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "volume-events")
+			Expect(err).NotTo(HaveOccurred())
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			hub = volume.NewEventHub()
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				hub,
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			events, unsubscribe = hub.Subscribe(10)
+		})
+
+		AfterEach(func() {
+			unsubscribe()
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("publishes creates, property changes, and destroys", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{"a": "b"}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.SetProperty("some-handle", "c", "d", nil, "")).To(Succeed())
+			Expect(realRepo.SetProperties("some-handle", volume.Properties{"e": "f"}, "")).To(Succeed())
+			Expect(realRepo.DeleteProperty("some-handle", "a", "")).To(Succeed())
+
+			err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+			Expect(err).NotTo(HaveOccurred())
+
+			var event volume.Event
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventCreated))
+			Expect(event.Handle).To(Equal("some-handle"))
+			Expect(event.Properties).To(Equal(volume.Properties{"a": "b"}))
+
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventPropertyChanged))
+			Expect(event.Properties).To(Equal(volume.Properties{"a": "b", "c": "d"}))
+
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventPropertyChanged))
+			Expect(event.Properties).To(Equal(volume.Properties{"a": "b", "c": "d", "e": "f"}))
+
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventPropertyChanged))
+			Expect(event.Properties).To(Equal(volume.Properties{"c": "d", "e": "f"}))
+
+			Expect(events).To(Receive(&event))
+			Expect(event).To(Equal(volume.Event{
+				Type:       volume.EventDestroyed,
+				Handle:     "some-handle",
+				Properties: volume.Properties{"c": "d", "e": "f"},
+				Reason:     volume.DestroyReasonManual,
+				At:         fakeClock.Now(),
+			}))
+
+			Expect(events).NotTo(Receive())
+		})
+
+		It("publishes clones as creates", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{"a": "b"}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CloneVolume("some-handle", "some-clone")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(Receive())
+
+			var event volume.Event
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventCreated))
+			Expect(event.Handle).To(Equal("some-clone"))
+		})
+
+		It("publishes volumes destroyed when their TTL expires as expired", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonTTLExpiry})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(Receive())
+
+			var event volume.Event
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventExpired))
+			Expect(event.Reason).To(Equal(volume.DestroyReasonTTLExpiry))
+		})
+
+		It("does not publish property deletes that change nothing", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.DeleteProperty("some-handle", "missing", "")).To(Succeed())
+
+			Expect(events).To(Receive())
+			Expect(events).NotTo(Receive())
+		})
+
+		It("unsubscribes subscribers that fall behind", func() {
+			slow, _ := hub.Subscribe(1)
+
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.SetProperty("some-handle", "a", "b", nil, "")).To(Succeed())
+
+			Expect(slow).To(Receive())
+			Expect(slow).To(BeClosed())
+
+			Expect(events).To(Receive())
+			Expect(events).To(Receive())
+		})
+	})
+
+	Describe("encrypted volumes", func() {
+		var (
+			volumesDir       string
+			masterKey        volume.MasterKey
+			encryptingDriver *encryptingNaiveDriver
+			realRepo         volume.Repository
+		)
+
+		newRepo := func(fsDriver volume.Driver, masterKey volume.MasterKey) volume.Repository {
+			filesystem, err := volume.NewFilesystem(fsDriver, volumesDir, nil, masterKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			return volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				1,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+		}
+
+		encryptionFile := func(handle string) string {
+			return filepath.Join(volumesDir, "live", handle, "encryption.json")
+		}
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "encrypted")
+			Expect(err).NotTo(HaveOccurred())
+
+			masterKey = volume.MasterKey(strings.Repeat("k", volume.MinMasterKeySize))
+			encryptingDriver = &encryptingNaiveDriver{keys: map[string][]byte{}}
+		})
+
+		JustBeforeEach(func() {
+			realRepo = newRepo(encryptingDriver, masterKey)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("creates them with a key of their own, and reports them as encrypted", func() {
+			created, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Encrypted).To(BeTrue())
+
+			_, err = realRepo.CreateVolume("other-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(encryptingDriver.keys).To(HaveLen(2))
+			Expect(encryptingDriver.keys["some-handle"]).To(HaveLen(64))
+			Expect(encryptingDriver.keys["some-handle"]).NotTo(Equal(encryptingDriver.keys["other-handle"]))
+
+			vol, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(vol.Encrypted).To(BeTrue())
+		})
+
+		It("leaves volumes that are not asked to be encrypted unencrypted", func() {
+			created, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Encrypted).To(BeFalse())
+			Expect(encryptingDriver.keys).To(BeEmpty())
+			Expect(encryptionFile("some-handle")).NotTo(BeAnExistingFile())
+		})
+
+		It("keeps copy-on-write layers, copies, and clones of them encrypted with keys of their own", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.WriteFile(filepath.Join(volumesDir, "live", "some-handle", "volume", "some-file"), []byte("some-contents"), 0644)).To(Succeed())
+
+			cow, err := realRepo.CreateVolume("cow-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cow.Encrypted).To(BeTrue())
+
+			duplicate, err := realRepo.CreateVolume("copy-handle", volume.CopyStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(duplicate.Encrypted).To(BeTrue())
+
+			clone, err := realRepo.CloneVolume("some-handle", "clone-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clone.Encrypted).To(BeTrue())
+
+			Expect(encryptingDriver.keys).To(HaveLen(4))
+
+			for _, handle := range []string{"cow-handle", "copy-handle", "clone-handle"} {
+				Expect(encryptingDriver.keys[handle]).NotTo(Equal(encryptingDriver.keys["some-handle"]))
+
+				contents, err := ioutil.ReadFile(filepath.Join(volumesDir, "live", handle, "volume", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-contents"))
+			}
+		})
+
+		It("refuses to encrypt volumes the strategy can't create encrypted, without creating them", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.CreateVolume("cow-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).To(Equal(volume.ErrStrategyNotEncryptable))
+
+			_, found, err := realRepo.GetVolume("cow-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("locks them and shreds what their key is derived from when they are destroyed", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encryptionFile("some-handle")).To(BeAnExistingFile())
+
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{})).To(Succeed())
+
+			Expect(encryptingDriver.keys).To(BeEmpty())
+			Expect(encryptingDriver.locked).To(HaveLen(1))
+			Expect(encryptionFile("some-handle")).NotTo(BeAnExistingFile())
+		})
+
+		It("unlocks them with the same key when they are materialized after being locked, even once renamed", func() {
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			key := encryptingDriver.keys["some-handle"]
+
+			mounted, err := realRepo.Materialize("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mounted).To(BeFalse())
+
+			_, err = realRepo.RenameVolume("some-handle", "new-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			delete(encryptingDriver.keys, "some-handle")
+
+			mounted, err = realRepo.Materialize("new-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mounted).To(BeTrue())
+			Expect(encryptingDriver.keys["new-handle"]).To(Equal(key))
+		})
+
+		Context("without a master key", func() {
+			BeforeEach(func() {
+				masterKey = nil
+			})
+
+			It("refuses to create them", func() {
+				_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+				Expect(err).To(Equal(volume.ErrEncryptionNotEnabled))
+
+				_, found, err := realRepo.GetVolume("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeFalse())
+			})
+
+			It("refuses to unlock those created with one", func() {
+				_, err := newRepo(encryptingDriver, volume.MasterKey(strings.Repeat("k", volume.MinMasterKeySize))).CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = realRepo.Materialize("some-handle")
+				Expect(err).To(Equal(volume.ErrEncryptionNotEnabled))
+			})
+		})
+
+		Context("when the driver cannot encrypt volumes", func() {
+			It("refuses to create them", func() {
+				_, err := newRepo(struct{ volume.Driver }{&driver.NaiveDriver{}}, masterKey).CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{Encrypted: true})
+				Expect(err).To(Equal(volume.ErrEncryptionNotSupported))
+			})
+		})
+	})
+
+	Describe("deleted volumes", func() {
+		var (
+			volumesDir string
+			hub        *volume.EventHub
+			realRepo   volume.Repository
+
+			events      <-chan volume.Event
+			unsubscribe func()
+		)
+
+		BeforeEach(func() {
+			var err error
+			volumesDir, err = ioutil.TempDir("", "deleted-volumes")
+			Expect(err).NotTo(HaveOccurred())
+
+			deletedRetention = time.Hour
+			hub = volume.NewEventHub()
+		})
+
+		JustBeforeEach(func() {
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			realRepo = volume.NewRepository(
+				logger,
+				fakeClock,
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				fakePrivilegedNamespacer,
+				fakeUnprivilegedNamespacer,
+				nil,
+				time.Minute,
+				fakeDestroyAuditLog,
+				0,
+				1,
+				nil,
+				hub,
+				volume.PropertyLimits{},
+				deletedRetention,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{"some": "property"}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.WriteFile(filepath.Join(volumesDir, "live", "some-handle", "volume", "some-file"), []byte("some-contents"), 0644)).To(Succeed())
+
+			events, unsubscribe = hub.Subscribe(10)
+		})
+
+		AfterEach(func() {
+			unsubscribe()
+			Expect(os.RemoveAll(volumesDir)).To(Succeed())
+		})
+
+		It("keeps destroyed volumes, data and all, hidden from everything but ListDeletedVolumes", func() {
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+			Expect(filepath.Join(volumesDir, "live", "some-handle", "volume", "some-file")).To(BeAnExistingFile())
+
+			_, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(BeEmpty())
+
+			_, err = realRepo.StreamIn(context.Background(), "some-handle", "", &bytes.Buffer{}, volume.StreamInOptions{})
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+
+			deleted, corrupted, err := realRepo.ListDeletedVolumes(volume.Properties{"some": "property"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(corrupted).To(BeEmpty())
+			Expect(deleted).To(HaveLen(1))
+			Expect(deleted[0].Handle).To(Equal("some-handle"))
+			Expect(deleted[0].Deleted).To(BeTrue())
+			Expect(deleted[0].DeletedAt).To(BeTemporally("==", fakeClock.Now()))
+
+			deleted, _, err = realRepo.ListDeletedVolumes(volume.Properties{"some": "other-property"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeEmpty())
+
+			Expect(fakeDestroyAuditLog.RecordCallCount()).To(Equal(1))
+
+			var event volume.Event
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventDestroyed))
+		})
+
+		It("does not give the handle of a deleted volume to another", func() {
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+			_, err := realRepo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).To(Equal(volume.ErrVolumeAlreadyExists))
+
+			_, err = realRepo.CloneVolume("some-handle", "clone-handle")
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+
+		It("restores them as they were within the retention window, with their TTL starting over", func() {
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+			Eventually(events).Should(Receive())
+
+			fakeClock.Increment(59 * time.Minute)
+
+			restored, err := realRepo.RestoreVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restored.Handle).To(Equal("some-handle"))
+			Expect(restored.Properties).To(Equal(volume.Properties{"some": "property"}))
+			Expect(restored.Deleted).To(BeFalse())
+			Expect(restored.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+
+			var event volume.Event
+			Expect(events).To(Receive(&event))
+			Expect(event.Type).To(Equal(volume.EventRestored))
+			Expect(event.Properties).To(Equal(volume.Properties{"some": "property"}))
+
+			Expect(filepath.Join(volumesDir, "live", "some-handle", "volume", "some-file")).To(BeAnExistingFile())
+
+			volumes, _, err := realRepo.ListVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(1))
+
+			deleted, _, err := realRepo.ListDeletedVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeEmpty())
+
+			again, err := realRepo.RestoreVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again.Handle).To(Equal("some-handle"))
+		})
+
+		It("does not restore volumes that were never there", func() {
+			_, err := realRepo.RestoreVolume("bogus-handle")
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+
+		It("purges them once the retention window has passed, after which they are gone", func() {
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+			fakeClock.Increment(59 * time.Minute)
+
+			results, err := realRepo.PurgeDeletedVolumes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(BeEmpty())
+
+			fakeClock.Increment(time.Minute)
+
+			_, err = realRepo.RestoreVolume("some-handle")
+			Expect(err).To(Equal(volume.ErrVolumeGone))
+
+			results, err = realRepo.PurgeDeletedVolumes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal(map[string]error{"some-handle": nil}))
+
+			Expect(filepath.Join(volumesDir, "live", "some-handle")).NotTo(BeADirectory())
+
+			_, err = realRepo.RestoreVolume("some-handle")
+			Expect(err).To(Equal(volume.ErrVolumeGone))
+
+			// for another retention window
+			fakeClock.Increment(time.Hour + time.Second)
+
+			_, err = realRepo.PurgeDeletedVolumes()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = realRepo.RestoreVolume("some-handle")
+			Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+		})
+
+		It("destroys volumes straight away under disk pressure", func() {
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonDiskPressure})).To(Succeed())
+
+			Expect(filepath.Join(volumesDir, "live", "some-handle")).NotTo(BeADirectory())
+
+			deleted, _, err := realRepo.ListDeletedVolumes(volume.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeEmpty())
+		})
+
+		Context("with copy-on-write children", func() {
+			JustBeforeEach(func() {
+				_, err := realRepo.CreateVolume("child-handle", volume.COWStrategy{ParentHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("keeps the parent until its deleted children are purged, and restores children only once it is restored", func() {
+				destroyed, err := realRepo.DestroyVolumeAndDescendants("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(destroyed).To(Equal([]string{"child-handle", "some-handle"}))
+
+				_, err = realRepo.RestoreVolume("child-handle")
+				Expect(err).To(Equal(volume.ErrParentVolumeDeleted))
+
+				fakeClock.Increment(time.Hour)
+
+				results, err := realRepo.PurgeDeletedVolumes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string]error{"child-handle": nil}))
+
+				results, err = realRepo.PurgeDeletedVolumes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string]error{"some-handle": nil}))
+			})
+
+			It("counts restored children against destroying the parent again", func() {
+				Expect(realRepo.DestroyVolume("child-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+				_, err := realRepo.RestoreVolume("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				restored, err := realRepo.RestoreVolume("child-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(restored.ParentHandle).To(Equal("some-handle"))
+
+				err = realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})
+				Expect(err).To(Equal(volume.ErrVolumeHasChildren))
+			})
+		})
+
+		It("counts restored views against destroying their base", func() {
+			_, err := realRepo.CreateVolume("view-handle", volume.ViewStrategy{BaseHandle: "some-handle"}, volume.Properties{}, 60, false, volume.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.DestroyVolume("view-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+			_, err = realRepo.RestoreVolume("view-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+			released, found, err := realRepo.GetVolume("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue(), "the base of a view is only released")
+			Expect(released.Frozen).To(BeTrue())
+		})
+
+		Context("without a retention window", func() {
+			BeforeEach(func() {
+				deletedRetention = 0
+			})
+
+			It("destroys volumes straight away", func() {
+				Expect(realRepo.DestroyVolume("some-handle", volume.DestroyOptions{Reason: volume.DestroyReasonManual})).To(Succeed())
+
+				Expect(filepath.Join(volumesDir, "live", "some-handle")).NotTo(BeADirectory())
+
+				_, err := realRepo.RestoreVolume("some-handle")
+				Expect(err).To(Equal(volume.ErrVolumeDoesNotExist))
+			})
+		})
+	})
+})
+
+// readOnlyNaiveDriver records the volumes it is asked to make read-only,
+// leaving them writable so that they can be cleaned up.
+type readOnlyNaiveDriver struct {
+	driver.NaiveDriver
+
+	readOnly []string
+}
+
+func (driver *readOnlyNaiveDriver) MakeReadOnly(path string) error {
+	driver.readOnly = append(driver.readOnly, path)
+	return nil
+}
+
+// mountOptionsNaiveDriver records the mount options it is asked to give
+// volumes by their handles, as they are made live after being given them, and has them mounted afresh by Materialize once
+// unmounted is set.
+type mountOptionsNaiveDriver struct {
+	driver.NaiveDriver
+
+	options   map[string][]string
+	unmounted bool
+}
+
+func (driver *mountOptionsNaiveDriver) MountOptions() []string {
+	return []string{"compress=lzo", "compress=zstd", "noatime"}
+}
+
+func (driver *mountOptionsNaiveDriver) SetMountOptions(path string, options []string) error {
+	driver.options[filepath.Base(filepath.Dir(path))] = options
+	return nil
+}
+
+func (driver *mountOptionsNaiveDriver) EnsureMounted(path string) (bool, error) {
+	unmounted := driver.unmounted
+	driver.unmounted = false
+	return unmounted, nil
+}
+
+// promotingNaiveDriver records the volumes it is asked to promote, whose data
+// the naive driver has already copied.
+type promotingNaiveDriver struct {
+	driver.NaiveDriver
+
+	promoted []string
+}
+
+func (driver *promotingNaiveDriver) PromoteVolume(path string) error {
+	driver.promoted = append(driver.promoted, path)
+	return nil
+}
+
+// tmpfsNaiveDriver stands in for the tmpfs driver, recording the sizes it is
+// asked to limit volumes to by their handles, as they are made live after
+// their size is set, and the volumes it destroys.
+type tmpfsNaiveDriver struct {
+	driver.NaiveDriver
+
+	quotas    map[string]int64
+	destroyed []string
+}
+
+func (driver *tmpfsNaiveDriver) Name() string {
+	return "tmpfs"
+}
+
+func (driver *tmpfsNaiveDriver) DestroyVolume(path string) error {
+	driver.destroyed = append(driver.destroyed, path)
+	return driver.NaiveDriver.DestroyVolume(path)
+}
+
+func (driver *tmpfsNaiveDriver) SetVolumeQuota(path string, sizeInBytes int64) error {
+	driver.quotas[filepath.Base(filepath.Dir(path))] = sizeInBytes
+	return nil
+}
+
+func (driver *tmpfsNaiveDriver) GetVolumeQuota(path string) (int64, error) {
+	return driver.quotas[filepath.Base(filepath.Dir(path))], nil
+}
+
+// encryptingNaiveDriver records the keys of the volumes it has unlocked by
+// their handles, and the volumes it locks, leaving their data as it is.
+type encryptingNaiveDriver struct {
+	driver.NaiveDriver
+
+	keys   map[string][]byte
+	locked []string
+}
+
+func (driver *encryptingNaiveDriver) CreateEncryptedVolume(path string, key []byte) error {
+	err := driver.CreateVolume(path)
+	if err != nil {
+		return err
+	}
+
+	driver.keys[filepath.Base(filepath.Dir(path))] = key
+	return nil
+}
+
+func (driver *encryptingNaiveDriver) UnlockVolume(path string, key []byte) (bool, error) {
+	handle := filepath.Base(filepath.Dir(path))
+	if _, found := driver.keys[handle]; found {
+		return false, nil
+	}
+
+	driver.keys[handle] = key
+	return true, nil
+}
+
+func (driver *encryptingNaiveDriver) LockVolume(path string) error {
+	delete(driver.keys, filepath.Base(filepath.Dir(path)))
+	driver.locked = append(driver.locked, path)
+	return nil
+}
@@ -0,0 +1,15 @@
+package volume
+
+import (
+	"errors"
+	"regexp"
+)
+
+var ErrInvalidSELinuxLabel = errors.New("SELinux label must be of the form user:role:type[:level]")
+
+// the level may itself contain colons, e.g. s0:c1,c2
+var selinuxLabelPattern = regexp.MustCompile(`^[^:\s]+:[^:\s]+:[^:\s]+(:\S+)?$`)
+
+func validSELinuxLabel(label string) bool {
+	return selinuxLabelPattern.MatchString(label)
+}
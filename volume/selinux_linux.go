@@ -0,0 +1,40 @@
+package volume
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// selinuxfs is mounted whenever SELinux is enabled, enforcing or not
+const selinuxEnforcePath = "/sys/fs/selinux/enforce"
+
+func (repo *repository) relabel(dir string, label string, privileged bool) error {
+	if _, err := os.Stat(selinuxEnforcePath); err != nil {
+		return nil
+	}
+
+	// like tar, chcon runs in the volume's user namespace for unprivileged
+	// volumes, so it can only relabel what the volume's root could
+	dirFd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	defer dirFd.Close()
+
+	chconCommand := exec.Command("chcon", "-R", label, "/dev/fd/3/.")
+	chconCommand.ExtraFiles = []*os.File{dirFd}
+
+	if !privileged {
+		repo.namespacer(false).NamespaceCommand(chconCommand)
+	}
+
+	output, err := chconCommand.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("chcon: %s: %s", err, bytes.TrimSpace(output))
+	}
+
+	return nil
+}
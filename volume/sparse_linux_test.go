@@ -0,0 +1,201 @@
+package volume_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/baggageclaim/uidgid/uidgidfakes"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Streaming sparse files", func() {
+	const (
+		sparseSize = 64 * 1024 * 1024
+		mixedSize  = 8*1024*1024 + 4096
+	)
+
+	var (
+		volumesDir string
+		source     volume.Volume
+	)
+
+	allocated := func(path string) int64 {
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		return info.Sys().(*syscall.Stat_t).Blocks * 512
+	}
+
+	newRepoWithDriver := func(volumeDriver volume.Driver, streamInConcurrency int) volume.Repository {
+		filesystem, err := volume.NewFilesystem(volumeDriver, volumesDir, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		return volume.NewRepository(
+			lagertest.NewTestLogger("repository"),
+			fakeclock.NewFakeClock(time.Unix(123, 456)),
+			filesystem,
+			volume.NewLockManager(),
+			volume.NewPathLockManager(),
+			new(uidgidfakes.FakeNamespacer),
+			new(uidgidfakes.FakeNamespacer),
+			nil,
+			time.Minute,
+			volume.NoopDestroyAuditLog{},
+			0,
+			streamInConcurrency,
+			nil,
+			volume.NoopEventSink{},
+			volume.PropertyLimits{},
+			0,
+			volume.StreamLimits{},
+			0,
+		)
+	}
+
+	newRepo := func(streamInConcurrency int) volume.Repository {
+		return newRepoWithDriver(&driver.NaiveDriver{}, streamInConcurrency)
+	}
+
+	BeforeEach(func() {
+		var err error
+		volumesDir, err = ioutil.TempDir("", "volume-sparse")
+		Expect(err).NotTo(HaveOccurred())
+
+		source, err = newRepo(1).CreateVolume("source-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, true, volume.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		sparse, err := os.Create(filepath.Join(source.Path, "fully-sparse"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sparse.Truncate(sparseSize)).To(Succeed())
+		Expect(sparse.Close()).To(Succeed())
+
+		if allocated(filepath.Join(source.Path, "fully-sparse")) != 0 {
+			Skip("the volumes filesystem does not support holes")
+		}
+
+		mixed, err := os.Create(filepath.Join(source.Path, "mixed"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = mixed.WriteAt(bytes.Repeat([]byte("a"), 4096), 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = mixed.WriteAt(bytes.Repeat([]byte("b"), 4096), mixedSize-4096)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mixed.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(volumesDir)).To(Succeed())
+	})
+
+	It("streams every file in full unless asked not to", func() {
+		streamed := new(bytes.Buffer)
+		Expect(newRepo(1).StreamOut(context.Background(), "source-handle", ".", streamed, volume.StreamOutOptions{})).To(Succeed())
+		Expect(streamed.Len()).To(BeNumerically(">", sparseSize+mixedSize))
+	})
+
+	for _, concurrency := range []int{1, 4} {
+		concurrency := concurrency
+
+		Context(fmt.Sprintf("when streamed in with a concurrency of %d", concurrency), func() {
+			var dest volume.Volume
+
+			BeforeEach(func() {
+				streamed := new(bytes.Buffer)
+				Expect(newRepo(1).StreamOut(context.Background(), "source-handle", ".", streamed, volume.StreamOutOptions{Sparse: true})).To(Succeed())
+				Expect(streamed.Len()).To(BeNumerically("<", 1024*1024))
+
+				repo := newRepo(concurrency)
+
+				var err error
+				dest, err = repo.CreateVolume("dest-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, true, volume.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.StreamIn(context.Background(), "dest-handle", ".", streamed, volume.StreamInOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("keeps a fully sparse file at its size, with nothing allocated", func() {
+				info, err := os.Stat(filepath.Join(dest.Path, "fully-sparse"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Size()).To(Equal(int64(sparseSize)))
+
+				Expect(allocated(filepath.Join(dest.Path, "fully-sparse"))).To(BeNumerically("<=", 4096))
+			})
+
+			It("keeps the data of a file with holes, and leaves the holes unallocated", func() {
+				streamedIn, err := ioutil.ReadFile(filepath.Join(dest.Path, "mixed"))
+				Expect(err).NotTo(HaveOccurred())
+
+				original, err := ioutil.ReadFile(filepath.Join(source.Path, "mixed"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(streamedIn).To(Equal(original))
+				Expect(allocated(filepath.Join(dest.Path, "mixed"))).To(BeNumerically("<=", allocated(filepath.Join(source.Path, "mixed"))+4096))
+			})
+		})
+	}
+
+	Context("when streamed into a volume whose quota is less than the files' sizes", func() {
+		var repo volume.Repository
+
+		BeforeEach(func() {
+			repo = newRepoWithDriver(&quotaNaiveDriver{quota: 1024 * 1024}, 1)
+
+			_, err := repo.CreateVolume("dest-handle", volume.EmptyStrategy{}, volume.Properties{}, 60, true, volume.CreateOptions{SizeInBytes: 1024 * 1024})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("streams them in sparsely, as their holes don't count against it", func() {
+			streamed := new(bytes.Buffer)
+			Expect(repo.StreamOut(context.Background(), "source-handle", ".", streamed, volume.StreamOutOptions{Sparse: true})).To(Succeed())
+
+			_, err := repo.StreamIn(context.Background(), "dest-handle", ".", streamed, volume.StreamInOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("refuses them in full, without filling up the disk", func() {
+			streamed := new(bytes.Buffer)
+			Expect(repo.StreamOut(context.Background(), "source-handle", ".", streamed, volume.StreamOutOptions{})).To(Succeed())
+
+			_, err := repo.StreamIn(context.Background(), "dest-handle", ".", streamed, volume.StreamInOptions{})
+			Expect(err).To(BeAssignableToTypeOf(volume.StreamTooLargeError{}))
+		})
+	})
+})
+
+// quotaNaiveDriver reports every volume as limited to the same size, which
+// the naive driver has no way of enforcing.
+type quotaNaiveDriver struct {
+	driver.NaiveDriver
+
+	quota int64
+}
+
+func (driver *quotaNaiveDriver) SetVolumeQuota(path string, sizeInBytes int64) error {
+	return nil
+}
+
+func (driver *quotaNaiveDriver) GetVolumeQuota(path string) (int64, error) {
+	return driver.quota, nil
+}
+
+// GetVolumeSize stands in for the accounting a driver enforcing quotas
+// would keep, which the naive driver can only get by walking the volume.
+func (driver *quotaNaiveDriver) GetVolumeSize(path string) (int64, error) {
+	stats, err := driver.GetVolumeStats(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return stats.UsedBytes, nil
+}
@@ -1,9 +1,14 @@
 package volume
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/concourse/baggageclaim"
 )
@@ -16,39 +21,233 @@ const (
 	StrategyEmpty       = "empty"
 	StrategyCopyOnWrite = "cow"
 	StrategyImport      = "import"
+	StrategyCopy        = "copy"
+	StrategyView        = "view"
+	StrategyTmpfs       = "tmpfs"
+	StrategyURL         = "url"
+
+	// StrategyClone is recorded for volumes made by CloneVolume; it can't be
+	// requested when creating a volume.
+	StrategyClone = "clone"
 )
 
 var ErrNoStrategy = errors.New("no strategy given")
+var ErrNoStrategyType = errors.New("no strategy type given")
 var ErrUnknownStrategy = errors.New("unknown strategy")
+var ErrNoImportPath = errors.New("no path to import given")
+var ErrInvalidVolumeSize = errors.New("volume size must not be negative")
+var ErrSizeOfView = errors.New("a view shares its base's data and cannot be given a size of its own")
+var ErrTmpfsSizeRequired = errors.New("a tmpfs volume must be given a size")
+var ErrTmpfsSizeTooLarge = errors.New("tmpfs volume size exceeds the maximum")
+var ErrNoURL = errors.New("no URL to fetch given")
+var ErrInvalidURL = errors.New("URL to fetch must be an absolute http or https URL")
+var ErrInvalidChecksum = errors.New("checksum must be sha256: followed by 64 lowercase hex digits")
+
+// strategyFields are the fields each type of strategy may be given, besides
+// its type.
+var strategyFields = map[string][]string{
+	StrategyEmpty:       {},
+	StrategyCopyOnWrite: {"volume"},
+	StrategyImport:      {"path"},
+	StrategyView:        {"volume"},
+	StrategyTmpfs:       {},
+	StrategyURL:         {"url", "checksum"},
+}
+
+// StrategyError is returned by StrategyFor for a request it can't make a
+// strategy for, listing what is wrong with each of its fields.
+type StrategyError struct {
+	Fields []baggageclaim.FieldError
+}
+
+func (err StrategyError) Error() string {
+	problems := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		problems[i] = field.Field + ": " + field.Message
+	}
 
-type strategerizer struct{}
+	return "invalid strategy: " + strings.Join(problems, "; ")
+}
+
+func (err *StrategyError) add(field string, code string, problem error) {
+	err.Fields = append(err.Fields, baggageclaim.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: problem.Error(),
+	})
+}
 
-func NewStrategerizer() Strategerizer {
-	return &strategerizer{}
+type strategerizer struct {
+	copyThresholdInBytes int64
+	maxTmpfsSizeInBytes  int64
+	urlFetchTimeout      time.Duration
 }
 
+// NewStrategerizer returns a Strategerizer that turns COW requests into
+// copies when the request is flagged as mutation-heavy, or when its expected
+// size is at least copyThresholdInBytes. A threshold of 0 disables the size
+// check.
+//
+// Tmpfs requests are held in memory up to their size, which must be given
+// and be no more than maxTmpfsSizeInBytes. A maximum of 0 leaves them to the
+// filesystem to refuse.
+//
+// URL requests are fetched within urlFetchTimeout, or however long they take
+// if it is 0.
+func NewStrategerizer(copyThresholdInBytes int64, maxTmpfsSizeInBytes int64, urlFetchTimeout time.Duration) Strategerizer {
+	return &strategerizer{
+		copyThresholdInBytes: copyThresholdInBytes,
+		maxTmpfsSizeInBytes:  maxTmpfsSizeInBytes,
+		urlFetchTimeout:      urlFetchTimeout,
+	}
+}
+
+// StrategyFor returns a StrategyError for a request that is wrong, which
+// lists everything that is wrong with it rather than only the first thing.
 func (s *strategerizer) StrategyFor(request baggageclaim.VolumeRequest) (Strategy, error) {
+	var invalid StrategyError
+
+	if request.SizeInBytes < 0 {
+		invalid.add("size_in_bytes", baggageclaim.FieldErrorNegative, ErrInvalidVolumeSize)
+	}
+
 	if request.Strategy == nil {
-		return nil, ErrNoStrategy
+		invalid.add("strategy", baggageclaim.FieldErrorRequired, ErrNoStrategy)
+		return nil, invalid
 	}
 
 	var strategyInfo map[string]string
 	err := json.Unmarshal(*request.Strategy, &strategyInfo)
 	if err != nil {
-		return nil, fmt.Errorf("malformed strategy: %s", err)
+		invalid.add("strategy", baggageclaim.FieldErrorMalformed, fmt.Errorf("malformed strategy: %s", err))
+		return nil, invalid
+	}
+
+	strategyType := strategyInfo["type"]
+
+	fields, known := strategyFields[strategyType]
+	if !known {
+		if strategyType == "" {
+			invalid.add("strategy.type", baggageclaim.FieldErrorRequired, ErrNoStrategyType)
+		} else {
+			invalid.add("strategy.type", baggageclaim.FieldErrorUnknownStrategy, ErrUnknownStrategy)
+		}
+
+		return nil, invalid
+	}
+
+	for _, field := range unexpectedFields(strategyInfo, fields) {
+		invalid.add("strategy."+field, baggageclaim.FieldErrorConflict, fmt.Errorf("%s strategies have no %s", strategyType, field))
 	}
 
 	var strategy Strategy
-	switch strategyInfo["type"] {
+	switch strategyType {
 	case StrategyEmpty:
 		strategy = EmptyStrategy{}
 	case StrategyCopyOnWrite:
-		strategy = COWStrategy{strategyInfo["volume"]}
+		if strategyInfo["volume"] == "" {
+			invalid.add("strategy.volume", baggageclaim.FieldErrorRequired, ErrNoParentVolumeProvided)
+		}
+
+		if s.prefersCopy(request) {
+			strategy = CopyStrategy{strategyInfo["volume"]}
+		} else {
+			strategy = COWStrategy{strategyInfo["volume"]}
+		}
 	case StrategyImport:
+		if strategyInfo["path"] == "" {
+			invalid.add("strategy.path", baggageclaim.FieldErrorRequired, ErrNoImportPath)
+		}
+
 		strategy = ImportStrategy{strategyInfo["path"]}
-	default:
-		return nil, ErrUnknownStrategy
+	case StrategyView:
+		if strategyInfo["volume"] == "" {
+			invalid.add("strategy.volume", baggageclaim.FieldErrorRequired, ErrNoParentVolumeProvided)
+		}
+
+		if request.SizeInBytes > 0 {
+			invalid.add("size_in_bytes", baggageclaim.FieldErrorConflict, ErrSizeOfView)
+		}
+
+		strategy = ViewStrategy{strategyInfo["volume"]}
+	case StrategyTmpfs:
+		if request.SizeInBytes == 0 {
+			invalid.add("size_in_bytes", baggageclaim.FieldErrorRequired, ErrTmpfsSizeRequired)
+		}
+
+		if s.maxTmpfsSizeInBytes > 0 && request.SizeInBytes > s.maxTmpfsSizeInBytes {
+			invalid.add("size_in_bytes", baggageclaim.FieldErrorTooLarge, ErrTmpfsSizeTooLarge)
+		}
+
+		strategy = TmpfsStrategy{}
+	case StrategyURL:
+		if strategyInfo["url"] == "" {
+			invalid.add("strategy.url", baggageclaim.FieldErrorRequired, ErrNoURL)
+		} else if !validFetchURL(strategyInfo["url"]) {
+			invalid.add("strategy.url", baggageclaim.FieldErrorMalformed, ErrInvalidURL)
+		}
+
+		if strategyInfo["checksum"] != "" && !validChecksum(strategyInfo["checksum"]) {
+			invalid.add("strategy.checksum", baggageclaim.FieldErrorMalformed, ErrInvalidChecksum)
+		}
+
+		strategy = URLStrategy{
+			URL:      strategyInfo["url"],
+			Checksum: strategyInfo["checksum"],
+			Timeout:  s.urlFetchTimeout,
+		}
+	}
+
+	if len(invalid.Fields) > 0 {
+		return nil, invalid
 	}
 
 	return strategy, nil
 }
+
+// unexpectedFields returns the fields of the strategy, in order, that its
+// type has no use for.
+func unexpectedFields(strategyInfo map[string]string, fields []string) []string {
+	expected := map[string]bool{"type": true}
+	for _, field := range fields {
+		expected[field] = true
+	}
+
+	unexpected := []string{}
+	for field := range strategyInfo {
+		if !expected[field] {
+			unexpected = append(unexpected, field)
+		}
+	}
+
+	sort.Strings(unexpected)
+
+	return unexpected
+}
+
+func validFetchURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func validChecksum(checksum string) bool {
+	if !strings.HasPrefix(checksum, digestPrefix) {
+		return false
+	}
+
+	sum, err := hex.DecodeString(strings.TrimPrefix(checksum, digestPrefix))
+
+	return err == nil && len(sum) == 32 && strings.ToLower(checksum) == checksum
+}
+
+func (s *strategerizer) prefersCopy(request baggageclaim.VolumeRequest) bool {
+	if request.MutationHeavy {
+		return true
+	}
+
+	return s.copyThresholdInBytes > 0 && request.ExpectedSizeInBytes >= s.copyThresholdInBytes
+}
@@ -1,6 +1,10 @@
 package volume_test
 
 import (
+	"encoding/json"
+	"strings"
+	"time"
+
 	"github.com/concourse/baggageclaim"
 	"github.com/concourse/baggageclaim/baggageclaimfakes"
 	"github.com/concourse/baggageclaim/volume"
@@ -15,7 +19,7 @@ var _ = Describe("Strategerizer", func() {
 	)
 
 	BeforeEach(func() {
-		strategerizer = volume.NewStrategerizer()
+		strategerizer = volume.NewStrategerizer(1024, 4096, time.Minute)
 	})
 
 	Describe("StrategyFor", func() {
@@ -46,6 +50,193 @@ var _ = Describe("Strategerizer", func() {
 			It("constructs an empty strategy", func() {
 				Expect(strategy).To(Equal(volume.EmptyStrategy{}))
 			})
+
+			Context("when the size is negative", func() {
+				BeforeEach(func() {
+					request.SizeInBytes = -1
+				})
+
+				It("returns ErrInvalidVolumeSize for the size", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "size_in_bytes", Code: baggageclaim.FieldErrorNegative, Message: volume.ErrInvalidVolumeSize.Error()},
+					}}))
+				})
+			})
+		})
+
+		Context("without a strategy", func() {
+			It("returns ErrNoStrategy for the strategy", func() {
+				Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+					{Field: "strategy", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoStrategy.Error()},
+				}}))
+			})
+		})
+
+		Context("with a strategy that is not an object of strings", func() {
+			BeforeEach(func() {
+				request.Strategy = encodeStrategy(`{"type":"cow","volume":1}`)
+			})
+
+			It("returns a malformed strategy", func() {
+				Expect(strategyForErr).To(BeAssignableToTypeOf(volume.StrategyError{}))
+
+				fields := strategyForErr.(volume.StrategyError).Fields
+				Expect(fields).To(HaveLen(1))
+				Expect(fields[0].Field).To(Equal("strategy"))
+				Expect(fields[0].Code).To(Equal(baggageclaim.FieldErrorMalformed))
+			})
+		})
+
+		Context("with a strategy without a type", func() {
+			BeforeEach(func() {
+				request.Strategy = encodeStrategy(`{"volume":"parent-handle"}`)
+			})
+
+			It("returns ErrNoStrategyType for the type", func() {
+				Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+					{Field: "strategy.type", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoStrategyType.Error()},
+				}}))
+			})
+		})
+
+		Context("with an unknown strategy", func() {
+			BeforeEach(func() {
+				request.Strategy = encodeStrategy(`{"type":"grime"}`)
+				request.SizeInBytes = -1
+			})
+
+			It("returns ErrUnknownStrategy for the type, along with the rest of what is wrong", func() {
+				Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+					{Field: "size_in_bytes", Code: baggageclaim.FieldErrorNegative, Message: volume.ErrInvalidVolumeSize.Error()},
+					{Field: "strategy.type", Code: baggageclaim.FieldErrorUnknownStrategy, Message: volume.ErrUnknownStrategy.Error()},
+				}}))
+			})
+		})
+
+		Context("with an import strategy without a path", func() {
+			BeforeEach(func() {
+				request.Strategy = baggageclaim.ImportStrategy{}.Encode()
+			})
+
+			It("returns ErrNoImportPath for the path", func() {
+				Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+					{Field: "strategy.path", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoImportPath.Error()},
+				}}))
+			})
+		})
+
+		Context("with a view strategy", func() {
+			BeforeEach(func() {
+				volume := new(baggageclaimfakes.FakeVolume)
+				volume.HandleReturns("base-handle")
+				request.Strategy = baggageclaim.ViewStrategy{Parent: volume}.Encode()
+			})
+
+			It("constructs a view strategy", func() {
+				Expect(strategy).To(Equal(volume.ViewStrategy{BaseHandle: "base-handle"}))
+			})
+
+			Context("when a size is given", func() {
+				BeforeEach(func() {
+					request.SizeInBytes = 1024
+				})
+
+				It("returns ErrSizeOfView for the size, as the data is the base's", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "size_in_bytes", Code: baggageclaim.FieldErrorConflict, Message: volume.ErrSizeOfView.Error()},
+					}}))
+				})
+			})
+		})
+
+		Context("with a tmpfs strategy", func() {
+			BeforeEach(func() {
+				request.Strategy = baggageclaim.TmpfsStrategy{}.Encode()
+				request.SizeInBytes = 4096
+			})
+
+			It("constructs a tmpfs strategy", func() {
+				Expect(strategyForErr).ToNot(HaveOccurred())
+				Expect(strategy).To(Equal(volume.TmpfsStrategy{}))
+			})
+
+			Context("when no size is given", func() {
+				BeforeEach(func() {
+					request.SizeInBytes = 0
+				})
+
+				It("returns ErrTmpfsSizeRequired for the size", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "size_in_bytes", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrTmpfsSizeRequired.Error()},
+					}}))
+				})
+			})
+
+			Context("when the size is more than the maximum", func() {
+				BeforeEach(func() {
+					request.SizeInBytes = 4097
+				})
+
+				It("returns ErrTmpfsSizeTooLarge for the size", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "size_in_bytes", Code: baggageclaim.FieldErrorTooLarge, Message: volume.ErrTmpfsSizeTooLarge.Error()},
+					}}))
+				})
+			})
+		})
+
+		Context("with a URL strategy", func() {
+			BeforeEach(func() {
+				request.Strategy = baggageclaim.URLStrategy{
+					URL:      "https://example.com/some-archive.tgz",
+					Checksum: "sha256:" + strings.Repeat("ab", 32),
+				}.Encode()
+			})
+
+			It("constructs a URL strategy, fetched within the timeout", func() {
+				Expect(strategyForErr).ToNot(HaveOccurred())
+				Expect(strategy).To(Equal(volume.URLStrategy{
+					URL:      "https://example.com/some-archive.tgz",
+					Checksum: "sha256:" + strings.Repeat("ab", 32),
+					Timeout:  time.Minute,
+				}))
+			})
+
+			Context("without a checksum", func() {
+				BeforeEach(func() {
+					request.Strategy = baggageclaim.URLStrategy{URL: "http://example.com/some-archive.tar"}.Encode()
+				})
+
+				It("constructs a URL strategy that doesn't check one", func() {
+					Expect(strategyForErr).ToNot(HaveOccurred())
+					Expect(strategy).To(Equal(volume.URLStrategy{URL: "http://example.com/some-archive.tar", Timeout: time.Minute}))
+				})
+			})
+
+			Context("without a URL", func() {
+				BeforeEach(func() {
+					request.Strategy = baggageclaim.URLStrategy{}.Encode()
+				})
+
+				It("returns ErrNoURL for the URL", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "strategy.url", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoURL.Error()},
+					}}))
+				})
+			})
+
+			Context("with a URL that isn't http or https, and a malformed checksum", func() {
+				BeforeEach(func() {
+					request.Strategy = baggageclaim.URLStrategy{URL: "file:///etc/passwd", Checksum: "md5:abc"}.Encode()
+				})
+
+				It("returns what is wrong with both", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "strategy.url", Code: baggageclaim.FieldErrorMalformed, Message: volume.ErrInvalidURL.Error()},
+						{Field: "strategy.checksum", Code: baggageclaim.FieldErrorMalformed, Message: volume.ErrInvalidChecksum.Error()},
+					}}))
+				})
+			})
 		})
 
 		Context("with a COW strategy", func() {
@@ -62,6 +253,65 @@ var _ = Describe("Strategerizer", func() {
 			It("constructs a COW strategy", func() {
 				Expect(strategy).To(Equal(volume.COWStrategy{"parent-handle"}))
 			})
+
+			Context("when no parent is given", func() {
+				BeforeEach(func() {
+					request.Strategy = encodeStrategy(`{"type":"cow"}`)
+				})
+
+				It("returns ErrNoParentVolumeProvided for the parent", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "strategy.volume", Code: baggageclaim.FieldErrorRequired, Message: volume.ErrNoParentVolumeProvided.Error()},
+					}}))
+				})
+			})
+
+			Context("when it is given a field of another type of strategy", func() {
+				BeforeEach(func() {
+					request.Strategy = encodeStrategy(`{"type":"cow","volume":"parent-handle","path":"/some/path"}`)
+				})
+
+				It("returns a conflict for the field", func() {
+					Expect(strategyForErr).To(Equal(volume.StrategyError{Fields: []baggageclaim.FieldError{
+						{Field: "strategy.path", Code: baggageclaim.FieldErrorConflict, Message: "cow strategies have no path"},
+					}}))
+				})
+			})
+
+			Context("when the request is flagged as mutation-heavy", func() {
+				BeforeEach(func() {
+					request.MutationHeavy = true
+				})
+
+				It("constructs a copy strategy", func() {
+					Expect(strategy).To(Equal(volume.CopyStrategy{"parent-handle"}))
+				})
+			})
+
+			Context("when the expected size is at least the copy threshold", func() {
+				BeforeEach(func() {
+					request.ExpectedSizeInBytes = 1024
+				})
+
+				It("constructs a copy strategy", func() {
+					Expect(strategy).To(Equal(volume.CopyStrategy{"parent-handle"}))
+				})
+			})
+
+			Context("when the expected size is below the copy threshold", func() {
+				BeforeEach(func() {
+					request.ExpectedSizeInBytes = 1023
+				})
+
+				It("constructs a COW strategy", func() {
+					Expect(strategy).To(Equal(volume.COWStrategy{"parent-handle"}))
+				})
+			})
 		})
 	})
 })
+
+func encodeStrategy(strategy string) *json.RawMessage {
+	msg := json.RawMessage(strategy)
+	return &msg
+}
@@ -6,4 +6,8 @@ import "code.cloudfoundry.org/lager"
 
 type Strategy interface {
 	Materialize(lager.Logger, string, Filesystem) (FilesystemInitVolume, error)
+
+	// Type is the strategy type the volume was requested with, e.g.
+	// StrategyEmpty.
+	Type() string
 }
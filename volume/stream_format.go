@@ -0,0 +1,175 @@
+package volume
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os/exec"
+)
+
+var ErrUnknownStreamFormat = errors.New("stream is neither a tar archive nor a gzip, bzip2, xz, or zstd compressed one")
+var ErrUnsupportedContentEncoding = errors.New("stream content encoding is not supported")
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	// tar has no magic at the start, but POSIX and GNU headers both carry
+	// one at this offset; V7 and old GNU headers don't, so a block without
+	// it is left to tar to make sense of
+	tarMagic       = []byte("ustar")
+	tarMagicOffset = 257
+	tarBlockSize   = 512
+)
+
+// streamReadError is returned by decompressStream when the start of the
+// stream could not be read, which says nothing about its format.
+type streamReadError struct {
+	err error
+}
+
+func (err streamReadError) Error() string {
+	return err.err.Error()
+}
+
+func validContentEncoding(encoding string) bool {
+	if encoding == "" || encoding == "identity" {
+		return true
+	}
+
+	_, found := LookupCodec(encoding)
+	return found
+}
+
+// decodeContent undoes the content encoding the payload was sent with,
+// which is separate from the payload itself being compressed. The returned
+// check func reads what is left of the payload and reports whether the
+// encoding was broken.
+func decodeContent(stream io.Reader, encoding string) (io.Reader, func() error, error) {
+	codec, found := LookupCodec(encoding)
+	if !found {
+		return stream, func() error { return nil }, nil
+	}
+
+	reader, err := codec.NewReader(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoded := &errorTrackingReader{Reader: reader}
+
+	return decoded, func() error {
+		closeErr := reader.Close()
+		if decoded.err != nil {
+			return decoded.err
+		}
+
+		return closeErr
+	}, nil
+}
+
+// decompressStream sniffs the format of a stream-in payload by its magic
+// bytes and returns a reader of the plain tar stream. The returned close
+// func must be called once the tar stream has been consumed. It returns a
+// streamReadError if the stream could not be read, and
+// ErrUnknownStreamFormat if what was read is neither compressed nor
+// something tar can read a header from.
+func decompressStream(stream io.Reader) (io.Reader, func() error, error) {
+	buffered := bufio.NewReaderSize(stream, tarBlockSize)
+
+	header, err := buffered.Peek(tarBlockSize)
+	if err != nil && err != io.EOF {
+		return nil, nil, streamReadError{err}
+	}
+
+	noop := func() error { return nil }
+
+	switch {
+	case len(header) == 0:
+		// nothing to extract; let tar decide what an empty stream means
+		return buffered, noop, nil
+
+	case bytes.HasPrefix(header, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return gzipReader, gzipReader.Close, nil
+
+	case bytes.HasPrefix(header, bzip2Magic):
+		return bzip2.NewReader(buffered), noop, nil
+
+	case bytes.HasPrefix(header, xzMagic):
+		return xzReader(buffered)
+
+	case bytes.HasPrefix(header, zstdMagic):
+		codec, found := LookupCodec("zstd")
+		if !found {
+			return nil, nil, ErrUnknownStreamFormat
+		}
+
+		zstdReader, err := codec.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return zstdReader, zstdReader.Close, nil
+
+	case len(header) >= tarMagicOffset+len(tarMagic) && bytes.Equal(header[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic):
+		return buffered, noop, nil
+
+	case tarHeaderBlock(header):
+		return buffered, noop, nil
+
+	default:
+		return nil, nil, ErrUnknownStreamFormat
+	}
+}
+
+// tarHeaderBlock returns whether tar can read a header from the block, e.g.
+// a V7 one whose checksum holds, or the end of an archive. Whatever comes
+// after the header is for tar to find out about once it is extracted.
+func tarHeaderBlock(block []byte) bool {
+	if len(block) < tarBlockSize {
+		return false
+	}
+
+	_, err := tar.NewReader(bytes.NewReader(block)).Next()
+	return err != tar.ErrHeader
+}
+
+func xzReader(stream io.Reader) (io.Reader, func() error, error) {
+	xzCommand := exec.Command("xz", "--decompress", "--stdout")
+	xzCommand.Stdin = stream
+
+	stdout, err := xzCommand.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = xzCommand.Start()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stdout, func() error {
+		// tar stops reading at the end of the archive, so drain whatever
+		// is left to let xz finish and verify its checksum
+		_, drainErr := io.Copy(ioutil.Discard, stdout)
+
+		err := xzCommand.Wait()
+		if err != nil {
+			return err
+		}
+
+		return drainErr
+	}, nil
+}
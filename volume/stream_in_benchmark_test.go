@@ -0,0 +1,119 @@
+package volume_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/baggageclaim/uidgid"
+	"github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/driver"
+)
+
+// BenchmarkStreamInManySmallFiles streams an artifact of 5000 1KiB files in
+// 50 directories into a privileged volume at each extraction concurrency.
+func BenchmarkStreamInManySmallFiles(b *testing.B) {
+	artifact := manySmallFilesArtifact(b, 50, 100, 1024)
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			volumesDir, err := ioutil.TempDir("", "stream-in-benchmark")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			defer os.RemoveAll(volumesDir)
+
+			filesystem, err := volume.NewFilesystem(&driver.NaiveDriver{}, volumesDir, nil, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			repo := volume.NewRepository(
+				lagertest.NewTestLogger("benchmark"),
+				clock.NewClock(),
+				filesystem,
+				volume.NewLockManager(),
+				volume.NewPathLockManager(),
+				uidgid.NoopNamespacer{},
+				uidgid.NoopNamespacer{},
+				nil,
+				time.Minute,
+				volume.NoopDestroyAuditLog{},
+				0,
+				concurrency,
+				nil,
+				volume.NoopEventSink{},
+				volume.PropertyLimits{},
+				0,
+				volume.StreamLimits{},
+				0,
+			)
+
+			_, err = repo.CreateVolume("some-handle", volume.EmptyStrategy{}, volume.Properties{}, 0, true, volume.CreateOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(artifact)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := repo.StreamIn(context.Background(), "some-handle", fmt.Sprintf("iteration-%d", i), bytes.NewReader(artifact), volume.StreamInOptions{})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func manySmallFilesArtifact(b *testing.B, dirs int, filesPerDir int, fileSize int) []byte {
+	buffer := new(bytes.Buffer)
+	tarWriter := tar.NewWriter(buffer)
+
+	contents := bytes.Repeat([]byte("x"), fileSize)
+
+	for d := 0; d < dirs; d++ {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name:     fmt.Sprintf("dir-%d/", d),
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+			ModTime:  time.Unix(1000, 0),
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for f := 0; f < filesPerDir; f++ {
+			err := tarWriter.WriteHeader(&tar.Header{
+				Name:    fmt.Sprintf("dir-%d/file-%d", d, f),
+				Mode:    0644,
+				Size:    int64(fileSize),
+				ModTime: time.Unix(1000, 0),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, err = tarWriter.Write(contents)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	err := tarWriter.Close()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,568 @@
+package volume
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/concourse/baggageclaim"
+)
+
+var ErrInvalidWhiteout = errors.New("whiteouts must be empty entries that come before every other entry of a delta stream")
+var ErrInvalidLayerWhiteout = errors.New("whiteouts of a layer must be empty entries naming a path")
+
+// whiteoutMetaPrefix marks the entries of a layer that hold metadata of the
+// union filesystem it was made on rather than remove anything, like the
+// opaque whiteout does.
+const whiteoutMetaPrefix = baggageclaim.WhiteoutPrefix + baggageclaim.WhiteoutPrefix
+
+// NoSpaceError is returned when the disk fills up during a stream-in. What
+// the stream had extracted by then has been removed again.
+type NoSpaceError struct {
+	BytesWritten int64
+}
+
+func (err NoSpaceError) Error() string {
+	return fmt.Sprintf("no space left on device after extracting %d bytes of the stream; the partially extracted entries have been removed", err.BytesWritten)
+}
+
+// StreamTooLargeError is returned when a stream-in is found to hold more than
+// the volume has room for, by its quota or by the free space on the
+// filesystem it is on, before the disk fills up. Size is what the stream held
+// by then, or what it advertised it would hold in all. What the stream had
+// extracted by then has been removed again.
+type StreamTooLargeError struct {
+	Size uint64
+	Room uint64
+}
+
+func (err StreamTooLargeError) Error() string {
+	return fmt.Sprintf("stream holds at least %d bytes, but the volume only has room for %d; the partially extracted entries have been removed", err.Size, err.Room)
+}
+
+func isNoSpace(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
+// extractedEntries passes a tar stream on to its extraction an entry at a
+// time, checking each entry before any of it is passed on, to refuse entries
+// that would end up outside of the destination and to know which paths the
+// extraction may have written to. The whiteouts of a delta stream are
+// applied here instead, and not passed on at all; those of a layer are
+// extracted along with it, and applied once it has been. What the entries
+// hold is added up as they come, for the stream to be cut short once it is
+// more than the volume has room for.
+type extractedEntries struct {
+	pipe  *io.PipeReader
+	done  chan struct{}
+	names []string
+
+	// the paths a layer wrote to, along with the directories they are in,
+	// the whiteouts it extracted, and the paths and directories they remove
+	// what is below from
+	layered         map[string]bool
+	symlinks        map[string]bool
+	whiteoutEntries []string
+	whiteouts       []string
+	opaques         []string
+
+	bytesRead int64
+
+	// held is what the regular files extracted so far hold, less what was
+	// at their paths before; sparse files count for what they take up in the
+	// stream, leaving out their holes
+	held int64
+
+	// err is why the stream was cut short, if it was the stream's fault:
+	// ErrUnsafeTarEntry at the unsafe entry, a StreamTooLargeError, or the
+	// stream being unreadable
+	err         error
+	unsafeEntry string
+}
+
+// trackExtractedEntries follows the stream as it is extracted to dest. The
+// stream is cut short with a StreamTooLargeError once its entries hold more
+// than room, which math.MaxUint64 leaves unbounded.
+func trackExtractedEntries(stream io.Reader, dest string, root string, delta bool, layer bool, room uint64) (io.Reader, *extractedEntries) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	entries := &extractedEntries{
+		pipe:     pipeReader,
+		done:     make(chan struct{}),
+		layered:  map[string]bool{},
+		symlinks: map[string]bool{},
+	}
+
+	gate := &entryGate{
+		Reader: &countingReader{Reader: stream, count: &entries.bytesRead},
+		pipe:   pipeWriter,
+	}
+
+	go func() {
+		defer close(entries.done)
+
+		err := entries.follow(gate, dest, root, delta, layer, room)
+		if err != nil && gate.writeErr == nil {
+			entries.err = err
+		}
+
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader, entries
+}
+
+func (entries *extractedEntries) follow(gate *entryGate, dest string, root string, delta bool, layer bool, room uint64) error {
+	tarReader := tar.NewReader(gate)
+
+	// directories already found not to lead out of the volume through a
+	// symlink on disk
+	checkedDirs := map[string]bool{}
+
+	// whiteouts only come first, so that nothing already passed on to the
+	// extraction is removed from under it
+	whiteouts := delta
+
+	for {
+		gate.hold()
+
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// a global header names no path, and may say up front what the
+		// whole stream holds
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			size, err := strconv.ParseUint(header.PAXRecords[baggageclaim.StreamSizeRecord], 10, 64)
+			if err == nil && size > room {
+				return StreamTooLargeError{Size: size, Room: room}
+			}
+
+			err = gate.release()
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = checkTarEntry(header, dest, root, layer)
+		if err == nil {
+			// the destination itself, which archives of a whole volume begin
+			// with, is checked rather than what it is in
+			dir := filepath.Join(dest, header.Name)
+			if dir != filepath.Clean(dest) {
+				dir = filepath.Dir(dir)
+			}
+
+			if !checkedDirs[dir] {
+				safe, resolveErr := resolvesWithin(root, dir)
+				if resolveErr != nil {
+					return resolveErr
+				}
+
+				if !safe {
+					err = ErrUnsafeTarEntry
+				}
+
+				checkedDirs[dir] = true
+			}
+		}
+
+		if err != nil {
+			entries.unsafeEntry = header.Name
+			return err
+		}
+
+		sparse := isSparseEntry(header)
+
+		if room != math.MaxUint64 {
+			// what is replaced makes room for the entry; the layers below a
+			// layer's entries are removed for it before it is passed on
+			entries.held -= replacedSize(filepath.Join(dest, header.Name))
+
+			if (header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA) && !sparse {
+				entries.held += header.Size
+			}
+
+			err = entries.checkRoom(room)
+			if err != nil {
+				return err
+			}
+		}
+
+		if layer {
+			// whiteouts are passed on all the same, as what is held may have
+			// the end of the entry before
+			err = entries.layerEntry(header, dest)
+			if err != nil {
+				if err == ErrUnsafeTarEntry {
+					entries.unsafeEntry = header.Name
+				}
+
+				return err
+			}
+		} else if delta && strings.HasPrefix(path.Base(header.Name), baggageclaim.WhiteoutPrefix) {
+			if !whiteouts || header.Size != 0 {
+				return ErrInvalidWhiteout
+			}
+
+			err = removeWhitedOut(dest, header.Name)
+			if err != nil {
+				if err == ErrUnsafeTarEntry {
+					entries.unsafeEntry = header.Name
+				}
+
+				return err
+			}
+
+			gate.drop()
+
+			continue
+		}
+
+		whiteouts = false
+
+		entries.names = append(entries.names, header.Name)
+
+		err = gate.release()
+		if err != nil {
+			return err
+		}
+
+		// the holes of a sparse file take up neither the stream nor the
+		// disk, so it counts for what it takes up in the stream once it has
+		// been read
+		read := entries.bytesRead
+
+		_, err = io.Copy(ioutil.Discard, tarReader)
+		if err != nil {
+			return err
+		}
+
+		if sparse && room != math.MaxUint64 {
+			entries.held += entries.bytesRead - read
+
+			err = entries.checkRoom(room)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := gate.release()
+	if err != nil {
+		return err
+	}
+
+	// pass on whatever pads out the end of the archive
+	_, err = io.Copy(ioutil.Discard, gate)
+	return err
+}
+
+func (entries *extractedEntries) checkRoom(room uint64) error {
+	if entries.held > 0 && uint64(entries.held) > room {
+		return StreamTooLargeError{Size: uint64(entries.held), Room: room}
+	}
+
+	return nil
+}
+
+// isSparseEntry is whether the entry is a sparse file, in either the old GNU
+// format or the PAX one GNU tar writes.
+func isSparseEntry(header *tar.Header) bool {
+	if header.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+
+	for record := range header.PAXRecords {
+		if strings.HasPrefix(record, "GNU.sparse.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replacedSize is the size of the regular file at the path, which an entry
+// extracted to it replaces, or 0 if there is none.
+func replacedSize(path string) int64 {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+
+	return info.Size()
+}
+
+// removeWhitedOut removes what the whiteout entry stands for: the path next
+// to it named as it is without its prefix, along with everything under it.
+func removeWhitedOut(dest string, whiteout string) error {
+	name := strings.TrimPrefix(path.Base(whiteout), baggageclaim.WhiteoutPrefix)
+	if name == "" || name == "." || name == ".." {
+		return ErrInvalidWhiteout
+	}
+
+	removed := filepath.Join(dest, filepath.FromSlash(path.Dir(whiteout)), name)
+	if removed == dest || !within(dest, removed) {
+		return ErrUnsafeTarEntry
+	}
+
+	return os.RemoveAll(removed)
+}
+
+// layerEntry records an entry of a layer. A whiteout is recorded to be
+// applied, and removed, once the layer has been extracted; anything else
+// replaces what the layers below have at its path, unless both are
+// directories.
+func (entries *extractedEntries) layerEntry(header *tar.Header, dest string) error {
+	name := path.Base(header.Name)
+	entryPath := filepath.Join(dest, header.Name)
+
+	// the symlinks of a layer may point out of the volume once on disk, and
+	// may not be there yet for the check of what is written through them
+	for dir := filepath.Dir(entryPath); within(dest, dir) && dir != dest; dir = filepath.Dir(dir) {
+		if entries.symlinks[dir] {
+			return ErrUnsafeTarEntry
+		}
+	}
+
+	if strings.HasPrefix(name, baggageclaim.WhiteoutPrefix) {
+		if header.Size != 0 && !strings.HasPrefix(name, whiteoutMetaPrefix) {
+			return ErrInvalidLayerWhiteout
+		}
+
+		entries.whiteoutEntries = append(entries.whiteoutEntries, entryPath)
+
+		dir := filepath.Dir(entryPath)
+
+		switch {
+		case name == baggageclaim.OpaqueWhiteout:
+			entries.opaques = append(entries.opaques, dir)
+
+		case strings.HasPrefix(name, whiteoutMetaPrefix):
+			// e.g. the hard links aufs keeps track of, which remove nothing
+
+		default:
+			whitedOut := strings.TrimPrefix(name, baggageclaim.WhiteoutPrefix)
+			if whitedOut == "" || whitedOut == "." || whitedOut == ".." {
+				return ErrInvalidLayerWhiteout
+			}
+
+			entries.whiteouts = append(entries.whiteouts, filepath.Join(dir, whitedOut))
+		}
+
+		return nil
+	}
+
+	// what the layer already wrote under the path is kept; the extraction
+	// may still be writing it
+	if entryPath != dest && !entries.layered[entryPath] {
+		info, err := os.Lstat(entryPath)
+		if err == nil && !(info.IsDir() && header.Typeflag == tar.TypeDir) {
+			err = os.RemoveAll(entryPath)
+		}
+
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	for layered := entryPath; within(dest, layered) && !entries.layered[layered]; layered = filepath.Dir(layered) {
+		entries.layered[layered] = true
+	}
+
+	entries.symlinks[entryPath] = header.Typeflag == tar.TypeSymlink
+
+	return nil
+}
+
+// ApplyWhiteouts removes what the whiteouts of a layer stand for, leaving
+// what the layer wrote itself. It must be called once the layer has been
+// extracted, which may have put a symlink where a directory was; it returns
+// ErrUnsafeTarEntry rather than follow one out of the volume at root.
+func (entries *extractedEntries) ApplyWhiteouts(root string) error {
+	for _, whiteout := range entries.whiteoutEntries {
+		safe, err := resolvesWithin(root, filepath.Dir(whiteout))
+		if err != nil {
+			return err
+		}
+
+		if !safe {
+			return ErrUnsafeTarEntry
+		}
+
+		err = os.RemoveAll(whiteout)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, whitedOut := range entries.whiteouts {
+		safe, err := resolvesWithin(root, filepath.Dir(whitedOut))
+		if err != nil {
+			return err
+		}
+
+		if !safe {
+			return ErrUnsafeTarEntry
+		}
+
+		err = entries.prune(whitedOut)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range entries.opaques {
+		safe, err := resolvesWithin(root, dir)
+		if err != nil {
+			return err
+		}
+
+		if !safe {
+			return ErrUnsafeTarEntry
+		}
+
+		err = entries.pruneChildren(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prune removes the path unless the layer wrote to it, in which case only
+// what the layers below have in it is removed.
+func (entries *extractedEntries) prune(path string) error {
+	if !entries.layered[path] {
+		return os.RemoveAll(path)
+	}
+
+	return entries.pruneChildren(path)
+}
+
+func (entries *extractedEntries) pruneChildren(dir string) error {
+	info, err := os.Lstat(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	children, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		err := entries.prune(filepath.Join(dir, child.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops following the stream. It must be called once the extraction
+// is done with the stream, before looking at the entries.
+func (entries *extractedEntries) Stop() {
+	entries.pipe.Close()
+	<-entries.done
+}
+
+// Remove removes the entries in reverse order. Directories are only removed
+// once empty, leaving alone the ones that hold more than this stream wrote.
+func (entries *extractedEntries) Remove(dest string) {
+	for i := len(entries.names) - 1; i >= 0; i-- {
+		path := filepath.Join(dest, entries.names[i])
+		if !strings.HasPrefix(path, dest+string(filepath.Separator)) {
+			continue
+		}
+
+		os.Remove(path)
+	}
+}
+
+type countingReader struct {
+	io.Reader
+
+	count *int64
+}
+
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	*reader.count += int64(n)
+	return n, err
+}
+
+// entryGate passes on what is read through it, except while holding, when
+// it is kept back until released.
+type entryGate struct {
+	io.Reader
+
+	pipe    *io.PipeWriter
+	holding bool
+	held    bytes.Buffer
+
+	// writeErr is set once the extraction has stopped reading
+	writeErr error
+}
+
+func (gate *entryGate) Read(p []byte) (int, error) {
+	n, err := gate.Reader.Read(p)
+	if n > 0 {
+		if gate.holding {
+			gate.held.Write(p[:n])
+		} else if _, gate.writeErr = gate.pipe.Write(p[:n]); gate.writeErr != nil {
+			return n, gate.writeErr
+		}
+	}
+
+	return n, err
+}
+
+func (gate *entryGate) hold() {
+	gate.holding = true
+}
+
+// drop forgets what is held, leaving it out of what is passed on.
+func (gate *entryGate) drop() {
+	gate.held.Reset()
+}
+
+func (gate *entryGate) release() error {
+	gate.holding = false
+
+	if gate.held.Len() == 0 {
+		return nil
+	}
+
+	_, gate.writeErr = gate.pipe.Write(gate.held.Bytes())
+	gate.held.Reset()
+
+	return gate.writeErr
+}
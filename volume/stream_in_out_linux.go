@@ -1,25 +1,63 @@
 package volume
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
 )
 
-func (repo *repository) streamIn(stream io.Reader, dest string, privileged bool) (bool, error) {
-	tarCommand, dirFd, err := repo.tarIn(privileged, dest, "-x")
+func (repo *repository) streamIn(ctx context.Context, stream io.Reader, dest string, privileged bool, xattrs bool) (bool, error) {
+	// the concurrent extractor runs as root, so unprivileged volumes keep
+	// going through tar in their user namespace, as do streams whose xattrs
+	// are to be restored
+	if privileged && !xattrs && repo.streamInConcurrency > 1 {
+		return extractConcurrently(stream, dest, repo.streamInConcurrency)
+	}
+
+	var umask os.FileMode
+
+	args := []string{"-x"}
+	if xattrs {
+		// restore owners by number, as the names in a root filesystem's
+		// /etc/passwd need not match the host's
+		args = append(args, "--xattrs", "--xattrs-include=*", "--numeric-owner", "--same-permissions")
+	} else if !privileged && repo.extractionUmask != 0 {
+		// tar runs as root in the user namespace, which would otherwise
+		// keep the modes in the stream as they are
+		umask = repo.extractionUmask
+		args = append(args, "--no-same-permissions")
+	}
+
+	tarCommand, dirFd, err := repo.tarIn(ctx, privileged, umask, dest, args...)
 	if err != nil {
 		return false, err
 	}
 
 	defer dirFd.Close()
 
+	// tar carries on past failed writes, so watch for it running out of
+	// space and stop it rather than let it read the rest of the stream
+	noSpace := &noSpaceWatcher{command: tarCommand}
+
 	tarCommand.Stdin = stream
 	tarCommand.Stdout = os.Stderr
-	tarCommand.Stderr = os.Stderr
+	tarCommand.Stderr = io.MultiWriter(os.Stderr, noSpace)
+
+	err = tarCommand.Start()
+	if err != nil {
+		return false, err
+	}
+
+	err = tarCommand.Wait()
+	if noSpace.seen {
+		return false, syscall.ENOSPC
+	}
 
-	err = tarCommand.Run()
 	if err != nil {
 		if _, ok := err.(*exec.ExitError); ok {
 			return true, err
@@ -31,7 +69,7 @@ func (repo *repository) streamIn(stream io.Reader, dest string, privileged bool)
 	return false, nil
 }
 
-func (repo *repository) streamOut(w io.Writer, src string, privileged bool) error {
+func (repo *repository) streamOut(ctx context.Context, w io.Writer, src string, privileged bool, xattrs bool, sparse bool, canonical bool) error {
 	fileInfo, err := os.Stat(src)
 	if err != nil {
 		return err
@@ -47,7 +85,7 @@ func (repo *repository) streamOut(w io.Writer, src string, privileged bool) erro
 		tarCommandDir = filepath.Dir(src)
 	}
 
-	tarCommand, dirFd, err := repo.tarIn(privileged, tarCommandDir, "-c", tarCommandPath)
+	tarCommand, dirFd, err := repo.tarIn(ctx, privileged, 0, tarCommandDir, append(tarOutFlags(xattrs, sparse, canonical), tarCommandPath)...)
 	if err != nil {
 		return err
 	}
@@ -65,7 +103,79 @@ func (repo *repository) streamOut(w io.Writer, src string, privileged bool) erro
 	return nil
 }
 
-func (repo *repository) tarIn(privileged bool, dir string, args ...string) (*exec.Cmd, *os.File, error) {
+func (repo *repository) streamOutPaths(ctx context.Context, w io.Writer, src string, privileged bool, xattrs bool, sparse bool, canonical bool, walk func(func(string) error) error) error {
+	tarCommand, dirFd, err := repo.tarIn(ctx, privileged, 0, src, append(tarOutFlags(xattrs, sparse, canonical), "--no-recursion", "--null", "-T", "-")...)
+	if err != nil {
+		return err
+	}
+
+	defer dirFd.Close()
+
+	paths, err := tarCommand.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	tarCommand.Stdout = w
+	tarCommand.Stderr = os.Stderr
+
+	err = tarCommand.Start()
+	if err != nil {
+		return err
+	}
+
+	walkErr := walk(func(path string) error {
+		_, err := io.WriteString(paths, path+"\x00")
+		return err
+	})
+
+	paths.Close()
+
+	err = tarCommand.Wait()
+	if walkErr != nil {
+		return walkErr
+	}
+
+	return err
+}
+
+// tarOutFlags are the flags for tar to create an archive with, carrying every
+// xattr as a PAX record and the holes in sparse files if asked to. tar finds
+// the holes with SEEK_HOLE and SEEK_DATA where the filesystem supports them.
+//
+// Entries are sorted by name, and the PAX records leave out the access and
+// change times and tar's pid, so that an unchanged volume is streamed out as
+// the same bytes each time and a stream that was cut off can be resumed with
+// a range of them.
+//
+// A canonical archive also zeroes every mtime and leaves out the names of
+// owners, which come from whatever /etc/passwd tar sees, and is always of
+// the same format, so that it is the same bytes for any tree with the same
+// contents.
+func tarOutFlags(xattrs bool, sparse bool, canonical bool) []string {
+	flags := []string{"-c", "--sort=name"}
+
+	if xattrs {
+		flags = append(flags, "--format=posix", "--xattrs", "--xattrs-include=*", "--pax-option=exthdr.name=%d/PaxHeaders/%f,delete=atime,delete=ctime")
+	} else if canonical {
+		flags = append(flags, "--format=gnu")
+	}
+
+	if canonical {
+		flags = append(flags, "--mtime=@0", "--numeric-owner")
+	}
+
+	if sparse {
+		flags = append(flags, "--sparse")
+	}
+
+	return flags
+}
+
+// tarIn makes the tar command to run in dir, which is killed if the context
+// is done before it exits. A non-zero umask is set for tar to apply to what
+// it extracts.
+func (repo *repository) tarIn(ctx context.Context, privileged bool, umask os.FileMode, dir string, args ...string) (*exec.Cmd, *os.File, error) {
 	// 'tar' may run as MAX_UID in order to remap UIDs when streaming into an
 	// unprivileged volume. this may cause permission issues when exec'ing as it
 	// may not be able to even see the destination directory as non-root.
@@ -77,7 +187,15 @@ func (repo *repository) tarIn(privileged bool, dir string, args ...string) (*exe
 		return nil, nil, err
 	}
 
-	tarCommand := exec.Command("tar", append([]string{"-C", "/dev/fd/3"}, args...)...)
+	args = append([]string{"-C", "/dev/fd/3"}, args...)
+
+	tarCommand := exec.CommandContext(ctx, "tar", args...)
+	if umask != 0 {
+		// a command's umask can only be set by what it is run from, so it
+		// is run from a shell that sets it and then execs tar
+		tarCommand = exec.CommandContext(ctx, "sh", append([]string{"-c", `umask "$0" && exec tar "$@"`, fmt.Sprintf("%04o", umask)}, args...)...)
+	}
+
 	tarCommand.ExtraFiles = []*os.File{dirFd}
 
 	if !privileged {
@@ -86,3 +204,41 @@ func (repo *repository) tarIn(privileged bool, dir string, args ...string) (*exe
 
 	return tarCommand, dirFd, nil
 }
+
+// tar reports ENOSPC itself when creating files, and as a short write when
+// the disk fills up while writing one
+var noSpaceMessages = []string{"No space left on device", "Wrote only "}
+
+const noSpaceMessageLength = len("No space left on device")
+
+type noSpaceWatcher struct {
+	command *exec.Cmd
+
+	// the end of the previous write, in case a message is split across two
+	tail string
+	seen bool
+}
+
+func (watcher *noSpaceWatcher) Write(p []byte) (int, error) {
+	if watcher.seen {
+		return len(p), nil
+	}
+
+	output := watcher.tail + string(p)
+
+	for _, message := range noSpaceMessages {
+		if strings.Contains(output, message) {
+			watcher.seen = true
+			watcher.command.Process.Kill()
+			return len(p), nil
+		}
+	}
+
+	if len(output) > noSpaceMessageLength {
+		output = output[len(output)-noSpaceMessageLength:]
+	}
+
+	watcher.tail = output
+
+	return len(p), nil
+}
@@ -3,14 +3,21 @@
 package volume
 
 import (
+	"archive/tar"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/concourse/go-archive/tarfs"
 )
 
-func (repo *repository) streamIn(stream io.Reader, dest string, privileged bool) (bool, error) {
+func (repo *repository) streamIn(ctx context.Context, stream io.Reader, dest string, privileged bool, xattrs bool) (bool, error) {
+	if repo.streamInConcurrency > 1 {
+		return extractConcurrently(stream, dest, repo.streamInConcurrency)
+	}
+
 	err := tarfs.Extract(stream, dest)
 	if err != nil {
 		return true, err
@@ -19,21 +26,107 @@ func (repo *repository) streamIn(stream io.Reader, dest string, privileged bool)
 	return false, nil
 }
 
-func (repo *repository) streamOut(w io.Writer, src string, privileged bool) error {
+// streamOut walks src in lexical order, as tar sorts entries by name on
+// Linux, so that an unchanged volume is streamed out as the same bytes each
+// time.
+func (repo *repository) streamOut(ctx context.Context, w io.Writer, src string, privileged bool, xattrs bool, sparse bool, canonical bool) error {
 	fileInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
 
-	var tarDir, tarPath string
+	if !fileInfo.IsDir() {
+		return repo.streamOutPaths(ctx, w, filepath.Dir(src), privileged, xattrs, sparse, canonical, func(add func(string) error) error {
+			return add(filepath.Base(src))
+		})
+	}
+
+	return repo.streamOutPaths(ctx, w, src, privileged, xattrs, sparse, canonical, func(add func(string) error) error {
+		return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+
+			return add(filepath.ToSlash(rel))
+		})
+	})
+}
+
+func (repo *repository) streamOutPaths(ctx context.Context, w io.Writer, src string, privileged bool, xattrs bool, sparse bool, canonical bool, walk func(func(string) error) error) error {
+	tarWriter := tar.NewWriter(w)
+
+	err := walk(func(path string) error {
+		return writeTarEntry(tarWriter, src, path, canonical)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+func writeTarEntry(tarWriter *tar.Writer, root string, path string, canonical bool) error {
+	fullPath := filepath.Join(root, filepath.FromSlash(path))
+
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	var linkTarget string
+	if info.Mode()&os.ModeSymlink != 0 {
+		linkTarget, err = os.Readlink(fullPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, linkTarget)
+	if err != nil {
+		return err
+	}
+
+	header.Name = path
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if canonical {
+		canonicalizeTarHeader(header)
+	}
+
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
 
-	if fileInfo.IsDir() {
-		tarDir = src
-		tarPath = "."
-	} else {
-		tarDir = filepath.Dir(src)
-		tarPath = filepath.Base(src)
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return err
 	}
 
-	return tarfs.Compress(w, tarDir, tarPath)
+	defer file.Close()
+
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// canonicalizeTarHeader leaves only what is in the tree in the header, as tar
+// does on Linux for a canonical archive.
+func canonicalizeTarHeader(header *tar.Header) {
+	header.ModTime = time.Unix(0, 0)
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uname = ""
+	header.Gname = ""
+	header.Format = tar.FormatGNU
 }
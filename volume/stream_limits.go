@@ -0,0 +1,80 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+var ErrTooManyStreams = errors.New("too many streams in progress; try again later")
+
+// StreamLimits cap how many streams into and out of volumes run at once.
+// Streams in and out are capped apart, so that a flood of one can't hold up
+// the other.
+type StreamLimits struct {
+	// MaxStreamsIn is the most stream-ins that run at once. 0 leaves them
+	// unbounded.
+	MaxStreamsIn int
+
+	// MaxStreamsOut is the most stream-outs, diffs included, that run at
+	// once. 0 leaves them unbounded.
+	MaxStreamsOut int
+
+	// QueueTimeout is how long a stream waits for one of those running to
+	// finish, once there are as many as allowed, before it fails with
+	// ErrTooManyStreams. 0 fails it straight away.
+	QueueTimeout time.Duration
+}
+
+// streamSlots are the slots streams of one direction take while they run.
+// Nil slots are unbounded.
+type streamSlots chan struct{}
+
+func newStreamSlots(max int) streamSlots {
+	if max <= 0 {
+		return nil
+	}
+
+	return make(streamSlots, max)
+}
+
+// acquire takes a slot, waiting up to the timeout for one to be released. It
+// returns ErrTooManyStreams if none is, or the context's error if it is done
+// first.
+func (slots streamSlots) acquire(ctx context.Context, clock clock.Clock, timeout time.Duration) error {
+	if slots == nil {
+		return nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if timeout <= 0 {
+		return ErrTooManyStreams
+	}
+
+	timer := clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C():
+		return ErrTooManyStreams
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (slots streamSlots) release() {
+	if slots == nil {
+		return
+	}
+
+	<-slots
+}
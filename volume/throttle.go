@@ -0,0 +1,152 @@
+package volume
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// throttleSlices is how many pieces a second's worth of bytes is passed on
+// in, so that a throttled stream moves steadily rather than in bursts.
+const throttleSlices = 10
+
+const maxInt = int(^uint(0) >> 1)
+
+// throttle paces the bytes passed through it to bytesPerSecond, waiting
+// after each piece until the schedule has caught up with it. A stream that
+// stalls can only make up for a second of lost time in a burst.
+type throttle struct {
+	ctx            context.Context
+	clock          clock.Clock
+	bytesPerSecond int64
+
+	start time.Time
+	bytes int64
+}
+
+func newThrottle(ctx context.Context, clock clock.Clock, bytesPerSecond int64) *throttle {
+	return &throttle{
+		ctx:            ctx,
+		clock:          clock,
+		bytesPerSecond: bytesPerSecond,
+
+		start: clock.Now(),
+	}
+}
+
+// piece is the most that is passed on before waiting.
+func (t *throttle) piece() int {
+	piece := t.bytesPerSecond / throttleSlices
+	if piece < 1 {
+		return 1
+	}
+
+	if piece > int64(maxInt) {
+		return maxInt
+	}
+
+	return int(piece)
+}
+
+// wait blocks until n more bytes are due, or the context is done.
+func (t *throttle) wait(n int) error {
+	t.bytes += int64(n)
+
+	now := t.clock.Now()
+
+	due := t.start.Add(time.Duration(float64(t.bytes) / float64(t.bytesPerSecond) * float64(time.Second)))
+
+	if behind := now.Add(-time.Second); due.Before(behind) {
+		t.start = t.start.Add(behind.Sub(due))
+		return nil
+	}
+
+	delay := due.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := t.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	}
+}
+
+// throttledReader reads from its reader no faster than its throttle allows.
+type throttledReader struct {
+	io.Reader
+
+	throttle *throttle
+}
+
+func newThrottledReader(ctx context.Context, clock clock.Clock, reader io.Reader, bytesPerSecond int64) io.Reader {
+	return &throttledReader{
+		Reader:   reader,
+		throttle: newThrottle(ctx, clock, bytesPerSecond),
+	}
+}
+
+func (reader *throttledReader) Read(p []byte) (int, error) {
+	if piece := reader.throttle.piece(); len(p) > piece {
+		p = p[:piece]
+	}
+
+	n, err := reader.Reader.Read(p)
+	if n > 0 {
+		waitErr := reader.throttle.wait(n)
+		if waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+// throttledWriter writes to its writer no faster than its throttle allows,
+// splitting larger writes into pieces.
+type throttledWriter struct {
+	io.Writer
+
+	throttle *throttle
+}
+
+func newThrottledWriter(ctx context.Context, clock clock.Clock, writer io.Writer, bytesPerSecond int64) io.Writer {
+	return &throttledWriter{
+		Writer:   writer,
+		throttle: newThrottle(ctx, clock, bytesPerSecond),
+	}
+}
+
+func (writer *throttledWriter) Write(p []byte) (int, error) {
+	piece := writer.throttle.piece()
+
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > piece {
+			chunk = chunk[:piece]
+		}
+
+		n, err := writer.Writer.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		err = writer.throttle.wait(n)
+		if err != nil {
+			return written, err
+		}
+
+		p = p[n:]
+	}
+
+	return written, nil
+}
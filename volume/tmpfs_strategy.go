@@ -0,0 +1,13 @@
+package volume
+
+import "code.cloudfoundry.org/lager"
+
+type TmpfsStrategy struct{}
+
+func (TmpfsStrategy) Materialize(logger lager.Logger, handle string, fs Filesystem) (FilesystemInitVolume, error) {
+	return fs.NewTmpfsVolume(handle)
+}
+
+func (TmpfsStrategy) Type() string {
+	return StrategyTmpfs
+}
@@ -0,0 +1,170 @@
+package volume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrChecksumMismatch = errors.New("fetched archive does not match its checksum")
+var ErrInvalidFetchedArchive = errors.New("fetched content is not a valid tar archive")
+
+// FetchError is returned when a URL strategy's URL can't be fetched.
+// Temporary ones, e.g. the connection failing or the remote server erroring,
+// may succeed if the create is retried; others, e.g. the URL not being
+// found, won't.
+type FetchError struct {
+	URL       string
+	Err       error
+	Temporary bool
+}
+
+func (err FetchError) Error() string {
+	return fmt.Sprintf("fetching %s: %s", err.URL, err.Err)
+}
+
+// URLStrategy creates a volume from a tar archive the server fetches from
+// the URL, compressed as for a stream-in or not at all. If the Checksum is
+// given, as sha256:<hex>, the whole of what was fetched must match it, or
+// the volume is destroyed rather than created.
+type URLStrategy struct {
+	URL      string
+	Checksum string
+
+	// Timeout bounds the whole fetch. 0 leaves it unbounded.
+	Timeout time.Duration
+}
+
+func (strategy URLStrategy) Materialize(logger lager.Logger, handle string, fs Filesystem) (FilesystemInitVolume, error) {
+	initVolume, err := fs.NewVolume(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	err = strategy.fetch(logger.Session("fetch", lager.Data{"url": strategy.URL}), initVolume.DataPath())
+	if err != nil {
+		initVolume.Destroy()
+		return nil, err
+	}
+
+	return initVolume, nil
+}
+
+func (URLStrategy) Type() string {
+	return StrategyURL
+}
+
+// fetch extracts the archive into dest as it is downloaded, hashing it on
+// the way. The volume is not live until fetch returns, so nothing sees its
+// contents before they are checked.
+func (strategy URLStrategy) fetch(logger lager.Logger, dest string) error {
+	client := &http.Client{Timeout: strategy.Timeout}
+
+	response, err := client.Get(strategy.URL)
+	if err != nil {
+		return FetchError{URL: strategy.URL, Err: err, Temporary: true}
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return FetchError{
+			URL:       strategy.URL,
+			Err:       fmt.Errorf("unexpected status %s", response.Status),
+			Temporary: temporaryFetchStatus(response.StatusCode),
+		}
+	}
+
+	body := &errorTrackingReader{Reader: response.Body}
+
+	digest := sha256.New()
+	fetched := io.TeeReader(body, digest)
+
+	err = extractFetched(logger, fetched, dest)
+
+	if body.err != nil {
+		return FetchError{URL: strategy.URL, Err: body.err, Temporary: true}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if strategy.Checksum != "" {
+		actual := digestPrefix + hex.EncodeToString(digest.Sum(nil))
+		if actual != strategy.Checksum {
+			logger.Info("checksum-mismatch", lager.Data{"expected": strategy.Checksum, "actual": actual})
+			return ErrChecksumMismatch
+		}
+	}
+
+	return nil
+}
+
+// extractFetched extracts the archive into dest, then reads the rest of what
+// was fetched, as tar stops at the end of the archive but the checksum is of
+// all of it. It returns ErrInvalidFetchedArchive for a broken archive, or one
+// with entries that would be extracted outside of dest, which is only down
+// to the archive if reading what was fetched didn't fail.
+func extractFetched(logger lager.Logger, fetched io.Reader, dest string) error {
+	tarStream, closeStream, err := decompressStream(fetched)
+	if err == ErrUnknownStreamFormat {
+		return err
+	}
+
+	if readErr, ok := err.(streamReadError); ok {
+		return readErr.err
+	}
+
+	if err != nil {
+		logger.Info("invalid-compression", lager.Data{"error": err.Error()})
+		return ErrInvalidFetchedArchive
+	}
+
+	// the archive is extracted as root, so its entries are checked as those
+	// of a stream-in are, leaving out any that would reach outside of dest
+	trackedStream, entries := trackExtractedEntries(tarStream, dest, dest, false, false, math.MaxUint64)
+
+	badStream, err := extractConcurrently(trackedStream, dest, 1)
+
+	entries.Stop()
+
+	if err != nil {
+		closeStream()
+
+		if entries.err == ErrUnsafeTarEntry {
+			logger.Info("unsafe-tar-entry", lager.Data{"entry": entries.unsafeEntry})
+			return ErrInvalidFetchedArchive
+		}
+
+		if badStream || entries.err != nil {
+			logger.Info("invalid-archive", lager.Data{"error": err.Error()})
+			return ErrInvalidFetchedArchive
+		}
+
+		return err
+	}
+
+	err = closeStream()
+	if err != nil {
+		logger.Info("invalid-compression", lager.Data{"error": err.Error()})
+		return ErrInvalidFetchedArchive
+	}
+
+	_, err = io.Copy(ioutil.Discard, fetched)
+	return err
+}
+
+// temporaryFetchStatus returns whether the remote server may respond
+// otherwise if asked again.
+func temporaryFetchStatus(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+}
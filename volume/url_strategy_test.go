@@ -0,0 +1,253 @@
+package volume_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("URLStrategy", func() {
+	var (
+		server   *ghttp.Server
+		dataPath string
+
+		fakeFilesystem *volumefakes.FakeFilesystem
+		fakeVolume     *volumefakes.FakeFilesystemInitVolume
+
+		archive  []byte
+		strategy URLStrategy
+
+		materializedVolume FilesystemInitVolume
+		materializeErr     error
+	)
+
+	tarball := func(contents string) []byte {
+		buffer := new(bytes.Buffer)
+
+		tarWriter := tar.NewWriter(buffer)
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-dir/", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "some-dir/some-file", Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tarWriter.Write([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tarWriter.Close()).To(Succeed())
+
+		return buffer.Bytes()
+	}
+
+	checksum := func(contents []byte) string {
+		sum := sha256.Sum256(contents)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		var err error
+		dataPath, err = ioutil.TempDir("", "url-strategy")
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeVolume = new(volumefakes.FakeFilesystemInitVolume)
+		fakeVolume.DataPathReturns(dataPath)
+
+		fakeFilesystem = new(volumefakes.FakeFilesystem)
+		fakeFilesystem.NewVolumeReturns(fakeVolume, nil)
+
+		archive = tarball("some-contents")
+		strategy = URLStrategy{URL: server.URL() + "/some-archive.tar"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(dataPath)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		materializedVolume, materializeErr = strategy.Materialize(
+			lagertest.NewTestLogger("test"),
+			"some-volume",
+			fakeFilesystem,
+		)
+	})
+
+	Context("when the archive is fetched", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/some-archive.tar"),
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Write(archive)
+				},
+			))
+		})
+
+		It("extracts it into the new volume", func() {
+			Expect(materializeErr).NotTo(HaveOccurred())
+			Expect(materializedVolume).To(Equal(fakeVolume))
+			Expect(fakeFilesystem.NewVolumeArgsForCall(0)).To(Equal("some-volume"))
+
+			contents, err := ioutil.ReadFile(filepath.Join(dataPath, "some-dir", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+
+		Context("when it is compressed", func() {
+			BeforeEach(func() {
+				compressed := new(bytes.Buffer)
+				gzipWriter := gzip.NewWriter(compressed)
+				_, err := gzipWriter.Write(archive)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gzipWriter.Close()).To(Succeed())
+
+				archive = compressed.Bytes()
+				strategy.Checksum = checksum(archive)
+			})
+
+			It("decompresses it, checking the checksum of what was fetched", func() {
+				Expect(materializeErr).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(dataPath, "some-dir", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-contents"))
+			})
+		})
+
+		Context("when it matches the checksum", func() {
+			BeforeEach(func() {
+				strategy.Checksum = checksum(archive)
+			})
+
+			It("keeps the volume", func() {
+				Expect(materializeErr).NotTo(HaveOccurred())
+				Expect(fakeVolume.DestroyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when it does not match the checksum", func() {
+			BeforeEach(func() {
+				strategy.Checksum = checksum(tarball("other-contents"))
+			})
+
+			It("returns ErrChecksumMismatch, destroying the volume", func() {
+				Expect(materializeErr).To(Equal(ErrChecksumMismatch))
+				Expect(materializedVolume).To(BeNil())
+				Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when it is not an archive", func() {
+			BeforeEach(func() {
+				archive = []byte("<html>some error page</html>")
+			})
+
+			It("returns ErrUnknownStreamFormat, destroying the volume", func() {
+				Expect(materializeErr).To(Equal(ErrUnknownStreamFormat))
+				Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when it has a symlink out of the volume in place of a directory", func() {
+			var hostFile string
+
+			BeforeEach(func() {
+				file, err := ioutil.TempFile("", "url-strategy-host-file")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(file.Close()).To(Succeed())
+
+				hostFile = file.Name()
+				Expect(os.Chmod(hostFile, 0600)).To(Succeed())
+
+				buffer := new(bytes.Buffer)
+
+				tarWriter := tar.NewWriter(buffer)
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "a/", Mode: 0777, Typeflag: tar.TypeDir})).To(Succeed())
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "a", Linkname: hostFile, Mode: 0777, Typeflag: tar.TypeSymlink})).To(Succeed())
+				Expect(tarWriter.Close()).To(Succeed())
+
+				archive = buffer.Bytes()
+			})
+
+			AfterEach(func() {
+				Expect(os.Remove(hostFile)).To(Succeed())
+			})
+
+			It("returns ErrInvalidFetchedArchive without touching what it points to", func() {
+				Expect(materializeErr).To(Equal(ErrInvalidFetchedArchive))
+				Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+
+				info, err := os.Stat(hostFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+				info, err = os.Lstat(filepath.Join(dataPath, "a"))
+				if err == nil {
+					Expect(info.Mode() & os.ModeSymlink).To(BeZero())
+				}
+			})
+		})
+
+		Context("when it is cut short", func() {
+			BeforeEach(func() {
+				archive = archive[:600]
+			})
+
+			It("returns ErrInvalidFetchedArchive", func() {
+				Expect(materializeErr).To(Equal(ErrInvalidFetchedArchive))
+				Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("when the remote server refuses the request", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, "not here"))
+		})
+
+		It("returns a FetchError that is not temporary", func() {
+			fetchErr, ok := materializeErr.(FetchError)
+			Expect(ok).To(BeTrue())
+			Expect(fetchErr.URL).To(Equal(strategy.URL))
+			Expect(fetchErr.Temporary).To(BeFalse())
+
+			Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the remote server fails", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable, "try again"))
+		})
+
+		It("returns a temporary FetchError", func() {
+			fetchErr, ok := materializeErr.(FetchError)
+			Expect(ok).To(BeTrue())
+			Expect(fetchErr.Temporary).To(BeTrue())
+		})
+	})
+
+	Context("when the remote server can't be reached", func() {
+		BeforeEach(func() {
+			server.Close()
+		})
+
+		It("returns a temporary FetchError", func() {
+			fetchErr, ok := materializeErr.(FetchError)
+			Expect(ok).To(BeTrue())
+			Expect(fetchErr.Temporary).To(BeTrue())
+
+			Expect(fakeVolume.DestroyCallCount()).To(Equal(1))
+		})
+	})
+})
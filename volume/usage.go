@@ -0,0 +1,35 @@
+package volume
+
+// Usage is how much of the volumes filesystem the volumes use, as the sum of
+// their sizes. Those sizes are what their stats report, so on btrfs data a
+// COW volume shares with its parent is counted for neither.
+type Usage struct {
+	UsedBytes int64  `json:"used_bytes"`
+	FreeBytes uint64 `json:"free_bytes"`
+	Volumes   int    `json:"volumes"`
+
+	// GroupedBy is the property the volumes were grouped by, if any. Groups
+	// are keyed by the volumes' values for it, and Ungrouped counts the
+	// volumes that don't have it.
+	GroupedBy string                `json:"grouped_by,omitempty"`
+	Groups    map[string]UsageGroup `json:"groups,omitempty"`
+	Ungrouped *UsageGroup           `json:"ungrouped,omitempty"`
+
+	// Failed lists the volumes that could not be sized, which are left out.
+	Failed []string `json:"failed,omitempty"`
+}
+
+type UsageGroup struct {
+	UsedBytes int64 `json:"used_bytes"`
+	Volumes   int   `json:"volumes"`
+}
+
+func (usage *Usage) add(size int64) {
+	usage.UsedBytes += size
+	usage.Volumes++
+}
+
+func (group *UsageGroup) add(size int64) {
+	group.UsedBytes += size
+	group.Volumes++
+}
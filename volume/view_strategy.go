@@ -0,0 +1,62 @@
+package volume
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrParentVolumeIsView = errors.New("cannot create a view of a view")
+
+// ViewStrategy creates a read-only view onto the base volume's data. No data
+// is copied: the base is kept around until its last view is destroyed.
+type ViewStrategy struct {
+	BaseHandle string
+}
+
+func (strategy ViewStrategy) Materialize(logger lager.Logger, handle string, fs Filesystem) (FilesystemInitVolume, error) {
+	if strategy.BaseHandle == "" {
+		logger.Info("parent-not-specified")
+		return nil, ErrNoParentVolumeProvided
+	}
+
+	baseVolume, found, err := fs.LookupVolume(strategy.BaseHandle)
+	if err != nil {
+		logger.Error("failed-to-lookup-parent", err)
+		return nil, err
+	}
+
+	if !found {
+		logger.Info("parent-not-found")
+		return nil, ErrParentVolumeNotFound
+	}
+
+	isView, err := baseVolume.IsView()
+	if err != nil {
+		logger.Error("failed-to-check-parent", err)
+		return nil, err
+	}
+
+	if isView {
+		logger.Info("parent-is-view")
+		return nil, ErrParentVolumeIsView
+	}
+
+	_, released, err := baseVolume.LoadReleased()
+	if err != nil {
+		logger.Error("failed-to-check-parent", err)
+		return nil, err
+	}
+
+	// a released base is only waiting on its views to go away
+	if released {
+		logger.Info("parent-released")
+		return nil, ErrParentVolumeNotFound
+	}
+
+	return baseVolume.NewView(handle)
+}
+
+func (ViewStrategy) Type() string {
+	return StrategyView
+}
@@ -0,0 +1,111 @@
+package volume_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/concourse/baggageclaim/volume"
+	"github.com/concourse/baggageclaim/volume/volumefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ViewStrategy", func() {
+	var (
+		strategy Strategy
+	)
+
+	BeforeEach(func() {
+		strategy = ViewStrategy{"base-volume"}
+	})
+
+	Describe("Materialize", func() {
+		var (
+			fakeFilesystem *volumefakes.FakeFilesystem
+
+			materializedVolume FilesystemInitVolume
+			materializeErr     error
+		)
+
+		BeforeEach(func() {
+			fakeFilesystem = new(volumefakes.FakeFilesystem)
+		})
+
+		JustBeforeEach(func() {
+			materializedVolume, materializeErr = strategy.Materialize(
+				lagertest.NewTestLogger("test"),
+				"some-volume",
+				fakeFilesystem,
+			)
+		})
+
+		Context("when the base volume can be found", func() {
+			var baseVolume *volumefakes.FakeFilesystemLiveVolume
+
+			BeforeEach(func() {
+				baseVolume = new(volumefakes.FakeFilesystemLiveVolume)
+				fakeFilesystem.LookupVolumeReturns(baseVolume, true, nil)
+			})
+
+			Context("when it is a plain volume", func() {
+				var fakeVolume *volumefakes.FakeFilesystemInitVolume
+
+				BeforeEach(func() {
+					fakeVolume = new(volumefakes.FakeFilesystemInitVolume)
+					baseVolume.NewViewReturns(fakeVolume, nil)
+				})
+
+				It("returns a view of it with the correct handle", func() {
+					Expect(materializeErr).ToNot(HaveOccurred())
+					Expect(materializedVolume).To(Equal(fakeVolume))
+					Expect(baseVolume.NewViewArgsForCall(0)).To(Equal("some-volume"))
+				})
+
+				It("looked up the base with the correct handle", func() {
+					Expect(fakeFilesystem.LookupVolumeArgsForCall(0)).To(Equal("base-volume"))
+				})
+			})
+
+			Context("when it is itself a view", func() {
+				BeforeEach(func() {
+					baseVolume.IsViewReturns(true, nil)
+				})
+
+				It("returns ErrParentVolumeIsView", func() {
+					Expect(materializeErr).To(Equal(ErrParentVolumeIsView))
+					Expect(baseVolume.NewViewCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when it has been released", func() {
+				BeforeEach(func() {
+					baseVolume.LoadReleasedReturns(DestroyOptions{}, true, nil)
+				})
+
+				It("returns ErrParentVolumeNotFound", func() {
+					Expect(materializeErr).To(Equal(ErrParentVolumeNotFound))
+					Expect(baseVolume.NewViewCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("when the base volume can not be found", func() {
+			BeforeEach(func() {
+				fakeFilesystem.LookupVolumeReturns(nil, false, nil)
+			})
+
+			It("returns ErrParentVolumeNotFound", func() {
+				Expect(materializeErr).To(Equal(ErrParentVolumeNotFound))
+			})
+		})
+
+		Context("when no base is given", func() {
+			BeforeEach(func() {
+				strategy = ViewStrategy{}
+			})
+
+			It("returns ErrNoParentVolumeProvided", func() {
+				Expect(materializeErr).To(Equal(ErrNoParentVolumeProvided))
+			})
+		})
+	})
+})
@@ -9,6 +9,215 @@ type Volume struct {
 	TTL        TTL        `json:"ttl,omitempty"`
 	ExpiresAt  time.Time  `json:"expires_at"`
 	Privileged bool       `json:"privileged"`
+
+	// ParentHandle is the handle of the volume a copy-on-write volume was
+	// created from, whose data it shares. It is empty for other volumes.
+	ParentHandle string `json:"parent_handle"`
+
+	// PendingDestroy is set once the TTL has expired; the volume may still be
+	// rescued by setting a new TTL until the reaper destroys it, which happens
+	// once its grace period has passed as well.
+	PendingDestroy bool `json:"pending_destroy"`
+
+	// RenewTTLOnAccess is set for volumes whose TTL starts over each time
+	// they are streamed out, looked up, or touched, rather than only when it
+	// is set.
+	RenewTTLOnAccess bool `json:"renew_ttl_on_access,omitempty"`
+
+	Committed   bool      `json:"committed"`
+	CommittedAt time.Time `json:"committed_at"`
+	Frozen      bool      `json:"frozen"`
+
+	// ReadOnly is set for volumes created read-only, which are frozen from
+	// the start and, where the driver can, mounted read-only as well.
+	ReadOnly bool `json:"read_only"`
+
+	// MountOptions are the mount options the volume was given of its own.
+	MountOptions []string `json:"mount_options,omitempty"`
+
+	// Encrypted is set for volumes whose data is encrypted where it is
+	// stored, and for views of them.
+	Encrypted bool `json:"encrypted"`
+
+	// Driver is the driver that created the volume, and FilesystemType the
+	// type of filesystem its data was on then, e.g. "btrfs" or "ext4".
+	Driver         string `json:"driver"`
+	FilesystemType string `json:"filesystem_type"`
+
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+
+	// CreatedAt is when the volume was created, and ModifiedAt when its
+	// properties or contents were last changed through the API. For volumes
+	// created before they were recorded, both are taken from the mtime of
+	// their data. Strategy is empty for those.
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Strategy   string    `json:"strategy"`
+
+	// Digest is the digest of the volume's contents recorded by the last
+	// stream-in, if there has been one.
+	Digest string `json:"digest,omitempty"`
+
+	// Deleted is set for volumes that are kept around after being destroyed
+	// until they are restored or purged, and DeletedAt is when they were
+	// destroyed. Only deleted volumes are listed as such.
+	Deleted   bool      `json:"deleted,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
 }
 
 type Volumes []Volume
+
+type CreateOptions struct {
+	// SizeInBytes, if positive, is the quota the volume is created with.
+	SizeInBytes int64
+
+	// ReadOnly keeps the volume's data from being written to once it is
+	// created, and freezes it.
+	ReadOnly bool
+
+	// MountOptions are given to the volume's data, out of those its driver
+	// allows; a MountOptionsError is returned if it cannot be given them.
+	MountOptions []string
+
+	// RenewTTLOnAccess has the volume's TTL start over each time it is
+	// streamed out, looked up, or touched.
+	RenewTTLOnAccess bool
+
+	// IdempotencyKey identifies the create across retries. A create with
+	// the key of a volume that was created with it returns that volume
+	// rather than creating another, whatever the handle, as long as it asks
+	// for the same volume otherwise; ErrIdempotencyKeyConflict is returned
+	// if it does not.
+	IdempotencyKey string
+
+	// Encrypted has the volume's data encrypted with a key of its own, which
+	// only strategies that create the volume afresh or from an encrypted
+	// parent can do; ErrStrategyNotEncryptable is returned for others.
+	Encrypted bool
+}
+
+type StreamInOptions struct {
+	// IdempotencyKey identifies the stream across retries. A stream whose key
+	// was already applied to the volume within the idempotency window is not
+	// applied again.
+	IdempotencyKey string
+
+	// SELinuxLabel, if set, is applied to everything streamed in. It is
+	// ignored on systems without SELinux.
+	SELinuxLabel string
+
+	// ContentEncoding is the encoding the stream was sent with: "identity"
+	// or the name of a registered Codec. The stream is decoded before its
+	// format is sniffed.
+	ContentEncoding string
+
+	// Xattrs restores the extended attributes carried in the stream's PAX
+	// records, e.g. file capabilities, along with owners by number and
+	// modes as they are. Owners are still remapped into unprivileged
+	// volumes. It is ignored on systems other than Linux.
+	Xattrs bool
+
+	// Delta applies the stream on top of what is already at the path, like
+	// any other, except that its leading whiteouts remove what they name.
+	// The removals are not undone should the rest of the stream fail.
+	Delta bool
+
+	// Layer applies the stream as an OCI or Docker image layer on top of
+	// what is already at the path. Its whiteouts, wherever they are in the
+	// stream, remove what they name once the rest of it has been extracted,
+	// keeping what the layer itself wrote, and each of its entries replaces
+	// what is at its path unless both are directories. Absolute symlinks
+	// are taken to point into the path, as they would in a container, rather
+	// than be refused. Delta is ignored with it, and the removals are not
+	// undone should the stream fail.
+	Layer bool
+
+	// BytesPerSecond, if positive, caps how fast the stream is read, before
+	// it is decoded. The stream is read as fast as it comes otherwise.
+	BytesPerSecond int64
+
+	// LeaseToken is the token of the lease held on the volume, if any; the
+	// stream-in fails with ErrVolumeIsLeased otherwise.
+	LeaseToken string
+
+	// Checksum, if set, gives the sha256:<hex> digest the stream must have
+	// once decoded and decompressed, which is the digest of the tar itself.
+	// It is asked for again once all of the stream has been read, so that
+	// it may only be known by then, e.g. if it was sent in a trailer. A
+	// stream that doesn't match is removed as one that fails partway is,
+	// before a layer's whiteouts are applied, though a delta's have already
+	// been, and ErrStreamChecksumMismatch returned. An empty checksum leaves
+	// the stream unchecked.
+	Checksum func() string
+}
+
+type CopyInOptions struct {
+	// SourcePath is the path in the source volume to copy. A directory's
+	// contents are copied into the destination path, as a stream-in of its
+	// stream-out would be, and anything else is copied into it by its name.
+	SourcePath string
+
+	// LeaseToken is the token of the lease held on the destination volume,
+	// if any; the copy fails with ErrVolumeIsLeased otherwise.
+	LeaseToken string
+}
+
+type StreamOutFormat string
+
+const (
+	StreamOutTar  StreamOutFormat = "tar"
+	StreamOutFile StreamOutFormat = "file"
+)
+
+type StreamOutOptions struct {
+	// Format is how the path is streamed. StreamOutTar always tars it, and
+	// StreamOutFile streams the bytes of the regular file at it. Without
+	// one, regular files are streamed as files unless an option below that
+	// only applies to tars is given, and everything else as a tar.
+	Format StreamOutFormat
+
+	// StreamedAs, if set, is told the format the path is streamed in before
+	// any of it is written.
+	StreamedAs *StreamOutFormat
+
+	// ModifiedSince limits the stream to entries whose mtime is after the
+	// given time; directories are always included. Note that mtime
+	// granularity depends on the filesystem (e.g. 1s on ext3, 2s on FAT, 1ns
+	// on ext4/btrfs), that extracting a tar preserves the archived mtimes
+	// rather than the extraction time, and that renames and chmods only touch
+	// ctime, so such changes are not picked up.
+	ModifiedSince time.Time
+
+	// Consistent waits for in-flight stream-ins and blocks new ones, then
+	// streams from a snapshot of the volume if the driver can take one, or
+	// otherwise from the volume after flushing it to disk. Writes that do
+	// not go through baggageclaim, e.g. from a container the volume is
+	// mounted into, are still seen unless the driver took a snapshot.
+	Consistent bool
+
+	// Downgrade, if set, makes the stream safe to extract into an
+	// unprivileged volume: setuid and setgid bits are cleared and device
+	// nodes are left out. What was changed is counted in it.
+	Downgrade *DowngradeReport
+
+	// Xattrs carries every extended attribute in the stream as PAX records.
+	// It is ignored on systems other than Linux.
+	Xattrs bool
+
+	// Sparse carries the holes in files as GNU sparse entries, found with
+	// SEEK_HOLE and SEEK_DATA, rather than as runs of zeros. It is ignored
+	// when downgrading, which rewrites the stream in full, and on systems
+	// other than Linux.
+	Sparse bool
+
+	// Canonical streams a tar of only what is in the tree, so that identical
+	// trees are streamed out as the same bytes wherever and whenever they
+	// were written: entries in name order, as they always are, but with
+	// every mtime zeroed and owners by number alone. It costs nothing over
+	// an ordinary stream, but loses the mtimes. It is a tar option.
+	Canonical bool
+
+	// BytesPerSecond, if positive, caps how fast the stream is written, before
+	// it is encoded. The stream is written as fast as it is read otherwise.
+	BytesPerSecond int64
+}
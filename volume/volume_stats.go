@@ -2,4 +2,20 @@ package volume
 
 type VolumeStats struct {
 	SizeInBytes int64 `json:"size_in_bytes"`
+	FileCount   int64 `json:"file_count"`
+
+	// SizeExact is whether SizeInBytes was read from the backing store's own
+	// accounting rather than estimated by walking the volume.
+	SizeExact bool `json:"size_exact"`
+
+	// FreeBytes is what is free on the volume's backing store, or 0 if it
+	// can't be found.
+	FreeBytes uint64 `json:"free_bytes"`
+
+	// QuotaInBytes is the volume's size limit, or 0 if it has none.
+	QuotaInBytes int64 `json:"quota_in_bytes,omitempty"`
+
+	// Driver and FilesystemType are what the volume's data is backed by.
+	Driver         string `json:"driver"`
+	FilesystemType string `json:"filesystem_type"`
 }
@@ -30,17 +30,17 @@ type FakeDriver struct {
 	destroyVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetVolumeSizeInBytesStub        func(path string) (int64, error)
-	getVolumeSizeInBytesMutex       sync.RWMutex
-	getVolumeSizeInBytesArgsForCall []struct {
+	GetVolumeStatsStub        func(path string) (volume.DriverStats, error)
+	getVolumeStatsMutex       sync.RWMutex
+	getVolumeStatsArgsForCall []struct {
 		path string
 	}
-	getVolumeSizeInBytesReturns struct {
-		result1 int64
+	getVolumeStatsReturns struct {
+		result1 volume.DriverStats
 		result2 error
 	}
-	getVolumeSizeInBytesReturnsOnCall map[int]struct {
-		result1 int64
+	getVolumeStatsReturnsOnCall map[int]struct {
+		result1 volume.DriverStats
 		result2 error
 	}
 	CreateCopyOnWriteLayerStub        func(path string, parent string) error
@@ -55,6 +55,15 @@ type FakeDriver struct {
 	createCopyOnWriteLayerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	NameStub        func() string
+	nameMutex       sync.RWMutex
+	nameArgsForCall []struct{}
+	nameReturns     struct {
+		result1 string
+	}
+	nameReturnsOnCall map[int]struct {
+		result1 string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -155,53 +164,53 @@ func (fake *FakeDriver) DestroyVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeDriver) GetVolumeSizeInBytes(path string) (int64, error) {
-	fake.getVolumeSizeInBytesMutex.Lock()
-	ret, specificReturn := fake.getVolumeSizeInBytesReturnsOnCall[len(fake.getVolumeSizeInBytesArgsForCall)]
-	fake.getVolumeSizeInBytesArgsForCall = append(fake.getVolumeSizeInBytesArgsForCall, struct {
+func (fake *FakeDriver) GetVolumeStats(path string) (volume.DriverStats, error) {
+	fake.getVolumeStatsMutex.Lock()
+	ret, specificReturn := fake.getVolumeStatsReturnsOnCall[len(fake.getVolumeStatsArgsForCall)]
+	fake.getVolumeStatsArgsForCall = append(fake.getVolumeStatsArgsForCall, struct {
 		path string
 	}{path})
-	fake.recordInvocation("GetVolumeSizeInBytes", []interface{}{path})
-	fake.getVolumeSizeInBytesMutex.Unlock()
-	if fake.GetVolumeSizeInBytesStub != nil {
-		return fake.GetVolumeSizeInBytesStub(path)
+	fake.recordInvocation("GetVolumeStats", []interface{}{path})
+	fake.getVolumeStatsMutex.Unlock()
+	if fake.GetVolumeStatsStub != nil {
+		return fake.GetVolumeStatsStub(path)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.getVolumeSizeInBytesReturns.result1, fake.getVolumeSizeInBytesReturns.result2
+	return fake.getVolumeStatsReturns.result1, fake.getVolumeStatsReturns.result2
 }
 
-func (fake *FakeDriver) GetVolumeSizeInBytesCallCount() int {
-	fake.getVolumeSizeInBytesMutex.RLock()
-	defer fake.getVolumeSizeInBytesMutex.RUnlock()
-	return len(fake.getVolumeSizeInBytesArgsForCall)
+func (fake *FakeDriver) GetVolumeStatsCallCount() int {
+	fake.getVolumeStatsMutex.RLock()
+	defer fake.getVolumeStatsMutex.RUnlock()
+	return len(fake.getVolumeStatsArgsForCall)
 }
 
-func (fake *FakeDriver) GetVolumeSizeInBytesArgsForCall(i int) string {
-	fake.getVolumeSizeInBytesMutex.RLock()
-	defer fake.getVolumeSizeInBytesMutex.RUnlock()
-	return fake.getVolumeSizeInBytesArgsForCall[i].path
+func (fake *FakeDriver) GetVolumeStatsArgsForCall(i int) string {
+	fake.getVolumeStatsMutex.RLock()
+	defer fake.getVolumeStatsMutex.RUnlock()
+	return fake.getVolumeStatsArgsForCall[i].path
 }
 
-func (fake *FakeDriver) GetVolumeSizeInBytesReturns(result1 int64, result2 error) {
-	fake.GetVolumeSizeInBytesStub = nil
-	fake.getVolumeSizeInBytesReturns = struct {
-		result1 int64
+func (fake *FakeDriver) GetVolumeStatsReturns(result1 volume.DriverStats, result2 error) {
+	fake.GetVolumeStatsStub = nil
+	fake.getVolumeStatsReturns = struct {
+		result1 volume.DriverStats
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeDriver) GetVolumeSizeInBytesReturnsOnCall(i int, result1 int64, result2 error) {
-	fake.GetVolumeSizeInBytesStub = nil
-	if fake.getVolumeSizeInBytesReturnsOnCall == nil {
-		fake.getVolumeSizeInBytesReturnsOnCall = make(map[int]struct {
-			result1 int64
+func (fake *FakeDriver) GetVolumeStatsReturnsOnCall(i int, result1 volume.DriverStats, result2 error) {
+	fake.GetVolumeStatsStub = nil
+	if fake.getVolumeStatsReturnsOnCall == nil {
+		fake.getVolumeStatsReturnsOnCall = make(map[int]struct {
+			result1 volume.DriverStats
 			result2 error
 		})
 	}
-	fake.getVolumeSizeInBytesReturnsOnCall[i] = struct {
-		result1 int64
+	fake.getVolumeStatsReturnsOnCall[i] = struct {
+		result1 volume.DriverStats
 		result2 error
 	}{result1, result2}
 }
@@ -255,6 +264,46 @@ func (fake *FakeDriver) CreateCopyOnWriteLayerReturnsOnCall(i int, result1 error
 	}{result1}
 }
 
+func (fake *FakeDriver) Name() string {
+	fake.nameMutex.Lock()
+	ret, specificReturn := fake.nameReturnsOnCall[len(fake.nameArgsForCall)]
+	fake.nameArgsForCall = append(fake.nameArgsForCall, struct{}{})
+	fake.recordInvocation("Name", []interface{}{})
+	fake.nameMutex.Unlock()
+	if fake.NameStub != nil {
+		return fake.NameStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.nameReturns.result1
+}
+
+func (fake *FakeDriver) NameCallCount() int {
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	return len(fake.nameArgsForCall)
+}
+
+func (fake *FakeDriver) NameReturns(result1 string) {
+	fake.NameStub = nil
+	fake.nameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDriver) NameReturnsOnCall(i int, result1 string) {
+	fake.NameStub = nil
+	if fake.nameReturnsOnCall == nil {
+		fake.nameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.nameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeDriver) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -262,10 +311,12 @@ func (fake *FakeDriver) Invocations() map[string][][]interface{} {
 	defer fake.createVolumeMutex.RUnlock()
 	fake.destroyVolumeMutex.RLock()
 	defer fake.destroyVolumeMutex.RUnlock()
-	fake.getVolumeSizeInBytesMutex.RLock()
-	defer fake.getVolumeSizeInBytesMutex.RUnlock()
+	fake.getVolumeStatsMutex.RLock()
+	defer fake.getVolumeStatsMutex.RUnlock()
 	fake.createCopyOnWriteLayerMutex.RLock()
 	defer fake.createCopyOnWriteLayerMutex.RUnlock()
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -21,6 +21,32 @@ type FakeFilesystem struct {
 		result1 volume.FilesystemInitVolume
 		result2 error
 	}
+	NewTmpfsVolumeStub        func(string) (volume.FilesystemInitVolume, error)
+	newTmpfsVolumeMutex       sync.RWMutex
+	newTmpfsVolumeArgsForCall []struct {
+		arg1 string
+	}
+	newTmpfsVolumeReturns struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	newTmpfsVolumeReturnsOnCall map[int]struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	NewEncryptedVolumeStub        func(string) (volume.FilesystemInitVolume, error)
+	newEncryptedVolumeMutex       sync.RWMutex
+	newEncryptedVolumeArgsForCall []struct {
+		arg1 string
+	}
+	newEncryptedVolumeReturns struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	newEncryptedVolumeReturnsOnCall map[int]struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
 	LookupVolumeStub        func(string) (volume.FilesystemLiveVolume, bool, error)
 	lookupVolumeMutex       sync.RWMutex
 	lookupVolumeArgsForCall []struct {
@@ -47,6 +73,74 @@ type FakeFilesystem struct {
 		result1 []volume.FilesystemLiveVolume
 		result2 error
 	}
+	LookupDeletedVolumeStub        func(string) (volume.FilesystemLiveVolume, bool, error)
+	lookupDeletedVolumeMutex       sync.RWMutex
+	lookupDeletedVolumeArgsForCall []struct {
+		arg1 string
+	}
+	lookupDeletedVolumeReturns struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}
+	lookupDeletedVolumeReturnsOnCall map[int]struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}
+	ListDeletedVolumesStub        func() ([]volume.FilesystemLiveVolume, error)
+	listDeletedVolumesMutex       sync.RWMutex
+	listDeletedVolumesArgsForCall []struct{}
+	listDeletedVolumesReturns     struct {
+		result1 []volume.FilesystemLiveVolume
+		result2 error
+	}
+	listDeletedVolumesReturnsOnCall map[int]struct {
+		result1 []volume.FilesystemLiveVolume
+		result2 error
+	}
+	FreeInodesStub        func() (uint64, bool, error)
+	freeInodesMutex       sync.RWMutex
+	freeInodesArgsForCall []struct{}
+	freeInodesReturns     struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}
+	freeInodesReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}
+	FreeBytesStub        func() (uint64, error)
+	freeBytesMutex       sync.RWMutex
+	freeBytesArgsForCall []struct{}
+	freeBytesReturns     struct {
+		result1 uint64
+		result2 error
+	}
+	freeBytesReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 error
+	}
+	CheckMountStub        func() error
+	checkMountMutex       sync.RWMutex
+	checkMountArgsForCall []struct{}
+	checkMountReturns     struct {
+		result1 error
+	}
+	checkMountReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ProbeWriteStub        func() error
+	probeWriteMutex       sync.RWMutex
+	probeWriteArgsForCall []struct{}
+	probeWriteReturns     struct {
+		result1 error
+	}
+	probeWriteReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -102,6 +196,108 @@ func (fake *FakeFilesystem) NewVolumeReturnsOnCall(i int, result1 volume.Filesys
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystem) NewTmpfsVolume(arg1 string) (volume.FilesystemInitVolume, error) {
+	fake.newTmpfsVolumeMutex.Lock()
+	ret, specificReturn := fake.newTmpfsVolumeReturnsOnCall[len(fake.newTmpfsVolumeArgsForCall)]
+	fake.newTmpfsVolumeArgsForCall = append(fake.newTmpfsVolumeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("NewTmpfsVolume", []interface{}{arg1})
+	fake.newTmpfsVolumeMutex.Unlock()
+	if fake.NewTmpfsVolumeStub != nil {
+		return fake.NewTmpfsVolumeStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.newTmpfsVolumeReturns.result1, fake.newTmpfsVolumeReturns.result2
+}
+
+func (fake *FakeFilesystem) NewTmpfsVolumeCallCount() int {
+	fake.newTmpfsVolumeMutex.RLock()
+	defer fake.newTmpfsVolumeMutex.RUnlock()
+	return len(fake.newTmpfsVolumeArgsForCall)
+}
+
+func (fake *FakeFilesystem) NewTmpfsVolumeArgsForCall(i int) string {
+	fake.newTmpfsVolumeMutex.RLock()
+	defer fake.newTmpfsVolumeMutex.RUnlock()
+	return fake.newTmpfsVolumeArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystem) NewTmpfsVolumeReturns(result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewTmpfsVolumeStub = nil
+	fake.newTmpfsVolumeReturns = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) NewTmpfsVolumeReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewTmpfsVolumeStub = nil
+	if fake.newTmpfsVolumeReturnsOnCall == nil {
+		fake.newTmpfsVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemInitVolume
+			result2 error
+		})
+	}
+	fake.newTmpfsVolumeReturnsOnCall[i] = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) NewEncryptedVolume(arg1 string) (volume.FilesystemInitVolume, error) {
+	fake.newEncryptedVolumeMutex.Lock()
+	ret, specificReturn := fake.newEncryptedVolumeReturnsOnCall[len(fake.newEncryptedVolumeArgsForCall)]
+	fake.newEncryptedVolumeArgsForCall = append(fake.newEncryptedVolumeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("NewEncryptedVolume", []interface{}{arg1})
+	fake.newEncryptedVolumeMutex.Unlock()
+	if fake.NewEncryptedVolumeStub != nil {
+		return fake.NewEncryptedVolumeStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.newEncryptedVolumeReturns.result1, fake.newEncryptedVolumeReturns.result2
+}
+
+func (fake *FakeFilesystem) NewEncryptedVolumeCallCount() int {
+	fake.newEncryptedVolumeMutex.RLock()
+	defer fake.newEncryptedVolumeMutex.RUnlock()
+	return len(fake.newEncryptedVolumeArgsForCall)
+}
+
+func (fake *FakeFilesystem) NewEncryptedVolumeArgsForCall(i int) string {
+	fake.newEncryptedVolumeMutex.RLock()
+	defer fake.newEncryptedVolumeMutex.RUnlock()
+	return fake.newEncryptedVolumeArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystem) NewEncryptedVolumeReturns(result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewEncryptedVolumeStub = nil
+	fake.newEncryptedVolumeReturns = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) NewEncryptedVolumeReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewEncryptedVolumeStub = nil
+	if fake.newEncryptedVolumeReturnsOnCall == nil {
+		fake.newEncryptedVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemInitVolume
+			result2 error
+		})
+	}
+	fake.newEncryptedVolumeReturnsOnCall[i] = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeFilesystem) LookupVolume(arg1 string) (volume.FilesystemLiveVolume, bool, error) {
 	fake.lookupVolumeMutex.Lock()
 	ret, specificReturn := fake.lookupVolumeReturnsOnCall[len(fake.lookupVolumeArgsForCall)]
@@ -199,15 +395,297 @@ func (fake *FakeFilesystem) ListVolumesReturnsOnCall(i int, result1 []volume.Fil
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystem) LookupDeletedVolume(arg1 string) (volume.FilesystemLiveVolume, bool, error) {
+	fake.lookupDeletedVolumeMutex.Lock()
+	ret, specificReturn := fake.lookupDeletedVolumeReturnsOnCall[len(fake.lookupDeletedVolumeArgsForCall)]
+	fake.lookupDeletedVolumeArgsForCall = append(fake.lookupDeletedVolumeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("LookupDeletedVolume", []interface{}{arg1})
+	fake.lookupDeletedVolumeMutex.Unlock()
+	if fake.LookupDeletedVolumeStub != nil {
+		return fake.LookupDeletedVolumeStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.lookupDeletedVolumeReturns.result1, fake.lookupDeletedVolumeReturns.result2, fake.lookupDeletedVolumeReturns.result3
+}
+
+func (fake *FakeFilesystem) LookupDeletedVolumeCallCount() int {
+	fake.lookupDeletedVolumeMutex.RLock()
+	defer fake.lookupDeletedVolumeMutex.RUnlock()
+	return len(fake.lookupDeletedVolumeArgsForCall)
+}
+
+func (fake *FakeFilesystem) LookupDeletedVolumeArgsForCall(i int) string {
+	fake.lookupDeletedVolumeMutex.RLock()
+	defer fake.lookupDeletedVolumeMutex.RUnlock()
+	return fake.lookupDeletedVolumeArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystem) LookupDeletedVolumeReturns(result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
+	fake.LookupDeletedVolumeStub = nil
+	fake.lookupDeletedVolumeReturns = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystem) LookupDeletedVolumeReturnsOnCall(i int, result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
+	fake.LookupDeletedVolumeStub = nil
+	if fake.lookupDeletedVolumeReturnsOnCall == nil {
+		fake.lookupDeletedVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemLiveVolume
+			result2 bool
+			result3 error
+		})
+	}
+	fake.lookupDeletedVolumeReturnsOnCall[i] = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystem) ListDeletedVolumes() ([]volume.FilesystemLiveVolume, error) {
+	fake.listDeletedVolumesMutex.Lock()
+	ret, specificReturn := fake.listDeletedVolumesReturnsOnCall[len(fake.listDeletedVolumesArgsForCall)]
+	fake.listDeletedVolumesArgsForCall = append(fake.listDeletedVolumesArgsForCall, struct{}{})
+	fake.recordInvocation("ListDeletedVolumes", []interface{}{})
+	fake.listDeletedVolumesMutex.Unlock()
+	if fake.ListDeletedVolumesStub != nil {
+		return fake.ListDeletedVolumesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listDeletedVolumesReturns.result1, fake.listDeletedVolumesReturns.result2
+}
+
+func (fake *FakeFilesystem) ListDeletedVolumesCallCount() int {
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	return len(fake.listDeletedVolumesArgsForCall)
+}
+
+func (fake *FakeFilesystem) ListDeletedVolumesReturns(result1 []volume.FilesystemLiveVolume, result2 error) {
+	fake.ListDeletedVolumesStub = nil
+	fake.listDeletedVolumesReturns = struct {
+		result1 []volume.FilesystemLiveVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) ListDeletedVolumesReturnsOnCall(i int, result1 []volume.FilesystemLiveVolume, result2 error) {
+	fake.ListDeletedVolumesStub = nil
+	if fake.listDeletedVolumesReturnsOnCall == nil {
+		fake.listDeletedVolumesReturnsOnCall = make(map[int]struct {
+			result1 []volume.FilesystemLiveVolume
+			result2 error
+		})
+	}
+	fake.listDeletedVolumesReturnsOnCall[i] = struct {
+		result1 []volume.FilesystemLiveVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) FreeInodes() (uint64, bool, error) {
+	fake.freeInodesMutex.Lock()
+	ret, specificReturn := fake.freeInodesReturnsOnCall[len(fake.freeInodesArgsForCall)]
+	fake.freeInodesArgsForCall = append(fake.freeInodesArgsForCall, struct{}{})
+	fake.recordInvocation("FreeInodes", []interface{}{})
+	fake.freeInodesMutex.Unlock()
+	if fake.FreeInodesStub != nil {
+		return fake.FreeInodesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.freeInodesReturns.result1, fake.freeInodesReturns.result2, fake.freeInodesReturns.result3
+}
+
+func (fake *FakeFilesystem) FreeInodesCallCount() int {
+	fake.freeInodesMutex.RLock()
+	defer fake.freeInodesMutex.RUnlock()
+	return len(fake.freeInodesArgsForCall)
+}
+
+func (fake *FakeFilesystem) FreeInodesReturns(result1 uint64, result2 bool, result3 error) {
+	fake.FreeInodesStub = nil
+	fake.freeInodesReturns = struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystem) FreeInodesReturnsOnCall(i int, result1 uint64, result2 bool, result3 error) {
+	fake.FreeInodesStub = nil
+	if fake.freeInodesReturnsOnCall == nil {
+		fake.freeInodesReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 bool
+			result3 error
+		})
+	}
+	fake.freeInodesReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystem) FreeBytes() (uint64, error) {
+	fake.freeBytesMutex.Lock()
+	ret, specificReturn := fake.freeBytesReturnsOnCall[len(fake.freeBytesArgsForCall)]
+	fake.freeBytesArgsForCall = append(fake.freeBytesArgsForCall, struct{}{})
+	fake.recordInvocation("FreeBytes", []interface{}{})
+	fake.freeBytesMutex.Unlock()
+	if fake.FreeBytesStub != nil {
+		return fake.FreeBytesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.freeBytesReturns.result1, fake.freeBytesReturns.result2
+}
+
+func (fake *FakeFilesystem) FreeBytesCallCount() int {
+	fake.freeBytesMutex.RLock()
+	defer fake.freeBytesMutex.RUnlock()
+	return len(fake.freeBytesArgsForCall)
+}
+
+func (fake *FakeFilesystem) FreeBytesReturns(result1 uint64, result2 error) {
+	fake.FreeBytesStub = nil
+	fake.freeBytesReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) FreeBytesReturnsOnCall(i int, result1 uint64, result2 error) {
+	fake.FreeBytesStub = nil
+	if fake.freeBytesReturnsOnCall == nil {
+		fake.freeBytesReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 error
+		})
+	}
+	fake.freeBytesReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystem) CheckMount() error {
+	fake.checkMountMutex.Lock()
+	ret, specificReturn := fake.checkMountReturnsOnCall[len(fake.checkMountArgsForCall)]
+	fake.checkMountArgsForCall = append(fake.checkMountArgsForCall, struct{}{})
+	fake.recordInvocation("CheckMount", []interface{}{})
+	fake.checkMountMutex.Unlock()
+	if fake.CheckMountStub != nil {
+		return fake.CheckMountStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.checkMountReturns.result1
+}
+
+func (fake *FakeFilesystem) CheckMountCallCount() int {
+	fake.checkMountMutex.RLock()
+	defer fake.checkMountMutex.RUnlock()
+	return len(fake.checkMountArgsForCall)
+}
+
+func (fake *FakeFilesystem) CheckMountReturns(result1 error) {
+	fake.CheckMountStub = nil
+	fake.checkMountReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystem) CheckMountReturnsOnCall(i int, result1 error) {
+	fake.CheckMountStub = nil
+	if fake.checkMountReturnsOnCall == nil {
+		fake.checkMountReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkMountReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystem) ProbeWrite() error {
+	fake.probeWriteMutex.Lock()
+	ret, specificReturn := fake.probeWriteReturnsOnCall[len(fake.probeWriteArgsForCall)]
+	fake.probeWriteArgsForCall = append(fake.probeWriteArgsForCall, struct{}{})
+	fake.recordInvocation("ProbeWrite", []interface{}{})
+	fake.probeWriteMutex.Unlock()
+	if fake.ProbeWriteStub != nil {
+		return fake.ProbeWriteStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.probeWriteReturns.result1
+}
+
+func (fake *FakeFilesystem) ProbeWriteCallCount() int {
+	fake.probeWriteMutex.RLock()
+	defer fake.probeWriteMutex.RUnlock()
+	return len(fake.probeWriteArgsForCall)
+}
+
+func (fake *FakeFilesystem) ProbeWriteReturns(result1 error) {
+	fake.ProbeWriteStub = nil
+	fake.probeWriteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystem) ProbeWriteReturnsOnCall(i int, result1 error) {
+	fake.ProbeWriteStub = nil
+	if fake.probeWriteReturnsOnCall == nil {
+		fake.probeWriteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.probeWriteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystem) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.newVolumeMutex.RLock()
 	defer fake.newVolumeMutex.RUnlock()
+	fake.newTmpfsVolumeMutex.RLock()
+	defer fake.newTmpfsVolumeMutex.RUnlock()
+	fake.newEncryptedVolumeMutex.RLock()
+	defer fake.newEncryptedVolumeMutex.RUnlock()
 	fake.lookupVolumeMutex.RLock()
 	defer fake.lookupVolumeMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
+	fake.lookupDeletedVolumeMutex.RLock()
+	defer fake.lookupDeletedVolumeMutex.RUnlock()
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	fake.freeInodesMutex.RLock()
+	defer fake.freeInodesMutex.RUnlock()
+	fake.freeBytesMutex.RLock()
+	defer fake.freeBytesMutex.RUnlock()
+	fake.checkMountMutex.RLock()
+	defer fake.checkMountMutex.RUnlock()
+	fake.probeWriteMutex.RLock()
+	defer fake.probeWriteMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
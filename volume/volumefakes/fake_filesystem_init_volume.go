@@ -38,6 +38,63 @@ type FakeFilesystemInitVolume struct {
 		result1 volume.Properties
 		result2 error
 	}
+	LoadReadOnlyStub        func() (bool, error)
+	loadReadOnlyMutex       sync.RWMutex
+	loadReadOnlyArgsForCall []struct{}
+	loadReadOnlyReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadReadOnlyReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadRenewTTLOnAccessStub        func() (bool, error)
+	loadRenewTTLOnAccessMutex       sync.RWMutex
+	loadRenewTTLOnAccessArgsForCall []struct{}
+	loadRenewTTLOnAccessReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadRenewTTLOnAccessReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadMountOptionsStub        func() ([]string, error)
+	loadMountOptionsMutex       sync.RWMutex
+	loadMountOptionsArgsForCall []struct{}
+	loadMountOptionsReturns     struct {
+		result1 []string
+		result2 error
+	}
+	loadMountOptionsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	LoadEncryptedStub        func() (bool, error)
+	loadEncryptedMutex       sync.RWMutex
+	loadEncryptedArgsForCall []struct{}
+	loadEncryptedReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadEncryptedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadBackingStub        func() (string, string, error)
+	loadBackingMutex       sync.RWMutex
+	loadBackingArgsForCall []struct{}
+	loadBackingReturns     struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	loadBackingReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 error
+	}
 	StorePropertiesStub        func(volume.Properties) error
 	storePropertiesMutex       sync.RWMutex
 	storePropertiesArgsForCall []struct {
@@ -62,6 +119,26 @@ type FakeFilesystemInitVolume struct {
 		result2 time.Time
 		result3 error
 	}
+	MakeReadOnlyStub        func() error
+	makeReadOnlyMutex       sync.RWMutex
+	makeReadOnlyArgsForCall []struct{}
+	makeReadOnlyReturns     struct {
+		result1 error
+	}
+	makeReadOnlyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetMountOptionsStub        func([]string) error
+	setMountOptionsMutex       sync.RWMutex
+	setMountOptionsArgsForCall []struct {
+		arg1 []string
+	}
+	setMountOptionsReturns struct {
+		result1 error
+	}
+	setMountOptionsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StoreTTLStub        func(volume.TTL) (time.Time, error)
 	storeTTLMutex       sync.RWMutex
 	storeTTLArgsForCall []struct {
@@ -75,6 +152,18 @@ type FakeFilesystemInitVolume struct {
 		result1 time.Time
 		result2 error
 	}
+	StoreExpiryStub        func(volume.TTL, time.Time) error
+	storeExpiryMutex       sync.RWMutex
+	storeExpiryArgsForCall []struct {
+		arg1 volume.TTL
+		arg2 time.Time
+	}
+	storeExpiryReturns struct {
+		result1 error
+	}
+	storeExpiryReturnsOnCall map[int]struct {
+		result1 error
+	}
 	LoadPrivilegedStub        func() (bool, error)
 	loadPrivilegedMutex       sync.RWMutex
 	loadPrivilegedArgsForCall []struct{}
@@ -97,6 +186,204 @@ type FakeFilesystemInitVolume struct {
 	storePrivilegedReturnsOnCall map[int]struct {
 		result1 error
 	}
+	LoadCommittedStub        func() (time.Time, bool, error)
+	loadCommittedMutex       sync.RWMutex
+	loadCommittedArgsForCall []struct{}
+	loadCommittedReturns     struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}
+	loadCommittedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}
+	StoreCommittedStub        func(bool) (time.Time, error)
+	storeCommittedMutex       sync.RWMutex
+	storeCommittedArgsForCall []struct {
+		arg1 bool
+	}
+	storeCommittedReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	storeCommittedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadLastAccessedStub        func() (time.Time, error)
+	loadLastAccessedMutex       sync.RWMutex
+	loadLastAccessedArgsForCall []struct{}
+	loadLastAccessedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	loadLastAccessedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	StoreLastAccessedStub        func() (time.Time, error)
+	storeLastAccessedMutex       sync.RWMutex
+	storeLastAccessedArgsForCall []struct{}
+	storeLastAccessedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	storeLastAccessedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadCreatedStub        func() (time.Time, string, error)
+	loadCreatedMutex       sync.RWMutex
+	loadCreatedArgsForCall []struct{}
+	loadCreatedReturns     struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}
+	loadCreatedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}
+	StoreCreatedStub        func(string) (time.Time, error)
+	storeCreatedMutex       sync.RWMutex
+	storeCreatedArgsForCall []struct {
+		arg1 string
+	}
+	storeCreatedReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	storeCreatedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadModifiedStub        func() (time.Time, error)
+	loadModifiedMutex       sync.RWMutex
+	loadModifiedArgsForCall []struct{}
+	loadModifiedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	loadModifiedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	StoreModifiedStub        func() (time.Time, error)
+	storeModifiedMutex       sync.RWMutex
+	storeModifiedArgsForCall []struct{}
+	storeModifiedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	storeModifiedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadDigestStub        func() (string, error)
+	loadDigestMutex       sync.RWMutex
+	loadDigestArgsForCall []struct{}
+	loadDigestReturns     struct {
+		result1 string
+		result2 error
+	}
+	loadDigestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	StoreDigestStub        func(string) error
+	storeDigestMutex       sync.RWMutex
+	storeDigestArgsForCall []struct {
+		arg1 string
+	}
+	storeDigestReturns struct {
+		result1 error
+	}
+	storeDigestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadStreamInKeysStub        func() (map[string]time.Time, error)
+	loadStreamInKeysMutex       sync.RWMutex
+	loadStreamInKeysArgsForCall []struct{}
+	loadStreamInKeysReturns     struct {
+		result1 map[string]time.Time
+		result2 error
+	}
+	loadStreamInKeysReturnsOnCall map[int]struct {
+		result1 map[string]time.Time
+		result2 error
+	}
+	StoreStreamInKeysStub        func(map[string]time.Time) error
+	storeStreamInKeysMutex       sync.RWMutex
+	storeStreamInKeysArgsForCall []struct {
+		arg1 map[string]time.Time
+	}
+	storeStreamInKeysReturns struct {
+		result1 error
+	}
+	storeStreamInKeysReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadCreateKeyStub        func() (string, string, error)
+	loadCreateKeyMutex       sync.RWMutex
+	loadCreateKeyArgsForCall []struct{}
+	loadCreateKeyReturns     struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	loadCreateKeyReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	StoreCreateKeyStub        func(key string, fingerprint string) error
+	storeCreateKeyMutex       sync.RWMutex
+	storeCreateKeyArgsForCall []struct {
+		key         string
+		fingerprint string
+	}
+	storeCreateKeyReturns struct {
+		result1 error
+	}
+	storeCreateKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadReleasedStub        func() (volume.DestroyOptions, bool, error)
+	loadReleasedMutex       sync.RWMutex
+	loadReleasedArgsForCall []struct{}
+	loadReleasedReturns     struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}
+	loadReleasedReturnsOnCall map[int]struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}
+	StoreReleasedStub        func(volume.DestroyOptions) error
+	storeReleasedMutex       sync.RWMutex
+	storeReleasedArgsForCall []struct {
+		arg1 volume.DestroyOptions
+	}
+	storeReleasedReturns struct {
+		result1 error
+	}
+	storeReleasedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRenewTTLOnAccessStub        func() error
+	storeRenewTTLOnAccessMutex       sync.RWMutex
+	storeRenewTTLOnAccessArgsForCall []struct{}
+	storeRenewTTLOnAccessReturns     struct {
+		result1 error
+	}
+	storeRenewTTLOnAccessReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ParentStub        func() (volume.FilesystemLiveVolume, bool, error)
 	parentMutex       sync.RWMutex
 	parentArgsForCall []struct{}
@@ -119,6 +406,17 @@ type FakeFilesystemInitVolume struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetQuotaStub        func(sizeInBytes int64) error
+	setQuotaMutex       sync.RWMutex
+	setQuotaArgsForCall []struct {
+		sizeInBytes int64
+	}
+	setQuotaReturns struct {
+		result1 error
+	}
+	setQuotaReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InitializeStub        func() (volume.FilesystemLiveVolume, error)
 	initializeMutex       sync.RWMutex
 	initializeArgsForCall []struct{}
@@ -257,6 +555,224 @@ func (fake *FakeFilesystemInitVolume) LoadPropertiesReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystemInitVolume) LoadReadOnly() (bool, error) {
+	fake.loadReadOnlyMutex.Lock()
+	ret, specificReturn := fake.loadReadOnlyReturnsOnCall[len(fake.loadReadOnlyArgsForCall)]
+	fake.loadReadOnlyArgsForCall = append(fake.loadReadOnlyArgsForCall, struct{}{})
+	fake.recordInvocation("LoadReadOnly", []interface{}{})
+	fake.loadReadOnlyMutex.Unlock()
+	if fake.LoadReadOnlyStub != nil {
+		return fake.LoadReadOnlyStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadReadOnlyReturns.result1, fake.loadReadOnlyReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReadOnlyCallCount() int {
+	fake.loadReadOnlyMutex.RLock()
+	defer fake.loadReadOnlyMutex.RUnlock()
+	return len(fake.loadReadOnlyArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReadOnlyReturns(result1 bool, result2 error) {
+	fake.LoadReadOnlyStub = nil
+	fake.loadReadOnlyReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReadOnlyReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadReadOnlyStub = nil
+	if fake.loadReadOnlyReturnsOnCall == nil {
+		fake.loadReadOnlyReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadReadOnlyReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadRenewTTLOnAccess() (bool, error) {
+	fake.loadRenewTTLOnAccessMutex.Lock()
+	ret, specificReturn := fake.loadRenewTTLOnAccessReturnsOnCall[len(fake.loadRenewTTLOnAccessArgsForCall)]
+	fake.loadRenewTTLOnAccessArgsForCall = append(fake.loadRenewTTLOnAccessArgsForCall, struct{}{})
+	fake.recordInvocation("LoadRenewTTLOnAccess", []interface{}{})
+	fake.loadRenewTTLOnAccessMutex.Unlock()
+	if fake.LoadRenewTTLOnAccessStub != nil {
+		return fake.LoadRenewTTLOnAccessStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadRenewTTLOnAccessReturns.result1, fake.loadRenewTTLOnAccessReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadRenewTTLOnAccessCallCount() int {
+	fake.loadRenewTTLOnAccessMutex.RLock()
+	defer fake.loadRenewTTLOnAccessMutex.RUnlock()
+	return len(fake.loadRenewTTLOnAccessArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadRenewTTLOnAccessReturns(result1 bool, result2 error) {
+	fake.LoadRenewTTLOnAccessStub = nil
+	fake.loadRenewTTLOnAccessReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadRenewTTLOnAccessReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadRenewTTLOnAccessStub = nil
+	if fake.loadRenewTTLOnAccessReturnsOnCall == nil {
+		fake.loadRenewTTLOnAccessReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadRenewTTLOnAccessReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadMountOptions() ([]string, error) {
+	fake.loadMountOptionsMutex.Lock()
+	ret, specificReturn := fake.loadMountOptionsReturnsOnCall[len(fake.loadMountOptionsArgsForCall)]
+	fake.loadMountOptionsArgsForCall = append(fake.loadMountOptionsArgsForCall, struct{}{})
+	fake.recordInvocation("LoadMountOptions", []interface{}{})
+	fake.loadMountOptionsMutex.Unlock()
+	if fake.LoadMountOptionsStub != nil {
+		return fake.LoadMountOptionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadMountOptionsReturns.result1, fake.loadMountOptionsReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadMountOptionsCallCount() int {
+	fake.loadMountOptionsMutex.RLock()
+	defer fake.loadMountOptionsMutex.RUnlock()
+	return len(fake.loadMountOptionsArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadMountOptionsReturns(result1 []string, result2 error) {
+	fake.LoadMountOptionsStub = nil
+	fake.loadMountOptionsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadMountOptionsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.LoadMountOptionsStub = nil
+	if fake.loadMountOptionsReturnsOnCall == nil {
+		fake.loadMountOptionsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.loadMountOptionsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadEncrypted() (bool, error) {
+	fake.loadEncryptedMutex.Lock()
+	ret, specificReturn := fake.loadEncryptedReturnsOnCall[len(fake.loadEncryptedArgsForCall)]
+	fake.loadEncryptedArgsForCall = append(fake.loadEncryptedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadEncrypted", []interface{}{})
+	fake.loadEncryptedMutex.Unlock()
+	if fake.LoadEncryptedStub != nil {
+		return fake.LoadEncryptedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadEncryptedReturns.result1, fake.loadEncryptedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadEncryptedCallCount() int {
+	fake.loadEncryptedMutex.RLock()
+	defer fake.loadEncryptedMutex.RUnlock()
+	return len(fake.loadEncryptedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadEncryptedReturns(result1 bool, result2 error) {
+	fake.LoadEncryptedStub = nil
+	fake.loadEncryptedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadEncryptedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadEncryptedStub = nil
+	if fake.loadEncryptedReturnsOnCall == nil {
+		fake.loadEncryptedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadEncryptedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadBacking() (string, string, error) {
+	fake.loadBackingMutex.Lock()
+	ret, specificReturn := fake.loadBackingReturnsOnCall[len(fake.loadBackingArgsForCall)]
+	fake.loadBackingArgsForCall = append(fake.loadBackingArgsForCall, struct{}{})
+	fake.recordInvocation("LoadBacking", []interface{}{})
+	fake.loadBackingMutex.Unlock()
+	if fake.LoadBackingStub != nil {
+		return fake.LoadBackingStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadBackingReturns.result1, fake.loadBackingReturns.result2, fake.loadBackingReturns.result3
+}
+
+func (fake *FakeFilesystemInitVolume) LoadBackingCallCount() int {
+	fake.loadBackingMutex.RLock()
+	defer fake.loadBackingMutex.RUnlock()
+	return len(fake.loadBackingArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadBackingReturns(result1 string, result2 string, result3 error) {
+	fake.LoadBackingStub = nil
+	fake.loadBackingReturns = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadBackingReturnsOnCall(i int, result1 string, result2 string, result3 error) {
+	fake.LoadBackingStub = nil
+	if fake.loadBackingReturnsOnCall == nil {
+		fake.loadBackingReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadBackingReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeFilesystemInitVolume) StoreProperties(arg1 volume.Properties) error {
 	fake.storePropertiesMutex.Lock()
 	ret, specificReturn := fake.storePropertiesReturnsOnCall[len(fake.storePropertiesArgsForCall)]
@@ -351,8 +867,101 @@ func (fake *FakeFilesystemInitVolume) LoadTTLReturnsOnCall(i int, result1 volume
 	}{result1, result2, result3}
 }
 
-func (fake *FakeFilesystemInitVolume) StoreTTL(arg1 volume.TTL) (time.Time, error) {
-	fake.storeTTLMutex.Lock()
+func (fake *FakeFilesystemInitVolume) MakeReadOnly() error {
+	fake.makeReadOnlyMutex.Lock()
+	ret, specificReturn := fake.makeReadOnlyReturnsOnCall[len(fake.makeReadOnlyArgsForCall)]
+	fake.makeReadOnlyArgsForCall = append(fake.makeReadOnlyArgsForCall, struct{}{})
+	fake.recordInvocation("MakeReadOnly", []interface{}{})
+	fake.makeReadOnlyMutex.Unlock()
+	if fake.MakeReadOnlyStub != nil {
+		return fake.MakeReadOnlyStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.makeReadOnlyReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) MakeReadOnlyCallCount() int {
+	fake.makeReadOnlyMutex.RLock()
+	defer fake.makeReadOnlyMutex.RUnlock()
+	return len(fake.makeReadOnlyArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) MakeReadOnlyReturns(result1 error) {
+	fake.MakeReadOnlyStub = nil
+	fake.makeReadOnlyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) MakeReadOnlyReturnsOnCall(i int, result1 error) {
+	fake.MakeReadOnlyStub = nil
+	if fake.makeReadOnlyReturnsOnCall == nil {
+		fake.makeReadOnlyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.makeReadOnlyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) SetMountOptions(arg1 []string) error {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.setMountOptionsMutex.Lock()
+	ret, specificReturn := fake.setMountOptionsReturnsOnCall[len(fake.setMountOptionsArgsForCall)]
+	fake.setMountOptionsArgsForCall = append(fake.setMountOptionsArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	fake.recordInvocation("SetMountOptions", []interface{}{arg1Copy})
+	fake.setMountOptionsMutex.Unlock()
+	if fake.SetMountOptionsStub != nil {
+		return fake.SetMountOptionsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setMountOptionsReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) SetMountOptionsCallCount() int {
+	fake.setMountOptionsMutex.RLock()
+	defer fake.setMountOptionsMutex.RUnlock()
+	return len(fake.setMountOptionsArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) SetMountOptionsArgsForCall(i int) []string {
+	fake.setMountOptionsMutex.RLock()
+	defer fake.setMountOptionsMutex.RUnlock()
+	return fake.setMountOptionsArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) SetMountOptionsReturns(result1 error) {
+	fake.SetMountOptionsStub = nil
+	fake.setMountOptionsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) SetMountOptionsReturnsOnCall(i int, result1 error) {
+	fake.SetMountOptionsStub = nil
+	if fake.setMountOptionsReturnsOnCall == nil {
+		fake.setMountOptionsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setMountOptionsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreTTL(arg1 volume.TTL) (time.Time, error) {
+	fake.storeTTLMutex.Lock()
 	ret, specificReturn := fake.storeTTLReturnsOnCall[len(fake.storeTTLArgsForCall)]
 	fake.storeTTLArgsForCall = append(fake.storeTTLArgsForCall, struct {
 		arg1 volume.TTL
@@ -402,6 +1011,55 @@ func (fake *FakeFilesystemInitVolume) StoreTTLReturnsOnCall(i int, result1 time.
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystemInitVolume) StoreExpiry(arg1 volume.TTL, arg2 time.Time) error {
+	fake.storeExpiryMutex.Lock()
+	ret, specificReturn := fake.storeExpiryReturnsOnCall[len(fake.storeExpiryArgsForCall)]
+	fake.storeExpiryArgsForCall = append(fake.storeExpiryArgsForCall, struct {
+		arg1 volume.TTL
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("StoreExpiry", []interface{}{arg1, arg2})
+	fake.storeExpiryMutex.Unlock()
+	if fake.StoreExpiryStub != nil {
+		return fake.StoreExpiryStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeExpiryReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreExpiryCallCount() int {
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
+	return len(fake.storeExpiryArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreExpiryArgsForCall(i int) (volume.TTL, time.Time) {
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
+	return fake.storeExpiryArgsForCall[i].arg1, fake.storeExpiryArgsForCall[i].arg2
+}
+
+func (fake *FakeFilesystemInitVolume) StoreExpiryReturns(result1 error) {
+	fake.StoreExpiryStub = nil
+	fake.storeExpiryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreExpiryReturnsOnCall(i int, result1 error) {
+	fake.StoreExpiryStub = nil
+	if fake.storeExpiryReturnsOnCall == nil {
+		fake.storeExpiryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeExpiryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystemInitVolume) LoadPrivileged() (bool, error) {
 	fake.loadPrivilegedMutex.Lock()
 	ret, specificReturn := fake.loadPrivilegedReturnsOnCall[len(fake.loadPrivilegedArgsForCall)]
@@ -493,6 +1151,783 @@ func (fake *FakeFilesystemInitVolume) StorePrivilegedReturnsOnCall(i int, result
 	}{result1}
 }
 
+func (fake *FakeFilesystemInitVolume) LoadCommitted() (time.Time, bool, error) {
+	fake.loadCommittedMutex.Lock()
+	ret, specificReturn := fake.loadCommittedReturnsOnCall[len(fake.loadCommittedArgsForCall)]
+	fake.loadCommittedArgsForCall = append(fake.loadCommittedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCommitted", []interface{}{})
+	fake.loadCommittedMutex.Unlock()
+	if fake.LoadCommittedStub != nil {
+		return fake.LoadCommittedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadCommittedReturns.result1, fake.loadCommittedReturns.result2, fake.loadCommittedReturns.result3
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCommittedCallCount() int {
+	fake.loadCommittedMutex.RLock()
+	defer fake.loadCommittedMutex.RUnlock()
+	return len(fake.loadCommittedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCommittedReturns(result1 time.Time, result2 bool, result3 error) {
+	fake.LoadCommittedStub = nil
+	fake.loadCommittedReturns = struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCommittedReturnsOnCall(i int, result1 time.Time, result2 bool, result3 error) {
+	fake.LoadCommittedStub = nil
+	if fake.loadCommittedReturnsOnCall == nil {
+		fake.loadCommittedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 bool
+			result3 error
+		})
+	}
+	fake.loadCommittedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCommitted(arg1 bool) (time.Time, error) {
+	fake.storeCommittedMutex.Lock()
+	ret, specificReturn := fake.storeCommittedReturnsOnCall[len(fake.storeCommittedArgsForCall)]
+	fake.storeCommittedArgsForCall = append(fake.storeCommittedArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	fake.recordInvocation("StoreCommitted", []interface{}{arg1})
+	fake.storeCommittedMutex.Unlock()
+	if fake.StoreCommittedStub != nil {
+		return fake.StoreCommittedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeCommittedReturns.result1, fake.storeCommittedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCommittedCallCount() int {
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	return len(fake.storeCommittedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCommittedArgsForCall(i int) bool {
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	return fake.storeCommittedArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCommittedReturns(result1 time.Time, result2 error) {
+	fake.StoreCommittedStub = nil
+	fake.storeCommittedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCommittedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreCommittedStub = nil
+	if fake.storeCommittedReturnsOnCall == nil {
+		fake.storeCommittedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeCommittedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadLastAccessed() (time.Time, error) {
+	fake.loadLastAccessedMutex.Lock()
+	ret, specificReturn := fake.loadLastAccessedReturnsOnCall[len(fake.loadLastAccessedArgsForCall)]
+	fake.loadLastAccessedArgsForCall = append(fake.loadLastAccessedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadLastAccessed", []interface{}{})
+	fake.loadLastAccessedMutex.Unlock()
+	if fake.LoadLastAccessedStub != nil {
+		return fake.LoadLastAccessedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadLastAccessedReturns.result1, fake.loadLastAccessedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadLastAccessedCallCount() int {
+	fake.loadLastAccessedMutex.RLock()
+	defer fake.loadLastAccessedMutex.RUnlock()
+	return len(fake.loadLastAccessedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadLastAccessedReturns(result1 time.Time, result2 error) {
+	fake.LoadLastAccessedStub = nil
+	fake.loadLastAccessedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadLastAccessedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.LoadLastAccessedStub = nil
+	if fake.loadLastAccessedReturnsOnCall == nil {
+		fake.loadLastAccessedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.loadLastAccessedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreLastAccessed() (time.Time, error) {
+	fake.storeLastAccessedMutex.Lock()
+	ret, specificReturn := fake.storeLastAccessedReturnsOnCall[len(fake.storeLastAccessedArgsForCall)]
+	fake.storeLastAccessedArgsForCall = append(fake.storeLastAccessedArgsForCall, struct{}{})
+	fake.recordInvocation("StoreLastAccessed", []interface{}{})
+	fake.storeLastAccessedMutex.Unlock()
+	if fake.StoreLastAccessedStub != nil {
+		return fake.StoreLastAccessedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeLastAccessedReturns.result1, fake.storeLastAccessedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) StoreLastAccessedCallCount() int {
+	fake.storeLastAccessedMutex.RLock()
+	defer fake.storeLastAccessedMutex.RUnlock()
+	return len(fake.storeLastAccessedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreLastAccessedReturns(result1 time.Time, result2 error) {
+	fake.StoreLastAccessedStub = nil
+	fake.storeLastAccessedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreLastAccessedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreLastAccessedStub = nil
+	if fake.storeLastAccessedReturnsOnCall == nil {
+		fake.storeLastAccessedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeLastAccessedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreated() (time.Time, string, error) {
+	fake.loadCreatedMutex.Lock()
+	ret, specificReturn := fake.loadCreatedReturnsOnCall[len(fake.loadCreatedArgsForCall)]
+	fake.loadCreatedArgsForCall = append(fake.loadCreatedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCreated", []interface{}{})
+	fake.loadCreatedMutex.Unlock()
+	if fake.LoadCreatedStub != nil {
+		return fake.LoadCreatedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadCreatedReturns.result1, fake.loadCreatedReturns.result2, fake.loadCreatedReturns.result3
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreatedCallCount() int {
+	fake.loadCreatedMutex.RLock()
+	defer fake.loadCreatedMutex.RUnlock()
+	return len(fake.loadCreatedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreatedReturns(result1 time.Time, result2 string, result3 error) {
+	fake.LoadCreatedStub = nil
+	fake.loadCreatedReturns = struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreatedReturnsOnCall(i int, result1 time.Time, result2 string, result3 error) {
+	fake.LoadCreatedStub = nil
+	if fake.loadCreatedReturnsOnCall == nil {
+		fake.loadCreatedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadCreatedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreated(arg1 string) (time.Time, error) {
+	fake.storeCreatedMutex.Lock()
+	ret, specificReturn := fake.storeCreatedReturnsOnCall[len(fake.storeCreatedArgsForCall)]
+	fake.storeCreatedArgsForCall = append(fake.storeCreatedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StoreCreated", []interface{}{arg1})
+	fake.storeCreatedMutex.Unlock()
+	if fake.StoreCreatedStub != nil {
+		return fake.StoreCreatedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeCreatedReturns.result1, fake.storeCreatedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreatedCallCount() int {
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	return len(fake.storeCreatedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreatedArgsForCall(i int) string {
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	return fake.storeCreatedArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreatedReturns(result1 time.Time, result2 error) {
+	fake.StoreCreatedStub = nil
+	fake.storeCreatedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreatedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreCreatedStub = nil
+	if fake.storeCreatedReturnsOnCall == nil {
+		fake.storeCreatedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeCreatedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadModified() (time.Time, error) {
+	fake.loadModifiedMutex.Lock()
+	ret, specificReturn := fake.loadModifiedReturnsOnCall[len(fake.loadModifiedArgsForCall)]
+	fake.loadModifiedArgsForCall = append(fake.loadModifiedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadModified", []interface{}{})
+	fake.loadModifiedMutex.Unlock()
+	if fake.LoadModifiedStub != nil {
+		return fake.LoadModifiedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadModifiedReturns.result1, fake.loadModifiedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadModifiedCallCount() int {
+	fake.loadModifiedMutex.RLock()
+	defer fake.loadModifiedMutex.RUnlock()
+	return len(fake.loadModifiedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadModifiedReturns(result1 time.Time, result2 error) {
+	fake.LoadModifiedStub = nil
+	fake.loadModifiedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadModifiedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.LoadModifiedStub = nil
+	if fake.loadModifiedReturnsOnCall == nil {
+		fake.loadModifiedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.loadModifiedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreModified() (time.Time, error) {
+	fake.storeModifiedMutex.Lock()
+	ret, specificReturn := fake.storeModifiedReturnsOnCall[len(fake.storeModifiedArgsForCall)]
+	fake.storeModifiedArgsForCall = append(fake.storeModifiedArgsForCall, struct{}{})
+	fake.recordInvocation("StoreModified", []interface{}{})
+	fake.storeModifiedMutex.Unlock()
+	if fake.StoreModifiedStub != nil {
+		return fake.StoreModifiedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeModifiedReturns.result1, fake.storeModifiedReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) StoreModifiedCallCount() int {
+	fake.storeModifiedMutex.RLock()
+	defer fake.storeModifiedMutex.RUnlock()
+	return len(fake.storeModifiedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreModifiedReturns(result1 time.Time, result2 error) {
+	fake.StoreModifiedStub = nil
+	fake.storeModifiedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreModifiedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreModifiedStub = nil
+	if fake.storeModifiedReturnsOnCall == nil {
+		fake.storeModifiedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeModifiedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadDigest() (string, error) {
+	fake.loadDigestMutex.Lock()
+	ret, specificReturn := fake.loadDigestReturnsOnCall[len(fake.loadDigestArgsForCall)]
+	fake.loadDigestArgsForCall = append(fake.loadDigestArgsForCall, struct{}{})
+	fake.recordInvocation("LoadDigest", []interface{}{})
+	fake.loadDigestMutex.Unlock()
+	if fake.LoadDigestStub != nil {
+		return fake.LoadDigestStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadDigestReturns.result1, fake.loadDigestReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadDigestCallCount() int {
+	fake.loadDigestMutex.RLock()
+	defer fake.loadDigestMutex.RUnlock()
+	return len(fake.loadDigestArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadDigestReturns(result1 string, result2 error) {
+	fake.LoadDigestStub = nil
+	fake.loadDigestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadDigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.LoadDigestStub = nil
+	if fake.loadDigestReturnsOnCall == nil {
+		fake.loadDigestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.loadDigestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreDigest(arg1 string) error {
+	fake.storeDigestMutex.Lock()
+	ret, specificReturn := fake.storeDigestReturnsOnCall[len(fake.storeDigestArgsForCall)]
+	fake.storeDigestArgsForCall = append(fake.storeDigestArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StoreDigest", []interface{}{arg1})
+	fake.storeDigestMutex.Unlock()
+	if fake.StoreDigestStub != nil {
+		return fake.StoreDigestStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeDigestReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreDigestCallCount() int {
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	return len(fake.storeDigestArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreDigestArgsForCall(i int) string {
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	return fake.storeDigestArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreDigestReturns(result1 error) {
+	fake.StoreDigestStub = nil
+	fake.storeDigestReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreDigestReturnsOnCall(i int, result1 error) {
+	fake.StoreDigestStub = nil
+	if fake.storeDigestReturnsOnCall == nil {
+		fake.storeDigestReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeDigestReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadStreamInKeys() (map[string]time.Time, error) {
+	fake.loadStreamInKeysMutex.Lock()
+	ret, specificReturn := fake.loadStreamInKeysReturnsOnCall[len(fake.loadStreamInKeysArgsForCall)]
+	fake.loadStreamInKeysArgsForCall = append(fake.loadStreamInKeysArgsForCall, struct{}{})
+	fake.recordInvocation("LoadStreamInKeys", []interface{}{})
+	fake.loadStreamInKeysMutex.Unlock()
+	if fake.LoadStreamInKeysStub != nil {
+		return fake.LoadStreamInKeysStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadStreamInKeysReturns.result1, fake.loadStreamInKeysReturns.result2
+}
+
+func (fake *FakeFilesystemInitVolume) LoadStreamInKeysCallCount() int {
+	fake.loadStreamInKeysMutex.RLock()
+	defer fake.loadStreamInKeysMutex.RUnlock()
+	return len(fake.loadStreamInKeysArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadStreamInKeysReturns(result1 map[string]time.Time, result2 error) {
+	fake.LoadStreamInKeysStub = nil
+	fake.loadStreamInKeysReturns = struct {
+		result1 map[string]time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadStreamInKeysReturnsOnCall(i int, result1 map[string]time.Time, result2 error) {
+	fake.LoadStreamInKeysStub = nil
+	if fake.loadStreamInKeysReturnsOnCall == nil {
+		fake.loadStreamInKeysReturnsOnCall = make(map[int]struct {
+			result1 map[string]time.Time
+			result2 error
+		})
+	}
+	fake.loadStreamInKeysReturnsOnCall[i] = struct {
+		result1 map[string]time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreStreamInKeys(arg1 map[string]time.Time) error {
+	fake.storeStreamInKeysMutex.Lock()
+	ret, specificReturn := fake.storeStreamInKeysReturnsOnCall[len(fake.storeStreamInKeysArgsForCall)]
+	fake.storeStreamInKeysArgsForCall = append(fake.storeStreamInKeysArgsForCall, struct {
+		arg1 map[string]time.Time
+	}{arg1})
+	fake.recordInvocation("StoreStreamInKeys", []interface{}{arg1})
+	fake.storeStreamInKeysMutex.Unlock()
+	if fake.StoreStreamInKeysStub != nil {
+		return fake.StoreStreamInKeysStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeStreamInKeysReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreStreamInKeysCallCount() int {
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	return len(fake.storeStreamInKeysArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreStreamInKeysArgsForCall(i int) map[string]time.Time {
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	return fake.storeStreamInKeysArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreStreamInKeysReturns(result1 error) {
+	fake.StoreStreamInKeysStub = nil
+	fake.storeStreamInKeysReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreStreamInKeysReturnsOnCall(i int, result1 error) {
+	fake.StoreStreamInKeysStub = nil
+	if fake.storeStreamInKeysReturnsOnCall == nil {
+		fake.storeStreamInKeysReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeStreamInKeysReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreateKey() (string, string, error) {
+	fake.loadCreateKeyMutex.Lock()
+	ret, specificReturn := fake.loadCreateKeyReturnsOnCall[len(fake.loadCreateKeyArgsForCall)]
+	fake.loadCreateKeyArgsForCall = append(fake.loadCreateKeyArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCreateKey", []interface{}{})
+	fake.loadCreateKeyMutex.Unlock()
+	if fake.LoadCreateKeyStub != nil {
+		return fake.LoadCreateKeyStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadCreateKeyReturns.result1, fake.loadCreateKeyReturns.result2, fake.loadCreateKeyReturns.result3
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreateKeyCallCount() int {
+	fake.loadCreateKeyMutex.RLock()
+	defer fake.loadCreateKeyMutex.RUnlock()
+	return len(fake.loadCreateKeyArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreateKeyReturns(result1 string, result2 string, result3 error) {
+	fake.LoadCreateKeyStub = nil
+	fake.loadCreateKeyReturns = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadCreateKeyReturnsOnCall(i int, result1 string, result2 string, result3 error) {
+	fake.LoadCreateKeyStub = nil
+	if fake.loadCreateKeyReturnsOnCall == nil {
+		fake.loadCreateKeyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadCreateKeyReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreateKey(key string, fingerprint string) error {
+	fake.storeCreateKeyMutex.Lock()
+	ret, specificReturn := fake.storeCreateKeyReturnsOnCall[len(fake.storeCreateKeyArgsForCall)]
+	fake.storeCreateKeyArgsForCall = append(fake.storeCreateKeyArgsForCall, struct {
+		key         string
+		fingerprint string
+	}{key, fingerprint})
+	fake.recordInvocation("StoreCreateKey", []interface{}{key, fingerprint})
+	fake.storeCreateKeyMutex.Unlock()
+	if fake.StoreCreateKeyStub != nil {
+		return fake.StoreCreateKeyStub(key, fingerprint)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeCreateKeyReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreateKeyCallCount() int {
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	return len(fake.storeCreateKeyArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreateKeyArgsForCall(i int) (string, string) {
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	return fake.storeCreateKeyArgsForCall[i].key, fake.storeCreateKeyArgsForCall[i].fingerprint
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreateKeyReturns(result1 error) {
+	fake.StoreCreateKeyStub = nil
+	fake.storeCreateKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreCreateKeyReturnsOnCall(i int, result1 error) {
+	fake.StoreCreateKeyStub = nil
+	if fake.storeCreateKeyReturnsOnCall == nil {
+		fake.storeCreateKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeCreateKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReleased() (volume.DestroyOptions, bool, error) {
+	fake.loadReleasedMutex.Lock()
+	ret, specificReturn := fake.loadReleasedReturnsOnCall[len(fake.loadReleasedArgsForCall)]
+	fake.loadReleasedArgsForCall = append(fake.loadReleasedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadReleased", []interface{}{})
+	fake.loadReleasedMutex.Unlock()
+	if fake.LoadReleasedStub != nil {
+		return fake.LoadReleasedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadReleasedReturns.result1, fake.loadReleasedReturns.result2, fake.loadReleasedReturns.result3
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReleasedCallCount() int {
+	fake.loadReleasedMutex.RLock()
+	defer fake.loadReleasedMutex.RUnlock()
+	return len(fake.loadReleasedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReleasedReturns(result1 volume.DestroyOptions, result2 bool, result3 error) {
+	fake.LoadReleasedStub = nil
+	fake.loadReleasedReturns = struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) LoadReleasedReturnsOnCall(i int, result1 volume.DestroyOptions, result2 bool, result3 error) {
+	fake.LoadReleasedStub = nil
+	if fake.loadReleasedReturnsOnCall == nil {
+		fake.loadReleasedReturnsOnCall = make(map[int]struct {
+			result1 volume.DestroyOptions
+			result2 bool
+			result3 error
+		})
+	}
+	fake.loadReleasedReturnsOnCall[i] = struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreReleased(arg1 volume.DestroyOptions) error {
+	fake.storeReleasedMutex.Lock()
+	ret, specificReturn := fake.storeReleasedReturnsOnCall[len(fake.storeReleasedArgsForCall)]
+	fake.storeReleasedArgsForCall = append(fake.storeReleasedArgsForCall, struct {
+		arg1 volume.DestroyOptions
+	}{arg1})
+	fake.recordInvocation("StoreReleased", []interface{}{arg1})
+	fake.storeReleasedMutex.Unlock()
+	if fake.StoreReleasedStub != nil {
+		return fake.StoreReleasedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeReleasedReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreReleasedCallCount() int {
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	return len(fake.storeReleasedArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreReleasedArgsForCall(i int) volume.DestroyOptions {
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	return fake.storeReleasedArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreReleasedReturns(result1 error) {
+	fake.StoreReleasedStub = nil
+	fake.storeReleasedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreReleasedReturnsOnCall(i int, result1 error) {
+	fake.StoreReleasedStub = nil
+	if fake.storeReleasedReturnsOnCall == nil {
+		fake.storeReleasedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeReleasedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreRenewTTLOnAccess() error {
+	fake.storeRenewTTLOnAccessMutex.Lock()
+	ret, specificReturn := fake.storeRenewTTLOnAccessReturnsOnCall[len(fake.storeRenewTTLOnAccessArgsForCall)]
+	fake.storeRenewTTLOnAccessArgsForCall = append(fake.storeRenewTTLOnAccessArgsForCall, struct{}{})
+	fake.recordInvocation("StoreRenewTTLOnAccess", []interface{}{})
+	fake.storeRenewTTLOnAccessMutex.Unlock()
+	if fake.StoreRenewTTLOnAccessStub != nil {
+		return fake.StoreRenewTTLOnAccessStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeRenewTTLOnAccessReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) StoreRenewTTLOnAccessCallCount() int {
+	fake.storeRenewTTLOnAccessMutex.RLock()
+	defer fake.storeRenewTTLOnAccessMutex.RUnlock()
+	return len(fake.storeRenewTTLOnAccessArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) StoreRenewTTLOnAccessReturns(result1 error) {
+	fake.StoreRenewTTLOnAccessStub = nil
+	fake.storeRenewTTLOnAccessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) StoreRenewTTLOnAccessReturnsOnCall(i int, result1 error) {
+	fake.StoreRenewTTLOnAccessStub = nil
+	if fake.storeRenewTTLOnAccessReturnsOnCall == nil {
+		fake.storeRenewTTLOnAccessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRenewTTLOnAccessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystemInitVolume) Parent() (volume.FilesystemLiveVolume, bool, error) {
 	fake.parentMutex.Lock()
 	ret, specificReturn := fake.parentReturnsOnCall[len(fake.parentArgsForCall)]
@@ -579,6 +2014,54 @@ func (fake *FakeFilesystemInitVolume) DestroyReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeFilesystemInitVolume) SetQuota(sizeInBytes int64) error {
+	fake.setQuotaMutex.Lock()
+	ret, specificReturn := fake.setQuotaReturnsOnCall[len(fake.setQuotaArgsForCall)]
+	fake.setQuotaArgsForCall = append(fake.setQuotaArgsForCall, struct {
+		sizeInBytes int64
+	}{sizeInBytes})
+	fake.recordInvocation("SetQuota", []interface{}{sizeInBytes})
+	fake.setQuotaMutex.Unlock()
+	if fake.SetQuotaStub != nil {
+		return fake.SetQuotaStub(sizeInBytes)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setQuotaReturns.result1
+}
+
+func (fake *FakeFilesystemInitVolume) SetQuotaCallCount() int {
+	fake.setQuotaMutex.RLock()
+	defer fake.setQuotaMutex.RUnlock()
+	return len(fake.setQuotaArgsForCall)
+}
+
+func (fake *FakeFilesystemInitVolume) SetQuotaArgsForCall(i int) int64 {
+	fake.setQuotaMutex.RLock()
+	defer fake.setQuotaMutex.RUnlock()
+	return fake.setQuotaArgsForCall[i].sizeInBytes
+}
+
+func (fake *FakeFilesystemInitVolume) SetQuotaReturns(result1 error) {
+	fake.SetQuotaStub = nil
+	fake.setQuotaReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemInitVolume) SetQuotaReturnsOnCall(i int, result1 error) {
+	fake.SetQuotaStub = nil
+	if fake.setQuotaReturnsOnCall == nil {
+		fake.setQuotaReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setQuotaReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystemInitVolume) Initialize() (volume.FilesystemLiveVolume, error) {
 	fake.initializeMutex.Lock()
 	ret, specificReturn := fake.initializeReturnsOnCall[len(fake.initializeArgsForCall)]
@@ -631,20 +2114,72 @@ func (fake *FakeFilesystemInitVolume) Invocations() map[string][][]interface{} {
 	defer fake.dataPathMutex.RUnlock()
 	fake.loadPropertiesMutex.RLock()
 	defer fake.loadPropertiesMutex.RUnlock()
+	fake.loadReadOnlyMutex.RLock()
+	defer fake.loadReadOnlyMutex.RUnlock()
+	fake.loadRenewTTLOnAccessMutex.RLock()
+	defer fake.loadRenewTTLOnAccessMutex.RUnlock()
+	fake.loadMountOptionsMutex.RLock()
+	defer fake.loadMountOptionsMutex.RUnlock()
+	fake.loadEncryptedMutex.RLock()
+	defer fake.loadEncryptedMutex.RUnlock()
+	fake.loadBackingMutex.RLock()
+	defer fake.loadBackingMutex.RUnlock()
 	fake.storePropertiesMutex.RLock()
 	defer fake.storePropertiesMutex.RUnlock()
 	fake.loadTTLMutex.RLock()
 	defer fake.loadTTLMutex.RUnlock()
+	fake.makeReadOnlyMutex.RLock()
+	defer fake.makeReadOnlyMutex.RUnlock()
+	fake.setMountOptionsMutex.RLock()
+	defer fake.setMountOptionsMutex.RUnlock()
 	fake.storeTTLMutex.RLock()
 	defer fake.storeTTLMutex.RUnlock()
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
 	fake.loadPrivilegedMutex.RLock()
 	defer fake.loadPrivilegedMutex.RUnlock()
 	fake.storePrivilegedMutex.RLock()
 	defer fake.storePrivilegedMutex.RUnlock()
+	fake.loadCommittedMutex.RLock()
+	defer fake.loadCommittedMutex.RUnlock()
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	fake.loadLastAccessedMutex.RLock()
+	defer fake.loadLastAccessedMutex.RUnlock()
+	fake.storeLastAccessedMutex.RLock()
+	defer fake.storeLastAccessedMutex.RUnlock()
+	fake.loadCreatedMutex.RLock()
+	defer fake.loadCreatedMutex.RUnlock()
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	fake.loadModifiedMutex.RLock()
+	defer fake.loadModifiedMutex.RUnlock()
+	fake.storeModifiedMutex.RLock()
+	defer fake.storeModifiedMutex.RUnlock()
+	fake.loadDigestMutex.RLock()
+	defer fake.loadDigestMutex.RUnlock()
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	fake.loadStreamInKeysMutex.RLock()
+	defer fake.loadStreamInKeysMutex.RUnlock()
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	fake.loadCreateKeyMutex.RLock()
+	defer fake.loadCreateKeyMutex.RUnlock()
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	fake.loadReleasedMutex.RLock()
+	defer fake.loadReleasedMutex.RUnlock()
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	fake.storeRenewTTLOnAccessMutex.RLock()
+	defer fake.storeRenewTTLOnAccessMutex.RUnlock()
 	fake.parentMutex.RLock()
 	defer fake.parentMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.setQuotaMutex.RLock()
+	defer fake.setQuotaMutex.RUnlock()
 	fake.initializeMutex.RLock()
 	defer fake.initializeMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
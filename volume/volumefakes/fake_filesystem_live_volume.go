@@ -38,6 +38,63 @@ type FakeFilesystemLiveVolume struct {
 		result1 volume.Properties
 		result2 error
 	}
+	LoadReadOnlyStub        func() (bool, error)
+	loadReadOnlyMutex       sync.RWMutex
+	loadReadOnlyArgsForCall []struct{}
+	loadReadOnlyReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadReadOnlyReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadRenewTTLOnAccessStub        func() (bool, error)
+	loadRenewTTLOnAccessMutex       sync.RWMutex
+	loadRenewTTLOnAccessArgsForCall []struct{}
+	loadRenewTTLOnAccessReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadRenewTTLOnAccessReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadMountOptionsStub        func() ([]string, error)
+	loadMountOptionsMutex       sync.RWMutex
+	loadMountOptionsArgsForCall []struct{}
+	loadMountOptionsReturns     struct {
+		result1 []string
+		result2 error
+	}
+	loadMountOptionsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	LoadEncryptedStub        func() (bool, error)
+	loadEncryptedMutex       sync.RWMutex
+	loadEncryptedArgsForCall []struct{}
+	loadEncryptedReturns     struct {
+		result1 bool
+		result2 error
+	}
+	loadEncryptedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	LoadBackingStub        func() (string, string, error)
+	loadBackingMutex       sync.RWMutex
+	loadBackingArgsForCall []struct{}
+	loadBackingReturns     struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	loadBackingReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 error
+	}
 	StorePropertiesStub        func(volume.Properties) error
 	storePropertiesMutex       sync.RWMutex
 	storePropertiesArgsForCall []struct {
@@ -75,6 +132,18 @@ type FakeFilesystemLiveVolume struct {
 		result1 time.Time
 		result2 error
 	}
+	StoreExpiryStub        func(volume.TTL, time.Time) error
+	storeExpiryMutex       sync.RWMutex
+	storeExpiryArgsForCall []struct {
+		arg1 volume.TTL
+		arg2 time.Time
+	}
+	storeExpiryReturns struct {
+		result1 error
+	}
+	storeExpiryReturnsOnCall map[int]struct {
+		result1 error
+	}
 	LoadPrivilegedStub        func() (bool, error)
 	loadPrivilegedMutex       sync.RWMutex
 	loadPrivilegedArgsForCall []struct{}
@@ -97,6 +166,204 @@ type FakeFilesystemLiveVolume struct {
 	storePrivilegedReturnsOnCall map[int]struct {
 		result1 error
 	}
+	LoadCommittedStub        func() (time.Time, bool, error)
+	loadCommittedMutex       sync.RWMutex
+	loadCommittedArgsForCall []struct{}
+	loadCommittedReturns     struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}
+	loadCommittedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 bool
+		result3 error
+	}
+	StoreCommittedStub        func(bool) (time.Time, error)
+	storeCommittedMutex       sync.RWMutex
+	storeCommittedArgsForCall []struct {
+		arg1 bool
+	}
+	storeCommittedReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	storeCommittedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadLastAccessedStub        func() (time.Time, error)
+	loadLastAccessedMutex       sync.RWMutex
+	loadLastAccessedArgsForCall []struct{}
+	loadLastAccessedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	loadLastAccessedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	StoreLastAccessedStub        func() (time.Time, error)
+	storeLastAccessedMutex       sync.RWMutex
+	storeLastAccessedArgsForCall []struct{}
+	storeLastAccessedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	storeLastAccessedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadCreatedStub        func() (time.Time, string, error)
+	loadCreatedMutex       sync.RWMutex
+	loadCreatedArgsForCall []struct{}
+	loadCreatedReturns     struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}
+	loadCreatedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}
+	StoreCreatedStub        func(string) (time.Time, error)
+	storeCreatedMutex       sync.RWMutex
+	storeCreatedArgsForCall []struct {
+		arg1 string
+	}
+	storeCreatedReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	storeCreatedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadModifiedStub        func() (time.Time, error)
+	loadModifiedMutex       sync.RWMutex
+	loadModifiedArgsForCall []struct{}
+	loadModifiedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	loadModifiedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	StoreModifiedStub        func() (time.Time, error)
+	storeModifiedMutex       sync.RWMutex
+	storeModifiedArgsForCall []struct{}
+	storeModifiedReturns     struct {
+		result1 time.Time
+		result2 error
+	}
+	storeModifiedReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	LoadDigestStub        func() (string, error)
+	loadDigestMutex       sync.RWMutex
+	loadDigestArgsForCall []struct{}
+	loadDigestReturns     struct {
+		result1 string
+		result2 error
+	}
+	loadDigestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	StoreDigestStub        func(string) error
+	storeDigestMutex       sync.RWMutex
+	storeDigestArgsForCall []struct {
+		arg1 string
+	}
+	storeDigestReturns struct {
+		result1 error
+	}
+	storeDigestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadStreamInKeysStub        func() (map[string]time.Time, error)
+	loadStreamInKeysMutex       sync.RWMutex
+	loadStreamInKeysArgsForCall []struct{}
+	loadStreamInKeysReturns     struct {
+		result1 map[string]time.Time
+		result2 error
+	}
+	loadStreamInKeysReturnsOnCall map[int]struct {
+		result1 map[string]time.Time
+		result2 error
+	}
+	StoreStreamInKeysStub        func(map[string]time.Time) error
+	storeStreamInKeysMutex       sync.RWMutex
+	storeStreamInKeysArgsForCall []struct {
+		arg1 map[string]time.Time
+	}
+	storeStreamInKeysReturns struct {
+		result1 error
+	}
+	storeStreamInKeysReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadCreateKeyStub        func() (string, string, error)
+	loadCreateKeyMutex       sync.RWMutex
+	loadCreateKeyArgsForCall []struct{}
+	loadCreateKeyReturns     struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	loadCreateKeyReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	StoreCreateKeyStub        func(key string, fingerprint string) error
+	storeCreateKeyMutex       sync.RWMutex
+	storeCreateKeyArgsForCall []struct {
+		key         string
+		fingerprint string
+	}
+	storeCreateKeyReturns struct {
+		result1 error
+	}
+	storeCreateKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadReleasedStub        func() (volume.DestroyOptions, bool, error)
+	loadReleasedMutex       sync.RWMutex
+	loadReleasedArgsForCall []struct{}
+	loadReleasedReturns     struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}
+	loadReleasedReturnsOnCall map[int]struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}
+	StoreReleasedStub        func(volume.DestroyOptions) error
+	storeReleasedMutex       sync.RWMutex
+	storeReleasedArgsForCall []struct {
+		arg1 volume.DestroyOptions
+	}
+	storeReleasedReturns struct {
+		result1 error
+	}
+	storeReleasedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRenewTTLOnAccessStub        func() error
+	storeRenewTTLOnAccessMutex       sync.RWMutex
+	storeRenewTTLOnAccessArgsForCall []struct{}
+	storeRenewTTLOnAccessReturns     struct {
+		result1 error
+	}
+	storeRenewTTLOnAccessReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ParentStub        func() (volume.FilesystemLiveVolume, bool, error)
 	parentMutex       sync.RWMutex
 	parentArgsForCall []struct{}
@@ -119,14 +386,61 @@ type FakeFilesystemLiveVolume struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
-	SizeInBytesStub        func() (int64, error)
-	sizeInBytesMutex       sync.RWMutex
-	sizeInBytesArgsForCall []struct{}
-	sizeInBytesReturns     struct {
+	StatsStub        func() (volume.VolumeStats, error)
+	statsMutex       sync.RWMutex
+	statsArgsForCall []struct{}
+	statsReturns     struct {
+		result1 volume.VolumeStats
+		result2 error
+	}
+	statsReturnsOnCall map[int]struct {
+		result1 volume.VolumeStats
+		result2 error
+	}
+	LoadDeletedStub        func() (volume.DestroyOptions, time.Time, bool, error)
+	loadDeletedMutex       sync.RWMutex
+	loadDeletedArgsForCall []struct{}
+	loadDeletedReturns     struct {
+		result1 volume.DestroyOptions
+		result2 time.Time
+		result3 bool
+		result4 error
+	}
+	loadDeletedReturnsOnCall map[int]struct {
+		result1 volume.DestroyOptions
+		result2 time.Time
+		result3 bool
+		result4 error
+	}
+	StoreDeletedStub        func(volume.DestroyOptions, time.Time) error
+	storeDeletedMutex       sync.RWMutex
+	storeDeletedArgsForCall []struct {
+		arg1 volume.DestroyOptions
+		arg2 time.Time
+	}
+	storeDeletedReturns struct {
+		result1 error
+	}
+	storeDeletedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ClearDeletedStub        func() error
+	clearDeletedMutex       sync.RWMutex
+	clearDeletedArgsForCall []struct{}
+	clearDeletedReturns     struct {
+		result1 error
+	}
+	clearDeletedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SizeStub        func() (int64, error)
+	sizeMutex       sync.RWMutex
+	sizeArgsForCall []struct{}
+	sizeReturns     struct {
 		result1 int64
 		result2 error
 	}
-	sizeInBytesReturnsOnCall map[int]struct {
+	sizeReturnsOnCall map[int]struct {
 		result1 int64
 		result2 error
 	}
@@ -143,6 +457,114 @@ type FakeFilesystemLiveVolume struct {
 		result1 volume.FilesystemInitVolume
 		result2 error
 	}
+	NewViewStub        func(handle string) (volume.FilesystemInitVolume, error)
+	newViewMutex       sync.RWMutex
+	newViewArgsForCall []struct {
+		handle string
+	}
+	newViewReturns struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	newViewReturnsOnCall map[int]struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	IsViewStub        func() (bool, error)
+	isViewMutex       sync.RWMutex
+	isViewArgsForCall []struct{}
+	isViewReturns     struct {
+		result1 bool
+		result2 error
+	}
+	isViewReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	NewCloneStub        func(handle string) (volume.FilesystemInitVolume, error)
+	newCloneMutex       sync.RWMutex
+	newCloneArgsForCall []struct {
+		handle string
+	}
+	newCloneReturns struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	newCloneReturnsOnCall map[int]struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}
+	CopyDataStub        func(src string, dest string) error
+	copyDataMutex       sync.RWMutex
+	copyDataArgsForCall []struct {
+		src  string
+		dest string
+	}
+	copyDataReturns struct {
+		result1 error
+	}
+	copyDataReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SnapshotStub        func() (string, func() error, error)
+	snapshotMutex       sync.RWMutex
+	snapshotArgsForCall []struct{}
+	snapshotReturns     struct {
+		result1 string
+		result2 func() error
+		result3 error
+	}
+	snapshotReturnsOnCall map[int]struct {
+		result1 string
+		result2 func() error
+		result3 error
+	}
+	MaterializeStub        func() (bool, error)
+	materializeMutex       sync.RWMutex
+	materializeArgsForCall []struct{}
+	materializeReturns     struct {
+		result1 bool
+		result2 error
+	}
+	materializeReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	RenameStub        func(handle string) (volume.FilesystemLiveVolume, error)
+	renameMutex       sync.RWMutex
+	renameArgsForCall []struct {
+		handle string
+	}
+	renameReturns struct {
+		result1 volume.FilesystemLiveVolume
+		result2 error
+	}
+	renameReturnsOnCall map[int]struct {
+		result1 volume.FilesystemLiveVolume
+		result2 error
+	}
+	RoomStub        func() (uint64, bool, error)
+	roomMutex       sync.RWMutex
+	roomArgsForCall []struct{}
+	roomReturns     struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}
+	roomReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}
+	PromoteStub        func() error
+	promoteMutex       sync.RWMutex
+	promoteArgsForCall []struct{}
+	promoteReturns     struct {
+		result1 error
+	}
+	promoteReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -270,55 +692,273 @@ func (fake *FakeFilesystemLiveVolume) LoadPropertiesReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) StoreProperties(arg1 volume.Properties) error {
-	fake.storePropertiesMutex.Lock()
-	ret, specificReturn := fake.storePropertiesReturnsOnCall[len(fake.storePropertiesArgsForCall)]
-	fake.storePropertiesArgsForCall = append(fake.storePropertiesArgsForCall, struct {
-		arg1 volume.Properties
-	}{arg1})
-	fake.recordInvocation("StoreProperties", []interface{}{arg1})
-	fake.storePropertiesMutex.Unlock()
-	if fake.StorePropertiesStub != nil {
-		return fake.StorePropertiesStub(arg1)
+func (fake *FakeFilesystemLiveVolume) LoadReadOnly() (bool, error) {
+	fake.loadReadOnlyMutex.Lock()
+	ret, specificReturn := fake.loadReadOnlyReturnsOnCall[len(fake.loadReadOnlyArgsForCall)]
+	fake.loadReadOnlyArgsForCall = append(fake.loadReadOnlyArgsForCall, struct{}{})
+	fake.recordInvocation("LoadReadOnly", []interface{}{})
+	fake.loadReadOnlyMutex.Unlock()
+	if fake.LoadReadOnlyStub != nil {
+		return fake.LoadReadOnlyStub()
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.storePropertiesReturns.result1
+	return fake.loadReadOnlyReturns.result1, fake.loadReadOnlyReturns.result2
 }
 
-func (fake *FakeFilesystemLiveVolume) StorePropertiesCallCount() int {
-	fake.storePropertiesMutex.RLock()
-	defer fake.storePropertiesMutex.RUnlock()
-	return len(fake.storePropertiesArgsForCall)
+func (fake *FakeFilesystemLiveVolume) LoadReadOnlyCallCount() int {
+	fake.loadReadOnlyMutex.RLock()
+	defer fake.loadReadOnlyMutex.RUnlock()
+	return len(fake.loadReadOnlyArgsForCall)
 }
 
-func (fake *FakeFilesystemLiveVolume) StorePropertiesArgsForCall(i int) volume.Properties {
-	fake.storePropertiesMutex.RLock()
-	defer fake.storePropertiesMutex.RUnlock()
-	return fake.storePropertiesArgsForCall[i].arg1
+func (fake *FakeFilesystemLiveVolume) LoadReadOnlyReturns(result1 bool, result2 error) {
+	fake.LoadReadOnlyStub = nil
+	fake.loadReadOnlyReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) StorePropertiesReturns(result1 error) {
-	fake.StorePropertiesStub = nil
-	fake.storePropertiesReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeFilesystemLiveVolume) LoadReadOnlyReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadReadOnlyStub = nil
+	if fake.loadReadOnlyReturnsOnCall == nil {
+		fake.loadReadOnlyReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadReadOnlyReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) StorePropertiesReturnsOnCall(i int, result1 error) {
-	fake.StorePropertiesStub = nil
-	if fake.storePropertiesReturnsOnCall == nil {
-		fake.storePropertiesReturnsOnCall = make(map[int]struct {
-			result1 error
-		})
+func (fake *FakeFilesystemLiveVolume) LoadRenewTTLOnAccess() (bool, error) {
+	fake.loadRenewTTLOnAccessMutex.Lock()
+	ret, specificReturn := fake.loadRenewTTLOnAccessReturnsOnCall[len(fake.loadRenewTTLOnAccessArgsForCall)]
+	fake.loadRenewTTLOnAccessArgsForCall = append(fake.loadRenewTTLOnAccessArgsForCall, struct{}{})
+	fake.recordInvocation("LoadRenewTTLOnAccess", []interface{}{})
+	fake.loadRenewTTLOnAccessMutex.Unlock()
+	if fake.LoadRenewTTLOnAccessStub != nil {
+		return fake.LoadRenewTTLOnAccessStub()
 	}
-	fake.storePropertiesReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadRenewTTLOnAccessReturns.result1, fake.loadRenewTTLOnAccessReturns.result2
 }
 
-func (fake *FakeFilesystemLiveVolume) LoadTTL() (volume.TTL, time.Time, error) {
+func (fake *FakeFilesystemLiveVolume) LoadRenewTTLOnAccessCallCount() int {
+	fake.loadRenewTTLOnAccessMutex.RLock()
+	defer fake.loadRenewTTLOnAccessMutex.RUnlock()
+	return len(fake.loadRenewTTLOnAccessArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadRenewTTLOnAccessReturns(result1 bool, result2 error) {
+	fake.LoadRenewTTLOnAccessStub = nil
+	fake.loadRenewTTLOnAccessReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadRenewTTLOnAccessReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadRenewTTLOnAccessStub = nil
+	if fake.loadRenewTTLOnAccessReturnsOnCall == nil {
+		fake.loadRenewTTLOnAccessReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadRenewTTLOnAccessReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadMountOptions() ([]string, error) {
+	fake.loadMountOptionsMutex.Lock()
+	ret, specificReturn := fake.loadMountOptionsReturnsOnCall[len(fake.loadMountOptionsArgsForCall)]
+	fake.loadMountOptionsArgsForCall = append(fake.loadMountOptionsArgsForCall, struct{}{})
+	fake.recordInvocation("LoadMountOptions", []interface{}{})
+	fake.loadMountOptionsMutex.Unlock()
+	if fake.LoadMountOptionsStub != nil {
+		return fake.LoadMountOptionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadMountOptionsReturns.result1, fake.loadMountOptionsReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadMountOptionsCallCount() int {
+	fake.loadMountOptionsMutex.RLock()
+	defer fake.loadMountOptionsMutex.RUnlock()
+	return len(fake.loadMountOptionsArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadMountOptionsReturns(result1 []string, result2 error) {
+	fake.LoadMountOptionsStub = nil
+	fake.loadMountOptionsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadMountOptionsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.LoadMountOptionsStub = nil
+	if fake.loadMountOptionsReturnsOnCall == nil {
+		fake.loadMountOptionsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.loadMountOptionsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadEncrypted() (bool, error) {
+	fake.loadEncryptedMutex.Lock()
+	ret, specificReturn := fake.loadEncryptedReturnsOnCall[len(fake.loadEncryptedArgsForCall)]
+	fake.loadEncryptedArgsForCall = append(fake.loadEncryptedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadEncrypted", []interface{}{})
+	fake.loadEncryptedMutex.Unlock()
+	if fake.LoadEncryptedStub != nil {
+		return fake.LoadEncryptedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadEncryptedReturns.result1, fake.loadEncryptedReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadEncryptedCallCount() int {
+	fake.loadEncryptedMutex.RLock()
+	defer fake.loadEncryptedMutex.RUnlock()
+	return len(fake.loadEncryptedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadEncryptedReturns(result1 bool, result2 error) {
+	fake.LoadEncryptedStub = nil
+	fake.loadEncryptedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadEncryptedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.LoadEncryptedStub = nil
+	if fake.loadEncryptedReturnsOnCall == nil {
+		fake.loadEncryptedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.loadEncryptedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadBacking() (string, string, error) {
+	fake.loadBackingMutex.Lock()
+	ret, specificReturn := fake.loadBackingReturnsOnCall[len(fake.loadBackingArgsForCall)]
+	fake.loadBackingArgsForCall = append(fake.loadBackingArgsForCall, struct{}{})
+	fake.recordInvocation("LoadBacking", []interface{}{})
+	fake.loadBackingMutex.Unlock()
+	if fake.LoadBackingStub != nil {
+		return fake.LoadBackingStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadBackingReturns.result1, fake.loadBackingReturns.result2, fake.loadBackingReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadBackingCallCount() int {
+	fake.loadBackingMutex.RLock()
+	defer fake.loadBackingMutex.RUnlock()
+	return len(fake.loadBackingArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadBackingReturns(result1 string, result2 string, result3 error) {
+	fake.LoadBackingStub = nil
+	fake.loadBackingReturns = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadBackingReturnsOnCall(i int, result1 string, result2 string, result3 error) {
+	fake.LoadBackingStub = nil
+	if fake.loadBackingReturnsOnCall == nil {
+		fake.loadBackingReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadBackingReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreProperties(arg1 volume.Properties) error {
+	fake.storePropertiesMutex.Lock()
+	ret, specificReturn := fake.storePropertiesReturnsOnCall[len(fake.storePropertiesArgsForCall)]
+	fake.storePropertiesArgsForCall = append(fake.storePropertiesArgsForCall, struct {
+		arg1 volume.Properties
+	}{arg1})
+	fake.recordInvocation("StoreProperties", []interface{}{arg1})
+	fake.storePropertiesMutex.Unlock()
+	if fake.StorePropertiesStub != nil {
+		return fake.StorePropertiesStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storePropertiesReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StorePropertiesCallCount() int {
+	fake.storePropertiesMutex.RLock()
+	defer fake.storePropertiesMutex.RUnlock()
+	return len(fake.storePropertiesArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StorePropertiesArgsForCall(i int) volume.Properties {
+	fake.storePropertiesMutex.RLock()
+	defer fake.storePropertiesMutex.RUnlock()
+	return fake.storePropertiesArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemLiveVolume) StorePropertiesReturns(result1 error) {
+	fake.StorePropertiesStub = nil
+	fake.storePropertiesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StorePropertiesReturnsOnCall(i int, result1 error) {
+	fake.StorePropertiesStub = nil
+	if fake.storePropertiesReturnsOnCall == nil {
+		fake.storePropertiesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storePropertiesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadTTL() (volume.TTL, time.Time, error) {
 	fake.loadTTLMutex.Lock()
 	ret, specificReturn := fake.loadTTLReturnsOnCall[len(fake.loadTTLArgsForCall)]
 	fake.loadTTLArgsForCall = append(fake.loadTTLArgsForCall, struct{}{})
@@ -415,6 +1055,55 @@ func (fake *FakeFilesystemLiveVolume) StoreTTLReturnsOnCall(i int, result1 time.
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystemLiveVolume) StoreExpiry(arg1 volume.TTL, arg2 time.Time) error {
+	fake.storeExpiryMutex.Lock()
+	ret, specificReturn := fake.storeExpiryReturnsOnCall[len(fake.storeExpiryArgsForCall)]
+	fake.storeExpiryArgsForCall = append(fake.storeExpiryArgsForCall, struct {
+		arg1 volume.TTL
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("StoreExpiry", []interface{}{arg1, arg2})
+	fake.storeExpiryMutex.Unlock()
+	if fake.StoreExpiryStub != nil {
+		return fake.StoreExpiryStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeExpiryReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreExpiryCallCount() int {
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
+	return len(fake.storeExpiryArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreExpiryArgsForCall(i int) (volume.TTL, time.Time) {
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
+	return fake.storeExpiryArgsForCall[i].arg1, fake.storeExpiryArgsForCall[i].arg2
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreExpiryReturns(result1 error) {
+	fake.StoreExpiryStub = nil
+	fake.storeExpiryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreExpiryReturnsOnCall(i int, result1 error) {
+	fake.StoreExpiryStub = nil
+	if fake.storeExpiryReturnsOnCall == nil {
+		fake.storeExpiryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeExpiryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystemLiveVolume) LoadPrivileged() (bool, error) {
 	fake.loadPrivilegedMutex.Lock()
 	ret, specificReturn := fake.loadPrivilegedReturnsOnCall[len(fake.loadPrivilegedArgsForCall)]
@@ -506,186 +1195,1564 @@ func (fake *FakeFilesystemLiveVolume) StorePrivilegedReturnsOnCall(i int, result
 	}{result1}
 }
 
-func (fake *FakeFilesystemLiveVolume) Parent() (volume.FilesystemLiveVolume, bool, error) {
-	fake.parentMutex.Lock()
-	ret, specificReturn := fake.parentReturnsOnCall[len(fake.parentArgsForCall)]
-	fake.parentArgsForCall = append(fake.parentArgsForCall, struct{}{})
-	fake.recordInvocation("Parent", []interface{}{})
-	fake.parentMutex.Unlock()
-	if fake.ParentStub != nil {
-		return fake.ParentStub()
+func (fake *FakeFilesystemLiveVolume) LoadCommitted() (time.Time, bool, error) {
+	fake.loadCommittedMutex.Lock()
+	ret, specificReturn := fake.loadCommittedReturnsOnCall[len(fake.loadCommittedArgsForCall)]
+	fake.loadCommittedArgsForCall = append(fake.loadCommittedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCommitted", []interface{}{})
+	fake.loadCommittedMutex.Unlock()
+	if fake.LoadCommittedStub != nil {
+		return fake.LoadCommittedStub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
 	}
-	return fake.parentReturns.result1, fake.parentReturns.result2, fake.parentReturns.result3
+	return fake.loadCommittedReturns.result1, fake.loadCommittedReturns.result2, fake.loadCommittedReturns.result3
 }
 
-func (fake *FakeFilesystemLiveVolume) ParentCallCount() int {
-	fake.parentMutex.RLock()
-	defer fake.parentMutex.RUnlock()
-	return len(fake.parentArgsForCall)
+func (fake *FakeFilesystemLiveVolume) LoadCommittedCallCount() int {
+	fake.loadCommittedMutex.RLock()
+	defer fake.loadCommittedMutex.RUnlock()
+	return len(fake.loadCommittedArgsForCall)
 }
 
-func (fake *FakeFilesystemLiveVolume) ParentReturns(result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
-	fake.ParentStub = nil
-	fake.parentReturns = struct {
-		result1 volume.FilesystemLiveVolume
+func (fake *FakeFilesystemLiveVolume) LoadCommittedReturns(result1 time.Time, result2 bool, result3 error) {
+	fake.LoadCommittedStub = nil
+	fake.loadCommittedReturns = struct {
+		result1 time.Time
 		result2 bool
 		result3 error
 	}{result1, result2, result3}
 }
 
-func (fake *FakeFilesystemLiveVolume) ParentReturnsOnCall(i int, result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
-	fake.ParentStub = nil
-	if fake.parentReturnsOnCall == nil {
-		fake.parentReturnsOnCall = make(map[int]struct {
-			result1 volume.FilesystemLiveVolume
+func (fake *FakeFilesystemLiveVolume) LoadCommittedReturnsOnCall(i int, result1 time.Time, result2 bool, result3 error) {
+	fake.LoadCommittedStub = nil
+	if fake.loadCommittedReturnsOnCall == nil {
+		fake.loadCommittedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
 			result2 bool
 			result3 error
 		})
 	}
-	fake.parentReturnsOnCall[i] = struct {
-		result1 volume.FilesystemLiveVolume
+	fake.loadCommittedReturnsOnCall[i] = struct {
+		result1 time.Time
 		result2 bool
 		result3 error
 	}{result1, result2, result3}
 }
 
-func (fake *FakeFilesystemLiveVolume) Destroy() error {
-	fake.destroyMutex.Lock()
-	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
-	fake.destroyArgsForCall = append(fake.destroyArgsForCall, struct{}{})
-	fake.recordInvocation("Destroy", []interface{}{})
-	fake.destroyMutex.Unlock()
-	if fake.DestroyStub != nil {
-		return fake.DestroyStub()
+func (fake *FakeFilesystemLiveVolume) StoreCommitted(arg1 bool) (time.Time, error) {
+	fake.storeCommittedMutex.Lock()
+	ret, specificReturn := fake.storeCommittedReturnsOnCall[len(fake.storeCommittedArgsForCall)]
+	fake.storeCommittedArgsForCall = append(fake.storeCommittedArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	fake.recordInvocation("StoreCommitted", []interface{}{arg1})
+	fake.storeCommittedMutex.Unlock()
+	if fake.StoreCommittedStub != nil {
+		return fake.StoreCommittedStub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.destroyReturns.result1
+	return fake.storeCommittedReturns.result1, fake.storeCommittedReturns.result2
 }
 
-func (fake *FakeFilesystemLiveVolume) DestroyCallCount() int {
-	fake.destroyMutex.RLock()
-	defer fake.destroyMutex.RUnlock()
-	return len(fake.destroyArgsForCall)
+func (fake *FakeFilesystemLiveVolume) StoreCommittedCallCount() int {
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	return len(fake.storeCommittedArgsForCall)
 }
 
-func (fake *FakeFilesystemLiveVolume) DestroyReturns(result1 error) {
-	fake.DestroyStub = nil
-	fake.destroyReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeFilesystemLiveVolume) StoreCommittedArgsForCall(i int) bool {
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	return fake.storeCommittedArgsForCall[i].arg1
 }
 
-func (fake *FakeFilesystemLiveVolume) DestroyReturnsOnCall(i int, result1 error) {
-	fake.DestroyStub = nil
-	if fake.destroyReturnsOnCall == nil {
-		fake.destroyReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeFilesystemLiveVolume) StoreCommittedReturns(result1 time.Time, result2 error) {
+	fake.StoreCommittedStub = nil
+	fake.storeCommittedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCommittedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreCommittedStub = nil
+	if fake.storeCommittedReturnsOnCall == nil {
+		fake.storeCommittedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
 		})
 	}
-	fake.destroyReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.storeCommittedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) SizeInBytes() (int64, error) {
-	fake.sizeInBytesMutex.Lock()
-	ret, specificReturn := fake.sizeInBytesReturnsOnCall[len(fake.sizeInBytesArgsForCall)]
-	fake.sizeInBytesArgsForCall = append(fake.sizeInBytesArgsForCall, struct{}{})
-	fake.recordInvocation("SizeInBytes", []interface{}{})
-	fake.sizeInBytesMutex.Unlock()
-	if fake.SizeInBytesStub != nil {
-		return fake.SizeInBytesStub()
+func (fake *FakeFilesystemLiveVolume) LoadLastAccessed() (time.Time, error) {
+	fake.loadLastAccessedMutex.Lock()
+	ret, specificReturn := fake.loadLastAccessedReturnsOnCall[len(fake.loadLastAccessedArgsForCall)]
+	fake.loadLastAccessedArgsForCall = append(fake.loadLastAccessedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadLastAccessed", []interface{}{})
+	fake.loadLastAccessedMutex.Unlock()
+	if fake.LoadLastAccessedStub != nil {
+		return fake.LoadLastAccessedStub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.sizeInBytesReturns.result1, fake.sizeInBytesReturns.result2
+	return fake.loadLastAccessedReturns.result1, fake.loadLastAccessedReturns.result2
 }
 
-func (fake *FakeFilesystemLiveVolume) SizeInBytesCallCount() int {
-	fake.sizeInBytesMutex.RLock()
-	defer fake.sizeInBytesMutex.RUnlock()
-	return len(fake.sizeInBytesArgsForCall)
+func (fake *FakeFilesystemLiveVolume) LoadLastAccessedCallCount() int {
+	fake.loadLastAccessedMutex.RLock()
+	defer fake.loadLastAccessedMutex.RUnlock()
+	return len(fake.loadLastAccessedArgsForCall)
 }
 
-func (fake *FakeFilesystemLiveVolume) SizeInBytesReturns(result1 int64, result2 error) {
-	fake.SizeInBytesStub = nil
-	fake.sizeInBytesReturns = struct {
-		result1 int64
+func (fake *FakeFilesystemLiveVolume) LoadLastAccessedReturns(result1 time.Time, result2 error) {
+	fake.LoadLastAccessedStub = nil
+	fake.loadLastAccessedReturns = struct {
+		result1 time.Time
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) SizeInBytesReturnsOnCall(i int, result1 int64, result2 error) {
-	fake.SizeInBytesStub = nil
-	if fake.sizeInBytesReturnsOnCall == nil {
-		fake.sizeInBytesReturnsOnCall = make(map[int]struct {
-			result1 int64
+func (fake *FakeFilesystemLiveVolume) LoadLastAccessedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.LoadLastAccessedStub = nil
+	if fake.loadLastAccessedReturnsOnCall == nil {
+		fake.loadLastAccessedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
 			result2 error
 		})
 	}
-	fake.sizeInBytesReturnsOnCall[i] = struct {
-		result1 int64
+	fake.loadLastAccessedReturnsOnCall[i] = struct {
+		result1 time.Time
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) NewSubvolume(handle string) (volume.FilesystemInitVolume, error) {
-	fake.newSubvolumeMutex.Lock()
-	ret, specificReturn := fake.newSubvolumeReturnsOnCall[len(fake.newSubvolumeArgsForCall)]
-	fake.newSubvolumeArgsForCall = append(fake.newSubvolumeArgsForCall, struct {
-		handle string
-	}{handle})
-	fake.recordInvocation("NewSubvolume", []interface{}{handle})
-	fake.newSubvolumeMutex.Unlock()
-	if fake.NewSubvolumeStub != nil {
-		return fake.NewSubvolumeStub(handle)
+func (fake *FakeFilesystemLiveVolume) StoreLastAccessed() (time.Time, error) {
+	fake.storeLastAccessedMutex.Lock()
+	ret, specificReturn := fake.storeLastAccessedReturnsOnCall[len(fake.storeLastAccessedArgsForCall)]
+	fake.storeLastAccessedArgsForCall = append(fake.storeLastAccessedArgsForCall, struct{}{})
+	fake.recordInvocation("StoreLastAccessed", []interface{}{})
+	fake.storeLastAccessedMutex.Unlock()
+	if fake.StoreLastAccessedStub != nil {
+		return fake.StoreLastAccessedStub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.newSubvolumeReturns.result1, fake.newSubvolumeReturns.result2
-}
-
-func (fake *FakeFilesystemLiveVolume) NewSubvolumeCallCount() int {
-	fake.newSubvolumeMutex.RLock()
-	defer fake.newSubvolumeMutex.RUnlock()
-	return len(fake.newSubvolumeArgsForCall)
+	return fake.storeLastAccessedReturns.result1, fake.storeLastAccessedReturns.result2
 }
 
-func (fake *FakeFilesystemLiveVolume) NewSubvolumeArgsForCall(i int) string {
-	fake.newSubvolumeMutex.RLock()
-	defer fake.newSubvolumeMutex.RUnlock()
-	return fake.newSubvolumeArgsForCall[i].handle
+func (fake *FakeFilesystemLiveVolume) StoreLastAccessedCallCount() int {
+	fake.storeLastAccessedMutex.RLock()
+	defer fake.storeLastAccessedMutex.RUnlock()
+	return len(fake.storeLastAccessedArgsForCall)
 }
 
-func (fake *FakeFilesystemLiveVolume) NewSubvolumeReturns(result1 volume.FilesystemInitVolume, result2 error) {
-	fake.NewSubvolumeStub = nil
-	fake.newSubvolumeReturns = struct {
-		result1 volume.FilesystemInitVolume
+func (fake *FakeFilesystemLiveVolume) StoreLastAccessedReturns(result1 time.Time, result2 error) {
+	fake.StoreLastAccessedStub = nil
+	fake.storeLastAccessedReturns = struct {
+		result1 time.Time
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeFilesystemLiveVolume) NewSubvolumeReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
-	fake.NewSubvolumeStub = nil
-	if fake.newSubvolumeReturnsOnCall == nil {
-		fake.newSubvolumeReturnsOnCall = make(map[int]struct {
-			result1 volume.FilesystemInitVolume
+func (fake *FakeFilesystemLiveVolume) StoreLastAccessedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreLastAccessedStub = nil
+	if fake.storeLastAccessedReturnsOnCall == nil {
+		fake.storeLastAccessedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
 			result2 error
 		})
 	}
-	fake.newSubvolumeReturnsOnCall[i] = struct {
+	fake.storeLastAccessedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreated() (time.Time, string, error) {
+	fake.loadCreatedMutex.Lock()
+	ret, specificReturn := fake.loadCreatedReturnsOnCall[len(fake.loadCreatedArgsForCall)]
+	fake.loadCreatedArgsForCall = append(fake.loadCreatedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCreated", []interface{}{})
+	fake.loadCreatedMutex.Unlock()
+	if fake.LoadCreatedStub != nil {
+		return fake.LoadCreatedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadCreatedReturns.result1, fake.loadCreatedReturns.result2, fake.loadCreatedReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreatedCallCount() int {
+	fake.loadCreatedMutex.RLock()
+	defer fake.loadCreatedMutex.RUnlock()
+	return len(fake.loadCreatedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreatedReturns(result1 time.Time, result2 string, result3 error) {
+	fake.LoadCreatedStub = nil
+	fake.loadCreatedReturns = struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreatedReturnsOnCall(i int, result1 time.Time, result2 string, result3 error) {
+	fake.LoadCreatedStub = nil
+	if fake.loadCreatedReturnsOnCall == nil {
+		fake.loadCreatedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadCreatedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreated(arg1 string) (time.Time, error) {
+	fake.storeCreatedMutex.Lock()
+	ret, specificReturn := fake.storeCreatedReturnsOnCall[len(fake.storeCreatedArgsForCall)]
+	fake.storeCreatedArgsForCall = append(fake.storeCreatedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StoreCreated", []interface{}{arg1})
+	fake.storeCreatedMutex.Unlock()
+	if fake.StoreCreatedStub != nil {
+		return fake.StoreCreatedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeCreatedReturns.result1, fake.storeCreatedReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreatedCallCount() int {
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	return len(fake.storeCreatedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreatedArgsForCall(i int) string {
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	return fake.storeCreatedArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreatedReturns(result1 time.Time, result2 error) {
+	fake.StoreCreatedStub = nil
+	fake.storeCreatedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreatedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreCreatedStub = nil
+	if fake.storeCreatedReturnsOnCall == nil {
+		fake.storeCreatedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeCreatedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadModified() (time.Time, error) {
+	fake.loadModifiedMutex.Lock()
+	ret, specificReturn := fake.loadModifiedReturnsOnCall[len(fake.loadModifiedArgsForCall)]
+	fake.loadModifiedArgsForCall = append(fake.loadModifiedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadModified", []interface{}{})
+	fake.loadModifiedMutex.Unlock()
+	if fake.LoadModifiedStub != nil {
+		return fake.LoadModifiedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadModifiedReturns.result1, fake.loadModifiedReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadModifiedCallCount() int {
+	fake.loadModifiedMutex.RLock()
+	defer fake.loadModifiedMutex.RUnlock()
+	return len(fake.loadModifiedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadModifiedReturns(result1 time.Time, result2 error) {
+	fake.LoadModifiedStub = nil
+	fake.loadModifiedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadModifiedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.LoadModifiedStub = nil
+	if fake.loadModifiedReturnsOnCall == nil {
+		fake.loadModifiedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.loadModifiedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreModified() (time.Time, error) {
+	fake.storeModifiedMutex.Lock()
+	ret, specificReturn := fake.storeModifiedReturnsOnCall[len(fake.storeModifiedArgsForCall)]
+	fake.storeModifiedArgsForCall = append(fake.storeModifiedArgsForCall, struct{}{})
+	fake.recordInvocation("StoreModified", []interface{}{})
+	fake.storeModifiedMutex.Unlock()
+	if fake.StoreModifiedStub != nil {
+		return fake.StoreModifiedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.storeModifiedReturns.result1, fake.storeModifiedReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreModifiedCallCount() int {
+	fake.storeModifiedMutex.RLock()
+	defer fake.storeModifiedMutex.RUnlock()
+	return len(fake.storeModifiedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreModifiedReturns(result1 time.Time, result2 error) {
+	fake.StoreModifiedStub = nil
+	fake.storeModifiedReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreModifiedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.StoreModifiedStub = nil
+	if fake.storeModifiedReturnsOnCall == nil {
+		fake.storeModifiedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.storeModifiedReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDigest() (string, error) {
+	fake.loadDigestMutex.Lock()
+	ret, specificReturn := fake.loadDigestReturnsOnCall[len(fake.loadDigestArgsForCall)]
+	fake.loadDigestArgsForCall = append(fake.loadDigestArgsForCall, struct{}{})
+	fake.recordInvocation("LoadDigest", []interface{}{})
+	fake.loadDigestMutex.Unlock()
+	if fake.LoadDigestStub != nil {
+		return fake.LoadDigestStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadDigestReturns.result1, fake.loadDigestReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDigestCallCount() int {
+	fake.loadDigestMutex.RLock()
+	defer fake.loadDigestMutex.RUnlock()
+	return len(fake.loadDigestArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDigestReturns(result1 string, result2 error) {
+	fake.LoadDigestStub = nil
+	fake.loadDigestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.LoadDigestStub = nil
+	if fake.loadDigestReturnsOnCall == nil {
+		fake.loadDigestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.loadDigestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDigest(arg1 string) error {
+	fake.storeDigestMutex.Lock()
+	ret, specificReturn := fake.storeDigestReturnsOnCall[len(fake.storeDigestArgsForCall)]
+	fake.storeDigestArgsForCall = append(fake.storeDigestArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("StoreDigest", []interface{}{arg1})
+	fake.storeDigestMutex.Unlock()
+	if fake.StoreDigestStub != nil {
+		return fake.StoreDigestStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeDigestReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDigestCallCount() int {
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	return len(fake.storeDigestArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDigestArgsForCall(i int) string {
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	return fake.storeDigestArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDigestReturns(result1 error) {
+	fake.StoreDigestStub = nil
+	fake.storeDigestReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDigestReturnsOnCall(i int, result1 error) {
+	fake.StoreDigestStub = nil
+	if fake.storeDigestReturnsOnCall == nil {
+		fake.storeDigestReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeDigestReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadStreamInKeys() (map[string]time.Time, error) {
+	fake.loadStreamInKeysMutex.Lock()
+	ret, specificReturn := fake.loadStreamInKeysReturnsOnCall[len(fake.loadStreamInKeysArgsForCall)]
+	fake.loadStreamInKeysArgsForCall = append(fake.loadStreamInKeysArgsForCall, struct{}{})
+	fake.recordInvocation("LoadStreamInKeys", []interface{}{})
+	fake.loadStreamInKeysMutex.Unlock()
+	if fake.LoadStreamInKeysStub != nil {
+		return fake.LoadStreamInKeysStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.loadStreamInKeysReturns.result1, fake.loadStreamInKeysReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadStreamInKeysCallCount() int {
+	fake.loadStreamInKeysMutex.RLock()
+	defer fake.loadStreamInKeysMutex.RUnlock()
+	return len(fake.loadStreamInKeysArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadStreamInKeysReturns(result1 map[string]time.Time, result2 error) {
+	fake.LoadStreamInKeysStub = nil
+	fake.loadStreamInKeysReturns = struct {
+		result1 map[string]time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadStreamInKeysReturnsOnCall(i int, result1 map[string]time.Time, result2 error) {
+	fake.LoadStreamInKeysStub = nil
+	if fake.loadStreamInKeysReturnsOnCall == nil {
+		fake.loadStreamInKeysReturnsOnCall = make(map[int]struct {
+			result1 map[string]time.Time
+			result2 error
+		})
+	}
+	fake.loadStreamInKeysReturnsOnCall[i] = struct {
+		result1 map[string]time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreStreamInKeys(arg1 map[string]time.Time) error {
+	fake.storeStreamInKeysMutex.Lock()
+	ret, specificReturn := fake.storeStreamInKeysReturnsOnCall[len(fake.storeStreamInKeysArgsForCall)]
+	fake.storeStreamInKeysArgsForCall = append(fake.storeStreamInKeysArgsForCall, struct {
+		arg1 map[string]time.Time
+	}{arg1})
+	fake.recordInvocation("StoreStreamInKeys", []interface{}{arg1})
+	fake.storeStreamInKeysMutex.Unlock()
+	if fake.StoreStreamInKeysStub != nil {
+		return fake.StoreStreamInKeysStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeStreamInKeysReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreStreamInKeysCallCount() int {
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	return len(fake.storeStreamInKeysArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreStreamInKeysArgsForCall(i int) map[string]time.Time {
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	return fake.storeStreamInKeysArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreStreamInKeysReturns(result1 error) {
+	fake.StoreStreamInKeysStub = nil
+	fake.storeStreamInKeysReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreStreamInKeysReturnsOnCall(i int, result1 error) {
+	fake.StoreStreamInKeysStub = nil
+	if fake.storeStreamInKeysReturnsOnCall == nil {
+		fake.storeStreamInKeysReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeStreamInKeysReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreateKey() (string, string, error) {
+	fake.loadCreateKeyMutex.Lock()
+	ret, specificReturn := fake.loadCreateKeyReturnsOnCall[len(fake.loadCreateKeyArgsForCall)]
+	fake.loadCreateKeyArgsForCall = append(fake.loadCreateKeyArgsForCall, struct{}{})
+	fake.recordInvocation("LoadCreateKey", []interface{}{})
+	fake.loadCreateKeyMutex.Unlock()
+	if fake.LoadCreateKeyStub != nil {
+		return fake.LoadCreateKeyStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadCreateKeyReturns.result1, fake.loadCreateKeyReturns.result2, fake.loadCreateKeyReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreateKeyCallCount() int {
+	fake.loadCreateKeyMutex.RLock()
+	defer fake.loadCreateKeyMutex.RUnlock()
+	return len(fake.loadCreateKeyArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreateKeyReturns(result1 string, result2 string, result3 error) {
+	fake.LoadCreateKeyStub = nil
+	fake.loadCreateKeyReturns = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadCreateKeyReturnsOnCall(i int, result1 string, result2 string, result3 error) {
+	fake.LoadCreateKeyStub = nil
+	if fake.loadCreateKeyReturnsOnCall == nil {
+		fake.loadCreateKeyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadCreateKeyReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreateKey(key string, fingerprint string) error {
+	fake.storeCreateKeyMutex.Lock()
+	ret, specificReturn := fake.storeCreateKeyReturnsOnCall[len(fake.storeCreateKeyArgsForCall)]
+	fake.storeCreateKeyArgsForCall = append(fake.storeCreateKeyArgsForCall, struct {
+		key         string
+		fingerprint string
+	}{key, fingerprint})
+	fake.recordInvocation("StoreCreateKey", []interface{}{key, fingerprint})
+	fake.storeCreateKeyMutex.Unlock()
+	if fake.StoreCreateKeyStub != nil {
+		return fake.StoreCreateKeyStub(key, fingerprint)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeCreateKeyReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreateKeyCallCount() int {
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	return len(fake.storeCreateKeyArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreateKeyArgsForCall(i int) (string, string) {
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	return fake.storeCreateKeyArgsForCall[i].key, fake.storeCreateKeyArgsForCall[i].fingerprint
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreateKeyReturns(result1 error) {
+	fake.StoreCreateKeyStub = nil
+	fake.storeCreateKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreCreateKeyReturnsOnCall(i int, result1 error) {
+	fake.StoreCreateKeyStub = nil
+	if fake.storeCreateKeyReturnsOnCall == nil {
+		fake.storeCreateKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeCreateKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadReleased() (volume.DestroyOptions, bool, error) {
+	fake.loadReleasedMutex.Lock()
+	ret, specificReturn := fake.loadReleasedReturnsOnCall[len(fake.loadReleasedArgsForCall)]
+	fake.loadReleasedArgsForCall = append(fake.loadReleasedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadReleased", []interface{}{})
+	fake.loadReleasedMutex.Unlock()
+	if fake.LoadReleasedStub != nil {
+		return fake.LoadReleasedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.loadReleasedReturns.result1, fake.loadReleasedReturns.result2, fake.loadReleasedReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadReleasedCallCount() int {
+	fake.loadReleasedMutex.RLock()
+	defer fake.loadReleasedMutex.RUnlock()
+	return len(fake.loadReleasedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadReleasedReturns(result1 volume.DestroyOptions, result2 bool, result3 error) {
+	fake.LoadReleasedStub = nil
+	fake.loadReleasedReturns = struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadReleasedReturnsOnCall(i int, result1 volume.DestroyOptions, result2 bool, result3 error) {
+	fake.LoadReleasedStub = nil
+	if fake.loadReleasedReturnsOnCall == nil {
+		fake.loadReleasedReturnsOnCall = make(map[int]struct {
+			result1 volume.DestroyOptions
+			result2 bool
+			result3 error
+		})
+	}
+	fake.loadReleasedReturnsOnCall[i] = struct {
+		result1 volume.DestroyOptions
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreReleased(arg1 volume.DestroyOptions) error {
+	fake.storeReleasedMutex.Lock()
+	ret, specificReturn := fake.storeReleasedReturnsOnCall[len(fake.storeReleasedArgsForCall)]
+	fake.storeReleasedArgsForCall = append(fake.storeReleasedArgsForCall, struct {
+		arg1 volume.DestroyOptions
+	}{arg1})
+	fake.recordInvocation("StoreReleased", []interface{}{arg1})
+	fake.storeReleasedMutex.Unlock()
+	if fake.StoreReleasedStub != nil {
+		return fake.StoreReleasedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeReleasedReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreReleasedCallCount() int {
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	return len(fake.storeReleasedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreReleasedArgsForCall(i int) volume.DestroyOptions {
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	return fake.storeReleasedArgsForCall[i].arg1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreReleasedReturns(result1 error) {
+	fake.StoreReleasedStub = nil
+	fake.storeReleasedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreReleasedReturnsOnCall(i int, result1 error) {
+	fake.StoreReleasedStub = nil
+	if fake.storeReleasedReturnsOnCall == nil {
+		fake.storeReleasedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeReleasedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreRenewTTLOnAccess() error {
+	fake.storeRenewTTLOnAccessMutex.Lock()
+	ret, specificReturn := fake.storeRenewTTLOnAccessReturnsOnCall[len(fake.storeRenewTTLOnAccessArgsForCall)]
+	fake.storeRenewTTLOnAccessArgsForCall = append(fake.storeRenewTTLOnAccessArgsForCall, struct{}{})
+	fake.recordInvocation("StoreRenewTTLOnAccess", []interface{}{})
+	fake.storeRenewTTLOnAccessMutex.Unlock()
+	if fake.StoreRenewTTLOnAccessStub != nil {
+		return fake.StoreRenewTTLOnAccessStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeRenewTTLOnAccessReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreRenewTTLOnAccessCallCount() int {
+	fake.storeRenewTTLOnAccessMutex.RLock()
+	defer fake.storeRenewTTLOnAccessMutex.RUnlock()
+	return len(fake.storeRenewTTLOnAccessArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreRenewTTLOnAccessReturns(result1 error) {
+	fake.StoreRenewTTLOnAccessStub = nil
+	fake.storeRenewTTLOnAccessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreRenewTTLOnAccessReturnsOnCall(i int, result1 error) {
+	fake.StoreRenewTTLOnAccessStub = nil
+	if fake.storeRenewTTLOnAccessReturnsOnCall == nil {
+		fake.storeRenewTTLOnAccessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRenewTTLOnAccessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) Parent() (volume.FilesystemLiveVolume, bool, error) {
+	fake.parentMutex.Lock()
+	ret, specificReturn := fake.parentReturnsOnCall[len(fake.parentArgsForCall)]
+	fake.parentArgsForCall = append(fake.parentArgsForCall, struct{}{})
+	fake.recordInvocation("Parent", []interface{}{})
+	fake.parentMutex.Unlock()
+	if fake.ParentStub != nil {
+		return fake.ParentStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.parentReturns.result1, fake.parentReturns.result2, fake.parentReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) ParentCallCount() int {
+	fake.parentMutex.RLock()
+	defer fake.parentMutex.RUnlock()
+	return len(fake.parentArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) ParentReturns(result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
+	fake.ParentStub = nil
+	fake.parentReturns = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) ParentReturnsOnCall(i int, result1 volume.FilesystemLiveVolume, result2 bool, result3 error) {
+	fake.ParentStub = nil
+	if fake.parentReturnsOnCall == nil {
+		fake.parentReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemLiveVolume
+			result2 bool
+			result3 error
+		})
+	}
+	fake.parentReturnsOnCall[i] = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) Destroy() error {
+	fake.destroyMutex.Lock()
+	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
+	fake.destroyArgsForCall = append(fake.destroyArgsForCall, struct{}{})
+	fake.recordInvocation("Destroy", []interface{}{})
+	fake.destroyMutex.Unlock()
+	if fake.DestroyStub != nil {
+		return fake.DestroyStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.destroyReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) DestroyCallCount() int {
+	fake.destroyMutex.RLock()
+	defer fake.destroyMutex.RUnlock()
+	return len(fake.destroyArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) DestroyReturns(result1 error) {
+	fake.DestroyStub = nil
+	fake.destroyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) DestroyReturnsOnCall(i int, result1 error) {
+	fake.DestroyStub = nil
+	if fake.destroyReturnsOnCall == nil {
+		fake.destroyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.destroyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) Stats() (volume.VolumeStats, error) {
+	fake.statsMutex.Lock()
+	ret, specificReturn := fake.statsReturnsOnCall[len(fake.statsArgsForCall)]
+	fake.statsArgsForCall = append(fake.statsArgsForCall, struct{}{})
+	fake.recordInvocation("Stats", []interface{}{})
+	fake.statsMutex.Unlock()
+	if fake.StatsStub != nil {
+		return fake.StatsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.statsReturns.result1, fake.statsReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) StatsCallCount() int {
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	return len(fake.statsArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StatsReturns(result1 volume.VolumeStats, result2 error) {
+	fake.StatsStub = nil
+	fake.statsReturns = struct {
+		result1 volume.VolumeStats
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) StatsReturnsOnCall(i int, result1 volume.VolumeStats, result2 error) {
+	fake.StatsStub = nil
+	if fake.statsReturnsOnCall == nil {
+		fake.statsReturnsOnCall = make(map[int]struct {
+			result1 volume.VolumeStats
+			result2 error
+		})
+	}
+	fake.statsReturnsOnCall[i] = struct {
+		result1 volume.VolumeStats
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDeleted() (volume.DestroyOptions, time.Time, bool, error) {
+	fake.loadDeletedMutex.Lock()
+	ret, specificReturn := fake.loadDeletedReturnsOnCall[len(fake.loadDeletedArgsForCall)]
+	fake.loadDeletedArgsForCall = append(fake.loadDeletedArgsForCall, struct{}{})
+	fake.recordInvocation("LoadDeleted", []interface{}{})
+	fake.loadDeletedMutex.Unlock()
+	if fake.LoadDeletedStub != nil {
+		return fake.LoadDeletedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3, ret.result4
+	}
+	return fake.loadDeletedReturns.result1, fake.loadDeletedReturns.result2, fake.loadDeletedReturns.result3, fake.loadDeletedReturns.result4
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDeletedCallCount() int {
+	fake.loadDeletedMutex.RLock()
+	defer fake.loadDeletedMutex.RUnlock()
+	return len(fake.loadDeletedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDeletedReturns(result1 volume.DestroyOptions, result2 time.Time, result3 bool, result4 error) {
+	fake.LoadDeletedStub = nil
+	fake.loadDeletedReturns = struct {
+		result1 volume.DestroyOptions
+		result2 time.Time
+		result3 bool
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeFilesystemLiveVolume) LoadDeletedReturnsOnCall(i int, result1 volume.DestroyOptions, result2 time.Time, result3 bool, result4 error) {
+	fake.LoadDeletedStub = nil
+	if fake.loadDeletedReturnsOnCall == nil {
+		fake.loadDeletedReturnsOnCall = make(map[int]struct {
+			result1 volume.DestroyOptions
+			result2 time.Time
+			result3 bool
+			result4 error
+		})
+	}
+	fake.loadDeletedReturnsOnCall[i] = struct {
+		result1 volume.DestroyOptions
+		result2 time.Time
+		result3 bool
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDeleted(arg1 volume.DestroyOptions, arg2 time.Time) error {
+	fake.storeDeletedMutex.Lock()
+	ret, specificReturn := fake.storeDeletedReturnsOnCall[len(fake.storeDeletedArgsForCall)]
+	fake.storeDeletedArgsForCall = append(fake.storeDeletedArgsForCall, struct {
+		arg1 volume.DestroyOptions
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("StoreDeleted", []interface{}{arg1, arg2})
+	fake.storeDeletedMutex.Unlock()
+	if fake.StoreDeletedStub != nil {
+		return fake.StoreDeletedStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.storeDeletedReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDeletedCallCount() int {
+	fake.storeDeletedMutex.RLock()
+	defer fake.storeDeletedMutex.RUnlock()
+	return len(fake.storeDeletedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDeletedArgsForCall(i int) (volume.DestroyOptions, time.Time) {
+	fake.storeDeletedMutex.RLock()
+	defer fake.storeDeletedMutex.RUnlock()
+	return fake.storeDeletedArgsForCall[i].arg1, fake.storeDeletedArgsForCall[i].arg2
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDeletedReturns(result1 error) {
+	fake.StoreDeletedStub = nil
+	fake.storeDeletedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) StoreDeletedReturnsOnCall(i int, result1 error) {
+	fake.StoreDeletedStub = nil
+	if fake.storeDeletedReturnsOnCall == nil {
+		fake.storeDeletedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeDeletedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) ClearDeleted() error {
+	fake.clearDeletedMutex.Lock()
+	ret, specificReturn := fake.clearDeletedReturnsOnCall[len(fake.clearDeletedArgsForCall)]
+	fake.clearDeletedArgsForCall = append(fake.clearDeletedArgsForCall, struct{}{})
+	fake.recordInvocation("ClearDeleted", []interface{}{})
+	fake.clearDeletedMutex.Unlock()
+	if fake.ClearDeletedStub != nil {
+		return fake.ClearDeletedStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.clearDeletedReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) ClearDeletedCallCount() int {
+	fake.clearDeletedMutex.RLock()
+	defer fake.clearDeletedMutex.RUnlock()
+	return len(fake.clearDeletedArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) ClearDeletedReturns(result1 error) {
+	fake.ClearDeletedStub = nil
+	fake.clearDeletedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) ClearDeletedReturnsOnCall(i int, result1 error) {
+	fake.ClearDeletedStub = nil
+	if fake.clearDeletedReturnsOnCall == nil {
+		fake.clearDeletedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.clearDeletedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) Size() (int64, error) {
+	fake.sizeMutex.Lock()
+	ret, specificReturn := fake.sizeReturnsOnCall[len(fake.sizeArgsForCall)]
+	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct{}{})
+	fake.recordInvocation("Size", []interface{}{})
+	fake.sizeMutex.Unlock()
+	if fake.SizeStub != nil {
+		return fake.SizeStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.sizeReturns.result1, fake.sizeReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) SizeCallCount() int {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return len(fake.sizeArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) SizeReturns(result1 int64, result2 error) {
+	fake.SizeStub = nil
+	fake.sizeReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) SizeReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.SizeStub = nil
+	if fake.sizeReturnsOnCall == nil {
+		fake.sizeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.sizeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewSubvolume(handle string) (volume.FilesystemInitVolume, error) {
+	fake.newSubvolumeMutex.Lock()
+	ret, specificReturn := fake.newSubvolumeReturnsOnCall[len(fake.newSubvolumeArgsForCall)]
+	fake.newSubvolumeArgsForCall = append(fake.newSubvolumeArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("NewSubvolume", []interface{}{handle})
+	fake.newSubvolumeMutex.Unlock()
+	if fake.NewSubvolumeStub != nil {
+		return fake.NewSubvolumeStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.newSubvolumeReturns.result1, fake.newSubvolumeReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) NewSubvolumeCallCount() int {
+	fake.newSubvolumeMutex.RLock()
+	defer fake.newSubvolumeMutex.RUnlock()
+	return len(fake.newSubvolumeArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) NewSubvolumeArgsForCall(i int) string {
+	fake.newSubvolumeMutex.RLock()
+	defer fake.newSubvolumeMutex.RUnlock()
+	return fake.newSubvolumeArgsForCall[i].handle
+}
+
+func (fake *FakeFilesystemLiveVolume) NewSubvolumeReturns(result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewSubvolumeStub = nil
+	fake.newSubvolumeReturns = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewSubvolumeReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewSubvolumeStub = nil
+	if fake.newSubvolumeReturnsOnCall == nil {
+		fake.newSubvolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemInitVolume
+			result2 error
+		})
+	}
+	fake.newSubvolumeReturnsOnCall[i] = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewView(handle string) (volume.FilesystemInitVolume, error) {
+	fake.newViewMutex.Lock()
+	ret, specificReturn := fake.newViewReturnsOnCall[len(fake.newViewArgsForCall)]
+	fake.newViewArgsForCall = append(fake.newViewArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("NewView", []interface{}{handle})
+	fake.newViewMutex.Unlock()
+	if fake.NewViewStub != nil {
+		return fake.NewViewStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.newViewReturns.result1, fake.newViewReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) NewViewCallCount() int {
+	fake.newViewMutex.RLock()
+	defer fake.newViewMutex.RUnlock()
+	return len(fake.newViewArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) NewViewArgsForCall(i int) string {
+	fake.newViewMutex.RLock()
+	defer fake.newViewMutex.RUnlock()
+	return fake.newViewArgsForCall[i].handle
+}
+
+func (fake *FakeFilesystemLiveVolume) NewViewReturns(result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewViewStub = nil
+	fake.newViewReturns = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewViewReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewViewStub = nil
+	if fake.newViewReturnsOnCall == nil {
+		fake.newViewReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemInitVolume
+			result2 error
+		})
+	}
+	fake.newViewReturnsOnCall[i] = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) IsView() (bool, error) {
+	fake.isViewMutex.Lock()
+	ret, specificReturn := fake.isViewReturnsOnCall[len(fake.isViewArgsForCall)]
+	fake.isViewArgsForCall = append(fake.isViewArgsForCall, struct{}{})
+	fake.recordInvocation("IsView", []interface{}{})
+	fake.isViewMutex.Unlock()
+	if fake.IsViewStub != nil {
+		return fake.IsViewStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.isViewReturns.result1, fake.isViewReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) IsViewCallCount() int {
+	fake.isViewMutex.RLock()
+	defer fake.isViewMutex.RUnlock()
+	return len(fake.isViewArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) IsViewReturns(result1 bool, result2 error) {
+	fake.IsViewStub = nil
+	fake.isViewReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) IsViewReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.IsViewStub = nil
+	if fake.isViewReturnsOnCall == nil {
+		fake.isViewReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isViewReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewClone(handle string) (volume.FilesystemInitVolume, error) {
+	fake.newCloneMutex.Lock()
+	ret, specificReturn := fake.newCloneReturnsOnCall[len(fake.newCloneArgsForCall)]
+	fake.newCloneArgsForCall = append(fake.newCloneArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("NewClone", []interface{}{handle})
+	fake.newCloneMutex.Unlock()
+	if fake.NewCloneStub != nil {
+		return fake.NewCloneStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.newCloneReturns.result1, fake.newCloneReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) NewCloneCallCount() int {
+	fake.newCloneMutex.RLock()
+	defer fake.newCloneMutex.RUnlock()
+	return len(fake.newCloneArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) NewCloneArgsForCall(i int) string {
+	fake.newCloneMutex.RLock()
+	defer fake.newCloneMutex.RUnlock()
+	return fake.newCloneArgsForCall[i].handle
+}
+
+func (fake *FakeFilesystemLiveVolume) NewCloneReturns(result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewCloneStub = nil
+	fake.newCloneReturns = struct {
+		result1 volume.FilesystemInitVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) NewCloneReturnsOnCall(i int, result1 volume.FilesystemInitVolume, result2 error) {
+	fake.NewCloneStub = nil
+	if fake.newCloneReturnsOnCall == nil {
+		fake.newCloneReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemInitVolume
+			result2 error
+		})
+	}
+	fake.newCloneReturnsOnCall[i] = struct {
 		result1 volume.FilesystemInitVolume
 		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeFilesystemLiveVolume) CopyData(src string, dest string) error {
+	fake.copyDataMutex.Lock()
+	ret, specificReturn := fake.copyDataReturnsOnCall[len(fake.copyDataArgsForCall)]
+	fake.copyDataArgsForCall = append(fake.copyDataArgsForCall, struct {
+		src  string
+		dest string
+	}{src, dest})
+	fake.recordInvocation("CopyData", []interface{}{src, dest})
+	fake.copyDataMutex.Unlock()
+	if fake.CopyDataStub != nil {
+		return fake.CopyDataStub(src, dest)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.copyDataReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) CopyDataCallCount() int {
+	fake.copyDataMutex.RLock()
+	defer fake.copyDataMutex.RUnlock()
+	return len(fake.copyDataArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) CopyDataArgsForCall(i int) (string, string) {
+	fake.copyDataMutex.RLock()
+	defer fake.copyDataMutex.RUnlock()
+	return fake.copyDataArgsForCall[i].src, fake.copyDataArgsForCall[i].dest
+}
+
+func (fake *FakeFilesystemLiveVolume) CopyDataReturns(result1 error) {
+	fake.CopyDataStub = nil
+	fake.copyDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) CopyDataReturnsOnCall(i int, result1 error) {
+	fake.CopyDataStub = nil
+	if fake.copyDataReturnsOnCall == nil {
+		fake.copyDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) Snapshot() (string, func() error, error) {
+	fake.snapshotMutex.Lock()
+	ret, specificReturn := fake.snapshotReturnsOnCall[len(fake.snapshotArgsForCall)]
+	fake.snapshotArgsForCall = append(fake.snapshotArgsForCall, struct{}{})
+	fake.recordInvocation("Snapshot", []interface{}{})
+	fake.snapshotMutex.Unlock()
+	if fake.SnapshotStub != nil {
+		return fake.SnapshotStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.snapshotReturns.result1, fake.snapshotReturns.result2, fake.snapshotReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) SnapshotCallCount() int {
+	fake.snapshotMutex.RLock()
+	defer fake.snapshotMutex.RUnlock()
+	return len(fake.snapshotArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) SnapshotReturns(result1 string, result2 func() error, result3 error) {
+	fake.SnapshotStub = nil
+	fake.snapshotReturns = struct {
+		result1 string
+		result2 func() error
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) SnapshotReturnsOnCall(i int, result1 string, result2 func() error, result3 error) {
+	fake.SnapshotStub = nil
+	if fake.snapshotReturnsOnCall == nil {
+		fake.snapshotReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 func() error
+			result3 error
+		})
+	}
+	fake.snapshotReturnsOnCall[i] = struct {
+		result1 string
+		result2 func() error
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) Materialize() (bool, error) {
+	fake.materializeMutex.Lock()
+	ret, specificReturn := fake.materializeReturnsOnCall[len(fake.materializeArgsForCall)]
+	fake.materializeArgsForCall = append(fake.materializeArgsForCall, struct{}{})
+	fake.recordInvocation("Materialize", []interface{}{})
+	fake.materializeMutex.Unlock()
+	if fake.MaterializeStub != nil {
+		return fake.MaterializeStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.materializeReturns.result1, fake.materializeReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) MaterializeCallCount() int {
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	return len(fake.materializeArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) MaterializeReturns(result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	fake.materializeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) MaterializeReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	if fake.materializeReturnsOnCall == nil {
+		fake.materializeReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.materializeReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) Rename(handle string) (volume.FilesystemLiveVolume, error) {
+	fake.renameMutex.Lock()
+	ret, specificReturn := fake.renameReturnsOnCall[len(fake.renameArgsForCall)]
+	fake.renameArgsForCall = append(fake.renameArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Rename", []interface{}{handle})
+	fake.renameMutex.Unlock()
+	if fake.RenameStub != nil {
+		return fake.RenameStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.renameReturns.result1, fake.renameReturns.result2
+}
+
+func (fake *FakeFilesystemLiveVolume) RenameCallCount() int {
+	fake.renameMutex.RLock()
+	defer fake.renameMutex.RUnlock()
+	return len(fake.renameArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) RenameArgsForCall(i int) string {
+	fake.renameMutex.RLock()
+	defer fake.renameMutex.RUnlock()
+	return fake.renameArgsForCall[i].handle
+}
+
+func (fake *FakeFilesystemLiveVolume) RenameReturns(result1 volume.FilesystemLiveVolume, result2 error) {
+	fake.RenameStub = nil
+	fake.renameReturns = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) RenameReturnsOnCall(i int, result1 volume.FilesystemLiveVolume, result2 error) {
+	fake.RenameStub = nil
+	if fake.renameReturnsOnCall == nil {
+		fake.renameReturnsOnCall = make(map[int]struct {
+			result1 volume.FilesystemLiveVolume
+			result2 error
+		})
+	}
+	fake.renameReturnsOnCall[i] = struct {
+		result1 volume.FilesystemLiveVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFilesystemLiveVolume) Room() (uint64, bool, error) {
+	fake.roomMutex.Lock()
+	ret, specificReturn := fake.roomReturnsOnCall[len(fake.roomArgsForCall)]
+	fake.roomArgsForCall = append(fake.roomArgsForCall, struct{}{})
+	fake.recordInvocation("Room", []interface{}{})
+	fake.roomMutex.Unlock()
+	if fake.RoomStub != nil {
+		return fake.RoomStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.roomReturns.result1, fake.roomReturns.result2, fake.roomReturns.result3
+}
+
+func (fake *FakeFilesystemLiveVolume) RoomCallCount() int {
+	fake.roomMutex.RLock()
+	defer fake.roomMutex.RUnlock()
+	return len(fake.roomArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) RoomReturns(result1 uint64, result2 bool, result3 error) {
+	fake.RoomStub = nil
+	fake.roomReturns = struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) RoomReturnsOnCall(i int, result1 uint64, result2 bool, result3 error) {
+	fake.RoomStub = nil
+	if fake.roomReturnsOnCall == nil {
+		fake.roomReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 bool
+			result3 error
+		})
+	}
+	fake.roomReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeFilesystemLiveVolume) Promote() error {
+	fake.promoteMutex.Lock()
+	ret, specificReturn := fake.promoteReturnsOnCall[len(fake.promoteArgsForCall)]
+	fake.promoteArgsForCall = append(fake.promoteArgsForCall, struct{}{})
+	fake.recordInvocation("Promote", []interface{}{})
+	fake.promoteMutex.Unlock()
+	if fake.PromoteStub != nil {
+		return fake.PromoteStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.promoteReturns.result1
+}
+
+func (fake *FakeFilesystemLiveVolume) PromoteCallCount() int {
+	fake.promoteMutex.RLock()
+	defer fake.promoteMutex.RUnlock()
+	return len(fake.promoteArgsForCall)
+}
+
+func (fake *FakeFilesystemLiveVolume) PromoteReturns(result1 error) {
+	fake.PromoteStub = nil
+	fake.promoteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFilesystemLiveVolume) PromoteReturnsOnCall(i int, result1 error) {
+	fake.PromoteStub = nil
+	if fake.promoteReturnsOnCall == nil {
+		fake.promoteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.promoteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFilesystemLiveVolume) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -695,24 +2762,96 @@ func (fake *FakeFilesystemLiveVolume) Invocations() map[string][][]interface{} {
 	defer fake.dataPathMutex.RUnlock()
 	fake.loadPropertiesMutex.RLock()
 	defer fake.loadPropertiesMutex.RUnlock()
+	fake.loadReadOnlyMutex.RLock()
+	defer fake.loadReadOnlyMutex.RUnlock()
+	fake.loadRenewTTLOnAccessMutex.RLock()
+	defer fake.loadRenewTTLOnAccessMutex.RUnlock()
+	fake.loadMountOptionsMutex.RLock()
+	defer fake.loadMountOptionsMutex.RUnlock()
+	fake.loadEncryptedMutex.RLock()
+	defer fake.loadEncryptedMutex.RUnlock()
+	fake.loadBackingMutex.RLock()
+	defer fake.loadBackingMutex.RUnlock()
 	fake.storePropertiesMutex.RLock()
 	defer fake.storePropertiesMutex.RUnlock()
 	fake.loadTTLMutex.RLock()
 	defer fake.loadTTLMutex.RUnlock()
 	fake.storeTTLMutex.RLock()
 	defer fake.storeTTLMutex.RUnlock()
+	fake.storeExpiryMutex.RLock()
+	defer fake.storeExpiryMutex.RUnlock()
 	fake.loadPrivilegedMutex.RLock()
 	defer fake.loadPrivilegedMutex.RUnlock()
 	fake.storePrivilegedMutex.RLock()
 	defer fake.storePrivilegedMutex.RUnlock()
+	fake.loadCommittedMutex.RLock()
+	defer fake.loadCommittedMutex.RUnlock()
+	fake.storeCommittedMutex.RLock()
+	defer fake.storeCommittedMutex.RUnlock()
+	fake.loadLastAccessedMutex.RLock()
+	defer fake.loadLastAccessedMutex.RUnlock()
+	fake.storeLastAccessedMutex.RLock()
+	defer fake.storeLastAccessedMutex.RUnlock()
+	fake.loadCreatedMutex.RLock()
+	defer fake.loadCreatedMutex.RUnlock()
+	fake.storeCreatedMutex.RLock()
+	defer fake.storeCreatedMutex.RUnlock()
+	fake.loadModifiedMutex.RLock()
+	defer fake.loadModifiedMutex.RUnlock()
+	fake.storeModifiedMutex.RLock()
+	defer fake.storeModifiedMutex.RUnlock()
+	fake.loadDigestMutex.RLock()
+	defer fake.loadDigestMutex.RUnlock()
+	fake.storeDigestMutex.RLock()
+	defer fake.storeDigestMutex.RUnlock()
+	fake.loadStreamInKeysMutex.RLock()
+	defer fake.loadStreamInKeysMutex.RUnlock()
+	fake.storeStreamInKeysMutex.RLock()
+	defer fake.storeStreamInKeysMutex.RUnlock()
+	fake.loadCreateKeyMutex.RLock()
+	defer fake.loadCreateKeyMutex.RUnlock()
+	fake.storeCreateKeyMutex.RLock()
+	defer fake.storeCreateKeyMutex.RUnlock()
+	fake.loadReleasedMutex.RLock()
+	defer fake.loadReleasedMutex.RUnlock()
+	fake.storeReleasedMutex.RLock()
+	defer fake.storeReleasedMutex.RUnlock()
+	fake.storeRenewTTLOnAccessMutex.RLock()
+	defer fake.storeRenewTTLOnAccessMutex.RUnlock()
 	fake.parentMutex.RLock()
 	defer fake.parentMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
-	fake.sizeInBytesMutex.RLock()
-	defer fake.sizeInBytesMutex.RUnlock()
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	fake.loadDeletedMutex.RLock()
+	defer fake.loadDeletedMutex.RUnlock()
+	fake.storeDeletedMutex.RLock()
+	defer fake.storeDeletedMutex.RUnlock()
+	fake.clearDeletedMutex.RLock()
+	defer fake.clearDeletedMutex.RUnlock()
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
 	fake.newSubvolumeMutex.RLock()
 	defer fake.newSubvolumeMutex.RUnlock()
+	fake.newViewMutex.RLock()
+	defer fake.newViewMutex.RUnlock()
+	fake.isViewMutex.RLock()
+	defer fake.isViewMutex.RUnlock()
+	fake.newCloneMutex.RLock()
+	defer fake.newCloneMutex.RUnlock()
+	fake.copyDataMutex.RLock()
+	defer fake.copyDataMutex.RUnlock()
+	fake.snapshotMutex.RLock()
+	defer fake.snapshotMutex.RUnlock()
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	fake.renameMutex.RLock()
+	defer fake.renameMutex.RUnlock()
+	fake.roomMutex.RLock()
+	defer fake.roomMutex.RUnlock()
+	fake.promoteMutex.RLock()
+	defer fake.promoteMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
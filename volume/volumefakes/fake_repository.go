@@ -2,9 +2,12 @@
 package volumefakes
 
 import (
+	"context"
 	"io"
 	"sync"
+	"time"
 
+	"code.cloudfoundry.org/lager"
 	"github.com/concourse/baggageclaim/volume"
 )
 
@@ -39,6 +42,19 @@ type FakeRepository struct {
 		result2 bool
 		result3 error
 	}
+	VolumeExistsStub        func(handle string) (bool, error)
+	volumeExistsMutex       sync.RWMutex
+	volumeExistsArgsForCall []struct {
+		handle string
+	}
+	volumeExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+	volumeExistsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	GetVolumeStatsStub        func(handle string) (volume.VolumeStats, bool, error)
 	getVolumeStatsMutex       sync.RWMutex
 	getVolumeStatsArgsForCall []struct {
@@ -54,7 +70,20 @@ type FakeRepository struct {
 		result2 bool
 		result3 error
 	}
-	CreateVolumeStub        func(handle string, strategy volume.Strategy, properties volume.Properties, ttlInSeconds uint, isPrivileged bool) (volume.Volume, error)
+	TotalUsageStub        func(groupBy string) (volume.Usage, error)
+	totalUsageMutex       sync.RWMutex
+	totalUsageArgsForCall []struct {
+		groupBy string
+	}
+	totalUsageReturns struct {
+		result1 volume.Usage
+		result2 error
+	}
+	totalUsageReturnsOnCall map[int]struct {
+		result1 volume.Usage
+		result2 error
+	}
+	CreateVolumeStub        func(handle string, strategy volume.Strategy, properties volume.Properties, ttlInSeconds uint, isPrivileged bool, opts volume.CreateOptions) (volume.Volume, error)
 	createVolumeMutex       sync.RWMutex
 	createVolumeArgsForCall []struct {
 		handle       string
@@ -62,6 +91,7 @@ type FakeRepository struct {
 		properties   volume.Properties
 		ttlInSeconds uint
 		isPrivileged bool
+		opts         volume.CreateOptions
 	}
 	createVolumeReturns struct {
 		result1 volume.Volume
@@ -71,10 +101,52 @@ type FakeRepository struct {
 		result1 volume.Volume
 		result2 error
 	}
-	DestroyVolumeStub        func(handle string) error
+	CloneVolumeStub        func(srcHandle string, handle string) (volume.Volume, error)
+	cloneVolumeMutex       sync.RWMutex
+	cloneVolumeArgsForCall []struct {
+		srcHandle string
+		handle    string
+	}
+	cloneVolumeReturns struct {
+		result1 volume.Volume
+		result2 error
+	}
+	cloneVolumeReturnsOnCall map[int]struct {
+		result1 volume.Volume
+		result2 error
+	}
+	RenameVolumeStub        func(handle string, newHandle string) (volume.Volume, error)
+	renameVolumeMutex       sync.RWMutex
+	renameVolumeArgsForCall []struct {
+		handle    string
+		newHandle string
+	}
+	renameVolumeReturns struct {
+		result1 volume.Volume
+		result2 error
+	}
+	renameVolumeReturnsOnCall map[int]struct {
+		result1 volume.Volume
+		result2 error
+	}
+	PromoteStub        func(handle string) (volume.Volume, error)
+	promoteMutex       sync.RWMutex
+	promoteArgsForCall []struct {
+		handle string
+	}
+	promoteReturns struct {
+		result1 volume.Volume
+		result2 error
+	}
+	promoteReturnsOnCall map[int]struct {
+		result1 volume.Volume
+		result2 error
+	}
+	DestroyVolumeStub        func(handle string, opts volume.DestroyOptions) error
 	destroyVolumeMutex       sync.RWMutex
 	destroyVolumeArgsForCall []struct {
 		handle string
+		opts   volume.DestroyOptions
 	}
 	destroyVolumeReturns struct {
 		result1 error
@@ -82,23 +154,122 @@ type FakeRepository struct {
 	destroyVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DestroyVolumeAndDescendantsStub        func(handle string) error
+	DestroyVolumeAndDescendantsStub        func(handle string, opts volume.DestroyOptions) ([]string, error)
 	destroyVolumeAndDescendantsMutex       sync.RWMutex
 	destroyVolumeAndDescendantsArgsForCall []struct {
 		handle string
+		opts   volume.DestroyOptions
 	}
 	destroyVolumeAndDescendantsReturns struct {
-		result1 error
+		result1 []string
+		result2 error
 	}
 	destroyVolumeAndDescendantsReturnsOnCall map[int]struct {
-		result1 error
+		result1 []string
+		result2 error
+	}
+	PlanDestroyStub        func(handle string, opts volume.DestroyOptions, descendants bool) ([]string, error)
+	planDestroyMutex       sync.RWMutex
+	planDestroyArgsForCall []struct {
+		handle      string
+		opts        volume.DestroyOptions
+		descendants bool
+	}
+	planDestroyReturns struct {
+		result1 []string
+		result2 error
+	}
+	planDestroyReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	DestroyVolumesStub        func(handles []string, opts volume.DestroyOptions) map[string]error
+	destroyVolumesMutex       sync.RWMutex
+	destroyVolumesArgsForCall []struct {
+		handles []string
+		opts    volume.DestroyOptions
+	}
+	destroyVolumesReturns struct {
+		result1 map[string]error
+	}
+	destroyVolumesReturnsOnCall map[int]struct {
+		result1 map[string]error
+	}
+	DestroyVolumesWithPropertiesStub        func(properties volume.Properties, opts volume.DestroyOptions) (map[string]error, error)
+	destroyVolumesWithPropertiesMutex       sync.RWMutex
+	destroyVolumesWithPropertiesArgsForCall []struct {
+		properties volume.Properties
+		opts       volume.DestroyOptions
+	}
+	destroyVolumesWithPropertiesReturns struct {
+		result1 map[string]error
+		result2 error
+	}
+	destroyVolumesWithPropertiesReturnsOnCall map[int]struct {
+		result1 map[string]error
+		result2 error
+	}
+	RestoreVolumeStub        func(handle string) (volume.Volume, error)
+	restoreVolumeMutex       sync.RWMutex
+	restoreVolumeArgsForCall []struct {
+		handle string
+	}
+	restoreVolumeReturns struct {
+		result1 volume.Volume
+		result2 error
+	}
+	restoreVolumeReturnsOnCall map[int]struct {
+		result1 volume.Volume
+		result2 error
+	}
+	ListDeletedVolumesStub        func(queryProperties volume.Properties) (volume.Volumes, []string, error)
+	listDeletedVolumesMutex       sync.RWMutex
+	listDeletedVolumesArgsForCall []struct {
+		queryProperties volume.Properties
+	}
+	listDeletedVolumesReturns struct {
+		result1 volume.Volumes
+		result2 []string
+		result3 error
+	}
+	listDeletedVolumesReturnsOnCall map[int]struct {
+		result1 volume.Volumes
+		result2 []string
+		result3 error
+	}
+	PurgeDeletedVolumesStub        func() (map[string]error, error)
+	purgeDeletedVolumesMutex       sync.RWMutex
+	purgeDeletedVolumesArgsForCall []struct{}
+	purgeDeletedVolumesReturns     struct {
+		result1 map[string]error
+		result2 error
+	}
+	purgeDeletedVolumesReturnsOnCall map[int]struct {
+		result1 map[string]error
+		result2 error
+	}
+	GetPropertyStub        func(handle string, propertyName string) (string, error)
+	getPropertyMutex       sync.RWMutex
+	getPropertyArgsForCall []struct {
+		handle       string
+		propertyName string
+	}
+	getPropertyReturns struct {
+		result1 string
+		result2 error
+	}
+	getPropertyReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
 	}
-	SetPropertyStub        func(handle string, propertyName string, propertyValue string) error
+	SetPropertyStub        func(handle string, propertyName string, propertyValue string, expected *volume.PropertyExpectation, leaseToken string) error
 	setPropertyMutex       sync.RWMutex
 	setPropertyArgsForCall []struct {
 		handle        string
 		propertyName  string
 		propertyValue string
+		expected      *volume.PropertyExpectation
+		leaseToken    string
 	}
 	setPropertyReturns struct {
 		result1 error
@@ -106,6 +277,59 @@ type FakeRepository struct {
 	setPropertyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetPropertiesStub        func(handle string, properties volume.Properties, leaseToken string) error
+	setPropertiesMutex       sync.RWMutex
+	setPropertiesArgsForCall []struct {
+		handle     string
+		properties volume.Properties
+		leaseToken string
+	}
+	setPropertiesReturns struct {
+		result1 error
+	}
+	setPropertiesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeletePropertyStub        func(handle string, propertyName string, leaseToken string) error
+	deletePropertyMutex       sync.RWMutex
+	deletePropertyArgsForCall []struct {
+		handle       string
+		propertyName string
+		leaseToken   string
+	}
+	deletePropertyReturns struct {
+		result1 error
+	}
+	deletePropertyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AcquireLeaseStub        func(handle string, token string, duration time.Duration) (volume.Lease, error)
+	acquireLeaseMutex       sync.RWMutex
+	acquireLeaseArgsForCall []struct {
+		handle   string
+		token    string
+		duration time.Duration
+	}
+	acquireLeaseReturns struct {
+		result1 volume.Lease
+		result2 error
+	}
+	acquireLeaseReturnsOnCall map[int]struct {
+		result1 volume.Lease
+		result2 error
+	}
+	ReleaseLeaseStub        func(handle string, token string) error
+	releaseLeaseMutex       sync.RWMutex
+	releaseLeaseArgsForCall []struct {
+		handle string
+		token  string
+	}
+	releaseLeaseReturns struct {
+		result1 error
+	}
+	releaseLeaseReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetTTLStub        func(handle string, ttl uint) error
 	setTTLMutex       sync.RWMutex
 	setTTLArgsForCall []struct {
@@ -118,6 +342,18 @@ type FakeRepository struct {
 	setTTLReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetExpiresAtStub        func(handle string, expiresAt time.Time) error
+	setExpiresAtMutex       sync.RWMutex
+	setExpiresAtArgsForCall []struct {
+		handle    string
+		expiresAt time.Time
+	}
+	setExpiresAtReturns struct {
+		result1 error
+	}
+	setExpiresAtReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetPrivilegedStub        func(handle string, privileged bool) error
 	setPrivilegedMutex       sync.RWMutex
 	setPrivilegedArgsForCall []struct {
@@ -130,12 +366,62 @@ type FakeRepository struct {
 	setPrivilegedReturnsOnCall map[int]struct {
 		result1 error
 	}
-	StreamInStub        func(handle string, path string, stream io.Reader) (bool, error)
+	SetSELinuxLabelStub        func(handle string, label string) error
+	setSELinuxLabelMutex       sync.RWMutex
+	setSELinuxLabelArgsForCall []struct {
+		handle string
+		label  string
+	}
+	setSELinuxLabelReturns struct {
+		result1 error
+	}
+	setSELinuxLabelReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CommitVolumeStub        func(handle string, freeze bool) error
+	commitVolumeMutex       sync.RWMutex
+	commitVolumeArgsForCall []struct {
+		handle string
+		freeze bool
+	}
+	commitVolumeReturns struct {
+		result1 error
+	}
+	commitVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	TouchAccessStub        func(handle string) error
+	touchAccessMutex       sync.RWMutex
+	touchAccessArgsForCall []struct {
+		handle string
+	}
+	touchAccessReturns struct {
+		result1 error
+	}
+	touchAccessReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MaterializeStub        func(handle string) (bool, error)
+	materializeMutex       sync.RWMutex
+	materializeArgsForCall []struct {
+		handle string
+	}
+	materializeReturns struct {
+		result1 bool
+		result2 error
+	}
+	materializeReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	StreamInStub        func(ctx context.Context, handle string, path string, stream io.Reader, opts volume.StreamInOptions) (bool, error)
 	streamInMutex       sync.RWMutex
 	streamInArgsForCall []struct {
+		ctx    context.Context
 		handle string
 		path   string
 		stream io.Reader
+		opts   volume.StreamInOptions
 	}
 	streamInReturns struct {
 		result1 bool
@@ -145,12 +431,14 @@ type FakeRepository struct {
 		result1 bool
 		result2 error
 	}
-	StreamOutStub        func(handle string, path string, dest io.Writer) error
+	StreamOutStub        func(ctx context.Context, handle string, path string, dest io.Writer, opts volume.StreamOutOptions) error
 	streamOutMutex       sync.RWMutex
 	streamOutArgsForCall []struct {
+		ctx    context.Context
 		handle string
 		path   string
 		dest   io.Writer
+		opts   volume.StreamOutOptions
 	}
 	streamOutReturns struct {
 		result1 error
@@ -158,6 +446,74 @@ type FakeRepository struct {
 	streamOutReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CopyInStub        func(ctx context.Context, handle string, path string, sourceHandle string, opts volume.CopyInOptions) error
+	copyInMutex       sync.RWMutex
+	copyInArgsForCall []struct {
+		ctx          context.Context
+		handle       string
+		path         string
+		sourceHandle string
+		opts         volume.CopyInOptions
+	}
+	copyInReturns struct {
+		result1 error
+	}
+	copyInReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DiffVolumesStub        func(handle string, baseHandle string, emit func(volume.DiffEntry) error) error
+	diffVolumesMutex       sync.RWMutex
+	diffVolumesArgsForCall []struct {
+		handle     string
+		baseHandle string
+		emit       func(volume.DiffEntry) error
+	}
+	diffVolumesReturns struct {
+		result1 error
+	}
+	diffVolumesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ManifestStub        func(handle string, path string, emit func(volume.ManifestEntry) error) error
+	manifestMutex       sync.RWMutex
+	manifestArgsForCall []struct {
+		handle string
+		path   string
+		emit   func(volume.ManifestEntry) error
+	}
+	manifestReturns struct {
+		result1 error
+	}
+	manifestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListFilesStub        func(handle string, path string) ([]volume.FileEntry, error)
+	listFilesMutex       sync.RWMutex
+	listFilesArgsForCall []struct {
+		handle string
+		path   string
+	}
+	listFilesReturns struct {
+		result1 []volume.FileEntry
+		result2 error
+	}
+	listFilesReturnsOnCall map[int]struct {
+		result1 []volume.FileEntry
+		result2 error
+	}
+	StreamOutDiffStub        func(handle string, baseHandle string, dest io.Writer) error
+	streamOutDiffMutex       sync.RWMutex
+	streamOutDiffArgsForCall []struct {
+		handle     string
+		baseHandle string
+		dest       io.Writer
+	}
+	streamOutDiffReturns struct {
+		result1 error
+	}
+	streamOutDiffReturnsOnCall map[int]struct {
+		result1 error
+	}
 	VolumeParentStub        func(handle string) (volume.Volume, bool, error)
 	volumeParentMutex       sync.RWMutex
 	volumeParentArgsForCall []struct {
@@ -173,6 +529,65 @@ type FakeRepository struct {
 		result2 bool
 		result3 error
 	}
+	WithLogDataStub        func(lager.Data) volume.Repository
+	withLogDataMutex       sync.RWMutex
+	withLogDataArgsForCall []struct {
+		arg1 lager.Data
+	}
+	withLogDataReturns struct {
+		result1 volume.Repository
+	}
+	withLogDataReturnsOnCall map[int]struct {
+		result1 volume.Repository
+	}
+	VolumeChildrenStub        func(handle string) ([]string, error)
+	volumeChildrenMutex       sync.RWMutex
+	volumeChildrenArgsForCall []struct {
+		handle string
+	}
+	volumeChildrenReturns struct {
+		result1 []string
+		result2 error
+	}
+	volumeChildrenReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	VolumeDigestStub        func(handle string) (string, error)
+	volumeDigestMutex       sync.RWMutex
+	volumeDigestArgsForCall []struct {
+		handle string
+	}
+	volumeDigestReturns struct {
+		result1 string
+		result2 error
+	}
+	volumeDigestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	InodesExhaustedStub        func() (bool, error)
+	inodesExhaustedMutex       sync.RWMutex
+	inodesExhaustedArgsForCall []struct{}
+	inodesExhaustedReturns     struct {
+		result1 bool
+		result2 error
+	}
+	inodesExhaustedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	FreeBytesStub        func() (uint64, error)
+	freeBytesMutex       sync.RWMutex
+	freeBytesArgsForCall []struct{}
+	freeBytesReturns     struct {
+		result1 uint64
+		result2 error
+	}
+	freeBytesReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -285,6 +700,57 @@ func (fake *FakeRepository) GetVolumeReturnsOnCall(i int, result1 volume.Volume,
 	}{result1, result2, result3}
 }
 
+func (fake *FakeRepository) VolumeExists(handle string) (bool, error) {
+	fake.volumeExistsMutex.Lock()
+	ret, specificReturn := fake.volumeExistsReturnsOnCall[len(fake.volumeExistsArgsForCall)]
+	fake.volumeExistsArgsForCall = append(fake.volumeExistsArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("VolumeExists", []interface{}{handle})
+	fake.volumeExistsMutex.Unlock()
+	if fake.VolumeExistsStub != nil {
+		return fake.VolumeExistsStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.volumeExistsReturns.result1, fake.volumeExistsReturns.result2
+}
+
+func (fake *FakeRepository) VolumeExistsCallCount() int {
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
+	return len(fake.volumeExistsArgsForCall)
+}
+
+func (fake *FakeRepository) VolumeExistsArgsForCall(i int) string {
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
+	return fake.volumeExistsArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) VolumeExistsReturns(result1 bool, result2 error) {
+	fake.VolumeExistsStub = nil
+	fake.volumeExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) VolumeExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.VolumeExistsStub = nil
+	if fake.volumeExistsReturnsOnCall == nil {
+		fake.volumeExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.volumeExistsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeRepository) GetVolumeStats(handle string) (volume.VolumeStats, bool, error) {
 	fake.getVolumeStatsMutex.Lock()
 	ret, specificReturn := fake.getVolumeStatsReturnsOnCall[len(fake.getVolumeStatsArgsForCall)]
@@ -339,7 +805,58 @@ func (fake *FakeRepository) GetVolumeStatsReturnsOnCall(i int, result1 volume.Vo
 	}{result1, result2, result3}
 }
 
-func (fake *FakeRepository) CreateVolume(handle string, strategy volume.Strategy, properties volume.Properties, ttlInSeconds uint, isPrivileged bool) (volume.Volume, error) {
+func (fake *FakeRepository) TotalUsage(groupBy string) (volume.Usage, error) {
+	fake.totalUsageMutex.Lock()
+	ret, specificReturn := fake.totalUsageReturnsOnCall[len(fake.totalUsageArgsForCall)]
+	fake.totalUsageArgsForCall = append(fake.totalUsageArgsForCall, struct {
+		groupBy string
+	}{groupBy})
+	fake.recordInvocation("TotalUsage", []interface{}{groupBy})
+	fake.totalUsageMutex.Unlock()
+	if fake.TotalUsageStub != nil {
+		return fake.TotalUsageStub(groupBy)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.totalUsageReturns.result1, fake.totalUsageReturns.result2
+}
+
+func (fake *FakeRepository) TotalUsageCallCount() int {
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
+	return len(fake.totalUsageArgsForCall)
+}
+
+func (fake *FakeRepository) TotalUsageArgsForCall(i int) string {
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
+	return fake.totalUsageArgsForCall[i].groupBy
+}
+
+func (fake *FakeRepository) TotalUsageReturns(result1 volume.Usage, result2 error) {
+	fake.TotalUsageStub = nil
+	fake.totalUsageReturns = struct {
+		result1 volume.Usage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) TotalUsageReturnsOnCall(i int, result1 volume.Usage, result2 error) {
+	fake.TotalUsageStub = nil
+	if fake.totalUsageReturnsOnCall == nil {
+		fake.totalUsageReturnsOnCall = make(map[int]struct {
+			result1 volume.Usage
+			result2 error
+		})
+	}
+	fake.totalUsageReturnsOnCall[i] = struct {
+		result1 volume.Usage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) CreateVolume(handle string, strategy volume.Strategy, properties volume.Properties, ttlInSeconds uint, isPrivileged bool, opts volume.CreateOptions) (volume.Volume, error) {
 	fake.createVolumeMutex.Lock()
 	ret, specificReturn := fake.createVolumeReturnsOnCall[len(fake.createVolumeArgsForCall)]
 	fake.createVolumeArgsForCall = append(fake.createVolumeArgsForCall, struct {
@@ -348,11 +865,12 @@ func (fake *FakeRepository) CreateVolume(handle string, strategy volume.Strategy
 		properties   volume.Properties
 		ttlInSeconds uint
 		isPrivileged bool
-	}{handle, strategy, properties, ttlInSeconds, isPrivileged})
-	fake.recordInvocation("CreateVolume", []interface{}{handle, strategy, properties, ttlInSeconds, isPrivileged})
+		opts         volume.CreateOptions
+	}{handle, strategy, properties, ttlInSeconds, isPrivileged, opts})
+	fake.recordInvocation("CreateVolume", []interface{}{handle, strategy, properties, ttlInSeconds, isPrivileged, opts})
 	fake.createVolumeMutex.Unlock()
 	if fake.CreateVolumeStub != nil {
-		return fake.CreateVolumeStub(handle, strategy, properties, ttlInSeconds, isPrivileged)
+		return fake.CreateVolumeStub(handle, strategy, properties, ttlInSeconds, isPrivileged, opts)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -366,10 +884,10 @@ func (fake *FakeRepository) CreateVolumeCallCount() int {
 	return len(fake.createVolumeArgsForCall)
 }
 
-func (fake *FakeRepository) CreateVolumeArgsForCall(i int) (string, volume.Strategy, volume.Properties, uint, bool) {
+func (fake *FakeRepository) CreateVolumeArgsForCall(i int) (string, volume.Strategy, volume.Properties, uint, bool, volume.CreateOptions) {
 	fake.createVolumeMutex.RLock()
 	defer fake.createVolumeMutex.RUnlock()
-	return fake.createVolumeArgsForCall[i].handle, fake.createVolumeArgsForCall[i].strategy, fake.createVolumeArgsForCall[i].properties, fake.createVolumeArgsForCall[i].ttlInSeconds, fake.createVolumeArgsForCall[i].isPrivileged
+	return fake.createVolumeArgsForCall[i].handle, fake.createVolumeArgsForCall[i].strategy, fake.createVolumeArgsForCall[i].properties, fake.createVolumeArgsForCall[i].ttlInSeconds, fake.createVolumeArgsForCall[i].isPrivileged, fake.createVolumeArgsForCall[i].opts
 }
 
 func (fake *FakeRepository) CreateVolumeReturns(result1 volume.Volume, result2 error) {
@@ -394,37 +912,193 @@ func (fake *FakeRepository) CreateVolumeReturnsOnCall(i int, result1 volume.Volu
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) DestroyVolume(handle string) error {
-	fake.destroyVolumeMutex.Lock()
-	ret, specificReturn := fake.destroyVolumeReturnsOnCall[len(fake.destroyVolumeArgsForCall)]
-	fake.destroyVolumeArgsForCall = append(fake.destroyVolumeArgsForCall, struct {
-		handle string
-	}{handle})
-	fake.recordInvocation("DestroyVolume", []interface{}{handle})
-	fake.destroyVolumeMutex.Unlock()
-	if fake.DestroyVolumeStub != nil {
-		return fake.DestroyVolumeStub(handle)
+func (fake *FakeRepository) CloneVolume(srcHandle string, handle string) (volume.Volume, error) {
+	fake.cloneVolumeMutex.Lock()
+	ret, specificReturn := fake.cloneVolumeReturnsOnCall[len(fake.cloneVolumeArgsForCall)]
+	fake.cloneVolumeArgsForCall = append(fake.cloneVolumeArgsForCall, struct {
+		srcHandle string
+		handle    string
+	}{srcHandle, handle})
+	fake.recordInvocation("CloneVolume", []interface{}{srcHandle, handle})
+	fake.cloneVolumeMutex.Unlock()
+	if fake.CloneVolumeStub != nil {
+		return fake.CloneVolumeStub(srcHandle, handle)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.destroyVolumeReturns.result1
+	return fake.cloneVolumeReturns.result1, fake.cloneVolumeReturns.result2
 }
 
-func (fake *FakeRepository) DestroyVolumeCallCount() int {
-	fake.destroyVolumeMutex.RLock()
-	defer fake.destroyVolumeMutex.RUnlock()
-	return len(fake.destroyVolumeArgsForCall)
+func (fake *FakeRepository) CloneVolumeCallCount() int {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return len(fake.cloneVolumeArgsForCall)
 }
 
-func (fake *FakeRepository) DestroyVolumeArgsForCall(i int) string {
-	fake.destroyVolumeMutex.RLock()
-	defer fake.destroyVolumeMutex.RUnlock()
-	return fake.destroyVolumeArgsForCall[i].handle
+func (fake *FakeRepository) CloneVolumeArgsForCall(i int) (string, string) {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return fake.cloneVolumeArgsForCall[i].srcHandle, fake.cloneVolumeArgsForCall[i].handle
 }
 
-func (fake *FakeRepository) DestroyVolumeReturns(result1 error) {
-	fake.DestroyVolumeStub = nil
+func (fake *FakeRepository) CloneVolumeReturns(result1 volume.Volume, result2 error) {
+	fake.CloneVolumeStub = nil
+	fake.cloneVolumeReturns = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) CloneVolumeReturnsOnCall(i int, result1 volume.Volume, result2 error) {
+	fake.CloneVolumeStub = nil
+	if fake.cloneVolumeReturnsOnCall == nil {
+		fake.cloneVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.Volume
+			result2 error
+		})
+	}
+	fake.cloneVolumeReturnsOnCall[i] = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) RenameVolume(handle string, newHandle string) (volume.Volume, error) {
+	fake.renameVolumeMutex.Lock()
+	ret, specificReturn := fake.renameVolumeReturnsOnCall[len(fake.renameVolumeArgsForCall)]
+	fake.renameVolumeArgsForCall = append(fake.renameVolumeArgsForCall, struct {
+		handle    string
+		newHandle string
+	}{handle, newHandle})
+	fake.recordInvocation("RenameVolume", []interface{}{handle, newHandle})
+	fake.renameVolumeMutex.Unlock()
+	if fake.RenameVolumeStub != nil {
+		return fake.RenameVolumeStub(handle, newHandle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.renameVolumeReturns.result1, fake.renameVolumeReturns.result2
+}
+
+func (fake *FakeRepository) RenameVolumeCallCount() int {
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	return len(fake.renameVolumeArgsForCall)
+}
+
+func (fake *FakeRepository) RenameVolumeArgsForCall(i int) (string, string) {
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	return fake.renameVolumeArgsForCall[i].handle, fake.renameVolumeArgsForCall[i].newHandle
+}
+
+func (fake *FakeRepository) RenameVolumeReturns(result1 volume.Volume, result2 error) {
+	fake.RenameVolumeStub = nil
+	fake.renameVolumeReturns = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) RenameVolumeReturnsOnCall(i int, result1 volume.Volume, result2 error) {
+	fake.RenameVolumeStub = nil
+	if fake.renameVolumeReturnsOnCall == nil {
+		fake.renameVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.Volume
+			result2 error
+		})
+	}
+	fake.renameVolumeReturnsOnCall[i] = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) Promote(handle string) (volume.Volume, error) {
+	fake.promoteMutex.Lock()
+	ret, specificReturn := fake.promoteReturnsOnCall[len(fake.promoteArgsForCall)]
+	fake.promoteArgsForCall = append(fake.promoteArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Promote", []interface{}{handle})
+	fake.promoteMutex.Unlock()
+	if fake.PromoteStub != nil {
+		return fake.PromoteStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.promoteReturns.result1, fake.promoteReturns.result2
+}
+
+func (fake *FakeRepository) PromoteCallCount() int {
+	fake.promoteMutex.RLock()
+	defer fake.promoteMutex.RUnlock()
+	return len(fake.promoteArgsForCall)
+}
+
+func (fake *FakeRepository) PromoteArgsForCall(i int) string {
+	fake.promoteMutex.RLock()
+	defer fake.promoteMutex.RUnlock()
+	return fake.promoteArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) PromoteReturns(result1 volume.Volume, result2 error) {
+	fake.PromoteStub = nil
+	fake.promoteReturns = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) PromoteReturnsOnCall(i int, result1 volume.Volume, result2 error) {
+	fake.PromoteStub = nil
+	if fake.promoteReturnsOnCall == nil {
+		fake.promoteReturnsOnCall = make(map[int]struct {
+			result1 volume.Volume
+			result2 error
+		})
+	}
+	fake.promoteReturnsOnCall[i] = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) DestroyVolume(handle string, opts volume.DestroyOptions) error {
+	fake.destroyVolumeMutex.Lock()
+	ret, specificReturn := fake.destroyVolumeReturnsOnCall[len(fake.destroyVolumeArgsForCall)]
+	fake.destroyVolumeArgsForCall = append(fake.destroyVolumeArgsForCall, struct {
+		handle string
+		opts   volume.DestroyOptions
+	}{handle, opts})
+	fake.recordInvocation("DestroyVolume", []interface{}{handle, opts})
+	fake.destroyVolumeMutex.Unlock()
+	if fake.DestroyVolumeStub != nil {
+		return fake.DestroyVolumeStub(handle, opts)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.destroyVolumeReturns.result1
+}
+
+func (fake *FakeRepository) DestroyVolumeCallCount() int {
+	fake.destroyVolumeMutex.RLock()
+	defer fake.destroyVolumeMutex.RUnlock()
+	return len(fake.destroyVolumeArgsForCall)
+}
+
+func (fake *FakeRepository) DestroyVolumeArgsForCall(i int) (string, volume.DestroyOptions) {
+	fake.destroyVolumeMutex.RLock()
+	defer fake.destroyVolumeMutex.RUnlock()
+	return fake.destroyVolumeArgsForCall[i].handle, fake.destroyVolumeArgsForCall[i].opts
+}
+
+func (fake *FakeRepository) DestroyVolumeReturns(result1 error) {
+	fake.DestroyVolumeStub = nil
 	fake.destroyVolumeReturns = struct {
 		result1 error
 	}{result1}
@@ -442,21 +1116,22 @@ func (fake *FakeRepository) DestroyVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeRepository) DestroyVolumeAndDescendants(handle string) error {
+func (fake *FakeRepository) DestroyVolumeAndDescendants(handle string, opts volume.DestroyOptions) ([]string, error) {
 	fake.destroyVolumeAndDescendantsMutex.Lock()
 	ret, specificReturn := fake.destroyVolumeAndDescendantsReturnsOnCall[len(fake.destroyVolumeAndDescendantsArgsForCall)]
 	fake.destroyVolumeAndDescendantsArgsForCall = append(fake.destroyVolumeAndDescendantsArgsForCall, struct {
 		handle string
-	}{handle})
-	fake.recordInvocation("DestroyVolumeAndDescendants", []interface{}{handle})
+		opts   volume.DestroyOptions
+	}{handle, opts})
+	fake.recordInvocation("DestroyVolumeAndDescendants", []interface{}{handle, opts})
 	fake.destroyVolumeAndDescendantsMutex.Unlock()
 	if fake.DestroyVolumeAndDescendantsStub != nil {
-		return fake.DestroyVolumeAndDescendantsStub(handle)
+		return fake.DestroyVolumeAndDescendantsStub(handle, opts)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.destroyVolumeAndDescendantsReturns.result1
+	return fake.destroyVolumeAndDescendantsReturns.result1, fake.destroyVolumeAndDescendantsReturns.result2
 }
 
 func (fake *FakeRepository) DestroyVolumeAndDescendantsCallCount() int {
@@ -465,278 +1140,1348 @@ func (fake *FakeRepository) DestroyVolumeAndDescendantsCallCount() int {
 	return len(fake.destroyVolumeAndDescendantsArgsForCall)
 }
 
-func (fake *FakeRepository) DestroyVolumeAndDescendantsArgsForCall(i int) string {
-	fake.destroyVolumeAndDescendantsMutex.RLock()
-	defer fake.destroyVolumeAndDescendantsMutex.RUnlock()
-	return fake.destroyVolumeAndDescendantsArgsForCall[i].handle
+func (fake *FakeRepository) DestroyVolumeAndDescendantsArgsForCall(i int) (string, volume.DestroyOptions) {
+	fake.destroyVolumeAndDescendantsMutex.RLock()
+	defer fake.destroyVolumeAndDescendantsMutex.RUnlock()
+	return fake.destroyVolumeAndDescendantsArgsForCall[i].handle, fake.destroyVolumeAndDescendantsArgsForCall[i].opts
+}
+
+func (fake *FakeRepository) DestroyVolumeAndDescendantsReturns(result1 []string, result2 error) {
+	fake.DestroyVolumeAndDescendantsStub = nil
+	fake.destroyVolumeAndDescendantsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) DestroyVolumeAndDescendantsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.DestroyVolumeAndDescendantsStub = nil
+	if fake.destroyVolumeAndDescendantsReturnsOnCall == nil {
+		fake.destroyVolumeAndDescendantsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.destroyVolumeAndDescendantsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) PlanDestroy(handle string, opts volume.DestroyOptions, descendants bool) ([]string, error) {
+	fake.planDestroyMutex.Lock()
+	ret, specificReturn := fake.planDestroyReturnsOnCall[len(fake.planDestroyArgsForCall)]
+	fake.planDestroyArgsForCall = append(fake.planDestroyArgsForCall, struct {
+		handle      string
+		opts        volume.DestroyOptions
+		descendants bool
+	}{handle, opts, descendants})
+	fake.recordInvocation("PlanDestroy", []interface{}{handle, opts, descendants})
+	fake.planDestroyMutex.Unlock()
+	if fake.PlanDestroyStub != nil {
+		return fake.PlanDestroyStub(handle, opts, descendants)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.planDestroyReturns.result1, fake.planDestroyReturns.result2
+}
+
+func (fake *FakeRepository) PlanDestroyCallCount() int {
+	fake.planDestroyMutex.RLock()
+	defer fake.planDestroyMutex.RUnlock()
+	return len(fake.planDestroyArgsForCall)
+}
+
+func (fake *FakeRepository) PlanDestroyArgsForCall(i int) (string, volume.DestroyOptions, bool) {
+	fake.planDestroyMutex.RLock()
+	defer fake.planDestroyMutex.RUnlock()
+	return fake.planDestroyArgsForCall[i].handle, fake.planDestroyArgsForCall[i].opts, fake.planDestroyArgsForCall[i].descendants
+}
+
+func (fake *FakeRepository) PlanDestroyReturns(result1 []string, result2 error) {
+	fake.PlanDestroyStub = nil
+	fake.planDestroyReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) PlanDestroyReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.PlanDestroyStub = nil
+	if fake.planDestroyReturnsOnCall == nil {
+		fake.planDestroyReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.planDestroyReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) DestroyVolumes(handles []string, opts volume.DestroyOptions) map[string]error {
+	var handlesCopy []string
+	if handles != nil {
+		handlesCopy = make([]string, len(handles))
+		copy(handlesCopy, handles)
+	}
+	fake.destroyVolumesMutex.Lock()
+	ret, specificReturn := fake.destroyVolumesReturnsOnCall[len(fake.destroyVolumesArgsForCall)]
+	fake.destroyVolumesArgsForCall = append(fake.destroyVolumesArgsForCall, struct {
+		handles []string
+		opts    volume.DestroyOptions
+	}{handlesCopy, opts})
+	fake.recordInvocation("DestroyVolumes", []interface{}{handlesCopy, opts})
+	fake.destroyVolumesMutex.Unlock()
+	if fake.DestroyVolumesStub != nil {
+		return fake.DestroyVolumesStub(handles, opts)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.destroyVolumesReturns.result1
+}
+
+func (fake *FakeRepository) DestroyVolumesCallCount() int {
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	return len(fake.destroyVolumesArgsForCall)
+}
+
+func (fake *FakeRepository) DestroyVolumesArgsForCall(i int) ([]string, volume.DestroyOptions) {
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	return fake.destroyVolumesArgsForCall[i].handles, fake.destroyVolumesArgsForCall[i].opts
+}
+
+func (fake *FakeRepository) DestroyVolumesReturns(result1 map[string]error) {
+	fake.DestroyVolumesStub = nil
+	fake.destroyVolumesReturns = struct {
+		result1 map[string]error
+	}{result1}
+}
+
+func (fake *FakeRepository) DestroyVolumesReturnsOnCall(i int, result1 map[string]error) {
+	fake.DestroyVolumesStub = nil
+	if fake.destroyVolumesReturnsOnCall == nil {
+		fake.destroyVolumesReturnsOnCall = make(map[int]struct {
+			result1 map[string]error
+		})
+	}
+	fake.destroyVolumesReturnsOnCall[i] = struct {
+		result1 map[string]error
+	}{result1}
+}
+
+func (fake *FakeRepository) DestroyVolumesWithProperties(properties volume.Properties, opts volume.DestroyOptions) (map[string]error, error) {
+	fake.destroyVolumesWithPropertiesMutex.Lock()
+	ret, specificReturn := fake.destroyVolumesWithPropertiesReturnsOnCall[len(fake.destroyVolumesWithPropertiesArgsForCall)]
+	fake.destroyVolumesWithPropertiesArgsForCall = append(fake.destroyVolumesWithPropertiesArgsForCall, struct {
+		properties volume.Properties
+		opts       volume.DestroyOptions
+	}{properties, opts})
+	fake.recordInvocation("DestroyVolumesWithProperties", []interface{}{properties, opts})
+	fake.destroyVolumesWithPropertiesMutex.Unlock()
+	if fake.DestroyVolumesWithPropertiesStub != nil {
+		return fake.DestroyVolumesWithPropertiesStub(properties, opts)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.destroyVolumesWithPropertiesReturns.result1, fake.destroyVolumesWithPropertiesReturns.result2
+}
+
+func (fake *FakeRepository) DestroyVolumesWithPropertiesCallCount() int {
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
+	return len(fake.destroyVolumesWithPropertiesArgsForCall)
+}
+
+func (fake *FakeRepository) DestroyVolumesWithPropertiesArgsForCall(i int) (volume.Properties, volume.DestroyOptions) {
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
+	return fake.destroyVolumesWithPropertiesArgsForCall[i].properties, fake.destroyVolumesWithPropertiesArgsForCall[i].opts
+}
+
+func (fake *FakeRepository) DestroyVolumesWithPropertiesReturns(result1 map[string]error, result2 error) {
+	fake.DestroyVolumesWithPropertiesStub = nil
+	fake.destroyVolumesWithPropertiesReturns = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) DestroyVolumesWithPropertiesReturnsOnCall(i int, result1 map[string]error, result2 error) {
+	fake.DestroyVolumesWithPropertiesStub = nil
+	if fake.destroyVolumesWithPropertiesReturnsOnCall == nil {
+		fake.destroyVolumesWithPropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]error
+			result2 error
+		})
+	}
+	fake.destroyVolumesWithPropertiesReturnsOnCall[i] = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) RestoreVolume(handle string) (volume.Volume, error) {
+	fake.restoreVolumeMutex.Lock()
+	ret, specificReturn := fake.restoreVolumeReturnsOnCall[len(fake.restoreVolumeArgsForCall)]
+	fake.restoreVolumeArgsForCall = append(fake.restoreVolumeArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("RestoreVolume", []interface{}{handle})
+	fake.restoreVolumeMutex.Unlock()
+	if fake.RestoreVolumeStub != nil {
+		return fake.RestoreVolumeStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.restoreVolumeReturns.result1, fake.restoreVolumeReturns.result2
+}
+
+func (fake *FakeRepository) RestoreVolumeCallCount() int {
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
+	return len(fake.restoreVolumeArgsForCall)
+}
+
+func (fake *FakeRepository) RestoreVolumeArgsForCall(i int) string {
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
+	return fake.restoreVolumeArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) RestoreVolumeReturns(result1 volume.Volume, result2 error) {
+	fake.RestoreVolumeStub = nil
+	fake.restoreVolumeReturns = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) RestoreVolumeReturnsOnCall(i int, result1 volume.Volume, result2 error) {
+	fake.RestoreVolumeStub = nil
+	if fake.restoreVolumeReturnsOnCall == nil {
+		fake.restoreVolumeReturnsOnCall = make(map[int]struct {
+			result1 volume.Volume
+			result2 error
+		})
+	}
+	fake.restoreVolumeReturnsOnCall[i] = struct {
+		result1 volume.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) ListDeletedVolumes(queryProperties volume.Properties) (volume.Volumes, []string, error) {
+	fake.listDeletedVolumesMutex.Lock()
+	ret, specificReturn := fake.listDeletedVolumesReturnsOnCall[len(fake.listDeletedVolumesArgsForCall)]
+	fake.listDeletedVolumesArgsForCall = append(fake.listDeletedVolumesArgsForCall, struct {
+		queryProperties volume.Properties
+	}{queryProperties})
+	fake.recordInvocation("ListDeletedVolumes", []interface{}{queryProperties})
+	fake.listDeletedVolumesMutex.Unlock()
+	if fake.ListDeletedVolumesStub != nil {
+		return fake.ListDeletedVolumesStub(queryProperties)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.listDeletedVolumesReturns.result1, fake.listDeletedVolumesReturns.result2, fake.listDeletedVolumesReturns.result3
+}
+
+func (fake *FakeRepository) ListDeletedVolumesCallCount() int {
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	return len(fake.listDeletedVolumesArgsForCall)
+}
+
+func (fake *FakeRepository) ListDeletedVolumesArgsForCall(i int) volume.Properties {
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	return fake.listDeletedVolumesArgsForCall[i].queryProperties
+}
+
+func (fake *FakeRepository) ListDeletedVolumesReturns(result1 volume.Volumes, result2 []string, result3 error) {
+	fake.ListDeletedVolumesStub = nil
+	fake.listDeletedVolumesReturns = struct {
+		result1 volume.Volumes
+		result2 []string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRepository) ListDeletedVolumesReturnsOnCall(i int, result1 volume.Volumes, result2 []string, result3 error) {
+	fake.ListDeletedVolumesStub = nil
+	if fake.listDeletedVolumesReturnsOnCall == nil {
+		fake.listDeletedVolumesReturnsOnCall = make(map[int]struct {
+			result1 volume.Volumes
+			result2 []string
+			result3 error
+		})
+	}
+	fake.listDeletedVolumesReturnsOnCall[i] = struct {
+		result1 volume.Volumes
+		result2 []string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRepository) PurgeDeletedVolumes() (map[string]error, error) {
+	fake.purgeDeletedVolumesMutex.Lock()
+	ret, specificReturn := fake.purgeDeletedVolumesReturnsOnCall[len(fake.purgeDeletedVolumesArgsForCall)]
+	fake.purgeDeletedVolumesArgsForCall = append(fake.purgeDeletedVolumesArgsForCall, struct{}{})
+	fake.recordInvocation("PurgeDeletedVolumes", []interface{}{})
+	fake.purgeDeletedVolumesMutex.Unlock()
+	if fake.PurgeDeletedVolumesStub != nil {
+		return fake.PurgeDeletedVolumesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.purgeDeletedVolumesReturns.result1, fake.purgeDeletedVolumesReturns.result2
+}
+
+func (fake *FakeRepository) PurgeDeletedVolumesCallCount() int {
+	fake.purgeDeletedVolumesMutex.RLock()
+	defer fake.purgeDeletedVolumesMutex.RUnlock()
+	return len(fake.purgeDeletedVolumesArgsForCall)
+}
+
+func (fake *FakeRepository) PurgeDeletedVolumesReturns(result1 map[string]error, result2 error) {
+	fake.PurgeDeletedVolumesStub = nil
+	fake.purgeDeletedVolumesReturns = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) PurgeDeletedVolumesReturnsOnCall(i int, result1 map[string]error, result2 error) {
+	fake.PurgeDeletedVolumesStub = nil
+	if fake.purgeDeletedVolumesReturnsOnCall == nil {
+		fake.purgeDeletedVolumesReturnsOnCall = make(map[int]struct {
+			result1 map[string]error
+			result2 error
+		})
+	}
+	fake.purgeDeletedVolumesReturnsOnCall[i] = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetProperty(handle string, propertyName string) (string, error) {
+	fake.getPropertyMutex.Lock()
+	ret, specificReturn := fake.getPropertyReturnsOnCall[len(fake.getPropertyArgsForCall)]
+	fake.getPropertyArgsForCall = append(fake.getPropertyArgsForCall, struct {
+		handle       string
+		propertyName string
+	}{handle, propertyName})
+	fake.recordInvocation("GetProperty", []interface{}{handle, propertyName})
+	fake.getPropertyMutex.Unlock()
+	if fake.GetPropertyStub != nil {
+		return fake.GetPropertyStub(handle, propertyName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getPropertyReturns.result1, fake.getPropertyReturns.result2
+}
+
+func (fake *FakeRepository) GetPropertyCallCount() int {
+	fake.getPropertyMutex.RLock()
+	defer fake.getPropertyMutex.RUnlock()
+	return len(fake.getPropertyArgsForCall)
+}
+
+func (fake *FakeRepository) GetPropertyArgsForCall(i int) (string, string) {
+	fake.getPropertyMutex.RLock()
+	defer fake.getPropertyMutex.RUnlock()
+	return fake.getPropertyArgsForCall[i].handle, fake.getPropertyArgsForCall[i].propertyName
+}
+
+func (fake *FakeRepository) GetPropertyReturns(result1 string, result2 error) {
+	fake.GetPropertyStub = nil
+	fake.getPropertyReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetPropertyReturnsOnCall(i int, result1 string, result2 error) {
+	fake.GetPropertyStub = nil
+	if fake.getPropertyReturnsOnCall == nil {
+		fake.getPropertyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getPropertyReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) SetProperty(handle string, propertyName string, propertyValue string, expected *volume.PropertyExpectation, leaseToken string) error {
+	fake.setPropertyMutex.Lock()
+	ret, specificReturn := fake.setPropertyReturnsOnCall[len(fake.setPropertyArgsForCall)]
+	fake.setPropertyArgsForCall = append(fake.setPropertyArgsForCall, struct {
+		handle        string
+		propertyName  string
+		propertyValue string
+		expected      *volume.PropertyExpectation
+		leaseToken    string
+	}{handle, propertyName, propertyValue, expected, leaseToken})
+	fake.recordInvocation("SetProperty", []interface{}{handle, propertyName, propertyValue, expected, leaseToken})
+	fake.setPropertyMutex.Unlock()
+	if fake.SetPropertyStub != nil {
+		return fake.SetPropertyStub(handle, propertyName, propertyValue, expected, leaseToken)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setPropertyReturns.result1
+}
+
+func (fake *FakeRepository) SetPropertyCallCount() int {
+	fake.setPropertyMutex.RLock()
+	defer fake.setPropertyMutex.RUnlock()
+	return len(fake.setPropertyArgsForCall)
+}
+
+func (fake *FakeRepository) SetPropertyArgsForCall(i int) (string, string, string, *volume.PropertyExpectation, string) {
+	fake.setPropertyMutex.RLock()
+	defer fake.setPropertyMutex.RUnlock()
+	return fake.setPropertyArgsForCall[i].handle, fake.setPropertyArgsForCall[i].propertyName, fake.setPropertyArgsForCall[i].propertyValue, fake.setPropertyArgsForCall[i].expected, fake.setPropertyArgsForCall[i].leaseToken
+}
+
+func (fake *FakeRepository) SetPropertyReturns(result1 error) {
+	fake.SetPropertyStub = nil
+	fake.setPropertyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetPropertyReturnsOnCall(i int, result1 error) {
+	fake.SetPropertyStub = nil
+	if fake.setPropertyReturnsOnCall == nil {
+		fake.setPropertyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPropertyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetProperties(handle string, properties volume.Properties, leaseToken string) error {
+	fake.setPropertiesMutex.Lock()
+	ret, specificReturn := fake.setPropertiesReturnsOnCall[len(fake.setPropertiesArgsForCall)]
+	fake.setPropertiesArgsForCall = append(fake.setPropertiesArgsForCall, struct {
+		handle     string
+		properties volume.Properties
+		leaseToken string
+	}{handle, properties, leaseToken})
+	fake.recordInvocation("SetProperties", []interface{}{handle, properties, leaseToken})
+	fake.setPropertiesMutex.Unlock()
+	if fake.SetPropertiesStub != nil {
+		return fake.SetPropertiesStub(handle, properties, leaseToken)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setPropertiesReturns.result1
+}
+
+func (fake *FakeRepository) SetPropertiesCallCount() int {
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	return len(fake.setPropertiesArgsForCall)
+}
+
+func (fake *FakeRepository) SetPropertiesArgsForCall(i int) (string, volume.Properties, string) {
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	return fake.setPropertiesArgsForCall[i].handle, fake.setPropertiesArgsForCall[i].properties, fake.setPropertiesArgsForCall[i].leaseToken
+}
+
+func (fake *FakeRepository) SetPropertiesReturns(result1 error) {
+	fake.SetPropertiesStub = nil
+	fake.setPropertiesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetPropertiesReturnsOnCall(i int, result1 error) {
+	fake.SetPropertiesStub = nil
+	if fake.setPropertiesReturnsOnCall == nil {
+		fake.setPropertiesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPropertiesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) DeleteProperty(handle string, propertyName string, leaseToken string) error {
+	fake.deletePropertyMutex.Lock()
+	ret, specificReturn := fake.deletePropertyReturnsOnCall[len(fake.deletePropertyArgsForCall)]
+	fake.deletePropertyArgsForCall = append(fake.deletePropertyArgsForCall, struct {
+		handle       string
+		propertyName string
+		leaseToken   string
+	}{handle, propertyName, leaseToken})
+	fake.recordInvocation("DeleteProperty", []interface{}{handle, propertyName, leaseToken})
+	fake.deletePropertyMutex.Unlock()
+	if fake.DeletePropertyStub != nil {
+		return fake.DeletePropertyStub(handle, propertyName, leaseToken)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deletePropertyReturns.result1
+}
+
+func (fake *FakeRepository) DeletePropertyCallCount() int {
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
+	return len(fake.deletePropertyArgsForCall)
+}
+
+func (fake *FakeRepository) DeletePropertyArgsForCall(i int) (string, string, string) {
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
+	return fake.deletePropertyArgsForCall[i].handle, fake.deletePropertyArgsForCall[i].propertyName, fake.deletePropertyArgsForCall[i].leaseToken
+}
+
+func (fake *FakeRepository) DeletePropertyReturns(result1 error) {
+	fake.DeletePropertyStub = nil
+	fake.deletePropertyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) DeletePropertyReturnsOnCall(i int, result1 error) {
+	fake.DeletePropertyStub = nil
+	if fake.deletePropertyReturnsOnCall == nil {
+		fake.deletePropertyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deletePropertyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) AcquireLease(handle string, token string, duration time.Duration) (volume.Lease, error) {
+	fake.acquireLeaseMutex.Lock()
+	ret, specificReturn := fake.acquireLeaseReturnsOnCall[len(fake.acquireLeaseArgsForCall)]
+	fake.acquireLeaseArgsForCall = append(fake.acquireLeaseArgsForCall, struct {
+		handle   string
+		token    string
+		duration time.Duration
+	}{handle, token, duration})
+	fake.recordInvocation("AcquireLease", []interface{}{handle, token, duration})
+	fake.acquireLeaseMutex.Unlock()
+	if fake.AcquireLeaseStub != nil {
+		return fake.AcquireLeaseStub(handle, token, duration)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.acquireLeaseReturns.result1, fake.acquireLeaseReturns.result2
+}
+
+func (fake *FakeRepository) AcquireLeaseCallCount() int {
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	return len(fake.acquireLeaseArgsForCall)
+}
+
+func (fake *FakeRepository) AcquireLeaseArgsForCall(i int) (string, string, time.Duration) {
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	return fake.acquireLeaseArgsForCall[i].handle, fake.acquireLeaseArgsForCall[i].token, fake.acquireLeaseArgsForCall[i].duration
+}
+
+func (fake *FakeRepository) AcquireLeaseReturns(result1 volume.Lease, result2 error) {
+	fake.AcquireLeaseStub = nil
+	fake.acquireLeaseReturns = struct {
+		result1 volume.Lease
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) AcquireLeaseReturnsOnCall(i int, result1 volume.Lease, result2 error) {
+	fake.AcquireLeaseStub = nil
+	if fake.acquireLeaseReturnsOnCall == nil {
+		fake.acquireLeaseReturnsOnCall = make(map[int]struct {
+			result1 volume.Lease
+			result2 error
+		})
+	}
+	fake.acquireLeaseReturnsOnCall[i] = struct {
+		result1 volume.Lease
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) ReleaseLease(handle string, token string) error {
+	fake.releaseLeaseMutex.Lock()
+	ret, specificReturn := fake.releaseLeaseReturnsOnCall[len(fake.releaseLeaseArgsForCall)]
+	fake.releaseLeaseArgsForCall = append(fake.releaseLeaseArgsForCall, struct {
+		handle string
+		token  string
+	}{handle, token})
+	fake.recordInvocation("ReleaseLease", []interface{}{handle, token})
+	fake.releaseLeaseMutex.Unlock()
+	if fake.ReleaseLeaseStub != nil {
+		return fake.ReleaseLeaseStub(handle, token)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.releaseLeaseReturns.result1
+}
+
+func (fake *FakeRepository) ReleaseLeaseCallCount() int {
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
+	return len(fake.releaseLeaseArgsForCall)
+}
+
+func (fake *FakeRepository) ReleaseLeaseArgsForCall(i int) (string, string) {
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
+	return fake.releaseLeaseArgsForCall[i].handle, fake.releaseLeaseArgsForCall[i].token
+}
+
+func (fake *FakeRepository) ReleaseLeaseReturns(result1 error) {
+	fake.ReleaseLeaseStub = nil
+	fake.releaseLeaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) ReleaseLeaseReturnsOnCall(i int, result1 error) {
+	fake.ReleaseLeaseStub = nil
+	if fake.releaseLeaseReturnsOnCall == nil {
+		fake.releaseLeaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseLeaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetTTL(handle string, ttl uint) error {
+	fake.setTTLMutex.Lock()
+	ret, specificReturn := fake.setTTLReturnsOnCall[len(fake.setTTLArgsForCall)]
+	fake.setTTLArgsForCall = append(fake.setTTLArgsForCall, struct {
+		handle string
+		ttl    uint
+	}{handle, ttl})
+	fake.recordInvocation("SetTTL", []interface{}{handle, ttl})
+	fake.setTTLMutex.Unlock()
+	if fake.SetTTLStub != nil {
+		return fake.SetTTLStub(handle, ttl)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setTTLReturns.result1
+}
+
+func (fake *FakeRepository) SetTTLCallCount() int {
+	fake.setTTLMutex.RLock()
+	defer fake.setTTLMutex.RUnlock()
+	return len(fake.setTTLArgsForCall)
+}
+
+func (fake *FakeRepository) SetTTLArgsForCall(i int) (string, uint) {
+	fake.setTTLMutex.RLock()
+	defer fake.setTTLMutex.RUnlock()
+	return fake.setTTLArgsForCall[i].handle, fake.setTTLArgsForCall[i].ttl
+}
+
+func (fake *FakeRepository) SetTTLReturns(result1 error) {
+	fake.SetTTLStub = nil
+	fake.setTTLReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetTTLReturnsOnCall(i int, result1 error) {
+	fake.SetTTLStub = nil
+	if fake.setTTLReturnsOnCall == nil {
+		fake.setTTLReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setTTLReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetExpiresAt(handle string, expiresAt time.Time) error {
+	fake.setExpiresAtMutex.Lock()
+	ret, specificReturn := fake.setExpiresAtReturnsOnCall[len(fake.setExpiresAtArgsForCall)]
+	fake.setExpiresAtArgsForCall = append(fake.setExpiresAtArgsForCall, struct {
+		handle    string
+		expiresAt time.Time
+	}{handle, expiresAt})
+	fake.recordInvocation("SetExpiresAt", []interface{}{handle, expiresAt})
+	fake.setExpiresAtMutex.Unlock()
+	if fake.SetExpiresAtStub != nil {
+		return fake.SetExpiresAtStub(handle, expiresAt)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setExpiresAtReturns.result1
+}
+
+func (fake *FakeRepository) SetExpiresAtCallCount() int {
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
+	return len(fake.setExpiresAtArgsForCall)
+}
+
+func (fake *FakeRepository) SetExpiresAtArgsForCall(i int) (string, time.Time) {
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
+	return fake.setExpiresAtArgsForCall[i].handle, fake.setExpiresAtArgsForCall[i].expiresAt
+}
+
+func (fake *FakeRepository) SetExpiresAtReturns(result1 error) {
+	fake.SetExpiresAtStub = nil
+	fake.setExpiresAtReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetExpiresAtReturnsOnCall(i int, result1 error) {
+	fake.SetExpiresAtStub = nil
+	if fake.setExpiresAtReturnsOnCall == nil {
+		fake.setExpiresAtReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setExpiresAtReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetPrivileged(handle string, privileged bool) error {
+	fake.setPrivilegedMutex.Lock()
+	ret, specificReturn := fake.setPrivilegedReturnsOnCall[len(fake.setPrivilegedArgsForCall)]
+	fake.setPrivilegedArgsForCall = append(fake.setPrivilegedArgsForCall, struct {
+		handle     string
+		privileged bool
+	}{handle, privileged})
+	fake.recordInvocation("SetPrivileged", []interface{}{handle, privileged})
+	fake.setPrivilegedMutex.Unlock()
+	if fake.SetPrivilegedStub != nil {
+		return fake.SetPrivilegedStub(handle, privileged)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setPrivilegedReturns.result1
+}
+
+func (fake *FakeRepository) SetPrivilegedCallCount() int {
+	fake.setPrivilegedMutex.RLock()
+	defer fake.setPrivilegedMutex.RUnlock()
+	return len(fake.setPrivilegedArgsForCall)
+}
+
+func (fake *FakeRepository) SetPrivilegedArgsForCall(i int) (string, bool) {
+	fake.setPrivilegedMutex.RLock()
+	defer fake.setPrivilegedMutex.RUnlock()
+	return fake.setPrivilegedArgsForCall[i].handle, fake.setPrivilegedArgsForCall[i].privileged
+}
+
+func (fake *FakeRepository) SetPrivilegedReturns(result1 error) {
+	fake.SetPrivilegedStub = nil
+	fake.setPrivilegedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetPrivilegedReturnsOnCall(i int, result1 error) {
+	fake.SetPrivilegedStub = nil
+	if fake.setPrivilegedReturnsOnCall == nil {
+		fake.setPrivilegedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPrivilegedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetSELinuxLabel(handle string, label string) error {
+	fake.setSELinuxLabelMutex.Lock()
+	ret, specificReturn := fake.setSELinuxLabelReturnsOnCall[len(fake.setSELinuxLabelArgsForCall)]
+	fake.setSELinuxLabelArgsForCall = append(fake.setSELinuxLabelArgsForCall, struct {
+		handle string
+		label  string
+	}{handle, label})
+	fake.recordInvocation("SetSELinuxLabel", []interface{}{handle, label})
+	fake.setSELinuxLabelMutex.Unlock()
+	if fake.SetSELinuxLabelStub != nil {
+		return fake.SetSELinuxLabelStub(handle, label)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.setSELinuxLabelReturns.result1
+}
+
+func (fake *FakeRepository) SetSELinuxLabelCallCount() int {
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
+	return len(fake.setSELinuxLabelArgsForCall)
+}
+
+func (fake *FakeRepository) SetSELinuxLabelArgsForCall(i int) (string, string) {
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
+	return fake.setSELinuxLabelArgsForCall[i].handle, fake.setSELinuxLabelArgsForCall[i].label
+}
+
+func (fake *FakeRepository) SetSELinuxLabelReturns(result1 error) {
+	fake.SetSELinuxLabelStub = nil
+	fake.setSELinuxLabelReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) SetSELinuxLabelReturnsOnCall(i int, result1 error) {
+	fake.SetSELinuxLabelStub = nil
+	if fake.setSELinuxLabelReturnsOnCall == nil {
+		fake.setSELinuxLabelReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setSELinuxLabelReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) CommitVolume(handle string, freeze bool) error {
+	fake.commitVolumeMutex.Lock()
+	ret, specificReturn := fake.commitVolumeReturnsOnCall[len(fake.commitVolumeArgsForCall)]
+	fake.commitVolumeArgsForCall = append(fake.commitVolumeArgsForCall, struct {
+		handle string
+		freeze bool
+	}{handle, freeze})
+	fake.recordInvocation("CommitVolume", []interface{}{handle, freeze})
+	fake.commitVolumeMutex.Unlock()
+	if fake.CommitVolumeStub != nil {
+		return fake.CommitVolumeStub(handle, freeze)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.commitVolumeReturns.result1
+}
+
+func (fake *FakeRepository) CommitVolumeCallCount() int {
+	fake.commitVolumeMutex.RLock()
+	defer fake.commitVolumeMutex.RUnlock()
+	return len(fake.commitVolumeArgsForCall)
+}
+
+func (fake *FakeRepository) CommitVolumeArgsForCall(i int) (string, bool) {
+	fake.commitVolumeMutex.RLock()
+	defer fake.commitVolumeMutex.RUnlock()
+	return fake.commitVolumeArgsForCall[i].handle, fake.commitVolumeArgsForCall[i].freeze
+}
+
+func (fake *FakeRepository) CommitVolumeReturns(result1 error) {
+	fake.CommitVolumeStub = nil
+	fake.commitVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) CommitVolumeReturnsOnCall(i int, result1 error) {
+	fake.CommitVolumeStub = nil
+	if fake.commitVolumeReturnsOnCall == nil {
+		fake.commitVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) TouchAccess(handle string) error {
+	fake.touchAccessMutex.Lock()
+	ret, specificReturn := fake.touchAccessReturnsOnCall[len(fake.touchAccessArgsForCall)]
+	fake.touchAccessArgsForCall = append(fake.touchAccessArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("TouchAccess", []interface{}{handle})
+	fake.touchAccessMutex.Unlock()
+	if fake.TouchAccessStub != nil {
+		return fake.TouchAccessStub(handle)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.touchAccessReturns.result1
+}
+
+func (fake *FakeRepository) TouchAccessCallCount() int {
+	fake.touchAccessMutex.RLock()
+	defer fake.touchAccessMutex.RUnlock()
+	return len(fake.touchAccessArgsForCall)
+}
+
+func (fake *FakeRepository) TouchAccessArgsForCall(i int) string {
+	fake.touchAccessMutex.RLock()
+	defer fake.touchAccessMutex.RUnlock()
+	return fake.touchAccessArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) TouchAccessReturns(result1 error) {
+	fake.TouchAccessStub = nil
+	fake.touchAccessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) TouchAccessReturnsOnCall(i int, result1 error) {
+	fake.TouchAccessStub = nil
+	if fake.touchAccessReturnsOnCall == nil {
+		fake.touchAccessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.touchAccessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) Materialize(handle string) (bool, error) {
+	fake.materializeMutex.Lock()
+	ret, specificReturn := fake.materializeReturnsOnCall[len(fake.materializeArgsForCall)]
+	fake.materializeArgsForCall = append(fake.materializeArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Materialize", []interface{}{handle})
+	fake.materializeMutex.Unlock()
+	if fake.MaterializeStub != nil {
+		return fake.MaterializeStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.materializeReturns.result1, fake.materializeReturns.result2
+}
+
+func (fake *FakeRepository) MaterializeCallCount() int {
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	return len(fake.materializeArgsForCall)
+}
+
+func (fake *FakeRepository) MaterializeArgsForCall(i int) string {
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
+	return fake.materializeArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) MaterializeReturns(result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	fake.materializeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) MaterializeReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.MaterializeStub = nil
+	if fake.materializeReturnsOnCall == nil {
+		fake.materializeReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.materializeReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) StreamIn(ctx context.Context, handle string, path string, stream io.Reader, opts volume.StreamInOptions) (bool, error) {
+	fake.streamInMutex.Lock()
+	ret, specificReturn := fake.streamInReturnsOnCall[len(fake.streamInArgsForCall)]
+	fake.streamInArgsForCall = append(fake.streamInArgsForCall, struct {
+		ctx    context.Context
+		handle string
+		path   string
+		stream io.Reader
+		opts   volume.StreamInOptions
+	}{ctx, handle, path, stream, opts})
+	fake.recordInvocation("StreamIn", []interface{}{ctx, handle, path, stream, opts})
+	fake.streamInMutex.Unlock()
+	if fake.StreamInStub != nil {
+		return fake.StreamInStub(ctx, handle, path, stream, opts)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.streamInReturns.result1, fake.streamInReturns.result2
+}
+
+func (fake *FakeRepository) StreamInCallCount() int {
+	fake.streamInMutex.RLock()
+	defer fake.streamInMutex.RUnlock()
+	return len(fake.streamInArgsForCall)
+}
+
+func (fake *FakeRepository) StreamInArgsForCall(i int) (context.Context, string, string, io.Reader, volume.StreamInOptions) {
+	fake.streamInMutex.RLock()
+	defer fake.streamInMutex.RUnlock()
+	return fake.streamInArgsForCall[i].ctx, fake.streamInArgsForCall[i].handle, fake.streamInArgsForCall[i].path, fake.streamInArgsForCall[i].stream, fake.streamInArgsForCall[i].opts
+}
+
+func (fake *FakeRepository) StreamInReturns(result1 bool, result2 error) {
+	fake.StreamInStub = nil
+	fake.streamInReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) StreamInReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.StreamInStub = nil
+	if fake.streamInReturnsOnCall == nil {
+		fake.streamInReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.streamInReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) StreamOut(ctx context.Context, handle string, path string, dest io.Writer, opts volume.StreamOutOptions) error {
+	fake.streamOutMutex.Lock()
+	ret, specificReturn := fake.streamOutReturnsOnCall[len(fake.streamOutArgsForCall)]
+	fake.streamOutArgsForCall = append(fake.streamOutArgsForCall, struct {
+		ctx    context.Context
+		handle string
+		path   string
+		dest   io.Writer
+		opts   volume.StreamOutOptions
+	}{ctx, handle, path, dest, opts})
+	fake.recordInvocation("StreamOut", []interface{}{ctx, handle, path, dest, opts})
+	fake.streamOutMutex.Unlock()
+	if fake.StreamOutStub != nil {
+		return fake.StreamOutStub(ctx, handle, path, dest, opts)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.streamOutReturns.result1
+}
+
+func (fake *FakeRepository) StreamOutCallCount() int {
+	fake.streamOutMutex.RLock()
+	defer fake.streamOutMutex.RUnlock()
+	return len(fake.streamOutArgsForCall)
+}
+
+func (fake *FakeRepository) StreamOutArgsForCall(i int) (context.Context, string, string, io.Writer, volume.StreamOutOptions) {
+	fake.streamOutMutex.RLock()
+	defer fake.streamOutMutex.RUnlock()
+	return fake.streamOutArgsForCall[i].ctx, fake.streamOutArgsForCall[i].handle, fake.streamOutArgsForCall[i].path, fake.streamOutArgsForCall[i].dest, fake.streamOutArgsForCall[i].opts
 }
 
-func (fake *FakeRepository) DestroyVolumeAndDescendantsReturns(result1 error) {
-	fake.DestroyVolumeAndDescendantsStub = nil
-	fake.destroyVolumeAndDescendantsReturns = struct {
+func (fake *FakeRepository) StreamOutReturns(result1 error) {
+	fake.StreamOutStub = nil
+	fake.streamOutReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) DestroyVolumeAndDescendantsReturnsOnCall(i int, result1 error) {
-	fake.DestroyVolumeAndDescendantsStub = nil
-	if fake.destroyVolumeAndDescendantsReturnsOnCall == nil {
-		fake.destroyVolumeAndDescendantsReturnsOnCall = make(map[int]struct {
+func (fake *FakeRepository) StreamOutReturnsOnCall(i int, result1 error) {
+	fake.StreamOutStub = nil
+	if fake.streamOutReturnsOnCall == nil {
+		fake.streamOutReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.destroyVolumeAndDescendantsReturnsOnCall[i] = struct {
+	fake.streamOutReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetProperty(handle string, propertyName string, propertyValue string) error {
-	fake.setPropertyMutex.Lock()
-	ret, specificReturn := fake.setPropertyReturnsOnCall[len(fake.setPropertyArgsForCall)]
-	fake.setPropertyArgsForCall = append(fake.setPropertyArgsForCall, struct {
-		handle        string
-		propertyName  string
-		propertyValue string
-	}{handle, propertyName, propertyValue})
-	fake.recordInvocation("SetProperty", []interface{}{handle, propertyName, propertyValue})
-	fake.setPropertyMutex.Unlock()
-	if fake.SetPropertyStub != nil {
-		return fake.SetPropertyStub(handle, propertyName, propertyValue)
+func (fake *FakeRepository) CopyIn(ctx context.Context, handle string, path string, sourceHandle string, opts volume.CopyInOptions) error {
+	fake.copyInMutex.Lock()
+	ret, specificReturn := fake.copyInReturnsOnCall[len(fake.copyInArgsForCall)]
+	fake.copyInArgsForCall = append(fake.copyInArgsForCall, struct {
+		ctx          context.Context
+		handle       string
+		path         string
+		sourceHandle string
+		opts         volume.CopyInOptions
+	}{ctx, handle, path, sourceHandle, opts})
+	fake.recordInvocation("CopyIn", []interface{}{ctx, handle, path, sourceHandle, opts})
+	fake.copyInMutex.Unlock()
+	if fake.CopyInStub != nil {
+		return fake.CopyInStub(ctx, handle, path, sourceHandle, opts)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.setPropertyReturns.result1
+	return fake.copyInReturns.result1
 }
 
-func (fake *FakeRepository) SetPropertyCallCount() int {
-	fake.setPropertyMutex.RLock()
-	defer fake.setPropertyMutex.RUnlock()
-	return len(fake.setPropertyArgsForCall)
+func (fake *FakeRepository) CopyInCallCount() int {
+	fake.copyInMutex.RLock()
+	defer fake.copyInMutex.RUnlock()
+	return len(fake.copyInArgsForCall)
 }
 
-func (fake *FakeRepository) SetPropertyArgsForCall(i int) (string, string, string) {
-	fake.setPropertyMutex.RLock()
-	defer fake.setPropertyMutex.RUnlock()
-	return fake.setPropertyArgsForCall[i].handle, fake.setPropertyArgsForCall[i].propertyName, fake.setPropertyArgsForCall[i].propertyValue
+func (fake *FakeRepository) CopyInArgsForCall(i int) (context.Context, string, string, string, volume.CopyInOptions) {
+	fake.copyInMutex.RLock()
+	defer fake.copyInMutex.RUnlock()
+	return fake.copyInArgsForCall[i].ctx, fake.copyInArgsForCall[i].handle, fake.copyInArgsForCall[i].path, fake.copyInArgsForCall[i].sourceHandle, fake.copyInArgsForCall[i].opts
 }
 
-func (fake *FakeRepository) SetPropertyReturns(result1 error) {
-	fake.SetPropertyStub = nil
-	fake.setPropertyReturns = struct {
+func (fake *FakeRepository) CopyInReturns(result1 error) {
+	fake.CopyInStub = nil
+	fake.copyInReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetPropertyReturnsOnCall(i int, result1 error) {
-	fake.SetPropertyStub = nil
-	if fake.setPropertyReturnsOnCall == nil {
-		fake.setPropertyReturnsOnCall = make(map[int]struct {
+func (fake *FakeRepository) CopyInReturnsOnCall(i int, result1 error) {
+	fake.CopyInStub = nil
+	if fake.copyInReturnsOnCall == nil {
+		fake.copyInReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.setPropertyReturnsOnCall[i] = struct {
+	fake.copyInReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetTTL(handle string, ttl uint) error {
-	fake.setTTLMutex.Lock()
-	ret, specificReturn := fake.setTTLReturnsOnCall[len(fake.setTTLArgsForCall)]
-	fake.setTTLArgsForCall = append(fake.setTTLArgsForCall, struct {
-		handle string
-		ttl    uint
-	}{handle, ttl})
-	fake.recordInvocation("SetTTL", []interface{}{handle, ttl})
-	fake.setTTLMutex.Unlock()
-	if fake.SetTTLStub != nil {
-		return fake.SetTTLStub(handle, ttl)
+func (fake *FakeRepository) DiffVolumes(handle string, baseHandle string, emit func(volume.DiffEntry) error) error {
+	fake.diffVolumesMutex.Lock()
+	ret, specificReturn := fake.diffVolumesReturnsOnCall[len(fake.diffVolumesArgsForCall)]
+	fake.diffVolumesArgsForCall = append(fake.diffVolumesArgsForCall, struct {
+		handle     string
+		baseHandle string
+		emit       func(volume.DiffEntry) error
+	}{handle, baseHandle, emit})
+	fake.recordInvocation("DiffVolumes", []interface{}{handle, baseHandle, emit})
+	fake.diffVolumesMutex.Unlock()
+	if fake.DiffVolumesStub != nil {
+		return fake.DiffVolumesStub(handle, baseHandle, emit)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.setTTLReturns.result1
+	return fake.diffVolumesReturns.result1
 }
 
-func (fake *FakeRepository) SetTTLCallCount() int {
-	fake.setTTLMutex.RLock()
-	defer fake.setTTLMutex.RUnlock()
-	return len(fake.setTTLArgsForCall)
+func (fake *FakeRepository) DiffVolumesCallCount() int {
+	fake.diffVolumesMutex.RLock()
+	defer fake.diffVolumesMutex.RUnlock()
+	return len(fake.diffVolumesArgsForCall)
 }
 
-func (fake *FakeRepository) SetTTLArgsForCall(i int) (string, uint) {
-	fake.setTTLMutex.RLock()
-	defer fake.setTTLMutex.RUnlock()
-	return fake.setTTLArgsForCall[i].handle, fake.setTTLArgsForCall[i].ttl
+func (fake *FakeRepository) DiffVolumesArgsForCall(i int) (string, string, func(volume.DiffEntry) error) {
+	fake.diffVolumesMutex.RLock()
+	defer fake.diffVolumesMutex.RUnlock()
+	return fake.diffVolumesArgsForCall[i].handle, fake.diffVolumesArgsForCall[i].baseHandle, fake.diffVolumesArgsForCall[i].emit
 }
 
-func (fake *FakeRepository) SetTTLReturns(result1 error) {
-	fake.SetTTLStub = nil
-	fake.setTTLReturns = struct {
+func (fake *FakeRepository) DiffVolumesReturns(result1 error) {
+	fake.DiffVolumesStub = nil
+	fake.diffVolumesReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetTTLReturnsOnCall(i int, result1 error) {
-	fake.SetTTLStub = nil
-	if fake.setTTLReturnsOnCall == nil {
-		fake.setTTLReturnsOnCall = make(map[int]struct {
+func (fake *FakeRepository) DiffVolumesReturnsOnCall(i int, result1 error) {
+	fake.DiffVolumesStub = nil
+	if fake.diffVolumesReturnsOnCall == nil {
+		fake.diffVolumesReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.setTTLReturnsOnCall[i] = struct {
+	fake.diffVolumesReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetPrivileged(handle string, privileged bool) error {
-	fake.setPrivilegedMutex.Lock()
-	ret, specificReturn := fake.setPrivilegedReturnsOnCall[len(fake.setPrivilegedArgsForCall)]
-	fake.setPrivilegedArgsForCall = append(fake.setPrivilegedArgsForCall, struct {
-		handle     string
-		privileged bool
-	}{handle, privileged})
-	fake.recordInvocation("SetPrivileged", []interface{}{handle, privileged})
-	fake.setPrivilegedMutex.Unlock()
-	if fake.SetPrivilegedStub != nil {
-		return fake.SetPrivilegedStub(handle, privileged)
+func (fake *FakeRepository) Manifest(handle string, path string, emit func(volume.ManifestEntry) error) error {
+	fake.manifestMutex.Lock()
+	ret, specificReturn := fake.manifestReturnsOnCall[len(fake.manifestArgsForCall)]
+	fake.manifestArgsForCall = append(fake.manifestArgsForCall, struct {
+		handle string
+		path   string
+		emit   func(volume.ManifestEntry) error
+	}{handle, path, emit})
+	fake.recordInvocation("Manifest", []interface{}{handle, path, emit})
+	fake.manifestMutex.Unlock()
+	if fake.ManifestStub != nil {
+		return fake.ManifestStub(handle, path, emit)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.setPrivilegedReturns.result1
+	return fake.manifestReturns.result1
 }
 
-func (fake *FakeRepository) SetPrivilegedCallCount() int {
-	fake.setPrivilegedMutex.RLock()
-	defer fake.setPrivilegedMutex.RUnlock()
-	return len(fake.setPrivilegedArgsForCall)
+func (fake *FakeRepository) ManifestCallCount() int {
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	return len(fake.manifestArgsForCall)
 }
 
-func (fake *FakeRepository) SetPrivilegedArgsForCall(i int) (string, bool) {
-	fake.setPrivilegedMutex.RLock()
-	defer fake.setPrivilegedMutex.RUnlock()
-	return fake.setPrivilegedArgsForCall[i].handle, fake.setPrivilegedArgsForCall[i].privileged
+func (fake *FakeRepository) ManifestArgsForCall(i int) (string, string, func(volume.ManifestEntry) error) {
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	return fake.manifestArgsForCall[i].handle, fake.manifestArgsForCall[i].path, fake.manifestArgsForCall[i].emit
 }
 
-func (fake *FakeRepository) SetPrivilegedReturns(result1 error) {
-	fake.SetPrivilegedStub = nil
-	fake.setPrivilegedReturns = struct {
+func (fake *FakeRepository) ManifestReturns(result1 error) {
+	fake.ManifestStub = nil
+	fake.manifestReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) SetPrivilegedReturnsOnCall(i int, result1 error) {
-	fake.SetPrivilegedStub = nil
-	if fake.setPrivilegedReturnsOnCall == nil {
-		fake.setPrivilegedReturnsOnCall = make(map[int]struct {
+func (fake *FakeRepository) ManifestReturnsOnCall(i int, result1 error) {
+	fake.ManifestStub = nil
+	if fake.manifestReturnsOnCall == nil {
+		fake.manifestReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.setPrivilegedReturnsOnCall[i] = struct {
+	fake.manifestReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) StreamIn(handle string, path string, stream io.Reader) (bool, error) {
-	fake.streamInMutex.Lock()
-	ret, specificReturn := fake.streamInReturnsOnCall[len(fake.streamInArgsForCall)]
-	fake.streamInArgsForCall = append(fake.streamInArgsForCall, struct {
+func (fake *FakeRepository) ListFiles(handle string, path string) ([]volume.FileEntry, error) {
+	fake.listFilesMutex.Lock()
+	ret, specificReturn := fake.listFilesReturnsOnCall[len(fake.listFilesArgsForCall)]
+	fake.listFilesArgsForCall = append(fake.listFilesArgsForCall, struct {
 		handle string
 		path   string
-		stream io.Reader
-	}{handle, path, stream})
-	fake.recordInvocation("StreamIn", []interface{}{handle, path, stream})
-	fake.streamInMutex.Unlock()
-	if fake.StreamInStub != nil {
-		return fake.StreamInStub(handle, path, stream)
+	}{handle, path})
+	fake.recordInvocation("ListFiles", []interface{}{handle, path})
+	fake.listFilesMutex.Unlock()
+	if fake.ListFilesStub != nil {
+		return fake.ListFilesStub(handle, path)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.streamInReturns.result1, fake.streamInReturns.result2
+	return fake.listFilesReturns.result1, fake.listFilesReturns.result2
 }
 
-func (fake *FakeRepository) StreamInCallCount() int {
-	fake.streamInMutex.RLock()
-	defer fake.streamInMutex.RUnlock()
-	return len(fake.streamInArgsForCall)
+func (fake *FakeRepository) ListFilesCallCount() int {
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	return len(fake.listFilesArgsForCall)
 }
 
-func (fake *FakeRepository) StreamInArgsForCall(i int) (string, string, io.Reader) {
-	fake.streamInMutex.RLock()
-	defer fake.streamInMutex.RUnlock()
-	return fake.streamInArgsForCall[i].handle, fake.streamInArgsForCall[i].path, fake.streamInArgsForCall[i].stream
+func (fake *FakeRepository) ListFilesArgsForCall(i int) (string, string) {
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	return fake.listFilesArgsForCall[i].handle, fake.listFilesArgsForCall[i].path
 }
 
-func (fake *FakeRepository) StreamInReturns(result1 bool, result2 error) {
-	fake.StreamInStub = nil
-	fake.streamInReturns = struct {
-		result1 bool
+func (fake *FakeRepository) ListFilesReturns(result1 []volume.FileEntry, result2 error) {
+	fake.ListFilesStub = nil
+	fake.listFilesReturns = struct {
+		result1 []volume.FileEntry
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) StreamInReturnsOnCall(i int, result1 bool, result2 error) {
-	fake.StreamInStub = nil
-	if fake.streamInReturnsOnCall == nil {
-		fake.streamInReturnsOnCall = make(map[int]struct {
-			result1 bool
+func (fake *FakeRepository) ListFilesReturnsOnCall(i int, result1 []volume.FileEntry, result2 error) {
+	fake.ListFilesStub = nil
+	if fake.listFilesReturnsOnCall == nil {
+		fake.listFilesReturnsOnCall = make(map[int]struct {
+			result1 []volume.FileEntry
 			result2 error
 		})
 	}
-	fake.streamInReturnsOnCall[i] = struct {
-		result1 bool
+	fake.listFilesReturnsOnCall[i] = struct {
+		result1 []volume.FileEntry
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) StreamOut(handle string, path string, dest io.Writer) error {
-	fake.streamOutMutex.Lock()
-	ret, specificReturn := fake.streamOutReturnsOnCall[len(fake.streamOutArgsForCall)]
-	fake.streamOutArgsForCall = append(fake.streamOutArgsForCall, struct {
-		handle string
-		path   string
-		dest   io.Writer
-	}{handle, path, dest})
-	fake.recordInvocation("StreamOut", []interface{}{handle, path, dest})
-	fake.streamOutMutex.Unlock()
-	if fake.StreamOutStub != nil {
-		return fake.StreamOutStub(handle, path, dest)
+func (fake *FakeRepository) StreamOutDiff(handle string, baseHandle string, dest io.Writer) error {
+	fake.streamOutDiffMutex.Lock()
+	ret, specificReturn := fake.streamOutDiffReturnsOnCall[len(fake.streamOutDiffArgsForCall)]
+	fake.streamOutDiffArgsForCall = append(fake.streamOutDiffArgsForCall, struct {
+		handle     string
+		baseHandle string
+		dest       io.Writer
+	}{handle, baseHandle, dest})
+	fake.recordInvocation("StreamOutDiff", []interface{}{handle, baseHandle, dest})
+	fake.streamOutDiffMutex.Unlock()
+	if fake.StreamOutDiffStub != nil {
+		return fake.StreamOutDiffStub(handle, baseHandle, dest)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.streamOutReturns.result1
+	return fake.streamOutDiffReturns.result1
 }
 
-func (fake *FakeRepository) StreamOutCallCount() int {
-	fake.streamOutMutex.RLock()
-	defer fake.streamOutMutex.RUnlock()
-	return len(fake.streamOutArgsForCall)
+func (fake *FakeRepository) StreamOutDiffCallCount() int {
+	fake.streamOutDiffMutex.RLock()
+	defer fake.streamOutDiffMutex.RUnlock()
+	return len(fake.streamOutDiffArgsForCall)
 }
 
-func (fake *FakeRepository) StreamOutArgsForCall(i int) (string, string, io.Writer) {
-	fake.streamOutMutex.RLock()
-	defer fake.streamOutMutex.RUnlock()
-	return fake.streamOutArgsForCall[i].handle, fake.streamOutArgsForCall[i].path, fake.streamOutArgsForCall[i].dest
+func (fake *FakeRepository) StreamOutDiffArgsForCall(i int) (string, string, io.Writer) {
+	fake.streamOutDiffMutex.RLock()
+	defer fake.streamOutDiffMutex.RUnlock()
+	return fake.streamOutDiffArgsForCall[i].handle, fake.streamOutDiffArgsForCall[i].baseHandle, fake.streamOutDiffArgsForCall[i].dest
 }
 
-func (fake *FakeRepository) StreamOutReturns(result1 error) {
-	fake.StreamOutStub = nil
-	fake.streamOutReturns = struct {
+func (fake *FakeRepository) StreamOutDiffReturns(result1 error) {
+	fake.StreamOutDiffStub = nil
+	fake.streamOutDiffReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeRepository) StreamOutReturnsOnCall(i int, result1 error) {
-	fake.StreamOutStub = nil
-	if fake.streamOutReturnsOnCall == nil {
-		fake.streamOutReturnsOnCall = make(map[int]struct {
+func (fake *FakeRepository) StreamOutDiffReturnsOnCall(i int, result1 error) {
+	fake.StreamOutDiffStub = nil
+	if fake.streamOutDiffReturnsOnCall == nil {
+		fake.streamOutDiffReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.streamOutReturnsOnCall[i] = struct {
+	fake.streamOutDiffReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -795,6 +2540,242 @@ func (fake *FakeRepository) VolumeParentReturnsOnCall(i int, result1 volume.Volu
 	}{result1, result2, result3}
 }
 
+func (fake *FakeRepository) WithLogData(arg1 lager.Data) volume.Repository {
+	fake.withLogDataMutex.Lock()
+	ret, specificReturn := fake.withLogDataReturnsOnCall[len(fake.withLogDataArgsForCall)]
+	fake.withLogDataArgsForCall = append(fake.withLogDataArgsForCall, struct {
+		arg1 lager.Data
+	}{arg1})
+	fake.recordInvocation("WithLogData", []interface{}{arg1})
+	fake.withLogDataMutex.Unlock()
+	if fake.WithLogDataStub != nil {
+		return fake.WithLogDataStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.withLogDataReturns.result1
+}
+
+func (fake *FakeRepository) WithLogDataCallCount() int {
+	fake.withLogDataMutex.RLock()
+	defer fake.withLogDataMutex.RUnlock()
+	return len(fake.withLogDataArgsForCall)
+}
+
+func (fake *FakeRepository) WithLogDataArgsForCall(i int) lager.Data {
+	fake.withLogDataMutex.RLock()
+	defer fake.withLogDataMutex.RUnlock()
+	return fake.withLogDataArgsForCall[i].arg1
+}
+
+func (fake *FakeRepository) WithLogDataReturns(result1 volume.Repository) {
+	fake.WithLogDataStub = nil
+	fake.withLogDataReturns = struct {
+		result1 volume.Repository
+	}{result1}
+}
+
+func (fake *FakeRepository) WithLogDataReturnsOnCall(i int, result1 volume.Repository) {
+	fake.WithLogDataStub = nil
+	if fake.withLogDataReturnsOnCall == nil {
+		fake.withLogDataReturnsOnCall = make(map[int]struct {
+			result1 volume.Repository
+		})
+	}
+	fake.withLogDataReturnsOnCall[i] = struct {
+		result1 volume.Repository
+	}{result1}
+}
+
+func (fake *FakeRepository) VolumeChildren(handle string) ([]string, error) {
+	fake.volumeChildrenMutex.Lock()
+	ret, specificReturn := fake.volumeChildrenReturnsOnCall[len(fake.volumeChildrenArgsForCall)]
+	fake.volumeChildrenArgsForCall = append(fake.volumeChildrenArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("VolumeChildren", []interface{}{handle})
+	fake.volumeChildrenMutex.Unlock()
+	if fake.VolumeChildrenStub != nil {
+		return fake.VolumeChildrenStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.volumeChildrenReturns.result1, fake.volumeChildrenReturns.result2
+}
+
+func (fake *FakeRepository) VolumeChildrenCallCount() int {
+	fake.volumeChildrenMutex.RLock()
+	defer fake.volumeChildrenMutex.RUnlock()
+	return len(fake.volumeChildrenArgsForCall)
+}
+
+func (fake *FakeRepository) VolumeChildrenArgsForCall(i int) string {
+	fake.volumeChildrenMutex.RLock()
+	defer fake.volumeChildrenMutex.RUnlock()
+	return fake.volumeChildrenArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) VolumeChildrenReturns(result1 []string, result2 error) {
+	fake.VolumeChildrenStub = nil
+	fake.volumeChildrenReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) VolumeChildrenReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.VolumeChildrenStub = nil
+	if fake.volumeChildrenReturnsOnCall == nil {
+		fake.volumeChildrenReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.volumeChildrenReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) VolumeDigest(handle string) (string, error) {
+	fake.volumeDigestMutex.Lock()
+	ret, specificReturn := fake.volumeDigestReturnsOnCall[len(fake.volumeDigestArgsForCall)]
+	fake.volumeDigestArgsForCall = append(fake.volumeDigestArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("VolumeDigest", []interface{}{handle})
+	fake.volumeDigestMutex.Unlock()
+	if fake.VolumeDigestStub != nil {
+		return fake.VolumeDigestStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.volumeDigestReturns.result1, fake.volumeDigestReturns.result2
+}
+
+func (fake *FakeRepository) VolumeDigestCallCount() int {
+	fake.volumeDigestMutex.RLock()
+	defer fake.volumeDigestMutex.RUnlock()
+	return len(fake.volumeDigestArgsForCall)
+}
+
+func (fake *FakeRepository) VolumeDigestArgsForCall(i int) string {
+	fake.volumeDigestMutex.RLock()
+	defer fake.volumeDigestMutex.RUnlock()
+	return fake.volumeDigestArgsForCall[i].handle
+}
+
+func (fake *FakeRepository) VolumeDigestReturns(result1 string, result2 error) {
+	fake.VolumeDigestStub = nil
+	fake.volumeDigestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) VolumeDigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.VolumeDigestStub = nil
+	if fake.volumeDigestReturnsOnCall == nil {
+		fake.volumeDigestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.volumeDigestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) InodesExhausted() (bool, error) {
+	fake.inodesExhaustedMutex.Lock()
+	ret, specificReturn := fake.inodesExhaustedReturnsOnCall[len(fake.inodesExhaustedArgsForCall)]
+	fake.inodesExhaustedArgsForCall = append(fake.inodesExhaustedArgsForCall, struct{}{})
+	fake.recordInvocation("InodesExhausted", []interface{}{})
+	fake.inodesExhaustedMutex.Unlock()
+	if fake.InodesExhaustedStub != nil {
+		return fake.InodesExhaustedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.inodesExhaustedReturns.result1, fake.inodesExhaustedReturns.result2
+}
+
+func (fake *FakeRepository) InodesExhaustedCallCount() int {
+	fake.inodesExhaustedMutex.RLock()
+	defer fake.inodesExhaustedMutex.RUnlock()
+	return len(fake.inodesExhaustedArgsForCall)
+}
+
+func (fake *FakeRepository) InodesExhaustedReturns(result1 bool, result2 error) {
+	fake.InodesExhaustedStub = nil
+	fake.inodesExhaustedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) InodesExhaustedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.InodesExhaustedStub = nil
+	if fake.inodesExhaustedReturnsOnCall == nil {
+		fake.inodesExhaustedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.inodesExhaustedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) FreeBytes() (uint64, error) {
+	fake.freeBytesMutex.Lock()
+	ret, specificReturn := fake.freeBytesReturnsOnCall[len(fake.freeBytesArgsForCall)]
+	fake.freeBytesArgsForCall = append(fake.freeBytesArgsForCall, struct{}{})
+	fake.recordInvocation("FreeBytes", []interface{}{})
+	fake.freeBytesMutex.Unlock()
+	if fake.FreeBytesStub != nil {
+		return fake.FreeBytesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.freeBytesReturns.result1, fake.freeBytesReturns.result2
+}
+
+func (fake *FakeRepository) FreeBytesCallCount() int {
+	fake.freeBytesMutex.RLock()
+	defer fake.freeBytesMutex.RUnlock()
+	return len(fake.freeBytesArgsForCall)
+}
+
+func (fake *FakeRepository) FreeBytesReturns(result1 uint64, result2 error) {
+	fake.FreeBytesStub = nil
+	fake.freeBytesReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) FreeBytesReturnsOnCall(i int, result1 uint64, result2 error) {
+	fake.FreeBytesStub = nil
+	if fake.freeBytesReturnsOnCall == nil {
+		fake.freeBytesReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 error
+		})
+	}
+	fake.freeBytesReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -802,26 +2783,88 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.listVolumesMutex.RUnlock()
 	fake.getVolumeMutex.RLock()
 	defer fake.getVolumeMutex.RUnlock()
+	fake.volumeExistsMutex.RLock()
+	defer fake.volumeExistsMutex.RUnlock()
 	fake.getVolumeStatsMutex.RLock()
 	defer fake.getVolumeStatsMutex.RUnlock()
+	fake.totalUsageMutex.RLock()
+	defer fake.totalUsageMutex.RUnlock()
 	fake.createVolumeMutex.RLock()
 	defer fake.createVolumeMutex.RUnlock()
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	fake.renameVolumeMutex.RLock()
+	defer fake.renameVolumeMutex.RUnlock()
+	fake.promoteMutex.RLock()
+	defer fake.promoteMutex.RUnlock()
 	fake.destroyVolumeMutex.RLock()
 	defer fake.destroyVolumeMutex.RUnlock()
 	fake.destroyVolumeAndDescendantsMutex.RLock()
 	defer fake.destroyVolumeAndDescendantsMutex.RUnlock()
+	fake.planDestroyMutex.RLock()
+	defer fake.planDestroyMutex.RUnlock()
+	fake.destroyVolumesMutex.RLock()
+	defer fake.destroyVolumesMutex.RUnlock()
+	fake.destroyVolumesWithPropertiesMutex.RLock()
+	defer fake.destroyVolumesWithPropertiesMutex.RUnlock()
+	fake.restoreVolumeMutex.RLock()
+	defer fake.restoreVolumeMutex.RUnlock()
+	fake.listDeletedVolumesMutex.RLock()
+	defer fake.listDeletedVolumesMutex.RUnlock()
+	fake.purgeDeletedVolumesMutex.RLock()
+	defer fake.purgeDeletedVolumesMutex.RUnlock()
+	fake.getPropertyMutex.RLock()
+	defer fake.getPropertyMutex.RUnlock()
 	fake.setPropertyMutex.RLock()
 	defer fake.setPropertyMutex.RUnlock()
+	fake.setPropertiesMutex.RLock()
+	defer fake.setPropertiesMutex.RUnlock()
+	fake.deletePropertyMutex.RLock()
+	defer fake.deletePropertyMutex.RUnlock()
+	fake.acquireLeaseMutex.RLock()
+	defer fake.acquireLeaseMutex.RUnlock()
+	fake.releaseLeaseMutex.RLock()
+	defer fake.releaseLeaseMutex.RUnlock()
 	fake.setTTLMutex.RLock()
 	defer fake.setTTLMutex.RUnlock()
+	fake.setExpiresAtMutex.RLock()
+	defer fake.setExpiresAtMutex.RUnlock()
 	fake.setPrivilegedMutex.RLock()
 	defer fake.setPrivilegedMutex.RUnlock()
+	fake.setSELinuxLabelMutex.RLock()
+	defer fake.setSELinuxLabelMutex.RUnlock()
+	fake.commitVolumeMutex.RLock()
+	defer fake.commitVolumeMutex.RUnlock()
+	fake.touchAccessMutex.RLock()
+	defer fake.touchAccessMutex.RUnlock()
+	fake.materializeMutex.RLock()
+	defer fake.materializeMutex.RUnlock()
 	fake.streamInMutex.RLock()
 	defer fake.streamInMutex.RUnlock()
 	fake.streamOutMutex.RLock()
 	defer fake.streamOutMutex.RUnlock()
+	fake.copyInMutex.RLock()
+	defer fake.copyInMutex.RUnlock()
+	fake.diffVolumesMutex.RLock()
+	defer fake.diffVolumesMutex.RUnlock()
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	fake.listFilesMutex.RLock()
+	defer fake.listFilesMutex.RUnlock()
+	fake.streamOutDiffMutex.RLock()
+	defer fake.streamOutDiffMutex.RUnlock()
 	fake.volumeParentMutex.RLock()
 	defer fake.volumeParentMutex.RUnlock()
+	fake.withLogDataMutex.RLock()
+	defer fake.withLogDataMutex.RUnlock()
+	fake.volumeChildrenMutex.RLock()
+	defer fake.volumeChildrenMutex.RUnlock()
+	fake.volumeDigestMutex.RLock()
+	defer fake.volumeDigestMutex.RUnlock()
+	fake.inodesExhaustedMutex.RLock()
+	defer fake.inodesExhaustedMutex.RUnlock()
+	fake.freeBytesMutex.RLock()
+	defer fake.freeBytesMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value